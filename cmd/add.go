@@ -1,10 +1,9 @@
 package cmd
 
 import (
-	"fmt"
-	"os"
 	"strings"
 
+	"github.com/m44rten1/sprout/internal/build"
 	"github.com/m44rten1/sprout/internal/core"
 	"github.com/m44rten1/sprout/internal/effects"
 	"github.com/m44rten1/sprout/internal/git"
@@ -14,12 +13,94 @@ import (
 var (
 	addNoHooksFlag bool
 	addNoOpenFlag  bool
+	addOpenFlag    bool
+	addPrintPath   bool
+	addTrustFlag   bool
+	addTicketFlag  string
+	addArtifacts   bool
+	addEvictFlag   bool
+	addFromStash   string
+	addApplyPatch  string
+	addTTLFlag     string
+	addTagFlag     string
+	addBranchFlag  string
+	addDetachFlag  string
+	addForceCreate bool
+	addLockFlag    string
+	addPorcelain   string
 )
 
 var addCmd = &cobra.Command{
-	Use:   "add [branch]",
+	Use:   "add [branch | label]",
 	Short: "Create a new worktree",
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Create a new worktree for a branch, or pick one interactively.
+
+With --ticket, the branch name is built from the branch_template in
+.sprout.yml and the argument is a short description:
+
+  # branch_template: "feat/{user}/{ticket}-{slug}"
+  sprout add --ticket ABC-123 "fix login"   # feat/jane/ABC-123-fix-login
+
+If ticket_provider (jira or linear) is set, the description may be omitted
+and the ticket's title is fetched instead. The ticket is shown next to the
+worktree in 'sprout list' and 'sprout info'.
+
+With --clone-artifacts, the build output directories listed under artifacts
+in .sprout.yml are cloned from the main worktree so the first build is
+incremental. This needs a filesystem with copy-on-write clones (APFS, btrfs,
+XFS); elsewhere the worktree is created without them.
+
+If max_worktrees is set and the new worktree exceeds it, the least recently
+used worktrees are listed as candidates for removal. With --evict, clean
+worktrees whose commits are all merged are removed instead, oldest first.
+
+With --from-stash, a stash entry (such as stash@{0}, or just 0) is applied to
+the new worktree; the entry stays in 'git stash list'. --apply-patch applies
+a patch file, such as the output of 'git diff', the same way. To move the
+uncommitted changes of the current worktree instead, use 'sprout graft'.
+
+With --tag, the worktree gets a new branch that starts at a tag, such as a
+fix for a release. The branch is named with --branch or the argument:
+
+  sprout add --tag v1.4.2 --branch hotfix/1.4.3
+
+With --detach, the worktree checks out a commit on a detached HEAD, to
+look at a release or bisect without a branch. Its directory is named after
+the argument, a label, or the short commit without one, and the other
+commands find it by that name:
+
+  sprout add --detach v1.4.2 release   # sprout open release
+  sprout add --detach 3f2a9c1          # sprout remove 3f2a9c1
+
+git checks out a branch in one worktree at a time, so when another worktree
+already has it, sprout says where instead of creating a new one. Open that
+worktree with 'sprout open <path>', or pass --force-create to check the
+branch out in both.
+
+With --ttl, the worktree is temporary: 'sprout list' shows the time it has
+left, and once that has run out 'sprout prune' removes it, unless it has
+uncommitted changes or is pinned. The branch is kept. Durations are like
+"90m", "2h" or "3d":
+
+  sprout add spike/try-bun --ttl 2h
+
+With --lock, git locks the worktree as it creates it, so 'git worktree
+prune', which sprout runs too, keeps it while its directory is missing, such
+as on a network share or removable volume that isn't mounted. The reason is
+shown by 'git worktree list'; pass "" to lock without one. Unlock it with
+'git worktree unlock <path>':
+
+  sprout add feature --lock "on the USB drive"
+
+With --print-path, only the worktree's path is printed on stdout (other
+output goes to stderr), for scripts:
+
+  cd "$(sprout add feature -p --no-open)"
+
+--porcelain=v1 prints one stable, tab-separated line instead: the main
+worktree, the worktree, its branch, and 1 if it was created or 0 if it
+existed already.`,
+	Args: cobra.MaximumNArgs(1),
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		// Only complete the first argument
 		if len(args) > 0 {
@@ -44,137 +125,93 @@ var addCmd = &cobra.Command{
 		// Reuse core logic to filter available branches
 		availableBranches := core.GetWorktreeAvailableBranches(branches, worktrees)
 
-		var completions []string
-		for _, branch := range availableBranches {
-			// Filter by what user has typed so far
-			if strings.HasPrefix(branch.DisplayName, toComplete) {
-				completions = append(completions, branch.DisplayName)
-			}
-		}
-
-		return completions, cobra.ShellCompDirectiveNoFileComp
+		return core.BranchCompletions(availableBranches, toComplete), cobra.ShellCompDirectiveNoFileComp
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		fx := effects.NewRealEffects()
+		var output func(core.AddContext) string
+		if porcelainFormat(addPorcelain) != "" {
+			output = addedPorcelain
+		} else if addPrintPath {
+			output = addedPath
+		}
 
-		ctx, err := BuildAddContext(fx, args, addNoHooksFlag, addNoOpenFlag)
+		fx := effects.NewRealEffects()
+		defer lockRepo(fx)()
+
+		ctx, err := build.AddContext(fx, args, build.AddOptions{
+			NoHooks: addNoHooksFlag,
+			NoOpen:  addNoOpenFlag,
+			Open:    addOpenFlag,
+			Trust:   addTrustFlag,
+			Ticket:  addTicketFlag,
+
+			CloneArtifacts: addArtifacts,
+			Evict:          addEvictFlag,
+			FromStash:      addFromStash,
+			ApplyPatch:     addApplyPatch,
+			TTL:            addTTLFlag,
+			Tag:            addTagFlag,
+			Branch:         addBranchFlag,
+			Detach:         addDetachFlag,
+			ForceCreate:    addForceCreate,
+			Lock:           cmd.Flags().Changed("lock"),
+			LockReason:     addLockFlag,
+		})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 
 		plan := core.PlanAddCommand(ctx)
-		runPlan(plan, fx)
+		runAddPlan(plan, fx, ctx, output)
 	},
 }
 
-// BuildAddContext gathers all inputs needed to plan the add command.
-// It handles interactive branch selection if no branch is provided.
-func BuildAddContext(fx effects.Effects, args []string, noHooks, noOpen bool) (core.AddContext, error) {
-	// Get repo root
-	repoRoot, err := fx.GetRepoRoot()
-	if err != nil {
-		return core.AddContext{}, fmt.Errorf("not a git repository: %w", err)
-	}
-
-	// Get main worktree path for config loading and hooks
-	mainWorktreePath, err := fx.GetMainWorktreePath()
-	if err != nil {
-		return core.AddContext{}, fmt.Errorf("failed to get main worktree: %w", err)
-	}
-
-	// Determine branch name (interactive or from args)
-	var branch string
-	if len(args) == 0 {
-		// Interactive mode: select from existing branches
-		branches, err := fx.ListBranches(repoRoot)
-		if err != nil {
-			return core.AddContext{}, fmt.Errorf("failed to list branches: %w", err)
-		}
-
-		worktrees, err := fx.ListWorktrees(repoRoot)
-		if err != nil {
-			return core.AddContext{}, fmt.Errorf("failed to list worktrees: %w", err)
-		}
-
-		availableBranches := core.GetWorktreeAvailableBranches(branches, worktrees)
-		if len(availableBranches) == 0 {
-			return core.AddContext{}, fmt.Errorf("no available branches found")
-		}
-
-		idx, err := fx.SelectBranch(availableBranches)
-		if err != nil {
-			return core.AddContext{}, fmt.Errorf("branch selection cancelled: %w", err)
-		}
-
-		branch = availableBranches[idx].DisplayName
-	} else {
-		branch = args[0]
-	}
-
-	// Strip remote prefix if user provided it (e.g., "origin/feature" -> "feature")
-	branch = strings.TrimPrefix(branch, "origin/")
-
-	// Calculate worktree path
-	worktreePath, err := fx.GetWorktreePath(mainWorktreePath, branch)
-	if err != nil {
-		return core.AddContext{}, fmt.Errorf("error calculating worktree path: %w", err)
-	}
-
-	// Check if worktree already exists
-	worktreeExists := fx.FileExists(worktreePath)
-
-	// Check branch existence
-	localBranchExists, err := fx.LocalBranchExists(repoRoot, branch)
-	if err != nil {
-		return core.AddContext{}, fmt.Errorf("failed to check local branch: %w", err)
-	}
-
-	remoteBranchExists, err := fx.RemoteBranchExists(repoRoot, branch)
-	if err != nil {
-		return core.AddContext{}, fmt.Errorf("failed to check remote branch: %w", err)
-	}
-
-	// Check if origin/main exists (used as base for new branches)
-	// Note: RemoteBranchExists automatically prepends "origin/" prefix
-	hasRemoteMain, err := fx.RemoteBranchExists(repoRoot, "main")
-	if err != nil {
-		return core.AddContext{}, fmt.Errorf("failed to check origin/main: %w", err)
-	}
-
-	// Load config
-	cfg, err := fx.LoadConfig(repoRoot, mainWorktreePath)
-	if err != nil {
-		return core.AddContext{}, fmt.Errorf("failed to load config: %w", err)
-	}
-
-	// Check trust status (only matters if hooks will run)
-	isTrusted := false
-	if cfg.HasCreateHooks() && !noHooks {
-		isTrusted, err = fx.IsTrusted(mainWorktreePath)
-		if err != nil {
-			return core.AddContext{}, fmt.Errorf("failed to check trust status: %w", err)
-		}
-	}
-
-	return core.AddContext{
-		Branch:             branch,
-		RepoRoot:           repoRoot,
-		MainWorktreePath:   mainWorktreePath,
-		WorktreePath:       worktreePath,
-		WorktreeExists:     worktreeExists,
-		LocalBranchExists:  localBranchExists,
-		RemoteBranchExists: remoteBranchExists,
-		HasOriginMain:      hasRemoteMain,
-		Config:             cfg,
-		IsTrusted:          isTrusted,
-		NoHooks:            noHooks,
-		NoOpen:             noOpen,
-	}, nil
-}
-
 func init() {
 	rootCmd.AddCommand(addCmd)
 	addCmd.Flags().BoolVar(&addNoHooksFlag, "no-hooks", false, "Skip running on_create hooks even if .sprout.yml exists")
 	addCmd.Flags().BoolVar(&addNoOpenFlag, "no-open", false, "Skip opening the worktree in an editor")
+	addCmd.Flags().BoolVar(&addOpenFlag, "open", false, "Open the worktree in an editor even if open_editor is false")
+	addCmd.MarkFlagsMutuallyExclusive("open", "no-open")
+	addCmd.Flags().BoolVarP(&addPrintPath, "print-path", "p", false, "Only print the worktree path on stdout (for cd \"$(sprout add ...)\")")
+	addPorcelainFlag(addCmd, &addPorcelain)
+	addCmd.MarkFlagsMutuallyExclusive("print-path", "porcelain")
+	addCmd.Flags().BoolVar(&addTrustFlag, "trust", false, "Trust this repository's hooks without prompting (for scripted use)")
+	addCmd.Flags().BoolVar(&addArtifacts, "clone-artifacts", false, "Clone the build artifacts listed in .sprout.yml from the main worktree (copy-on-write filesystems only)")
+	addCmd.Flags().BoolVar(&addEvictFlag, "evict", false, "Remove least recently used clean, merged worktrees when over max_worktrees")
+	addCmd.Flags().StringVar(&addTicketFlag, "ticket", "", "Ticket id to build the branch name from branch_template (the argument becomes the description)")
+	addCmd.Flags().StringVar(&addFromStash, "from-stash", "", "Apply this stash entry (e.g. stash@{0}, or 0) to the new worktree")
+	addCmd.Flags().StringVar(&addApplyPatch, "apply-patch", "", "Apply this patch file (e.g. from 'git diff') to the new worktree")
+	addCmd.MarkFlagsMutuallyExclusive("from-stash", "apply-patch")
+	addCmd.Flags().StringVar(&addTagFlag, "tag", "", "Start a new branch at this tag (e.g. v1.4.2)")
+	addCmd.Flags().StringVar(&addBranchFlag, "branch", "", "Name of the branch, instead of the argument (e.g. with --tag)")
+	addCmd.MarkFlagsMutuallyExclusive("tag", "ticket")
+	addCmd.Flags().StringVar(&addDetachFlag, "detach", "", "Check out this ref on a detached HEAD; the argument is the label (default: the short commit)")
+	addCmd.MarkFlagsMutuallyExclusive("detach", "ticket", "tag", "branch")
+	addCmd.Flags().BoolVar(&addForceCreate, "force-create", false, "Check out the branch even if another worktree has it checked out")
+	addCmd.MarkFlagsMutuallyExclusive("branch", "ticket")
+	_ = addCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	addCmd.Flags().StringVar(&addLockFlag, "lock", "", "Lock the worktree so 'git worktree prune' keeps it while its directory is missing (e.g. on a removable volume), giving this reason")
+	addCmd.Flags().StringVar(&addTTLFlag, "ttl", "", "Make the worktree temporary: prune removes it once this long has passed (e.g. 2h, 3d)")
+	_ = addCmd.RegisterFlagCompletionFunc("from-stash", completeStashEntries)
+	_ = addCmd.MarkFlagFilename("apply-patch", "diff", "patch")
+}
+
+// completeTags completes --tag with the tags of the current repository,
+// newest first.
+func completeTags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	out, err := git.RunGitCommand("", "tag", "--list", "--sort=-creatordate")
+	if err != nil || out == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return strings.Split(out, "\n"), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeStashEntries completes --from-stash with the stash entries of
+// the current repository and their messages.
+func completeStashEntries(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	out, err := git.RunGitCommand("", "stash", "list", "--format=%gd\t%gs")
+	if err != nil || out == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return strings.Split(out, "\n"), cobra.ShellCompDirectiveNoFileComp
 }
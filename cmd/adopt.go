@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/m44rten1/sprout/internal/build"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+
+	"github.com/spf13/cobra"
+)
+
+var adoptMoveFlag bool
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt [branch-or-path]",
+	Short: "Let sprout manage a worktree created with git worktree add",
+	Long: `Adopt a worktree that was created manually with 'git worktree add', so
+list, open and remove treat it like any other sprout worktree.
+
+If no argument is provided, the worktree you are currently in is adopted.
+
+By default the worktree stays where it is and is recorded in sprout's metadata.
+With --move it is relocated under the sprout root instead, to the same path
+'sprout add' would have used.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+		defer lockRepo(fx)()
+
+		ctx, err := BuildAdoptContext(fx, args, adoptMoveFlag)
+		if err != nil {
+			exitWithError(err)
+		}
+
+		plan := core.PlanAdoptCommand(ctx)
+		runPlan(plan, fx)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+	adoptCmd.Flags().BoolVar(&adoptMoveFlag, "move", false, "Move the worktree under the sprout root instead of recording its location")
+}
+
+// BuildAdoptContext gathers all inputs needed to plan the adopt command.
+// The argument is treated as a path if it exists, otherwise as a branch name.
+func BuildAdoptContext(fx effects.Effects, args []string, move bool) (core.AdoptContext, error) {
+	repoRoot, err := fx.GetRepoRoot()
+	if err != nil {
+		return core.AdoptContext{}, fmt.Errorf("not a git repository: %w", err)
+	}
+
+	mainWorktreePath, err := fx.GetMainWorktreePath()
+	if err != nil {
+		return core.AdoptContext{}, fmt.Errorf("failed to get main worktree: %w", err)
+	}
+
+	sproutRoot, err := fx.GetSproutRoot()
+	if err != nil {
+		return core.AdoptContext{}, fmt.Errorf("failed to get sprout root: %w", err)
+	}
+
+	adopted, err := fx.ListAdoptedWorktrees()
+	if err != nil {
+		return core.AdoptContext{}, fmt.Errorf("failed to load adopted worktrees: %w", err)
+	}
+
+	worktrees, err := fx.ListWorktrees(repoRoot)
+	if err != nil {
+		return core.AdoptContext{}, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	// Resolve the target: current worktree, an existing path, or a branch name
+	targetPath := repoRoot
+	byBranch := false
+	if len(args) > 0 {
+		arg := args[0]
+		if effects.Exists(fx, arg) {
+			if abs, err := filepath.Abs(arg); err == nil {
+				targetPath = abs
+			} else {
+				targetPath = arg
+			}
+		} else {
+			targetPath = arg
+			byBranch = true
+		}
+	}
+
+	ctx := core.AdoptContext{
+		RepoRoot:         repoRoot,
+		MainWorktreePath: mainWorktreePath,
+		SproutRoot:       sproutRoot,
+		Adopted:          adopted,
+		TargetPath:       targetPath,
+		Move:             move,
+	}
+
+	match := -1
+	if byBranch {
+		match = core.MatchWorktreeBranch(worktrees, targetPath)
+	} else {
+		for i, wt := range worktrees {
+			if filepath.Clean(wt.Path) == filepath.Clean(targetPath) {
+				match = i
+				break
+			}
+		}
+	}
+	if match >= 0 {
+		ctx.TargetPath = worktrees[match].Path
+		ctx.Branch = worktrees[match].Branch
+		ctx.IsWorktree = true
+	}
+	if byBranch && !ctx.IsWorktree {
+		return core.AdoptContext{}, fmt.Errorf("no worktree found for branch '%s'", targetPath)
+	}
+
+	if move && ctx.IsWorktree && ctx.Branch != "" {
+		destPath, err := fx.GetWorktreePath(mainWorktreePath, ctx.Branch)
+		if err != nil {
+			return core.AdoptContext{}, fmt.Errorf("failed to compute worktree path: %w", err)
+		}
+		ctx.DestPath = destPath
+		if ctx.DestExists, err = build.WorktreeDirExists(fx, destPath); err != nil {
+			return core.AdoptContext{}, err
+		}
+	}
+
+	return ctx, nil
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildAdoptContext(t *testing.T) {
+	t.Parallel()
+
+	newFx := func() *effects.TestEffects {
+		fx := effects.NewTestEffects()
+		fx.RepoRoot = "/test/repo"
+		fx.MainWorktreePath = "/test/repo"
+		fx.Worktrees = []git.Worktree{
+			{Path: "/test/repo", Branch: "main"},
+			{Path: "/manual/feature", Branch: "feature"},
+		}
+		return fx
+	}
+
+	t.Run("by branch", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+
+		ctx, err := BuildAdoptContext(fx, []string{"feature"}, false)
+		require.NoError(t, err)
+		assert.Equal(t, "/manual/feature", ctx.TargetPath)
+		assert.True(t, ctx.IsWorktree)
+		assert.Empty(t, ctx.DestPath, "destination only matters with --move")
+
+		require.NoError(t, effects.ExecutePlan(core.PlanAdoptCommand(ctx), fx))
+		assert.Equal(t, []string{"/manual/feature"}, fx.Adopted)
+	})
+
+	t.Run("move computes destination", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.WorktreePaths["feature"] = "/sprout/repo-1234/feature/repo"
+
+		ctx, err := BuildAdoptContext(fx, []string{"feature"}, true)
+		require.NoError(t, err)
+		assert.Equal(t, "/sprout/repo-1234/feature/repo", ctx.DestPath)
+		assert.False(t, ctx.DestExists)
+	})
+
+	t.Run("unknown branch", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+
+		_, err := BuildAdoptContext(fx, []string{"nope"}, false)
+		assert.Error(t, err)
+	})
+}
+
+func TestBuildListContext_IncludesAdopted(t *testing.T) {
+	t.Parallel()
+
+	fx := effects.NewTestEffects()
+	fx.RepoRoot = "/test/repo"
+	fx.Worktrees = []git.Worktree{
+		{Path: "/test/repo", Branch: "main"},
+		{Path: "/manual/feature", Branch: "feature"},
+	}
+	fx.Adopted = []string{"/manual/feature"}
+	fx.Files["/manual/feature"] = true
+
+	ctx, err := BuildListContext(fx, false)
+	require.NoError(t, err)
+	require.Len(t, ctx.Repos, 1)
+	require.Len(t, ctx.Repos[0].Worktrees, 2)
+	assert.Equal(t, "/manual/feature", ctx.Repos[0].Worktrees[1].Path)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/m44rten1/sprout/internal/core"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// expandAliases replaces the command word of args when it is one of the
+// aliases from the user config, keeping the global flags before it. In a
+// completion request the word after it is expanded, so aliases complete
+// like the commands they stand for.
+func expandAliases(args []string, aliases map[string]string) ([]string, error) {
+	if len(aliases) == 0 {
+		return args, nil
+	}
+	i := commandWordIndex(args)
+	if i >= 0 && (args[i] == cobra.ShellCompRequestCmd || args[i] == cobra.ShellCompNoDescRequestCmd) {
+		i++
+	}
+	if i < 0 || i >= len(args) {
+		return args, nil
+	}
+
+	words, ok, err := core.ExpandAlias(args[i], aliases, isBuiltinCommand)
+	if err != nil || !ok {
+		return args, err
+	}
+	return slices.Concat(args[:i], words, args[i+1:]), nil
+}
+
+// commandWordIndex returns the index of the first argument that is neither
+// a global flag nor its value, or -1 if there is none.
+func commandWordIndex(args []string) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return -1
+		case !strings.HasPrefix(arg, "-") || arg == "-":
+			return i
+		case strings.Contains(arg, "="):
+			continue
+		}
+
+		var flag *pflag.Flag
+		if name, long := strings.CutPrefix(arg, "--"); long {
+			flag = rootCmd.PersistentFlags().Lookup(name)
+		} else if len(arg) == 2 {
+			flag = rootCmd.PersistentFlags().ShorthandLookup(arg[1:])
+		}
+		if flag != nil && flag.NoOptDefVal == "" {
+			i++ // Its value
+		}
+	}
+	return -1
+}
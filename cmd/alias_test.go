@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandAliases(t *testing.T) {
+	t.Parallel()
+
+	aliases := map[string]string{"a": "add --no-open", "rm": "remove --force"}
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "command word", args: []string{"a", "feature"}, want: []string{"add", "--no-open", "feature"}},
+		{name: "after global flags", args: []string{"--repo", "api", "--no-color", "rm", "old"}, want: []string{"--repo", "api", "--no-color", "remove", "--force", "old"}},
+		{name: "flag value that looks like an alias", args: []string{"--repo", "a", "list"}, want: []string{"--repo", "a", "list"}},
+		{name: "flag with its value attached", args: []string{"--repo=api", "a"}, want: []string{"--repo=api", "add", "--no-open"}},
+		{name: "arguments are left alone", args: []string{"open", "a"}, want: []string{"open", "a"}},
+		{name: "completion request", args: []string{"__complete", "a", ""}, want: []string{"__complete", "add", "--no-open", ""}},
+		{name: "no command", args: []string{"--version"}, want: []string{"--version"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := expandAliases(tt.args, aliases)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
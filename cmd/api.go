@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/m44rten1/sprout/internal/build"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var apiCmd = &cobra.Command{
+	Use:   "api",
+	Short: "Machine-readable interface for editor plugins",
+	Long: `A stable JSON interface for editor plugins and other tools, so they don't
+have to parse the human-readable output of the other commands.
+
+Subcommands that take input read a JSON request object from stdin, such as
+{"branch": "feature"}. Every subcommand prints exactly one JSON object on
+stdout, carrying the schema "version" and either a result or an "error"
+with a "code" (invalid_request, not_found, non_interactive, git, failed)
+and a "message". Anything else sprout or hooks print goes to stderr.
+
+Requests may say which "version" they were written for; a newer version
+than this sprout speaks is rejected. sprout api never prompts: a create
+that would need trusting hooks fails with non_interactive unless the
+request sets "trust".
+
+  sprout api list-worktrees
+  echo '{"branch": "feature"}' | sprout api resolve-branch
+  echo '{"branch": "feature", "no_hooks": true}' | sprout api create
+  echo '{"branch": "feature", "force": true}' | sprout api remove`,
+}
+
+var apiListWorktreesCmd = &cobra.Command{
+	Use:   "list-worktrees",
+	Short: "List the main worktree and sprout worktrees with their status",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := apiEffects()
+		worktrees, err := build.APIWorktreeList(fx)
+		if err != nil {
+			exitWithAPIError(fx, err)
+		}
+		writeAPIResponse(fx, core.APIResponse{Worktrees: worktrees})
+	},
+}
+
+var apiResolveBranchCmd = &cobra.Command{
+	Use:   "resolve-branch",
+	Short: "Find the worktree of a branch",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := apiEffects()
+		req := readAPIRequest(fx)
+		wt, err := build.ResolveAPIWorktree(fx, req.Branch)
+		if err != nil {
+			exitWithAPIError(fx, err)
+		}
+		writeAPIResponse(fx, core.APIResponse{Worktree: &wt})
+	},
+}
+
+var apiCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a worktree for a branch, without opening the editor",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := apiEffects()
+		req := readAPIRequest(fx)
+		if req.Branch == "" {
+			exitWithAPIError(fx, fmt.Errorf("%w: branch is required", core.ErrInvalidAPIRequest))
+		}
+
+		ctx, err := build.AddContext(fx, []string{req.Branch}, build.AddOptions{
+			NoHooks: req.NoHooks,
+			NoOpen:  true,
+			Trust:   req.Trust,
+		})
+		if err != nil {
+			exitWithAPIError(fx, err)
+		}
+		if err := runAPIPlan(core.PlanAddCommand(ctx), fx); err != nil {
+			exitWithAPIError(fx, err)
+		}
+
+		wt := core.APIWorktree{Path: absPath(ctx.WorktreePath), Branch: ctx.Branch}
+		writeAPIResponse(fx, core.APIResponse{Worktree: &wt, Created: !ctx.WorktreeExists})
+	},
+}
+
+var apiRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove the sprout worktree of a branch",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := apiEffects()
+		req := readAPIRequest(fx)
+		wt, err := build.ResolveAPIWorktree(fx, req.Branch)
+		if err != nil {
+			exitWithAPIError(fx, err)
+		}
+
+		ctx, err := build.RemoveContext(fx, []string{wt.Path}, req.Force)
+		if err != nil {
+			exitWithAPIError(fx, err)
+		}
+		if err := runAPIPlan(core.PlanRemoveCommand(ctx), fx); err != nil {
+			exitWithAPIError(fx, err)
+		}
+		writeAPIResponse(fx, core.APIResponse{Worktree: &wt})
+	},
+}
+
+func init() {
+	apiCmd.AddCommand(apiListWorktreesCmd, apiResolveBranchCmd, apiCreateCmd, apiRemoveCmd)
+	rootCmd.AddCommand(apiCmd)
+}
+
+// apiEffects returns the effects for an api subcommand. Clients read stdout,
+// so nothing may prompt, and --dry-run would report changes never made.
+func apiEffects() effects.Effects {
+	fx := effects.NewRealEffects()
+	os.Setenv("SPROUT_NON_INTERACTIVE", "1")
+	if dryRunFlag {
+		exitWithAPIError(fx, fmt.Errorf("%w: --dry-run is not supported by sprout api", core.ErrInvalidAPIRequest))
+	}
+	return fx
+}
+
+// readAPIRequest reads the request from stdin, or exits with an api error.
+// Without piped input the request is empty rather than waiting on the terminal.
+func readAPIRequest(fx effects.Effects) core.APIRequest {
+	var data []byte
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		var err error
+		data, err = io.ReadAll(os.Stdin)
+		if err != nil {
+			exitWithAPIError(fx, fmt.Errorf("failed to read request: %w", err))
+		}
+	}
+	req, err := core.ParseAPIRequest(data)
+	if err != nil {
+		exitWithAPIError(fx, err)
+	}
+	return req
+}
+
+// runAPIPlan executes a plan with everything it prints sent to stderr, so
+// stdout carries only the response. A plan that only reports invalid input
+// is returned as an error instead of printing it.
+func runAPIPlan(plan core.Plan, fx effects.Effects) error {
+	if err := core.PlanErr(plan); err != nil {
+		return err
+	}
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = stdout }()
+	return effects.ExecutePlan(plan, fx)
+}
+
+// writeAPIResponse prints resp on stdout.
+func writeAPIResponse(fx effects.Effects, resp core.APIResponse) {
+	if err := effects.ExecutePlan(core.PlanAPIResponse(resp), fx); err != nil {
+		if code, ok := effects.IsExit(err); ok {
+			os.Exit(code)
+		}
+		exitWithError(err)
+	}
+}
+
+// exitWithAPIError prints err as an api response and exits.
+func exitWithAPIError(fx effects.Effects, err error) {
+	writeAPIResponse(fx, core.APIResponse{Error: apiError(err)})
+	recordTelemetry(errorType(err))
+	os.Exit(errorExitCode(err))
+}
+
+// apiError classifies err for an api response.
+func apiError(err error) *core.APIError {
+	apiErr := &core.APIError{Code: core.APIErrFailed, Message: err.Error()}
+	var gitErr *git.GitError
+	switch {
+	case errors.Is(err, core.ErrInvalidAPIRequest), errors.Is(err, core.ErrEmptyBranch):
+		apiErr.Code = core.APIErrInvalidRequest
+	case errors.Is(err, core.ErrNoWorktreeFound), errors.Is(err, core.ErrNoSproutWorktrees):
+		apiErr.Code = core.APIErrNotFound
+	case errors.Is(err, effects.ErrNonInteractive):
+		apiErr.Code = core.APIErrNonInteractive
+	case errors.As(err, &gitErr):
+		apiErr.Code = core.APIErrGit
+		apiErr.Git = gitErr
+	}
+	return apiErr
+}
+
+// absPath makes path absolute where possible, for clients running elsewhere.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
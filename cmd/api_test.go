@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError(t *testing.T) {
+	t.Parallel()
+
+	gitErr := &git.GitError{Args: []string{"worktree", "remove", "/wt"}, ExitCode: 128, Stderr: "fatal: contains modified files"}
+
+	tests := []struct {
+		name     string
+		err      error
+		wantCode string
+	}{
+		{name: "invalid request", err: fmt.Errorf("%w: branch is required", core.ErrInvalidAPIRequest), wantCode: core.APIErrInvalidRequest},
+		{name: "not found", err: fmt.Errorf("%w for branch 'x'", core.ErrNoWorktreeFound), wantCode: core.APIErrNotFound},
+		{name: "no sprout worktrees", err: core.ErrNoSproutWorktrees, wantCode: core.APIErrNotFound},
+		{name: "needs a prompt", err: fmt.Errorf("prompt trust: %w", effects.ErrNonInteractive), wantCode: core.APIErrNonInteractive},
+		{name: "git failure", err: fmt.Errorf("git command in /repo failed: %w", gitErr), wantCode: core.APIErrGit},
+		{name: "anything else", err: errors.New("boom"), wantCode: core.APIErrFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := apiError(tt.err)
+			assert.Equal(t, tt.wantCode, got.Code)
+			assert.Equal(t, tt.err.Error(), got.Message)
+		})
+	}
+
+	assert.Same(t, gitErr, apiError(fmt.Errorf("wrapped: %w", gitErr)).Git)
+}
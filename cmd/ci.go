@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/m44rten1/sprout/internal/config"
+)
+
+var ciFlag bool
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&ciFlag, "ci", false, "Run for a CI pipeline: no prompts, pickers, editor, colors or progress, and hook output as a summary (also SPROUT_CI=1)")
+}
+
+// ciMode reports whether sprout runs in a CI pipeline: with --ci, or with
+// SPROUT_CI set, by the pipeline or by a sprout that runs the hooks.
+func ciMode() bool {
+	if ciFlag {
+		return true
+	}
+	v := os.Getenv("SPROUT_CI")
+	return v != "" && v != "0"
+}
+
+// initCI turns on everything --ci stands for. It runs before the flags it
+// sets are read, and exports SPROUT_CI so nested sprout invocations follow.
+// Hook output is only summarized when SPROUT_HOOK_OUTPUT doesn't say otherwise.
+func initCI() {
+	if !ciMode() {
+		return
+	}
+	os.Setenv("SPROUT_CI", "1")
+	nonInteractive = true
+	noColorFlag = true
+	noProgressFlag = true
+	if os.Getenv("SPROUT_HOOK_OUTPUT") == "" {
+		os.Setenv("SPROUT_HOOK_OUTPUT", string(config.HookOutputSummary))
+	}
+}
@@ -7,28 +7,135 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+
 	"github.com/spf13/cobra"
 )
 
 var (
 	completionDryRunFlag bool
+	completionEvalFlag   bool
+	zshEvalFlag          bool
+)
+
+// Markers around the lines install-completion adds to a shell config file.
+// Blocks written before the end marker existed are recognized by their lines.
+const (
+	completionBlockStart = "# Sprout completion setup (added by 'sprout completion install')"
+	completionBlockEnd   = "# End of sprout completion setup"
 )
 
+// zshEvalPreamble loads compinit unless the shell config already has, since
+// the completion script registers itself with compdef.
+const zshEvalPreamble = `autoload -Uz compinit
+(( $+functions[compdef] )) || compinit
+`
+
 var completionInstallCmd = &cobra.Command{
 	Use:   "install-completion",
 	Short: "Install shell completion automatically",
-	Long:  `Detects your shell and automatically configures completion by adding the necessary lines to your shell config file.`,
+	Long: `Detects your shell and automatically configures completion by adding the necessary lines to your shell config file.
+
+With --eval, a single line that loads completion from sprout itself is added
+instead, such as eval "$(sprout completion zsh --eval)" for zsh.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := installCompletion(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 	},
 }
 
+var completionUninstallCmd = &cobra.Command{
+	Use:   "uninstall-completion",
+	Short: "Remove the shell completion setup added by install-completion",
+	Long: `Removes the lines install-completion added to your shell config file,
+leaving the rest of the file as it is. The file is backed up first, like
+install-completion does.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := uninstallCompletion(); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// completeSproutWorktrees completes the branches of sprout-managed worktrees,
+// describing each with its status icons.
+func completeSproutWorktrees(toComplete string) ([]string, cobra.ShellCompDirective) {
+	fx := effects.NewRealEffects()
+
+	repoRoot, err := fx.GetRepoRoot()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	mainWorktreePath, err := fx.GetMainWorktreePath()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	worktrees, err := fx.ListWorktrees(repoRoot)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	sproutRoot, err := fx.GetWorktreeRoot(mainWorktreePath)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	adopted, _ := fx.ListAdoptedWorktrees()
+	choices := core.FilterSproutWorktrees(worktrees, sproutRoot, adopted...)
+	bases := make(map[string]string)
+	if metadata, err := fx.LoadWorktreeMetadata(); err == nil {
+		for path, meta := range metadata {
+			bases[path] = meta.Base
+		}
+	}
+	var defaultBranch string
+	if cfg, err := fx.LoadConfig(mainWorktreePath, mainWorktreePath); err == nil {
+		defaultBranch = cfg.DefaultBranch
+	}
+	statuses := git.GetWorktreeStatuses(choices, bases, defaultBranch)
+
+	// Completion computes fresh statuses anyway; keep the prompt cache current
+	cached := make(map[string]git.WorktreeStatus, len(choices))
+	for i, wt := range choices {
+		cached[wt.Path] = statuses[i]
+	}
+	_ = fx.CacheStatuses(cached)
+
+	return core.WorktreeCompletions(choices, statuses, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
 func init() {
 	rootCmd.AddCommand(completionInstallCmd)
 	completionInstallCmd.Flags().BoolVar(&completionDryRunFlag, "dry-run", false, "Show what would be added without modifying files")
+	completionInstallCmd.Flags().BoolVar(&completionEvalFlag, "eval", false, "Add a single line that loads completion from sprout instead of the compinit setup")
+	rootCmd.AddCommand(completionUninstallCmd)
+	completionUninstallCmd.Flags().BoolVar(&completionDryRunFlag, "dry-run", false, "Show what would be removed without modifying files")
+
+	// Cobra adds its completion command when sprout runs; adding it now lets
+	// zsh take --eval. The commands of the files before this one are enough
+	// for cobra to see that sprout has subcommands.
+	rootCmd.InitDefaultCompletionCmd()
+	if zshCmd, _, err := rootCmd.Find([]string{"completion", "zsh"}); err == nil && zshCmd.Name() == "zsh" {
+		zshCmd.Flags().BoolVar(&zshEvalFlag, "eval", false, "Also load compinit, for eval \"$(sprout completion zsh --eval)\" in ~/.zshrc")
+		zshCmd.Long += `
+To load completions for every new session without writing a script file,
+add this line to ~/.zshrc instead:
+
+	eval "$(sprout completion zsh --eval)"
+`
+		generate := zshCmd.RunE
+		zshCmd.RunE = func(cmd *cobra.Command, args []string) error {
+			if zshEvalFlag {
+				fmt.Fprint(cmd.OutOrStdout(), zshEvalPreamble)
+			}
+			return generate(cmd, args)
+		}
+	}
 }
 
 func installCompletion() error {
@@ -55,7 +162,7 @@ func installCompletion() error {
 	}
 
 	// Generate the completion setup lines
-	setupLines := generateSetupLines(shell)
+	setupLines := generateSetupLines(shell, completionEvalFlag)
 
 	if completionDryRunFlag {
 		fmt.Println("Dry run mode - would add the following to", configFile)
@@ -160,15 +267,31 @@ func isAlreadyConfigured(configFile, shell string) bool {
 	return false
 }
 
-func generateSetupLines(shell string) string {
+// generateSetupLines returns the block install-completion adds for shell.
+// With eval it is one line that loads the completion script from sprout,
+// which then needs no compinit or Homebrew setup of its own.
+func generateSetupLines(shell string, eval bool) string {
+	return completionBlockStart + "\n" + setupBody(shell, eval, isHomebrewInstalled()) + completionBlockEnd + "\n"
+}
+
+// setupBody returns the lines between the markers of the block
+// install-completion adds, brew saying whether Homebrew is installed.
+func setupBody(shell string, eval, brew bool) string {
 	var lines strings.Builder
 
-	lines.WriteString("# Sprout completion setup (added by 'sprout completion install')\n")
+	switch {
+	case eval && shell == "zsh":
+		lines.WriteString("eval \"$(sprout completion zsh --eval)\"\n")
 
-	switch shell {
-	case "zsh":
+	case eval && shell == "bash":
+		lines.WriteString("source <(sprout completion bash)\n")
+
+	case eval && shell == "fish":
+		lines.WriteString("sprout completion fish | source\n")
+
+	case shell == "zsh":
 		// Check if Homebrew is installed and add fpath setup
-		if isHomebrewInstalled() {
+		if brew {
 			lines.WriteString("if type brew &>/dev/null; then\n")
 			lines.WriteString("  FPATH=\"$(brew --prefix)/share/zsh/site-functions:${FPATH}\"\n")
 			lines.WriteString("fi\n")
@@ -176,8 +299,8 @@ func generateSetupLines(shell string) string {
 		lines.WriteString("autoload -Uz compinit\n")
 		lines.WriteString("compinit\n")
 
-	case "bash":
-		if isHomebrewInstalled() {
+	case shell == "bash":
+		if brew {
 			lines.WriteString("if type brew &>/dev/null; then\n")
 			lines.WriteString("  HOMEBREW_PREFIX=\"$(brew --prefix)\"\n")
 			lines.WriteString("  if [[ -r \"${HOMEBREW_PREFIX}/etc/profile.d/bash_completion.sh\" ]]; then\n")
@@ -188,8 +311,8 @@ func generateSetupLines(shell string) string {
 			lines.WriteString("source <(sprout completion bash)\n")
 		}
 
-	case "fish":
-		if isHomebrewInstalled() {
+	case shell == "fish":
+		if brew {
 			lines.WriteString("if type -q brew\n")
 			lines.WriteString("  set -gx fish_complete_path (brew --prefix)/share/fish/vendor_completions.d $fish_complete_path\n")
 			lines.WriteString("end\n")
@@ -201,6 +324,108 @@ func generateSetupLines(shell string) string {
 	return lines.String()
 }
 
+func uninstallCompletion() error {
+	shell := detectShell()
+	if shell == "" {
+		return fmt.Errorf("could not detect shell. Supported shells: zsh, bash, fish")
+	}
+
+	configFile, err := getShellConfigFile(shell)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(configFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	content, removed := removeSetupLines(string(data))
+	if removed == "" {
+		fmt.Printf("No sprout completion setup found in %s\n", configFile)
+		return nil
+	}
+
+	if completionDryRunFlag {
+		fmt.Println("Dry run mode - would remove the following from", configFile)
+		fmt.Println(strings.Repeat("-", 60))
+		fmt.Print(removed)
+		fmt.Println(strings.Repeat("-", 60))
+		return nil
+	}
+
+	if err := backupConfigFile(configFile); err != nil {
+		return fmt.Errorf("failed to backup config file: %w", err)
+	}
+	info, err := os.Stat(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := os.WriteFile(configFile, []byte(content), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	fmt.Printf("✓ Removed completion setup from %s\n", configFile)
+	fmt.Printf("✓ Backup saved to: %s.backup-sprout\n", configFile)
+	fmt.Println("\nRestart your shell for it to take effect:")
+	fmt.Printf("  exec %s\n", shell)
+	return nil
+}
+
+// removeSetupLines removes every block install-completion added to content,
+// with the blank lines it put around each, and returns the remaining content
+// and the removed lines. Blocks from before the end marker lose only the
+// lines sprout wrote (see legacySetupLength).
+func removeSetupLines(content string) (rest, removed string) {
+	lines := strings.SplitAfter(content, "\n")
+	var kept, dropped []string
+	for i := 0; i < len(lines); i++ {
+		if strings.TrimRight(lines[i], "\r\n") != completionBlockStart {
+			kept = append(kept, lines[i])
+			continue
+		}
+		if n := len(kept); n > 0 && strings.TrimSpace(kept[n-1]) == "" {
+			kept = kept[:n-1]
+		}
+
+		end := i + legacySetupLength(lines[i+1:])
+		for j := i + 1; j < len(lines); j++ {
+			line := strings.TrimRight(lines[j], "\r\n")
+			if line == "" {
+				break
+			}
+			if line == completionBlockEnd {
+				end = j
+				break
+			}
+		}
+		dropped = append(dropped, lines[i:end+1]...)
+		if end+1 < len(lines) && strings.TrimSpace(lines[end+1]) == "" {
+			end++
+		}
+		i = end
+	}
+	return strings.Join(kept, ""), strings.Join(dropped, "")
+}
+
+// legacySetupLength returns how many of lines, which follow a start marker
+// without an end marker, are the block install-completion wrote before it
+// had one: the longest run that matches the setup of a shell line for line.
+// Lines the user added or changed after them are kept.
+func legacySetupLength(lines []string) int {
+	longest := 0
+	for _, shell := range []string{"zsh", "bash", "fish"} {
+		for _, brew := range []bool{false, true} {
+			body := strings.SplitAfter(setupBody(shell, false, brew), "\n")
+			n := 0
+			for n < len(body)-1 && n < len(lines) && strings.TrimRight(lines[n], "\r\n") == strings.TrimSuffix(body[n], "\n") {
+				n++
+			}
+			longest = max(longest, n)
+		}
+	}
+	return longest
+}
+
 func isHomebrewInstalled() bool {
 	_, err := exec.LookPath("brew")
 	return err == nil
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateSetupLines_Eval(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"zsh":  `eval "$(sprout completion zsh --eval)"`,
+		"bash": "source <(sprout completion bash)",
+		"fish": "sprout completion fish | source",
+	}
+	for shell, line := range tests {
+		assert.Equal(t, completionBlockStart+"\n"+line+"\n"+completionBlockEnd+"\n", generateSetupLines(shell, true), shell)
+	}
+}
+
+func TestRemoveSetupLines(t *testing.T) {
+	t.Parallel()
+
+	t.Run("round trip with install", func(t *testing.T) {
+		t.Parallel()
+		original := "export A=1\n"
+		installed := original + "\n" + generateSetupLines("zsh", true) + "\n" + "alias x=y\n"
+
+		rest, removed := removeSetupLines(installed)
+		assert.Equal(t, "export A=1\nalias x=y\n", rest)
+		assert.Equal(t, generateSetupLines("zsh", true), removed)
+	})
+
+	t.Run("block without end marker", func(t *testing.T) {
+		t.Parallel()
+		content := "export A=1\n\n" + completionBlockStart + "\nautoload -Uz compinit\ncompinit\n\nalias x=y\n"
+
+		rest, removed := removeSetupLines(content)
+		assert.Equal(t, "export A=1\nalias x=y\n", rest)
+		assert.Equal(t, completionBlockStart+"\nautoload -Uz compinit\ncompinit\n", removed)
+	})
+
+	t.Run("block without end marker keeps the user's lines after it", func(t *testing.T) {
+		t.Parallel()
+		legacy := completionBlockStart + "\n" + setupBody("bash", false, true)
+		content := "export A=1\n\n" + legacy + "alias x=y\nexport B=2\n"
+
+		rest, removed := removeSetupLines(content)
+		assert.Equal(t, "export A=1\nalias x=y\nexport B=2\n", rest)
+		assert.Equal(t, legacy, removed)
+	})
+
+	t.Run("block without end marker keeps lines the user changed", func(t *testing.T) {
+		t.Parallel()
+		content := completionBlockStart + "\nautoload -Uz compinit\ncompinit -u\n"
+
+		rest, removed := removeSetupLines(content)
+		assert.Equal(t, "compinit -u\n", rest)
+		assert.Equal(t, completionBlockStart+"\nautoload -Uz compinit\n", removed)
+	})
+
+	t.Run("nothing installed", func(t *testing.T) {
+		t.Parallel()
+		rest, removed := removeSetupLines("export A=1\n")
+		assert.Equal(t, "export A=1\n", rest)
+		assert.Empty(t, removed)
+	})
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Check .sprout.yml and get its schema",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Report problems in .sprout.yml",
+	Long: `Check a .sprout.yml for unknown keys (such as a misspelled on_create),
+values of the wrong type and unsupported settings. Every problem is printed
+with its line, and the exit code is non-zero if there are any, so it can run
+in CI or a pre-commit hook.
+
+Without an argument, the .sprout.yml sprout would load is checked: the
+current worktree's, or else the main worktree's.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+
+		ctx, err := BuildConfigFileContext(fx, args)
+		if err != nil {
+			exitWithError(err)
+		}
+
+		plan := core.PlanConfigValidateCommand(ctx)
+		runPlan(plan, fx)
+	},
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print a JSON Schema for .sprout.yml",
+	Long: `Print a JSON Schema describing .sprout.yml, so editors can complete and
+check the file. For example, with the YAML language server (VS Code's YAML
+extension and others):
+
+  sprout config schema > .sprout.schema.json
+
+and at the top of .sprout.yml:
+
+  # yaml-language-server: $schema=.sprout.schema.json
+
+The schema is generated from the settings this sprout understands, so
+regenerate it after updating sprout.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runPlan(core.PlanConfigSchemaCommand(), effects.NewRealEffects())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd, configSchemaCmd)
+}
+
+// readConfigBases reads the files the config file at path extends, and the
+// ones they extend. Problems are left for validating to report.
+func readConfigBases(fx effects.Effects, path, dir string, data []byte) map[string][]byte {
+	bases := map[string][]byte{}
+	_, _ = config.ResolveIn(path, dir, data, func(base string) ([]byte, error) {
+		data, err := fx.ReadFile(base)
+		if err == nil {
+			bases[base] = data
+		}
+		return data, err
+	})
+	return bases
+}
+
+// BuildConfigFileContext reads the config file given, or else the
+// .sprout.yml that config.Load would read: the current worktree's, falling
+// back to the main worktree's.
+func BuildConfigFileContext(fx effects.Effects, args []string) (core.ConfigFileContext, error) {
+	var candidates []string
+	var dir string // Where relative extends resolve from; the file's directory if empty
+	if len(args) > 0 {
+		candidates = []string{args[0]}
+	} else {
+		repoRoot, err := fx.GetRepoRoot()
+		if err != nil {
+			return core.ConfigFileContext{}, fmt.Errorf("not a git repository: %w", err)
+		}
+		mainWorktreePath, err := fx.GetMainWorktreePath()
+		if err != nil {
+			return core.ConfigFileContext{}, fmt.Errorf("failed to get main worktree: %w", err)
+		}
+		candidates = []string{filepath.Join(repoRoot, ".sprout.yml"), filepath.Join(mainWorktreePath, ".sprout.yml")}
+		dir = mainWorktreePath
+	}
+
+	for _, path := range candidates {
+		data, err := fx.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return core.ConfigFileContext{}, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		ctx := core.ConfigFileContext{Path: path, Data: data, Dir: dir}
+		ctx.Bases = readConfigBases(fx, path, cmp.Or(dir, filepath.Dir(path)), data)
+		return ctx, nil
+	}
+	return core.ConfigFileContext{}, fmt.Errorf("no config file found at %s", candidates[0])
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildConfigFileContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("prefers the current worktree's config", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+		fx.RepoRoot = "/sprout/repo-1234/feature/repo"
+		fx.FileContents["/sprout/repo-1234/feature/repo/.sprout.yml"] = []byte("share_mode: clone\n")
+		fx.FileContents["/test/repo/.sprout.yml"] = []byte("share_mode: symlink\n")
+
+		ctx, err := BuildConfigFileContext(fx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "/sprout/repo-1234/feature/repo/.sprout.yml", ctx.Path)
+		assert.Equal(t, "share_mode: clone\n", string(ctx.Data))
+	})
+
+	t.Run("falls back to the main worktree", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+		fx.RepoRoot = "/sprout/repo-1234/feature/repo"
+		fx.FileContents["/test/repo/.sprout.yml"] = []byte("share_mode: symlink\n")
+
+		ctx, err := BuildConfigFileContext(fx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "/test/repo/.sprout.yml", ctx.Path)
+	})
+
+	t.Run("worktree extends relative to the main worktree", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+		fx.RepoRoot = "/sprout/repo-1234/feature/repo"
+		fx.FileContents["/sprout/repo-1234/feature/repo/.sprout.yml"] = []byte("extends: ../shared/base.yml\n")
+		fx.FileContents["/test/shared/base.yml"] = []byte("share_mode: clone\n")
+
+		ctx, err := BuildConfigFileContext(fx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "/test/repo", ctx.Dir)
+		assert.Equal(t, map[string][]byte{"/test/shared/base.yml": []byte("share_mode: clone\n")}, ctx.Bases)
+	})
+
+	t.Run("explicit file", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+
+		_, err := BuildConfigFileContext(fx, []string{"/elsewhere/.sprout.yml"})
+		assert.EqualError(t, err, "no config file found at /elsewhere/.sprout.yml")
+	})
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffWorkingFlag bool
+	diffToolFlag    bool
+	diffStatFlag    bool
+)
+
+// DiffOptions holds the command-line flags of the diff command.
+type DiffOptions struct {
+	Working bool // Compare working trees (uncommitted changes included) instead of HEADs
+	Tool    bool
+	Stat    bool
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <branch-or-path> [branch-or-path] [-- path...]",
+	Short: "Compare two worktrees",
+	Long: `Show the differences between two worktrees, e.g. two approaches to the
+same problem in parallel branches. With one argument, the current worktree
+is compared to it.
+
+By default the worktrees' HEADs are compared. With --working, uncommitted
+changes to tracked files are included on both sides (untracked files are
+not). With --tool, 'git difftool --dir-diff' opens your configured difftool.
+
+  sprout diff approach-a approach-b
+  sprout diff approach-b --working -- internal/api`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if n := worktreeArgCount(cmd, args); n < 1 || n > 2 {
+			return fmt.Errorf("expected one or two worktrees, got %d", n)
+		}
+		return nil
+	},
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= 2 || cmd.ArgsLenAtDash() >= 0 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return completeSproutWorktrees(toComplete)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+
+		n := worktreeArgCount(cmd, args)
+		ctx, err := BuildDiffContext(fx, args[:n], args[n:], DiffOptions{
+			Working: diffWorkingFlag,
+			Tool:    diffToolFlag,
+			Stat:    diffStatFlag,
+		})
+		if err != nil {
+			exitWithError(err)
+		}
+
+		plan := core.PlanDiffCommand(ctx)
+		runPlan(plan, fx)
+	},
+}
+
+// worktreeArgCount returns how many arguments name worktrees; the rest,
+// after "--", are paths.
+func worktreeArgCount(cmd *cobra.Command, args []string) int {
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		return dash
+	}
+	return len(args)
+}
+
+// BuildDiffContext resolves the worktrees to compare (the current one when
+// only one is given) and the revision to use for each.
+func BuildDiffContext(fx effects.Effects, targets, paths []string, opts DiffOptions) (core.DiffContext, error) {
+	repoRoot, err := fx.GetRepoRoot()
+	if err != nil {
+		return core.DiffContext{}, fmt.Errorf("not a git repository: %w", err)
+	}
+
+	mainWorktreePath, err := fx.GetMainWorktreePath()
+	if err != nil {
+		return core.DiffContext{}, fmt.Errorf("failed to get main worktree: %w", err)
+	}
+
+	sproutRoot, err := fx.GetWorktreeRoot(mainWorktreePath)
+	if err != nil {
+		return core.DiffContext{}, fmt.Errorf("failed to get sprout root: %w", err)
+	}
+
+	worktrees, err := fx.ListWorktrees(repoRoot)
+	if err != nil {
+		return core.DiffContext{}, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	adopted, err := fx.ListAdoptedWorktrees()
+	if err != nil {
+		return core.DiffContext{}, fmt.Errorf("failed to load adopted worktrees: %w", err)
+	}
+
+	if len(targets) == 1 {
+		targets = []string{repoRoot, targets[0]}
+	}
+
+	var sides [2]core.DiffSide
+	for i, target := range targets {
+		wt, err := resolveDiffWorktree(fx, worktrees, sproutRoot, adopted, target)
+		if err != nil {
+			return core.DiffContext{}, err
+		}
+		sides[i], err = diffSide(fx, wt, opts.Working)
+		if err != nil {
+			return core.DiffContext{}, err
+		}
+	}
+
+	return core.DiffContext{
+		RepoRoot: repoRoot,
+		From:     sides[0],
+		To:       sides[1],
+		Tool:     opts.Tool,
+		Stat:     opts.Stat,
+		Paths:    paths,
+	}, nil
+}
+
+// resolveDiffWorktree finds the worktree for a path or branch. Besides
+// sprout worktrees, the main worktree's branch is accepted, since comparing
+// against main is common.
+func resolveDiffWorktree(fx effects.Effects, worktrees []git.Worktree, sproutRoot string, adopted []string, target string) (git.Worktree, error) {
+	if effects.Exists(fx, target) {
+		abs := target
+		if a, err := filepath.Abs(target); err == nil {
+			abs = a
+		}
+		for _, wt := range worktrees {
+			if filepath.Clean(wt.Path) == filepath.Clean(abs) {
+				return wt, nil
+			}
+		}
+		return git.Worktree{}, fmt.Errorf("%s: %w", target, core.ErrNotAWorktree)
+	}
+
+	if path, found := core.FindWorktreeByBranch(worktrees, sproutRoot, target, adopted...); found {
+		for _, wt := range worktrees {
+			if wt.Path == path {
+				return wt, nil
+			}
+		}
+	}
+	if len(worktrees) > 0 && core.MatchWorktreeBranch(worktrees[:1], target) == 0 {
+		return worktrees[0], nil
+	}
+	return git.Worktree{}, fmt.Errorf("no worktree found for branch '%s'", target)
+}
+
+// diffSide picks the revision to compare for a worktree: its branch (or
+// commit when detached), or with working set a snapshot of its uncommitted
+// changes made by 'git stash create', which leaves the worktree untouched.
+func diffSide(fx effects.Effects, wt git.Worktree, working bool) (core.DiffSide, error) {
+	side := core.DiffSide{Path: wt.Path, Rev: wt.Branch}
+	if side.Rev == "" {
+		side.Rev = wt.HEAD
+	}
+	if !working {
+		return side, nil
+	}
+
+	snapshot, err := fx.RunGitCommand(wt.Path, "stash", "create")
+	if err != nil {
+		return core.DiffSide{}, fmt.Errorf("failed to snapshot changes in %s: %w", wt.Path, err)
+	}
+	if snapshot != "" { // Empty when there are no changes
+		side.Rev = snapshot
+	}
+	return side, nil
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().BoolVar(&diffWorkingFlag, "working", false, "Compare working trees, including uncommitted changes to tracked files")
+	diffCmd.Flags().BoolVar(&diffToolFlag, "tool", false, "Open git difftool --dir-diff instead of printing the diff")
+	diffCmd.Flags().BoolVar(&diffStatFlag, "stat", false, "Only show a diffstat")
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildDiffContext(t *testing.T) {
+	t.Parallel()
+
+	newFx := func() *effects.TestEffects {
+		fx := effects.NewTestEffects()
+		fx.WorktreeRoot = "/sprout/repo-1234"
+		fx.Worktrees = []git.Worktree{
+			{Path: "/test/repo", Branch: "main"},
+			{Path: "/sprout/repo-1234/approach-a/repo", Branch: "approach-a"},
+			{Path: "/sprout/repo-1234/approach-b/repo", Branch: "approach-b"},
+			{Path: "/sprout/repo-1234/spike/repo", HEAD: "abc1234"},
+		}
+		return fx
+	}
+
+	t.Run("two branches", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+
+		ctx, err := BuildDiffContext(fx, []string{"approach-a", "approach-b"}, []string{"go.mod"}, DiffOptions{Stat: true})
+		require.NoError(t, err)
+		assert.Equal(t, core.DiffSide{Path: "/sprout/repo-1234/approach-a/repo", Rev: "approach-a"}, ctx.From)
+		assert.Equal(t, core.DiffSide{Path: "/sprout/repo-1234/approach-b/repo", Rev: "approach-b"}, ctx.To)
+		assert.Equal(t, []string{"go.mod"}, ctx.Paths)
+		assert.True(t, ctx.Stat)
+	})
+
+	t.Run("one argument compares the current worktree", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.Files["/test/repo"] = true
+
+		ctx, err := BuildDiffContext(fx, []string{"approach-b"}, nil, DiffOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "main", ctx.From.Rev)
+		assert.Equal(t, "approach-b", ctx.To.Rev)
+	})
+
+	t.Run("main branch and detached worktree by path", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.Files["/sprout/repo-1234/spike/repo"] = true
+
+		ctx, err := BuildDiffContext(fx, []string{"main", "/sprout/repo-1234/spike/repo"}, nil, DiffOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "main", ctx.From.Rev)
+		assert.Equal(t, "abc1234", ctx.To.Rev)
+	})
+
+	t.Run("working trees use stash snapshots", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.GitCommandOutput["/sprout/repo-1234/approach-a/repo\nstash create"] = "f00dbabe"
+
+		ctx, err := BuildDiffContext(fx, []string{"approach-a", "approach-b"}, nil, DiffOptions{Working: true})
+		require.NoError(t, err)
+		assert.Equal(t, "f00dbabe", ctx.From.Rev)
+		assert.Equal(t, "approach-b", ctx.To.Rev, "clean worktree falls back to its branch")
+	})
+
+	t.Run("unknown branch", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+
+		_, err := BuildDiffContext(fx, []string{"approach-a", "nope"}, nil, DiffOptions{})
+		assert.ErrorContains(t, err, "no worktree found for branch 'nope'")
+	})
+
+	t.Run("path outside the repository", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.Files["/tmp/elsewhere"] = true
+
+		_, err := BuildDiffContext(fx, []string{"approach-a", "/tmp/elsewhere"}, nil, DiffOptions{})
+		assert.ErrorIs(t, err, core.ErrNotAWorktree)
+	})
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/sprout"
+
+	"github.com/spf13/cobra"
+)
+
+var envShellFlag string
+
+// envVarNames are the variables 'sprout env' knows, in the order it prints them.
+var envVarNames = []string{
+	"SPROUT_ROOT",
+	"SPROUT_REPO_ROOT",
+	"SPROUT_WORKTREE_PATH",
+	"SPROUT_MAIN_WORKTREE_PATH",
+	"SPROUT_WORKTREE_ROOT",
+	"SPROUT_BRANCH",
+	"SPROUT_BASE_BRANCH",
+	"SPROUT_WORKTREE_NAME",
+	"SPROUT_WORKTREE_INDEX",
+	"SPROUT_PORT_BASE",
+	"SPROUT_SCRATCH",
+}
+
+var envCmd = &cobra.Command{
+	Use:   "env [NAME...]",
+	Short: "Print the sprout context as shell variables",
+	Long: `Print the worktree you are in as the SPROUT_* variables hooks get, as
+shell commands that export them:
+
+  eval "$(sprout env)"
+
+Outside a repository only SPROUT_ROOT is set. In fish, or with --shell
+fish, the commands are for fish:
+
+  sprout env | source
+
+Name variables to print only their values, one per line, such as in a
+Makefile:
+
+  PORT := $(shell sprout env SPROUT_PORT_BASE)`,
+	ValidArgs: envVarNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+		ctx, err := BuildEnvContext(fx, args, envShellFlag)
+		if err != nil {
+			exitWithError(err)
+		}
+		runPlan(core.PlanEnvCommand(ctx), fx)
+	},
+}
+
+// BuildEnvContext gathers the sprout context of the current directory.
+// shell is "sh" or "fish"; empty picks fish only when it is $SHELL.
+func BuildEnvContext(fx effects.Effects, names []string, shell string) (core.EnvContext, error) {
+	if shell == "" {
+		shell = "sh"
+		if detectShell() == "fish" {
+			shell = "fish"
+		}
+	}
+	if shell != "sh" && shell != "fish" {
+		return core.EnvContext{}, fmt.Errorf("unknown shell %q; sprout env prints sh or fish", shell)
+	}
+	ctx := core.EnvContext{Names: names, Fish: shell == "fish"}
+
+	sproutRoot, err := fx.GetSproutRoot()
+	if err != nil {
+		return core.EnvContext{}, err
+	}
+	ctx.Vars = append(ctx.Vars, core.EnvVar{Name: "SPROUT_ROOT", Value: sproutRoot})
+
+	repoRoot, err := fx.GetRepoRoot()
+	if err != nil {
+		return ctx, nil
+	}
+	mainWorktreePath, err := fx.GetMainWorktreePath()
+	if err != nil {
+		return core.EnvContext{}, err
+	}
+	worktreeRoot, err := fx.GetWorktreeRoot(mainWorktreePath)
+	if err != nil {
+		return core.EnvContext{}, err
+	}
+	branch, err := fx.RunGitCommand(repoRoot, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil || branch == "HEAD" {
+		branch = ""
+	}
+	var baseBranch string
+	if cfg, err := fx.LoadConfig(repoRoot, mainWorktreePath); err == nil && cfg.DefaultBranch != "" {
+		baseBranch = cfg.DefaultBranch
+	} else {
+		baseBranch, _ = fx.GetDefaultBranch(mainWorktreePath)
+	}
+
+	vars := map[string]string{
+		"SPROUT_REPO_ROOT":          repoRoot,
+		"SPROUT_WORKTREE_PATH":      repoRoot,
+		"SPROUT_MAIN_WORKTREE_PATH": mainWorktreePath,
+		"SPROUT_WORKTREE_ROOT":      worktreeRoot,
+		"SPROUT_BRANCH":             branch,
+		"SPROUT_BASE_BRANCH":        baseBranch,
+		"SPROUT_WORKTREE_NAME":      core.WorktreeName(branch, repoRoot),
+	}
+	// Like hooks, leave out what can't be known; unlike them, env doesn't
+	// make the scratch directory
+	if index, err := fx.WorktreeIndex(mainWorktreePath, repoRoot); err == nil {
+		vars["SPROUT_WORKTREE_INDEX"] = strconv.Itoa(index)
+		vars["SPROUT_PORT_BASE"] = strconv.Itoa(sprout.PortBase(index))
+	}
+	if scratch := sprout.ScratchDir(sproutRoot, repoRoot); effects.Exists(fx, scratch) {
+		vars["SPROUT_SCRATCH"] = scratch
+	}
+	for _, name := range envVarNames[1:] {
+		if value, ok := vars[name]; ok {
+			ctx.Vars = append(ctx.Vars, core.EnvVar{Name: name, Value: value})
+		}
+	}
+	return ctx, nil
+}
+
+func init() {
+	envCmd.Flags().StringVar(&envShellFlag, "shell", "", "Print commands for this shell: sh or fish (default: fish when $SHELL is fish, else sh)")
+	rootCmd.AddCommand(envCmd)
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildEnvContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("in a worktree", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+		fx.RepoRoot = "/sprout/repo-1234/feat-login/repo"
+		fx.SproutRoot = "/sprout"
+		fx.WorktreeRoot = "/sprout/repo-1234"
+		fx.WorktreeIndices["/sprout/repo-1234/feat-login/repo"] = 2
+		fx.GitCommandOutput["/sprout/repo-1234/feat-login/repo\nrev-parse --abbrev-ref HEAD"] = "feat/login"
+
+		ctx, err := BuildEnvContext(fx, nil, "sh")
+		require.NoError(t, err)
+		assert.False(t, ctx.Fish)
+		assert.Equal(t, []core.EnvVar{
+			{Name: "SPROUT_ROOT", Value: "/sprout"},
+			{Name: "SPROUT_REPO_ROOT", Value: "/sprout/repo-1234/feat-login/repo"},
+			{Name: "SPROUT_WORKTREE_PATH", Value: "/sprout/repo-1234/feat-login/repo"},
+			{Name: "SPROUT_MAIN_WORKTREE_PATH", Value: "/test/repo"},
+			{Name: "SPROUT_WORKTREE_ROOT", Value: "/sprout/repo-1234"},
+			{Name: "SPROUT_BRANCH", Value: "feat/login"},
+			{Name: "SPROUT_BASE_BRANCH", Value: "main"},
+			{Name: "SPROUT_WORKTREE_NAME", Value: "feat-login"},
+			{Name: "SPROUT_WORKTREE_INDEX", Value: "2"},
+			{Name: "SPROUT_PORT_BASE", Value: "3020"},
+		}, ctx.Vars)
+	})
+
+	t.Run("outside a repository", func(t *testing.T) {
+		t.Parallel()
+		fx := effects.NewTestEffects()
+		fx.GetRepoRootErr = errors.New("not a git repository")
+		fx.SproutRoot = "/sprout"
+
+		ctx, err := BuildEnvContext(fx, []string{"SPROUT_BRANCH"}, "fish")
+		require.NoError(t, err)
+		assert.True(t, ctx.Fish)
+		assert.Equal(t, []string{"SPROUT_BRANCH"}, ctx.Names)
+		assert.Equal(t, []core.EnvVar{{Name: "SPROUT_ROOT", Value: "/sprout"}}, ctx.Vars)
+	})
+
+	t.Run("unknown shell", func(t *testing.T) {
+		t.Parallel()
+		_, err := BuildEnvContext(baseTestFx(), nil, "nu")
+		assert.EqualError(t, err, `unknown shell "nu"; sprout env prints sh or fish`)
+	})
+}
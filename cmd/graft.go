@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/m44rten1/sprout/internal/build"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	graftNoHooksFlag bool
+	graftNoOpenFlag  bool
+	graftOpenFlag    bool
+	graftTrustFlag   bool
+)
+
+var graftCmd = &cobra.Command{
+	Use:   "graft [branch]",
+	Short: "Move uncommitted changes into a new worktree",
+	Long: `Started on the wrong branch? graft moves the uncommitted changes of the
+current worktree, untracked files included, into a new worktree for another
+branch, leaving the current one clean.
+
+The new worktree is created like 'sprout add' does. If the changes don't
+apply cleanly on the new branch, the conflicts are left to resolve there and
+the changes stay in 'git stash list'.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+		defer lockRepo(fx)()
+
+		ctx, err := BuildGraftContext(fx, args, build.AddOptions{
+			NoHooks: graftNoHooksFlag,
+			NoOpen:  graftNoOpenFlag,
+			Open:    graftOpenFlag,
+			Trust:   graftTrustFlag,
+		})
+		if err != nil {
+			exitWithError(err)
+		}
+
+		plan := core.PlanGraftCommand(ctx)
+		runPlan(plan, fx)
+	},
+}
+
+// BuildGraftContext gathers the inputs of the add command for the target
+// branch, grafting from the current worktree. It fails early when there are
+// no changes to move.
+func BuildGraftContext(fx effects.Effects, args []string, opts build.AddOptions) (core.AddContext, error) {
+	repoRoot, err := fx.GetRepoRoot()
+	if err != nil {
+		return core.AddContext{}, fmt.Errorf("not a git repository: %w", err)
+	}
+
+	status, err := fx.RunGitCommand(repoRoot, "status", "--porcelain")
+	if err != nil {
+		return core.AddContext{}, fmt.Errorf("failed to check for changes: %w", err)
+	}
+	if strings.TrimSpace(status) == "" {
+		return core.AddContext{}, fmt.Errorf("no uncommitted changes to graft in %s", repoRoot)
+	}
+
+	ctx, err := build.AddContext(fx, args, opts)
+	if err != nil {
+		return core.AddContext{}, err
+	}
+	ctx.GraftFrom = repoRoot
+	return ctx, nil
+}
+
+func init() {
+	rootCmd.AddCommand(graftCmd)
+	graftCmd.ValidArgsFunction = addCmd.ValidArgsFunction
+	graftCmd.Flags().BoolVar(&graftNoHooksFlag, "no-hooks", false, "Skip running on_create hooks even if .sprout.yml exists")
+	graftCmd.Flags().BoolVar(&graftNoOpenFlag, "no-open", false, "Skip opening the worktree in an editor")
+	graftCmd.Flags().BoolVar(&graftOpenFlag, "open", false, "Open the worktree in an editor even if open_editor is false")
+	graftCmd.MarkFlagsMutuallyExclusive("open", "no-open")
+	graftCmd.Flags().BoolVar(&graftTrustFlag, "trust", false, "Trust this repository's hooks without prompting (for scripted use)")
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/build"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildGraftContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("grafts from the current worktree", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+		fx.GitCommandOutput["/test/repo\nstatus --porcelain"] = " M main.go\n?? notes.txt"
+
+		ctx, err := BuildGraftContext(fx, []string{"feature"}, build.AddOptions{NoOpen: true})
+		require.NoError(t, err)
+		assert.Equal(t, "/test/repo", ctx.GraftFrom)
+		assert.Equal(t, "feature", ctx.Branch)
+		assert.True(t, ctx.NoOpen)
+	})
+
+	t.Run("nothing to graft", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+
+		_, err := BuildGraftContext(fx, []string{"feature"}, build.AddOptions{})
+		assert.ErrorContains(t, err, "no uncommitted changes to graft")
+		assert.Zero(t, fx.GetWorktreePathCalls, "fails before looking at the branch")
+	})
+}
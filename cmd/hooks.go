@@ -1,135 +1,136 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
+	"sort"
+	"time"
 
-	"github.com/m44rten1/sprout/internal/config"
-	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
 	"github.com/m44rten1/sprout/internal/trust"
 
 	"github.com/spf13/cobra"
 )
 
+var hooksJSONFlag bool
+
 var hooksCmd = &cobra.Command{
 	Use:   "hooks",
 	Short: "Show hook configuration status",
 	Long: `Display information about hooks for the current repository:
 - Whether .sprout.yml exists
 - Trust status
-- Which hooks are defined`,
-	Run: func(cmd *cobra.Command, args []string) {
-		// Get repo root
-		repoRoot, err := git.GetRepoRoot()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
-
-		fmt.Println()
-		fmt.Printf("Repository: %s\n", repoRoot)
-		fmt.Println()
-
-		// Get main worktree path for config fallback
-		mainWorktreePath, err := git.GetMainWorktreePath()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to get main worktree path: %v\n", err)
-			os.Exit(1)
-		}
-
-		// Check if .sprout.yml exists in current or main worktree
-		configPath := filepath.Join(repoRoot, ".sprout.yml")
-		_, err = os.Stat(configPath)
-		configExists := err == nil
-
-		if !configExists && mainWorktreePath != repoRoot {
-			configPath = filepath.Join(mainWorktreePath, ".sprout.yml")
-			_, err = os.Stat(configPath)
-			configExists = err == nil
-		}
-
-		if !configExists {
-			fmt.Println("❌ No .sprout.yml found")
-			fmt.Println()
-			fmt.Println("To add hooks, create a .sprout.yml file in your repository root.")
-			fmt.Println("Example:")
-			fmt.Println()
-			fmt.Println("  hooks:")
-			fmt.Println("    on_create:")
-			fmt.Println("      - npm ci")
-			fmt.Println("      - npm run build")
-			fmt.Println("    on_open:")
-			fmt.Println("      - npm run lint:types")
-			fmt.Println()
-			return
-		}
-
-		fmt.Printf("✅ Config file: %s\n", configPath)
-		fmt.Println()
-
-		// Check trust status
-		isTrusted, err := trust.IsRepoTrusted(repoRoot)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to check trust status: %v\n", err)
-			os.Exit(1)
-		}
+- Which hooks are defined
+- How the last run of each went in every worktree, command by command
 
-		if isTrusted {
-			fmt.Println("✅ Repository is trusted")
-		} else {
-			fmt.Println("🔒 Repository is NOT trusted")
-			fmt.Println()
-			fmt.Println("Run 'sprout trust' to enable hooks for this repository.")
-		}
-		fmt.Println()
+With --json, the same information is printed as a JSON object, including a
+hash of the hook commands, for editor extensions and CI checks. Errors are
+printed as {"error": "..."} too, with the args, exit code and stderr of the
+git command under "git" when git failed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
 
-		// Load and display hooks
-		cfg, err := config.Load(repoRoot, mainWorktreePath)
+		ctx, err := BuildHooksContext(fx, hooksJSONFlag)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
-			os.Exit(1)
+			if hooksJSONFlag {
+				exitWithJSONError(err)
+			}
+			exitWithError(err)
 		}
 
-		if !cfg.HasHooks() {
-			fmt.Println("ℹ️  No hooks defined")
-			return
-		}
+		plan := core.PlanHooksCommand(ctx)
+		runPlan(plan, fx)
+	},
+}
 
-		// Display on_create hooks
-		if cfg.HasCreateHooks() {
-			fmt.Println("on_create hooks:")
-			for i, cmd := range cfg.Hooks.OnCreate {
-				fmt.Printf("  %d. %s\n", i+1, cmd)
-			}
-			fmt.Println()
-		}
+// BuildHooksContext gathers all inputs needed to plan the hooks command.
+// The config is looked up in the current worktree first, then in the main
+// worktree; trust and hooks are only inspected if a config file exists.
+func BuildHooksContext(fx effects.Effects, jsonOutput bool) (core.HooksContext, error) {
+	repoRoot, err := fx.GetRepoRoot()
+	if err != nil {
+		return core.HooksContext{}, err
+	}
+
+	// Get main worktree path for config fallback
+	mainWorktreePath, err := fx.GetMainWorktreePath()
+	if err != nil {
+		return core.HooksContext{}, fmt.Errorf("failed to get main worktree path: %w", err)
+	}
+
+	ctx := core.HooksContext{
+		RepoRoot:         repoRoot,
+		MainWorktreePath: mainWorktreePath,
+		JSON:             jsonOutput,
+	}
+
+	// Check if .sprout.yml exists in current or main worktree
+	ctx.ConfigPath = filepath.Join(repoRoot, ".sprout.yml")
+	ctx.ConfigExists = effects.Exists(fx, ctx.ConfigPath)
+	if !ctx.ConfigExists && mainWorktreePath != repoRoot {
+		ctx.ConfigPath = filepath.Join(mainWorktreePath, ".sprout.yml")
+		ctx.ConfigExists = effects.Exists(fx, ctx.ConfigPath)
+	}
+	if !ctx.ConfigExists {
+		return ctx, nil
+	}
+
+	// Trust is keyed by the main worktree, matching add/open/trust, and
+	// covers the hooks of the config shown
+	ctx.IsTrusted, err = fx.IsTrusted(mainWorktreePath, repoRoot)
+	ctx.DeniedByPolicy = errors.Is(err, trust.ErrDeniedByPolicy)
+	if err != nil && !ctx.DeniedByPolicy {
+		return core.HooksContext{}, fmt.Errorf("failed to check trust status: %w", err)
+	}
+
+	ctx.Config, err = fx.LoadConfig(repoRoot, mainWorktreePath)
+	if err != nil {
+		return core.HooksContext{}, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ctx.LastRuns = collectHookRuns(fx, mainWorktreePath)
+	ctx.Now = time.Now()
+	return ctx, nil
+}
 
-		// Display on_open hooks
-		if cfg.HasOpenHooks() {
-			fmt.Println("on_open hooks:")
-			for i, cmd := range cfg.Hooks.OnOpen {
-				fmt.Printf("  %d. %s\n", i+1, cmd)
+// collectHookRuns returns the recorded hook runs of the repository's
+// worktrees. Without metadata there is nothing to show, which is no error.
+func collectHookRuns(fx effects.Effects, mainWorktreePath string) []core.HookRunSummary {
+	worktrees, err := fx.ListWorktrees(mainWorktreePath)
+	if err != nil {
+		return nil
+	}
+	metadata, err := fx.LoadWorktreeMetadata()
+	if err != nil {
+		return nil
+	}
+
+	var runs []core.HookRunSummary
+	for _, wt := range worktrees {
+		for hookType, run := range metadata[wt.Path].Hooks {
+			summary := core.HookRunSummary{WorktreePath: wt.Path, Branch: wt.Branch, Type: core.HookType(hookType), At: run.At}
+			for _, cmd := range run.Commands {
+				summary.Commands = append(summary.Commands, core.HookCommandResult{
+					Command:    cmd.Command,
+					DurationMS: cmd.Duration.Milliseconds(),
+					ExitCode:   cmd.ExitCode,
+				})
 			}
-			fmt.Println()
+			runs = append(runs, summary)
 		}
-
-		// Show how hooks are triggered
-		if isTrusted {
-			fmt.Println("Hooks run automatically when:")
-			if cfg.HasCreateHooks() {
-				fmt.Println("  - sprout add           (runs on_create)")
-			}
-			if cfg.HasOpenHooks() {
-				fmt.Println("  - sprout open          (runs on_open)")
-			}
-			fmt.Println()
-			fmt.Println("Use --no-hooks flag to skip automatic execution.")
-			fmt.Println()
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if runs[i].WorktreePath != runs[j].WorktreePath {
+			return runs[i].WorktreePath < runs[j].WorktreePath
 		}
-	},
+		return runs[i].Type < runs[j].Type
+	})
+	return runs
 }
 
 func init() {
 	rootCmd.AddCommand(hooksCmd)
+	hooksCmd.Flags().BoolVar(&hooksJSONFlag, "json", false, "Print hook configuration as JSON")
 }
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/sprout"
+	"github.com/m44rten1/sprout/internal/trust"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildHooksContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no config skips trust and config loading", func(t *testing.T) {
+		t.Parallel()
+		fx := effects.NewTestEffects()
+
+		ctx, err := BuildHooksContext(fx, false)
+		require.NoError(t, err)
+		assert.False(t, ctx.ConfigExists)
+		assert.Equal(t, 0, fx.IsTrustedCalls)
+		assert.Equal(t, 0, fx.LoadConfigCalls)
+	})
+
+	t.Run("falls back to main worktree config", func(t *testing.T) {
+		t.Parallel()
+		fx := effects.NewTestEffects()
+		fx.RepoRoot = "/wt/feature"
+		fx.MainWorktreePath = "/test/repo"
+		fx.Files["/test/repo/.sprout.yml"] = true
+		fx.TrustedRepos["/test/repo"] = true
+		fx.Config = &config.Config{Hooks: config.HooksConfig{OnCreate: []string{"npm ci"}}}
+
+		ctx, err := BuildHooksContext(fx, true)
+		require.NoError(t, err)
+		assert.True(t, ctx.ConfigExists)
+		assert.Equal(t, "/test/repo/.sprout.yml", ctx.ConfigPath)
+		assert.True(t, ctx.IsTrusted)
+		assert.True(t, ctx.JSON)
+		assert.Equal(t, []string{"/test/repo"}, fx.IsTrustedArgs)
+	})
+
+	t.Run("policy denial is not an error", func(t *testing.T) {
+		t.Parallel()
+		fx := effects.NewTestEffects()
+		fx.Files["/test/repo/.sprout.yml"] = true
+		fx.IsTrustedErr = trust.ErrDeniedByPolicy
+
+		ctx, err := BuildHooksContext(fx, false)
+		require.NoError(t, err)
+		assert.True(t, ctx.DeniedByPolicy)
+		assert.False(t, ctx.IsTrusted)
+	})
+
+	t.Run("collects the last hook runs of the repository's worktrees", func(t *testing.T) {
+		t.Parallel()
+		fx := effects.NewTestEffects()
+		fx.Files["/test/repo/.sprout.yml"] = true
+		fx.Worktrees = []git.Worktree{
+			{Path: "/test/repo", Branch: "main"},
+			{Path: "/wt/feature", Branch: "feature"},
+		}
+		at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		fx.WorktreeMetadata["/wt/feature"] = sprout.WorktreeMeta{Hooks: map[string]sprout.HookRun{
+			"on_open":   {At: at, Commands: []sprout.HookCommandRun{{Command: "make", Duration: 1500 * time.Millisecond}}},
+			"on_create": {At: at, Commands: []sprout.HookCommandRun{{Command: "npm ci", Duration: time.Second, ExitCode: 1}}},
+		}}
+		fx.WorktreeMetadata["/other/repo/wt"] = sprout.WorktreeMeta{Hooks: map[string]sprout.HookRun{"on_create": {At: at}}}
+
+		ctx, err := BuildHooksContext(fx, false)
+		require.NoError(t, err)
+		assert.Equal(t, []core.HookRunSummary{
+			{WorktreePath: "/wt/feature", Branch: "feature", Type: core.HookTypeOnCreate, At: at, Commands: []core.HookCommandResult{{Command: "npm ci", DurationMS: 1000, ExitCode: 1}}},
+			{WorktreePath: "/wt/feature", Branch: "feature", Type: core.HookTypeOnOpen, At: at, Commands: []core.HookCommandResult{{Command: "make", DurationMS: 1500}}},
+		}, ctx.LastRuns)
+	})
+
+	t.Run("trust errors are reported", func(t *testing.T) {
+		t.Parallel()
+		fx := effects.NewTestEffects()
+		fx.Files["/test/repo/.sprout.yml"] = true
+		fx.IsTrustedErr = errors.New("store corrupted")
+
+		_, err := BuildHooksContext(fx, false)
+		assert.ErrorContains(t, err, "failed to check trust status")
+	})
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/sprout"
+
+	"github.com/spf13/cobra"
+)
+
+var infoPorcelain string
+
+var infoCmd = &cobra.Command{
+	Use:   "info [branch-or-path]",
+	Short: "Show details about a worktree",
+	Long: `Show details about a worktree, including the stable index and port range
+sprout assigns to it. Hooks receive these as SPROUT_WORKTREE_INDEX and
+SPROUT_PORT_BASE, so each worktree can run its own dev servers.
+
+If no argument is provided, the worktree you are currently in is shown.
+
+With --porcelain=v1, the details are printed as "key<TAB>value" lines for
+scripts, always with these keys in this order: repository, worktree,
+branch, base, index, port_base, port_count and ticket.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+		porcelain := porcelainFormat(infoPorcelain)
+
+		ctx, err := BuildInfoContext(fx, args)
+		if err != nil {
+			exitWithError(err)
+		}
+		ctx.Porcelain = porcelain
+
+		plan := core.PlanInfoCommand(ctx)
+		runPlan(plan, fx)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+	addPorcelainFlag(infoCmd, &infoPorcelain)
+}
+
+// BuildInfoContext gathers all inputs needed to plan the info command.
+// The argument is treated as a path if it exists, otherwise as a branch name.
+// Looking up a worktree's index assigns one if it has none yet.
+func BuildInfoContext(fx effects.Effects, args []string) (core.InfoContext, error) {
+	repoRoot, err := fx.GetRepoRoot()
+	if err != nil {
+		return core.InfoContext{}, fmt.Errorf("not a git repository: %w", err)
+	}
+
+	mainWorktreePath, err := fx.GetMainWorktreePath()
+	if err != nil {
+		return core.InfoContext{}, fmt.Errorf("failed to get main worktree: %w", err)
+	}
+
+	worktrees, err := fx.ListWorktrees(repoRoot)
+	if err != nil {
+		return core.InfoContext{}, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	// Resolve the target: current worktree, an existing path, or a branch name
+	target := repoRoot
+	byBranch := false
+	if len(args) > 0 {
+		target = args[0]
+		if effects.Exists(fx, target) {
+			if abs, err := filepath.Abs(target); err == nil {
+				target = abs
+			}
+		} else {
+			byBranch = true
+		}
+	}
+
+	ctx := core.InfoContext{MainWorktreePath: mainWorktreePath}
+	if byBranch {
+		if i := core.MatchWorktreeBranch(worktrees, target); i >= 0 {
+			ctx.WorktreePath = worktrees[i].Path
+			ctx.Branch = worktrees[i].Branch
+		}
+	} else {
+		for _, wt := range worktrees {
+			if filepath.Clean(wt.Path) == filepath.Clean(target) {
+				ctx.WorktreePath = wt.Path
+				ctx.Branch = wt.Branch
+				break
+			}
+		}
+	}
+	if ctx.WorktreePath == "" {
+		if byBranch {
+			return core.InfoContext{}, fmt.Errorf("no worktree found for branch '%s'", target)
+		}
+		return core.InfoContext{}, fmt.Errorf("%s: %w", target, core.ErrNotAWorktree)
+	}
+
+	index, err := fx.WorktreeIndex(mainWorktreePath, ctx.WorktreePath)
+	if err != nil {
+		return core.InfoContext{}, fmt.Errorf("failed to get worktree index: %w", err)
+	}
+	ctx.Index = index
+	ctx.PortBase = sprout.PortBase(index)
+	ctx.PortCount = sprout.PortStride
+	if root, err := fx.GetSproutRoot(); err == nil {
+		ctx.Scratch = sprout.ScratchDir(root, ctx.WorktreePath)
+	}
+
+	// The ticket is informational; an unreadable store is not worth failing over
+	if recorded, err := fx.LoadTickets(); err == nil {
+		ctx.Ticket = recorded[ctx.WorktreePath]
+	}
+	if metadata, err := fx.LoadWorktreeMetadata(); err == nil {
+		ctx.Base = metadata[ctx.WorktreePath].Base
+	}
+
+	return ctx, nil
+}
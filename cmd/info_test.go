@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildInfoContext(t *testing.T) {
+	t.Parallel()
+
+	newFx := func() *effects.TestEffects {
+		fx := effects.NewTestEffects()
+		fx.RepoRoot = "/test/repo"
+		fx.MainWorktreePath = "/test/repo"
+		fx.Worktrees = []git.Worktree{
+			{Path: "/test/repo", Branch: "main"},
+			{Path: "/sprout/repo-1234/feature/repo", Branch: "feature"},
+			{Path: "/sprout/repo-1234/other/repo", Branch: "other"},
+		}
+		return fx
+	}
+
+	t.Run("main worktree has index zero", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+
+		ctx, err := BuildInfoContext(fx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "/test/repo", ctx.WorktreePath)
+		assert.Equal(t, "main", ctx.Branch)
+		assert.Equal(t, 0, ctx.Index)
+		assert.Equal(t, 3000, ctx.PortBase)
+	})
+
+	t.Run("branch lookup keeps existing assignment", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.WorktreeIndices["/sprout/repo-1234/feature/repo"] = 3
+
+		ctx, err := BuildInfoContext(fx, []string{"feature"})
+		require.NoError(t, err)
+		assert.Equal(t, "/sprout/repo-1234/feature/repo", ctx.WorktreePath)
+		assert.Equal(t, 3, ctx.Index)
+		assert.Equal(t, 3030, ctx.PortBase)
+		assert.Equal(t, 10, ctx.PortCount)
+	})
+
+	t.Run("unassigned worktree gets lowest free index", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.WorktreeIndices["/sprout/repo-1234/feature/repo"] = 1
+
+		ctx, err := BuildInfoContext(fx, []string{"other"})
+		require.NoError(t, err)
+		assert.Equal(t, 2, ctx.Index)
+		assert.Equal(t, 1, fx.WorktreeIndexCalls)
+	})
+
+	t.Run("branch in another case, with a remote prefix", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, err := BuildInfoContext(newFx(), []string{"origin/Feature"})
+		require.NoError(t, err)
+		assert.Equal(t, "/sprout/repo-1234/feature/repo", ctx.WorktreePath)
+		assert.Equal(t, "feature", ctx.Branch)
+	})
+
+	t.Run("unknown branch", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+
+		_, err := BuildInfoContext(fx, []string{"missing"})
+		assert.ErrorContains(t, err, "no worktree found for branch 'missing'")
+	})
+
+	t.Run("path that is not a worktree", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.Files["/tmp/elsewhere"] = true
+
+		_, err := BuildInfoContext(fx, []string{"/tmp/elsewhere"})
+		assert.ErrorIs(t, err, core.ErrNotAWorktree)
+	})
+}
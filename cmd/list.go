@@ -1,20 +1,30 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"maps"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 
+	"github.com/m44rten1/sprout/internal/build"
 	"github.com/m44rten1/sprout/internal/core"
 	"github.com/m44rten1/sprout/internal/effects"
-	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/style"
 
 	"github.com/spf13/cobra"
 )
 
-var listAllFlag bool
+var (
+	listAllFlag      bool
+	listGroupFlag    string
+	listCollapseFlag bool
+	listDetailsFlag  bool
+	listPorcelain    string
+	listWorkspace    bool
+)
 
 var listCmd = &cobra.Command{
 	Use:   "list",
@@ -22,34 +32,89 @@ var listCmd = &cobra.Command{
 	Long: `List worktrees for the current repository or all repositories.
 
 Status indicators show the git state of each worktree:
-  ` + "\033[31m✗\033[0m" + `  Dirty - worktree has uncommitted changes
-  ` + "\033[33m↑\033[0m" + `  Ahead - worktree has unpushed commits
-  ` + "\033[36m↓\033[0m" + `  Behind - worktree needs to pull
-  ` + "\033[35m↕\033[0m" + `  Unmerged - worktree has commits not in main/master branch
-
-Multiple indicators can appear together (e.g., ` + "\033[31m✗\033[0m \033[35m↕\033[0m" + ` means dirty and unmerged).
-Clean worktrees show no indicators.`,
+  ` + style.Red("✗") + `  Dirty - worktree has modified or staged changes
+  ` + style.Gray("?") + `  Untracked - worktree only has new, untracked files
+  ` + style.Yellow("↑") + `  Ahead - worktree has unpushed commits
+  ` + style.Cyan("↓") + `  Behind - worktree needs to pull
+  ` + style.Magenta("↕") + `  Unmerged - worktree has commits not in the branch it was created from (or main/master)
+  ` + style.Blue("≡") + `  Stash - the stash has entries made on the worktree's branch
+
+Multiple indicators can appear together (e.g., ` + style.Red("✗") + " " + style.Magenta("↕") + ` means dirty and unmerged).
+Clean worktrees show no indicators. The ahead and behind arrows carry commit
+counts, such as ` + style.Yellow("↑3") + " " + style.Cyan("↓1") + `; set status_counts: false in
+~/.config/sprout/config.yml for bare arrows. Repositories without a remote
+show no ahead or behind arrows, and count unmerged commits against the local
+main/master.
+
+With --all, repositories are sectioned by group when they span more than one.
+A repository's group is the "group" key in its .sprout.yml, or else the name of
+the directory containing it. --group shows a single group (and implies --all);
+--collapse prints one summary line per group.
+
+With --porcelain=v1, each worktree is one line of tab-separated fields
+that never change, for scripts: repository, path, branch, kind (main or
+sprout), then dirty, untracked, ahead, behind, unmerged, stash and pinned
+as 0/1 flags or counts. Tabs, newlines and backslashes in fields are
+escaped as \t, \n and \\.
+
+With --details, each worktree also shows the ref its branch was created
+from, which is what unmerged commits are counted against, and its note
+(see 'sprout note').
+
+With --workspace, the worktrees of workspaces (see 'sprout ws') are listed
+instead, a section per workspace with a line per repository, so the state
+of a feature across repositories shows at a glance.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		fx := effects.NewRealEffects()
 
+		porcelain := porcelainFormat(listPorcelain)
+		if porcelain != "" && listCollapseFlag {
+			exitWithError(errors.New("--porcelain lists every worktree and can't be combined with --collapse"))
+		}
+
+		if listWorkspace {
+			if porcelain != "" || listGroupFlag != "" || listCollapseFlag || listDetailsFlag {
+				exitWithError(errors.New("--workspace can't be combined with --porcelain, --group, --collapse or --details"))
+			}
+			ctx, err := BuildWorkspaceListContext(fx)
+			if err != nil {
+				exitWithError(err)
+			}
+			fx.Print(core.FormatListOutput(ctx))
+			return
+		}
+
 		// 1. Gather (imperative - uses Effects)
-		ctx, err := BuildListContext(fx, listAllFlag)
+		all := listAllFlag || listGroupFlag != "" || listCollapseFlag
+		ctx, err := BuildListContext(fx, all)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
+		}
+		ctx.Group = listGroupFlag
+		ctx.Collapse = listCollapseFlag
+		ctx.Porcelain = porcelain
+		if listDetailsFlag {
+			build.AssignDetails(fx, ctx.Repos)
 		}
 
 		// 2. Format (pure - no I/O)
 		output := core.FormatListOutput(ctx)
 
 		// 3. Output (imperative)
-		fx.Print(output)
+		if output != "" {
+			fx.Print(output)
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(listCmd)
 	listCmd.Flags().BoolVar(&listAllFlag, "all", false, "List worktrees from all repositories")
+	listCmd.Flags().StringVar(&listGroupFlag, "group", "", "Only list repositories in this group (implies --all)")
+	listCmd.Flags().BoolVar(&listCollapseFlag, "collapse", false, "Show one summary line per group (implies --all)")
+	addPorcelainFlag(listCmd, &listPorcelain)
+	listCmd.Flags().BoolVar(&listDetailsFlag, "details", false, "Show the ref each worktree was created from and its note")
+	listCmd.Flags().BoolVar(&listWorkspace, "workspace", false, "List the worktrees of each workspace (see 'sprout ws') instead of each repository")
 }
 
 // BuildListContext gathers all data needed for the list command.
@@ -73,15 +138,57 @@ func BuildListContext(fx effects.Effects, all bool) (core.ListContext, error) {
 		return core.ListContext{}, err
 	}
 
+	assignRepoGroups(fx, repos)
+	build.AssignTickets(fx, repos)
+	build.AssignPins(fx, repos)
+	build.CacheStatuses(fx, repos)
+
 	home, _ := fx.UserHomeDir()
+	userCfg, _ := fx.LoadUserConfig()
 
 	return core.ListContext{
 		Repos:   repos,
 		Home:    home,
 		ShowAll: all,
+		Counts:  userCfg.ShowsStatusCounts(),
+	}, nil
+}
+
+// BuildWorkspaceListContext gathers every workspace with the status of its
+// worktrees, for list --workspace.
+func BuildWorkspaceListContext(fx effects.Effects) (core.ListContext, error) {
+	wsCtx, err := BuildWsListContext(fx)
+	if err != nil {
+		return core.ListContext{}, err
+	}
+	for _, ws := range wsCtx.Workspaces {
+		for i, m := range ws.Members {
+			if m.WorktreePath != "" {
+				ws.Members[i].Status = fx.GetWorktreeStatus(m.WorktreePath)
+			}
+		}
+	}
+	userCfg, _ := fx.LoadUserConfig()
+	return core.ListContext{
+		Home:        wsCtx.Home,
+		Counts:      userCfg.ShowsStatusCounts(),
+		ByWorkspace: true,
+		Workspaces:  wsCtx.Workspaces,
 	}, nil
 }
 
+// assignRepoGroups sets each repo's group from its .sprout.yml.
+// Unreadable configs fall back to the inferred group.
+func assignRepoGroups(fx effects.Effects, repos []core.RepoDisplay) {
+	for i := range repos {
+		var configured string
+		if cfg, err := fx.LoadConfig(repos[i].MainPath, repos[i].MainPath); err == nil {
+			configured = cfg.Group
+		}
+		repos[i].Group = core.RepoGroup(configured, repos[i].MainPath)
+	}
+}
+
 // collectCurrentRepoWithEffects gathers information about the current repository using Effects.
 // Returns (repo, true, nil) if sprout worktrees exist.
 // Returns (empty, false, nil) if no sprout worktrees exist (not an error).
@@ -109,14 +216,18 @@ func collectCurrentRepoWithEffects(fx effects.Effects) (core.RepoDisplay, bool,
 	if err != nil {
 		return core.RepoDisplay{}, false, fmt.Errorf("failed to get sprout root: %w", err)
 	}
-	sproutWorktrees := core.FilterSproutWorktrees(allWorktrees[1:], sproutRoot)
-	sproutWorktrees = filterExistingWorktreesWithEffects(fx, sproutWorktrees)
+	adopted, err := fx.ListAdoptedWorktrees()
+	if err != nil {
+		return core.RepoDisplay{}, false, fmt.Errorf("failed to load adopted worktrees: %w", err)
+	}
+	sproutWorktrees := core.FilterSproutWorktrees(allWorktrees[1:], sproutRoot, adopted...)
+	sproutWorktrees = build.ExistingWorktrees(fx, sproutWorktrees)
 
 	if len(sproutWorktrees) == 0 {
 		return core.RepoDisplay{}, false, nil // No sprout worktrees is not an error
 	}
 
-	return buildRepoDisplayWithEffects(fx, filepath.Base(mainWorktree.Path), mainWorktree, sproutWorktrees), true, nil
+	return build.RepoDisplay(fx, filepath.Base(mainWorktree.Path), mainWorktree, sproutWorktrees), true, nil
 }
 
 // collectAllReposWithEffects discovers all sprout-managed repositories using Effects.
@@ -126,11 +237,27 @@ func collectAllReposWithEffects(fx effects.Effects) ([]core.RepoDisplay, error)
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan sprout directories: %w", err)
 	}
-	if len(repoDirs) == 0 {
+
+	// Adopted worktrees live outside the sprout root, so they are discovered separately
+	adopted, err := fx.ListAdoptedWorktrees()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load adopted worktrees: %w", err)
+	}
+
+	if len(repoDirs) == 0 && len(adopted) == 0 {
 		return nil, nil
 	}
 
-	repoMap := discoverReposParallelWithEffects(fx, repoDirs)
+	// Without an index, or for directories it misses, worktrees are scanned for
+	index, err := fx.LoadRepoIndex()
+	if err != nil {
+		index = nil
+	}
+	repoMap, found := discoverReposParallelWithEffects(fx, repoDirs, adopted, index)
+	// Directories that no longer hold worktrees drop out of the index here
+	if !maps.Equal(found, index) {
+		_ = fx.SaveRepoIndex(found)
+	}
 
 	// Convert map to sorted slice
 	repos := make([]core.RepoDisplay, 0, len(repoMap))
@@ -155,7 +282,7 @@ func findAllRepoDirectoriesWithEffects(fx effects.Effects) ([]string, error) {
 	}
 
 	// Check if sprout directory exists
-	if !fx.FileExists(sproutRoot) {
+	if !effects.Exists(fx, sproutRoot) {
 		// Not an error - user just hasn't created any worktrees yet
 		return nil, nil
 	}
@@ -175,42 +302,80 @@ func findAllRepoDirectoriesWithEffects(fx effects.Effects) ([]string, error) {
 	return repoDirs, nil
 }
 
-// discoverReposParallelWithEffects processes repo directories in parallel and returns a map of repos.
-func discoverReposParallelWithEffects(fx effects.Effects, repoDirs []string) map[string]core.RepoDisplay {
+// discoverReposParallelWithEffects processes repo directories and adopted worktrees
+// in parallel and returns a map of repos, and the repository index for the
+// directories: the main worktree path the repository in each was found at.
+// Directories in index go straight to their main worktree; the others are
+// scanned for one.
+func discoverReposParallelWithEffects(fx effects.Effects, repoDirs, adopted []string, index map[string]string) (map[string]core.RepoDisplay, map[string]string) {
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 	repoMap := make(map[string]core.RepoDisplay)
+	found := make(map[string]string)
+
+	// add records repo, and indexes it under repoDir unless that is empty
+	add := func(repoDir string, repo core.RepoDisplay) {
+		mu.Lock()
+		if repoDir != "" {
+			found[repoDir] = repo.MainPath
+		}
+		if _, exists := repoMap[repo.MainPath]; !exists {
+			repoMap[repo.MainPath] = repo
+		}
+		mu.Unlock()
+	}
 
 	for _, repoDir := range repoDirs {
 		wg.Add(1)
-		go func(dir string) {
+		go func() {
 			defer wg.Done()
 
-			repo, ok := processRepoDirectoryWithEffects(fx, dir)
-			if !ok {
-				return
+			// An indexed main worktree only counts if it still has worktrees here
+			if mainPath, ok := index[repoDir]; ok && effects.Exists(fx, mainPath) {
+				if repo, ok := processWorktreeWithEffects(fx, mainPath, adopted); ok && hasWorktreeIn(repo, repoDir) {
+					add(repoDir, repo)
+					return
+				}
 			}
 
-			mu.Lock()
-			if _, exists := repoMap[repo.MainPath]; !exists {
-				repoMap[repo.MainPath] = repo
+			anyWorktree := findFirstWorktreeWithEffects(fx, repoDir)
+			if anyWorktree == "" {
+				return
+			}
+			if repo, ok := processWorktreeWithEffects(fx, anyWorktree, adopted); ok {
+				add(repoDir, repo)
 			}
-			mu.Unlock()
-		}(repoDir)
+		}()
+	}
+	for _, path := range adopted {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !effects.Exists(fx, path) {
+				return
+			}
+			if repo, ok := processWorktreeWithEffects(fx, path, adopted); ok {
+				add("", repo)
+			}
+		}()
 	}
 
 	wg.Wait()
-	return repoMap
+	return repoMap, found
 }
 
-// processRepoDirectoryWithEffects processes a single repo directory and returns repo info.
-func processRepoDirectoryWithEffects(fx effects.Effects, repoDir string) (core.RepoDisplay, bool) {
-	// Find any worktree in this repo dir
-	anyWorktree := findFirstWorktreeWithEffects(fx, repoDir)
-	if anyWorktree == "" {
-		return core.RepoDisplay{}, false
+// hasWorktreeIn reports whether any worktree of repo is inside dir.
+func hasWorktreeIn(repo core.RepoDisplay, dir string) bool {
+	for _, wt := range repo.Worktrees {
+		if strings.HasPrefix(wt.Path, dir+string(filepath.Separator)) {
+			return true
+		}
 	}
+	return false
+}
 
+// processWorktreeWithEffects builds repo info from any worktree belonging to the repo.
+func processWorktreeWithEffects(fx effects.Effects, anyWorktree string, adopted []string) (core.RepoDisplay, bool) {
 	// Get all worktrees for this repo
 	allWorktrees, err := fx.ListWorktrees(anyWorktree)
 	if err != nil || len(allWorktrees) == 0 {
@@ -225,56 +390,15 @@ func processRepoDirectoryWithEffects(fx effects.Effects, repoDir string) (core.R
 	if err != nil {
 		return core.RepoDisplay{}, false
 	}
-	sproutWorktrees := core.FilterSproutWorktrees(allWorktrees[1:], sproutRoot)
-	sproutWorktrees = filterExistingWorktreesWithEffects(fx, sproutWorktrees)
+	sproutWorktrees := core.FilterSproutWorktrees(allWorktrees[1:], sproutRoot, adopted...)
+	sproutWorktrees = build.ExistingWorktrees(fx, sproutWorktrees)
 
 	if len(sproutWorktrees) == 0 {
 		return core.RepoDisplay{}, false
 	}
 
 	repoName := filepath.Base(mainWorktree.Path)
-	return buildRepoDisplayWithEffects(fx, repoName, mainWorktree, sproutWorktrees), true
-}
-
-// buildRepoDisplayWithEffects creates a RepoDisplay with parallel status collection.
-func buildRepoDisplayWithEffects(fx effects.Effects, name string, mainWorktree git.Worktree, sproutWorktrees []git.Worktree) core.RepoDisplay {
-	totalWorktrees := 1 + len(sproutWorktrees)
-	worktrees := make([]core.WorktreeDisplayItem, totalWorktrees)
-	var wg sync.WaitGroup
-
-	// Collect status for main worktree
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		worktrees[0] = core.WorktreeDisplayItem{
-			Branch: mainWorktree.Branch,
-			Path:   mainWorktree.Path,
-			Status: fx.GetWorktreeStatus(mainWorktree.Path),
-			IsMain: true,
-		}
-	}()
-
-	// Collect status for sprout worktrees
-	for i, wt := range sproutWorktrees {
-		wg.Add(1)
-		go func(idx int, worktree git.Worktree) {
-			defer wg.Done()
-			worktrees[idx+1] = core.WorktreeDisplayItem{
-				Branch: worktree.Branch,
-				Path:   worktree.Path,
-				Status: fx.GetWorktreeStatus(worktree.Path),
-				IsMain: false,
-			}
-		}(i, wt)
-	}
-
-	wg.Wait()
-
-	return core.RepoDisplay{
-		Name:      name,
-		MainPath:  mainWorktree.Path,
-		Worktrees: worktrees,
-	}
+	return build.RepoDisplay(fx, repoName, mainWorktree, sproutWorktrees), true
 }
 
 // findFirstWorktreeWithEffects does a shallow scan to find any worktree in the repo directory.
@@ -323,7 +447,7 @@ func scanLevelWithEffects(fx effects.Effects, dir string, currentDepth, maxDepth
 		entryPath := filepath.Join(dir, entry.Name())
 
 		// Check if this directory has .git
-		if fx.FileExists(filepath.Join(entryPath, ".git")) {
+		if effects.Exists(fx, filepath.Join(entryPath, ".git")) {
 			*candidates = append(*candidates, entryPath)
 		}
 
@@ -331,14 +455,3 @@ func scanLevelWithEffects(fx effects.Effects, dir string, currentDepth, maxDepth
 		scanLevelWithEffects(fx, entryPath, currentDepth+1, maxDepth, candidates)
 	}
 }
-
-// filterExistingWorktreesWithEffects filters out worktrees whose paths don't exist on the filesystem.
-func filterExistingWorktreesWithEffects(fx effects.Effects, worktrees []git.Worktree) []git.Worktree {
-	var existing []git.Worktree
-	for _, wt := range worktrees {
-		if fx.FileExists(wt.Path) {
-			existing = append(existing, wt)
-		}
-	}
-	return existing
-}
@@ -8,10 +8,9 @@ import (
 	"github.com/m44rten1/sprout/internal/effects"
 	"github.com/m44rten1/sprout/internal/git"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-// Note: BuildStatusEmojis and ShortenPath tests moved to internal/core/list_test.go
-
 // Test helper to create directories, fails test on error
 func mustMkdirAll(t *testing.T, path string) {
 	t.Helper()
@@ -28,35 +27,6 @@ func mustWriteFile(t *testing.T, path string, content string) {
 	}
 }
 
-func TestFilterExistingWorktrees(t *testing.T) {
-	t.Parallel()
-
-	tmpDir := t.TempDir()
-
-	// Create some existing paths
-	existingPath1 := filepath.Join(tmpDir, "existing1")
-	existingPath2 := filepath.Join(tmpDir, "existing2")
-	nonExistingPath := filepath.Join(tmpDir, "nonexistent")
-
-	mustMkdirAll(t, existingPath1)
-	mustMkdirAll(t, existingPath2)
-
-	worktrees := []git.Worktree{
-		{Path: existingPath1, Branch: "branch1"},
-		{Path: nonExistingPath, Branch: "branch2"},
-		{Path: existingPath2, Branch: "branch3"},
-	}
-
-	fx := effects.NewTestEffects()
-	fx.Files[existingPath1] = true
-	fx.Files[existingPath2] = true
-	result := filterExistingWorktreesWithEffects(fx, worktrees)
-
-	assert.Len(t, result, 2, "should filter out non-existing path")
-	assert.Equal(t, existingPath1, result[0].Path, "should preserve order")
-	assert.Equal(t, existingPath2, result[1].Path, "should preserve order")
-}
-
 func TestScanForGitDirs(t *testing.T) {
 	t.Parallel()
 
@@ -184,3 +154,67 @@ func TestScanForGitDirs_MaxDepthZero(t *testing.T) {
 	result := scanForGitDirsWithEffects(fx, tmpDir, 0)
 	assert.Empty(t, result, "maxDepth 0 should not traverse into any directories")
 }
+
+func TestCollectAllRepos_RepoIndex(t *testing.T) {
+	t.Parallel()
+
+	// A sprout root holding one repository directory with one worktree
+	newFx := func(t *testing.T) (*effects.TestEffects, string) {
+		root := t.TempDir()
+		repoDir := filepath.Join(root, "app-1234")
+		mustMkdirAll(t, filepath.Join(repoDir, "feature"))
+
+		fx := effects.NewTestEffects()
+		fx.SproutRoot = root
+		fx.Worktrees = []git.Worktree{
+			{Path: "/code/app", Branch: "main"},
+			{Path: filepath.Join(repoDir, "feature"), Branch: "feature"},
+		}
+		for _, dir := range []string{root, repoDir} {
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			fx.DirEntries[dir] = entries
+		}
+		fx.Files[root] = true
+		fx.Files["/code/app"] = true
+		fx.Files[filepath.Join(repoDir, "feature")] = true
+		fx.Files[filepath.Join(repoDir, "feature", ".git")] = true
+		return fx, repoDir
+	}
+
+	t.Run("indexed directory is not scanned", func(t *testing.T) {
+		t.Parallel()
+		fx, repoDir := newFx(t)
+		fx.RepoIndex[repoDir] = "/code/app"
+
+		repos, err := collectAllReposWithEffects(fx)
+		require.NoError(t, err)
+		require.Len(t, repos, 1)
+		assert.Equal(t, "/code/app", repos[0].MainPath)
+		assert.NotContains(t, fx.ReadDirArgs, repoDir)
+		assert.Zero(t, fx.SaveRepoIndexCalls, "unchanged index is not rewritten")
+	})
+
+	t.Run("missing directory is scanned and indexed", func(t *testing.T) {
+		t.Parallel()
+		fx, repoDir := newFx(t)
+
+		repos, err := collectAllReposWithEffects(fx)
+		require.NoError(t, err)
+		require.Len(t, repos, 1)
+		assert.Contains(t, fx.ReadDirArgs, repoDir)
+		assert.Equal(t, map[string]string{repoDir: "/code/app"}, fx.RepoIndex)
+	})
+
+	t.Run("stale entry is replaced", func(t *testing.T) {
+		t.Parallel()
+		fx, repoDir := newFx(t)
+		fx.RepoIndex[repoDir] = "/moved/app"
+		fx.RepoIndex[filepath.Join(filepath.Dir(repoDir), "gone-5678")] = "/code/gone"
+
+		repos, err := collectAllReposWithEffects(fx)
+		require.NoError(t, err)
+		require.Len(t, repos, 1)
+		assert.Equal(t, map[string]string{repoDir: "/code/app"}, fx.RepoIndex)
+	})
+}
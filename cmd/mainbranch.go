@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/m44rten1/sprout/internal/build"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	mainNoHooksFlag bool
+	mainNoOpenFlag  bool
+	mainOpenFlag    bool
+	mainPrintPath   bool
+	mainTrustFlag   bool
+)
+
+var mainCmd = &cobra.Command{
+	Use:   "main",
+	Short: "Open the default branch's worktree, creating it if missing",
+	Long: `Open the worktree that has the repository's default branch checked out,
+or create a sprout worktree for it when none has, like 'sprout add' would.
+
+This is the way back to main from anywhere in the repository, and the way
+to get a checkout of it at all in bare-clone setups, where there is no
+main checkout to open. The default branch is default_branch from
+.sprout.yml, or else the one git reports for origin.
+
+The flags are those of 'sprout open' and 'sprout add':
+
+  cd "$(sprout main -p --no-open)"`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+		// Whether the worktree must be created is only known from the plan,
+		// which must be built under the lock in case it is
+		defer lockRepo(fx)()
+
+		plan, path, _, err := BuildMainPlan(fx, MainOptions{
+			NoHooks: mainNoHooksFlag,
+			NoOpen:  mainNoOpenFlag,
+			Open:    mainOpenFlag,
+			Trust:   mainTrustFlag,
+		})
+		if err != nil {
+			exitWithError(err)
+		}
+
+		if mainPrintPath {
+			runPlanPrintingPath(plan, fx, path)
+			return
+		}
+		runPlan(plan, fx)
+	},
+}
+
+// MainOptions holds the command-line flags of the main command.
+type MainOptions struct {
+	NoHooks bool // Skip on_open or on_create hooks
+	NoOpen  bool // Skip opening the editor
+	Open    bool // Open the editor even if open_editor is false
+	Trust   bool // Trust the repository without prompting if on_create hooks would run
+}
+
+// BuildMainPlan plans opening the worktree that has the default branch,
+// the main checkout included, or adding one when there is none. It returns
+// the worktree's path, and whether it is created.
+func BuildMainPlan(fx effects.Effects, opts MainOptions) (core.Plan, string, bool, error) {
+	repoRoot, err := fx.GetRepoRoot()
+	if err != nil {
+		return core.Plan{}, "", false, fmt.Errorf("not a git repository: %w", err)
+	}
+	mainWorktreePath, err := fx.GetMainWorktreePath()
+	if err != nil {
+		return core.Plan{}, "", false, fmt.Errorf("failed to get main worktree: %w", err)
+	}
+	cfg, err := fx.LoadConfig(repoRoot, mainWorktreePath)
+	if err != nil {
+		return core.Plan{}, "", false, fmt.Errorf("failed to load config: %w", err)
+	}
+	branch := cfg.DefaultBranch
+	if branch == "" {
+		if branch, err = fx.GetDefaultBranch(mainWorktreePath); err != nil {
+			return core.Plan{}, "", false, fmt.Errorf("failed to get default branch: %w", err)
+		}
+	}
+
+	worktrees, err := fx.ListWorktrees(repoRoot)
+	if err != nil {
+		return core.Plan{}, "", false, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	for _, wt := range worktrees {
+		if wt.Branch != branch {
+			continue
+		}
+		if exists, err := build.WorktreeDirExists(fx, wt.Path); err != nil {
+			return core.Plan{}, "", false, err
+		} else if !exists {
+			continue
+		}
+		ctx, err := buildOpenContextFor(fx, wt.Path, repoRoot, mainWorktreePath, OpenOptions{NoHooks: opts.NoHooks, NoOpen: opts.NoOpen, Open: opts.Open})
+		if err != nil {
+			return core.Plan{}, "", false, err
+		}
+		return core.PlanOpenCommand(ctx), wt.Path, false, nil
+	}
+
+	ctx, err := build.AddContext(fx, []string{branch}, build.AddOptions{NoHooks: opts.NoHooks, NoOpen: opts.NoOpen, Open: opts.Open, Trust: opts.Trust})
+	if err != nil {
+		return core.Plan{}, "", false, err
+	}
+	return core.PlanAddCommand(ctx), ctx.WorktreePath, !ctx.WorktreeExists, nil
+}
+
+func init() {
+	rootCmd.AddCommand(mainCmd)
+	mainCmd.Flags().BoolVar(&mainNoHooksFlag, "no-hooks", false, "Skip running on_open or on_create hooks even if .sprout.yml exists")
+	mainCmd.Flags().BoolVar(&mainNoOpenFlag, "no-open", false, "Print the worktree path instead of opening it in an editor")
+	mainCmd.Flags().BoolVar(&mainOpenFlag, "open", false, "Open the worktree in an editor even if open_editor is false")
+	mainCmd.Flags().BoolVarP(&mainPrintPath, "print-path", "p", false, "Only print the worktree path on stdout")
+	mainCmd.Flags().BoolVar(&mainTrustFlag, "trust", false, "Trust this repository's hooks without prompting when the worktree is created")
+	mainCmd.MarkFlagsMutuallyExclusive("open", "no-open")
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMainPlan(t *testing.T) {
+	t.Parallel()
+
+	t.Run("opens the main checkout", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+		fx.Worktrees = []git.Worktree{{Path: "/test/repo", Branch: "main"}}
+		fx.Files["/test/repo"] = true
+
+		plan, path, created, err := BuildMainPlan(fx, MainOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "/test/repo", path)
+		assert.False(t, created)
+		assert.Contains(t, plan.Actions, core.Action(core.OpenEditor{Path: "/test/repo"}))
+	})
+
+	t.Run("opens the sprout worktree of default_branch", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+		fx.Config = &config.Config{DefaultBranch: "develop"}
+		fx.Worktrees = []git.Worktree{
+			{Path: "/test/repo", Branch: "feature"},
+			{Path: "/test/repo-sprout/develop/repo", Branch: "develop"},
+		}
+		fx.Files["/test/repo-sprout/develop/repo"] = true
+
+		_, path, created, err := BuildMainPlan(fx, MainOptions{NoOpen: true})
+		require.NoError(t, err)
+		assert.Equal(t, "/test/repo-sprout/develop/repo", path)
+		assert.False(t, created)
+	})
+
+	t.Run("creates a worktree when none has the branch", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+		fx.DefaultBranch = "trunk"
+		fx.Worktrees = []git.Worktree{{Path: "/test/repo"}} // A bare clone has no branch checked out
+		fx.RemoteBranches["trunk"] = true
+		fx.WorktreePaths["trunk"] = "/test/repo-sprout/trunk/repo"
+
+		plan, path, created, err := BuildMainPlan(fx, MainOptions{NoOpen: true})
+		require.NoError(t, err)
+		assert.Equal(t, "/test/repo-sprout/trunk/repo", path)
+		assert.True(t, created)
+
+		require.NoError(t, effects.ExecutePlan(plan, fx))
+		assert.Contains(t, fx.GitCommands, effects.GitCmd{
+			Dir:  "/test/repo",
+			Args: []string{"worktree", "add", "/test/repo-sprout/trunk/repo", "-b", "trunk", "origin/trunk"},
+		})
+	})
+}
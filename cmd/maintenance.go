@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	maintenanceGC       bool
+	maintenanceSchedule bool
+)
+
+var maintenanceCmd = &cobra.Command{
+	Use:   "maintenance",
+	Short: "Run git maintenance on every sprout-managed repository",
+	Long: `All worktrees of a repository share one object store, which grows with
+every worktree's fetches and commits. maintenance prunes stale worktree
+entries and runs 'git maintenance run' in every repository sprout manages,
+or 'git gc' with --gc.
+
+With --schedule, the repositories are registered for git's background
+maintenance instead ('git maintenance start'), which runs hourly, daily and
+weekly tasks through cron, launchd or systemd. Undo it with
+'git maintenance unregister' in a repository, or stop it for all with
+'git maintenance stop'.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+
+		ctx, err := BuildMaintenanceContext(fx)
+		if err != nil {
+			exitWithError(err)
+		}
+		ctx.GC = maintenanceGC
+		ctx.Schedule = maintenanceSchedule
+
+		runPlan(core.PlanMaintenanceCommand(ctx), fx)
+	},
+}
+
+// BuildMaintenanceContext finds the main worktrees of all sprout-managed
+// repositories, as 'sprout list --all' does.
+func BuildMaintenanceContext(fx effects.Effects) (core.MaintenanceContext, error) {
+	repos, err := collectAllReposWithEffects(fx)
+	if err != nil {
+		return core.MaintenanceContext{}, err
+	}
+	var ctx core.MaintenanceContext
+	for _, repo := range repos {
+		ctx.Repos = append(ctx.Repos, repo.MainPath)
+	}
+	return ctx, nil
+}
+
+func init() {
+	rootCmd.AddCommand(maintenanceCmd)
+	maintenanceCmd.Flags().BoolVar(&maintenanceGC, "gc", false, "Run git gc instead of git maintenance run")
+	maintenanceCmd.Flags().BoolVar(&maintenanceSchedule, "schedule", false, "Register the repositories for git's scheduled background maintenance")
+	maintenanceCmd.MarkFlagsMutuallyExclusive("gc", "schedule")
+}
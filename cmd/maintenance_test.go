@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMaintenanceContext(t *testing.T) {
+	t.Parallel()
+	fx := effects.NewTestEffects()
+	fx.Worktrees = []git.Worktree{
+		{Path: "/code/app", Branch: "main"},
+		{Path: "/manual/feature", Branch: "feature"},
+	}
+	fx.Adopted = []string{"/manual/feature"}
+	fx.Files["/manual/feature"] = true
+
+	ctx, err := BuildMaintenanceContext(fx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/code/app"}, ctx.Repos)
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+
+	"github.com/spf13/cobra"
+)
+
+var noteClearFlag bool
+
+var noteCmd = &cobra.Command{
+	Use:   "note <branch-or-path> [note]",
+	Short: "Note why a worktree exists",
+	Long: `Attach a short note to a worktree, such as "waiting on review", to
+remember why a half-finished worktree is still around. The note is shown by
+'sprout list --details' and in pickers.
+
+  sprout note feature "waiting on review"   set the note
+  sprout note feature                       print it
+  sprout note feature --clear               remove it
+
+Use . for the current worktree.`,
+	Args: cobra.RangeArgs(1, 2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeSproutWorktrees(toComplete)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+
+		ctx, err := BuildNoteContext(fx, args, noteClearFlag)
+		if err != nil {
+			exitWithError(err)
+		}
+
+		plan := core.PlanNoteCommand(ctx)
+		runPlan(plan, fx)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(noteCmd)
+	noteCmd.Flags().BoolVar(&noteClearFlag, "clear", false, "Remove the note")
+}
+
+// BuildNoteContext resolves the worktree given as the first argument and
+// takes the new note, if any, from the second.
+func BuildNoteContext(fx effects.Effects, args []string, clear bool) (core.NoteContext, error) {
+	if clear && len(args) > 1 {
+		return core.NoteContext{}, errors.New("--clear doesn't take a note")
+	}
+	target, err := resolveWorktreeTarget(fx, args[:1])
+	if err != nil {
+		return core.NoteContext{}, err
+	}
+
+	ctx := core.NoteContext{
+		TargetPath: target.Path,
+		Branch:     target.Branch,
+		SproutRoot: target.SproutRoot,
+		Adopted:    target.Adopted,
+		Current:    target.Meta.Note,
+		Clear:      clear,
+	}
+	if len(args) > 1 {
+		ctx.Note, ctx.Set = args[1], true
+	}
+	return ctx, nil
+}
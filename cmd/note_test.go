@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/sprout"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildNoteContext(t *testing.T) {
+	t.Parallel()
+
+	fx := baseTestFx()
+	fx.WorktreeRoot = "/sprout/repo"
+	fx.Worktrees = []git.Worktree{
+		{Path: "/test/repo", Branch: "main"},
+		{Path: "/sprout/repo/feature", Branch: "feature"},
+	}
+	fx.WorktreeMetadata["/sprout/repo/feature"] = sprout.WorktreeMeta{Path: "/sprout/repo/feature", Note: "old"}
+
+	ctx, err := BuildNoteContext(fx, []string{"feature", "waiting on review"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, "/sprout/repo/feature", ctx.TargetPath)
+	assert.Equal(t, "old", ctx.Current)
+	assert.Equal(t, "waiting on review", ctx.Note)
+	assert.True(t, ctx.Set)
+
+	ctx, err = BuildNoteContext(fx, []string{"feature"}, false)
+	require.NoError(t, err)
+	assert.False(t, ctx.Set)
+
+	_, err = BuildNoteContext(fx, []string{"feature", "note"}, true)
+	assert.EqualError(t, err, "--clear doesn't take a note")
+}
@@ -1,78 +1,128 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"strings"
+	"path/filepath"
 
+	"github.com/m44rten1/sprout/internal/build"
 	"github.com/m44rten1/sprout/internal/core"
 	"github.com/m44rten1/sprout/internal/effects"
 	"github.com/m44rten1/sprout/internal/git"
-	"github.com/m44rten1/sprout/internal/sprout"
+	"github.com/m44rten1/sprout/internal/trust"
 
 	"github.com/spf13/cobra"
 )
 
 var (
 	openNoHooksFlag bool
+	openNoOpenFlag  bool
+	openOpenFlag    bool
+	openPrintPath   bool
+	openWebFlag     bool
+	openGroupFlag   string
+	openWorkspace   bool
 )
 
+// OpenOptions holds the command-line flags that influence the open command.
+type OpenOptions struct {
+	NoHooks bool // Skip on_open hooks
+	NoOpen  bool // Print the path instead of opening the editor
+	Open    bool // Open the editor even if open_editor is false
+}
+
 var openCmd = &cobra.Command{
-	Use:   "open [branch-or-path]",
+	Use:   "open [branch-or-path] [file[:line[:column]]]",
 	Short: "Open a worktree",
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Open a worktree in your editor and run its on_open hooks.
+
+The worktree is given by path or branch, and any unique part of the branch
+will do: "sprout open ABC-1234" opens feature/ABC-1234-long-description.
+When it matches several worktrees, you pick one of them.
+
+With --web, open the branch's page on GitHub or GitLab in your browser
+instead. The branch is taken from the argument (a branch name or worktree
+path) or, without one, from the worktree you are in.
+
+With --group and no argument, pick from the worktrees of every repository in
+that group (see 'sprout list --group'); this works outside a repository too.
+
+A second argument opens a file of the worktree, optionally at a line, as
+printed in stack traces and compiler errors:
+
+  sprout open feature internal/api/server.go:42
+
+Relative paths are resolved inside the worktree; absolute paths into another
+worktree of the repository are mapped to the same file in this one.
+
+With --workspace, open the repository's multi-root workspace (see 'sprout
+workspace') instead, generating it with every worktree if it doesn't exist.
+
+With --no-open, or open_editor: false in .sprout.yml or the user config, the
+path is printed instead of opened (e.g. for cd "$(sprout open feature)").
+--open overrides the config.
+
+With --print-path, the worktree is opened as usual but only its path is
+printed on stdout; other output goes to stderr.`,
+	Args: cobra.MaximumNArgs(2),
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		// Only complete the first argument
-		if len(args) > 0 {
-			return nil, cobra.ShellCompDirectiveNoFileComp
+		// The second argument is a file
+		if len(args) == 1 {
+			return nil, cobra.ShellCompDirectiveDefault
 		}
-
-		repoRoot, err := git.GetRepoRoot()
-		if err != nil {
+		if len(args) > 1 {
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		}
 
-		worktrees, err := git.ListWorktrees(repoRoot)
-		if err != nil {
-			return nil, cobra.ShellCompDirectiveNoFileComp
-		}
+		return completeSproutWorktrees(toComplete)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
 
-		// Filter to sprout worktrees
-		sproutRoot, err := sprout.GetSproutRoot()
-		if err != nil {
-			return nil, cobra.ShellCompDirectiveNoFileComp
+		if openPrintPath && (openWorkspace || openWebFlag) {
+			printError(errors.New("--print-path cannot be combined with --workspace or --web"))
+			os.Exit(1)
 		}
-		choices := core.FilterSproutWorktrees(worktrees, sproutRoot)
 
-		var completions []string
-		for _, wt := range choices {
-			if wt.Branch != "" {
-				// Filter by what user has typed so far for smarter completion
-				if toComplete == "" || strings.HasPrefix(wt.Branch, toComplete) {
-					completions = append(completions, wt.Branch)
-				}
+		if openWorkspace {
+			ctx, err := BuildOpenWorkspaceContext(fx, args)
+			if err != nil {
+				exitWithError(err)
 			}
+			runPlan(core.PlanWorkspaceCommand(ctx), fx)
+			return
 		}
 
-		return completions, cobra.ShellCompDirectiveNoFileComp
-	},
-	Run: func(cmd *cobra.Command, args []string) {
-		fx := effects.NewRealEffects()
-
-		ctx, err := BuildOpenContext(fx, args, openNoHooksFlag)
+		opts := OpenOptions{NoHooks: openNoHooksFlag, NoOpen: openNoOpenFlag, Open: openOpenFlag}
+		var ctx core.OpenContext
+		var err error
+		switch {
+		case openWebFlag && len(args) > 1:
+			err = fmt.Errorf("--web takes a single branch or path")
+		case openWebFlag:
+			ctx, err = BuildOpenWebContext(fx, args)
+		case openGroupFlag != "" && len(args) == 0:
+			ctx, err = BuildOpenGroupContext(fx, openGroupFlag, opts)
+		default:
+			ctx, err = BuildOpenContext(fx, args, opts)
+		}
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 
 		plan := core.PlanOpenCommand(ctx)
+		if openPrintPath {
+			runPlanPrintingPath(plan, fx, ctx.TargetPath)
+			return
+		}
 		runPlan(plan, fx)
 	},
 }
 
 // BuildOpenContext gathers all inputs needed to plan the open command.
 // It handles interactive selection if no argument is provided.
-func BuildOpenContext(fx effects.Effects, args []string, noHooks bool) (core.OpenContext, error) {
+func BuildOpenContext(fx effects.Effects, args []string, opts OpenOptions) (core.OpenContext, error) {
 	// Get repo root
 	repoRoot, err := fx.GetRepoRoot()
 	if err != nil {
@@ -91,16 +141,22 @@ func BuildOpenContext(fx effects.Effects, args []string, noHooks bool) (core.Ope
 		return core.OpenContext{}, fmt.Errorf("failed to get sprout root: %w", err)
 	}
 
+	adopted, err := fx.ListAdoptedWorktrees()
+	if err != nil {
+		return core.OpenContext{}, fmt.Errorf("failed to load adopted worktrees: %w", err)
+	}
+
 	var targetPath string
+	var worktrees []git.Worktree
 
 	if len(args) == 0 {
 		// Interactive mode: select from sprout worktrees
-		worktrees, err := fx.ListWorktrees(repoRoot)
+		worktrees, err = fx.ListWorktrees(repoRoot)
 		if err != nil {
 			return core.OpenContext{}, fmt.Errorf("failed to list worktrees: %w", err)
 		}
 
-		choices := core.FilterSproutWorktrees(worktrees, sproutRoot)
+		choices := core.FilterSproutWorktrees(worktrees, sproutRoot, adopted...)
 
 		if len(choices) == 0 {
 			return core.OpenContext{}, fmt.Errorf(core.MsgNoSproutWorktrees)
@@ -115,34 +171,83 @@ func BuildOpenContext(fx effects.Effects, args []string, noHooks bool) (core.Ope
 	} else {
 		arg := args[0]
 		// Check if it's a path (paths take precedence over branch names)
-		if fx.FileExists(arg) {
+		if effects.Exists(fx, arg) {
 			targetPath = arg
 		} else {
 			// Assume it's a branch - search for it in worktrees
-			worktrees, err := fx.ListWorktrees(repoRoot)
+			worktrees, err = fx.ListWorktrees(repoRoot)
 			if err != nil {
 				return core.OpenContext{}, fmt.Errorf("failed to list worktrees: %w", err)
 			}
 
-			var found bool
-			targetPath, found = core.FindWorktreeByBranch(worktrees, sproutRoot, arg)
-			if !found {
-				return core.OpenContext{}, fmt.Errorf("no sprout-managed worktree found for branch '%s'", arg)
+			targetPath, err = build.FindWorktreeByBranch(fx, worktrees, sproutRoot, arg, adopted)
+			if err != nil {
+				return core.OpenContext{}, err
 			}
 		}
 	}
 
+	ctx, err := buildOpenContextFor(fx, targetPath, repoRoot, mainWorktreePath, opts)
+	if err != nil || len(args) < 2 {
+		return ctx, err
+	}
+
+	// Resolve the file inside the chosen worktree
+	if worktrees == nil {
+		worktrees, err = fx.ListWorktrees(repoRoot)
+		if err != nil {
+			return core.OpenContext{}, fmt.Errorf("failed to list worktrees: %w", err)
+		}
+	}
+	worktreePaths := make([]string, 0, len(worktrees))
+	for _, wt := range worktrees {
+		worktreePaths = append(worktreePaths, wt.Path)
+	}
+
+	file, line, column := core.ParseFileLocation(args[1])
+	if abs, err := filepath.Abs(targetPath); err == nil {
+		targetPath = abs
+	}
+	ctx.File = core.RelocateFile(file, targetPath, worktreePaths)
+	ctx.Line = line
+	ctx.Column = column
+	exists, isDir, err := fx.Stat(ctx.File)
+	switch {
+	case err != nil:
+		return core.OpenContext{}, fmt.Errorf("can't open file: %w", err)
+	case !exists:
+		return core.OpenContext{}, fmt.Errorf("file not found in worktree: %s", ctx.File)
+	case isDir:
+		return core.OpenContext{}, fmt.Errorf("%s is a directory, not a file", ctx.File)
+	}
+
+	return ctx, nil
+}
+
+// buildOpenContextFor loads the config and trust state for opening
+// targetPath, a worktree of the repository whose main worktree is
+// mainWorktreePath.
+func buildOpenContextFor(fx effects.Effects, targetPath, repoRoot, mainWorktreePath string, opts OpenOptions) (core.OpenContext, error) {
 	// Load config
 	cfg, err := fx.LoadConfig(repoRoot, mainWorktreePath)
 	if err != nil {
 		return core.OpenContext{}, fmt.Errorf("failed to load config: %w", err)
 	}
 
+	noOpen, err := build.SkipEditor(fx, cfg, opts.Open, opts.NoOpen)
+	if err != nil {
+		return core.OpenContext{}, err
+	}
+
 	// Check trust status (only matters if hooks will run)
+	// A policy denial is not an error: hooks are skipped and the planner says why
 	isTrusted := false
-	if cfg.HasOpenHooks() && !noHooks {
-		isTrusted, err = fx.IsTrusted(mainWorktreePath)
-		if err != nil {
+	hooksDenied := false
+	if cfg.HasOpenHooks() && !opts.NoHooks {
+		isTrusted, err = fx.IsTrusted(mainWorktreePath, mainWorktreePath)
+		if errors.Is(err, trust.ErrDeniedByPolicy) {
+			hooksDenied = true
+		} else if err != nil {
 			return core.OpenContext{}, fmt.Errorf("failed to check trust status: %w", err)
 		}
 	}
@@ -153,11 +258,158 @@ func BuildOpenContext(fx effects.Effects, args []string, noHooks bool) (core.Ope
 		MainWorktreePath: mainWorktreePath,
 		Config:           cfg,
 		IsTrusted:        isTrusted,
-		NoHooks:          noHooks,
+		NoHooks:          opts.NoHooks,
+		NoOpen:           noOpen,
+		HooksDenied:      hooksDenied,
+	}, nil
+}
+
+// BuildOpenGroupContext selects a worktree interactively from every
+// repository in the group (see 'sprout list --group') and builds the open
+// context for it, so it works from outside any repository.
+func BuildOpenGroupContext(fx effects.Effects, group string, opts OpenOptions) (core.OpenContext, error) {
+	repos, err := collectAllReposWithEffects(fx)
+	if err != nil {
+		return core.OpenContext{}, err
+	}
+	assignRepoGroups(fx, repos)
+	repos = core.FilterReposByGroup(repos, group)
+
+	// Labels are prefixed with the repo name, since branch names repeat across repos
+	var choices []git.Worktree
+	var mainPaths []string
+	for _, repo := range repos {
+		for _, wt := range repo.Worktrees {
+			if wt.IsMain {
+				continue
+			}
+			branch := wt.Branch
+			if branch == "" {
+				branch = filepath.Base(wt.Path)
+			}
+			choices = append(choices, git.Worktree{Path: wt.Path, Branch: repo.Name + " › " + branch})
+			mainPaths = append(mainPaths, repo.MainPath)
+		}
+	}
+	if len(choices) == 0 {
+		return core.OpenContext{}, fmt.Errorf("no sprout-managed worktrees found in group '%s'", group)
+	}
+
+	idx, err := fx.SelectWorktree(choices)
+	if err != nil {
+		return core.OpenContext{}, fmt.Errorf("selection cancelled: %w", err)
+	}
+
+	targetPath := choices[idx].Path
+	return buildOpenContextFor(fx, targetPath, targetPath, mainPaths[idx], opts)
+}
+
+// BuildOpenWebContext resolves the branch page to open for --web.
+// The argument may be a branch name or a worktree path; without one the
+// current worktree's branch is used. The branch does not need a worktree.
+func BuildOpenWebContext(fx effects.Effects, args []string) (core.OpenContext, error) {
+	repoRoot, err := fx.GetRepoRoot()
+	if err != nil {
+		return core.OpenContext{}, fmt.Errorf("not a git repository: %w", err)
+	}
+
+	mainWorktreePath, err := fx.GetMainWorktreePath()
+	if err != nil {
+		return core.OpenContext{}, fmt.Errorf("failed to get main worktree: %w", err)
+	}
+
+	var branch string
+	switch {
+	case len(args) == 0:
+		branch, err = fx.RunGitCommand(repoRoot, "rev-parse", "--abbrev-ref", "HEAD")
+		if err != nil {
+			return core.OpenContext{}, fmt.Errorf("failed to get current branch: %w", err)
+		}
+		if branch == "HEAD" {
+			return core.OpenContext{}, fmt.Errorf("HEAD is detached; pass a branch name")
+		}
+	case effects.Exists(fx, args[0]):
+		worktrees, err := fx.ListWorktrees(repoRoot)
+		if err != nil {
+			return core.OpenContext{}, fmt.Errorf("failed to list worktrees: %w", err)
+		}
+		target := args[0]
+		if abs, err := filepath.Abs(target); err == nil {
+			target = abs
+		}
+		for _, wt := range worktrees {
+			if filepath.Clean(wt.Path) == filepath.Clean(target) {
+				branch = wt.Branch
+				break
+			}
+		}
+		if branch == "" {
+			return core.OpenContext{}, fmt.Errorf("%s is not a worktree with a branch checked out", args[0])
+		}
+	default:
+		branch = args[0]
+	}
+
+	if hasRemote, err := fx.HasRemote(repoRoot); err == nil && !hasRemote {
+		return core.OpenContext{}, errors.New("this repository has no remote, so its branches have no web page")
+	}
+	remoteURL, err := fx.RunGitCommand(mainWorktreePath, "remote", "get-url", "origin")
+	if err != nil {
+		return core.OpenContext{}, fmt.Errorf("failed to get origin URL: %w", err)
+	}
+	remote, err := git.ParseRemoteURL(remoteURL)
+	if err != nil {
+		return core.OpenContext{}, err
+	}
+	webURL, err := remote.BranchURL(branch)
+	if err != nil {
+		return core.OpenContext{}, err
+	}
+
+	return core.OpenContext{
+		RepoRoot:         repoRoot,
+		MainWorktreePath: mainWorktreePath,
+		WebURL:           webURL,
 	}, nil
 }
 
+// BuildOpenWorkspaceContext opens the repository's workspace file as it is,
+// or generates it with every sprout worktree when there is none yet.
+func BuildOpenWorkspaceContext(fx effects.Effects, args []string) (core.WorkspaceContext, error) {
+	if len(args) > 0 {
+		return core.WorkspaceContext{}, fmt.Errorf("--workspace takes no arguments (use 'sprout workspace <branch>...' to choose folders)")
+	}
+
+	mainWorktreePath, err := fx.GetMainWorktreePath()
+	if err != nil {
+		return core.WorkspaceContext{}, fmt.Errorf("failed to get main worktree: %w", err)
+	}
+	worktreeRoot, err := fx.GetWorktreeRoot(mainWorktreePath)
+	if err != nil {
+		return core.WorkspaceContext{}, fmt.Errorf("failed to get sprout root: %w", err)
+	}
+
+	path := defaultWorkspacePath(worktreeRoot, mainWorktreePath)
+	if effects.Exists(fx, path) {
+		return core.WorkspaceContext{WorkspacePath: path, Open: true}, nil
+	}
+
+	ctx, err := BuildWorkspaceContext(fx, nil, path)
+	if err != nil {
+		return core.WorkspaceContext{}, err
+	}
+	ctx.Open = true
+	return ctx, nil
+}
+
 func init() {
 	rootCmd.AddCommand(openCmd)
 	openCmd.Flags().BoolVar(&openNoHooksFlag, "no-hooks", false, "Skip running on_open hooks even if .sprout.yml exists")
+	openCmd.Flags().BoolVar(&openNoOpenFlag, "no-open", false, "Print the worktree path instead of opening it in an editor")
+	openCmd.Flags().BoolVar(&openOpenFlag, "open", false, "Open the worktree in an editor even if open_editor is false")
+	openCmd.MarkFlagsMutuallyExclusive("open", "no-open")
+	openCmd.Flags().BoolVarP(&openPrintPath, "print-path", "p", false, "Only print the worktree path on stdout")
+	openCmd.Flags().BoolVar(&openWebFlag, "web", false, "Open the branch page on GitHub/GitLab in the browser instead")
+	openCmd.Flags().BoolVar(&openWorkspace, "workspace", false, "Open the repository's multi-root workspace (see 'sprout workspace')")
+	openCmd.Flags().StringVar(&openGroupFlag, "group", "", "Pick from the worktrees of every repository in this group")
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/m44rten1/sprout/internal/core"
 	"github.com/m44rten1/sprout/internal/effects"
 	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/trust"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -60,6 +61,26 @@ func TestBuildOpenContext(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:    "policy denial sets HooksDenied instead of failing",
+			args:    []string{"/test/repo/.sprout/feature"},
+			noHooks: false,
+			setupFx: func(fx *effects.TestEffects) {
+				fx.Files["/test/repo/.sprout/feature"] = true
+				fx.Config = &config.Config{Hooks: config.HooksConfig{OnOpen: []string{"make"}}}
+				fx.IsTrustedErr = trust.ErrDeniedByPolicy
+			},
+			wantCtx: &core.OpenContext{
+				TargetPath:       "/test/repo/.sprout/feature",
+				RepoRoot:         "/test/repo",
+				MainWorktreePath: "/test/repo",
+				Config:           &config.Config{Hooks: config.HooksConfig{OnOpen: []string{"make"}}},
+				IsTrusted:        false,
+				NoHooks:          false,
+				HooksDenied:      true,
+			},
+			wantErr: false,
+		},
 		{
 			name:    "explicit branch argument - finds worktree",
 			args:    []string{"feature"},
@@ -263,7 +284,7 @@ func TestBuildOpenContext(t *testing.T) {
 			fx := baseTestFxOpen(t)
 			tt.setupFx(fx)
 
-			ctx, err := BuildOpenContext(fx, tt.args, tt.noHooks)
+			ctx, err := BuildOpenContext(fx, tt.args, OpenOptions{NoHooks: tt.noHooks})
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -450,7 +471,7 @@ func TestOpenCommand_EndToEnd(t *testing.T) {
 			tt.setupFx(fx)
 
 			// Build context from effects (simulating handler)
-			ctx, err := BuildOpenContext(fx, tt.args, tt.noHooks)
+			ctx, err := BuildOpenContext(fx, tt.args, OpenOptions{NoHooks: tt.noHooks})
 			if tt.wantErr && err != nil {
 				// Early error in context building
 				require.Error(t, err)
@@ -480,3 +501,213 @@ func TestOpenCommand_EndToEnd(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildOpenContext_File(t *testing.T) {
+	t.Parallel()
+
+	newFx := func() *effects.TestEffects {
+		fx := effects.NewTestRepo("/test/repo").
+			WithWorktree("feat", "/sprout/app/feat/app").
+			WithFile("/sprout/app/feat/app/internal/api/server.go", "package api\n").
+			Effects()
+		fx.SproutRoot = "/sprout"
+		return fx
+	}
+
+	t.Run("relative file with line", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+
+		ctx, err := BuildOpenContext(fx, []string{"feat", "internal/api/server.go:42"}, OpenOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "/sprout/app/feat/app", ctx.TargetPath)
+		assert.Equal(t, "/sprout/app/feat/app/internal/api/server.go", ctx.File)
+		assert.Equal(t, 42, ctx.Line)
+
+		require.NoError(t, effects.ExecutePlan(core.PlanOpenCommand(ctx), fx))
+		assert.Equal(t, []effects.FileOpen{{
+			WorktreePath: "/sprout/app/feat/app",
+			File:         "/sprout/app/feat/app/internal/api/server.go",
+			Line:         42,
+		}}, fx.OpenedFiles)
+		assert.Empty(t, fx.OpenedPaths)
+	})
+
+	t.Run("path from the main checkout maps into the worktree", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, err := BuildOpenContext(newFx(), []string{"feat", "/test/repo/internal/api/server.go:42:7"}, OpenOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "/sprout/app/feat/app/internal/api/server.go", ctx.File)
+		assert.Equal(t, 7, ctx.Column)
+	})
+
+	t.Run("open_editor false prints the location", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		disabled := false
+		fx.UserConfig = &config.UserConfig{OpenEditor: &disabled}
+
+		ctx, err := BuildOpenContext(fx, []string{"feat", "internal/api/server.go:42"}, OpenOptions{})
+		require.NoError(t, err)
+		assert.True(t, ctx.NoOpen)
+
+		require.NoError(t, effects.ExecutePlan(core.PlanOpenCommand(ctx), fx))
+		assert.Equal(t, []string{"/sprout/app/feat/app/internal/api/server.go:42"}, fx.PrintedMsgs)
+		assert.Empty(t, fx.OpenedFiles)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := BuildOpenContext(newFx(), []string{"feat", "nope.go:1"}, OpenOptions{})
+		assert.ErrorContains(t, err, "file not found in worktree: /sprout/app/feat/app/nope.go")
+	})
+}
+
+func TestBuildOpenContext_AbbreviatedBranch(t *testing.T) {
+	t.Parallel()
+
+	newFx := func() *effects.TestEffects {
+		fx := effects.NewTestRepo("/test/repo").
+			WithWorktree("feature/ABC-1234-long-description", "/sprout/app/feature/ABC-1234-long-description/app").
+			WithWorktree("feature/ABC-1250-other", "/sprout/app/feature/ABC-1250-other/app").
+			Effects()
+		fx.SproutRoot = "/sprout"
+		return fx
+	}
+
+	t.Run("unique match", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, err := BuildOpenContext(newFx(), []string{"ABC-1234"}, OpenOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "/sprout/app/feature/ABC-1234-long-description/app", ctx.TargetPath)
+	})
+
+	t.Run("ambiguous match is picked", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.SelectedWorktreeIndex = 1
+
+		ctx, err := BuildOpenContext(fx, []string{"ABC-12"}, OpenOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, fx.SelectWorktreeCalls)
+		assert.Equal(t, "/sprout/app/feature/ABC-1250-other/app", ctx.TargetPath)
+	})
+
+	t.Run("ambiguous match without a terminal", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.SelectionError = fmt.Errorf("%w; pass a branch or path", effects.ErrNonInteractive)
+
+		_, err := BuildOpenContext(fx, []string{"ABC-12"}, OpenOptions{})
+		assert.EqualError(t, err, "'ABC-12' matches 2 worktrees: feature/ABC-1234-long-description, feature/ABC-1250-other; give more of the branch name")
+	})
+}
+
+func TestBuildOpenWebContext(t *testing.T) {
+	t.Parallel()
+
+	newFx := func() *effects.TestEffects {
+		fx := effects.NewTestEffects()
+		fx.GitCommandOutput["/test/repo\nremote get-url origin"] = "git@github.com:acme/app.git"
+		fx.GitCommandOutput["/test/repo\nrev-parse --abbrev-ref HEAD"] = "feat/current"
+		fx.Worktrees = []git.Worktree{
+			{Path: "/test/repo", Branch: "main"},
+			{Path: "/sprout/app/feat/login/app", Branch: "feat/login"},
+		}
+		return fx
+	}
+
+	t.Run("current branch", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+
+		ctx, err := BuildOpenWebContext(fx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "https://github.com/acme/app/tree/feat/current", ctx.WebURL)
+
+		require.NoError(t, effects.ExecutePlan(core.PlanOpenCommand(ctx), fx))
+		assert.Equal(t, []string{"https://github.com/acme/app/tree/feat/current"}, fx.OpenedURLs)
+		assert.Empty(t, fx.OpenedPaths)
+		assert.Empty(t, fx.RunHooksInvocations)
+	})
+
+	t.Run("branch without a worktree", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.GitCommandOutput["/test/repo\nremote get-url origin"] = "https://gitlab.com/acme/app.git"
+
+		ctx, err := BuildOpenWebContext(fx, []string{"release"})
+		require.NoError(t, err)
+		assert.Equal(t, "https://gitlab.com/acme/app/-/tree/release", ctx.WebURL)
+	})
+
+	t.Run("worktree path", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.Files["/sprout/app/feat/login/app"] = true
+
+		ctx, err := BuildOpenWebContext(fx, []string{"/sprout/app/feat/login/app"})
+		require.NoError(t, err)
+		assert.Equal(t, "https://github.com/acme/app/tree/feat/login", ctx.WebURL)
+	})
+
+	t.Run("detached HEAD", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.GitCommandOutput["/test/repo\nrev-parse --abbrev-ref HEAD"] = "HEAD"
+
+		_, err := BuildOpenWebContext(fx, nil)
+		assert.ErrorContains(t, err, "detached")
+	})
+
+	t.Run("unsupported forge", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.GitCommandOutput["/test/repo\nremote get-url origin"] = "ssh://git@git.example.com/acme/app.git"
+
+		_, err := BuildOpenWebContext(fx, []string{"main"})
+		assert.ErrorIs(t, err, git.ErrUnsupportedForge)
+	})
+}
+
+func TestBuildOpenGroupContext(t *testing.T) {
+	t.Parallel()
+
+	newFx := func() *effects.TestEffects {
+		fx := effects.NewTestEffects()
+		fx.Worktrees = []git.Worktree{
+			{Path: "/code/work/app", Branch: "main"},
+			{Path: "/manual/feature", Branch: "feature"},
+		}
+		fx.Adopted = []string{"/manual/feature"}
+		fx.Files["/manual/feature"] = true
+		return fx
+	}
+
+	t.Run("selects across the group", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+
+		ctx, err := BuildOpenGroupContext(fx, "work", OpenOptions{NoHooks: true})
+		require.NoError(t, err)
+		assert.Equal(t, "/manual/feature", ctx.TargetPath)
+		assert.Equal(t, "/code/work/app", ctx.MainWorktreePath)
+		assert.True(t, ctx.NoHooks)
+	})
+
+	t.Run("configured group wins over inference", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.Config = &config.Config{Group: "clients"}
+
+		_, err := BuildOpenGroupContext(fx, "work", OpenOptions{})
+		assert.ErrorContains(t, err, "no sprout-managed worktrees found in group 'work'")
+
+		ctx, err := BuildOpenGroupContext(fx, "clients", OpenOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "/manual/feature", ctx.TargetPath)
+	})
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/sprout"
+
+	"github.com/spf13/cobra"
+)
+
+var pathsCmd = &cobra.Command{
+	Use:   "paths [config|data|state|cache]",
+	Short: "Print where sprout keeps its files",
+	Long: `Print the directories sprout keeps its files in, and what is in each,
+to debug sprout or to know what to back up. They follow the XDG base
+directory spec, so XDG_CONFIG_HOME, XDG_DATA_HOME, XDG_STATE_HOME and
+XDG_CACHE_HOME move them.
+
+Name one to print only its path:
+
+  cd "$(sprout paths data)"`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{"config", "data", "state", "cache"},
+	Run: func(cmd *cobra.Command, args []string) {
+		paths, err := sproutPaths()
+		if err != nil {
+			exitWithError(err)
+		}
+		ctx := core.PathsContext{Paths: paths}
+		if len(args) > 0 {
+			ctx.Name = args[0]
+		}
+		runPlan(core.PlanPathsCommand(ctx), effects.NewRealEffects())
+	},
+}
+
+// sproutPaths resolves the directories sprout keeps its files in.
+func sproutPaths() ([]core.SproutPath, error) {
+	dirs := []struct {
+		name, holds string
+		dir         func() (string, error)
+	}{
+		{"config", "config.yml, policy.yml and the trusted repositories", config.GetUserConfigDir},
+		{"data", "worktrees, and what sprout records about them", sprout.GetSproutRoot},
+		{"state", "the debug log, timings, telemetry, worktree indices and locks", sprout.GetStateDir},
+		{"cache", "the repository index and worktree statuses", sprout.GetCacheDir},
+	}
+	paths := make([]core.SproutPath, len(dirs))
+	for i, d := range dirs {
+		path, err := d.dir()
+		if err != nil {
+			return nil, err
+		}
+		paths[i] = core.SproutPath{Name: d.name, Path: path, Holds: d.holds}
+	}
+	return paths, nil
+}
+
+func init() {
+	rootCmd.AddCommand(pathsCmd)
+}
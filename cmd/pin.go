@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/m44rten1/sprout/internal/build"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/sprout"
+
+	"github.com/spf13/cobra"
+)
+
+var pinCmd = &cobra.Command{
+	Use:   "pin [branch-or-path]",
+	Short: "Pin a worktree so it's listed first and never cleaned up",
+	Long: `Pin a worktree, given by branch or path, or the current one.
+
+Pinned worktrees are listed right after the main worktree in 'sprout list'
+and offered first in pickers. 'sprout remove --all-merged' and
+'sprout add --evict' skip them, even when they are clean and merged.
+'sprout remove' still removes a pinned worktree when asked to by name.`,
+	Args: cobra.MaximumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeSproutWorktrees(toComplete)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		runPin(args, true)
+	},
+}
+
+var unpinCmd = &cobra.Command{
+	Use:   "unpin [branch-or-path]",
+	Short: "Unpin a worktree",
+	Long:  `Unpin a worktree, given by branch or path, or the current one.`,
+	Args:  cobra.MaximumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeSproutWorktrees(toComplete)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		runPin(args, false)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pinCmd)
+	rootCmd.AddCommand(unpinCmd)
+}
+
+func runPin(args []string, pin bool) {
+	fx := effects.NewRealEffects()
+
+	ctx, err := BuildPinContext(fx, args)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	plan := core.PlanPinCommand(ctx, pin)
+	runPlan(plan, fx)
+}
+
+// BuildPinContext resolves the worktree to pin or unpin: the argument as a
+// path or branch, or else the current worktree.
+func BuildPinContext(fx effects.Effects, args []string) (core.PinContext, error) {
+	target, err := resolveWorktreeTarget(fx, args)
+	if err != nil {
+		return core.PinContext{}, err
+	}
+	return core.PinContext{
+		TargetPath: target.Path,
+		Branch:     target.Branch,
+		SproutRoot: target.SproutRoot,
+		Adopted:    target.Adopted,
+		Pinned:     target.Meta.Pinned,
+	}, nil
+}
+
+// worktreeTarget is a worktree a command acts on, with what sprout
+// recorded about it.
+type worktreeTarget struct {
+	Path       string
+	Branch     string // Empty for a detached HEAD
+	SproutRoot string
+	Adopted    []string
+	Meta       sprout.WorktreeMeta
+}
+
+// resolveWorktreeTarget resolves the first of args as a path or branch, or
+// without args the current worktree. Whether it is a sprout worktree is
+// left to the planner.
+func resolveWorktreeTarget(fx effects.Effects, args []string) (worktreeTarget, error) {
+	repoRoot, err := fx.GetRepoRoot()
+	if err != nil {
+		return worktreeTarget{}, fmt.Errorf("not a git repository: %w", err)
+	}
+	mainWorktreePath, err := fx.GetMainWorktreePath()
+	if err != nil {
+		return worktreeTarget{}, fmt.Errorf("failed to get main worktree: %w", err)
+	}
+	sproutRoot, err := fx.GetWorktreeRoot(mainWorktreePath)
+	if err != nil {
+		return worktreeTarget{}, fmt.Errorf("failed to get sprout root: %w", err)
+	}
+	worktrees, err := fx.ListWorktrees(repoRoot)
+	if err != nil {
+		return worktreeTarget{}, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	adopted, err := fx.ListAdoptedWorktrees()
+	if err != nil {
+		return worktreeTarget{}, fmt.Errorf("failed to load adopted worktrees: %w", err)
+	}
+
+	targetPath := repoRoot
+	if len(args) > 0 {
+		// Paths take precedence over branch names, as in remove
+		if effects.Exists(fx, args[0]) {
+			// Metadata is keyed by absolute path
+			if targetPath, err = filepath.Abs(args[0]); err != nil {
+				return worktreeTarget{}, err
+			}
+		} else {
+			targetPath, err = build.FindWorktreeByBranch(fx, worktrees, sproutRoot, args[0], adopted)
+			if err != nil {
+				return worktreeTarget{}, err
+			}
+		}
+	}
+
+	var branch string
+	for _, wt := range worktrees {
+		if wt.Path == targetPath {
+			branch = wt.Branch
+		}
+	}
+	metadata, err := fx.LoadWorktreeMetadata()
+	if err != nil {
+		return worktreeTarget{}, fmt.Errorf("failed to load worktree metadata: %w", err)
+	}
+
+	return worktreeTarget{
+		Path:       targetPath,
+		Branch:     branch,
+		SproutRoot: sproutRoot,
+		Adopted:    adopted,
+		Meta:       metadata[targetPath],
+	}, nil
+}
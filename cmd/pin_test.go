@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/sprout"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildPinContext(t *testing.T) {
+	t.Parallel()
+
+	newFx := func() *effects.TestEffects {
+		fx := baseTestFx()
+		fx.WorktreeRoot = "/sprout/repo"
+		fx.Worktrees = []git.Worktree{
+			{Path: "/test/repo", Branch: "main"},
+			{Path: "/sprout/repo/feature", Branch: "feature"},
+		}
+		fx.WorktreeMetadata["/sprout/repo/feature"] = sprout.WorktreeMeta{Path: "/sprout/repo/feature", Pinned: true}
+		return fx
+	}
+
+	t.Run("resolves a branch", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, err := BuildPinContext(newFx(), []string{"feature"})
+		require.NoError(t, err)
+		assert.Equal(t, "/sprout/repo/feature", ctx.TargetPath)
+		assert.Equal(t, "feature", ctx.Branch)
+		assert.Equal(t, "/sprout/repo", ctx.SproutRoot)
+		assert.True(t, ctx.Pinned)
+	})
+
+	t.Run("defaults to the current worktree", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.RepoRoot = "/sprout/repo/feature"
+
+		ctx, err := BuildPinContext(fx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "/sprout/repo/feature", ctx.TargetPath)
+	})
+
+	t.Run("unknown branch", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := BuildPinContext(newFx(), []string{"nope"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no sprout-managed worktree found for branch 'nope'")
+	})
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+
+	"github.com/spf13/cobra"
+)
+
+// pluginPrefix starts the name of every plugin executable.
+const pluginPrefix = "sprout-"
+
+// pluginName matches the names a plugin can have, so 'sprout ../x' or a
+// mistyped flag never looks anything up on PATH.
+var pluginName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "List the plugins found on PATH",
+	Long: `List the plugins found on PATH.
+
+A plugin is an executable named sprout-<name>. 'sprout <name> [args...]'
+runs it with the remaining arguments, like git and kubectl do, so teams can
+add their own subcommands. It gets these environment variables (empty
+outside a git repository):
+
+  SPROUT_REPO_ROOT            root of the worktree sprout was run in
+  SPROUT_WORKTREE_PATH        same as SPROUT_REPO_ROOT
+  SPROUT_MAIN_WORKTREE_PATH   the main worktree (your original checkout)
+  SPROUT_WORKTREE_ROOT        where sprout creates the repository's worktrees
+  SPROUT_ROOT                 sprout's data directory
+  SPROUT_BIN                  the sprout executable, for calling back
+
+Built-in commands take precedence over plugins with the same name.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		plan := core.PlanPluginsCommand(core.PluginsContext{Plugins: FindPlugins(os.Getenv("PATH"))})
+		runPlan(plan, effects.NewRealEffects())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pluginsCmd)
+}
+
+// FindPlugins returns the plugins in the directories of path, a PATH
+// value. Like a shell, only the first executable of each name counts.
+func FindPlugins(path string) []core.Plugin {
+	var plugins []core.Plugin
+	seen := make(map[string]bool)
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			dir = "."
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), pluginPrefix)
+			if !ok || !pluginName.MatchString(name) || seen[name] {
+				continue
+			}
+			file := filepath.Join(dir, entry.Name())
+			if info, err := os.Stat(file); err != nil || info.IsDir() || info.Mode()&0o111 == 0 {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, core.Plugin{Name: name, Path: file, Shadowed: isBuiltinCommand(name)})
+		}
+	}
+	sort.SliceStable(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// isBuiltinCommand reports whether name is a sprout command or alias.
+// help and completion are only added once cobra executes, so they are
+// named here.
+func isBuiltinCommand(name string) bool {
+	switch name {
+	case "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	cmd, _, err := rootCmd.Find([]string{name})
+	return err == nil && cmd != rootCmd
+}
+
+// lookupPlugin returns the plugin executable args run, if args don't name
+// a built-in command but a sprout-<name> on PATH.
+func lookupPlugin(args []string) (string, bool) {
+	if len(args) == 0 || !pluginName.MatchString(args[0]) || isBuiltinCommand(args[0]) {
+		return "", false
+	}
+	path, err := exec.LookPath(pluginPrefix + args[0])
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// PluginEnv returns the environment variables a plugin gets on top of
+// sprout's own, describing the repository it was run in. Outside a git
+// repository they are empty.
+func PluginEnv(fx effects.Effects) []string {
+	repoRoot, _ := fx.GetRepoRoot()
+	mainWorktreePath, _ := fx.GetMainWorktreePath()
+	var worktreeRoot string
+	if mainWorktreePath != "" {
+		worktreeRoot, _ = fx.GetWorktreeRoot(mainWorktreePath)
+	}
+	sproutRoot, _ := fx.GetSproutRoot()
+	bin, _ := os.Executable()
+
+	return []string{
+		"SPROUT_REPO_ROOT=" + repoRoot,
+		"SPROUT_WORKTREE_PATH=" + repoRoot,
+		"SPROUT_MAIN_WORKTREE_PATH=" + mainWorktreePath,
+		"SPROUT_WORKTREE_ROOT=" + worktreeRoot,
+		"SPROUT_ROOT=" + sproutRoot,
+		"SPROUT_BIN=" + bin,
+	}
+}
+
+// runPlugin runs the plugin at path with args and exits with its exit code.
+// Ctrl-C reaches the plugin directly, since it shares the terminal, so
+// sprout waits for it to finish instead of dying first.
+func runPlugin(path string, args []string) {
+	c := exec.Command(path, args...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	c.Env = append(os.Environ(), PluginEnv(effects.NewRealEffects())...)
+
+	signal.Ignore(os.Interrupt)
+	err := c.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		if code := exitErr.ExitCode(); code > 0 {
+			os.Exit(code)
+		}
+		os.Exit(1) // Killed by a signal
+	case err != nil:
+		printError(fmt.Errorf("failed to run plugin %s: %w", path, err))
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
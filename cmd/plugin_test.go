@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindPlugins(t *testing.T) {
+	t.Parallel()
+
+	first, second := t.TempDir(), t.TempDir()
+	write := func(dir, name string, mode os.FileMode) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"), mode))
+		return path
+	}
+	deploy := write(first, "sprout-deploy", 0o755)
+	write(second, "sprout-deploy", 0o755) // Hidden by the first on PATH
+	list := write(second, "sprout-list", 0o755)
+	write(first, "sprout-notes", 0o644) // Not executable
+	write(first, "git-deploy", 0o755)
+	require.NoError(t, os.Mkdir(filepath.Join(second, "sprout-dir"), 0o755))
+
+	plugins := FindPlugins(strings.Join([]string{first, filepath.Join(first, "missing"), second}, string(os.PathListSeparator)))
+	assert.Equal(t, []core.Plugin{
+		{Name: "deploy", Path: deploy},
+		{Name: "list", Path: list, Shadowed: true},
+	}, plugins)
+}
+
+func TestLookupPlugin(t *testing.T) {
+	t.Parallel()
+
+	for _, args := range [][]string{nil, {"list"}, {"help"}, {"--dry-run"}, {"../deploy"}} {
+		_, ok := lookupPlugin(args)
+		assert.False(t, ok, "args %q", args)
+	}
+}
+
+func TestPluginEnv(t *testing.T) {
+	t.Parallel()
+
+	t.Run("describes the repository", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+		fx.SproutRoot = "/home/me/.local/share/sprout"
+		fx.WorktreeRoot = "/home/me/.local/share/sprout/repo-1234"
+
+		env := PluginEnv(fx)
+		assert.Contains(t, env, "SPROUT_REPO_ROOT=/test/repo")
+		assert.Contains(t, env, "SPROUT_WORKTREE_PATH=/test/repo")
+		assert.Contains(t, env, "SPROUT_MAIN_WORKTREE_PATH=/test/repo")
+		assert.Contains(t, env, "SPROUT_WORKTREE_ROOT=/home/me/.local/share/sprout/repo-1234")
+		assert.Contains(t, env, "SPROUT_ROOT=/home/me/.local/share/sprout")
+	})
+
+	t.Run("leaves the repository empty outside one", func(t *testing.T) {
+		t.Parallel()
+		fx := effects.NewTestEffects()
+		fx.GetRepoRootErr = errors.New("not a git repository")
+		fx.GetMainWorktreePathErr = errors.New("not a git repository")
+		fx.SproutRoot = "/home/me/.local/share/sprout"
+
+		env := PluginEnv(fx)
+		assert.Contains(t, env, "SPROUT_REPO_ROOT=")
+		assert.Contains(t, env, "SPROUT_MAIN_WORKTREE_PATH=")
+		assert.Contains(t, env, "SPROUT_WORKTREE_ROOT=")
+		assert.Contains(t, env, "SPROUT_ROOT=/home/me/.local/share/sprout")
+		assert.Zero(t, fx.GetWorktreeRootCalls)
+	})
+}
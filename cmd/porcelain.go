@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/m44rten1/sprout/internal/core"
+
+	"github.com/spf13/cobra"
+)
+
+// addPorcelainFlag adds --porcelain[=<version>] to cmd. A bare --porcelain
+// means the first version, as in git; pin the version in scripts.
+func addPorcelainFlag(cmd *cobra.Command, value *string) {
+	cmd.Flags().StringVar(value, "porcelain", "", "Print stable, tab-separated output for scripts (--porcelain=v1)")
+	cmd.Flags().Lookup("porcelain").NoOptDefVal = core.PorcelainV1
+}
+
+// porcelainFormat returns the porcelain format asked for with --porcelain,
+// or "" without the flag. An unknown version exits with an error.
+func porcelainFormat(value string) string {
+	if value == "" {
+		return ""
+	}
+	format, err := core.ParsePorcelain(value)
+	if err != nil {
+		exitWithError(err)
+	}
+	return format
+}
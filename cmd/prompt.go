@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	promptRawFlag     bool
+	promptJSONFlag    bool
+	promptRefreshFlag bool
+)
+
+// PromptOptions holds the command-line flags of the prompt command.
+type PromptOptions struct {
+	Raw     bool
+	JSON    bool
+	Refresh bool // Recompute the status with git and update the cache
+}
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Print a compact worktree segment for your shell prompt",
+	Long: `Print a compact description of the current worktree for a shell
+prompt, such as "🌱 feature ✗↑". Outside a worktree nothing is printed.
+
+It never runs git: the branch is read from .git directly and the status
+icons come from the cache that 'sprout list' and shell completion keep up
+to date. Use --refresh (e.g. from an async prompt hook) to update the cache
+for the current worktree.
+
+  --raw   prints "head dirty ahead behind unmerged", e.g. "feature 1 2 0 0"
+  --json  prints a single-line JSON object
+
+For example, in zsh:
+
+  setopt PROMPT_SUBST
+  PROMPT='$(sprout prompt) %# '`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+
+		dir, err := os.Getwd()
+		if err != nil {
+			return // Nothing to describe; never break the prompt
+		}
+
+		ctx, err := BuildPromptContext(fx, dir, PromptOptions{
+			Raw:     promptRawFlag,
+			JSON:    promptJSONFlag,
+			Refresh: promptRefreshFlag,
+		})
+		if err != nil {
+			exitWithError(err)
+		}
+
+		plan := core.PlanPromptCommand(ctx)
+		runPlan(plan, fx)
+	},
+}
+
+// BuildPromptContext gathers the inputs for the prompt command from the
+// worktree containing dir. Outside a worktree the context is empty, and a
+// missing or unreadable cache just leaves the status unknown.
+func BuildPromptContext(fx effects.Effects, dir string, opts PromptOptions) (core.PromptContext, error) {
+	ctx := core.PromptContext{Raw: opts.Raw, JSON: opts.JSON}
+
+	root, head, err := fx.ReadHead(dir)
+	if errors.Is(err, git.ErrNotInWorktree) {
+		return ctx, nil
+	}
+	if err != nil {
+		return core.PromptContext{}, err
+	}
+	ctx.WorktreePath = root
+	ctx.Head = head
+
+	if opts.Refresh {
+		ctx.Status = fx.GetWorktreeStatus(root)
+		ctx.StatusKnown = true
+		ctx.UpdatedAt = time.Now()
+		if err := fx.CacheStatuses(map[string]git.WorktreeStatus{root: ctx.Status}); err != nil {
+			return core.PromptContext{}, fmt.Errorf("failed to update status cache: %w", err)
+		}
+		return ctx, nil
+	}
+
+	if cached, ok, err := fx.LoadCachedStatus(root); err == nil && ok {
+		ctx.Status = cached.Status()
+		ctx.StatusKnown = true
+		ctx.UpdatedAt = cached.UpdatedAt
+	}
+	return ctx, nil
+}
+
+func init() {
+	rootCmd.AddCommand(promptCmd)
+	promptCmd.Flags().BoolVar(&promptRawFlag, "raw", false, "Print space-separated fields instead of the segment")
+	promptCmd.Flags().BoolVar(&promptJSONFlag, "json", false, "Print the worktree status as JSON")
+	promptCmd.Flags().BoolVar(&promptRefreshFlag, "refresh", false, "Recompute the status with git and update the cache")
+}
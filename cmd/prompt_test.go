@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/build"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/sprout"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildPromptContext(t *testing.T) {
+	t.Parallel()
+
+	newFx := func() *effects.TestEffects {
+		fx := effects.NewTestEffects()
+		fx.Heads["/sprout/feature"] = "feature"
+		return fx
+	}
+
+	t.Run("reads the status from the cache only", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		updated := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+		fx.CachedStatuses["/sprout/feature"] = sprout.CachedStatus{Path: "/sprout/feature", Dirty: true, UpdatedAt: updated}
+
+		ctx, err := BuildPromptContext(fx, "/sprout/feature/src", PromptOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "/sprout/feature", ctx.WorktreePath)
+		assert.Equal(t, "feature", ctx.Head)
+		assert.True(t, ctx.Status.Dirty)
+		assert.True(t, ctx.StatusKnown)
+		assert.Equal(t, updated, ctx.UpdatedAt)
+		assert.Equal(t, 0, fx.GetWorktreeStatusCalls)
+		assert.Empty(t, fx.GitCommands)
+	})
+
+	t.Run("uncached status is unknown", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, err := BuildPromptContext(newFx(), "/sprout/feature", PromptOptions{})
+		require.NoError(t, err)
+		assert.False(t, ctx.StatusKnown)
+	})
+
+	t.Run("outside a worktree", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, err := BuildPromptContext(newFx(), "/tmp", PromptOptions{JSON: true})
+		require.NoError(t, err)
+		assert.Empty(t, ctx.WorktreePath)
+	})
+
+	t.Run("refresh updates the cache", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.WorktreeStatuses["/sprout/feature"] = git.WorktreeStatus{Behind: 3}
+
+		ctx, err := BuildPromptContext(fx, "/sprout/feature", PromptOptions{Refresh: true})
+		require.NoError(t, err)
+		assert.Equal(t, 3, ctx.Status.Behind)
+		assert.Equal(t, 3, fx.CachedStatuses["/sprout/feature"].Behind)
+	})
+}
+
+func TestCacheStatuses(t *testing.T) {
+	t.Parallel()
+	fx := effects.NewTestEffects()
+
+	build.CacheStatuses(fx, []core.RepoDisplay{{
+		Worktrees: []core.WorktreeDisplayItem{
+			{Path: "/repo", IsMain: true},
+			{Path: "/sprout/feature", Status: git.WorktreeStatus{Dirty: true}},
+		},
+	}})
+
+	assert.Len(t, fx.CachedStatuses, 2)
+	assert.True(t, fx.CachedStatuses["/sprout/feature"].Dirty)
+}
@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/sprout"
+
+	"github.com/spf13/cobra"
+)
+
+var pruneYes bool
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove orphaned directories and expired temporary worktrees",
+	Long: `Find directories under the sprout root that git no longer knows as
+worktrees, list them with their sizes, and remove them once you confirm.
+
+They are left behind when a worktree is removed with git instead of sprout
+(kept by an untracked build output, say), when its repository is deleted,
+or when a command is interrupted. Directories holding a worktree of any
+repository that still exists are never listed.
+
+Scratch directories (see 'sprout info') of worktrees git no longer knows
+are listed the same way, while the ones in use are never touched.
+
+A directory with a .git file was a worktree whose repository went away. If
+the repository was only moved, run 'sprout repair' in it instead, which
+links the worktree up again.
+
+Temporary worktrees created with 'sprout add --ttl' whose time has run out
+are listed too, and removed through git; their branches are kept. Expired
+worktrees with uncommitted changes or untracked files, or that are pinned
+or locked, are listed but kept. One that git refuses to remove doesn't
+stop the others; prune lists it at the end and exits with 1. To remove
+them in the background, run prune from cron:
+
+  0 * * * *  sprout prune --yes
+
+Use --dry-run to only list what would be removed, and --yes to remove it
+without asking, which non-interactive runs require.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+
+		ctx, err := BuildPruneContext(fx)
+		if err != nil {
+			exitWithError(err)
+		}
+
+		// The listing is what --dry-run is for, so it is printed either way
+		if err := effects.ExecutePlan(core.PlanPruneReport(ctx), fx); err != nil {
+			exitWithError(err)
+		}
+		if len(ctx.Orphans) == 0 && len(ctx.Removable()) == 0 {
+			return
+		}
+		if !pruneYes && !dryRunFlag {
+			ok, err := fx.Confirm(pruneQuestion(ctx))
+			if err != nil {
+				exitWithError(fmt.Errorf("%w; pass --yes to remove them", err))
+			}
+			if !ok {
+				fmt.Println("Nothing removed.")
+				return
+			}
+		}
+		if len(ctx.Orphans) > 0 {
+			runPlan(core.PlanPruneDelete(ctx), fx)
+		}
+		removable := ctx.Removable()
+		results := make([]core.PruneResult, 0, len(removable))
+		for _, wt := range removable {
+			results = append(results, pruneExpired(fx, wt))
+		}
+		if len(results) > 0 && !dryRunFlag {
+			runPlan(core.PlanPruneExpiredSummary(ctx, results), fx)
+		}
+	},
+}
+
+// pruneExpired removes one expired worktree. A failure is reported in the
+// summary instead of stopping prune, so the other worktrees still go.
+func pruneExpired(fx effects.Effects, wt core.ExpiredWorktree) core.PruneResult {
+	result := core.PruneResult{Worktree: wt}
+	plan := core.PlanPruneExpired(wt)
+	if dryRunFlag {
+		printPlan(plan)
+		return result
+	}
+	if err := effects.ExecutePlan(plan, fx); err != nil {
+		result.Failed = err.Error()
+		var gitErr *git.GitError
+		if errors.As(err, &gitErr) {
+			result.Failed = gitErr.Summary()
+		}
+	}
+	return result
+}
+
+// pruneQuestion asks to remove what the report listed.
+func pruneQuestion(ctx core.PruneContext) string {
+	var parts []string
+	if len(ctx.Orphans) > 0 {
+		parts = append(parts, fmt.Sprintf("%d director(ies)", len(ctx.Orphans)))
+	}
+	if n := len(ctx.Removable()); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d expired worktree(s)", n))
+	}
+	return "Remove " + strings.Join(parts, " and ") + "?"
+}
+
+// BuildPruneContext finds the orphaned directories under the sprout root,
+// with their sizes, and the temporary worktrees that have expired. A
+// directory is in use when it is, or holds, a worktree of a repository
+// sprout manages, or of any repository git can still find from a worktree
+// inside it.
+func BuildPruneContext(fx effects.Effects) (core.PruneContext, error) {
+	sproutRoot, err := fx.GetSproutRoot()
+	if err != nil {
+		return core.PruneContext{}, fmt.Errorf("get sprout root: %w", err)
+	}
+	if !effects.Exists(fx, sproutRoot) {
+		return core.PruneContext{}, nil
+	}
+
+	repos, err := collectAllReposWithEffects(fx)
+	if err != nil {
+		return core.PruneContext{}, err
+	}
+	// Every worktree git has recorded, whether it still exists or not
+	known := make(map[string]bool)
+	for _, repo := range repos {
+		worktrees, err := fx.ListWorktrees(repo.MainPath)
+		if err != nil {
+			return core.PruneContext{}, fmt.Errorf("list worktrees of %s: %w", repo.MainPath, err)
+		}
+		for _, wt := range worktrees {
+			known[wt.Path] = true
+		}
+	}
+
+	orphans := findOrphanedDirs(fx, sproutRoot, known)
+	scratchRoot := filepath.Join(sproutRoot, sprout.ScratchDirName)
+	orphans = slices.DeleteFunc(orphans, func(o core.OrphanedDir) bool { return o.Path == scratchRoot })
+	orphans = append(orphans, findOrphanedScratch(fx, sproutRoot, known)...)
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].Path < orphans[j].Path })
+
+	// Sizing walks every file, so directories are sized in parallel
+	var wg sync.WaitGroup
+	for i := range orphans {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			orphans[i].Size, _ = fx.DirSize(orphans[i].Path)
+		}()
+	}
+	wg.Wait()
+
+	home, _ := fx.UserHomeDir()
+	return core.PruneContext{Orphans: orphans, Expired: findExpired(fx, repos, time.Now()), Home: home}, nil
+}
+
+// findExpired returns the temporary worktrees whose expiry has passed,
+// marking the ones to keep. Without metadata nothing is temporary.
+func findExpired(fx effects.Effects, repos []core.RepoDisplay, now time.Time) []core.ExpiredWorktree {
+	metadata, err := fx.LoadWorktreeMetadata()
+	if err != nil || len(metadata) == 0 {
+		return nil
+	}
+
+	var expired []core.ExpiredWorktree
+	for _, repo := range repos {
+		for _, wt := range repo.Worktrees {
+			meta := metadata[wt.Path]
+			if wt.IsMain || meta.ExpiresAt.IsZero() || meta.ExpiresAt.After(now) {
+				continue
+			}
+			item := core.ExpiredWorktree{MainPath: repo.MainPath, Path: wt.Path, Branch: wt.Branch}
+			switch {
+			case meta.Pinned:
+				item.Kept = "pinned"
+			case wt.Locked:
+				item.Kept = "locked"
+			case wt.Status.UntrackedOnly:
+				item.Kept = "untracked files"
+			case wt.Status.Dirty:
+				item.Kept = "uncommitted changes"
+			}
+			expired = append(expired, item)
+		}
+	}
+	return expired
+}
+
+// findOrphanedDirs returns the topmost directories under dir that hold no
+// known worktree, so each can be removed as a whole. Worktrees git finds
+// from inside a directory are added to known before it is judged.
+func findOrphanedDirs(fx effects.Effects, dir string, known map[string]bool) []core.OrphanedDir {
+	entries, err := fx.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var orphans []core.OrphanedDir
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+
+		hasGit := false
+		if !known[path] && !holdsKnown(path, known) {
+			candidates := scanForGitDirsWithEffects(fx, path, 3)
+			if effects.Exists(fx, filepath.Join(path, ".git")) {
+				candidates = append(candidates, path)
+			}
+			hasGit = len(candidates) > 0
+			for _, candidate := range candidates {
+				if worktrees, err := fx.ListWorktrees(candidate); err == nil {
+					for _, wt := range worktrees {
+						known[wt.Path] = true
+					}
+				}
+			}
+		}
+
+		switch {
+		case known[path]:
+		case holdsKnown(path, known):
+			orphans = append(orphans, findOrphanedDirs(fx, path, known)...)
+		default:
+			orphans = append(orphans, core.OrphanedDir{Path: path, HasGit: hasGit})
+		}
+	}
+	return orphans
+}
+
+// findOrphanedScratch returns the scratch directories whose worktree git
+// no longer knows, left behind when a worktree is removed outside sprout.
+func findOrphanedScratch(fx effects.Effects, sproutRoot string, known map[string]bool) []core.OrphanedDir {
+	scratchRoot := filepath.Join(sproutRoot, sprout.ScratchDirName)
+	entries, err := fx.ReadDir(scratchRoot)
+	if err != nil {
+		return nil
+	}
+
+	inUse := make(map[string]bool, len(known))
+	for path := range known {
+		inUse[sprout.ScratchDir(sproutRoot, path)] = true
+	}
+	var orphans []core.OrphanedDir
+	for _, entry := range entries {
+		path := filepath.Join(scratchRoot, entry.Name())
+		if entry.IsDir() && !inUse[path] {
+			orphans = append(orphans, core.OrphanedDir{Path: path})
+		}
+	}
+	return orphans
+}
+
+// holdsKnown reports whether a known worktree lies inside dir.
+func holdsKnown(dir string, known map[string]bool) bool {
+	for path := range known {
+		if strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+	pruneCmd.Flags().BoolVarP(&pruneYes, "yes", "y", false, "Remove without asking")
+}
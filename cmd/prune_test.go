@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/sprout"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildPruneContext(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	feature := filepath.Join(root, "app-1234", "feature", "app")
+	mustMkdirAll(t, feature)
+	mustWriteFile(t, filepath.Join(feature, ".git"), "gitdir: /code/app/.git/worktrees/app")
+	mustMkdirAll(t, filepath.Join(root, "app-1234", "stale", "app", "node_modules"))
+	broken := filepath.Join(root, "gone-5678", "old", "gone")
+	mustMkdirAll(t, broken)
+	mustWriteFile(t, filepath.Join(broken, ".git"), "gitdir: /code/gone/.git/worktrees/gone")
+	mustWriteFile(t, filepath.Join(root, "repos.json"), "{}")
+	mustMkdirAll(t, sprout.ScratchDir(root, feature))
+	leftover := filepath.Join(root, sprout.ScratchDirName, "deadbeef")
+	mustMkdirAll(t, leftover)
+
+	// Mirror the tree into TestEffects
+	fx := effects.NewTestEffects()
+	fx.SproutRoot = root
+	fx.UserHome = "/home/me"
+	require.NoError(t, filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		require.NoError(t, err)
+		fx.Files[path] = true
+		if d.IsDir() {
+			entries, err := os.ReadDir(path)
+			require.NoError(t, err)
+			fx.DirEntries[path] = entries
+		}
+		return nil
+	}))
+	fx.Files["/code/app"] = true
+	fx.Worktrees = []git.Worktree{
+		{Path: "/code/app", Branch: "main"},
+		{Path: feature, Branch: "feature"},
+	}
+	fx.DirSizes[filepath.Join(root, "app-1234", "stale")] = 2048
+
+	ctx, err := BuildPruneContext(fx)
+	require.NoError(t, err)
+	assert.Equal(t, "/home/me", ctx.Home)
+	assert.Equal(t, []core.OrphanedDir{
+		{Path: filepath.Join(root, "app-1234", "stale"), Size: 2048},
+		{Path: filepath.Join(root, "gone-5678"), HasGit: true},
+		{Path: leftover},
+	}, ctx.Orphans, "the scratch directory of a live worktree is kept")
+}
+
+func TestFindExpired(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	fx := effects.NewTestEffects()
+	fx.WorktreeMetadata["/sprout/app/spike"] = sprout.WorktreeMeta{ExpiresAt: now.Add(-time.Hour)}
+	fx.WorktreeMetadata["/sprout/app/dirty"] = sprout.WorktreeMeta{ExpiresAt: now.Add(-time.Hour)}
+	fx.WorktreeMetadata["/sprout/app/pinned"] = sprout.WorktreeMeta{ExpiresAt: now.Add(-time.Hour), Pinned: true}
+	fx.WorktreeMetadata["/sprout/app/locked"] = sprout.WorktreeMeta{ExpiresAt: now.Add(-time.Hour)}
+	fx.WorktreeMetadata["/sprout/app/later"] = sprout.WorktreeMeta{ExpiresAt: now.Add(time.Hour)}
+
+	repos := []core.RepoDisplay{{MainPath: "/code/app", Worktrees: []core.WorktreeDisplayItem{
+		{Branch: "main", Path: "/code/app", IsMain: true},
+		{Branch: "spike", Path: "/sprout/app/spike"},
+		{Branch: "dirty", Path: "/sprout/app/dirty", Status: git.WorktreeStatus{Dirty: true}},
+		{Branch: "pinned", Path: "/sprout/app/pinned"},
+		{Branch: "locked", Path: "/sprout/app/locked", Locked: true},
+		{Branch: "later", Path: "/sprout/app/later"},
+		{Branch: "feature", Path: "/sprout/app/feature"},
+	}}}
+
+	assert.Equal(t, []core.ExpiredWorktree{
+		{MainPath: "/code/app", Path: "/sprout/app/spike", Branch: "spike"},
+		{MainPath: "/code/app", Path: "/sprout/app/dirty", Branch: "dirty", Kept: "uncommitted changes"},
+		{MainPath: "/code/app", Path: "/sprout/app/pinned", Branch: "pinned", Kept: "pinned"},
+		{MainPath: "/code/app", Path: "/sprout/app/locked", Branch: "locked", Kept: "locked"},
+	}, findExpired(fx, repos, now))
+}
+
+func TestPruneQuestion(t *testing.T) {
+	t.Parallel()
+	orphans := []core.OrphanedDir{{Path: "/sprout/gone"}}
+	expired := []core.ExpiredWorktree{{Path: "/sprout/app/spike"}, {Path: "/sprout/app/try", Kept: "pinned"}}
+
+	assert.Equal(t, "Remove 1 director(ies)?", pruneQuestion(core.PruneContext{Orphans: orphans}))
+	assert.Equal(t, "Remove 1 expired worktree(s)?", pruneQuestion(core.PruneContext{Expired: expired}))
+	assert.Equal(t, "Remove 1 director(ies) and 1 expired worktree(s)?", pruneQuestion(core.PruneContext{Orphans: orphans, Expired: expired}))
+}
+
+func TestBuildPruneContext_NoSproutRoot(t *testing.T) {
+	t.Parallel()
+	fx := effects.NewTestEffects()
+
+	ctx, err := BuildPruneContext(fx)
+	require.NoError(t, err)
+	assert.Empty(t, ctx.Orphans)
+}
+
+func TestPrunePlanRemovesOrphans(t *testing.T) {
+	t.Parallel()
+	fx := effects.NewTestEffects()
+	fx.Files["/sprout/gone-5678"] = true
+	fx.Files["/sprout/gone-5678/old/gone"] = true
+
+	ctx := core.PruneContext{Orphans: []core.OrphanedDir{{Path: "/sprout/gone-5678", Size: 10}}}
+	require.NoError(t, effects.ExecutePlan(core.PlanPruneDelete(ctx), fx))
+	assert.Equal(t, []string{"/sprout/gone-5678"}, fx.RemovedDirs)
+	assert.False(t, fx.Files["/sprout/gone-5678/old/gone"])
+}
+
+func TestPruneExpired(t *testing.T) {
+	t.Parallel()
+	fx := effects.NewTestEffects()
+	expiresAt := time.Now().Add(-time.Hour)
+	fx.WorktreeMetadata["/sprout/app/spike"] = sprout.WorktreeMeta{ExpiresAt: expiresAt}
+	fx.WorktreeMetadata["/sprout/app/dirty"] = sprout.WorktreeMeta{ExpiresAt: expiresAt}
+	fx.GitCommandErrors["/code/app\nworktree remove /sprout/app/dirty"] = &git.GitError{
+		Args:   []string{"worktree", "remove", "/sprout/app/dirty"},
+		Stderr: "fatal: '/sprout/app/dirty' contains modified or untracked files, use --force to delete it",
+	}
+
+	spike := core.ExpiredWorktree{MainPath: "/code/app", Path: "/sprout/app/spike", Branch: "spike"}
+	dirty := core.ExpiredWorktree{MainPath: "/code/app", Path: "/sprout/app/dirty", Branch: "dirty"}
+
+	failed := pruneExpired(fx, dirty)
+	assert.Contains(t, failed.Failed, "contains modified or untracked files")
+	assert.Equal(t, expiresAt, fx.WorktreeMetadata["/sprout/app/dirty"].ExpiresAt, "a worktree git kept stays temporary")
+
+	removed := pruneExpired(fx, spike)
+	assert.Empty(t, removed.Failed, "the next worktree is removed after one failed")
+	assert.True(t, fx.WorktreeMetadata["/sprout/app/spike"].ExpiresAt.IsZero(), "the expiry is cleared with the worktree")
+}
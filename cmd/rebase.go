@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/trust"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	rebaseAllMerge     bool
+	rebaseAllAutostash bool
+	rebaseAllNoHooks   bool
+)
+
+// RebaseAllOptions holds the command-line flags that influence rebase-all.
+type RebaseAllOptions struct {
+	Merge     bool // Merge the default branch in instead of rebasing
+	Autostash bool // Stash uncommitted changes around the update
+	NoHooks   bool // Skip post_sync hooks
+}
+
+var rebaseAllCmd = &cobra.Command{
+	Use:   "rebase-all",
+	Short: "Rebase every worktree onto the default branch",
+	Long: `Fetch the default branch and rebase every sprout worktree of the
+repository onto it, or merge it in with --merge, then print what happened to
+each worktree.
+
+Worktrees with uncommitted changes are skipped, unless --autostash stashes
+the changes around the update. A worktree whose update conflicts is put
+back as it was and listed with the conflicting files, for you to update by
+hand; the other worktrees are still updated. sprout exits with 1 when any
+update failed.
+
+The default branch is default_branch from .sprout.yml, or else the one git
+reports. Repositories without a remote are updated onto the local branch.
+
+Each updated worktree then runs the post_sync hooks from .sprout.yml, such
+as reinstalling dependencies, unless --no-hooks is given. A failing hook
+leaves the update in place and makes sprout exit with 1 too.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+		defer lockRepo(fx)()
+
+		ctx, err := BuildRebaseAllContext(fx, RebaseAllOptions{
+			Merge:     rebaseAllMerge,
+			Autostash: rebaseAllAutostash,
+			NoHooks:   rebaseAllNoHooks,
+		})
+		if err != nil {
+			if errors.Is(err, core.ErrNoSproutWorktrees) {
+				fmt.Println("No sprout-managed worktrees found.")
+				return
+			}
+			exitWithError(err)
+		}
+
+		runPlan(core.PlanRebaseAllFetch(ctx), fx)
+		results := make([]core.RebaseResult, 0, len(ctx.Worktrees))
+		for _, wt := range ctx.Worktrees {
+			results = append(results, rebaseWorktree(fx, ctx, wt))
+		}
+		if !dryRunFlag {
+			runPlan(core.PlanRebaseAllSummary(ctx, results), fx)
+		}
+	},
+}
+
+// BuildRebaseAllContext gathers the sprout worktrees of the repository with
+// their branch and whether they have uncommitted changes, and the post_sync
+// hooks with their trust. How far behind each worktree is is only known
+// after the fetch (see rebaseWorktree).
+func BuildRebaseAllContext(fx effects.Effects, opts RebaseAllOptions) (core.RebaseAllContext, error) {
+	repoRoot, err := fx.GetRepoRoot()
+	if err != nil {
+		return core.RebaseAllContext{}, fmt.Errorf("not a git repository: %w", err)
+	}
+	mainWorktreePath, err := fx.GetMainWorktreePath()
+	if err != nil {
+		return core.RebaseAllContext{}, fmt.Errorf("failed to get main worktree: %w", err)
+	}
+	cfg, err := fx.LoadConfig(repoRoot, mainWorktreePath)
+	if err != nil {
+		return core.RebaseAllContext{}, fmt.Errorf("failed to load config: %w", err)
+	}
+	defaultBranch := cfg.DefaultBranch
+	if defaultBranch == "" {
+		if defaultBranch, err = fx.GetDefaultBranch(mainWorktreePath); err != nil {
+			return core.RebaseAllContext{}, fmt.Errorf("failed to get default branch: %w", err)
+		}
+	}
+	hasRemote, err := fx.HasRemote(repoRoot)
+	if err != nil {
+		return core.RebaseAllContext{}, fmt.Errorf("failed to list remotes: %w", err)
+	}
+
+	sproutRoot, err := fx.GetWorktreeRoot(mainWorktreePath)
+	if err != nil {
+		return core.RebaseAllContext{}, fmt.Errorf("failed to get sprout root: %w", err)
+	}
+	worktrees, err := fx.ListWorktrees(repoRoot)
+	if err != nil {
+		return core.RebaseAllContext{}, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	adopted, err := fx.ListAdoptedWorktrees()
+	if err != nil {
+		return core.RebaseAllContext{}, fmt.Errorf("failed to load adopted worktrees: %w", err)
+	}
+	managed := core.FilterSproutWorktrees(worktrees, sproutRoot, adopted...)
+	if len(managed) == 0 {
+		return core.RebaseAllContext{}, core.ErrNoSproutWorktrees
+	}
+
+	ctx := core.RebaseAllContext{
+		RepoRoot:         repoRoot,
+		DefaultBranch:    defaultBranch,
+		HasRemote:        hasRemote,
+		Merge:            opts.Merge,
+		Autostash:        opts.Autostash,
+		MainWorktreePath: mainWorktreePath,
+	}
+
+	// A policy denial is not an error: hooks are skipped and the planner says why
+	if cfg.HasSyncHooks() && !opts.NoHooks {
+		ctx.SyncHooks = cfg.Hooks.PostSync
+		ctx.IsTrusted, err = fx.IsTrusted(mainWorktreePath, mainWorktreePath)
+		if errors.Is(err, trust.ErrDeniedByPolicy) {
+			ctx.HooksDenied = true
+		} else if err != nil {
+			return core.RebaseAllContext{}, fmt.Errorf("failed to check trust status: %w", err)
+		}
+	}
+
+	for _, wt := range managed {
+		status, err := fx.RunGitCommand(wt.Path, "status", "--porcelain")
+		if err != nil {
+			return core.RebaseAllContext{}, fmt.Errorf("failed to check for changes in %s: %w", wt.Path, err)
+		}
+		ctx.Worktrees = append(ctx.Worktrees, core.RebaseWorktree{
+			Path:   wt.Path,
+			Branch: wt.Branch,
+			Dirty:  strings.TrimSpace(status) != "",
+		})
+	}
+	return ctx, nil
+}
+
+// rebaseWorktree updates one worktree, unless RebaseSkipReason says to
+// leave it alone. A failed update is aborted, after collecting the
+// conflicting files for the summary.
+func rebaseWorktree(fx effects.Effects, ctx core.RebaseAllContext, wt core.RebaseWorktree) core.RebaseResult {
+	result := core.RebaseResult{Branch: wt.Branch}
+	if wt.Branch != "" {
+		out, err := fx.RunGitCommand(wt.Path, "rev-list", "--count", "HEAD.."+ctx.Target())
+		if err != nil {
+			result.Failed = fmt.Sprintf("can't compare with %s: %v", ctx.Target(), err)
+			return result
+		}
+		fmt.Sscanf(out, "%d", &wt.Behind)
+	}
+	if result.Skipped = core.RebaseSkipReason(ctx, wt); result.Skipped != "" {
+		return result
+	}
+
+	plan := core.PlanRebaseWorktree(ctx, wt)
+	hooks := core.PlanSyncHooks(ctx, wt)
+	if dryRunFlag {
+		printPlan(core.Plan{Actions: append(plan.Actions, hooks.Actions...)})
+		return result
+	}
+	err := effects.ExecutePlan(plan, fx)
+	if err == nil {
+		result.Updated = true
+		// The update stands either way; the summary reports the hooks
+		if err := effects.ExecutePlan(hooks, fx); err != nil {
+			result.HooksFailed = err.Error()
+		}
+		return result
+	}
+
+	if out, diffErr := fx.RunGitCommand(wt.Path, "diff", "--name-only", "--diff-filter=U"); diffErr == nil && out != "" {
+		result.Conflicts = strings.Split(out, "\n")
+	} else {
+		result.Failed = err.Error()
+		var gitErr *git.GitError
+		if errors.As(err, &gitErr) {
+			result.Failed = gitErr.Summary()
+		}
+	}
+	// Nothing to abort when git refused to start, e.g. over untracked files
+	_ = effects.ExecutePlan(core.PlanRebaseAbort(ctx, wt), fx)
+	return result
+}
+
+func init() {
+	rootCmd.AddCommand(rebaseAllCmd)
+	rebaseAllCmd.Flags().BoolVar(&rebaseAllMerge, "merge", false, "Merge the default branch into each worktree instead of rebasing")
+	rebaseAllCmd.Flags().BoolVar(&rebaseAllAutostash, "autostash", false, "Stash uncommitted changes around the update instead of skipping the worktree")
+	rebaseAllCmd.Flags().BoolVar(&rebaseAllNoHooks, "no-hooks", false, "Skip running post_sync hooks in the updated worktrees")
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRebaseTestFx() *effects.TestEffects {
+	fx := baseTestFx()
+	fx.WorktreeRoot = "/sprout/repo"
+	fx.Worktrees = []git.Worktree{
+		{Path: "/test/repo", Branch: "main"},
+		{Path: "/sprout/repo/a", Branch: "a"},
+		{Path: "/sprout/repo/b", Branch: "b"},
+	}
+	fx.GitCommandOutput["/sprout/repo/b\nstatus --porcelain"] = " M go.mod"
+	return fx
+}
+
+func TestBuildRebaseAllContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sprout worktrees with their changes", func(t *testing.T) {
+		t.Parallel()
+		fx := newRebaseTestFx()
+		fx.DefaultBranch = "develop"
+
+		ctx, err := BuildRebaseAllContext(fx, RebaseAllOptions{Merge: true})
+		require.NoError(t, err)
+		assert.Equal(t, "develop", ctx.DefaultBranch)
+		assert.True(t, ctx.HasRemote)
+		assert.True(t, ctx.Merge)
+		assert.Equal(t, []core.RebaseWorktree{
+			{Path: "/sprout/repo/a", Branch: "a"},
+			{Path: "/sprout/repo/b", Branch: "b", Dirty: true},
+		}, ctx.Worktrees)
+	})
+
+	t.Run("default_branch from the config wins", func(t *testing.T) {
+		t.Parallel()
+		fx := newRebaseTestFx()
+		fx.Config = &config.Config{DefaultBranch: "trunk"}
+
+		ctx, err := BuildRebaseAllContext(fx, RebaseAllOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "trunk", ctx.DefaultBranch)
+	})
+
+	t.Run("post_sync hooks with their trust", func(t *testing.T) {
+		t.Parallel()
+		fx := newRebaseTestFx()
+		fx.Config = &config.Config{Hooks: config.HooksConfig{PostSync: []string{"npm ci"}}}
+		fx.TrustedRepos["/test/repo"] = true
+
+		ctx, err := BuildRebaseAllContext(fx, RebaseAllOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"npm ci"}, ctx.SyncHooks)
+		assert.True(t, ctx.IsTrusted)
+
+		ctx, err = BuildRebaseAllContext(fx, RebaseAllOptions{NoHooks: true})
+		require.NoError(t, err)
+		assert.Empty(t, ctx.SyncHooks)
+	})
+
+	t.Run("no sprout worktrees", func(t *testing.T) {
+		t.Parallel()
+		fx := newRebaseTestFx()
+		fx.Worktrees = fx.Worktrees[:1]
+
+		_, err := BuildRebaseAllContext(fx, RebaseAllOptions{})
+		assert.ErrorIs(t, err, core.ErrNoSproutWorktrees)
+	})
+}
+
+func TestRebaseWorktree(t *testing.T) {
+	t.Parallel()
+	ctx := core.RebaseAllContext{RepoRoot: "/test/repo", DefaultBranch: "main", HasRemote: true}
+	wt := core.RebaseWorktree{Path: "/sprout/repo/a", Branch: "a"}
+
+	t.Run("updated", func(t *testing.T) {
+		t.Parallel()
+		fx := newRebaseTestFx()
+		fx.GitCommandOutput["/sprout/repo/a\nrev-list --count HEAD..origin/main"] = "3"
+
+		result := rebaseWorktree(fx, ctx, wt)
+		assert.Equal(t, core.RebaseResult{Branch: "a", Updated: true}, result)
+		assert.Contains(t, fx.GitCommands, effects.GitCmd{Dir: "/sprout/repo/a", Args: []string{"rebase", "origin/main"}})
+	})
+
+	t.Run("runs post_sync hooks after the update", func(t *testing.T) {
+		t.Parallel()
+		fx := newRebaseTestFx()
+		fx.GitCommandOutput["/sprout/repo/a\nrev-list --count HEAD..origin/main"] = "3"
+		fx.RunHooksErr = errors.New("hook command failed with exit code 1: npm ci")
+		hooked := ctx
+		hooked.MainWorktreePath = "/test/repo"
+		hooked.SyncHooks = []string{"npm ci"}
+		hooked.IsTrusted = true
+
+		result := rebaseWorktree(fx, hooked, wt)
+		assert.True(t, result.Updated, "a failing hook keeps the update")
+		assert.Contains(t, result.HooksFailed, "npm ci")
+		require.Len(t, fx.RunHooksInvocations, 1)
+		assert.Equal(t, "/sprout/repo/a", fx.RunHooksInvocations[0].WorktreePath)
+		assert.NotContains(t, fx.GitCommands, effects.GitCmd{Dir: "/sprout/repo/a", Args: []string{"rebase", "--abort"}})
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		t.Parallel()
+		fx := newRebaseTestFx()
+		fx.GitCommandOutput["/sprout/repo/a\nrev-list --count HEAD..origin/main"] = "0"
+
+		result := rebaseWorktree(fx, ctx, wt)
+		assert.Equal(t, core.RebaseResult{Branch: "a", Skipped: "up to date"}, result)
+	})
+
+	t.Run("conflicts are aborted", func(t *testing.T) {
+		t.Parallel()
+		fx := newRebaseTestFx()
+		fx.GitCommandOutput["/sprout/repo/a\nrev-list --count HEAD..origin/main"] = "1"
+		fx.GitCommandErrors["/sprout/repo/a\nrebase origin/main"] = errors.New("exit status 1")
+		fx.GitCommandOutput["/sprout/repo/a\ndiff --name-only --diff-filter=U"] = "go.mod\nmain.go"
+
+		result := rebaseWorktree(fx, ctx, wt)
+		assert.Equal(t, []string{"go.mod", "main.go"}, result.Conflicts)
+		assert.False(t, result.Updated)
+		assert.Equal(t, effects.GitCmd{Dir: "/sprout/repo/a", Args: []string{"rebase", "--abort"}}, fx.GitCommands[len(fx.GitCommands)-1])
+	})
+}
@@ -5,62 +5,75 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/m44rten1/sprout/internal/build"
 	"github.com/m44rten1/sprout/internal/core"
 	"github.com/m44rten1/sprout/internal/effects"
 
 	"github.com/spf13/cobra"
 )
 
+var removePorcelain string
+
 var removeCmd = &cobra.Command{
 	Use:   "remove [branch-or-path]",
 	Short: "Remove a worktree",
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Remove a worktree, given by branch or path, or picked interactively.
+As with 'sprout open', a unique part of the branch is enough, but then
+sprout asks before removing the worktree it found, even with --force.
+
+With --all-merged, every sprout worktree in the repository that is clean
+and whose commits are all merged is removed without asking, followed by a
+summary of what was removed and what was skipped, and why.
+
+--porcelain=v1 prints one stable, tab-separated line per worktree instead:
+its path, its branch, 1 if it was removed or 0 if it was kept, and why it
+was kept.`,
+	Args: cobra.MaximumNArgs(1),
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		// Only complete the first argument
 		if len(args) > 0 {
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		}
 
-		// Use effects interface for consistency
+		return completeSproutWorktrees(toComplete)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		porcelain := porcelainFormat(removePorcelain)
 		fx := effects.NewRealEffects()
 
-		repoRoot, err := fx.GetRepoRoot()
+		force, err := cmd.Flags().GetBool("force")
 		if err != nil {
-			return nil, cobra.ShellCompDirectiveNoFileComp
+			exitWithError(err)
 		}
 
-		worktrees, err := fx.ListWorktrees(repoRoot)
+		allMerged, err := cmd.Flags().GetBool("all-merged")
 		if err != nil {
-			return nil, cobra.ShellCompDirectiveNoFileComp
+			exitWithError(err)
 		}
-
-		sproutRoot, err := fx.GetWorktreeRoot(repoRoot)
-		if err != nil {
-			return nil, cobra.ShellCompDirectiveNoFileComp
-		}
-
-		choices := core.FilterSproutWorktrees(worktrees, sproutRoot)
-
-		var completions []string
-		for _, wt := range choices {
-			if wt.Branch != "" {
-				completions = append(completions, wt.Branch)
+		if allMerged {
+			if len(args) > 0 {
+				exitWithError(errors.New("--all-merged doesn't take a branch or path"))
 			}
-		}
-
-		return completions, cobra.ShellCompDirectiveNoFileComp
-	},
-	Run: func(cmd *cobra.Command, args []string) {
-		fx := effects.NewRealEffects()
-
-		force, err := cmd.Flags().GetBool("force")
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			if force {
+				exitWithError(errors.New("--all-merged only removes clean worktrees and can't be combined with --force"))
+			}
+			defer lockRepo(fx)()
+			ctx, err := BuildRemoveMergedContext(fx)
+			if err != nil {
+				exitWithError(err)
+			}
+			plan := core.PlanRemoveMergedCommand(ctx)
+			if porcelain != "" {
+				runPlanPrinting(plan, fx, func() string { return core.FormatRemovePorcelain(ctx.Outcomes()) })
+				return
+			}
+			runPlan(plan, fx)
+			return
 		}
 
 		// Build context
-		ctx, err := BuildRemoveContext(fx, args, force)
+		defer lockRepo(fx)()
+		ctx, err := build.RemoveContext(fx, args, force)
 		if err != nil {
 			// Handle specific errors with better UX
 			if errors.Is(err, core.ErrNoSproutWorktrees) {
@@ -71,95 +84,51 @@ var removeCmd = &cobra.Command{
 				// Silent exit for cancelled selection (user pressed Ctrl+C)
 				os.Exit(1)
 			}
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 
 		// Plan and execute
+		if porcelain != "" {
+			ctx.TargetPath = absPath(ctx.TargetPath)
+			plan := core.PlanRemoveCommand(ctx)
+			runPlanPrinting(plan, fx, func() string { return core.FormatRemovePorcelain([]core.RemoveOutcome{ctx.Outcome()}) })
+			return
+		}
 		plan := core.PlanRemoveCommand(ctx)
 		runPlan(plan, fx)
 	},
 }
 
-// BuildRemoveContext gathers all inputs needed for the remove command.
-//
-// It handles three input modes:
-// - Interactive selection if no argument provided
-// - Branch name lookup (tries to match against worktree branches)
-// - Direct path (if argument is an existing file/directory)
-//
-// Path vs branch disambiguation: If the argument exists as a file/directory,
-// it's treated as a path; otherwise it's treated as a branch name. This means
-// a branch name that matches a file in CWD will be interpreted as a path.
-// This is acceptable for a worktree tool where explicit paths are uncommon.
-func BuildRemoveContext(fx effects.Effects, args []string, force bool) (core.RemoveContext, error) {
-	// Get repository root
+// BuildRemoveMergedContext gathers the repository's sprout worktrees with
+// their status for remove --all-merged.
+func BuildRemoveMergedContext(fx effects.Effects) (core.RemoveMergedContext, error) {
 	repoRoot, err := fx.GetRepoRoot()
 	if err != nil {
-		return core.RemoveContext{}, fmt.Errorf("failed to get repository root: %w", err)
+		return core.RemoveMergedContext{}, fmt.Errorf("failed to get repository root: %w", err)
 	}
-
-	// Get sprout root
-	sproutRoot, err := fx.GetWorktreeRoot(repoRoot)
+	mainWorktreePath, err := fx.GetMainWorktreePath()
 	if err != nil {
-		return core.RemoveContext{}, fmt.Errorf("failed to get sprout root: %w", err)
+		return core.RemoveMergedContext{}, fmt.Errorf("failed to get main worktree: %w", err)
 	}
 
-	// Get all worktrees
-	worktrees, err := fx.ListWorktrees(repoRoot)
+	usages, err := build.WorktreeUsage(fx, repoRoot, mainWorktreePath, 0)
 	if err != nil {
-		return core.RemoveContext{}, fmt.Errorf("failed to list worktrees: %w", err)
+		return core.RemoveMergedContext{}, err
 	}
-
-	// Filter to sprout-managed worktrees
-	sproutWorktrees := core.FilterSproutWorktrees(worktrees, sproutRoot)
-
-	var targetPath string
-	var argProvided bool
-	var arg string
-
-	if len(args) == 0 {
-		// Interactive mode
-		if len(sproutWorktrees) == 0 {
-			return core.RemoveContext{}, core.ErrNoSproutWorktrees
-		}
-
-		idx, err := fx.SelectWorktree(sproutWorktrees)
-		if err != nil {
-			return core.RemoveContext{}, core.ErrSelectionCancelled
-		}
-		targetPath = sproutWorktrees[idx].Path
-
-	} else {
-		// Argument provided
-		argProvided = true
-		arg = args[0]
-
-		// Disambiguate: path (if exists) vs branch name
-		if fx.FileExists(arg) {
-			targetPath = arg
-		} else {
-			// Assume it's a branch - search for it in worktrees
-			var found bool
-			targetPath, found = core.FindWorktreeByBranch(worktrees, sproutRoot, arg)
-			if !found {
-				return core.RemoveContext{}, fmt.Errorf("no sprout-managed worktree found for branch '%s'", arg)
-			}
-		}
+	if len(usages) == 0 {
+		return core.RemoveMergedContext{}, core.ErrNoSproutWorktrees
 	}
 
-	return core.RemoveContext{
-		ArgProvided: argProvided,
-		Arg:         arg,
+	return core.RemoveMergedContext{
 		RepoRoot:    repoRoot,
-		SproutRoot:  sproutRoot,
-		Worktrees:   worktrees,
-		TargetPath:  targetPath,
-		Force:       force,
+		CurrentPath: repoRoot,
+		Worktrees:   usages,
 	}, nil
 }
 
 func init() {
 	removeCmd.Flags().Bool("force", false, "Force removal")
+	removeCmd.Flags().Bool("all-merged", false, "Remove every clean, merged sprout worktree")
+	addPorcelainFlag(removeCmd, &removePorcelain)
 	rootCmd.AddCommand(removeCmd)
 }
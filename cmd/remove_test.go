@@ -1,9 +1,9 @@
 package cmd
 
 import (
-	"fmt"
 	"testing"
 
+	"github.com/m44rten1/sprout/internal/build"
 	"github.com/m44rten1/sprout/internal/core"
 	"github.com/m44rten1/sprout/internal/effects"
 	"github.com/m44rten1/sprout/internal/git"
@@ -11,201 +11,6 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestBuildRemoveContext(t *testing.T) {
-	tests := []struct {
-		name       string
-		setupFx    func(*effects.TestEffects)
-		args       []string
-		force      bool
-		wantCtx    *core.RemoveContext
-		wantErr    bool
-		assertions func(t *testing.T, fx *effects.TestEffects)
-	}{
-		{
-			name: "explicit path argument",
-			setupFx: func(fx *effects.TestEffects) {
-				fx.RepoRoot = "/test/repo"
-				fx.WorktreeRoot = "/test/repo/.sprout"
-				fx.Worktrees = []git.Worktree{
-					{Path: "/test/repo", Branch: "main"},
-					{Path: "/test/repo/.sprout/feature", Branch: "feature"},
-				}
-				fx.Files["/test/repo/.sprout/feature"] = true
-			},
-			args:  []string{"/test/repo/.sprout/feature"},
-			force: false,
-			wantCtx: &core.RemoveContext{
-				ArgProvided: true,
-				Arg:         "/test/repo/.sprout/feature",
-				RepoRoot:    "/test/repo",
-				SproutRoot:  "/test/repo/.sprout",
-				TargetPath:  "/test/repo/.sprout/feature",
-				Force:       false,
-			},
-			wantErr: false,
-		},
-		{
-			name: "branch name argument",
-			setupFx: func(fx *effects.TestEffects) {
-				fx.RepoRoot = "/test/repo"
-				fx.WorktreeRoot = "/test/repo/.sprout"
-				fx.Worktrees = []git.Worktree{
-					{Path: "/test/repo", Branch: "main"},
-					{Path: "/test/repo/.sprout/feature", Branch: "feature"},
-				}
-			},
-			args:  []string{"feature"},
-			force: true,
-			wantCtx: &core.RemoveContext{
-				ArgProvided: true,
-				Arg:         "feature",
-				RepoRoot:    "/test/repo",
-				SproutRoot:  "/test/repo/.sprout",
-				TargetPath:  "/test/repo/.sprout/feature",
-				Force:       true,
-			},
-			wantErr: false,
-		},
-		{
-			name: "interactive selection",
-			setupFx: func(fx *effects.TestEffects) {
-				fx.RepoRoot = "/test/repo"
-				fx.WorktreeRoot = "/test/repo/.sprout"
-				fx.Worktrees = []git.Worktree{
-					{Path: "/test/repo", Branch: "main"},
-					{Path: "/test/repo/.sprout/feature", Branch: "feature"},
-					{Path: "/test/repo/.sprout/bugfix", Branch: "bugfix"},
-				}
-				fx.SelectedWorktreeIndex = 1 // Select "bugfix"
-			},
-			args:  []string{},
-			force: false,
-			wantCtx: &core.RemoveContext{
-				ArgProvided: false,
-				Arg:         "",
-				RepoRoot:    "/test/repo",
-				SproutRoot:  "/test/repo/.sprout",
-				TargetPath:  "/test/repo/.sprout/bugfix",
-				Force:       false,
-			},
-			wantErr: false,
-			assertions: func(t *testing.T, fx *effects.TestEffects) {
-				assert.Equal(t, 1, fx.SelectWorktreeCalls)
-			},
-		},
-		{
-			name: "interactive selection cancelled",
-			setupFx: func(fx *effects.TestEffects) {
-				fx.RepoRoot = "/test/repo"
-				fx.WorktreeRoot = "/test/repo/.sprout"
-				fx.Worktrees = []git.Worktree{
-					{Path: "/test/repo/.sprout/feature", Branch: "feature"},
-				}
-				fx.SelectionError = fmt.Errorf("cancelled")
-			},
-			args:    []string{},
-			force:   false,
-			wantCtx: nil,
-			wantErr: true,
-		},
-		{
-			name: "no sprout worktrees for interactive",
-			setupFx: func(fx *effects.TestEffects) {
-				fx.RepoRoot = "/test/repo"
-				fx.WorktreeRoot = "/test/repo/.sprout"
-				fx.Worktrees = []git.Worktree{
-					{Path: "/test/repo", Branch: "main"},
-				}
-			},
-			args:    []string{},
-			force:   false,
-			wantCtx: nil,
-			wantErr: true,
-		},
-		{
-			name: "branch not found",
-			setupFx: func(fx *effects.TestEffects) {
-				fx.RepoRoot = "/test/repo"
-				fx.WorktreeRoot = "/test/repo/.sprout"
-				fx.Worktrees = []git.Worktree{
-					{Path: "/test/repo/.sprout/feature", Branch: "feature"},
-				}
-			},
-			args:    []string{"nonexistent"},
-			force:   false,
-			wantCtx: nil,
-			wantErr: true,
-			assertions: func(t *testing.T, fx *effects.TestEffects) {
-				// Should not call SelectWorktree since arg was provided
-				assert.Equal(t, 0, fx.SelectWorktreeCalls)
-			},
-		},
-		{
-			name: "GetRepoRoot fails",
-			setupFx: func(fx *effects.TestEffects) {
-				fx.GetRepoRootErr = fmt.Errorf("not a git repo")
-			},
-			args:    []string{},
-			force:   false,
-			wantCtx: nil,
-			wantErr: true,
-		},
-		{
-			name: "GetWorktreeRoot fails",
-			setupFx: func(fx *effects.TestEffects) {
-				fx.RepoRoot = "/test/repo"
-				fx.GetWorktreeRootErr = fmt.Errorf("sprout root error")
-			},
-			args:    []string{},
-			force:   false,
-			wantCtx: nil,
-			wantErr: true,
-		},
-		{
-			name: "ListWorktrees fails",
-			setupFx: func(fx *effects.TestEffects) {
-				fx.RepoRoot = "/test/repo"
-				fx.WorktreeRoot = "/test/repo/.sprout"
-				fx.ListWorktreesErr = fmt.Errorf("git error")
-			},
-			args:    []string{},
-			force:   false,
-			wantCtx: nil,
-			wantErr: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			fx := effects.NewTestEffects()
-			tt.setupFx(fx)
-
-			got, err := BuildRemoveContext(fx, tt.args, tt.force)
-
-			if tt.wantErr {
-				assert.Error(t, err)
-			} else {
-				require.NoError(t, err)
-				require.NotNil(t, tt.wantCtx)
-
-				assert.Equal(t, tt.wantCtx.ArgProvided, got.ArgProvided)
-				assert.Equal(t, tt.wantCtx.Arg, got.Arg)
-				assert.Equal(t, tt.wantCtx.RepoRoot, got.RepoRoot)
-				assert.Equal(t, tt.wantCtx.SproutRoot, got.SproutRoot)
-				assert.Equal(t, tt.wantCtx.TargetPath, got.TargetPath)
-				assert.Equal(t, tt.wantCtx.Force, got.Force)
-				// Note: Worktrees field not checked here - it's passed through
-				// from fx.Worktrees but the specific content doesn't affect behavior
-			}
-
-			// Run custom assertions for both success and error paths
-			if tt.assertions != nil {
-				tt.assertions(t, fx)
-			}
-		})
-	}
-}
-
 func TestRemoveCommand_EndToEnd(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -330,7 +135,7 @@ func TestRemoveCommand_EndToEnd(t *testing.T) {
 			tt.setupFx(fx)
 
 			// Build context
-			ctx, err := BuildRemoveContext(fx, tt.args, tt.force)
+			ctx, err := build.RemoveContext(fx, tt.args, tt.force)
 			require.NoError(t, err, "context building should succeed in all current test cases")
 
 			// Plan
@@ -346,3 +151,38 @@ func TestRemoveCommand_EndToEnd(t *testing.T) {
 		})
 	}
 }
+
+func TestRemoveMergedCommand_EndToEnd(t *testing.T) {
+	t.Parallel()
+	fx := effects.NewTestEffects()
+	fx.RepoRoot = "/test/repo"
+	fx.WorktreeRoot = "/test/repo/.sprout"
+	fx.Worktrees = []git.Worktree{
+		{Path: "/test/repo", Branch: "main"},
+		{Path: "/test/repo/.sprout/merged", Branch: "merged"},
+		{Path: "/test/repo/.sprout/dirty", Branch: "dirty"},
+	}
+	fx.WorktreeStatuses["/test/repo/.sprout/dirty"] = git.WorktreeStatus{Dirty: true}
+
+	ctx, err := BuildRemoveMergedContext(fx)
+	require.NoError(t, err)
+	require.NoError(t, effects.ExecutePlan(core.PlanRemoveMergedCommand(ctx), fx))
+
+	require.Len(t, fx.GitCommands, 2)
+	assert.Equal(t, []string{"worktree", "remove", "/test/repo/.sprout/merged"}, fx.GitCommands[0].Args)
+	assert.Equal(t, []string{"worktree", "prune"}, fx.GitCommands[1].Args)
+	require.Len(t, fx.PrintedMsgs, 1)
+	assert.Contains(t, fx.PrintedMsgs[0], "removed  merged")
+	assert.Contains(t, fx.PrintedMsgs[0], "skipped  dirty   uncommitted changes")
+}
+
+func TestBuildRemoveMergedContext_NoWorktrees(t *testing.T) {
+	t.Parallel()
+	fx := effects.NewTestEffects()
+	fx.RepoRoot = "/test/repo"
+	fx.WorktreeRoot = "/test/repo/.sprout"
+	fx.Worktrees = []git.Worktree{{Path: "/test/repo", Branch: "main"}}
+
+	_, err := BuildRemoveMergedContext(fx)
+	assert.ErrorIs(t, err, core.ErrNoSproutWorktrees)
+}
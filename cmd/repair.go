@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+
+	"github.com/spf13/cobra"
+)
+
+// BuildRepairContext gathers the worktree locations needed to plan the repair command.
+func BuildRepairContext(fx effects.Effects) (core.RepoRepairContext, error) {
+	mainWorktreePath, err := fx.GetMainWorktreePath()
+	if err != nil {
+		return core.RepoRepairContext{}, fmt.Errorf("get main worktree: %w", err)
+	}
+
+	worktreeRoot, err := fx.GetWorktreeRoot(mainWorktreePath)
+	if err != nil {
+		return core.RepoRepairContext{}, fmt.Errorf("get worktree root: %w", err)
+	}
+
+	pathWorktreeRoot, err := fx.GetPathWorktreeRoot(mainWorktreePath)
+	if err != nil {
+		return core.RepoRepairContext{}, fmt.Errorf("get worktree root: %w", err)
+	}
+
+	worktrees, err := fx.ListWorktrees(mainWorktreePath)
+	if err != nil {
+		return core.RepoRepairContext{}, fmt.Errorf("list worktrees: %w", err)
+	}
+
+	return core.RepoRepairContext{
+		MainWorktreePath:       mainWorktreePath,
+		WorktreeRoot:           worktreeRoot,
+		WorktreeRootExists:     effects.Exists(fx, worktreeRoot),
+		PathWorktreeRoot:       pathWorktreeRoot,
+		PathWorktreeRootExists: effects.Exists(fx, pathWorktreeRoot),
+		Worktrees:              worktrees,
+	}, nil
+}
+
+var repairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Repair worktree links for the current repository",
+	Long: `Run 'git worktree repair' for the current repository, fixing worktrees
+whose links broke because the repository or its worktrees were moved.
+
+When repo_identity is set to "remote" in ~/.config/sprout/config.yml, worktrees
+are keyed by the repository's origin URL instead of its path. Repair moves
+worktrees created under the old path-based directory to the new location and
+re-links them, so they keep working after the switch.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+		defer lockRepo(fx)()
+
+		ctx, err := BuildRepairContext(fx)
+		if err != nil {
+			exitWithError(err)
+		}
+
+		plan := core.PlanRepoRepair(ctx)
+		runPlan(plan, fx)
+
+		// Worktrees may have moved to another directory
+		if !dryRunFlag && effects.Exists(fx, ctx.WorktreeRoot) {
+			if err := fx.IndexRepo(ctx.WorktreeRoot, ctx.MainWorktreePath); err != nil {
+				exitWithError(err)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(repairCmd)
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairCommand_MigratesPathRoot(t *testing.T) {
+	t.Parallel()
+
+	fx := effects.NewTestEffects()
+	fx.MainWorktreePath = "/test/repo"
+	fx.WorktreeRoot = "/sprout/app-bbbb2222"
+	fx.PathWorktreeRoot = "/sprout/repo-aaaa1111"
+	fx.Files["/sprout/repo-aaaa1111"] = true
+	fx.Worktrees = []git.Worktree{
+		{Path: "/test/repo", Branch: "main"},
+		{Path: "/sprout/repo-aaaa1111/feature/repo", Branch: "feature"},
+	}
+
+	ctx, err := BuildRepairContext(fx)
+	require.NoError(t, err)
+	assert.True(t, ctx.PathWorktreeRootExists)
+	assert.False(t, ctx.WorktreeRootExists)
+
+	require.NoError(t, effects.ExecutePlan(core.PlanRepoRepair(ctx), fx))
+
+	require.Len(t, fx.Renames, 1)
+	assert.Equal(t, effects.RenameCall{From: "/sprout/repo-aaaa1111", To: "/sprout/app-bbbb2222"}, fx.Renames[0])
+	require.Len(t, fx.GitCommands, 1)
+	assert.Equal(t, []string{"worktree", "repair", "/sprout/app-bbbb2222/feature/repo"}, fx.GitCommands[0].Args)
+}
+
+func TestBuildRepairContext_NotInRepo(t *testing.T) {
+	t.Parallel()
+
+	fx := effects.NewTestEffects()
+	fx.MainWorktreePath = ""
+
+	_, err := BuildRepairContext(fx)
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+
+	"github.com/spf13/cobra"
+)
+
+// ResolveRepoFlag turns the value of --repo into a directory to run in.
+// Existing paths (including ~/...) are used as-is; anything else is looked
+// up by name among the repositories under the sprout root.
+func ResolveRepoFlag(fx effects.Effects, value string) (string, error) {
+	home, _ := fx.UserHomeDir()
+	path := core.ExpandHomeWithHome(value, home)
+
+	if effects.Exists(fx, path) {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", value, err)
+		}
+		return abs, nil
+	}
+	// Relative paths with a slash may still be names, like work/app
+	if filepath.IsAbs(path) || strings.HasPrefix(value, "~") || strings.HasPrefix(value, ".") {
+		return "", fmt.Errorf("repository path does not exist: %s", value)
+	}
+
+	repos, err := collectAllReposWithEffects(fx)
+	if err != nil {
+		return "", err
+	}
+	return core.ResolveRepoName(repos, value)
+}
+
+// applyGitEnvOverrides switches to the worktree GIT_DIR and GIT_WORK_TREE
+// select, unless the working directory is already inside it.
+func applyGitEnvOverrides() error {
+	root, err := git.ResolveEnvOverrides()
+	if err != nil || root == "" {
+		return err
+	}
+	if current, err := git.GetRepoRoot(); err == nil && current == root {
+		return nil
+	}
+	return os.Chdir(root)
+}
+
+// completeRepoNames completes --repo with the names of known repositories,
+// as 'sprout repos' prints them.
+func completeRepoNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	repos, err := collectAllReposWithEffects(effects.NewRealEffects())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for i, name := range core.RepoNames(repos) {
+		if strings.HasPrefix(name, toComplete) {
+			completions = append(completions, name+"\t"+repos[i].MainPath)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
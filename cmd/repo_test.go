@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveRepoFlag(t *testing.T) {
+	t.Parallel()
+
+	newFx := func() *effects.TestEffects {
+		fx := effects.NewTestEffects()
+		fx.UserHome = "/home/me"
+		fx.Worktrees = []git.Worktree{
+			{Path: "/code/work/app", Branch: "main"},
+			{Path: "/manual/feature", Branch: "feature"},
+		}
+		fx.Adopted = []string{"/manual/feature"}
+		fx.Files["/manual/feature"] = true
+		return fx
+	}
+
+	t.Run("existing path is used directly", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.Files["/home/me/src/api"] = true
+
+		dir, err := ResolveRepoFlag(fx, "~/src/api")
+		require.NoError(t, err)
+		assert.Equal(t, "/home/me/src/api", dir)
+	})
+
+	t.Run("missing path is an error", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ResolveRepoFlag(newFx(), "/nowhere/api")
+		assert.ErrorContains(t, err, "repository path does not exist: /nowhere/api")
+	})
+
+	t.Run("name resolves to main worktree", func(t *testing.T) {
+		t.Parallel()
+
+		dir, err := ResolveRepoFlag(newFx(), "app")
+		require.NoError(t, err)
+		assert.Equal(t, "/code/work/app", dir)
+	})
+
+	t.Run("name with the directory above it", func(t *testing.T) {
+		t.Parallel()
+
+		dir, err := ResolveRepoFlag(newFx(), "work/app")
+		require.NoError(t, err)
+		assert.Equal(t, "/code/work/app", dir)
+	})
+
+	t.Run("unknown name", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ResolveRepoFlag(newFx(), "api")
+		assert.ErrorIs(t, err, core.ErrRepoNotFound)
+	})
+}
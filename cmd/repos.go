@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	reposJSONFlag         bool
+	reposRebuildIndexFlag bool
+)
+
+var reposCmd = &cobra.Command{
+	Use:   "repos",
+	Short: "List every repository sprout manages",
+	Long: `List the repositories sprout knows about, the ones 'sprout list --all'
+shows: their name, number of worktrees, when any of their worktrees was last
+worked in, and the path of the main worktree.
+
+The names are what --repo accepts, so 'sprout --repo <name> ...' runs any
+command in that repository. Repositories in directories of the same name are
+told apart by the directories above them, such as work/app and personal/app.
+
+With --json, the same is printed as a JSON array of objects with name,
+path, worktrees and last_activity.
+
+sprout keeps an index of the repositories under its root
+(~/.cache/sprout/repos.json) so it doesn't have to search every
+directory for a worktree. The index updates itself as worktrees are added,
+moved, removed and repaired, and directories it misses are searched anyway.
+--rebuild-index discards it and searches everything again.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+
+		if reposRebuildIndexFlag {
+			if err := fx.SaveRepoIndex(nil); err != nil {
+				exitWithError(err)
+			}
+		}
+
+		ctx, err := BuildReposContext(fx, time.Now())
+		if err != nil {
+			if reposJSONFlag {
+				exitWithJSONError(err)
+			}
+			exitWithError(err)
+		}
+		ctx.JSON = reposJSONFlag
+
+		runPlan(core.PlanReposCommand(ctx), fx)
+	},
+}
+
+// BuildReposContext finds all sprout-managed repositories, as
+// 'sprout list --all' does, and when each of their worktrees was last used.
+func BuildReposContext(fx effects.Effects, now time.Time) (core.ReposContext, error) {
+	repos, err := collectAllReposWithEffects(fx)
+	if err != nil {
+		return core.ReposContext{}, err
+	}
+	lastUsed := make(map[string]time.Time)
+	for _, repo := range repos {
+		for _, wt := range repo.Worktrees {
+			if used, err := fx.WorktreeLastUsed(wt.Path); err == nil {
+				lastUsed[wt.Path] = used
+			}
+		}
+	}
+	home, _ := fx.UserHomeDir()
+	return core.ReposContext{
+		Repos: core.SummarizeRepos(repos, lastUsed),
+		Home:  home,
+		Now:   now,
+	}, nil
+}
+
+func init() {
+	rootCmd.AddCommand(reposCmd)
+	reposCmd.Flags().BoolVar(&reposJSONFlag, "json", false, "Print the repositories as JSON")
+	reposCmd.Flags().BoolVar(&reposRebuildIndexFlag, "rebuild-index", false, "Find the repositories by searching the sprout root instead of using the index, and rebuild it")
+}
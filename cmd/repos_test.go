@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildReposContext(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	fx := effects.NewTestEffects()
+	fx.UserHome = "/home/me"
+	fx.Worktrees = []git.Worktree{
+		{Path: "/code/app", Branch: "main"},
+		{Path: "/manual/feature", Branch: "feature"},
+	}
+	fx.Adopted = []string{"/manual/feature"}
+	fx.Files["/manual/feature"] = true
+	fx.LastUsed["/manual/feature"] = now.Add(-time.Hour)
+
+	ctx, err := BuildReposContext(fx, now)
+	require.NoError(t, err)
+	assert.Equal(t, "/home/me", ctx.Home)
+	assert.Equal(t, now, ctx.Now)
+	assert.Equal(t, []core.RepoSummary{
+		{Name: "app", MainPath: "/code/app", Worktrees: 1, LastActivity: now.Add(-time.Hour)},
+	}, ctx.Repos)
+}
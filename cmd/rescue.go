@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/logging"
+)
+
+// runAddPlan runs the plan of adding the worktree of ctx like runPlan. With
+// output, like runPlanPrintingPath, its messages are left out, anything else
+// goes to stderr, and stdout only gets what output returns for the worktree
+// added. When git refuses to create the worktree for a reason sprout
+// understands, it explains why and offers ways out instead of failing with
+// what git printed.
+func runAddPlan(plan core.Plan, fx effects.Effects, ctx core.AddContext, output func(core.AddContext) string) {
+	if dryRunFlag {
+		if output != nil {
+			runPlanPrintingPath(plan, fx, ctx.WorktreePath)
+			return
+		}
+		runPlan(plan, fx)
+		return
+	}
+
+	stdout := os.Stdout
+	if output != nil {
+		os.Stdout = os.Stderr
+		plan = core.WithoutMessages(plan)
+	}
+	err := executePlan(plan, fx)
+	for err != nil {
+		rescue, ok, chooseErr := chooseAddRescue(fx, ctx, err)
+		if chooseErr != nil {
+			logging.Printf("%v", err)
+			exitWithError(chooseErr)
+		}
+		if !ok {
+			exitWithPlanError(err)
+		}
+		ctx = rescue.Ctx
+		retry := core.PlanAddRetry(rescue)
+		if output != nil {
+			retry = core.WithoutMessages(retry)
+		}
+		err = executePlan(retry, fx)
+	}
+	os.Stdout = stdout
+
+	if output != nil {
+		fmt.Println(output(ctx))
+	}
+}
+
+// addedPath is the output of --print-path: the absolute path of the
+// worktree added.
+func addedPath(ctx core.AddContext) string {
+	return absPath(ctx.WorktreePath)
+}
+
+// addedPorcelain is the output of --porcelain (see core.FormatAddPorcelain).
+func addedPorcelain(ctx core.AddContext) string {
+	ctx.WorktreePath = absPath(ctx.WorktreePath)
+	return core.FormatAddPorcelain(ctx)
+}
+
+// chooseAddRescue asks how to recover when err is a 'git worktree add'
+// that failed adding the worktree of ctx (see core.PlanAddRescues). ok is
+// false if there is no way sprout knows of. The error explains what went
+// wrong when the user can't or won't choose.
+func chooseAddRescue(fx effects.Effects, ctx core.AddContext, err error) (rescue core.AddRescue, ok bool, chooseErr error) {
+	var gitErr *git.GitError
+	if !errors.As(err, &gitErr) || len(gitErr.Args) < 2 || gitErr.Args[0] != "worktree" || gitErr.Args[1] != "add" {
+		return core.AddRescue{}, false, nil
+	}
+	failure, checkedOutAt := core.ClassifyAddFailure(gitErr.Stderr)
+
+	// git may create a new branch before finding it can't add the worktree
+	if ctx.Detach == "" && !ctx.LocalBranchExists {
+		if ref, _ := fx.RunGitCommand(ctx.RepoRoot, "for-each-ref", "--format=%(refname)", "refs/heads/"+ctx.Branch); ref != "" {
+			ctx.LocalBranchExists = true
+			ctx.Tag = ""
+		}
+	}
+
+	altPath := core.AlternativeWorktreePath(ctx.WorktreePath, func(path string) bool { return effects.Exists(fx, path) })
+	rescues, ok := core.PlanAddRescues(ctx, failure, checkedOutAt, altPath)
+	if !ok {
+		return core.AddRescue{}, false, nil
+	}
+
+	labels := make([]string, len(rescues.Rescues))
+	for i, r := range rescues.Rescues {
+		labels[i] = r.Label
+	}
+	i, err := fx.Choose(rescues.Problem+". What now?", labels)
+	if err != nil {
+		return core.AddRescue{}, false, fmt.Errorf("%s; %s", rescues.Problem, rescues.Hint)
+	}
+	return rescues.Rescues[i], true, nil
+}
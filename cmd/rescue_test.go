@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChooseAddRescue(t *testing.T) {
+	t.Parallel()
+
+	ctx := core.AddContext{
+		Branch:       "feature",
+		RepoRoot:     "/test/repo",
+		WorktreePath: "/sprout/feature/repo",
+		Config:       &config.Config{},
+	}
+	addErr := func(stderr string) error {
+		gitErr := &git.GitError{Args: []string{"worktree", "add", "/sprout/feature/repo", "-b", "feature"}, ExitCode: 128, Stderr: stderr}
+		return fmt.Errorf("git command in /test/repo failed: %w", gitErr)
+	}
+	const stale = "fatal: '/sprout/feature/repo' is a missing but already registered worktree;\nuse 'add -f' to override, or 'prune' or 'remove' to clear"
+
+	t.Run("chosen rescue", func(t *testing.T) {
+		t.Parallel()
+		fx := effects.NewTestEffects()
+		fx.Choice = 2
+
+		rescue, ok, err := chooseAddRescue(fx, ctx, addErr(stale))
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, 1, fx.ChooseCalls)
+		assert.Equal(t, "/sprout/feature-2/repo", rescue.Ctx.WorktreePath)
+	})
+
+	t.Run("branch created by the failed attempt", func(t *testing.T) {
+		t.Parallel()
+		fx := effects.NewTestEffects()
+		fx.GitCommandOutput["/test/repo\nfor-each-ref --format=%(refname) refs/heads/feature"] = "refs/heads/feature"
+
+		rescue, ok, err := chooseAddRescue(fx, ctx, addErr(stale))
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.True(t, rescue.Ctx.LocalBranchExists)
+	})
+
+	t.Run("without a terminal", func(t *testing.T) {
+		t.Parallel()
+		fx := effects.NewTestEffects()
+		fx.ChooseErr = effects.ErrNonInteractive
+
+		_, _, err := chooseAddRescue(fx, ctx, addErr(stale))
+		assert.EqualError(t, err, "/sprout/feature/repo is still registered as a worktree, but its directory is gone; run 'git worktree prune' and sprout add again")
+	})
+
+	t.Run("unknown failure", func(t *testing.T) {
+		t.Parallel()
+		fx := effects.NewTestEffects()
+
+		_, ok, err := chooseAddRescue(fx, ctx, addErr("fatal: invalid reference: feature"))
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Zero(t, fx.ChooseCalls)
+	})
+
+	t.Run("other git command", func(t *testing.T) {
+		t.Parallel()
+		fx := effects.NewTestEffects()
+
+		_, ok, err := chooseAddRescue(fx, ctx, errors.New("copy failed"))
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
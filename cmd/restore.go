@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/m44rten1/sprout/internal/build"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+
+	"github.com/spf13/cobra"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <manifest>",
+	Short: "Recreate worktrees from a snapshot manifest",
+	Long: `Recreate the worktrees listed in a manifest written by 'sprout snapshot'.
+
+Repositories missing from this machine are cloned from their remote first.
+Worktrees that already exist are left untouched. Hooks are not run; use
+'sprout open' to open a restored worktree and run its on_open hooks.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			printError(fmt.Errorf("failed to read manifest: %w", err))
+			os.Exit(1)
+		}
+
+		manifest, err := core.ParseManifest(data)
+		if err != nil {
+			exitWithError(err)
+		}
+
+		ctx, err := BuildRestoreContext(fx, manifest)
+		if err != nil {
+			exitWithError(err)
+		}
+
+		plan := core.PlanRestoreCommand(ctx)
+		runPlan(plan, fx)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+}
+
+// BuildRestoreContext resolves a manifest against the local machine: where
+// each repository lives, whether it needs cloning, and which worktrees exist.
+func BuildRestoreContext(fx effects.Effects, manifest core.Manifest) (core.RestoreContext, error) {
+	home, _ := fx.UserHomeDir()
+
+	var ctx core.RestoreContext
+	for _, entry := range manifest.Repos {
+		repo := core.RestoreRepo{
+			Path:   core.ExpandHomeWithHome(entry.Path, home),
+			Remote: entry.Remote,
+		}
+
+		if effects.Exists(fx, repo.Path) {
+			if _, err := fx.ListWorktrees(repo.Path); err != nil {
+				return core.RestoreContext{}, fmt.Errorf("%s exists but is not a git repository", repo.Path)
+			}
+			repo.Exists = true
+			repo.Path = resolveMainWorktree(fx, repo.Path, "")
+		}
+
+		var hasRemote, hasOriginMain bool
+		if repo.Exists {
+			var err error
+			if hasRemote, err = fx.HasRemote(repo.Path); err != nil {
+				return core.RestoreContext{}, fmt.Errorf("failed to list remotes of %s: %w", repo.Path, err)
+			}
+			if hasRemote {
+				hasOriginMain, err = fx.RemoteBranchExists(repo.Path, "main")
+				if err != nil {
+					return core.RestoreContext{}, fmt.Errorf("failed to check origin/main in %s: %w", repo.Path, err)
+				}
+			}
+		}
+
+		for _, item := range entry.Worktrees {
+			worktreePath, err := fx.GetWorktreePath(repo.Path, item.Branch)
+			if err != nil {
+				return core.RestoreContext{}, fmt.Errorf("error calculating worktree path for %s: %w", item.Branch, err)
+			}
+
+			exists, err := build.WorktreeDirExists(fx, worktreePath)
+			if err != nil {
+				return core.RestoreContext{}, err
+			}
+			wt := core.RestoreWorktree{
+				Branch:        item.Branch,
+				Base:          item.Base,
+				Path:          worktreePath,
+				Exists:        exists,
+				HasOriginMain: hasOriginMain,
+			}
+			if repo.Exists && !wt.Exists {
+				if wt.LocalBranchExists, err = fx.LocalBranchExists(repo.Path, item.Branch); err != nil {
+					return core.RestoreContext{}, fmt.Errorf("failed to check local branch %s: %w", item.Branch, err)
+				}
+				if hasRemote {
+					if wt.RemoteBranchExists, err = fx.RemoteBranchExists(repo.Path, item.Branch); err != nil {
+						return core.RestoreContext{}, fmt.Errorf("failed to check remote branch %s: %w", item.Branch, err)
+					}
+				}
+			}
+			repo.Worktrees = append(repo.Worktrees, wt)
+		}
+
+		ctx.Repos = append(ctx.Repos, repo)
+	}
+
+	return ctx, nil
+}
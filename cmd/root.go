@@ -1,12 +1,20 @@
 package cmd
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/m44rten1/sprout/internal/config"
 	"github.com/m44rten1/sprout/internal/core"
 	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/logging"
+	"github.com/m44rten1/sprout/internal/sprout"
+	"github.com/m44rten1/sprout/internal/timing"
 	"github.com/spf13/cobra"
 )
 
@@ -16,7 +24,23 @@ var rootCmd = &cobra.Command{
 	Long:  `sprout is a lightweight Go CLI tool for managing Git worktrees.`,
 }
 
-var dryRunFlag bool
+var (
+	dryRunFlag     bool
+	renderPlanFlag bool
+	noProgressFlag bool
+	repoFlag       string
+	nonInteractive bool
+	logFileFlag    string
+	quietHooks     bool
+	verboseGitFlag bool
+)
+
+var (
+	// startedAt is when sprout started, for command durations.
+	startedAt time.Time
+	// commandName is the running command, such as "list" or "api create".
+	commandName string
+)
 
 func init() {
 	// Enable shell completion command
@@ -24,16 +48,84 @@ func init() {
 
 	// Add global --dry-run flag
 	rootCmd.PersistentFlags().BoolVar(&dryRunFlag, "dry-run", false, "Show what would be done without executing")
+	// For writing tests: a dry run that prints the plan as a Go literal
+	rootCmd.PersistentFlags().BoolVar(&renderPlanFlag, "render-plan", false, "Print the plan as a Go literal instead of executing it")
+	_ = rootCmd.PersistentFlags().MarkHidden("render-plan")
+	rootCmd.PersistentFlags().BoolVar(&noProgressFlag, "no-progress", false, "Don't show step progress for multi-step operations")
+	rootCmd.PersistentFlags().StringVar(&repoFlag, "repo", "", "Run against this repository (a path, or the name of a sprout-managed repo) instead of the current directory")
+	_ = rootCmd.RegisterFlagCompletionFunc("repo", completeRepoNames)
+	rootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false, fmt.Sprintf("Fail with exit code %d instead of prompting or showing a picker (default when stdin is not a terminal)", effects.ExitNonInteractive))
+	rootCmd.PersistentFlags().BoolVar(&quietHooks, "quiet-hooks", false, "Show hook output only when a command fails (overrides hooks.output)")
+	rootCmd.PersistentFlags().StringVar(&logFileFlag, "log-file", "", "Log every action and git command with its duration to this file (SPROUT_DEBUG=1 logs to ~/.local/state/sprout/sprout.log)")
+	rootCmd.PersistentFlags().BoolVar(&verboseGitFlag, "verbose-git", false, "Print every git command on stderr before it runs, and its duration after (also SPROUT_VERBOSE_GIT=1)")
+
+	// Subcommands inherit it, so every flag cobra can't parse is a usage error
+	rootCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return usageError{err}
+	})
 
 	// Auto-repair worktrees before any command
 	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		commandName = commandPath(cmd)
+		if renderPlanFlag {
+			dryRunFlag = true
+		}
+		startLog()
+		if verboseGitFlag || os.Getenv("SPROUT_VERBOSE_GIT") == "1" {
+			git.SetTrace(os.Stderr)
+			// Exported so nested sprout invocations trace theirs too
+			os.Setenv("SPROUT_VERBOSE_GIT", "1")
+		}
+		initCI()
+
+		// Exported so hooks and nested sprout invocations don't prompt either
+		if nonInteractive {
+			os.Setenv("SPROUT_NON_INTERACTIVE", "1")
+		}
+		// ...nor color their output
+		initColor()
+		if noColorFlag {
+			os.Setenv("NO_COLOR", "1")
+		}
+		initIcons()
+		if quietHooks {
+			os.Setenv("SPROUT_HOOK_OUTPUT", string(config.HookOutputQuiet))
+		}
+
+		// GIT_DIR and GIT_WORK_TREE select a worktree the way --repo does;
+		// git mustn't see them, as sprout runs it in other worktrees too
+		if repoFlag == "" {
+			if err := applyGitEnvOverrides(); err != nil {
+				printError(err)
+				os.Exit(1)
+			}
+		} else {
+			for _, name := range git.EnvOverrides {
+				os.Unsetenv(name)
+			}
+		}
+
+		// --repo switches the working directory, so every command resolves
+		// the repository exactly as if it had been run from there
+		if repoFlag != "" {
+			dir, err := ResolveRepoFlag(effects.NewRealEffects(), repoFlag)
+			if err == nil {
+				err = os.Chdir(dir)
+			}
+			if err != nil {
+				printError(fmt.Errorf("--repo: %w", err))
+				os.Exit(1)
+			}
+		}
+
 		// Skip in tests or when explicitly disabled
 		if flag.Lookup("test.v") != nil || os.Getenv("SPROUT_SKIP_AUTOREPAIR") == "1" {
 			return
 		}
 
 		// Skip for commands that don't need worktree repair
-		if cmd.Name() == "completion" || cmd.Name() == "help" {
+		// (prompt runs on every shell prompt and must stay fast)
+		if cmd.Name() == "completion" || cmd.Name() == "help" || cmd.Name() == "self-update" || cmd.Name() == "prompt" {
 			return
 		}
 
@@ -48,10 +140,123 @@ func init() {
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// Flags aren't parsed yet; PersistentPreRun decides again with --no-color
+	initColor()
+
+	// Aliases expand first, so they may name plugins too. An unreadable
+	// user config is reported by the commands that need it
+	args := os.Args[1:]
+	if userCfg, err := config.LoadUser(); err == nil {
+		if args, err = expandAliases(args, userCfg.Aliases); err != nil {
+			printError(err)
+			os.Exit(effects.ExitUsage)
+		}
+	}
+	rootCmd.SetArgs(args)
+
+	// 'sprout foo' runs a sprout-foo plugin when foo isn't a command
+	if path, ok := lookupPlugin(args); ok {
+		runPlugin(path, args[1:])
+	}
+
+	startedAt = time.Now()
+	defer logging.Close()
+	timing.Start()
+	cmd, err := rootCmd.ExecuteC()
+	commandName = commandPath(cmd)
+	if err != nil {
+		if !isUsageError(rootCmd, cmd, args, err) {
+			exitWithError(err)
+		}
+		logging.Printf("error: %v", err)
+		recordTelemetry("usage")
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(effects.ExitUsage)
+	}
+	saveTimings()
+	recordTelemetry("")
+	logging.Printf("done in %s", time.Since(startedAt).Round(time.Millisecond))
+}
+
+// usageError is a flag cobra could not parse.
+type usageError struct{ error }
+
+func (e usageError) Unwrap() error { return e.error }
+
+// isUsageError reports whether err, which ExecuteC returned for cmd, is
+// cobra rejecting the command line: an unknown command or flag, a wrong
+// number of arguments or flags that can't go together. Anything else
+// failed while the command ran. Cobra's checks are side-effect free, so
+// they are run again to tell.
+func isUsageError(root, cmd *cobra.Command, args []string, err error) bool {
+	if errors.As(err, new(usageError)) {
+		return true
+	}
+	if _, _, err := root.Find(args); err != nil {
+		return true
+	}
+	return cmd.ValidateArgs(cmd.Flags().Args()) != nil ||
+		cmd.ValidateRequiredFlags() != nil ||
+		cmd.ValidateFlagGroups() != nil
+}
+
+// commandPath names cmd without the program name, e.g. "api create".
+func commandPath(cmd *cobra.Command) string {
+	return strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" ")
+}
+
+// isBackgroundCommand reports whether name is one of the commands shells
+// run on their own (the prompt and completion). They run constantly and
+// must stay fast, so they are left out of timings and telemetry, as is
+// sprout without a command.
+func isBackgroundCommand(name string) bool {
+	switch strings.Fields(name)[0] {
+	case rootCmd.Name(), "prompt", "completion", "help", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	return false
+}
+
+// saveTimings stores the timings of a command that completed, for 'sprout
+// stats', under the repository it ran in. Dry runs, stats itself and
+// uninstall, which just removed the store, are not recorded. Failing to
+// save is only logged.
+func saveTimings() {
+	if isBackgroundCommand(commandName) || commandName == "stats" || commandName == "uninstall" || dryRunFlag {
+		return
+	}
+	timing.Record(timing.Command, commandName, startedAt)
+
+	samples := timing.Drain()
+	repo, _ := effects.NewRealEffects().GetMainWorktreePath()
+	for i := range samples {
+		samples[i].Repo = repo
+	}
+	if err := sprout.AppendTimings(samples); err != nil {
+		logging.Printf("failed to save timings: %v", err)
+	}
+}
+
+// startLog opens the debug log given with --log-file, or the default one
+// in the XDG state directory with SPROUT_DEBUG=1. A log that can't be
+// opened is reported without failing the command.
+func startLog() {
+	path := logFileFlag
+	if path == "" && os.Getenv("SPROUT_DEBUG") == "1" {
+		var err error
+		if path, err = sprout.GetLogPath(); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  No debug log: %v\n", err)
+			return
+		}
+	}
+	if path == "" {
+		return
+	}
+	if err := logging.Open(path); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  No debug log: %v\n", err)
+		return
 	}
+	logging.Printf("sprout %s (commit %s): %s", version, commit, strings.Join(os.Args[1:], " "))
 }
 
 // autoRepairWorktrees runs silent worktree repair before each command.
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsUsageError(t *testing.T) {
+	t.Parallel()
+
+	// A tree of its own: executing rootCmd would run real commands
+	newRoot := func() *cobra.Command {
+		root := &cobra.Command{Use: "sprout"}
+		root.SetFlagErrorFunc(func(_ *cobra.Command, err error) error { return usageError{err} })
+		root.SilenceErrors, root.SilenceUsage = true, true
+		run := &cobra.Command{
+			Use:  "run <name>",
+			Args: cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error { return errors.New("it broke") },
+		}
+		run.Flags().Bool("open", false, "")
+		run.Flags().Bool("no-open", false, "")
+		run.MarkFlagsMutuallyExclusive("open", "no-open")
+		root.AddCommand(run)
+		return root
+	}
+
+	tests := []struct {
+		name  string
+		args  []string
+		usage bool
+	}{
+		{"unknown command", []string{"nope"}, true},
+		{"unknown flag", []string{"run", "x", "--nope"}, true},
+		{"wrong number of arguments", []string{"run"}, true},
+		{"flags that can't go together", []string{"run", "x", "--open", "--no-open"}, true},
+		{"the command failed", []string{"run", "x"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			root := newRoot()
+			root.SetArgs(tt.args)
+			cmd, err := root.ExecuteC()
+			assert.Error(t, err)
+			assert.Equal(t, tt.usage, isUsageError(root, cmd, tt.args, err))
+		})
+	}
+}
@@ -1,25 +1,153 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/m44rten1/sprout/internal/core"
 	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/logging"
+	"github.com/m44rten1/sprout/internal/sprout"
 )
 
 // runPlan executes a plan, or prints it in dry-run mode.
+// Step progress is shown unless --no-progress is set.
 func runPlan(plan core.Plan, fx effects.Effects) {
 	if dryRunFlag {
-		fmt.Println(core.FormatPlan(plan))
+		printPlan(plan)
 		return
 	}
-	if err := effects.ExecutePlan(plan, fx); err != nil {
-		if code, ok := effects.IsExit(err); ok {
-			os.Exit(code)
+	if err := executePlan(plan, fx); err != nil {
+		exitWithPlanError(err)
+	}
+}
+
+// printPlan prints what plan would do, or with --render-plan the plan
+// itself (see core.RenderPlan).
+func printPlan(plan core.Plan) {
+	if renderPlanFlag {
+		fmt.Println(core.RenderPlan(plan))
+		return
+	}
+	fmt.Println(core.FormatPlan(plan))
+}
+
+// executePlan executes a plan, showing step progress unless --no-progress
+// is set.
+func executePlan(plan core.Plan, fx effects.Effects) error {
+	return effects.ExecutePlanWithOptions(plan, fx, effects.ExecuteOptions{Progress: !noProgressFlag})
+}
+
+// exitWithPlanError exits with the code of the plan's Exit action, or
+// else like exitWithError.
+func exitWithPlanError(err error) {
+	if code, ok := effects.IsExit(err); ok {
+		logging.Printf("exit %d", code)
+		if code != 0 {
+			recordTelemetry("exit")
 		}
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(code)
 	}
+	exitWithError(err)
 }
 
+// repoLockTimeout is how long a command waits for another sprout process
+// changing the same repository's worktrees to finish.
+const repoLockTimeout = 10 * time.Second
+
+// lockRepo takes the lock of the current repository's worktrees (see
+// Effects.LockRepo) for a plan that changes them, exiting if another sprout
+// process keeps it. Take it before gathering the plan's context, so the
+// plan is built from the state it changes rather than one another process
+// is about to change. Dry runs change nothing and take no lock, and outside
+// a repository there is nothing to lock, so the command reports that.
+// Exiting releases the lock too, so callers need not unlock on every path.
+func lockRepo(fx effects.Effects) (unlock func()) {
+	if dryRunFlag {
+		return func() {}
+	}
+	mainWorktreePath, err := fx.GetMainWorktreePath()
+	if err != nil {
+		return func() {}
+	}
+	unlock, err = fx.LockRepo(mainWorktreePath, repoLockTimeout)
+	if err != nil {
+		exitWithError(err)
+	}
+	return unlock
+}
+
+// exitWithError prints err and exits. When git failed, everything it printed
+// follows the one-line message. A prompt refused by --non-interactive, and
+// a repository lock that was not released in time, exit with their own codes.
+func exitWithError(err error) {
+	logging.Printf("error: %v", err)
+	recordTelemetry(errorType(err))
+	printError(err)
+	var gitErr *git.GitError
+	if errors.As(err, &gitErr) && strings.Contains(gitErr.Stderr, "\n") {
+		fmt.Fprintln(os.Stderr, "\ngit output:")
+		for _, line := range strings.Split(gitErr.Stderr, "\n") {
+			fmt.Fprintln(os.Stderr, "  "+line)
+		}
+	}
+	os.Exit(errorExitCode(err))
+}
+
+// errorJSON is how a failed command reports its error with --json.
+type errorJSON struct {
+	Error string        `json:"error"`
+	Git   *git.GitError `json:"git,omitempty"` // Set when a git command failed
+}
+
+// exitWithJSONError prints err as a JSON object on stdout and exits, for
+// commands whose --json output is read by tools.
+func exitWithJSONError(err error) {
+	report := errorJSON{Error: err.Error()}
+	var gitErr *git.GitError
+	if errors.As(err, &gitErr) {
+		report.Git = gitErr
+	}
+	data, _ := json.Marshal(report)
+	fmt.Println(string(data))
+	recordTelemetry(errorType(err))
+	os.Exit(errorExitCode(err))
+}
+
+// errorExitCode is 1, or ExitNonInteractive for a refused prompt.
+func errorExitCode(err error) int {
+	switch {
+	case errors.Is(err, effects.ErrNonInteractive):
+		return effects.ExitNonInteractive
+	case errors.Is(err, sprout.ErrRepoLocked):
+		return effects.ExitLocked
+	}
+	return 1
+}
+
+// runPlanPrintingPath runs a plan for --print-path: its messages are left
+// out and anything else written to stdout (hook output, notices) goes to
+// stderr, so stdout carries nothing but path once the plan succeeds.
+// --render-plan prints the whole plan instead.
+func runPlanPrintingPath(plan core.Plan, fx effects.Effects, path string) {
+	runPlanPrinting(plan, fx, func() string { return absPath(path) })
+}
+
+// runPlanPrinting runs a plan like runPlanPrintingPath, printing what output
+// returns once the plan succeeded instead of a path, as for --porcelain.
+func runPlanPrinting(plan core.Plan, fx effects.Effects, output func() string) {
+	if renderPlanFlag {
+		printPlan(plan)
+		return
+	}
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	runPlan(core.WithoutMessages(plan), fx)
+	os.Stdout = stdout
+	fmt.Println(output())
+}
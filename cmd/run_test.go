@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/sprout"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockRepo(t *testing.T) {
+	t.Parallel()
+
+	t.Run("locks the main worktree until unlocked", func(t *testing.T) {
+		t.Parallel()
+		fx := effects.NewTestEffects()
+		fx.MainWorktreePath = "/code/app"
+
+		unlock := lockRepo(fx)
+		assert.Equal(t, []string{"/code/app"}, fx.LockedRepos)
+		unlock()
+		assert.Empty(t, fx.LockedRepos)
+	})
+
+	t.Run("nothing to lock outside a repository", func(t *testing.T) {
+		t.Parallel()
+		fx := effects.NewTestEffects()
+		fx.GetMainWorktreePathErr = assert.AnError
+
+		lockRepo(fx)()
+		assert.Empty(t, fx.LockedRepos)
+	})
+}
+
+func TestErrorExitCode(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 1, errorExitCode(errors.New("boom")))
+	assert.Equal(t, effects.ExitNonInteractive, errorExitCode(fmt.Errorf("%w; pass a branch", effects.ErrNonInteractive)))
+	assert.Equal(t, effects.ExitLocked, errorExitCode(fmt.Errorf("lock: %w", sprout.ErrRepoLocked)))
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/m44rten1/sprout/internal/selfupdate"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	selfUpdateChannelFlag   string
+	selfUpdateDowngradeFlag bool
+)
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update sprout to the latest release",
+	Long: `Download the latest sprout release for this platform, verify its SHA256
+checksum against the release's checksums.txt, and atomically replace the
+running binary.
+
+Use --channel prerelease to include pre-release versions. A release older
+than the running version (say, the latest stable one while running a
+pre-release) is only installed with --allow-downgrade.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runSelfUpdate(); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(selfUpdateCmd)
+	selfUpdateCmd.Flags().StringVar(&selfUpdateChannelFlag, "channel", string(selfupdate.ChannelStable), "Release channel: stable or prerelease")
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateDowngradeFlag, "allow-downgrade", false, "Install the selected release even if it is older than this version")
+}
+
+func runSelfUpdate() error {
+	channel, err := selfupdate.ParseChannel(selfUpdateChannelFlag)
+	if err != nil {
+		return err
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate current executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+		exePath = resolved
+	}
+
+	// Homebrew owns its Cellar; replacing the binary behind its back breaks `brew upgrade`
+	if strings.Contains(exePath, string(filepath.Separator)+"Cellar"+string(filepath.Separator)) {
+		return fmt.Errorf("sprout was installed with Homebrew; run 'brew upgrade sprout' instead")
+	}
+
+	client := selfupdate.NewClient()
+	releases, err := client.FetchReleases()
+	if err != nil {
+		return err
+	}
+
+	release, ok := selfupdate.SelectRelease(releases, channel)
+	if !ok {
+		return fmt.Errorf("no %s release found", channel)
+	}
+
+	if release.Version() == version {
+		fmt.Printf("✓ sprout %s is already the latest %s release\n", version, channel)
+		return nil
+	}
+	if c, ok := selfupdate.CompareVersions(release.Version(), version); ok && c < 0 && !selfUpdateDowngradeFlag {
+		return fmt.Errorf("the latest %s release, %s, is older than sprout %s; use --allow-downgrade to install it anyway", channel, release.Version(), version)
+	}
+
+	assetName := selfupdate.AssetName(runtime.GOOS, runtime.GOARCH)
+	asset, ok := release.FindAsset(assetName)
+	if !ok {
+		return fmt.Errorf("release %s has no binary for %s/%s (expected %s)", release.TagName, runtime.GOOS, runtime.GOARCH, assetName)
+	}
+	checksumsAsset, ok := release.FindAsset(selfupdate.ChecksumsAsset)
+	if !ok {
+		return fmt.Errorf("release %s has no %s; refusing to install an unverified binary", release.TagName, selfupdate.ChecksumsAsset)
+	}
+
+	if dryRunFlag {
+		fmt.Printf("Would update sprout %s → %s (%s channel)\n", version, release.Version(), channel)
+		fmt.Printf("  Download: %s\n", asset.URL)
+		fmt.Printf("  Replace:  %s\n", exePath)
+		return nil
+	}
+
+	fmt.Printf("Updating sprout %s → %s...\n", version, release.Version())
+
+	checksumData, err := client.Download(checksumsAsset)
+	if err != nil {
+		return err
+	}
+	expected, ok := selfupdate.ParseChecksums(checksumData)[assetName]
+	if !ok {
+		return fmt.Errorf("%s does not list %s", selfupdate.ChecksumsAsset, assetName)
+	}
+
+	archive, err := client.Download(asset)
+	if err != nil {
+		return err
+	}
+	if err := selfupdate.VerifyChecksum(archive, expected); err != nil {
+		return fmt.Errorf("%s: %w", assetName, err)
+	}
+
+	binary, err := selfupdate.ExtractBinary(assetName, archive, selfupdate.BinaryName(runtime.GOOS))
+	if err != nil {
+		return err
+	}
+
+	if err := selfupdate.ReplaceExecutable(exePath, binary); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Updated to sprout %s\n", release.Version())
+	return nil
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/m44rten1/sprout/internal/build"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotAllFlag    bool
+	snapshotOutputFlag string
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Export worktrees to a YAML manifest",
+	Long: `Export the sprout worktrees of the current repository (or all repositories
+with --all) to a YAML manifest listing each repository, branch and base.
+
+Recreate them later, or on another machine, with 'sprout restore <manifest>'.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+
+		manifest, err := BuildManifest(fx, snapshotAllFlag)
+		if err != nil {
+			exitWithError(err)
+		}
+
+		output, err := core.FormatManifest(manifest)
+		if err != nil {
+			exitWithError(err)
+		}
+
+		if snapshotOutputFlag == "" {
+			fmt.Print(output)
+			return
+		}
+		if err := os.WriteFile(snapshotOutputFlag, []byte(output), 0644); err != nil {
+			printError(fmt.Errorf("failed to write manifest: %w", err))
+			os.Exit(1)
+		}
+		fmt.Printf("📸 Saved manifest to %s\n", snapshotOutputFlag)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.Flags().BoolVar(&snapshotAllFlag, "all", false, "Export worktrees from all repositories")
+	snapshotCmd.Flags().StringVarP(&snapshotOutputFlag, "output", "o", "", "Write the manifest to a file instead of stdout")
+}
+
+// BuildManifest gathers the sprout worktrees to export, reusing list's discovery.
+// Paths under the home directory are written with "~" so manifests are portable.
+// Detached HEAD worktrees are skipped since they have no branch to recreate.
+// The base is the ref 'sprout add' recorded the branch was created from,
+// and left out where none was.
+func BuildManifest(fx effects.Effects, all bool) (core.Manifest, error) {
+	listCtx, err := BuildListContext(fx, all)
+	if err != nil {
+		return core.Manifest{}, err
+	}
+	build.AssignDetails(fx, listCtx.Repos)
+
+	manifest := core.Manifest{Version: core.ManifestVersion, Repos: []core.ManifestRepo{}}
+	for _, repo := range listCtx.Repos {
+		entry := core.ManifestRepo{
+			Path:      core.ShortenPathWithHome(repo.MainPath, listCtx.Home),
+			Worktrees: []core.ManifestWorktree{},
+		}
+		if remote, err := fx.RunGitCommand(repo.MainPath, "remote", "get-url", "origin"); err == nil {
+			entry.Remote = remote
+		}
+
+		for _, wt := range repo.Worktrees {
+			if wt.IsMain || wt.Branch == "" {
+				continue
+			}
+			entry.Worktrees = append(entry.Worktrees, core.ManifestWorktree{Branch: wt.Branch, Base: wt.Base})
+		}
+
+		if len(entry.Worktrees) > 0 {
+			manifest.Repos = append(manifest.Repos, entry)
+		}
+	}
+
+	return manifest, nil
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/sprout"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildManifest(t *testing.T) {
+	t.Parallel()
+
+	fx := effects.NewTestEffects()
+	fx.RepoRoot = "/home/user/code/app"
+	fx.UserHome = "/home/user"
+	fx.SproutRoot = "/home/user/.local/share/sprout"
+	fx.Worktrees = []git.Worktree{
+		{Path: "/home/user/code/app", Branch: "main"},
+		{Path: "/home/user/.local/share/sprout/app-1234/feat/app", Branch: "feat"},
+		{Path: "/home/user/.local/share/sprout/app-1234/detached/app", Branch: ""},
+	}
+	fx.Files["/home/user/.local/share/sprout/app-1234/feat/app"] = true
+	fx.Files["/home/user/.local/share/sprout/app-1234/detached/app"] = true
+	fx.GitCommandOutput["/home/user/code/app\nremote get-url origin"] = "git@github.com:mycompany/app.git"
+	fx.WorktreeMetadata["/home/user/.local/share/sprout/app-1234/feat/app"] = sprout.WorktreeMeta{Base: "origin/main"}
+
+	manifest, err := BuildManifest(fx, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, core.Manifest{Version: core.ManifestVersion, Repos: []core.ManifestRepo{{
+		Path:      "~/code/app",
+		Remote:    "git@github.com:mycompany/app.git",
+		Worktrees: []core.ManifestWorktree{{Branch: "feat", Base: "origin/main"}},
+	}}}, manifest)
+}
+
+func TestBuildRestoreContext(t *testing.T) {
+	t.Parallel()
+
+	fx := effects.NewTestEffects()
+	fx.UserHome = "/home/user"
+	fx.Files["/home/user/code/app"] = true
+	fx.Worktrees = []git.Worktree{{Path: "/home/user/code/app", Branch: "main"}}
+	fx.RemoteBranches["feat"] = true
+	fx.WorktreePaths["feat"] = "/sprout/app-1234/feat/app"
+	fx.WorktreePaths["other"] = "/sprout/other-5678/other/other"
+
+	ctx, err := BuildRestoreContext(fx, core.Manifest{Version: core.ManifestVersion, Repos: []core.ManifestRepo{
+		{Path: "~/code/app", Worktrees: []core.ManifestWorktree{{Branch: "feat"}}},
+		{Path: "~/code/other", Remote: "git@github.com:mycompany/other.git", Worktrees: []core.ManifestWorktree{{Branch: "other"}}},
+	}})
+	require.NoError(t, err)
+	require.Len(t, ctx.Repos, 2)
+
+	assert.True(t, ctx.Repos[0].Exists)
+	assert.Equal(t, "/home/user/code/app", ctx.Repos[0].Path)
+	assert.True(t, ctx.Repos[0].Worktrees[0].RemoteBranchExists)
+
+	assert.False(t, ctx.Repos[1].Exists, "missing repository should be cloned")
+	assert.Equal(t, "/home/user/code/other", ctx.Repos[1].Path)
+	assert.False(t, ctx.Repos[1].Worktrees[0].RemoteBranchExists, "branches are not queried in a repository that doesn't exist yet")
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+
+	"github.com/spf13/cobra"
+)
+
+var statsAllFlag bool
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show how long commands and hooks typically take",
+	Long: `Show how long sprout commands, hook commands, worktree status checks and
+individual actions typically take in the current repository: the number of
+runs and the median, 90th percentile and slowest duration, slowest first.
+
+sprout records these timings as you use it and keeps the most recent ones
+in timings.json in its state directory. They never leave your machine.
+Only commands that complete are recorded, and their durations include
+time spent in pickers and prompts.
+
+  --all   reports every repository`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+
+		ctx, err := BuildStatsContext(fx, statsAllFlag)
+		if err != nil {
+			exitWithError(err)
+		}
+
+		plan := core.PlanStatsCommand(ctx)
+		runPlan(plan, fx)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().BoolVar(&statsAllFlag, "all", false, "Report every repository")
+}
+
+// BuildStatsContext gathers the recorded timings and, unless all is set,
+// the repository to report on.
+func BuildStatsContext(fx effects.Effects, all bool) (core.StatsContext, error) {
+	samples, err := fx.LoadTimings()
+	if err != nil {
+		return core.StatsContext{}, fmt.Errorf("failed to load timings: %w", err)
+	}
+	home, _ := fx.UserHomeDir()
+	ctx := core.StatsContext{Samples: samples, Home: home}
+
+	if !all {
+		mainWorktreePath, err := fx.GetMainWorktreePath()
+		if err != nil {
+			return core.StatsContext{}, fmt.Errorf("not a git repository (use --all for every repository): %w", err)
+		}
+		ctx.Repo = mainWorktreePath
+	}
+	return ctx, nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/timing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildStatsContext(t *testing.T) {
+	t.Parallel()
+
+	newFx := func() *effects.TestEffects {
+		fx := baseTestFx()
+		fx.UserHome = "/home/me"
+		fx.Timings = []timing.Sample{
+			{Repo: "/test/repo", Kind: timing.Command, Name: "list", Duration: time.Second},
+		}
+		return fx
+	}
+
+	t.Run("reports the current repository", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+
+		ctx, err := BuildStatsContext(fx, false)
+		require.NoError(t, err)
+		assert.Equal(t, "/test/repo", ctx.Repo)
+		assert.Equal(t, "/home/me", ctx.Home)
+		assert.Equal(t, fx.Timings, ctx.Samples)
+	})
+
+	t.Run("all works outside a repository", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.GetMainWorktreePathErr = errors.New("not a git repository")
+
+		ctx, err := BuildStatsContext(fx, true)
+		require.NoError(t, err)
+		assert.Empty(t, ctx.Repo)
+		assert.Len(t, ctx.Samples, 1)
+
+		_, err = BuildStatsContext(fx, false)
+		assert.ErrorContains(t, err, "use --all")
+	})
+
+	t.Run("unreadable timings", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.LoadTimingsErr = errors.New("corrupt")
+
+		_, err := BuildStatsContext(fx, true)
+		assert.ErrorContains(t, err, "failed to load timings")
+	})
+}
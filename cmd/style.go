@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/style"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var noColorFlag bool
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&noColorFlag, "no-color", false, "Don't color output (also when NO_COLOR is set or output isn't a terminal)")
+
+	// Help doesn't run PersistentPreRun, and help texts were styled when
+	// the commands were defined
+	help := rootCmd.HelpFunc()
+	rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		initColor()
+		if !style.Enabled() {
+			cmd.Long = style.Strip(cmd.Long)
+		}
+		help(cmd, args)
+	})
+}
+
+// initColor decides whether sprout colors its output: not with --no-color,
+// NO_COLOR or TERM=dumb, nor when stdout isn't a terminal.
+func initColor() {
+	style.SetEnabled(style.ShouldColor(noColorFlag, os.Getenv, term.IsTerminal(int(os.Stdout.Fd()))))
+}
+
+// initIcons switches to the icons set in the user config. A config that
+// doesn't load keeps the defaults; commands that need it report why.
+func initIcons() {
+	if userCfg, err := config.LoadUser(); err == nil {
+		style.SetIcons(userCfg.StatusIcons())
+	}
+}
+
+// printError prints err to stderr as sprout's commands report errors.
+func printError(err error) {
+	fmt.Fprintf(os.Stderr, "%s %v\n", style.Red("Error:"), err)
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/hooks"
+	"github.com/m44rten1/sprout/internal/logging"
+	"github.com/m44rten1/sprout/internal/sprout"
+	"github.com/m44rten1/sprout/internal/telemetry"
+
+	"github.com/spf13/cobra"
+)
+
+var telemetryCmd = &cobra.Command{
+	Use:   "telemetry",
+	Short: "Opt in to or out of anonymous usage statistics",
+	Long: `Telemetry is off unless you turn it on. When on, sprout counts how often
+each command runs, how long it takes and which kinds of errors it fails with
+(such as "git" or "hook"), and sends a summary once a week along with the
+sprout version, OS and architecture.
+
+No paths, repository or branch names, arguments, error messages or
+identifiers are collected. 'sprout telemetry show' prints the exact report
+that will be sent.`,
+}
+
+var telemetryOnCmd = &cobra.Command{
+	Use:   "on",
+	Short: "Turn telemetry on",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runTelemetryPlan(core.PlanTelemetryOn)
+	},
+}
+
+var telemetryOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Turn telemetry off and discard what was collected",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runTelemetryPlan(core.PlanTelemetryOff)
+	},
+}
+
+var telemetryShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print exactly what would be sent",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runTelemetryPlan(core.PlanTelemetryShow)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(telemetryCmd)
+	telemetryCmd.AddCommand(telemetryOnCmd, telemetryOffCmd, telemetryShowCmd)
+}
+
+func runTelemetryPlan(planner func(core.TelemetryContext) core.Plan) {
+	fx := effects.NewRealEffects()
+
+	ctx, err := BuildTelemetryContext(fx, time.Now())
+	if err != nil {
+		exitWithError(err)
+	}
+
+	runPlan(planner(ctx), fx)
+}
+
+// BuildTelemetryContext gathers the telemetry state and what a report
+// made at now would describe.
+func BuildTelemetryContext(fx effects.Effects, now time.Time) (core.TelemetryContext, error) {
+	state, err := fx.LoadTelemetry()
+	if err != nil {
+		return core.TelemetryContext{}, err
+	}
+	return core.TelemetryContext{
+		State:    *state,
+		Endpoint: telemetry.Endpoint,
+		Version:  version,
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+		Now:      now,
+	}, nil
+}
+
+// recordTelemetry adds the running command to the usage aggregated for
+// telemetry, failed with errType unless it is empty, if the user opted
+// in. A command that succeeds sends the weekly report when it is due.
+// Telemetry never fails a command: problems are only logged.
+func recordTelemetry(errType string) {
+	if commandName == "" || isBackgroundCommand(commandName) || strings.Fields(commandName)[0] == "telemetry" {
+		return
+	}
+	state, err := sprout.LoadTelemetry()
+	if err != nil || !state.Enabled {
+		return
+	}
+	now := time.Now()
+	state.Record(commandName, now.Sub(startedAt), errType)
+
+	if errType == "" && state.Due(now) {
+		state.LastAttempt = now
+		if err := telemetry.Send(core.BuildTelemetryReport(*state, version, runtime.GOOS, runtime.GOARCH, now)); err != nil {
+			logging.Printf("telemetry: %v", err)
+		} else {
+			state.Reset(now)
+		}
+	}
+	if err := sprout.SaveTelemetry(state); err != nil {
+		logging.Printf("telemetry: %v", err)
+	}
+}
+
+// errorType classifies err for telemetry by what failed, never by its
+// message, which may contain paths and branch names.
+func errorType(err error) string {
+	var gitErr *git.GitError
+	var hookErr *hooks.HookExecutionError
+	var untrustedErr *hooks.UntrustedError
+	switch {
+	case errors.Is(err, effects.ErrNonInteractive):
+		return "non_interactive"
+	case errors.Is(err, core.ErrSelectionCancelled):
+		return "cancelled"
+	case errors.Is(err, core.ErrNoWorktreeFound), errors.Is(err, core.ErrNoSproutWorktrees):
+		return "not_found"
+	case errors.Is(err, core.ErrUntrustedWithHooks), errors.As(err, &untrustedErr):
+		return "untrusted"
+	case errors.As(err, &hookErr):
+		return "hook"
+	case errors.As(err, &gitErr):
+		return "git"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/hooks"
+	"github.com/m44rten1/sprout/internal/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTelemetryContext(t *testing.T) {
+	t.Parallel()
+	fx := effects.NewTestEffects()
+	fx.Telemetry = telemetry.State{Enabled: true}
+	now := time.Date(2026, 10, 8, 0, 0, 0, 0, time.UTC)
+
+	ctx, err := BuildTelemetryContext(fx, now)
+	require.NoError(t, err)
+	assert.True(t, ctx.State.Enabled)
+	assert.Equal(t, version, ctx.Version)
+	assert.Equal(t, now, ctx.Now)
+	assert.NotEmpty(t, ctx.OS)
+}
+
+func TestErrorType(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("prompt trust: %w", effects.ErrNonInteractive), "non_interactive"},
+		{core.ErrSelectionCancelled, "cancelled"},
+		{fmt.Errorf("%w for branch feature", core.ErrNoWorktreeFound), "not_found"},
+		{&hooks.UntrustedError{RepoRoot: "/repo"}, "untrusted"},
+		{fmt.Errorf("run on_create hooks: %w", &hooks.HookExecutionError{Command: "npm ci", ExitCode: 1}), "hook"},
+		{fmt.Errorf("fetch: %w", &git.GitError{Args: []string{"fetch"}, ExitCode: 128}), "git"},
+		{errors.New("/home/me/repo: permission denied"), "other"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, errorType(tt.err), tt.err.Error())
+	}
+}
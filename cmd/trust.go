@@ -1,11 +1,14 @@
 package cmd
 
 import (
+	"cmp"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/m44rten1/sprout/internal/core"
 	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/trust"
 
 	"github.com/spf13/cobra"
 )
@@ -14,36 +17,57 @@ import (
 // It uses the provided effects to determine the repository root and trust status.
 // If pathArg is empty, it uses the current repository.
 func BuildTrustContext(fx effects.Effects, pathArg string) (core.TrustContext, error) {
-	var repoRoot string
+	var repoRoot, worktreePath string
 	var err error
 
 	if pathArg != "" {
 		// Trust the specified path - verify it's a git repo
-		repoRoot = pathArg
-		_, err := fx.RunGitCommand(repoRoot, "rev-parse", "--show-toplevel")
+		toplevel, err := fx.RunGitCommand(pathArg, "rev-parse", "--show-toplevel")
 		if err != nil {
-			return core.TrustContext{}, fmt.Errorf("not a git repository: %s", repoRoot)
+			return core.TrustContext{}, fmt.Errorf("not a git repository: %s", pathArg)
 		}
+		repoRoot = resolveMainWorktree(fx, pathArg, toplevel)
+		worktreePath = toplevel
 	} else {
 		// Trust current repo - use main worktree path
 		repoRoot, err = fx.GetMainWorktreePath()
 		if err != nil {
 			return core.TrustContext{}, fmt.Errorf("get main worktree: %w", err)
 		}
+		worktreePath, _ = fx.GetRepoRoot()
+	}
+	// Run from another worktree, its own .sprout.yml hooks are the ones trusted
+	if worktreePath == repoRoot {
+		worktreePath = ""
 	}
 
 	// Check if already trusted
-	isTrusted, err := fx.IsTrusted(repoRoot)
+	isTrusted, err := fx.IsTrusted(repoRoot, cmp.Or(worktreePath, repoRoot))
 	if err != nil {
 		return core.TrustContext{}, fmt.Errorf("check trust status: %w", err)
 	}
 
 	return core.TrustContext{
 		RepoRoot:       repoRoot,
+		WorktreePath:   worktreePath,
 		AlreadyTrusted: isTrusted,
 	}, nil
 }
 
+// resolveMainWorktree maps a path inside a repository to its main worktree path,
+// the key used for trust, config fallback, and sprout path computation. This keeps
+// a repo's identity the same whether it's addressed via the main checkout or a worktree.
+// Falls back to the toplevel (or the path itself) if worktrees can't be listed.
+func resolveMainWorktree(fx effects.Effects, path, toplevel string) string {
+	if worktrees, err := fx.ListWorktrees(path); err == nil && len(worktrees) > 0 {
+		return worktrees[0].Path
+	}
+	if toplevel != "" {
+		return toplevel
+	}
+	return path
+}
+
 var trustCmd = &cobra.Command{
 	Use:   "trust [path]",
 	Short: "Trust a repository to run hooks",
@@ -51,6 +75,13 @@ var trustCmd = &cobra.Command{
 
 If no path is provided, the current repository is trusted.
 
+If trust_ttl is set in ~/.config/sprout/config.yml (e.g. "trust_ttl: 90d"),
+trust expires after that long and hooks require re-confirmation. Use
+'sprout trust --renew' to refresh it and 'sprout trust list' to see expiry dates.
+
+With --ci, trusting takes --yes as well, so a pipeline only ever trusts the
+repositories it names on purpose: 'sprout trust --yes <path>'.
+
 WARNING: Only trust repositories you control or have reviewed the .sprout.yml file for.
 Hooks can execute arbitrary commands on your system.`,
 	Args: cobra.MaximumNArgs(1),
@@ -67,8 +98,12 @@ Hooks can execute arbitrary commands on your system.`,
 		// Build context from effects
 		ctx, err := BuildTrustContext(fx, pathArg)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
+		}
+
+		ctx.Renew = trustRenewFlag
+		if ciMode() && !trustYesFlag && (!ctx.AlreadyTrusted || ctx.Renew) {
+			exitWithError(fmt.Errorf("%w; pass --yes to trust %s in CI", effects.ErrNonInteractive, ctx.RepoRoot))
 		}
 
 		// Plan and execute
@@ -77,6 +112,72 @@ Hooks can execute arbitrary commands on your system.`,
 	},
 }
 
+var trustListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List trusted repositories and when their trust expires",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, err := BuildTrustListContext()
+		if err != nil {
+			exitWithError(err)
+		}
+		fmt.Println(core.FormatTrustList(ctx))
+	},
+}
+
+var (
+	trustRenewFlag bool
+	trustYesFlag   bool
+)
+
+// BuildTrustListContext gathers trust store entries and the effective TTL.
+func BuildTrustListContext() (core.TrustListContext, error) {
+	store, err := trust.LoadStore()
+	if err != nil {
+		return core.TrustListContext{}, err
+	}
+
+	ttl, err := trust.TrustTTL()
+	if err != nil {
+		return core.TrustListContext{}, err
+	}
+
+	entries := make([]core.TrustEntryDisplay, 0, len(store.Trusted))
+	for _, project := range store.Trusted {
+		expiresAt, _ := project.ExpiresAt(ttl)
+		entries = append(entries, core.TrustEntryDisplay{
+			RepoRoot:  project.RepoRoot,
+			TrustedAt: project.TrustedAt,
+			ExpiresAt: expiresAt,
+		})
+	}
+
+	var ttlLabel string
+	if ttl > 0 {
+		ttlLabel = formatTTL(ttl)
+	}
+
+	home, _ := os.UserHomeDir()
+	return core.TrustListContext{
+		Entries:  entries,
+		TTLLabel: ttlLabel,
+		Now:      time.Now(),
+		Home:     home,
+	}, nil
+}
+
+// formatTTL renders a TTL in days when it is a whole number of days.
+func formatTTL(ttl time.Duration) string {
+	day := 24 * time.Hour
+	if ttl%day == 0 {
+		return fmt.Sprintf("%dd", ttl/day)
+	}
+	return ttl.String()
+}
+
 func init() {
 	rootCmd.AddCommand(trustCmd)
+	trustCmd.AddCommand(trustListCmd)
+	trustCmd.Flags().BoolVar(&trustRenewFlag, "renew", false, "Refresh trust for an already trusted repository (resets trust_ttl expiry)")
+	trustCmd.Flags().BoolVarP(&trustYesFlag, "yes", "y", false, "Confirm trusting the repository (required with --ci)")
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/m44rten1/sprout/internal/core"
 	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -216,6 +217,7 @@ func TestBuildTrustContext(t *testing.T) {
 			name:    "current repo already trusted",
 			pathArg: "",
 			setupFx: func(fx *effects.TestEffects) {
+				fx.RepoRoot = "/home/user/projects/myrepo"
 				fx.MainWorktreePath = "/home/user/projects/myrepo"
 				fx.TrustedRepos["/home/user/projects/myrepo"] = true
 			},
@@ -250,6 +252,41 @@ func TestBuildTrustContext(t *testing.T) {
 				assert.Equal(t, "/explicit/repo", fx.IsTrustedArgs[0])
 			},
 		},
+		{
+			name:    "explicit path inside a worktree resolves to main worktree",
+			pathArg: "/sprout/repo-abc/feature/repo",
+			setupFx: func(fx *effects.TestEffects) {
+				stubGitRepoValid(fx, "/sprout/repo-abc/feature/repo")
+				fx.Worktrees = []git.Worktree{
+					{Path: "/home/user/repo", Branch: "main"},
+					{Path: "/sprout/repo-abc/feature/repo", Branch: "feature"},
+				}
+			},
+			wantCtx: &core.TrustContext{
+				RepoRoot:       "/home/user/repo",
+				WorktreePath:   "/sprout/repo-abc/feature/repo",
+				AlreadyTrusted: false,
+			},
+			wantErr: false,
+			assertEffects: func(t *testing.T, fx *effects.TestEffects) {
+				// Trust is keyed by the main worktree, not the worktree the user pointed at
+				require.Len(t, fx.IsTrustedArgs, 1)
+				assert.Equal(t, "/home/user/repo", fx.IsTrustedArgs[0])
+			},
+		},
+		{
+			name:    "run from a worktree trusts its hooks",
+			pathArg: "",
+			setupFx: func(fx *effects.TestEffects) {
+				fx.RepoRoot = "/sprout/repo-abc/feature/repo"
+				fx.MainWorktreePath = "/home/user/repo"
+			},
+			wantCtx: &core.TrustContext{
+				RepoRoot:       "/home/user/repo",
+				WorktreePath:   "/sprout/repo-abc/feature/repo",
+				AlreadyTrusted: false,
+			},
+		},
 		{
 			name:    "GetMainWorktreePath fails",
 			pathArg: "",
@@ -315,7 +352,7 @@ func TestTrustCommand_EndToEnd(t *testing.T) {
 	fx.TrustedRepos["/test/repo"] = false
 
 	// Verify initial state
-	isTrusted, err := fx.IsTrusted("/test/repo")
+	isTrusted, err := fx.IsTrusted("/test/repo", "/test/repo")
 	require.NoError(t, err)
 	require.False(t, isTrusted, "Initial state: should not be trusted")
 
@@ -332,7 +369,7 @@ func TestTrustCommand_EndToEnd(t *testing.T) {
 
 	// Verify meaningful behavioral outcomes (not test bookkeeping)
 	// 1. State transition: repo becomes trusted
-	isTrusted, err = fx.IsTrusted("/test/repo")
+	isTrusted, err = fx.IsTrusted("/test/repo", "/test/repo")
 	require.NoError(t, err)
 	assert.True(t, isTrusted, "Final state: should be trusted after execution")
 
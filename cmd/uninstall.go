@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/sprout"
+	"github.com/m44rten1/sprout/internal/trust"
+
+	"github.com/spf13/cobra"
+)
+
+var uninstallYes bool
+
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove all sprout worktrees and everything sprout stored",
+	Long: `Remove everything sprout set up, to stop using it or to start over
+from a clean slate:
+
+  - every worktree under the sprout root, through git so repositories
+    forget it (uncommitted changes in them are lost)
+  - the sprout root, with what sprout recorded about worktrees
+  - the state and cache directories, with the debug logs, timings and
+    caches (see 'sprout paths')
+  - the trust store
+  - the completion setup install-completion added to your shell config,
+    which is backed up first
+
+Branches stay in their repositories, worktrees adopted from outside the
+sprout root stay where they are, and ~/.config/sprout/config.yml is kept.
+The sprout binary itself is left for your package manager.
+
+sprout lists what it removes and asks before removing it. Use --dry-run to
+only list it, and --yes to skip the question, which non-interactive runs
+require.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+
+		paths, err := defaultUninstallPaths()
+		if err != nil {
+			exitWithError(err)
+		}
+		ctx, err := BuildUninstallContext(fx, paths)
+		if err != nil {
+			exitWithError(err)
+		}
+
+		// The listing is what --dry-run is for, so it is printed either way
+		if err := effects.ExecutePlan(core.PlanUninstallReport(ctx), fx); err != nil {
+			exitWithError(err)
+		}
+		if !uninstallYes && !dryRunFlag {
+			ok, err := fx.Confirm("Uninstall sprout?")
+			if err != nil {
+				exitWithError(fmt.Errorf("%w; pass --yes to uninstall", err))
+			}
+			if !ok {
+				fmt.Println("Nothing removed.")
+				return
+			}
+		}
+		runPlan(core.PlanUninstall(ctx), fx)
+	},
+}
+
+// UninstallPaths are the files sprout keeps outside the sprout root.
+type UninstallPaths struct {
+	StateDir    string // Debug logs, timings and locks
+	CacheDir    string
+	ConfigDir   string
+	TrustStore  string
+	ShellConfig string // The config file install-completion writes to, if the shell is known
+}
+
+// defaultUninstallPaths finds the files sprout keeps outside the sprout
+// root, as the commands that write them do.
+func defaultUninstallPaths() (UninstallPaths, error) {
+	var paths UninstallPaths
+	var err error
+	if paths.StateDir, err = sprout.GetStateDir(); err != nil {
+		return paths, err
+	}
+	if paths.CacheDir, err = sprout.GetCacheDir(); err != nil {
+		return paths, err
+	}
+	if paths.ConfigDir, err = config.GetUserConfigDir(); err != nil {
+		return paths, err
+	}
+	if paths.TrustStore, err = trust.GetStorePath(); err != nil {
+		return paths, err
+	}
+	if shell := detectShell(); shell != "" {
+		paths.ShellConfig, _ = getShellConfigFile(shell)
+	}
+	return paths, nil
+}
+
+// BuildUninstallContext gathers the sprout worktrees of every repository
+// and the files sprout keeps that exist. Adopted worktrees live outside
+// the sprout root and are left out.
+func BuildUninstallContext(fx effects.Effects, paths UninstallPaths) (core.UninstallContext, error) {
+	sproutRoot, err := fx.GetSproutRoot()
+	if err != nil {
+		return core.UninstallContext{}, fmt.Errorf("get sprout root: %w", err)
+	}
+
+	repos, err := collectAllReposWithEffects(fx)
+	if err != nil {
+		return core.UninstallContext{}, err
+	}
+
+	ctx := core.UninstallContext{DataDir: sproutRoot, ConfigDir: paths.ConfigDir}
+	for _, repo := range repos {
+		var worktrees []core.WorktreeDisplayItem
+		for _, wt := range repo.Worktrees {
+			if !wt.IsMain && core.IsUnderSproutRoot(wt.Path, sproutRoot) {
+				worktrees = append(worktrees, wt)
+			}
+		}
+		if len(worktrees) > 0 {
+			ctx.Repos = append(ctx.Repos, core.UninstallRepo{MainPath: repo.MainPath, Worktrees: worktrees})
+		}
+	}
+
+	if effects.Exists(fx, paths.StateDir) {
+		ctx.StateDir = paths.StateDir
+	}
+	if effects.Exists(fx, paths.CacheDir) {
+		ctx.CacheDir = paths.CacheDir
+	}
+	if paths.TrustStore != "" && effects.Exists(fx, paths.TrustStore) {
+		ctx.TrustStore = paths.TrustStore
+	}
+	if paths.ShellConfig != "" {
+		if data, err := fx.ReadFile(paths.ShellConfig); err == nil {
+			if rest, removed := removeSetupLines(string(data)); removed != "" {
+				ctx.ShellConfig = paths.ShellConfig
+				ctx.ShellConfigData = data
+				ctx.ShellConfigRest = []byte(rest)
+			}
+		}
+	}
+
+	home, _ := fx.UserHomeDir()
+	ctx.Home = home
+	return ctx, nil
+}
+
+func init() {
+	rootCmd.AddCommand(uninstallCmd)
+	uninstallCmd.Flags().BoolVarP(&uninstallYes, "yes", "y", false, "Uninstall without asking")
+}
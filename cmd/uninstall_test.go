@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildUninstallContext(t *testing.T) {
+	t.Parallel()
+
+	sproutWorktree := "/home/user/.local/share/sprout/app-1234/feature/app"
+	fx := effects.NewTestEffects()
+	fx.UserHome = "/home/user"
+	fx.Worktrees = []git.Worktree{
+		{Path: "/code/app", Branch: "main"},
+		{Path: sproutWorktree, Branch: "feature"},
+		{Path: "/manual/adopted", Branch: "adopted"},
+	}
+	fx.Adopted = []string{"/manual/adopted"}
+	fx.Files["/manual/adopted"] = true
+	fx.Files[sproutWorktree] = true
+	fx.Files["/home/user/.config/sprout/trusted-projects.json"] = true
+	fx.FileContents["/home/user/.zshrc"] = []byte("export A=1\n\n" + generateSetupLines("zsh", true))
+
+	ctx, err := BuildUninstallContext(fx, UninstallPaths{
+		StateDir:    "/home/user/.local/state/sprout",
+		ConfigDir:   "/home/user/.config/sprout",
+		TrustStore:  "/home/user/.config/sprout/trusted-projects.json",
+		ShellConfig: "/home/user/.zshrc",
+	})
+	require.NoError(t, err)
+
+	require.Len(t, ctx.Repos, 1)
+	assert.Equal(t, "/code/app", ctx.Repos[0].MainPath)
+	require.Len(t, ctx.Repos[0].Worktrees, 1, "adopted worktrees are left alone")
+	assert.Equal(t, sproutWorktree, ctx.Repos[0].Worktrees[0].Path)
+
+	assert.Equal(t, fx.SproutRoot, ctx.DataDir)
+	assert.Empty(t, ctx.StateDir, "missing directories are left out")
+	assert.Equal(t, "/home/user/.config/sprout/trusted-projects.json", ctx.TrustStore)
+	assert.Equal(t, "/home/user/.zshrc", ctx.ShellConfig)
+	assert.Equal(t, "export A=1\n", string(ctx.ShellConfigRest))
+	assert.Equal(t, "/home/user", ctx.Home)
+}
+
+func TestBuildUninstallContext_NoCompletionSetup(t *testing.T) {
+	t.Parallel()
+	fx := effects.NewTestEffects()
+	fx.FileContents["/home/user/.zshrc"] = []byte("export A=1\n")
+
+	ctx, err := BuildUninstallContext(fx, UninstallPaths{ShellConfig: "/home/user/.zshrc"})
+	require.NoError(t, err)
+	assert.Equal(t, core.UninstallContext{DataDir: fx.SproutRoot, Home: fx.UserHome}, ctx)
+}
@@ -1,9 +1,6 @@
 package cmd
 
 import (
-	"fmt"
-	"os"
-
 	"github.com/m44rten1/sprout/internal/core"
 	"github.com/m44rten1/sprout/internal/effects"
 
@@ -33,8 +30,7 @@ You can still create worktrees with --no-hooks or run 'sprout trust' to re-enabl
 		// Build context from effects (reuse BuildTrustContext)
 		ctx, err := BuildTrustContext(fx, pathArg)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 
 		// Plan and execute
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+
+	"github.com/spf13/cobra"
+)
+
+var upgradeConfigCmd = &cobra.Command{
+	Use:   "upgrade-config [file]",
+	Short: "Rewrite .sprout.yml in the newest config format",
+	Long: fmt.Sprintf(`Rewrite a .sprout.yml in the newest config format (version %d), keeping
+its comments. Older formats keep working, since sprout upgrades them when
+loading, but only upgraded files can use newer settings.
+
+Without an argument, the .sprout.yml sprout would load is upgraded: the
+current worktree's, or else the main worktree's. Use --dry-run to see what
+would be written.`, config.CurrentVersion),
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+
+		ctx, err := BuildConfigFileContext(fx, args)
+		if err != nil {
+			exitWithError(err)
+		}
+
+		plan := core.PlanUpgradeConfigCommand(ctx)
+		runPlan(plan, fx)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(upgradeConfigCmd)
+}
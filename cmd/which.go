@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/m44rten1/sprout/internal/build"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+
+	"github.com/spf13/cobra"
+)
+
+var whichCmd = &cobra.Command{
+	Use:   "which <branch>",
+	Short: "Print the path of a branch's worktree",
+	Long: `Print the path of the worktree that has a branch checked out, and
+nothing else, for scripts:
+
+  cd "$(sprout which feature)"
+
+The branch is found like 'sprout open' finds it, so a unique part of it is
+enough; the main worktree counts too. When no worktree has the branch,
+which prints an error on stderr and exits with 1. Use --repo to look in
+another repository.`,
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeSproutWorktrees(toComplete)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := WhichWorktree(effects.NewRealEffects(), args[0])
+		if err != nil {
+			exitWithError(err)
+		}
+		fmt.Println(path)
+	},
+}
+
+// WhichWorktree returns the absolute path of the worktree branch names:
+// the main worktree if it has branch checked out, otherwise the sprout
+// worktree 'sprout open' would open.
+func WhichWorktree(fx effects.Effects, branch string) (string, error) {
+	repoRoot, err := fx.GetRepoRoot()
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %w", err)
+	}
+
+	sproutRoot, err := fx.GetSproutRoot()
+	if err != nil {
+		return "", fmt.Errorf("failed to get sprout root: %w", err)
+	}
+
+	adopted, err := fx.ListAdoptedWorktrees()
+	if err != nil {
+		return "", fmt.Errorf("failed to load adopted worktrees: %w", err)
+	}
+
+	worktrees, err := fx.ListWorktrees(repoRoot)
+	if err != nil {
+		return "", fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	// An exact main branch comes before sprout branches it is a prefix of
+	var path string
+	if len(worktrees) > 0 && core.MatchWorktreeBranch(worktrees[:1], branch) == 0 {
+		path = worktrees[0].Path
+	} else if path, err = build.FindWorktreeByBranch(fx, worktrees, sproutRoot, branch, adopted); err != nil {
+		return "", err
+	}
+
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return path, nil
+}
+
+func init() {
+	rootCmd.AddCommand(whichCmd)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhichWorktree(t *testing.T) {
+	t.Parallel()
+
+	newFx := func() *effects.TestEffects {
+		fx := effects.NewTestEffects()
+		fx.RepoRoot = "/test/repo"
+		fx.SproutRoot = "/sprout"
+		fx.Worktrees = []git.Worktree{
+			{Path: "/test/repo", Branch: "main"},
+			{Path: "/sprout/repo-1234/main-fix/repo", Branch: "main-fix"},
+			{Path: "/sprout/repo-1234/feature/ABC-1234-login/repo", Branch: "feature/ABC-1234-login"},
+			{Path: "/elsewhere/other", Branch: "other"},
+		}
+		return fx
+	}
+
+	tests := []struct {
+		name   string
+		branch string
+		want   string
+	}{
+		{name: "sprout worktree", branch: "feature/ABC-1234-login", want: "/sprout/repo-1234/feature/ABC-1234-login/repo"},
+		{name: "abbreviated branch", branch: "ABC-1234", want: "/sprout/repo-1234/feature/ABC-1234-login/repo"},
+		{name: "main worktree before prefixes", branch: "main", want: "/test/repo"},
+		{name: "remote prefix", branch: "origin/main-fix", want: "/sprout/repo-1234/main-fix/repo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := WhichWorktree(newFx(), tt.branch)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("worktree outside sprout", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := WhichWorktree(newFx(), "other")
+		assert.EqualError(t, err, "no sprout-managed worktree found for branch 'other'")
+	})
+
+	t.Run("ambiguous without a terminal", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.SelectionError = fmt.Errorf("%w; pass a branch or path", effects.ErrNonInteractive)
+
+		_, err := WhichWorktree(fx, "i")
+		assert.ErrorContains(t, err, "matches 2 worktrees")
+	})
+}
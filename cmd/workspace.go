@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/m44rten1/sprout/internal/build"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	workspaceOutputFlag string
+	workspaceOpenFlag   bool
+)
+
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace [branch...]",
+	Short: "Generate a multi-root VS Code workspace of your worktrees",
+	Long: `Generate a .code-workspace file with the main worktree and sprout
+worktrees as folders, for comparing branches side by side in VS Code or
+Cursor. Pass branches to include only those; without any, every sprout
+worktree is included.
+
+The file is written next to the repository's worktrees unless --output is
+given. Regenerating it keeps any settings you added. Open it with --open,
+or later with 'sprout open --workspace'.`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeSproutWorktrees(toComplete)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+
+		ctx, err := BuildWorkspaceContext(fx, args, workspaceOutputFlag)
+		if err != nil {
+			exitWithError(err)
+		}
+		ctx.Open = workspaceOpenFlag
+
+		plan := core.PlanWorkspaceCommand(ctx)
+		runPlan(plan, fx)
+	},
+}
+
+// BuildWorkspaceContext gathers the folders for the workspace file: the main
+// worktree first, then the sprout worktrees of the given branches (or all).
+func BuildWorkspaceContext(fx effects.Effects, branches []string, output string) (core.WorkspaceContext, error) {
+	repoRoot, err := fx.GetRepoRoot()
+	if err != nil {
+		return core.WorkspaceContext{}, fmt.Errorf("not a git repository: %w", err)
+	}
+
+	mainWorktreePath, err := fx.GetMainWorktreePath()
+	if err != nil {
+		return core.WorkspaceContext{}, fmt.Errorf("failed to get main worktree: %w", err)
+	}
+
+	worktreeRoot, err := fx.GetWorktreeRoot(mainWorktreePath)
+	if err != nil {
+		return core.WorkspaceContext{}, fmt.Errorf("failed to get sprout root: %w", err)
+	}
+
+	worktrees, err := fx.ListWorktrees(repoRoot)
+	if err != nil {
+		return core.WorkspaceContext{}, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	adopted, err := fx.ListAdoptedWorktrees()
+	if err != nil {
+		return core.WorkspaceContext{}, fmt.Errorf("failed to load adopted worktrees: %w", err)
+	}
+
+	var selected []git.Worktree
+	if len(branches) == 0 {
+		selected = core.FilterSproutWorktrees(worktrees, worktreeRoot, adopted...)
+	} else {
+		for _, branch := range branches {
+			path, found := core.FindWorktreeByBranch(worktrees, worktreeRoot, branch, adopted...)
+			if !found {
+				return core.WorkspaceContext{}, fmt.Errorf("no sprout-managed worktree found for branch '%s'", branch)
+			}
+			selected = append(selected, git.Worktree{Path: path, Branch: branch})
+		}
+	}
+	if len(selected) == 0 {
+		return core.WorkspaceContext{}, fmt.Errorf(core.MsgNoSproutWorktrees)
+	}
+
+	folders := []core.WorkspaceFolder{workspaceFolder(build.MainBranch(worktrees, mainWorktreePath), mainWorktreePath)}
+	for _, wt := range selected {
+		folders = append(folders, workspaceFolder(wt.Branch, wt.Path))
+	}
+
+	path := output
+	if path == "" {
+		path = defaultWorkspacePath(worktreeRoot, mainWorktreePath)
+	}
+	existing, _ := fx.ReadFile(path) // A missing file is created from scratch
+
+	return core.WorkspaceContext{
+		WorkspacePath: path,
+		Folders:       folders,
+		Existing:      existing,
+	}, nil
+}
+
+// defaultWorkspacePath is where the workspace file lives unless --output is
+// given: beside the branch directories in the repository's worktree root.
+func defaultWorkspacePath(worktreeRoot, mainWorktreePath string) string {
+	return filepath.Join(worktreeRoot, filepath.Base(mainWorktreePath)+".code-workspace")
+}
+
+// workspaceFolder names a folder after its branch, or its directory for a
+// detached HEAD.
+func workspaceFolder(branch, path string) core.WorkspaceFolder {
+	if branch == "" {
+		branch = filepath.Base(path)
+	}
+	return core.WorkspaceFolder{Name: branch, Path: path}
+}
+
+func init() {
+	rootCmd.AddCommand(workspaceCmd)
+	workspaceCmd.Flags().StringVarP(&workspaceOutputFlag, "output", "o", "", "Write the workspace file here instead of next to the worktrees")
+	workspaceCmd.Flags().BoolVar(&workspaceOpenFlag, "open", false, "Open the workspace in the editor afterwards")
+}
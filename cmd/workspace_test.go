@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func workspaceTestFx() *effects.TestEffects {
+	fx := effects.NewTestEffects()
+	fx.RepoRoot = "/repo"
+	fx.MainWorktreePath = "/repo"
+	fx.WorktreeRoot = "/sprout/repo-1234"
+	fx.Worktrees = []git.Worktree{
+		{Path: "/repo", Branch: "main"},
+		{Path: "/sprout/repo-1234/feature", Branch: "feature"},
+		{Path: "/sprout/repo-1234/bugfix", Branch: "bugfix"},
+	}
+	return fx
+}
+
+func TestBuildWorkspaceContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("all sprout worktrees", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, err := BuildWorkspaceContext(workspaceTestFx(), nil, "")
+		require.NoError(t, err)
+		assert.Equal(t, "/sprout/repo-1234/repo.code-workspace", ctx.WorkspacePath)
+		assert.Equal(t, []core.WorkspaceFolder{
+			{Name: "main", Path: "/repo"},
+			{Name: "feature", Path: "/sprout/repo-1234/feature"},
+			{Name: "bugfix", Path: "/sprout/repo-1234/bugfix"},
+		}, ctx.Folders)
+	})
+
+	t.Run("selected branches", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, err := BuildWorkspaceContext(workspaceTestFx(), []string{"bugfix"}, "/tmp/review.code-workspace")
+		require.NoError(t, err)
+		assert.Equal(t, "/tmp/review.code-workspace", ctx.WorkspacePath)
+		assert.Equal(t, []core.WorkspaceFolder{
+			{Name: "main", Path: "/repo"},
+			{Name: "bugfix", Path: "/sprout/repo-1234/bugfix"},
+		}, ctx.Folders)
+	})
+
+	t.Run("unknown branch", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := BuildWorkspaceContext(workspaceTestFx(), []string{"nope"}, "")
+		assert.ErrorContains(t, err, "no sprout-managed worktree found for branch 'nope'")
+	})
+
+	t.Run("keeps the existing file's content", func(t *testing.T) {
+		t.Parallel()
+		fx := workspaceTestFx()
+		fx.FileContents["/sprout/repo-1234/repo.code-workspace"] = []byte(`{"settings": {}}`)
+
+		ctx, err := BuildWorkspaceContext(fx, nil, "")
+		require.NoError(t, err)
+		assert.Equal(t, `{"settings": {}}`, string(ctx.Existing))
+	})
+}
+
+func TestBuildOpenWorkspaceContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("opens the existing workspace", func(t *testing.T) {
+		t.Parallel()
+		fx := workspaceTestFx()
+		fx.Files["/sprout/repo-1234/repo.code-workspace"] = true
+
+		ctx, err := BuildOpenWorkspaceContext(fx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, core.WorkspaceContext{WorkspacePath: "/sprout/repo-1234/repo.code-workspace", Open: true}, ctx)
+	})
+
+	t.Run("generates a missing workspace", func(t *testing.T) {
+		t.Parallel()
+		fx := workspaceTestFx()
+
+		ctx, err := BuildOpenWorkspaceContext(fx, nil)
+		require.NoError(t, err)
+		assert.True(t, ctx.Open)
+		assert.Len(t, ctx.Folders, 3)
+
+		require.NoError(t, effects.ExecutePlan(core.PlanWorkspaceCommand(ctx), fx))
+		assert.Contains(t, string(fx.FileContents["/sprout/repo-1234/repo.code-workspace"]), "bugfix")
+		assert.Equal(t, []string{"/sprout/repo-1234/repo.code-workspace"}, fx.OpenedPaths)
+	})
+}
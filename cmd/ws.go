@@ -0,0 +1,331 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/m44rten1/sprout/internal/build"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/sprout"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	wsNoHooksFlag bool
+	wsTrustFlag   bool
+	wsForceFlag   bool
+)
+
+var wsCmd = &cobra.Command{
+	Use:   "ws",
+	Short: "Work on a feature across several repositories",
+	Long: `A workspace is a named set of repositories that work on one feature
+together, such as an api, a web app and their infrastructure. Each of them
+gets a worktree of the branch named after the workspace, and the ws
+commands act on all of them at once:
+
+  sprout ws create feature-x api web infra
+  sprout ws open feature-x
+  sprout ws list
+  sprout ws remove feature-x
+
+Repositories are named as --repo takes them: a path, or a name that
+'sprout repos' prints. ws create with the name of an existing workspace
+adds the repositories given to it, and creates the worktrees it is
+missing, such as after a repository failed to create its worktree.
+
+Not to be confused with 'sprout workspace', which writes an editor
+workspace of one repository's worktrees.`,
+}
+
+var wsCreateCmd = &cobra.Command{
+	Use:   "create <name> [repo...]",
+	Short: "Create a worktree of the same branch in each repository",
+	Args:  cobra.MinimumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return completeWorkspaceNames(cmd, args, toComplete)
+		}
+		return completeRepoNames(cmd, args, toComplete)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+		ctx, err := BuildWsCreateContext(fx, realEffectsIn, args[0], args[1:], build.AddOptions{
+			NoHooks: wsNoHooksFlag,
+			NoOpen:  true,
+			Trust:   wsTrustFlag,
+		})
+		if err != nil {
+			exitWithError(err)
+		}
+		defer lockRepos(fx, ctx.Workspace)()
+		runPlan(core.PlanWsCreateCommand(ctx), fx)
+	},
+}
+
+var wsOpenCmd = &cobra.Command{
+	Use:               "open <name>",
+	Short:             "Open the worktree of each repository in the workspace",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorkspaceNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+		ctx, err := BuildWsOpenContext(fx, args[0], OpenOptions{NoHooks: wsNoHooksFlag})
+		if err != nil {
+			exitWithError(err)
+		}
+		runPlan(core.PlanWsOpenCommand(ctx), fx)
+	},
+}
+
+var wsRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove the workspace and its worktrees",
+	Long: `Remove the worktree of each repository in the workspace, and then the
+workspace. When any of the worktrees has uncommitted changes, nothing is
+removed unless --force is given. Branches are kept.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorkspaceNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+		ctx, err := BuildWsRemoveContext(fx, realEffectsIn, args[0], wsForceFlag)
+		if err != nil {
+			exitWithError(err)
+		}
+		defer lockRepos(fx, ctx.Workspace)()
+		runPlan(core.PlanWsRemoveCommand(ctx), fx)
+	},
+}
+
+var wsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the workspaces and the worktrees of their repositories",
+	Long: `List the workspaces and the worktree each of their repositories has.
+'sprout list --workspace' lists them with the status of each worktree.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fx := effects.NewRealEffects()
+		ctx, err := BuildWsListContext(fx)
+		if err != nil {
+			exitWithError(err)
+		}
+		runPlan(core.PlanWsListCommand(ctx), fx)
+	},
+}
+
+// realEffectsIn returns the effects for running in the repository at dir,
+// as build.AddContext and build.RemoveContext would from there.
+func realEffectsIn(dir string) effects.Effects {
+	return effects.NewRealEffectsIn(dir)
+}
+
+// BuildWsCreateContext resolves the repositories of the workspace called
+// name, those it has and repoArgs, and builds the add context of each that
+// has no worktree of its branch yet. fxIn gives the effects of running in a
+// repository.
+func BuildWsCreateContext(fx effects.Effects, fxIn func(dir string) effects.Effects, name string, repoArgs []string, opts build.AddOptions) (core.WsCreateContext, error) {
+	existing, _, err := findWorkspace(fx, name)
+	if err != nil {
+		return core.WsCreateContext{}, err
+	}
+	repos := slices.Clone(existing.Repos)
+	for _, arg := range repoArgs {
+		dir, err := ResolveRepoFlag(fx, arg)
+		if err != nil {
+			return core.WsCreateContext{}, err
+		}
+		mainPath, err := fxIn(dir).GetMainWorktreePath()
+		if err != nil {
+			return core.WsCreateContext{}, fmt.Errorf("%s is not in a git repository: %w", arg, err)
+		}
+		if !slices.Contains(repos, mainPath) {
+			repos = append(repos, mainPath)
+		}
+	}
+
+	ctx := core.WsCreateContext{Workspace: workspaceMembers(fx, name, repos)}
+	for _, m := range ctx.Workspace.Members {
+		if m.WorktreePath != "" {
+			continue
+		}
+		add, err := build.AddContext(fxIn(m.MainPath), []string{name}, opts)
+		if err != nil {
+			return core.WsCreateContext{}, fmt.Errorf("%s: %w", m.Repo, err)
+		}
+		ctx.Adds = append(ctx.Adds, add)
+	}
+	return ctx, nil
+}
+
+// BuildWsOpenContext builds the open context of each worktree of the
+// workspace called name.
+func BuildWsOpenContext(fx effects.Effects, name string, opts OpenOptions) (core.WsOpenContext, error) {
+	ws, err := loadWorkspace(fx, name)
+	if err != nil {
+		return core.WsOpenContext{}, err
+	}
+	ctx := core.WsOpenContext{Workspace: ws}
+	for _, m := range ws.Members {
+		if m.WorktreePath == "" {
+			continue
+		}
+		open, err := buildOpenContextFor(fx, m.WorktreePath, m.WorktreePath, m.MainPath, opts)
+		if err != nil {
+			return core.WsOpenContext{}, fmt.Errorf("%s: %w", m.Repo, err)
+		}
+		ctx.Opens = append(ctx.Opens, open)
+	}
+	return ctx, nil
+}
+
+// BuildWsRemoveContext builds the remove context of each worktree of the
+// workspace called name, and finds the ones with uncommitted changes.
+func BuildWsRemoveContext(fx effects.Effects, fxIn func(dir string) effects.Effects, name string, force bool) (core.WsRemoveContext, error) {
+	ws, err := loadWorkspace(fx, name)
+	if err != nil {
+		return core.WsRemoveContext{}, err
+	}
+	ctx := core.WsRemoveContext{Workspace: ws, Force: force}
+	for _, m := range ws.Members {
+		if m.WorktreePath == "" {
+			continue
+		}
+		remove, err := build.RemoveContext(fxIn(m.MainPath), []string{m.WorktreePath}, force)
+		if err != nil {
+			return core.WsRemoveContext{}, fmt.Errorf("%s: %w", m.Repo, err)
+		}
+		ctx.Removes = append(ctx.Removes, remove)
+		if !force && fx.GetWorktreeStatus(m.WorktreePath).Dirty {
+			ctx.Dirty = append(ctx.Dirty, m.Repo)
+		}
+	}
+	return ctx, nil
+}
+
+// BuildWsListContext gathers every workspace with its worktrees.
+func BuildWsListContext(fx effects.Effects) (core.WsListContext, error) {
+	workspaces, err := fx.LoadWorkspaces()
+	if err != nil {
+		return core.WsListContext{}, err
+	}
+	home, _ := fx.UserHomeDir()
+	ctx := core.WsListContext{Home: home}
+	for _, ws := range workspaces {
+		ctx.Workspaces = append(ctx.Workspaces, workspaceMembers(fx, ws.Name, ws.Repos))
+	}
+	return ctx, nil
+}
+
+// findWorkspace returns the recorded workspace called name; ok is false if
+// there is none.
+func findWorkspace(fx effects.Effects, name string) (ws sprout.Workspace, ok bool, err error) {
+	workspaces, err := fx.LoadWorkspaces()
+	if err != nil {
+		return sprout.Workspace{}, false, err
+	}
+	for _, ws := range workspaces {
+		if ws.Name == name {
+			return ws, true, nil
+		}
+	}
+	return sprout.Workspace{Name: name}, false, nil
+}
+
+// loadWorkspace returns the workspace called name with its worktrees, or
+// an error if there is no such workspace.
+func loadWorkspace(fx effects.Effects, name string) (core.RepoWorkspace, error) {
+	ws, ok, err := findWorkspace(fx, name)
+	if err != nil {
+		return core.RepoWorkspace{}, err
+	}
+	if !ok {
+		return core.RepoWorkspace{}, fmt.Errorf("no workspace named %s; 'sprout ws list' shows them", name)
+	}
+	return workspaceMembers(fx, ws.Name, ws.Repos), nil
+}
+
+// workspaceMembers finds the worktree of branch name in each repository of
+// repos (main worktree paths). A repository that is gone, or that has the
+// branch only in its main worktree, has no worktree of the workspace.
+func workspaceMembers(fx effects.Effects, name string, repos []string) core.RepoWorkspace {
+	displays := make([]core.RepoDisplay, len(repos))
+	for i, repo := range repos {
+		displays[i] = core.RepoDisplay{MainPath: repo}
+	}
+	names := core.RepoNames(displays)
+
+	ws := core.RepoWorkspace{Name: name}
+	for i, repo := range repos {
+		m := core.WsMember{Repo: names[i], MainPath: repo}
+		worktrees, _ := fx.ListWorktrees(repo)
+		for _, wt := range worktrees {
+			if wt.Branch == name && filepath.Clean(wt.Path) != filepath.Clean(repo) {
+				m.WorktreePath = wt.Path
+				break
+			}
+		}
+		ws.Members = append(ws.Members, m)
+	}
+	return ws
+}
+
+// lockRepos takes the locks of the workspace's repositories, as lockRepo
+// does for one, in the same order every time so two commands can't each
+// hold a lock the other waits for.
+func lockRepos(fx effects.Effects, ws core.RepoWorkspace) (unlock func()) {
+	if dryRunFlag {
+		return func() {}
+	}
+	paths := make([]string, len(ws.Members))
+	for i, m := range ws.Members {
+		paths[i] = m.MainPath
+	}
+	slices.Sort(paths)
+
+	var unlocks []func()
+	unlockAll := func() {
+		for _, unlock := range slices.Backward(unlocks) {
+			unlock()
+		}
+	}
+	for _, path := range paths {
+		unlock, err := fx.LockRepo(path, repoLockTimeout)
+		if err != nil {
+			unlockAll()
+			exitWithError(err)
+		}
+		unlocks = append(unlocks, unlock)
+	}
+	return unlockAll
+}
+
+// completeWorkspaceNames completes the names of the recorded workspaces.
+func completeWorkspaceNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	workspaces, err := effects.NewRealEffects().LoadWorkspaces()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, ws := range workspaces {
+		if strings.HasPrefix(ws.Name, toComplete) {
+			names = append(names, ws.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	wsCreateCmd.Flags().BoolVar(&wsNoHooksFlag, "no-hooks", false, "Skip on_create hooks")
+	wsCreateCmd.Flags().BoolVar(&wsTrustFlag, "trust", false, "Trust the repositories without prompting if hooks would run")
+	wsOpenCmd.Flags().BoolVar(&wsNoHooksFlag, "no-hooks", false, "Skip on_open hooks")
+	wsRemoveCmd.Flags().BoolVar(&wsForceFlag, "force", false, "Remove the worktrees even with uncommitted changes")
+	wsCmd.AddCommand(wsCreateCmd, wsOpenCmd, wsRemoveCmd, wsListCmd)
+	rootCmd.AddCommand(wsCmd)
+}
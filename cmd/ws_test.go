@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/build"
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// repoTestFx returns the effects of running in the repository at mainPath.
+func repoTestFx(mainPath string) *effects.TestEffects {
+	fx := effects.NewTestEffects()
+	fx.RepoRoot = mainPath
+	fx.MainWorktreePath = mainPath
+	fx.WorktreeRoot = "/sprout" + mainPath
+	fx.Config = &config.Config{}
+	return fx
+}
+
+func TestBuildWsCreateContext(t *testing.T) {
+	t.Parallel()
+
+	repos := map[string]*effects.TestEffects{
+		"/code/api": repoTestFx("/code/api"),
+		"/code/web": repoTestFx("/code/web"),
+	}
+	fxIn := func(dir string) effects.Effects { return repos[dir] }
+
+	fx := effects.NewTestEffects()
+	fx.Files["/code/api"] = true
+	fx.Files["/code/web"] = true
+	fx.Workspaces["feature-x"] = []string{"/code/api"}
+
+	ctx, err := BuildWsCreateContext(fx, fxIn, "feature-x", []string{"/code/web", "/code/api"}, build.AddOptions{NoOpen: true})
+	require.NoError(t, err)
+	assert.Equal(t, "feature-x", ctx.Workspace.Name)
+	require.Len(t, ctx.Workspace.Members, 2)
+	assert.Equal(t, "api", ctx.Workspace.Members[0].Repo)
+	assert.Equal(t, "/code/web", ctx.Workspace.Members[1].MainPath)
+
+	require.Len(t, ctx.Adds, 2)
+	assert.Equal(t, "/code/api", ctx.Adds[0].RepoRoot)
+	assert.Equal(t, "/code/web", ctx.Adds[1].RepoRoot)
+	assert.Equal(t, "feature-x", ctx.Adds[1].Branch)
+}
+
+func TestBuildWsRemoveContext(t *testing.T) {
+	t.Parallel()
+
+	const worktree = "/sprout/code/api/feature-x/api"
+	api := repoTestFx("/code/api")
+	api.Files[worktree] = true
+	api.Worktrees = []git.Worktree{{Path: "/code/api", Branch: "main"}, {Path: worktree, Branch: "feature-x"}}
+	fxIn := func(dir string) effects.Effects { return api }
+
+	fx := effects.NewTestEffects()
+	fx.Workspaces["feature-x"] = []string{"/code/api"}
+	fx.Worktrees = api.Worktrees
+	fx.WorktreeStatuses[worktree] = git.WorktreeStatus{Dirty: true}
+
+	t.Run("uncommitted changes", func(t *testing.T) {
+		ctx, err := BuildWsRemoveContext(fx, fxIn, "feature-x", false)
+		require.NoError(t, err)
+		require.Len(t, ctx.Removes, 1)
+		assert.Equal(t, worktree, ctx.Removes[0].TargetPath)
+		assert.Equal(t, []string{"api"}, ctx.Dirty)
+	})
+
+	t.Run("unknown workspace", func(t *testing.T) {
+		_, err := BuildWsRemoveContext(fx, fxIn, "nope", false)
+		assert.EqualError(t, err, "no workspace named nope; 'sprout ws list' shows them")
+	})
+}
+
+func TestBuildWorkspaceListContext(t *testing.T) {
+	t.Parallel()
+
+	const worktree = "/sprout/code/api/feature-x/api"
+	fx := effects.NewTestEffects()
+	fx.Workspaces["feature-x"] = []string{"/code/api"}
+	fx.Worktrees = []git.Worktree{{Path: "/code/api", Branch: "main"}, {Path: worktree, Branch: "feature-x"}}
+	fx.WorktreeStatuses[worktree] = git.WorktreeStatus{Ahead: 2}
+
+	ctx, err := BuildWorkspaceListContext(fx)
+	require.NoError(t, err)
+	assert.True(t, ctx.ByWorkspace)
+	require.Len(t, ctx.Workspaces, 1)
+	assert.Equal(t, []core.WsMember{{
+		Repo:         "api",
+		MainPath:     "/code/api",
+		WorktreePath: worktree,
+		Status:       git.WorktreeStatus{Ahead: 2},
+	}}, ctx.Workspaces[0].Members)
+}
@@ -0,0 +1,604 @@
+package build
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/tickets"
+	"github.com/m44rten1/sprout/internal/trust"
+)
+
+// AddOptions holds the command-line flags that influence the add command.
+type AddOptions struct {
+	NoHooks bool   // Skip on_create hooks
+	NoOpen  bool   // Skip opening the editor
+	Open    bool   // Open the editor even if open_editor is false
+	Trust   bool   // Trust the repository without prompting if hooks would run
+	Ticket  string // Build the branch name from branch_template; the argument is the description
+	// Clone the configured build artifacts from the main worktree
+	CloneArtifacts bool
+	// Remove clean, merged worktrees when over max_worktrees
+	Evict bool
+	// Apply a stash entry or patch file to the new worktree
+	FromStash  string
+	ApplyPatch string
+	// Make the worktree temporary: prune removes it once this has passed
+	TTL string
+	// Start a new branch at this tag; the branch is named by Branch or the
+	// argument
+	Tag    string
+	Branch string
+	// Check out this ref on a detached HEAD; the argument is the label
+	Detach string
+	// Check out a branch even if another worktree has it checked out
+	ForceCreate bool
+	// Lock the new worktree so git doesn't prune it, with LockReason if
+	// not empty
+	Lock       bool
+	LockReason string
+}
+
+// AddContext gathers all inputs needed to plan the add command.
+// It handles interactive branch selection if no branch is provided.
+func AddContext(fx effects.Effects, args []string, opts AddOptions) (core.AddContext, error) {
+	// Get repo root
+	repoRoot, err := fx.GetRepoRoot()
+	if err != nil {
+		return core.AddContext{}, fmt.Errorf("not a git repository: %w", err)
+	}
+
+	// Get main worktree path for config loading and hooks
+	mainWorktreePath, err := fx.GetMainWorktreePath()
+	if err != nil {
+		return core.AddContext{}, fmt.Errorf("failed to get main worktree: %w", err)
+	}
+
+	// Load config
+	cfg, err := fx.LoadConfig(repoRoot, mainWorktreePath)
+	if err != nil {
+		return core.AddContext{}, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	noOpen, err := SkipEditor(fx, cfg, opts.Open, opts.NoOpen)
+	if err != nil {
+		return core.AddContext{}, err
+	}
+
+	var expiresAt time.Time
+	if opts.TTL != "" {
+		ttl, err := config.ParseDuration(opts.TTL)
+		if err != nil || ttl <= 0 {
+			return core.AddContext{}, fmt.Errorf("invalid --ttl %q: use a duration like 90m, 2h or 3d", opts.TTL)
+		}
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	// --lock takes the next argument as its reason, even another flag
+	if opts.Lock && strings.HasPrefix(opts.LockReason, "-") {
+		return core.AddContext{}, fmt.Errorf("--lock takes a reason, not %s; pass --lock \"\" to lock without one", opts.LockReason)
+	}
+
+	if opts.Branch != "" {
+		if len(args) > 0 {
+			return core.AddContext{}, errors.New("name the branch either with --branch or as the argument, not both")
+		}
+		args = []string{opts.Branch}
+	}
+	tag, err := tagToStartFrom(fx, repoRoot, opts.Tag, args)
+	if err != nil {
+		return core.AddContext{}, err
+	}
+	detach, label, err := commitToDetach(fx, repoRoot, opts.Detach, args)
+	if err != nil {
+		return core.AddContext{}, err
+	}
+
+	// Determine branch name (the label of a detached worktree, from a
+	// ticket, interactive or from args)
+	var branch string
+	var ticket tickets.Ticket
+	if detach != "" {
+		branch = label
+	} else if opts.Ticket != "" {
+		var title string
+		if len(args) > 0 {
+			title = args[0]
+		}
+		if cfg.BranchTemplate == "" {
+			return core.AddContext{}, core.ErrNoBranchTemplate
+		}
+		ticket, err = lookupTicket(fx, cfg.TicketProvider, opts.Ticket, title)
+		if err != nil {
+			return core.AddContext{}, err
+		}
+		branch, err = branchFromTicket(fx, repoRoot, cfg.BranchTemplate, ticket, title)
+		if err != nil {
+			return core.AddContext{}, err
+		}
+	} else if len(args) == 0 {
+		// Interactive mode: select from existing branches
+		branches, err := fx.ListBranches(repoRoot)
+		if err != nil {
+			return core.AddContext{}, fmt.Errorf("failed to list branches: %w", err)
+		}
+
+		worktrees, err := fx.ListWorktrees(repoRoot)
+		if err != nil {
+			return core.AddContext{}, fmt.Errorf("failed to list worktrees: %w", err)
+		}
+
+		// Branches checked out elsewhere are listed too, to show where
+		// they are
+		choices := core.BranchChoices(branches, worktrees)
+		if !slices.ContainsFunc(choices, core.BranchChoice.Available) {
+			if len(choices) > 0 {
+				return core.AddContext{}, errors.New("no available branches found; each one is checked out in a worktree already (see 'git worktree list')")
+			}
+			return core.AddContext{}, fmt.Errorf("no available branches found")
+		}
+
+		idx, err := fx.SelectBranch(choices)
+		if err != nil {
+			return core.AddContext{}, fmt.Errorf("branch selection cancelled: %w", err)
+		}
+
+		branch = choices[idx].Branch.DisplayName
+	} else {
+		branch = args[0]
+	}
+
+	// Local-only repositories have no remote branches to check out or start
+	// new branches from, so those checks are skipped: new branches start
+	// from HEAD
+	hasRemote, err := fx.HasRemote(repoRoot)
+	if err != nil {
+		return core.AddContext{}, fmt.Errorf("failed to list remotes: %w", err)
+	}
+
+	// Strip remote prefix if user provided it (e.g., "origin/feature" -> "feature"),
+	// and take an existing branch typed in another case by its own name
+	if hasRemote && detach == "" {
+		branch = core.NormalizeBranchArg(branch)
+	}
+	if detach == "" && tag == "" && opts.Ticket == "" && len(args) > 0 {
+		branch = existingBranchName(fx, repoRoot, branch)
+	}
+
+	// Calculate worktree path
+	worktreePath, err := fx.GetWorktreePath(mainWorktreePath, branch)
+	if err != nil {
+		return core.AddContext{}, fmt.Errorf("error calculating worktree path: %w", err)
+	}
+
+	// Check if worktree already exists
+	worktreeExists, err := WorktreeDirExists(fx, worktreePath)
+	if err != nil {
+		return core.AddContext{}, err
+	}
+
+	// Check branch existence
+	localBranchExists, err := fx.LocalBranchExists(repoRoot, branch)
+	if err != nil {
+		return core.AddContext{}, fmt.Errorf("failed to check local branch: %w", err)
+	}
+	// Commands find a detached worktree by its label only when no branch
+	// of that name is checked out instead. Any commit counts as a local
+	// branch above, short ones like the default label too, so the branch
+	// is looked up by its ref
+	if detach != "" {
+		if ref, _ := fx.RunGitCommand(repoRoot, "for-each-ref", "--format=%(refname)", "refs/heads/"+branch); ref != "" {
+			return core.AddContext{}, fmt.Errorf("there is a branch %s; give the detached worktree another label", branch)
+		}
+		localBranchExists = false
+	}
+
+	var remoteBranchExists bool
+	var remoteBase string
+	if hasRemote && detach == "" {
+		remoteBranchExists, err = fx.RemoteBranchExists(repoRoot, branch)
+		if err != nil {
+			return core.AddContext{}, fmt.Errorf("failed to check remote branch: %w", err)
+		}
+
+		// New branches start from the remote default branch (default_branch,
+		// else the one git reports) when it exists.
+		// Note: RemoteBranchExists automatically prepends "origin/" prefix
+		defaultBranch := cfg.DefaultBranch
+		if defaultBranch == "" {
+			if defaultBranch, err = fx.GetDefaultBranch(mainWorktreePath); err != nil {
+				return core.AddContext{}, fmt.Errorf("failed to get default branch: %w", err)
+			}
+		}
+		hasRemoteBase, err := fx.RemoteBranchExists(repoRoot, defaultBranch)
+		if err != nil {
+			return core.AddContext{}, fmt.Errorf("failed to check origin/%s: %w", defaultBranch, err)
+		}
+		if hasRemoteBase {
+			remoteBase = "origin/" + defaultBranch
+		}
+	}
+
+	// git checks out a branch in one worktree at a time
+	var checkedOutAt string
+	if localBranchExists && !worktreeExists && detach == "" {
+		checkedOutAt, err = worktreeWithBranch(fx, repoRoot, branch, worktreePath)
+		if err != nil {
+			return core.AddContext{}, err
+		}
+	}
+
+	// A new branch without a remote base starts from the current branch,
+	// which is recorded as its base
+	var headBranch string
+	if tag == "" && detach == "" && !worktreeExists && !localBranchExists && !remoteBranchExists && remoteBase == "" {
+		if head, err := fx.RunGitCommand(repoRoot, "rev-parse", "--abbrev-ref", "HEAD"); err == nil && head != "HEAD" {
+			headBranch = head
+		}
+	}
+
+	userCfg, err := fx.LoadUserConfig()
+	if err != nil {
+		return core.AddContext{}, fmt.Errorf("failed to load user config: %w", err)
+	}
+	defaultHooks := config.DefaultHooks(cfg, userCfg)
+
+	// Check trust status (only matters if hooks will run)
+	// A policy denial is not an error: hooks are skipped and the planner says why
+	isTrusted := false
+	hooksDenied := false
+	if (cfg.HasCreateHooks() || len(defaultHooks) > 0) && !opts.NoHooks {
+		// The new worktree's own .sprout.yml is checked again when its hooks run
+		isTrusted, err = fx.IsTrusted(mainWorktreePath, mainWorktreePath)
+		if errors.Is(err, trust.ErrDeniedByPolicy) {
+			hooksDenied = true
+		} else if err != nil {
+			return core.AddContext{}, fmt.Errorf("failed to check trust status: %w", err)
+		}
+	}
+
+	// Template files are only needed when the worktree is created
+	var templateDir string
+	var templateFiles []string
+	if cfg.TemplateDir != "" && !worktreeExists {
+		templateDir, templateFiles, err = collectTemplateFiles(fx, cfg.TemplateDir, mainWorktreePath)
+		if err != nil {
+			return core.AddContext{}, err
+		}
+	}
+
+	var artifacts []string
+	if opts.CloneArtifacts && !worktreeExists {
+		artifacts, err = artifactsToClone(fx, cfg.Artifacts, mainWorktreePath, worktreePath)
+		if err != nil {
+			return core.AddContext{}, err
+		}
+	}
+
+	fromStash, patchFile, err := changesToApply(fx, repoRoot, opts)
+	if err != nil {
+		return core.AddContext{}, err
+	}
+
+	var existing []core.WorktreeUsage
+	if cfg.MaxWorktrees > 0 && !worktreeExists {
+		existing, err = WorktreeUsage(fx, repoRoot, mainWorktreePath, cfg.MaxWorktrees)
+		if err != nil {
+			return core.AddContext{}, err
+		}
+	}
+
+	return core.AddContext{
+		Branch:             branch,
+		RepoRoot:           repoRoot,
+		MainWorktreePath:   mainWorktreePath,
+		WorktreePath:       worktreePath,
+		WorktreeExists:     worktreeExists,
+		LocalBranchExists:  localBranchExists,
+		RemoteBranchExists: remoteBranchExists,
+		RemoteBase:         remoteBase,
+		HeadBranch:         headBranch,
+		Config:             cfg,
+		DefaultHooks:       defaultHooks,
+		IsTrusted:          isTrusted,
+		NoHooks:            opts.NoHooks,
+		NoOpen:             noOpen,
+		Trust:              opts.Trust,
+		HooksDenied:        hooksDenied,
+		Ticket:             ticket,
+		TemplateDir:        templateDir,
+		TemplateFiles:      templateFiles,
+		Artifacts:          artifacts,
+		ExistingWorktrees:  existing,
+		Evict:              opts.Evict,
+		FromStash:          fromStash,
+		PatchFile:          patchFile,
+		ExpiresAt:          expiresAt,
+		Tag:                tag,
+		Detach:             detach,
+		CheckedOutAt:       checkedOutAt,
+		Force:              opts.ForceCreate,
+		Lock:               opts.Lock,
+		LockReason:         opts.LockReason,
+	}, nil
+}
+
+// WorktreeDirExists reports whether there is a directory at path, where a
+// worktree goes. A file there, or a path that can't be checked, is an
+// error: sprout would take it for a worktree, or try to create one there.
+func WorktreeDirExists(fx effects.Effects, path string) (bool, error) {
+	exists, isDir, err := fx.Stat(path)
+	switch {
+	case err != nil:
+		return false, fmt.Errorf("can't check worktree path: %w", err)
+	case exists && !isDir:
+		return false, fmt.Errorf("%s is a file, where the worktree should go; move it away", path)
+	}
+	return exists, nil
+}
+
+// worktreeWithBranch returns the path of the worktree, other than the one
+// at worktreePath, that has branch checked out, or "" if none has.
+func worktreeWithBranch(fx effects.Effects, repoRoot, branch, worktreePath string) (string, error) {
+	worktrees, err := fx.ListWorktrees(repoRoot)
+	if err != nil {
+		return "", fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	for _, wt := range worktrees {
+		if wt.Branch == branch && filepath.Clean(wt.Path) != filepath.Clean(worktreePath) {
+			return wt.Path, nil
+		}
+	}
+	return "", nil
+}
+
+// tagToStartFrom checks the tag given with --tag, which a new branch named
+// by args starts at. A "refs/tags/" prefix is accepted.
+func tagToStartFrom(fx effects.Effects, repoRoot, tag string, args []string) (string, error) {
+	if tag == "" {
+		return "", nil
+	}
+	if len(args) == 0 {
+		return "", errors.New("--tag needs the name of the new branch, with --branch or as the argument")
+	}
+	tag = strings.TrimPrefix(tag, "refs/tags/")
+	if _, err := fx.RunGitCommand(repoRoot, "rev-parse", "--verify", "--quiet", "refs/tags/"+tag+"^{commit}"); err != nil {
+		return "", fmt.Errorf("no tag %s (see 'git tag --list')", tag)
+	}
+	return tag, nil
+}
+
+// existingBranchName returns the name of the local or remote branch that
+// branch refers to (see core.MatchBranchName), or branch itself for a new
+// one. Without a branch list, branch is taken as typed.
+func existingBranchName(fx effects.Effects, repoRoot, branch string) string {
+	branches, err := fx.ListBranches(repoRoot)
+	if err != nil {
+		return branch
+	}
+	names := make([]string, len(branches))
+	for i, b := range branches {
+		names[i] = b.Name
+	}
+	if i := core.MatchBranchName(names, branch); i >= 0 {
+		return names[i]
+	}
+	return branch
+}
+
+// commitToDetach resolves the ref given with --detach to the commit the
+// worktree checks out, and returns the label that names it: the argument,
+// or the short commit without one.
+func commitToDetach(fx effects.Effects, repoRoot, ref string, args []string) (commit, label string, err error) {
+	if ref == "" {
+		return "", "", nil
+	}
+	commit, err = fx.RunGitCommand(repoRoot, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil || commit == "" {
+		return "", "", fmt.Errorf("no commit %s to detach at", ref)
+	}
+	if len(args) > 0 {
+		label = args[0]
+	} else if label, err = fx.RunGitCommand(repoRoot, "rev-parse", "--short", commit); err != nil {
+		return "", "", fmt.Errorf("abbreviate %s: %w", commit, err)
+	}
+	if strings.ContainsRune(label, '/') {
+		return "", "", fmt.Errorf("invalid label %q: it names a single directory, so it can't contain '/'", label)
+	}
+	return commit, label, nil
+}
+
+// changesToApply checks the stash entry and patch file of --from-stash and
+// --apply-patch before anything is created. The patch is applied from the
+// new worktree, so its path is made absolute.
+func changesToApply(fx effects.Effects, repoRoot string, opts AddOptions) (stash, patch string, err error) {
+	if opts.FromStash != "" {
+		stash = core.StashRef(opts.FromStash)
+		if _, err := fx.RunGitCommand(repoRoot, "rev-parse", "--verify", "--quiet", stash+"^{commit}"); err != nil {
+			return "", "", fmt.Errorf("no stash entry %s (see 'git stash list')", stash)
+		}
+	}
+	if opts.ApplyPatch != "" {
+		exists, isDir, err := fx.Stat(opts.ApplyPatch)
+		switch {
+		case err != nil:
+			return "", "", fmt.Errorf("can't read patch file: %w", err)
+		case !exists:
+			return "", "", fmt.Errorf("patch file not found: %s", opts.ApplyPatch)
+		case isDir:
+			return "", "", fmt.Errorf("patch file %s is a directory", opts.ApplyPatch)
+		}
+		if patch, err = filepath.Abs(opts.ApplyPatch); err != nil {
+			return "", "", err
+		}
+	}
+	return stash, patch, nil
+}
+
+// SkipEditor reports whether to leave the editor closed: always in a CI
+// pipeline, else --open or --no-open if given, else open_editor from the
+// repository or user config.
+func SkipEditor(fx effects.Effects, cfg *config.Config, open, noOpen bool) (bool, error) {
+	if ciMode() {
+		return true, nil
+	}
+	if open || noOpen {
+		return noOpen, nil
+	}
+	userCfg, err := fx.LoadUserConfig()
+	if err != nil {
+		return false, fmt.Errorf("failed to load user config: %w", err)
+	}
+	return !config.OpensEditor(cfg, userCfg), nil
+}
+
+// WorktreeUsage returns the repository's sprout worktrees with their
+// status and last use, but only when another one would exceed limit: the
+// statuses take several git commands per worktree.
+func WorktreeUsage(fx effects.Effects, repoRoot, mainWorktreePath string, limit int) ([]core.WorktreeUsage, error) {
+	worktreeRoot, err := fx.GetWorktreeRoot(mainWorktreePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sprout root: %w", err)
+	}
+	worktrees, err := fx.ListWorktrees(repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	adopted, err := fx.ListAdoptedWorktrees()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load adopted worktrees: %w", err)
+	}
+
+	managed := core.FilterSproutWorktrees(worktrees, worktreeRoot, adopted...)
+	if len(managed) < limit {
+		return nil, nil
+	}
+
+	metadata, _ := fx.LoadWorktreeMetadata() // Without it nothing counts as pinned
+	usages := make([]core.WorktreeUsage, 0, len(managed))
+	for _, wt := range managed {
+		status := fx.GetWorktreeStatus(wt.Path)
+		lastUsed, _ := fx.WorktreeLastUsed(wt.Path) // Unknown sorts first, like an unused worktree
+		usages = append(usages, core.WorktreeUsage{
+			Path:     wt.Path,
+			Branch:   wt.Branch,
+			LastUsed: lastUsed,
+			Dirty:    status.Dirty,
+			Unmerged: status.Unmerged,
+			Adopted:  core.IsAdopted(wt.Path, adopted),
+			Pinned:   metadata[wt.Path].Pinned,
+			Locked:   wt.Locked,
+			Current:  wt.Path == repoRoot,
+		})
+	}
+	return usages, nil
+}
+
+// artifactsToClone returns the configured artifact directories for
+// --clone-artifacts, or none (with a warning) when the worktree's
+// filesystem can't clone them from the main worktree.
+func artifactsToClone(fx effects.Effects, artifacts []string, mainWorktreePath, worktreePath string) ([]string, error) {
+	if len(artifacts) == 0 {
+		return nil, fmt.Errorf("--clone-artifacts needs build directories listed under artifacts in .sprout.yml")
+	}
+	if !fx.ReflinkSupported(mainWorktreePath, filepath.Dir(worktreePath)) {
+		fx.PrintErr("Warning: copy-on-write clones are not supported between the main worktree and the new one; skipping --clone-artifacts")
+		return nil, nil
+	}
+	return artifacts, nil
+}
+
+// collectTemplateFiles resolves template_dir against the main worktree
+// (config validation keeps it inside) and lists the files below it,
+// relative to the directory.
+func collectTemplateFiles(fx effects.Effects, configured, mainWorktreePath string) (string, []string, error) {
+	dir := filepath.Join(mainWorktreePath, configured)
+	exists, isDir, err := fx.Stat(dir)
+	switch {
+	case err != nil:
+		return "", nil, fmt.Errorf("can't read template_dir: %w", err)
+	case !exists:
+		return "", nil, fmt.Errorf("template_dir not found: %s", dir)
+	case !isDir:
+		return "", nil, fmt.Errorf("template_dir %s is not a directory", dir)
+	}
+
+	var files []string
+	var walk func(rel string) error
+	walk = func(rel string) error {
+		entries, err := fx.ReadDir(filepath.Join(dir, rel))
+		if err != nil {
+			return fmt.Errorf("failed to read template_dir: %w", err)
+		}
+		for _, entry := range entries {
+			path := filepath.Join(rel, entry.Name())
+			if entry.IsDir() {
+				if err := walk(path); err != nil {
+					return err
+				}
+				continue
+			}
+			if entry.Type().IsRegular() {
+				files = append(files, path)
+			}
+		}
+		return nil
+	}
+	if err := walk(""); err != nil {
+		return "", nil, err
+	}
+
+	sort.Strings(files)
+	return dir, files, nil
+}
+
+// lookupTicket returns the ticket to record for --ticket. With a provider
+// configured its title is fetched; a description given on the command line
+// is used as the title when there is no provider or the lookup fails.
+func lookupTicket(fx effects.Effects, provider, id, description string) (tickets.Ticket, error) {
+	if provider == "" {
+		return tickets.Ticket{ID: id, Title: description}, nil
+	}
+
+	ticket, err := fx.FetchTicket(provider, id)
+	if err != nil {
+		if description == "" {
+			return tickets.Ticket{}, fmt.Errorf("failed to look up ticket (pass a description to skip): %w", err)
+		}
+		fx.PrintErr(fmt.Sprintf("Warning: %v", err))
+		return tickets.Ticket{ID: id, Title: description}, nil
+	}
+	return ticket, nil
+}
+
+// branchFromTicket expands the configured branch template for a ticket.
+// The description falls back to the ticket title for {slug}, and the git
+// user is only looked up when the template needs it.
+func branchFromTicket(fx effects.Effects, repoRoot, template string, ticket tickets.Ticket, description string) (string, error) {
+	vars := core.BranchTemplateVars{Ticket: ticket.ID, Title: description}
+	if vars.Title == "" {
+		vars.Title = ticket.Title
+	}
+	if strings.Contains(template, "{user}") {
+		email, err := fx.RunGitCommand(repoRoot, "config", "user.email")
+		if err == nil {
+			vars.User = core.BranchUserFromEmail(email)
+		}
+	}
+	return core.ExpandBranchTemplate(template, vars)
+}
+
+// ciMode reports whether sprout runs in a CI pipeline. The CLI exports
+// SPROUT_CI for --ci, so the variable alone tells.
+func ciMode() bool {
+	v := os.Getenv("SPROUT_CI")
+	return v != "" && v != "0"
+}
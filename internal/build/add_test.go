@@ -0,0 +1,1060 @@
+package build
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/tickets"
+	"github.com/m44rten1/sprout/internal/trust"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	errPermissionDenied = errors.New("permission denied")
+	errDiskFull         = errors.New("disk full")
+)
+
+// baseTestFx creates a TestEffects with common defaults for add command tests.
+// This reduces duplication and makes test case differences stand out.
+func baseTestFx() *effects.TestEffects {
+	fx := effects.NewTestEffects()
+	fx.RepoRoot = "/test/repo"
+	fx.MainWorktreePath = "/test/repo"
+	fx.RemoteBranches["main"] = true
+	fx.Config = &config.Config{Hooks: config.HooksConfig{}}
+	return fx
+}
+
+// TestAddContext tests the handler's logic for building AddContext
+// from effects (the "imperative shell" layer). This catches wiring bugs
+// that pure planner tests miss.
+func TestAddContext(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		args          []string
+		noHooks       bool
+		noOpen        bool
+		setupFx       func(*effects.TestEffects)
+		wantCtx       *core.AddContext // nil if error expected
+		wantErr       bool
+		assertEffects func(t *testing.T, fx *effects.TestEffects)
+	}{
+		{
+			name:    "explicit branch with new worktree",
+			args:    []string{"feature"},
+			noHooks: false,
+			noOpen:  false,
+			setupFx: func(fx *effects.TestEffects) {
+				fx.LocalBranches["feature"] = false
+				fx.RemoteBranches["feature"] = false
+				fx.WorktreePaths["feature"] = "/test/repo-sprout/feature"
+				fx.Files["/test/repo-sprout/feature"] = false
+				fx.TrustedRepos["/test/repo"] = false
+			},
+			wantCtx: &core.AddContext{
+				Branch:             "feature",
+				RepoRoot:           "/test/repo",
+				MainWorktreePath:   "/test/repo",
+				WorktreePath:       "/test/repo-sprout/feature",
+				WorktreeExists:     false,
+				LocalBranchExists:  false,
+				RemoteBranchExists: false,
+				RemoteBase:         "origin/main",
+				Config:             &config.Config{Hooks: config.HooksConfig{}},
+				IsTrusted:          false,
+				NoHooks:            false,
+				NoOpen:             false,
+			},
+			wantErr: false,
+			// Happy path: verify captured data, not exact call counts
+		},
+		{
+			name:    "explicit branch with origin prefix stripped",
+			args:    []string{"origin/feature"},
+			noHooks: false,
+			noOpen:  false,
+			setupFx: func(fx *effects.TestEffects) {
+				fx.LocalBranches["feature"] = false
+				fx.RemoteBranches["feature"] = true
+				fx.WorktreePaths["feature"] = "/test/repo-sprout/feature"
+				fx.Files["/test/repo-sprout/feature"] = false
+				fx.TrustedRepos["/test/repo"] = false
+			},
+			wantCtx: &core.AddContext{
+				Branch:             "feature",
+				RepoRoot:           "/test/repo",
+				MainWorktreePath:   "/test/repo",
+				WorktreePath:       "/test/repo-sprout/feature",
+				WorktreeExists:     false,
+				LocalBranchExists:  false,
+				RemoteBranchExists: true,
+				RemoteBase:         "origin/main",
+				Config:             &config.Config{Hooks: config.HooksConfig{}},
+				IsTrusted:          false,
+				NoHooks:            false,
+				NoOpen:             false,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "interactive branch selection",
+			args:    []string{}, // Empty args = interactive
+			noHooks: false,
+			noOpen:  false,
+			setupFx: func(fx *effects.TestEffects) {
+				fx.Branches = []git.Branch{
+					{RefName: "feature", Name: "feature", DisplayName: "feature", IsLocal: true},
+					{RefName: "origin/bugfix", Name: "bugfix", DisplayName: "bugfix", IsLocal: false},
+				}
+				fx.Worktrees = []git.Worktree{}
+				fx.SelectedBranchIndex = 1 // Select "bugfix"
+				fx.LocalBranches["bugfix"] = false
+				fx.RemoteBranches["bugfix"] = true
+				fx.WorktreePaths["bugfix"] = "/test/repo-sprout/bugfix"
+				fx.Files["/test/repo-sprout/bugfix"] = false
+			},
+			wantCtx: &core.AddContext{
+				Branch:             "bugfix",
+				RepoRoot:           "/test/repo",
+				MainWorktreePath:   "/test/repo",
+				WorktreePath:       "/test/repo-sprout/bugfix",
+				WorktreeExists:     false,
+				LocalBranchExists:  false,
+				RemoteBranchExists: true,
+				RemoteBase:         "origin/main",
+				Config:             &config.Config{Hooks: config.HooksConfig{}},
+				IsTrusted:          false,
+				NoHooks:            false,
+				NoOpen:             false,
+			},
+			wantErr: false,
+			assertEffects: func(t *testing.T, fx *effects.TestEffects) {
+				// Verify interactive flow happened
+				assert.Greater(t, fx.SelectBranchCalls, 0)
+				assert.Greater(t, fx.ListBranchesCalls, 0)
+				assert.Greater(t, fx.ListWorktreesCalls, 0)
+			},
+		},
+		{
+			name:    "worktree exists",
+			args:    []string{"existing"},
+			noHooks: false,
+			noOpen:  false,
+			setupFx: func(fx *effects.TestEffects) {
+				fx.WorktreePaths["existing"] = "/test/repo-sprout/existing"
+				fx.Files["/test/repo-sprout/existing"] = true // Exists!
+				fx.LocalBranches["existing"] = true
+				fx.RemoteBranches["existing"] = false
+			},
+			wantCtx: &core.AddContext{
+				Branch:             "existing",
+				RepoRoot:           "/test/repo",
+				MainWorktreePath:   "/test/repo",
+				WorktreePath:       "/test/repo-sprout/existing",
+				WorktreeExists:     true,
+				LocalBranchExists:  true,
+				RemoteBranchExists: false,
+				RemoteBase:         "origin/main",
+				Config:             &config.Config{Hooks: config.HooksConfig{}},
+				IsTrusted:          false,
+				NoHooks:            false,
+				NoOpen:             false,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "hooks configured and trusted",
+			args:    []string{"feature"},
+			noHooks: false,
+			noOpen:  false,
+			setupFx: func(fx *effects.TestEffects) {
+				fx.LocalBranches["feature"] = false
+				fx.RemoteBranches["feature"] = false
+				fx.WorktreePaths["feature"] = "/test/repo-sprout/feature"
+				fx.Files["/test/repo-sprout/feature"] = false
+				fx.Config = &config.Config{
+					Hooks: config.HooksConfig{
+						OnCreate: []string{"npm install"},
+					},
+				}
+				fx.TrustedRepos["/test/repo"] = true // Trusted!
+			},
+			wantCtx: &core.AddContext{
+				Branch:             "feature",
+				RepoRoot:           "/test/repo",
+				MainWorktreePath:   "/test/repo",
+				WorktreePath:       "/test/repo-sprout/feature",
+				WorktreeExists:     false,
+				LocalBranchExists:  false,
+				RemoteBranchExists: false,
+				RemoteBase:         "origin/main",
+				Config: &config.Config{
+					Hooks: config.HooksConfig{
+						OnCreate: []string{"npm install"},
+					},
+				},
+				IsTrusted: true,
+				NoHooks:   false,
+				NoOpen:    false,
+			},
+			wantErr: false,
+			assertEffects: func(t *testing.T, fx *effects.TestEffects) {
+				// Security-critical: trust check must happen when hooks configured
+				assert.Greater(t, fx.IsTrustedCalls, 0, "Trust check is required for hooks")
+				require.Len(t, fx.IsTrustedArgs, 1)
+				assert.Equal(t, "/test/repo", fx.IsTrustedArgs[0])
+			},
+		},
+		{
+			name:    "hooks configured but --no-hooks flag",
+			args:    []string{"feature"},
+			noHooks: true, // Flag overrides hooks
+			noOpen:  false,
+			setupFx: func(fx *effects.TestEffects) {
+				fx.LocalBranches["feature"] = false
+				fx.RemoteBranches["feature"] = false
+				fx.WorktreePaths["feature"] = "/test/repo-sprout/feature"
+				fx.Files["/test/repo-sprout/feature"] = false
+				fx.Config = &config.Config{
+					Hooks: config.HooksConfig{
+						OnCreate: []string{"npm install"},
+					},
+				}
+			},
+			wantCtx: &core.AddContext{
+				Branch:             "feature",
+				RepoRoot:           "/test/repo",
+				MainWorktreePath:   "/test/repo",
+				WorktreePath:       "/test/repo-sprout/feature",
+				WorktreeExists:     false,
+				LocalBranchExists:  false,
+				RemoteBranchExists: false,
+				RemoteBase:         "origin/main",
+				Config: &config.Config{
+					Hooks: config.HooksConfig{
+						OnCreate: []string{"npm install"},
+					},
+				},
+				IsTrusted: false,
+				NoHooks:   true,
+				NoOpen:    false,
+			},
+			wantErr: false,
+			assertEffects: func(t *testing.T, fx *effects.TestEffects) {
+				// Security-critical: --no-hooks must skip trust check
+				assert.Equal(t, 0, fx.IsTrustedCalls, "Trust check must be skipped with --no-hooks")
+			},
+		},
+		{
+			name:    "GetRepoRoot fails",
+			args:    []string{"feature"},
+			noHooks: false,
+			noOpen:  false,
+			setupFx: func(fx *effects.TestEffects) {
+				fx.GetRepoRootErr = errors.New("not a git repository")
+			},
+			wantCtx: nil, // Error expected
+			wantErr: true,
+			assertEffects: func(t *testing.T, fx *effects.TestEffects) {
+				assert.Equal(t, 1, fx.GetRepoRootCalls)
+				// Should not proceed further
+				assert.Equal(t, 0, fx.GetMainWorktreePathCalls)
+			},
+		},
+		{
+			name:    "GetMainWorktreePath fails",
+			args:    []string{"feature"},
+			noHooks: false,
+			noOpen:  false,
+			setupFx: func(fx *effects.TestEffects) {
+				fx.RepoRoot = "/test/repo"
+				fx.GetMainWorktreePathErr = errDiskFull
+			},
+			wantCtx: nil, // Error expected
+			wantErr: true,
+			assertEffects: func(t *testing.T, fx *effects.TestEffects) {
+				assert.Equal(t, 1, fx.GetRepoRootCalls)
+				assert.Equal(t, 1, fx.GetMainWorktreePathCalls)
+				// Should not proceed to branch selection
+				assert.Equal(t, 0, fx.SelectBranchCalls)
+			},
+		},
+		{
+			name:    "interactive selection cancelled",
+			args:    []string{}, // Interactive mode
+			noHooks: false,
+			noOpen:  false,
+			setupFx: func(fx *effects.TestEffects) {
+				fx.Branches = []git.Branch{
+					{RefName: "feature", Name: "feature", DisplayName: "feature", IsLocal: true},
+				}
+				fx.Worktrees = []git.Worktree{}
+				fx.SelectionError = errors.New("user cancelled")
+			},
+			wantCtx: nil, // Error expected
+			wantErr: true,
+			assertEffects: func(t *testing.T, fx *effects.TestEffects) {
+				assert.Equal(t, 1, fx.SelectBranchCalls)
+			},
+		},
+		{
+			name:    "no available branches in interactive mode",
+			args:    []string{}, // Interactive mode
+			noHooks: false,
+			noOpen:  false,
+			setupFx: func(fx *effects.TestEffects) {
+				fx.Branches = []git.Branch{
+					{RefName: "main", Name: "main", DisplayName: "main", IsLocal: true},
+				}
+				fx.Worktrees = []git.Worktree{
+					{Branch: "main", Path: "/test/repo"}, // main is taken
+				}
+			},
+			wantCtx: nil, // Error expected
+			wantErr: true,
+			assertEffects: func(t *testing.T, fx *effects.TestEffects) {
+				assert.Equal(t, 1, fx.ListBranchesCalls)
+				assert.Equal(t, 1, fx.ListWorktreesCalls)
+				// Should not proceed to selection
+				assert.Equal(t, 0, fx.SelectBranchCalls)
+			},
+		},
+		{
+			name:    "LoadConfig fails",
+			args:    []string{"feature"},
+			noHooks: false,
+			noOpen:  false,
+			setupFx: func(fx *effects.TestEffects) {
+				fx.LocalBranches["feature"] = false
+				fx.RemoteBranches["feature"] = false
+				fx.WorktreePaths["feature"] = "/test/repo-sprout/feature"
+				fx.Files["/test/repo-sprout/feature"] = false
+				fx.LoadConfigErr = errors.New("config parse error")
+			},
+			wantCtx: nil, // Error expected
+			wantErr: true,
+			assertEffects: func(t *testing.T, fx *effects.TestEffects) {
+				assert.Equal(t, 1, fx.LoadConfigCalls)
+			},
+		},
+		{
+			name:    "IsTrusted fails",
+			args:    []string{"feature"},
+			noHooks: false,
+			noOpen:  false,
+			setupFx: func(fx *effects.TestEffects) {
+				fx.LocalBranches["feature"] = false
+				fx.RemoteBranches["feature"] = false
+				fx.WorktreePaths["feature"] = "/test/repo-sprout/feature"
+				fx.Files["/test/repo-sprout/feature"] = false
+				fx.Config = &config.Config{
+					Hooks: config.HooksConfig{
+						OnCreate: []string{"npm install"},
+					},
+				}
+				fx.IsTrustedErr = errPermissionDenied
+			},
+			wantCtx: nil, // Error expected
+			wantErr: true,
+			assertEffects: func(t *testing.T, fx *effects.TestEffects) {
+				assert.Equal(t, 1, fx.IsTrustedCalls)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fx := baseTestFx()
+			tt.setupFx(fx)
+
+			ctx, err := AddContext(fx, tt.args, AddOptions{NoHooks: tt.noHooks, NoOpen: tt.noOpen})
+
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, tt.wantCtx, "Test misconfiguration: wantCtx should not be nil for success cases")
+				assert.Equal(t, *tt.wantCtx, ctx)
+			}
+
+			if tt.assertEffects != nil {
+				tt.assertEffects(t, fx)
+			}
+		})
+	}
+}
+
+func TestAddContext_Ticket(t *testing.T) {
+	t.Parallel()
+
+	newFx := func() *effects.TestEffects {
+		fx := baseTestFx()
+		fx.Config = &config.Config{BranchTemplate: "feat/{user}/{ticket}-{slug}"}
+		fx.GitCommandOutput["/test/repo\nconfig user.email"] = "jane@example.com"
+		fx.WorktreePaths["feat/jane/ABC-123-fix-login"] = "/test/repo-sprout/feat/jane/ABC-123-fix-login"
+		return fx
+	}
+
+	t.Run("expands branch template", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+
+		ctx, err := AddContext(fx, []string{"fix login"}, AddOptions{Ticket: "ABC-123"})
+		require.NoError(t, err)
+		assert.Equal(t, "feat/jane/ABC-123-fix-login", ctx.Branch)
+		assert.Equal(t, "/test/repo-sprout/feat/jane/ABC-123-fix-login", ctx.WorktreePath)
+		assert.Equal(t, 0, fx.SelectBranchCalls)
+	})
+
+	t.Run("requires a template", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.Config = &config.Config{}
+
+		_, err := AddContext(fx, []string{"fix login"}, AddOptions{Ticket: "ABC-123"})
+		assert.ErrorIs(t, err, core.ErrNoBranchTemplate)
+	})
+
+	t.Run("explains missing description", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := AddContext(newFx(), nil, AddOptions{Ticket: "ABC-123"})
+		assert.ErrorContains(t, err, "branch_template uses {slug} but no description was given")
+	})
+
+	t.Run("fetches title from provider", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.Config.TicketProvider = tickets.ProviderJira
+		fx.Tickets["ABC-123"] = tickets.Ticket{ID: "ABC-123", Title: "Fix login", URL: "https://acme.atlassian.net/browse/ABC-123"}
+
+		ctx, err := AddContext(fx, nil, AddOptions{Ticket: "ABC-123"})
+		require.NoError(t, err)
+		assert.Equal(t, "feat/jane/ABC-123-fix-login", ctx.Branch)
+		assert.Equal(t, fx.Tickets["ABC-123"], ctx.Ticket)
+	})
+
+	t.Run("description survives failed lookup", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.Config.TicketProvider = tickets.ProviderLinear
+		fx.FetchTicketErr = errors.New("linear ticket provider needs LINEAR_API_KEY")
+
+		ctx, err := AddContext(fx, []string{"fix login"}, AddOptions{Ticket: "ABC-123"})
+		require.NoError(t, err)
+		assert.Equal(t, tickets.Ticket{ID: "ABC-123", Title: "fix login"}, ctx.Ticket)
+		assert.Len(t, fx.PrintedErrs, 1)
+
+		_, err = AddContext(fx, nil, AddOptions{Ticket: "ABC-123"})
+		assert.ErrorContains(t, err, "LINEAR_API_KEY")
+	})
+}
+
+func TestAddContext_TemplateDir(t *testing.T) {
+	t.Parallel()
+
+	// Mirror a real template directory into TestEffects
+	tmpl := t.TempDir()
+	mustMkdirAll(t, filepath.Join(tmpl, ".vscode"))
+	mustWriteFile(t, filepath.Join(tmpl, ".vscode", "settings.json"), "{}")
+	mustWriteFile(t, filepath.Join(tmpl, ".env.local"), "PORT=3000")
+
+	const dir = "/test/repo/.sprout/template"
+	newFx := func() *effects.TestEffects {
+		fx := baseTestFx()
+		fx.Config = &config.Config{TemplateDir: ".sprout/template"}
+		fx.WorktreePaths["feature"] = "/test/repo-sprout/feature"
+		fx.Files[dir] = true
+		for _, sub := range []string{"", ".vscode"} {
+			entries, err := os.ReadDir(filepath.Join(tmpl, sub))
+			require.NoError(t, err)
+			fx.DirEntries[filepath.Join(dir, sub)] = entries
+		}
+		return fx
+	}
+
+	t.Run("lists template files", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, err := AddContext(newFx(), []string{"feature"}, AddOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, dir, ctx.TemplateDir)
+		assert.Equal(t, []string{".env.local", filepath.Join(".vscode", "settings.json")}, ctx.TemplateFiles)
+	})
+
+	t.Run("relative to main worktree", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.Config.TemplateDir = "template"
+
+		_, err := AddContext(fx, []string{"feature"}, AddOptions{})
+		assert.ErrorContains(t, err, "template_dir not found: /test/repo/template")
+	})
+
+	t.Run("existing worktree skips templates", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.Files["/test/repo-sprout/feature"] = true
+
+		ctx, err := AddContext(fx, []string{"feature"}, AddOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, ctx.TemplateFiles)
+		assert.Equal(t, 0, fx.ReadDirCalls)
+	})
+}
+
+func TestAddContext_CloneArtifacts(t *testing.T) {
+	t.Parallel()
+
+	newFx := func() *effects.TestEffects {
+		fx := baseTestFx()
+		fx.Config = &config.Config{Artifacts: []string{"target"}}
+		fx.WorktreePaths["feature"] = "/test/repo-sprout/feature"
+		fx.CanReflink = true
+		return fx
+	}
+
+	t.Run("clones configured artifacts", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, err := AddContext(newFx(), []string{"feature"}, AddOptions{CloneArtifacts: true})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"target"}, ctx.Artifacts)
+	})
+
+	t.Run("off without the flag", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, err := AddContext(newFx(), []string{"feature"}, AddOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, ctx.Artifacts)
+	})
+
+	t.Run("warns when clones are unsupported", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.CanReflink = false
+
+		ctx, err := AddContext(fx, []string{"feature"}, AddOptions{CloneArtifacts: true})
+		require.NoError(t, err)
+		assert.Empty(t, ctx.Artifacts)
+		require.Len(t, fx.PrintedErrs, 1)
+		assert.Contains(t, fx.PrintedErrs[0], "skipping --clone-artifacts")
+	})
+
+	t.Run("needs artifacts in config", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.Config.Artifacts = nil
+
+		_, err := AddContext(fx, []string{"feature"}, AddOptions{CloneArtifacts: true})
+		assert.ErrorContains(t, err, "artifacts in .sprout.yml")
+	})
+}
+
+func TestAddContext_MaxWorktrees(t *testing.T) {
+	t.Parallel()
+
+	newFx := func(limit int) *effects.TestEffects {
+		fx := baseTestFx()
+		fx.Config = &config.Config{MaxWorktrees: limit}
+		fx.WorktreeRoot = "/test/repo-sprout"
+		fx.WorktreePaths["feature"] = "/test/repo-sprout/feature"
+		fx.Worktrees = []git.Worktree{
+			{Path: "/test/repo", Branch: "main"},
+			{Path: "/test/repo-sprout/old", Branch: "old"},
+			{Path: "/test/repo-sprout/wip", Branch: "wip"},
+		}
+		fx.WorktreeStatuses["/test/repo-sprout/wip"] = git.WorktreeStatus{Dirty: true}
+		fx.LastUsed["/test/repo-sprout/old"] = time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+		return fx
+	}
+
+	t.Run("collects usage when over the limit", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, err := AddContext(newFx(2), []string{"feature"}, AddOptions{Evict: true})
+		require.NoError(t, err)
+		assert.True(t, ctx.Evict)
+		assert.Equal(t, []core.WorktreeUsage{
+			{Path: "/test/repo-sprout/old", Branch: "old", LastUsed: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+			{Path: "/test/repo-sprout/wip", Branch: "wip", Dirty: true},
+		}, ctx.ExistingWorktrees)
+	})
+
+	t.Run("marks the current and locked worktrees", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx(2)
+		fx.RepoRoot = "/test/repo-sprout/old"
+		fx.Worktrees[2].Locked = true
+
+		ctx, err := AddContext(fx, []string{"feature"}, AddOptions{Evict: true})
+		require.NoError(t, err)
+		assert.Equal(t, []core.WorktreeUsage{
+			{Path: "/test/repo-sprout/old", Branch: "old", LastUsed: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), Current: true},
+			{Path: "/test/repo-sprout/wip", Branch: "wip", Dirty: true, Locked: true},
+		}, ctx.ExistingWorktrees)
+	})
+
+	t.Run("skips statuses within the limit", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx(5)
+
+		ctx, err := AddContext(fx, []string{"feature"}, AddOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, ctx.ExistingWorktrees)
+		assert.Equal(t, 0, fx.GetWorktreeStatusCalls)
+	})
+}
+
+func TestAddContext_OpenEditor(t *testing.T) {
+	t.Parallel()
+
+	no, yes := false, true
+	tests := []struct {
+		name       string
+		repo, user *bool
+		opts       AddOptions
+		wantNoOpen bool
+	}{
+		{name: "opens by default"},
+		{name: "user config disables", user: &no, wantNoOpen: true},
+		{name: "repo config wins over user config", repo: &yes, user: &no},
+		{name: "repo config disables", repo: &no, wantNoOpen: true},
+		{name: "--open overrides config", repo: &no, opts: AddOptions{Open: true}},
+		{name: "--no-open overrides config", repo: &yes, opts: AddOptions{NoOpen: true}, wantNoOpen: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fx := baseTestFx()
+			fx.Config = &config.Config{OpenEditor: tt.repo}
+			fx.UserConfig = &config.UserConfig{OpenEditor: tt.user}
+
+			ctx, err := AddContext(fx, []string{"feature"}, tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantNoOpen, ctx.NoOpen)
+		})
+	}
+}
+
+func TestAddContext_DefaultHooks(t *testing.T) {
+	t.Parallel()
+
+	no := false
+	tests := []struct {
+		name string
+		repo config.HooksConfig
+		want []string
+	}{
+		{name: "user default hooks", want: []string{"direnv allow"}},
+		{name: "repository opts out", repo: config.HooksConfig{DefaultHooks: &no}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fx := baseTestFx()
+			fx.Config = &config.Config{Hooks: tt.repo}
+			fx.UserConfig = &config.UserConfig{DefaultHooks: []string{"direnv allow"}}
+
+			ctx, err := AddContext(fx, []string{"feature"}, AddOptions{NoOpen: true})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ctx.DefaultHooks)
+		})
+	}
+
+	t.Run("policy denial", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+		fx.UserConfig = &config.UserConfig{DefaultHooks: []string{"direnv allow"}}
+		fx.IsTrustedErr = trust.ErrDeniedByPolicy
+
+		ctx, err := AddContext(fx, []string{"feature"}, AddOptions{NoOpen: true})
+		require.NoError(t, err)
+		assert.True(t, ctx.HooksDenied)
+	})
+}
+
+func TestAddContext_HeadBranch(t *testing.T) {
+	t.Parallel()
+	fx := baseTestFx()
+	delete(fx.RemoteBranches, "main")
+	fx.GitCommandOutput["/test/repo\nrev-parse --abbrev-ref HEAD"] = "develop"
+
+	ctx, err := AddContext(fx, []string{"feature"}, AddOptions{NoOpen: true})
+	require.NoError(t, err)
+	assert.Equal(t, "develop", ctx.HeadBranch, "base of a new branch without origin/main")
+
+	require.NoError(t, effects.ExecutePlan(core.PlanAddCommand(ctx), fx))
+	assert.Equal(t, "develop", fx.WorktreeMetadata[ctx.WorktreePath].Base)
+}
+
+func TestAddContext_DefaultBranchBase(t *testing.T) {
+	t.Parallel()
+	fx := baseTestFx()
+	fx.Config = &config.Config{DefaultBranch: "trunk"}
+	fx.RemoteBranches["trunk"] = true
+
+	ctx, err := AddContext(fx, []string{"feature"}, AddOptions{NoOpen: true})
+	require.NoError(t, err)
+	assert.Equal(t, "origin/trunk", ctx.RemoteBase, "default_branch replaces main as the base")
+
+	require.NoError(t, effects.ExecutePlan(core.PlanAddCommand(ctx), fx))
+	assert.Equal(t, "origin/trunk", fx.WorktreeMetadata[ctx.WorktreePath].Base)
+}
+
+func TestAddContext_DetectedDefaultBranchBase(t *testing.T) {
+	t.Parallel()
+	fx := baseTestFx()
+	fx.DefaultBranch = "develop"
+	fx.RemoteBranches["develop"] = true
+
+	ctx, err := AddContext(fx, []string{"feature"}, AddOptions{NoOpen: true})
+	require.NoError(t, err)
+	assert.Equal(t, "origin/develop", ctx.RemoteBase, "without default_branch, the branch git reports")
+}
+
+func TestAddContext_NoRemote(t *testing.T) {
+	t.Parallel()
+	fx := baseTestFx()
+	fx.NoRemote = true
+	fx.GitCommandOutput["/test/repo\nrev-parse --abbrev-ref HEAD"] = "main"
+
+	ctx, err := AddContext(fx, []string{"origin/feature"}, AddOptions{NoOpen: true})
+	require.NoError(t, err)
+	assert.Equal(t, "origin/feature", ctx.Branch, "without a remote, origin/ is part of the branch name")
+	assert.Zero(t, fx.RemoteBranchExistsCalls, "no remote branches to check")
+	assert.Empty(t, ctx.RemoteBase)
+	assert.Equal(t, "main", ctx.HeadBranch)
+
+	assert.Contains(t, core.PlanAddCommand(ctx).Actions, core.RunGitCommand{
+		Dir:  "/test/repo",
+		Args: []string{"worktree", "add", ctx.WorktreePath, "-b", "origin/feature", "--no-track", "HEAD"},
+	}, "new branches start from HEAD")
+}
+
+func TestAddContext_ApplyChanges(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stash index", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+
+		ctx, err := AddContext(fx, []string{"feature"}, AddOptions{NoOpen: true, FromStash: "1"})
+		require.NoError(t, err)
+		assert.Equal(t, "stash@{1}", ctx.FromStash)
+	})
+
+	t.Run("missing stash entry", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+		fx.GitCommandErrors["/test/repo\nrev-parse --verify --quiet stash@{3}^{commit}"] = errors.New("exit status 1")
+
+		_, err := AddContext(fx, []string{"feature"}, AddOptions{NoOpen: true, FromStash: "stash@{3}"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no stash entry stash@{3}")
+	})
+
+	t.Run("patch path is made absolute", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+		fx.Files["fix.diff"] = true
+		fx.RegularFiles["fix.diff"] = true
+
+		ctx, err := AddContext(fx, []string{"feature"}, AddOptions{NoOpen: true, ApplyPatch: "fix.diff"})
+		require.NoError(t, err)
+		want, err := filepath.Abs("fix.diff")
+		require.NoError(t, err)
+		assert.Equal(t, want, ctx.PatchFile)
+	})
+
+	t.Run("missing patch file", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+
+		_, err := AddContext(fx, []string{"feature"}, AddOptions{NoOpen: true, ApplyPatch: "missing.diff"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "patch file not found: missing.diff")
+	})
+}
+
+func TestAddContext_TTL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("records the expiry", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+		before := time.Now()
+
+		ctx, err := AddContext(fx, []string{"feature"}, AddOptions{NoOpen: true, TTL: "2h"})
+		require.NoError(t, err)
+		assert.WithinRange(t, ctx.ExpiresAt, before.Add(2*time.Hour), time.Now().Add(2*time.Hour))
+
+		require.NoError(t, effects.ExecutePlan(core.PlanAddCommand(ctx), fx))
+		assert.Equal(t, ctx.ExpiresAt, fx.WorktreeMetadata[ctx.WorktreePath].ExpiresAt)
+	})
+
+	t.Run("accepts days", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, err := AddContext(baseTestFx(), []string{"feature"}, AddOptions{NoOpen: true, TTL: "3d"})
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(72*time.Hour), ctx.ExpiresAt, time.Minute)
+	})
+
+	for _, ttl := range []string{"soon", "0", "-1h"} {
+		t.Run("rejects "+ttl, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := AddContext(baseTestFx(), []string{"feature"}, AddOptions{NoOpen: true, TTL: ttl})
+			assert.ErrorContains(t, err, "invalid --ttl")
+		})
+	}
+}
+
+func TestAddContext_Tag(t *testing.T) {
+	t.Parallel()
+
+	t.Run("names the branch with --branch", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+		fx.WorktreePaths["hotfix/1.4.3"] = "/test/repo-sprout/hotfix/1.4.3"
+
+		ctx, err := AddContext(fx, nil, AddOptions{NoOpen: true, Tag: "refs/tags/v1.4.2", Branch: "hotfix/1.4.3"})
+		require.NoError(t, err)
+		assert.Equal(t, "hotfix/1.4.3", ctx.Branch)
+		assert.Equal(t, "v1.4.2", ctx.Tag)
+		assert.Zero(t, fx.SelectBranchCalls)
+
+		require.NoError(t, effects.ExecutePlan(core.PlanAddCommand(ctx), fx))
+		assert.Equal(t, "v1.4.2", fx.WorktreeMetadata[ctx.WorktreePath].Base)
+	})
+
+	t.Run("missing tag", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+		fx.GitCommandErrors["/test/repo\nrev-parse --verify --quiet refs/tags/v9^{commit}"] = errors.New("exit status 1")
+
+		_, err := AddContext(fx, []string{"hotfix"}, AddOptions{NoOpen: true, Tag: "v9"})
+		assert.ErrorContains(t, err, "no tag v9")
+	})
+
+	t.Run("needs a branch name", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := AddContext(baseTestFx(), nil, AddOptions{NoOpen: true, Tag: "v1.4.2"})
+		assert.ErrorContains(t, err, "--tag needs the name of the new branch")
+	})
+
+	t.Run("branch given twice", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := AddContext(baseTestFx(), []string{"hotfix"}, AddOptions{NoOpen: true, Tag: "v1.4.2", Branch: "hotfix"})
+		assert.ErrorContains(t, err, "not both")
+	})
+}
+
+func TestAddContext_BranchCase(t *testing.T) {
+	t.Parallel()
+	fx := baseTestFx()
+	fx.Branches = []git.Branch{
+		{RefName: "main", Name: "main", DisplayName: "main", IsLocal: true},
+		{RefName: "origin/feat/Login", Name: "feat/Login", DisplayName: "feat/Login"},
+	}
+	fx.RemoteBranches["feat/Login"] = true
+	fx.WorktreePaths["feat/Login"] = "/test/repo-sprout/feat/Login/repo"
+
+	ctx, err := AddContext(fx, []string{"origin/feat/login"}, AddOptions{NoOpen: true})
+	require.NoError(t, err)
+	assert.Equal(t, "feat/Login", ctx.Branch, "the existing branch, not a new one in another case")
+	assert.True(t, ctx.RemoteBranchExists)
+}
+
+func TestAddContext_PickerShowsCheckedOutBranches(t *testing.T) {
+	t.Parallel()
+	newFx := func() *effects.TestEffects {
+		fx := baseTestFx()
+		fx.Branches = []git.Branch{
+			{RefName: "main", Name: "main", DisplayName: "main", IsLocal: true},
+			{RefName: "feature", Name: "feature", DisplayName: "feature", IsLocal: true},
+			{RefName: "origin/feature", Name: "feature", DisplayName: "feature"},
+			{RefName: "origin/bugfix", Name: "bugfix", DisplayName: "bugfix"},
+		}
+		fx.Worktrees = []git.Worktree{
+			{Path: "/test/repo", Branch: "main"},
+			{Path: "/elsewhere/feature", Branch: "feature"},
+		}
+		fx.RemoteBranches["bugfix"] = true
+		return fx
+	}
+
+	fx := newFx()
+	ctx, err := AddContext(fx, nil, AddOptions{NoOpen: true})
+	require.NoError(t, err)
+	assert.Equal(t, "bugfix", ctx.Branch)
+	require.Len(t, fx.SelectBranchArgs, 1)
+	assert.Equal(t, []core.BranchChoice{
+		{Branch: fx.Branches[3]},
+		{Branch: fx.Branches[0], CheckedOutAt: "/test/repo"},
+		{Branch: fx.Branches[1], CheckedOutAt: "/elsewhere/feature"},
+	}, fx.SelectBranchArgs[0])
+
+	fx = newFx()
+	fx.SelectedBranchIndex = 2
+	_, err = AddContext(fx, nil, AddOptions{NoOpen: true})
+	assert.ErrorContains(t, err, "feature is checked out at /elsewhere/feature", "checked out branches can't be picked")
+
+	fx = newFx()
+	fx.Branches = fx.Branches[:3]
+	_, err = AddContext(fx, nil, AddOptions{NoOpen: true})
+	assert.EqualError(t, err, "no available branches found; each one is checked out in a worktree already (see 'git worktree list')")
+	assert.Zero(t, fx.SelectBranchCalls)
+}
+
+func TestAddContext_Lock(t *testing.T) {
+	t.Parallel()
+	fx := baseTestFx()
+
+	ctx, err := AddContext(fx, []string{"feature"}, AddOptions{NoOpen: true, Lock: true, LockReason: "on the USB drive"})
+	require.NoError(t, err)
+	assert.True(t, ctx.Lock)
+	assert.Equal(t, "on the USB drive", ctx.LockReason)
+	require.NoError(t, effects.ExecutePlan(core.PlanAddCommand(ctx), fx))
+	assert.Contains(t, fx.GitCommands, effects.GitCmd{Dir: "/test/repo", Args: []string{"worktree", "add", "--lock", "--reason", "on the USB drive", ctx.WorktreePath, "-b", "feature", "--no-track", "origin/main"}})
+
+	_, err = AddContext(baseTestFx(), []string{"feature"}, AddOptions{Lock: true, LockReason: "--no-open"})
+	assert.EqualError(t, err, `--lock takes a reason, not --no-open; pass --lock "" to lock without one`)
+}
+
+func TestAddContext_CheckedOutElsewhere(t *testing.T) {
+	t.Parallel()
+	newFx := func() *effects.TestEffects {
+		fx := baseTestFx()
+		fx.LocalBranches["feature"] = true
+		fx.WorktreePaths["feature"] = "/test/repo-sprout/feature/repo"
+		fx.Worktrees = []git.Worktree{
+			{Path: "/test/repo", Branch: "main"},
+			{Path: "/elsewhere/feature", Branch: "feature"},
+		}
+		return fx
+	}
+
+	ctx, err := AddContext(newFx(), []string{"feature"}, AddOptions{NoOpen: true})
+	require.NoError(t, err)
+	assert.Equal(t, "/elsewhere/feature", ctx.CheckedOutAt)
+	assert.False(t, ctx.Force)
+
+	fx := newFx()
+	ctx, err = AddContext(fx, []string{"feature"}, AddOptions{NoOpen: true, ForceCreate: true})
+	require.NoError(t, err)
+	assert.True(t, ctx.Force)
+	require.NoError(t, effects.ExecutePlan(core.PlanAddCommand(ctx), fx))
+	assert.Contains(t, fx.GitCommands, effects.GitCmd{Dir: "/test/repo", Args: []string{"worktree", "add", "--force", "/test/repo-sprout/feature/repo", "feature"}})
+}
+
+func TestAddContext_WorktreePathStat(t *testing.T) {
+	t.Parallel()
+	newFx := func() *effects.TestEffects {
+		fx := baseTestFx()
+		fx.WorktreePaths["feature"] = "/test/repo-sprout/feature/repo"
+		return fx
+	}
+
+	t.Run("permission denied", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.StatErrors["/test/repo-sprout/feature/repo"] = &os.PathError{Op: "stat", Path: "/test/repo-sprout/feature/repo", Err: os.ErrPermission}
+
+		_, err := AddContext(fx, []string{"feature"}, AddOptions{NoOpen: true})
+		assert.ErrorIs(t, err, os.ErrPermission)
+		assert.ErrorContains(t, err, "can't check worktree path")
+	})
+
+	t.Run("file in the way", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.Files["/test/repo-sprout/feature/repo"] = true
+		fx.RegularFiles["/test/repo-sprout/feature/repo"] = true
+
+		_, err := AddContext(fx, []string{"feature"}, AddOptions{NoOpen: true})
+		assert.EqualError(t, err, "/test/repo-sprout/feature/repo is a file, where the worktree should go; move it away")
+	})
+}
+
+func TestAddContext_Detach(t *testing.T) {
+	t.Parallel()
+	const sha = "3f2a9c1d6b0e4a7c8f9e2d1b0a3c4e5f6a7b8c9d"
+	detachFx := func() *effects.TestEffects {
+		fx := baseTestFx()
+		fx.GitCommandOutput["/test/repo\nrev-parse --verify --quiet v1.4.2^{commit}"] = sha
+		fx.GitCommandOutput["/test/repo\nrev-parse --short "+sha] = "3f2a9c1"
+		return fx
+	}
+
+	t.Run("named after the short commit", func(t *testing.T) {
+		t.Parallel()
+		fx := detachFx()
+		fx.LocalBranches["3f2a9c1"] = true // rev-parse --verify resolves commits too
+		fx.WorktreePaths["3f2a9c1"] = "/test/repo-sprout/3f2a9c1/repo"
+
+		ctx, err := AddContext(fx, nil, AddOptions{NoOpen: true, Detach: "v1.4.2"})
+		require.NoError(t, err)
+		assert.Equal(t, "3f2a9c1", ctx.Branch)
+		assert.Equal(t, sha, ctx.Detach)
+		assert.False(t, ctx.LocalBranchExists, "the short commit is no branch")
+		assert.Empty(t, ctx.RemoteBase, "no branch to start from origin/main")
+		assert.Zero(t, fx.SelectBranchCalls)
+	})
+
+	t.Run("named after the label", func(t *testing.T) {
+		t.Parallel()
+		fx := detachFx()
+		fx.WorktreePaths["release"] = "/test/repo-sprout/release/repo"
+
+		ctx, err := AddContext(fx, []string{"release"}, AddOptions{NoOpen: true, Detach: "v1.4.2"})
+		require.NoError(t, err)
+		assert.Equal(t, "release", ctx.Branch)
+		assert.Equal(t, "/test/repo-sprout/release/repo", ctx.WorktreePath)
+	})
+
+	t.Run("unknown ref", func(t *testing.T) {
+		t.Parallel()
+		fx := baseTestFx()
+		fx.GitCommandErrors["/test/repo\nrev-parse --verify --quiet nope^{commit}"] = errors.New("exit status 1")
+
+		_, err := AddContext(fx, nil, AddOptions{NoOpen: true, Detach: "nope"})
+		assert.ErrorContains(t, err, "no commit nope to detach at")
+	})
+
+	t.Run("label with a slash", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := AddContext(detachFx(), []string{"rel/1.4"}, AddOptions{NoOpen: true, Detach: "v1.4.2"})
+		assert.ErrorContains(t, err, "can't contain '/'")
+	})
+
+	t.Run("label of a branch", func(t *testing.T) {
+		t.Parallel()
+		fx := detachFx()
+		fx.GitCommandOutput["/test/repo\nfor-each-ref --format=%(refname) refs/heads/feature"] = "refs/heads/feature"
+		fx.WorktreePaths["feature"] = "/test/repo-sprout/feature/repo"
+
+		_, err := AddContext(fx, []string{"feature"}, AddOptions{NoOpen: true, Detach: "v1.4.2"})
+		assert.ErrorContains(t, err, "there is a branch feature")
+	})
+}
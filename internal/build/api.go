@@ -0,0 +1,81 @@
+package build
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+)
+
+// APIWorktreeList gathers the main worktree and the sprout worktrees of
+// the current repository, with status, base, note, ticket and pin, like 'sprout list'.
+func APIWorktreeList(fx effects.Effects) ([]core.APIWorktree, error) {
+	mainWorktree, managed, err := collectAPIWorktrees(fx)
+	if err != nil {
+		return nil, err
+	}
+
+	repos := []core.RepoDisplay{RepoDisplay(fx, filepath.Base(mainWorktree.Path), mainWorktree, managed)}
+	AssignTickets(fx, repos)
+	AssignDetails(fx, repos)
+	AssignPins(fx, repos)
+	CacheStatuses(fx, repos)
+
+	worktrees := make([]core.APIWorktree, 0, len(repos[0].Worktrees))
+	for _, item := range repos[0].Worktrees {
+		worktrees = append(worktrees, core.NewAPIWorktree(item))
+	}
+	return worktrees, nil
+}
+
+// ResolveAPIWorktree finds the main or sprout worktree that has branch
+// checked out. A remote prefix and differences in case are ignored (see
+// core.MatchBranchName).
+func ResolveAPIWorktree(fx effects.Effects, branch string) (core.APIWorktree, error) {
+	if branch == "" {
+		return core.APIWorktree{}, fmt.Errorf("%w: branch is required", core.ErrInvalidAPIRequest)
+	}
+
+	mainWorktree, managed, err := collectAPIWorktrees(fx)
+	if err != nil {
+		return core.APIWorktree{}, err
+	}
+	switch i := core.MatchWorktreeBranch(append([]git.Worktree{mainWorktree}, managed...), branch); {
+	case i == 0:
+		return core.APIWorktree{Path: mainWorktree.Path, Branch: mainWorktree.Branch, Main: true}, nil
+	case i > 0:
+		return core.APIWorktree{Path: managed[i-1].Path, Branch: managed[i-1].Branch}, nil
+	}
+	return core.APIWorktree{}, fmt.Errorf("%w for branch '%s'", core.ErrNoWorktreeFound, core.NormalizeBranchArg(branch))
+}
+
+// collectAPIWorktrees returns the main worktree and the existing sprout
+// worktrees of the current repository.
+func collectAPIWorktrees(fx effects.Effects) (git.Worktree, []git.Worktree, error) {
+	repoRoot, err := fx.GetRepoRoot()
+	if err != nil {
+		return git.Worktree{}, nil, fmt.Errorf("not a git repository: %w", err)
+	}
+	mainWorktreePath, err := fx.GetMainWorktreePath()
+	if err != nil {
+		return git.Worktree{}, nil, fmt.Errorf("failed to get main worktree: %w", err)
+	}
+	worktreeRoot, err := fx.GetWorktreeRoot(mainWorktreePath)
+	if err != nil {
+		return git.Worktree{}, nil, fmt.Errorf("failed to get sprout root: %w", err)
+	}
+	worktrees, err := fx.ListWorktrees(repoRoot)
+	if err != nil {
+		return git.Worktree{}, nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	adopted, err := fx.ListAdoptedWorktrees()
+	if err != nil {
+		return git.Worktree{}, nil, fmt.Errorf("failed to load adopted worktrees: %w", err)
+	}
+
+	mainWorktree := git.Worktree{Path: mainWorktreePath, Branch: MainBranch(worktrees, mainWorktreePath)}
+	managed := core.FilterSproutWorktrees(worktrees, worktreeRoot, adopted...)
+	return mainWorktree, ExistingWorktrees(fx, managed), nil
+}
@@ -0,0 +1,72 @@
+package build
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/sprout"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func apiTestFx() *effects.TestEffects {
+	fx := baseTestFx()
+	fx.WorktreeRoot = "/test/repo/.sprout"
+	fx.Worktrees = []git.Worktree{
+		{Path: "/test/repo", Branch: "main"},
+		{Path: "/test/repo/.sprout/feature", Branch: "feature"},
+		{Path: "/elsewhere/other", Branch: "other"}, // Not managed by sprout
+	}
+	fx.Files["/test/repo/.sprout/feature"] = true
+	fx.Files["/elsewhere/other"] = true
+	return fx
+}
+
+func TestResolveAPIWorktree(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		branch  string
+		want    core.APIWorktree
+		wantErr error
+	}{
+		{name: "sprout worktree", branch: "feature", want: core.APIWorktree{Path: "/test/repo/.sprout/feature", Branch: "feature"}},
+		{name: "origin prefix is ignored", branch: "origin/feature", want: core.APIWorktree{Path: "/test/repo/.sprout/feature", Branch: "feature"}},
+		{name: "case is ignored", branch: "refs/heads/FEATURE", want: core.APIWorktree{Path: "/test/repo/.sprout/feature", Branch: "feature"}},
+		{name: "main worktree", branch: "main", want: core.APIWorktree{Path: "/test/repo", Branch: "main", Main: true}},
+		{name: "worktree not managed by sprout", branch: "other", wantErr: core.ErrNoWorktreeFound},
+		{name: "unknown branch", branch: "nope", wantErr: core.ErrNoWorktreeFound},
+		{name: "missing branch", branch: "", wantErr: core.ErrInvalidAPIRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ResolveAPIWorktree(apiTestFx(), tt.branch)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAPIWorktreeList(t *testing.T) {
+	t.Parallel()
+	fx := apiTestFx()
+	fx.WorktreeStatuses["/test/repo/.sprout/feature"] = git.WorktreeStatus{Ahead: 3}
+	fx.WorktreeMetadata["/test/repo/.sprout/feature"] = sprout.WorktreeMeta{Path: "/test/repo/.sprout/feature", Base: "origin/main"}
+
+	worktrees, err := APIWorktreeList(fx)
+	require.NoError(t, err)
+	assert.Equal(t, []core.APIWorktree{
+		{Path: "/test/repo", Branch: "main", Main: true, Status: &core.APIStatus{}},
+		{Path: "/test/repo/.sprout/feature", Branch: "feature", Base: "origin/main", Status: &core.APIStatus{Ahead: 3}},
+	}, worktrees)
+	assert.Equal(t, 1, fx.CacheStatusesCalls, "keeps the prompt cache current like sprout list")
+}
@@ -0,0 +1,5 @@
+// Package build gathers the inputs of sprout's plans through the Effects
+// interface: the context builders of the imperative shell that the CLI in
+// cmd and the Go library in pkg/sproutclient share. Nothing here reads
+// command-line flags; callers pass them in as options.
+package build
@@ -0,0 +1,146 @@
+package build
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+)
+
+// AssignTickets annotates worktrees created with --ticket with the ticket
+// summary. Tickets are best-effort: an unreadable store shows no tickets.
+func AssignTickets(fx effects.Effects, repos []core.RepoDisplay) {
+	recorded, err := fx.LoadTickets()
+	if err != nil || len(recorded) == 0 {
+		return
+	}
+	for i := range repos {
+		for j := range repos[i].Worktrees {
+			if ticket, ok := recorded[repos[i].Worktrees[j].Path]; ok {
+				repos[i].Worktrees[j].Ticket = ticket.Summary()
+			}
+		}
+	}
+}
+
+// AssignPins marks pinned worktrees, which are listed first, and the time
+// temporary ones have left. Like tickets, both are best-effort.
+func AssignPins(fx effects.Effects, repos []core.RepoDisplay) {
+	metadata, err := fx.LoadWorktreeMetadata()
+	if err != nil || len(metadata) == 0 {
+		return
+	}
+	for i := range repos {
+		for j := range repos[i].Worktrees {
+			wt := &repos[i].Worktrees[j]
+			meta := metadata[wt.Path]
+			wt.Pinned = meta.Pinned
+			if !meta.ExpiresAt.IsZero() {
+				wt.Temporary = true
+				wt.ExpiresIn = time.Until(meta.ExpiresAt)
+			}
+		}
+	}
+}
+
+// AssignDetails annotates worktrees with the ref their branch was created
+// from and their note, where recorded. Like tickets, they are best-effort.
+func AssignDetails(fx effects.Effects, repos []core.RepoDisplay) {
+	metadata, err := fx.LoadWorktreeMetadata()
+	if err != nil || len(metadata) == 0 {
+		return
+	}
+	for i := range repos {
+		for j := range repos[i].Worktrees {
+			meta := metadata[repos[i].Worktrees[j].Path]
+			repos[i].Worktrees[j].Base = meta.Base
+			repos[i].Worktrees[j].Note = meta.Note
+		}
+	}
+}
+
+// CacheStatuses saves the statuses just computed for 'sprout prompt'.
+// The cache is best-effort: failing to write it doesn't fail the listing.
+func CacheStatuses(fx effects.Effects, repos []core.RepoDisplay) {
+	statuses := make(map[string]git.WorktreeStatus)
+	for _, repo := range repos {
+		for _, wt := range repo.Worktrees {
+			statuses[wt.Path] = wt.Status
+		}
+	}
+	if len(statuses) > 0 {
+		_ = fx.CacheStatuses(statuses)
+	}
+}
+
+// RepoDisplay creates a RepoDisplay with parallel status collection.
+func RepoDisplay(fx effects.Effects, name string, mainWorktree git.Worktree, sproutWorktrees []git.Worktree) core.RepoDisplay {
+	totalWorktrees := 1 + len(sproutWorktrees)
+	worktrees := make([]core.WorktreeDisplayItem, totalWorktrees)
+	var wg sync.WaitGroup
+
+	// Collect status for main worktree
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		worktrees[0] = core.WorktreeDisplayItem{
+			Branch: mainWorktree.Branch,
+			Path:   mainWorktree.Path,
+			Status: fx.GetWorktreeStatus(mainWorktree.Path),
+			IsMain: true,
+		}
+	}()
+
+	// Collect status for sprout worktrees
+	sproutRoot, _ := fx.GetSproutRoot()
+	for i, wt := range sproutWorktrees {
+		wg.Add(1)
+		go func(idx int, worktree git.Worktree) {
+			defer wg.Done()
+			item := core.WorktreeDisplayItem{
+				Branch: worktree.Branch,
+				Path:   worktree.Path,
+				Status: fx.GetWorktreeStatus(worktree.Path),
+				IsMain: false,
+				Locked: worktree.Locked,
+			}
+			if worktree.Branch == "" && sproutRoot != "" && core.IsUnderSproutRoot(worktree.Path, sproutRoot) {
+				item.Label = core.DetachedLabel(worktree.Path)
+				item.Commit = worktree.HEAD
+			}
+			worktrees[idx+1] = item
+		}(i, wt)
+	}
+
+	wg.Wait()
+
+	return core.RepoDisplay{
+		Name:      name,
+		MainPath:  mainWorktree.Path,
+		Worktrees: worktrees,
+	}
+}
+
+// ExistingWorktrees filters out worktrees whose paths don't exist on the filesystem.
+func ExistingWorktrees(fx effects.Effects, worktrees []git.Worktree) []git.Worktree {
+	var existing []git.Worktree
+	for _, wt := range worktrees {
+		if effects.Exists(fx, wt.Path) {
+			existing = append(existing, wt)
+		}
+	}
+	return existing
+}
+
+// MainBranch returns the branch checked out in the main worktree, if any.
+func MainBranch(worktrees []git.Worktree, mainWorktreePath string) string {
+	for _, wt := range worktrees {
+		if filepath.Clean(wt.Path) == filepath.Clean(mainWorktreePath) {
+			return wt.Branch
+		}
+	}
+	return ""
+}
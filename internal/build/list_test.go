@@ -0,0 +1,56 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test helper to create directories, fails test on error
+func mustMkdirAll(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(path, 0755); err != nil {
+		t.Fatalf("failed to create directory %s: %v", path, err)
+	}
+}
+
+// Test helper to create files, fails test on error
+func mustWriteFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write file %s: %v", path, err)
+	}
+}
+
+func TestExistingWorktrees(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+
+	// Create some existing paths
+	existingPath1 := filepath.Join(tmpDir, "existing1")
+	existingPath2 := filepath.Join(tmpDir, "existing2")
+	nonExistingPath := filepath.Join(tmpDir, "nonexistent")
+
+	mustMkdirAll(t, existingPath1)
+	mustMkdirAll(t, existingPath2)
+
+	worktrees := []git.Worktree{
+		{Path: existingPath1, Branch: "branch1"},
+		{Path: nonExistingPath, Branch: "branch2"},
+		{Path: existingPath2, Branch: "branch3"},
+	}
+
+	fx := effects.NewTestEffects()
+	fx.Files[existingPath1] = true
+	fx.Files[existingPath2] = true
+	result := ExistingWorktrees(fx, worktrees)
+
+	assert.Len(t, result, 2, "should filter out non-existing path")
+	assert.Equal(t, existingPath1, result[0].Path, "should preserve order")
+	assert.Equal(t, existingPath2, result[1].Path, "should preserve order")
+}
@@ -0,0 +1,157 @@
+package build
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+)
+
+// RemoveContext gathers all inputs needed for the remove command.
+//
+// It handles three input modes:
+// - Interactive selection if no argument provided
+// - Branch name lookup (tries to match against worktree branches)
+// - Direct path (if argument is an existing file/directory)
+//
+// Path vs branch disambiguation: If the argument exists as a file/directory,
+// it's treated as a path; otherwise it's treated as a branch name. This means
+// a branch name that matches a file in CWD will be interpreted as a path.
+// This is acceptable for a worktree tool where explicit paths are uncommon.
+func RemoveContext(fx effects.Effects, args []string, force bool) (core.RemoveContext, error) {
+	// Get repository root
+	repoRoot, err := fx.GetRepoRoot()
+	if err != nil {
+		return core.RemoveContext{}, fmt.Errorf("failed to get repository root: %w", err)
+	}
+
+	// Get main worktree path - the repo identity used for sprout paths,
+	// so removal works the same from the main checkout and from any worktree
+	mainWorktreePath, err := fx.GetMainWorktreePath()
+	if err != nil {
+		return core.RemoveContext{}, fmt.Errorf("failed to get main worktree: %w", err)
+	}
+
+	// Get sprout root
+	sproutRoot, err := fx.GetWorktreeRoot(mainWorktreePath)
+	if err != nil {
+		return core.RemoveContext{}, fmt.Errorf("failed to get sprout root: %w", err)
+	}
+
+	// Get all worktrees
+	worktrees, err := fx.ListWorktrees(repoRoot)
+	if err != nil {
+		return core.RemoveContext{}, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	adopted, err := fx.ListAdoptedWorktrees()
+	if err != nil {
+		return core.RemoveContext{}, fmt.Errorf("failed to load adopted worktrees: %w", err)
+	}
+
+	// Filter to sprout-managed worktrees
+	sproutWorktrees := core.FilterSproutWorktrees(worktrees, sproutRoot, adopted...)
+
+	var targetPath string
+	var argProvided bool
+	var arg string
+
+	if len(args) == 0 {
+		// Interactive mode
+		if len(sproutWorktrees) == 0 {
+			return core.RemoveContext{}, core.ErrNoSproutWorktrees
+		}
+
+		idx, err := fx.SelectWorktree(sproutWorktrees)
+		if errors.Is(err, effects.ErrNonInteractive) {
+			return core.RemoveContext{}, fmt.Errorf("no worktree given: %w", err)
+		}
+		if err != nil {
+			return core.RemoveContext{}, core.ErrSelectionCancelled
+		}
+		targetPath = sproutWorktrees[idx].Path
+
+	} else {
+		// Argument provided
+		argProvided = true
+		arg = args[0]
+
+		// Disambiguate: path (if exists) vs branch name
+		if effects.Exists(fx, arg) {
+			targetPath = arg
+		} else {
+			// Assume it's a branch - search for it in worktrees
+			targetPath, err = findWorktreeToRemove(fx, worktrees, sproutRoot, arg, adopted)
+			if err != nil {
+				return core.RemoveContext{}, err
+			}
+		}
+	}
+
+	return core.RemoveContext{
+		ArgProvided: argProvided,
+		Arg:         arg,
+		RepoRoot:    repoRoot,
+		SproutRoot:  sproutRoot,
+		Worktrees:   worktrees,
+		Adopted:     adopted,
+		TargetPath:  targetPath,
+		Force:       force,
+	}, nil
+}
+
+// findWorktreeToRemove is FindWorktreeByBranch for remove, which can't be
+// undone: a branch that only abbreviates the one of the worktree found is
+// confirmed first, even with --force, and non-interactively it is an error.
+func findWorktreeToRemove(fx effects.Effects, worktrees []git.Worktree, sproutRoot, branch string, adopted []string) (string, error) {
+	if path, ok := core.FindWorktreeByBranch(worktrees, sproutRoot, branch, adopted...); ok {
+		return path, nil
+	}
+	matches := core.MatchWorktreesByBranch(worktrees, sproutRoot, branch, adopted...)
+	if len(matches) != 1 {
+		// Not found, or the user picks which one
+		return FindWorktreeByBranch(fx, worktrees, sproutRoot, branch, adopted)
+	}
+
+	match := matches[0]
+	name := match.Branch
+	if name == "" {
+		name = core.DetachedLabel(match.Path)
+	}
+	ok, err := fx.Confirm(fmt.Sprintf("'%s' matches %s at %s. Remove it?", branch, name, match.Path))
+	if errors.Is(err, effects.ErrNonInteractive) {
+		return "", fmt.Errorf("'%s' only abbreviates %s; give the full branch name to remove it", branch, name)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", core.ErrSelectionCancelled
+	}
+	return match.Path, nil
+}
+
+// FindWorktreeByBranch finds the sprout-managed worktree a branch names,
+// which may be abbreviated (see core.MatchWorktreesByBranch). When it
+// matches more than one, the user picks, or non-interactively the error
+// lists them.
+func FindWorktreeByBranch(fx effects.Effects, worktrees []git.Worktree, sproutRoot, branch string, adopted []string) (string, error) {
+	matches := core.MatchWorktreesByBranch(worktrees, sproutRoot, branch, adopted...)
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no sprout-managed worktree found for branch '%s'", branch)
+	case 1:
+		return matches[0].Path, nil
+	}
+
+	idx, err := fx.SelectWorktree(matches)
+	if errors.Is(err, effects.ErrNonInteractive) {
+		return "", core.AmbiguousBranchError(branch, matches)
+	}
+	if err != nil {
+		return "", fmt.Errorf("selection cancelled: %w", err)
+	}
+	return matches[idx].Path, nil
+}
@@ -0,0 +1,297 @@
+package build
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoveContext(t *testing.T) {
+	tests := []struct {
+		name       string
+		setupFx    func(*effects.TestEffects)
+		args       []string
+		force      bool
+		wantCtx    *core.RemoveContext
+		wantErr    bool
+		assertions func(t *testing.T, fx *effects.TestEffects)
+	}{
+		{
+			name: "explicit path argument",
+			setupFx: func(fx *effects.TestEffects) {
+				fx.RepoRoot = "/test/repo"
+				fx.WorktreeRoot = "/test/repo/.sprout"
+				fx.Worktrees = []git.Worktree{
+					{Path: "/test/repo", Branch: "main"},
+					{Path: "/test/repo/.sprout/feature", Branch: "feature"},
+				}
+				fx.Files["/test/repo/.sprout/feature"] = true
+			},
+			args:  []string{"/test/repo/.sprout/feature"},
+			force: false,
+			wantCtx: &core.RemoveContext{
+				ArgProvided: true,
+				Arg:         "/test/repo/.sprout/feature",
+				RepoRoot:    "/test/repo",
+				SproutRoot:  "/test/repo/.sprout",
+				TargetPath:  "/test/repo/.sprout/feature",
+				Force:       false,
+			},
+			wantErr: false,
+		},
+		{
+			name: "run from inside a worktree uses main worktree for sprout root",
+			setupFx: func(fx *effects.TestEffects) {
+				fx.RepoRoot = "/test/repo/.sprout/feature" // cwd is a sprout worktree
+				fx.MainWorktreePath = "/test/repo"
+				fx.WorktreeRoot = "/test/repo/.sprout"
+				fx.Worktrees = []git.Worktree{
+					{Path: "/test/repo", Branch: "main"},
+					{Path: "/test/repo/.sprout/feature", Branch: "feature"},
+					{Path: "/test/repo/.sprout/bugfix", Branch: "bugfix"},
+				}
+			},
+			args: []string{"bugfix"},
+			wantCtx: &core.RemoveContext{
+				ArgProvided: true,
+				Arg:         "bugfix",
+				RepoRoot:    "/test/repo/.sprout/feature",
+				SproutRoot:  "/test/repo/.sprout",
+				TargetPath:  "/test/repo/.sprout/bugfix",
+			},
+			assertions: func(t *testing.T, fx *effects.TestEffects) {
+				require.Len(t, fx.GetWorktreeRootArgs, 1)
+				assert.Equal(t, "/test/repo", fx.GetWorktreeRootArgs[0], "sprout root must be derived from the main worktree")
+			},
+		},
+		{
+			name: "branch name argument",
+			setupFx: func(fx *effects.TestEffects) {
+				fx.RepoRoot = "/test/repo"
+				fx.WorktreeRoot = "/test/repo/.sprout"
+				fx.Worktrees = []git.Worktree{
+					{Path: "/test/repo", Branch: "main"},
+					{Path: "/test/repo/.sprout/feature", Branch: "feature"},
+				}
+			},
+			args:  []string{"feature"},
+			force: true,
+			wantCtx: &core.RemoveContext{
+				ArgProvided: true,
+				Arg:         "feature",
+				RepoRoot:    "/test/repo",
+				SproutRoot:  "/test/repo/.sprout",
+				TargetPath:  "/test/repo/.sprout/feature",
+				Force:       true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "interactive selection",
+			setupFx: func(fx *effects.TestEffects) {
+				fx.RepoRoot = "/test/repo"
+				fx.WorktreeRoot = "/test/repo/.sprout"
+				fx.Worktrees = []git.Worktree{
+					{Path: "/test/repo", Branch: "main"},
+					{Path: "/test/repo/.sprout/feature", Branch: "feature"},
+					{Path: "/test/repo/.sprout/bugfix", Branch: "bugfix"},
+				}
+				fx.SelectedWorktreeIndex = 1 // Select "bugfix"
+			},
+			args:  []string{},
+			force: false,
+			wantCtx: &core.RemoveContext{
+				ArgProvided: false,
+				Arg:         "",
+				RepoRoot:    "/test/repo",
+				SproutRoot:  "/test/repo/.sprout",
+				TargetPath:  "/test/repo/.sprout/bugfix",
+				Force:       false,
+			},
+			wantErr: false,
+			assertions: func(t *testing.T, fx *effects.TestEffects) {
+				assert.Equal(t, 1, fx.SelectWorktreeCalls)
+			},
+		},
+		{
+			name: "interactive selection cancelled",
+			setupFx: func(fx *effects.TestEffects) {
+				fx.RepoRoot = "/test/repo"
+				fx.WorktreeRoot = "/test/repo/.sprout"
+				fx.Worktrees = []git.Worktree{
+					{Path: "/test/repo/.sprout/feature", Branch: "feature"},
+				}
+				fx.SelectionError = fmt.Errorf("cancelled")
+			},
+			args:    []string{},
+			force:   false,
+			wantCtx: nil,
+			wantErr: true,
+		},
+		{
+			name: "no sprout worktrees for interactive",
+			setupFx: func(fx *effects.TestEffects) {
+				fx.RepoRoot = "/test/repo"
+				fx.WorktreeRoot = "/test/repo/.sprout"
+				fx.Worktrees = []git.Worktree{
+					{Path: "/test/repo", Branch: "main"},
+				}
+			},
+			args:    []string{},
+			force:   false,
+			wantCtx: nil,
+			wantErr: true,
+		},
+		{
+			name: "branch not found",
+			setupFx: func(fx *effects.TestEffects) {
+				fx.RepoRoot = "/test/repo"
+				fx.WorktreeRoot = "/test/repo/.sprout"
+				fx.Worktrees = []git.Worktree{
+					{Path: "/test/repo/.sprout/feature", Branch: "feature"},
+				}
+			},
+			args:    []string{"nonexistent"},
+			force:   false,
+			wantCtx: nil,
+			wantErr: true,
+			assertions: func(t *testing.T, fx *effects.TestEffects) {
+				// Should not call SelectWorktree since arg was provided
+				assert.Equal(t, 0, fx.SelectWorktreeCalls)
+			},
+		},
+		{
+			name: "GetRepoRoot fails",
+			setupFx: func(fx *effects.TestEffects) {
+				fx.GetRepoRootErr = fmt.Errorf("not a git repo")
+			},
+			args:    []string{},
+			force:   false,
+			wantCtx: nil,
+			wantErr: true,
+		},
+		{
+			name: "GetWorktreeRoot fails",
+			setupFx: func(fx *effects.TestEffects) {
+				fx.RepoRoot = "/test/repo"
+				fx.GetWorktreeRootErr = fmt.Errorf("sprout root error")
+			},
+			args:    []string{},
+			force:   false,
+			wantCtx: nil,
+			wantErr: true,
+		},
+		{
+			name: "ListWorktrees fails",
+			setupFx: func(fx *effects.TestEffects) {
+				fx.RepoRoot = "/test/repo"
+				fx.WorktreeRoot = "/test/repo/.sprout"
+				fx.ListWorktreesErr = fmt.Errorf("git error")
+			},
+			args:    []string{},
+			force:   false,
+			wantCtx: nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fx := effects.NewTestEffects()
+			tt.setupFx(fx)
+
+			got, err := RemoveContext(fx, tt.args, tt.force)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, tt.wantCtx)
+
+				assert.Equal(t, tt.wantCtx.ArgProvided, got.ArgProvided)
+				assert.Equal(t, tt.wantCtx.Arg, got.Arg)
+				assert.Equal(t, tt.wantCtx.RepoRoot, got.RepoRoot)
+				assert.Equal(t, tt.wantCtx.SproutRoot, got.SproutRoot)
+				assert.Equal(t, tt.wantCtx.TargetPath, got.TargetPath)
+				assert.Equal(t, tt.wantCtx.Force, got.Force)
+				// Note: Worktrees field not checked here - it's passed through
+				// from fx.Worktrees but the specific content doesn't affect behavior
+			}
+
+			// Run custom assertions for both success and error paths
+			if tt.assertions != nil {
+				tt.assertions(t, fx)
+			}
+		})
+	}
+}
+
+func TestRemoveContext_NonInteractive(t *testing.T) {
+	t.Parallel()
+	fx := effects.NewTestEffects()
+	fx.WorktreeRoot = "/test/repo/.sprout"
+	fx.Worktrees = []git.Worktree{
+		{Path: "/test/repo/.sprout/feature", Branch: "feature"},
+	}
+	fx.SelectionError = fmt.Errorf("%w; pass a branch or path", effects.ErrNonInteractive)
+
+	_, err := RemoveContext(fx, []string{}, false)
+	assert.ErrorIs(t, err, effects.ErrNonInteractive, "not reported as a silent cancellation")
+	assert.NotErrorIs(t, err, core.ErrSelectionCancelled)
+}
+
+func TestRemoveContext_AbbreviatedBranch(t *testing.T) {
+	t.Parallel()
+	newFx := func() *effects.TestEffects {
+		fx := effects.NewTestEffects()
+		fx.WorktreeRoot = "/test/repo/.sprout"
+		fx.Worktrees = []git.Worktree{
+			{Path: "/test/repo", Branch: "main"},
+			{Path: "/test/repo/.sprout/feature/ABC-1234-login", Branch: "feature/ABC-1234-login"},
+		}
+		return fx
+	}
+
+	t.Run("exact branch needs no confirmation", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+
+		ctx, err := RemoveContext(fx, []string{"feature/ABC-1234-login"}, true)
+		require.NoError(t, err)
+		assert.Equal(t, "/test/repo/.sprout/feature/ABC-1234-login", ctx.TargetPath)
+		assert.Equal(t, 0, fx.ConfirmCalls)
+	})
+
+	t.Run("abbreviation is confirmed even with force", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.Confirmed = true
+
+		ctx, err := RemoveContext(fx, []string{"ABC-1234"}, true)
+		require.NoError(t, err)
+		assert.Equal(t, "/test/repo/.sprout/feature/ABC-1234-login", ctx.TargetPath)
+		assert.Equal(t, 1, fx.ConfirmCalls)
+	})
+
+	t.Run("declined", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+
+		_, err := RemoveContext(fx, []string{"ABC-1234"}, true)
+		assert.ErrorIs(t, err, core.ErrSelectionCancelled)
+	})
+
+	t.Run("non-interactive abbreviation is an error", func(t *testing.T) {
+		t.Parallel()
+		fx := newFx()
+		fx.ConfirmErr = effects.ErrNonInteractive
+
+		_, err := RemoveContext(fx, []string{"abc"}, true)
+		assert.EqualError(t, err, "'abc' only abbreviates feature/ABC-1234-login; give the full branch name to remove it")
+	})
+}
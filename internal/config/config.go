@@ -1,24 +1,126 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 
-	"gopkg.in/yaml.v3"
+	"github.com/m44rten1/sprout/internal/tickets"
 )
 
 // Config represents the structure of .sprout.yml
 type Config struct {
-	Hooks HooksConfig `yaml:"hooks"`
+	// Version is the format the file is written in (see CurrentVersion).
+	// Older formats are upgraded when loaded; 'sprout upgrade-config'
+	// rewrites the file.
+	Version int `yaml:"version"`
+	// Extends names config files whose settings this one builds on, such
+	// as hooks shared across an organization's repositories. Relative
+	// paths are resolved against the directory of this file (see Resolve).
+	Extends PathList `yaml:"extends"`
+	// Group labels the repository in 'sprout list --all' (e.g. "work").
+	// When unset, the name of the directory containing the repository is used.
+	Group string `yaml:"group"`
+	// BranchTemplate builds branch names for 'sprout add --ticket', e.g.
+	// "feat/{user}/{ticket}-{slug}".
+	BranchTemplate string `yaml:"branch_template"`
+	// TicketProvider names the issue tracker 'sprout add --ticket' fetches
+	// titles from: "jira" or "linear". Credentials come from the environment.
+	TicketProvider string `yaml:"ticket_provider"`
+	// TemplateDir is a directory whose contents are copied into every new
+	// worktree (editor settings, local overrides), relative to the main
+	// worktree and inside it.
+	TemplateDir string `yaml:"template_dir"`
+	// TemplateConflict decides what happens when a template file already
+	// exists in the new worktree: skip (default), overwrite or backup.
+	TemplateConflict ConflictPolicy `yaml:"template_conflict"`
+	// Share lists heavy directories (node_modules, .venv) of the main
+	// worktree that new worktrees reuse instead of rebuilding, relative
+	// to the worktree root.
+	Share []string `yaml:"share"`
+	// ShareMode is how shared directories are reused: symlink (default)
+	// or clone (copy-on-write, falling back to symlink where unsupported).
+	ShareMode ShareMode `yaml:"share_mode"`
+	// Artifacts lists build output directories (target, .next/cache) that
+	// sprout add --clone-artifacts clones from the main worktree so builds
+	// in the new worktree start warm.
+	Artifacts []string `yaml:"artifacts"`
+	// MaxWorktrees caps the sprout worktrees of the repository; sprout add
+	// warns beyond it (0 means no limit).
+	MaxWorktrees int `yaml:"max_worktrees"`
+	// DefaultBranch names the branch work is merged into (e.g. trunk or
+	// develop) when git can't tell from origin/HEAD. Unmerged commits are
+	// counted against it on origin, and hooks get it as SPROUT_BASE_BRANCH.
+	DefaultBranch string `yaml:"default_branch"`
+	// OpenEditor set to false keeps sprout add and open from launching the
+	// editor for this repository; open prints the path instead. Unset
+	// falls back to the user config.
+	OpenEditor *bool       `yaml:"open_editor"`
+	Hooks      HooksConfig `yaml:"hooks"`
 }
 
+// ShareMode is how a shared directory is brought into a new worktree.
+type ShareMode string
+
+// Share modes for Config.ShareMode.
+const (
+	ShareSymlink ShareMode = "symlink" // Link to the main worktree's directory
+	ShareClone   ShareMode = "clone"   // Copy-on-write clone (APFS, btrfs, XFS)
+)
+
+// ConflictPolicy decides what to do when a file to be copied already exists.
+type ConflictPolicy string
+
+// Conflict policies for Config.TemplateConflict.
+const (
+	ConflictSkip      ConflictPolicy = "skip"      // Keep the existing file
+	ConflictOverwrite ConflictPolicy = "overwrite" // Replace it
+	ConflictBackup    ConflictPolicy = "backup"    // Move it aside to <name>.orig, then copy
+)
+
 // HooksConfig defines the hook configuration
 type HooksConfig struct {
 	OnCreate []string `yaml:"on_create"`
 	OnOpen   []string `yaml:"on_open"`
+	// PostSync runs in each worktree 'sprout rebase-all' updated, to
+	// reinstall dependencies or migrate after taking in new commits.
+	PostSync []string `yaml:"post_sync"`
+	// Output is how much of the commands' output is shown: full
+	// (default), summary or quiet.
+	Output HookOutput `yaml:"output"`
+	// Merge is how the hooks combine with those of the files extended:
+	// append (default) runs theirs first, replace runs only these for
+	// each hook type set here.
+	Merge HookMerge `yaml:"merge"`
+	// DefaultHooks set to false keeps the default_hooks of the user config
+	// (see UserConfig.DefaultHooks) from running in this repository.
+	DefaultHooks *bool `yaml:"default_hooks"`
 }
 
+// HookMerge is how hooks combine with the hooks of extended config files.
+type HookMerge string
+
+// Merge modes for HooksConfig.Merge.
+const (
+	HookMergeAppend  HookMerge = "append"  // Their commands, then these
+	HookMergeReplace HookMerge = "replace" // These commands instead of theirs
+)
+
+// HookOutput is how much hook commands print while they run.
+type HookOutput string
+
+// Output modes for HooksConfig.Output.
+const (
+	HookOutputFull    HookOutput = "full"    // Pass output through as it is written
+	HookOutputSummary HookOutput = "summary" // One line per command with its duration; output only on failure
+	HookOutputQuiet   HookOutput = "quiet"   // Nothing unless a command fails
+)
+
 // Load loads the .sprout.yml configuration with fallback support.
 // It first checks currentPath for a worktree-specific config, then falls back
 // to mainWorktreePath for a shared config (useful for gitignored configs).
@@ -50,41 +152,143 @@ func Load(currentPath, mainWorktreePath string) (*Config, error) {
 		}
 	}
 
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	// Relative extends mean the same files from every worktree
+	dir := filepath.Dir(configPath)
+	if mainWorktreePath != "" {
+		dir = mainWorktreePath
+	}
+	return ResolveIn(configPath, dir, data, os.ReadFile)
+}
+
+// Parse decodes and validates the config file at path, upgrading older
+// formats in memory. Unknown keys are errors. Every problem found is
+// reported, each pointing at its line where possible.
+func Parse(path string, data []byte) (*Config, error) {
+	doc, err := parseDocument(data)
+	if err != nil {
+		return nil, locate(path, nil, err)
+	}
+	if _, err := migrate(doc); err != nil {
+		return nil, locate(path, nil, err)
 	}
 
-	// Validate config
-	if err := cfg.Validate(); err != nil {
-		return nil, err
+	errs := unknownKeys(doc.Content[0], reflect.TypeOf(Config{}), "")
+	var cfg Config
+	if err := doc.Decode(&cfg); err != nil {
+		errs = append(errs, yamlError(err))
+	} else if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, locate(path, doc.Content[0], errors.Join(errs...))
 	}
 
 	return &cfg, nil
 }
 
-// Validate checks if the config is valid
+// Validate checks if the config is valid, reporting every problem found
 func (c *Config) Validate() error {
+	var errs []error
+
+	switch c.TicketProvider {
+	case "", tickets.ProviderJira, tickets.ProviderLinear:
+	default:
+		errs = append(errs, fieldError("ticket_provider", "%q is not supported (use jira or linear)", c.TicketProvider))
+	}
+
+	switch c.TemplateConflict {
+	case "", ConflictSkip, ConflictOverwrite, ConflictBackup:
+	default:
+		errs = append(errs, fieldError("template_conflict", "%q is not supported (use skip, overwrite or backup)", c.TemplateConflict))
+	}
+
+	switch c.ShareMode {
+	case "", ShareSymlink, ShareClone:
+	default:
+		errs = append(errs, fieldError("share_mode", "%q is not supported (use symlink or clone)", c.ShareMode))
+	}
+	if c.DefaultBranch != "" && (strings.ContainsAny(c.DefaultBranch, " \t") || strings.HasPrefix(c.DefaultBranch, "-")) {
+		errs = append(errs, fieldError("default_branch", "%q is not a valid branch name", c.DefaultBranch))
+	}
+	if strings.HasPrefix(c.DefaultBranch, "origin/") {
+		errs = append(errs, fieldError("default_branch", "should name the branch without the remote, e.g. %q", strings.TrimPrefix(c.DefaultBranch, "origin/")))
+	}
+	if c.MaxWorktrees < 0 {
+		errs = append(errs, fieldError("max_worktrees", "must not be negative, got %d", c.MaxWorktrees))
+	}
+	errs = append(errs, validateWorktreePaths("share", c.Share)...)
+	errs = append(errs, validateWorktreePaths("artifacts", c.Artifacts)...)
+	// A repository that isn't trusted can't have files from elsewhere copied
+	// in; ~ is rejected as well, since it used to name the home directory
+	if c.TemplateDir != "" && (!insideWorktree(c.TemplateDir) || strings.HasPrefix(c.TemplateDir, "~")) {
+		errs = append(errs, fieldError("template_dir", "must be a path inside the repository, got %q", c.TemplateDir))
+	}
+
+	switch c.Hooks.Output {
+	case "", HookOutputFull, HookOutputSummary, HookOutputQuiet:
+	default:
+		errs = append(errs, fieldError("hooks.output", "%q is not supported (use full, summary or quiet)", c.Hooks.Output))
+	}
+
+	for i, path := range c.Extends {
+		if path == "" {
+			errs = append(errs, fieldError(fmt.Sprintf("extends[%d]", i), "is empty"))
+		}
+	}
+	switch c.Hooks.Merge {
+	case "", HookMergeAppend, HookMergeReplace:
+		if c.Hooks.Merge != "" && len(c.Extends) == 0 {
+			errs = append(errs, fieldError("hooks.merge", "only applies to a config that extends another: it says whether these hooks run after the extended ones (append) or instead of them (replace)"))
+		}
+	default:
+		errs = append(errs, fieldError("hooks.merge", "%q is not supported (use append to run these hooks after the extended ones, or replace to run them instead)", c.Hooks.Merge))
+	}
+
 	// Check that on_create commands are strings
 	for i, cmd := range c.Hooks.OnCreate {
 		if cmd == "" {
-			return fmt.Errorf("on_create[%d] is empty", i)
+			errs = append(errs, fieldError(fmt.Sprintf("hooks.on_create[%d]", i), "is empty"))
 		}
 	}
 
 	// Check that on_open commands are strings
 	for i, cmd := range c.Hooks.OnOpen {
 		if cmd == "" {
-			return fmt.Errorf("on_open[%d] is empty", i)
+			errs = append(errs, fieldError(fmt.Sprintf("hooks.on_open[%d]", i), "is empty"))
+		}
+	}
+
+	for i, cmd := range c.Hooks.PostSync {
+		if cmd == "" {
+			errs = append(errs, fieldError(fmt.Sprintf("hooks.post_sync[%d]", i), "is empty"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateWorktreePaths checks that every entry of a config list is a
+// relative path that stays inside the worktree.
+func validateWorktreePaths(field string, paths []string) []error {
+	var errs []error
+	for i, dir := range paths {
+		if !insideWorktree(dir) {
+			errs = append(errs, fieldError(fmt.Sprintf("%s[%d]", field, i), "must be a path inside the worktree, got %q", dir))
 		}
 	}
+	return errs
+}
 
-	return nil
+// insideWorktree reports whether dir is a relative path below the worktree
+// it is resolved against.
+func insideWorktree(dir string) bool {
+	clean := filepath.Clean(dir)
+	return dir != "" && !filepath.IsAbs(dir) && clean != "." && clean != ".." && !strings.HasPrefix(clean, ".."+string(filepath.Separator))
 }
 
 // HasHooks returns true if any hooks are defined
 func (c *Config) HasHooks() bool {
-	return len(c.Hooks.OnCreate) > 0 || len(c.Hooks.OnOpen) > 0
+	return len(c.Hooks.OnCreate) > 0 || len(c.Hooks.OnOpen) > 0 || len(c.Hooks.PostSync) > 0
 }
 
 // HasCreateHooks returns true if on_create hooks are defined
@@ -96,3 +300,45 @@ func (c *Config) HasCreateHooks() bool {
 func (c *Config) HasOpenHooks() bool {
 	return len(c.Hooks.OnOpen) > 0
 }
+
+// HasSyncHooks returns true if post_sync hooks are defined
+func (c *Config) HasSyncHooks() bool {
+	return len(c.Hooks.PostSync) > 0
+}
+
+// HookCommands returns the commands of each hook type that has any, keyed
+// by its name in .sprout.yml (e.g. "on_create").
+func (c *Config) HookCommands() map[string][]string {
+	hooks := map[string][]string{}
+	for name, commands := range map[string][]string{
+		"on_create": c.Hooks.OnCreate,
+		"on_open":   c.Hooks.OnOpen,
+		"post_sync": c.Hooks.PostSync,
+	} {
+		if len(commands) > 0 {
+			hooks[name] = commands
+		}
+	}
+	return hooks
+}
+
+// HooksHash returns a stable digest of the hook commands ("sha256:<hex>"), so
+// tools can detect when the configured hooks change.
+func (c *Config) HooksHash() string {
+	hooks := map[string][]string{
+		"on_create": c.Hooks.OnCreate,
+		"on_open":   c.Hooks.OnOpen,
+	}
+	for k, v := range hooks {
+		if v == nil {
+			hooks[k] = []string{}
+		}
+	}
+	// Added later, so only when set: hashes of older configs stay the same
+	if len(c.Hooks.PostSync) > 0 {
+		hooks["post_sync"] = c.Hooks.PostSync
+	}
+	// Maps marshal with sorted keys, so the encoding is deterministic
+	data, _ := json.Marshal(hooks)
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PathList is a list of paths that may also be written as a single one:
+//
+//	extends: ../shared/.sprout-base.yml
+type PathList []string
+
+// UnmarshalYAML accepts a single path as well as a list of them.
+func (l *PathList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = PathList{value.Value}
+		return nil
+	}
+	var paths []string
+	if err := value.Decode(&paths); err != nil {
+		return err
+	}
+	*l = paths
+	return nil
+}
+
+// Resolve parses the config file at path like Parse, and the files it
+// extends, which read reads, and merges them into one config. Files extended
+// later take precedence over earlier ones, and the file itself over all:
+//
+//   - Settings that take one value keep the last one set.
+//   - Lists of directories (share, artifacts) add up, without duplicates.
+//   - Hooks run the extended commands first, unless hooks.merge is replace:
+//     then each hook type set in the file runs only its own commands.
+//
+// A file that extends itself, directly or through others, is an error.
+func Resolve(path string, data []byte, read func(path string) ([]byte, error)) (*Config, error) {
+	return ResolveIn(path, filepath.Dir(path), data, read)
+}
+
+// ResolveIn is Resolve with the relative paths the file at path extends
+// resolved against dir. A worktree's .sprout.yml is a copy of the main
+// worktree's, so its extends mean the same files as there; those extended
+// files resolve theirs against their own directory.
+func ResolveIn(path, dir string, data []byte, read func(path string) ([]byte, error)) (*Config, error) {
+	return resolve(path, dir, data, read, nil)
+}
+
+func resolve(path, dir string, data []byte, read func(string) ([]byte, error), chain []string) (*Config, error) {
+	cfg, err := Parse(path, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Extends) == 0 {
+		return cfg, nil
+	}
+	chain = append(chain, filepath.Clean(path))
+
+	var root *yaml.Node
+	if doc, err := parseDocument(data); err == nil {
+		root = doc.Content[0]
+	}
+	base := &Config{}
+	for i, extended := range cfg.Extends {
+		field := fmt.Sprintf("extends[%d]", i)
+		extendedPath := extended
+		if !filepath.IsAbs(extendedPath) {
+			extendedPath = filepath.Join(dir, extendedPath)
+		}
+		extendedPath = filepath.Clean(extendedPath)
+
+		if slices.Contains(chain, extendedPath) {
+			cycle := strings.Join(append(chain, extendedPath), " → ")
+			return nil, locate(path, root, fieldError(field, "makes a cycle: %s", cycle))
+		}
+		extendedData, err := read(extendedPath)
+		if err != nil {
+			return nil, locate(path, root, fieldError(field, "can't be read: %v", err))
+		}
+		extendedCfg, err := resolve(extendedPath, filepath.Dir(extendedPath), extendedData, read, chain)
+		if err != nil {
+			return nil, err
+		}
+		base = merge(base, extendedCfg)
+	}
+	return merge(base, cfg), nil
+}
+
+// merge returns base with the settings of cfg applied on top (see Resolve).
+func merge(base, cfg *Config) *Config {
+	merged := *cfg
+	pick := func(own, inherited string) string {
+		if own != "" {
+			return own
+		}
+		return inherited
+	}
+	merged.Group = pick(cfg.Group, base.Group)
+	merged.BranchTemplate = pick(cfg.BranchTemplate, base.BranchTemplate)
+	merged.TicketProvider = pick(cfg.TicketProvider, base.TicketProvider)
+	merged.TemplateDir = pick(cfg.TemplateDir, base.TemplateDir)
+	merged.TemplateConflict = ConflictPolicy(pick(string(cfg.TemplateConflict), string(base.TemplateConflict)))
+	merged.ShareMode = ShareMode(pick(string(cfg.ShareMode), string(base.ShareMode)))
+	merged.DefaultBranch = pick(cfg.DefaultBranch, base.DefaultBranch)
+	merged.Hooks.Output = HookOutput(pick(string(cfg.Hooks.Output), string(base.Hooks.Output)))
+	if cfg.MaxWorktrees == 0 {
+		merged.MaxWorktrees = base.MaxWorktrees
+	}
+	if cfg.OpenEditor == nil {
+		merged.OpenEditor = base.OpenEditor
+	}
+	if cfg.Hooks.DefaultHooks == nil {
+		merged.Hooks.DefaultHooks = base.Hooks.DefaultHooks
+	}
+	merged.Share = union(base.Share, cfg.Share)
+	merged.Artifacts = union(base.Artifacts, cfg.Artifacts)
+
+	hooks := func(own, inherited []string) []string {
+		// A hook type that is set, even to [], replaces the inherited one
+		if cfg.Hooks.Merge == HookMergeReplace && own != nil {
+			return own
+		}
+		return append(slices.Clone(inherited), own...)
+	}
+	merged.Hooks.OnCreate = hooks(cfg.Hooks.OnCreate, base.Hooks.OnCreate)
+	merged.Hooks.OnOpen = hooks(cfg.Hooks.OnOpen, base.Hooks.OnOpen)
+	merged.Hooks.PostSync = hooks(cfg.Hooks.PostSync, base.Hooks.PostSync)
+	return &merged
+}
+
+// union returns the entries of a, then those of b that a doesn't have.
+func union(a, b []string) []string {
+	result := slices.Clone(a)
+	for _, s := range b {
+		if !slices.Contains(result, s) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigResolve(t *testing.T) {
+	files := map[string]string{
+		"/shared/base.yml": "version: 1\nshare_mode: clone\nshare:\n  - node_modules\nhooks:\n  on_create:\n    - npm ci\n  on_open:\n    - make dev\n",
+	}
+	read := func(path string) ([]byte, error) {
+		data, ok := files[path]
+		if !ok {
+			return nil, fs.ErrNotExist
+		}
+		return []byte(data), nil
+	}
+
+	t.Run("append", func(t *testing.T) {
+		cfg, err := Resolve("/repo/.sprout.yml", []byte("version: 1\nextends: ../shared/base.yml\nshare:\n  - node_modules\n  - .venv\nhooks:\n  on_create:\n    - make db\n"), read)
+		require.NoError(t, err)
+		assert.Equal(t, ShareClone, cfg.ShareMode)
+		assert.Equal(t, []string{"node_modules", ".venv"}, cfg.Share)
+		assert.Equal(t, []string{"npm ci", "make db"}, cfg.Hooks.OnCreate)
+		assert.Equal(t, []string{"make dev"}, cfg.Hooks.OnOpen)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		cfg, err := Resolve("/repo/.sprout.yml", []byte("version: 1\nextends: ../shared/base.yml\nhooks:\n  merge: replace\n  on_create:\n    - make db\n  on_open: []\n"), read)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"make db"}, cfg.Hooks.OnCreate)
+		assert.Empty(t, cfg.Hooks.OnOpen)
+	})
+}
+
+func TestLoad_ExtendsFromWorktree(t *testing.T) {
+	root := t.TempDir()
+	main := filepath.Join(root, "code", "repo")
+	worktree := filepath.Join(root, "sprout", "feature", "repo")
+	for path, data := range map[string]string{
+		filepath.Join(root, "code", "shared", "base.yml"): "hooks:\n  on_create:\n    - npm ci\n",
+		filepath.Join(main, ".sprout.yml"):                "extends: ../shared/base.yml\n",
+		filepath.Join(worktree, ".sprout.yml"):            "extends: ../shared/base.yml\nhooks:\n  on_open:\n    - make dev\n",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+	}
+
+	cfg, err := Load(worktree, main)
+	require.NoError(t, err, "relative extends resolve against the main worktree, not the worktree's own directory")
+	assert.Equal(t, []string{"npm ci"}, cfg.Hooks.OnCreate)
+	assert.Equal(t, []string{"make dev"}, cfg.Hooks.OnOpen)
+
+	cfg, err = Load(main, main)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"npm ci"}, cfg.Hooks.OnCreate)
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/m44rten1/sprout/internal/tickets"
+)
+
+// schemaDescriptions documents each setting in the JSON Schema, keyed by
+// its YAML path. Every field of Config needs one (see SchemaFields).
+var schemaDescriptions = map[string]string{
+	"version":             fmt.Sprintf("Config format version. The newest is %d; 'sprout upgrade-config' rewrites older files.", CurrentVersion),
+	"extends":             "Config files this one builds on, such as hooks shared by several repositories. Relative paths are resolved against the directory of this file.",
+	"group":               "Label for the repository in 'sprout list --all'. Defaults to the name of the directory containing the repository.",
+	"branch_template":     "Branch names for 'sprout add --ticket', e.g. \"feat/{user}/{ticket}-{slug}\".",
+	"ticket_provider":     "Issue tracker 'sprout add --ticket' fetches titles from. Credentials come from the environment.",
+	"template_dir":        "Directory copied into every new worktree, relative to the main worktree and inside it.",
+	"template_conflict":   "What to do when a template file already exists in the new worktree.",
+	"share":               "Directories of the main worktree (node_modules, .venv) that new worktrees reuse, relative to the worktree root.",
+	"share_mode":          "How shared directories are reused: a symlink, or a copy-on-write clone where supported.",
+	"artifacts":           "Build output directories cloned from the main worktree by 'sprout add --clone-artifacts'.",
+	"max_worktrees":       "Number of sprout worktrees beyond which 'sprout add' warns. 0 means no limit.",
+	"default_branch":      "Branch work is merged into, when git can't tell from origin/HEAD. Without the remote, e.g. \"develop\".",
+	"open_editor":         "Set to false to keep 'sprout add' and 'sprout open' from launching the editor.",
+	"hooks":               "Shell commands run in new or opened worktrees, once the repository is trusted.",
+	"hooks.on_create":     "Commands run after 'sprout add' creates a worktree.",
+	"hooks.on_open":       "Commands run when 'sprout open' opens a worktree.",
+	"hooks.post_sync":     "Commands run in each worktree 'sprout rebase-all' updated, e.g. to reinstall dependencies.",
+	"hooks.output":        "How much of the commands' output is shown. summary prints a line per command and the output of a failing one.",
+	"hooks.merge":         "How these hooks combine with those of the files extended: append runs theirs first, replace runs only these for each hook type set here.",
+	"hooks.default_hooks": "Set to false to skip the default_hooks of your user config in this repository.",
+}
+
+// schemaEnums lists the allowed values of settings that take one of a few.
+var schemaEnums = map[string][]string{
+	"ticket_provider":   {tickets.ProviderJira, tickets.ProviderLinear},
+	"template_conflict": {string(ConflictSkip), string(ConflictOverwrite), string(ConflictBackup)},
+	"share_mode":        {string(ShareSymlink), string(ShareClone)},
+	"hooks.output":      {string(HookOutputFull), string(HookOutputSummary), string(HookOutputQuiet)},
+	"hooks.merge":       {string(HookMergeAppend), string(HookMergeReplace)},
+}
+
+// Schema returns a JSON Schema for .sprout.yml, generated from Config so
+// that it always matches what sprout accepts.
+func Schema() map[string]any {
+	schema := typeSchema(reflect.TypeOf(Config{}), "")
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = ".sprout.yml"
+	schema["description"] = "Repository configuration for sprout, the Git worktree helper."
+	return schema
+}
+
+// SchemaFields returns the YAML paths of every setting in Config, nested
+// ones included, such as "hooks.on_create".
+func SchemaFields() []string {
+	return structFields(reflect.TypeOf(Config{}), "")
+}
+
+func structFields(t reflect.Type, prefix string) []string {
+	var paths []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := yamlKey(field)
+		if name == "" {
+			continue
+		}
+		path := joinField(prefix, name)
+		paths = append(paths, path)
+		if ft := derefType(field.Type); ft.Kind() == reflect.Struct {
+			paths = append(paths, structFields(ft, path)...)
+		}
+	}
+	return paths
+}
+
+// typeSchema describes values of type t at path.
+func typeSchema(t reflect.Type, path string) map[string]any {
+	t = derefType(t)
+	schema := map[string]any{}
+	if desc, ok := schemaDescriptions[path]; ok {
+		schema["description"] = desc
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := map[string]any{}
+		for name, field := range yamlFields(t) {
+			properties[name] = typeSchema(field.Type, joinField(path, name))
+		}
+		schema["type"] = "object"
+		schema["properties"] = properties
+		// Unknown keys are errors, as in Parse
+		schema["additionalProperties"] = false
+	case reflect.Slice:
+		items := typeSchema(t.Elem(), path+"[]")
+		if items["type"] == "string" {
+			items["minLength"] = 1 // Empty entries are rejected
+		}
+		schema["type"] = "array"
+		schema["items"] = items
+		if t == reflect.TypeOf(PathList{}) {
+			// A single path may be written without the list
+			delete(schema, "type")
+			delete(schema, "items")
+			schema["anyOf"] = []any{items, map[string]any{"type": "array", "items": items}}
+		}
+	case reflect.String:
+		schema["type"] = "string"
+		if values, ok := schemaEnums[path]; ok {
+			schema["enum"] = values
+		}
+	case reflect.Bool:
+		schema["type"] = "boolean"
+	case reflect.Int, reflect.Int64:
+		// No setting is ever negative
+		schema["type"] = "integer"
+		schema["minimum"] = 0
+		if path == "version" {
+			schema["maximum"] = CurrentVersion
+		}
+	}
+	return schema
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
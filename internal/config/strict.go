@@ -0,0 +1,109 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// unknownKeys reports every key of mapping that no field of t (a struct
+// type) decodes, so typos like "on_creat" fail loudly instead of being
+// ignored. Nested settings are checked too; prefix names the enclosing key.
+func unknownKeys(mapping *yaml.Node, t reflect.Type, prefix string) []error {
+	if mapping.Kind != yaml.MappingNode {
+		return nil // Decoding reports the wrong shape
+	}
+
+	fields := yamlFields(t)
+	var errs []error
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		field, ok := fields[key.Value]
+		if !ok {
+			msg := fmt.Sprintf("unknown key %q", key.Value)
+			if prefix != "" {
+				msg += " in " + prefix
+			}
+			if suggestion := closestKey(key.Value, fields); suggestion != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+			}
+			errs = append(errs, &lineError{line: key.Line, err: errors.New(msg)})
+			continue
+		}
+
+		ft := field.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct {
+			errs = append(errs, unknownKeys(value, ft, joinField(prefix, key.Value))...)
+		}
+	}
+	return errs
+}
+
+// yamlFields maps the YAML keys of struct type t to its fields.
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		if name := yamlKey(t.Field(i)); name != "" {
+			fields[name] = t.Field(i)
+		}
+	}
+	return fields
+}
+
+// yamlKey returns the key a struct field is decoded from, or "" if none.
+func yamlKey(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	if name == "-" || !f.IsExported() {
+		return ""
+	}
+	if name == "" {
+		name = strings.ToLower(f.Name)
+	}
+	return name
+}
+
+func joinField(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// closestKey returns the known key nearest to key, if it is close enough
+// to be a typo.
+func closestKey(key string, fields map[string]reflect.StructField) string {
+	best, bestDist := "", 3 // More than two edits away is not a typo
+	for name := range fields {
+		if d := editDistance(key, name); d < bestDist || d == bestDist && name < best {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
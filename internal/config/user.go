@@ -0,0 +1,255 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/m44rten1/sprout/internal/style"
+	"gopkg.in/yaml.v3"
+)
+
+// UserConfig represents per-user settings in ~/.config/sprout/config.yml.
+// Unlike .sprout.yml these are never read from a repository, so they may
+// safely control security-relevant behavior such as trust expiry.
+type UserConfig struct {
+	// TrustTTL is how long trust lasts before hooks require re-confirmation (e.g. "90d").
+	// Empty means trust never expires.
+	TrustTTL string `yaml:"trust_ttl"`
+
+	// RepoIdentity selects how repositories are identified: "path" (default)
+	// keys worktree directories by the repository's absolute path, "remote"
+	// by its normalized origin URL so moving a checkout keeps its worktrees.
+	RepoIdentity string `yaml:"repo_identity"`
+
+	// EditorFallbacks are editor commands tried in order after $SPROUT_EDITOR
+	// or $EDITOR fails (e.g. ["cursor", "code --new-window", "vim"]). When
+	// set, they replace the platform defaults.
+	EditorFallbacks []string `yaml:"editor_fallbacks"`
+
+	// OpenEditor set to false keeps sprout add and open from launching the
+	// editor, unless a repository's .sprout.yml says otherwise.
+	OpenEditor *bool `yaml:"open_editor"`
+
+	// NetworkAttempts is how often git fetch, pull and push are tried when
+	// they fail with a network error (0 means DefaultNetworkAttempts, 1
+	// disables retries).
+	NetworkAttempts int `yaml:"network_attempts"`
+
+	// StatusCounts set to false shows bare ahead/behind arrows in list and
+	// the picker instead of commit counts such as ↑3 ↓1.
+	StatusCounts *bool `yaml:"status_counts"`
+
+	// Icons replace the glyphs that mark worktrees and their status; those
+	// not set keep their default.
+	Icons style.Icons `yaml:"icons"`
+
+	// ASCII set to true starts from plain ASCII icons (style.ASCIIIcons)
+	// instead of emoji and arrows. Icons still override them.
+	ASCII bool `yaml:"ascii"`
+
+	// DefaultHooks are commands run after creating any worktree (e.g.
+	// "direnv allow"), before the repository's on_create hooks. Being the
+	// user's own, they run without the repository being trusted. A
+	// repository opts out with hooks.default_hooks set to false.
+	DefaultHooks []string `yaml:"default_hooks"`
+
+	// EventsCommand is a shell command run with each event (a worktree
+	// created or removed, hooks completed) as JSON on stdin.
+	EventsCommand string `yaml:"events_command"`
+
+	// EventsSocket is a unix socket each event is written to as a line of JSON.
+	EventsSocket string `yaml:"events_socket"`
+
+	// Aliases name commands with their arguments, like git aliases:
+	// {a: "add --no-open"} makes 'sprout a feature' run 'sprout add
+	// --no-open feature'. The words are split on whitespace. Aliases
+	// never shadow built-in commands.
+	Aliases map[string]string `yaml:"aliases"`
+}
+
+// DefaultNetworkAttempts is used when network_attempts is not set.
+const DefaultNetworkAttempts = 3
+
+// Attempts returns the configured network attempts, or the default.
+func (c *UserConfig) Attempts() int {
+	if c == nil || c.NetworkAttempts == 0 {
+		return DefaultNetworkAttempts
+	}
+	return c.NetworkAttempts
+}
+
+// ShowsStatusCounts reports whether ahead/behind arrows carry commit
+// counts. Defaults to true.
+func (c *UserConfig) ShowsStatusCounts() bool {
+	if c == nil || c.StatusCounts == nil {
+		return true
+	}
+	return *c.StatusCounts
+}
+
+// StatusIcons returns the icons to use: the ones in Icons, and the
+// defaults (or the ASCII ones) for the rest.
+func (c *UserConfig) StatusIcons() style.Icons {
+	if c == nil {
+		return style.DefaultIcons
+	}
+	if c.ASCII {
+		return c.Icons.Or(style.ASCIIIcons)
+	}
+	return c.Icons.Or(style.DefaultIcons)
+}
+
+// Repository identity modes for UserConfig.RepoIdentity.
+const (
+	RepoIdentityPath   = "path"
+	RepoIdentityRemote = "remote"
+)
+
+// UsesRemoteIdentity reports whether repositories are keyed by origin URL.
+func (c *UserConfig) UsesRemoteIdentity() bool {
+	return c != nil && c.RepoIdentity == RepoIdentityRemote
+}
+
+// OpensEditor reports whether sprout add and open launch the editor. The
+// repository's open_editor takes precedence over the user's; both default
+// to true. Either config may be nil.
+func OpensEditor(repo *Config, user *UserConfig) bool {
+	if repo != nil && repo.OpenEditor != nil {
+		return *repo.OpenEditor
+	}
+	if user != nil && user.OpenEditor != nil {
+		return *user.OpenEditor
+	}
+	return true
+}
+
+// DefaultHooks returns the user's default_hooks to run in a new worktree of
+// repo, before its on_create hooks, or none if repo opts out. Either config
+// may be nil.
+func DefaultHooks(repo *Config, user *UserConfig) []string {
+	if user == nil || (repo != nil && repo.Hooks.DefaultHooks != nil && !*repo.Hooks.DefaultHooks) {
+		return nil
+	}
+	return user.DefaultHooks
+}
+
+// GetUserConfigDir returns the sprout config directory, respecting XDG_CONFIG_HOME.
+// The directory is created if it does not exist.
+func GetUserConfigDir() (string, error) {
+	var configDir string
+	if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
+		configDir = filepath.Join(xdgConfig, "sprout")
+	} else {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get user home directory: %w", err)
+		}
+		configDir = filepath.Join(home, ".config", "sprout")
+	}
+
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	return configDir, nil
+}
+
+// LoadUser loads the user config. Returns an empty config if the file doesn't exist.
+func LoadUser() (*UserConfig, error) {
+	configDir, err := GetUserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	configPath := filepath.Join(configDir, "config.yml")
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &UserConfig{}, nil
+		}
+		return nil, fmt.Errorf("failed to read user config: %w", err)
+	}
+
+	var cfg UserConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse user config %s: %w", configPath, err)
+	}
+
+	if cfg.TrustTTL != "" {
+		if _, err := ParseDuration(cfg.TrustTTL); err != nil {
+			return nil, fmt.Errorf("invalid trust_ttl in %s: %w", configPath, err)
+		}
+	}
+
+	switch cfg.RepoIdentity {
+	case "", RepoIdentityPath, RepoIdentityRemote:
+	default:
+		return nil, fmt.Errorf("invalid repo_identity %q in %s (expected %q or %q)", cfg.RepoIdentity, configPath, RepoIdentityPath, RepoIdentityRemote)
+	}
+
+	if cfg.NetworkAttempts < 0 {
+		return nil, fmt.Errorf("invalid network_attempts in %s: must be at least 1", configPath)
+	}
+
+	for i, editor := range cfg.EditorFallbacks {
+		if strings.TrimSpace(editor) == "" {
+			return nil, fmt.Errorf("invalid editor_fallbacks[%d] in %s: empty command", i, configPath)
+		}
+	}
+
+	for i, command := range cfg.DefaultHooks {
+		if strings.TrimSpace(command) == "" {
+			return nil, fmt.Errorf("invalid default_hooks[%d] in %s: empty command", i, configPath)
+		}
+	}
+
+	for name, command := range cfg.Aliases {
+		if name == "" || strings.ContainsFunc(name, unicode.IsSpace) || strings.HasPrefix(name, "-") {
+			return nil, fmt.Errorf("invalid alias %q in %s: a name is one word, not starting with '-'", name, configPath)
+		}
+		if strings.TrimSpace(command) == "" {
+			return nil, fmt.Errorf("invalid aliases.%s in %s: empty command", name, configPath)
+		}
+	}
+
+	var iconErr error
+	cfg.Icons.Each(func(name, icon string) {
+		if iconErr == nil && strings.ContainsFunc(icon, unicode.IsSpace) {
+			iconErr = fmt.Errorf("invalid icons.%s in %s: %q contains whitespace", name, configPath, icon)
+		}
+	})
+	if iconErr != nil {
+		return nil, iconErr
+	}
+
+	return &cfg, nil
+}
+
+// ParseDuration parses a duration that may use day ("90d") or week ("2w")
+// units in addition to everything time.ParseDuration accepts.
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil || count < 0 {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q (use e.g. 90d, 2w, 12h)", s)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid duration %q: must not be negative", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,245 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the newest .sprout.yml format. A file without a
+// version key is version 0, the format before versioning.
+const CurrentVersion = 1
+
+// migrations[i] rewrites a version i document as version i+1, in place so
+// comments survive. Version 1 only added the version key itself.
+var migrations = []func(doc *yaml.Node) error{
+	func(doc *yaml.Node) error { return nil },
+}
+
+// ErrNewerVersion is returned for a config written for a newer sprout.
+var ErrNewerVersion = errors.New("config was written for a newer version of sprout")
+
+// Error is a config problem at a line of the config file.
+type Error struct {
+	File string
+	Line int // 0 if unknown
+	Err  error
+}
+
+func (e *Error) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %v", e.File, e.Err)
+	}
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// FieldError is a problem with one config field, named by its YAML path
+// such as "hooks.on_create[1]".
+type FieldError struct {
+	Field string
+	Msg   string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + " " + e.Msg
+}
+
+func fieldError(field, format string, args ...any) *FieldError {
+	return &FieldError{Field: field, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Upgrade rewrites the config file data read from path in the newest
+// format, keeping comments. It returns the version data was in; data
+// already in the newest format is returned unchanged.
+func Upgrade(path string, data []byte) ([]byte, int, error) {
+	doc, err := parseDocument(data)
+	if err != nil {
+		return nil, 0, locate(path, nil, err)
+	}
+	version, err := migrate(doc)
+	if err != nil {
+		return nil, 0, locate(path, nil, err)
+	}
+	if version == CurrentVersion {
+		return data, version, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, 0, fmt.Errorf("failed to write upgraded config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, 0, fmt.Errorf("failed to write upgraded config: %w", err)
+	}
+	return buf.Bytes(), version, nil
+}
+
+// parseDocument parses config data into a document node holding a mapping.
+// An empty file, or an empty document such as a lone "---", is an empty
+// mapping.
+func parseDocument(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, yamlError(err)
+	}
+	empty := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{empty}}
+	}
+	if root := doc.Content[0]; root.Kind == yaml.ScalarNode && root.Tag == "!!null" {
+		doc.Content[0] = empty
+	}
+	if root := doc.Content[0]; root.Kind != yaml.MappingNode {
+		return nil, &lineError{line: root.Line, err: errors.New("config must be a mapping of settings")}
+	}
+	return &doc, nil
+}
+
+// migrate upgrades doc to CurrentVersion in place and returns the version
+// it was in.
+func migrate(doc *yaml.Node) (int, error) {
+	root := doc.Content[0]
+	version := 0
+	versionNode := mappingValue(root, "version")
+	if versionNode != nil {
+		v, err := strconv.Atoi(versionNode.Value)
+		if err != nil || versionNode.Kind != yaml.ScalarNode || v < 0 {
+			return 0, &lineError{line: versionNode.Line, err: fmt.Errorf("version must be a number, got %q", versionNode.Value)}
+		}
+		if v > CurrentVersion {
+			return 0, &lineError{line: versionNode.Line, err: fmt.Errorf("%w (version %d, this sprout reads up to %d); run 'sprout self-update'", ErrNewerVersion, v, CurrentVersion)}
+		}
+		version = v
+	}
+
+	for v := version; v < CurrentVersion; v++ {
+		if err := migrations[v](doc); err != nil {
+			return 0, fmt.Errorf("failed to upgrade config from version %d: %w", v, err)
+		}
+	}
+
+	current := strconv.Itoa(CurrentVersion)
+	if versionNode != nil {
+		versionNode.Value = current
+	} else if version != CurrentVersion {
+		root.Content = append([]*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"},
+			{Kind: yaml.ScalarNode, Tag: "!!int", Value: current},
+		}, root.Content...)
+	}
+	return version, nil
+}
+
+// mappingValue returns the value of key in a mapping node, or nil.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// fieldLine returns the line of the node at a field path such as
+// "hooks.on_create[1]", or 0 if the path isn't in the document.
+func fieldLine(root *yaml.Node, field string) int {
+	node := root
+	for _, part := range strings.Split(field, ".") {
+		name, index := part, -1
+		if open := strings.IndexByte(part, '['); open >= 0 && strings.HasSuffix(part, "]") {
+			name = part[:open]
+			i, err := strconv.Atoi(part[open+1 : len(part)-1])
+			if err != nil {
+				return 0
+			}
+			index = i
+		}
+		if node = mappingValue(node, name); node == nil {
+			return 0
+		}
+		if index >= 0 {
+			if node.Kind != yaml.SequenceNode || index >= len(node.Content) {
+				return node.Line
+			}
+			node = node.Content[index]
+		}
+	}
+	return node.Line
+}
+
+// lineError is an error at a known line, located in a file by locate.
+type lineError struct {
+	line int
+	err  error
+}
+
+func (e *lineError) Error() string { return e.err.Error() }
+
+func (e *lineError) Unwrap() error { return e.err }
+
+var yamlLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// yamlError turns a yaml.v3 error into lineErrors where it names a line,
+// so they can be reported as file:line.
+func yamlError(err error) error {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		errs := make([]error, 0, len(typeErr.Errors))
+		for _, msg := range typeErr.Errors {
+			errs = append(errs, yamlError(errors.New(msg)))
+		}
+		return errors.Join(errs...)
+	}
+	if m := yamlLine.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return &lineError{line: line, err: errors.New(m[2])}
+	}
+	return err
+}
+
+// Problems splits an error returned by Parse into the problems it reports.
+func Problems(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var problems []error
+		for _, e := range joined.Unwrap() {
+			problems = append(problems, Problems(e)...)
+		}
+		return problems
+	}
+	if err == nil {
+		return nil
+	}
+	return []error{err}
+}
+
+// locate attaches the config file, and the line where known, to err.
+// Several errors (from errors.Join) are located one by one.
+func locate(file string, root *yaml.Node, err error) error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs := joined.Unwrap()
+		located := make([]error, 0, len(errs))
+		for _, e := range errs {
+			located = append(located, locate(file, root, e))
+		}
+		return errors.Join(located...)
+	}
+
+	var lineErr *lineError
+	if errors.As(err, &lineErr) {
+		return &Error{File: file, Line: lineErr.line, Err: lineErr.err}
+	}
+	var fieldErr *FieldError
+	if root != nil && errors.As(err, &fieldErr) {
+		return &Error{File: file, Line: fieldLine(root, fieldErr.Field), Err: err}
+	}
+	return &Error{File: file, Err: err}
+}
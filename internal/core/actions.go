@@ -3,6 +3,10 @@ package core
 import (
 	"errors"
 	"os"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/tickets"
 )
 
 // HookType represents the type of hook to execute.
@@ -12,6 +16,7 @@ type HookType string
 const (
 	HookTypeOnCreate HookType = "on_create"
 	HookTypeOnOpen   HookType = "on_open"
+	HookTypePostSync HookType = "post_sync"
 )
 
 // Common error variables used across commands
@@ -22,6 +27,7 @@ var (
 	ErrEmptyWorktreePath     = errors.New("worktree path cannot be empty")
 	ErrEmptyMainWorktreePath = errors.New("main worktree path cannot be empty")
 	ErrEmptyBranch           = errors.New("branch name cannot be empty")
+	ErrEmptySproutRoot       = errors.New("sprout root cannot be empty")
 	ErrNilConfig             = errors.New("config must not be nil")
 	ErrUntrustedWithHooks    = errors.New("Repository not trusted. Cannot run hooks.\n\nThis repository has hooks defined that would run automatically.\nTo allow these hooks, run:\n  sprout trust\n\nTo skip hooks this time:\n  Add the --no-hooks flag")
 	ErrNoSproutWorktrees     = errors.New("no sprout-managed worktrees found")
@@ -61,6 +67,69 @@ type CreateDirectory struct {
 
 func (CreateDirectory) isAction() {}
 
+// MoveDirectory moves a directory to a new location.
+type MoveDirectory struct {
+	From string
+	To   string
+}
+
+func (MoveDirectory) isAction() {}
+
+// RemoveDirectory deletes a directory and everything in it.
+type RemoveDirectory struct {
+	Path string
+}
+
+func (RemoveDirectory) isAction() {}
+
+// RemoveFile deletes a file. A missing file is not an error.
+type RemoveFile struct {
+	Path string
+}
+
+func (RemoveFile) isAction() {}
+
+// CopyFile copies a file, creating missing parent directories of Dst.
+// OnConflict decides what happens when Dst already exists (skip by default).
+type CopyFile struct {
+	Src        string
+	Dst        string
+	OnConflict config.ConflictPolicy
+}
+
+func (CopyFile) isAction() {}
+
+// WriteFile writes Content to Path, creating missing parent directories.
+type WriteFile struct {
+	Path    string
+	Content []byte
+	Perm    os.FileMode
+}
+
+func (WriteFile) isAction() {}
+
+// ShareDirectory brings a directory of the main worktree (Src) into a new
+// worktree (Dst) by symlink or copy-on-write clone. Nothing happens if Src
+// is missing or Dst already exists.
+type ShareDirectory struct {
+	Src  string
+	Dst  string
+	Mode config.ShareMode
+}
+
+func (ShareDirectory) isAction() {}
+
+// CloneDirectory makes a copy-on-write clone of a main worktree directory
+// (Src) in a new worktree (Dst), e.g. build artifacts to start warm. Unlike
+// ShareDirectory it never falls back to a symlink, since builds would then
+// write into the main worktree.
+type CloneDirectory struct {
+	Src string
+	Dst string
+}
+
+func (CloneDirectory) isAction() {}
+
 // RunGitCommand executes a git command in the specified directory.
 type RunGitCommand struct {
 	Dir  string
@@ -69,6 +138,15 @@ type RunGitCommand struct {
 
 func (RunGitCommand) isAction() {}
 
+// RunGitInteractive runs a git command attached to the terminal, so pagers
+// and difftools work. Its output is never captured.
+type RunGitInteractive struct {
+	Dir  string
+	Args []string
+}
+
+func (RunGitInteractive) isAction() {}
+
 // OpenEditor opens the specified path in the user's editor.
 type OpenEditor struct {
 	Path string
@@ -76,6 +154,24 @@ type OpenEditor struct {
 
 func (OpenEditor) isAction() {}
 
+// OpenFile opens a file of a worktree in the user's editor, at Line and
+// Column when set (1-based). Editors that can't jump to a line just open the file.
+type OpenFile struct {
+	WorktreePath string
+	File         string // Absolute path inside WorktreePath
+	Line         int
+	Column       int
+}
+
+func (OpenFile) isAction() {}
+
+// OpenURL opens a web page (e.g. a branch on GitHub) in the user's browser.
+type OpenURL struct {
+	URL string
+}
+
+func (OpenURL) isAction() {}
+
 // RunHooks executes hook commands in the specified directory.
 type RunHooks struct {
 	Type             HookType // HookTypeOnCreate, HookTypeOnOpen, etc.
@@ -83,6 +179,9 @@ type RunHooks struct {
 	Path             string   // Working directory for hooks (worktree path)
 	RepoRoot         string   // Repository root
 	MainWorktreePath string   // Main worktree path (for trust checks)
+	// Branch checked out in Path, if the planner knows it. Only --dry-run
+	// uses it; hooks ask git
+	Branch string
 }
 
 func (RunHooks) isAction() {}
@@ -99,7 +198,8 @@ func (PromptTrust) isAction() {}
 
 // TrustRepo marks a repository as trusted.
 type TrustRepo struct {
-	RepoRoot string
+	RepoRoot     string
+	WorktreePath string // Whose .sprout.yml hooks are trusted; RepoRoot's if empty
 }
 
 func (TrustRepo) isAction() {}
@@ -111,6 +211,111 @@ type UntrustRepo struct {
 
 func (UntrustRepo) isAction() {}
 
+// AdoptWorktree records a worktree created outside sprout as sprout-managed.
+type AdoptWorktree struct {
+	RepoRoot string // Main worktree path of the owning repository
+	Path     string
+}
+
+func (AdoptWorktree) isAction() {}
+
+// ForgetWorktree removes a worktree from the adopted worktrees store.
+type ForgetWorktree struct {
+	Path string
+}
+
+func (ForgetWorktree) isAction() {}
+
+// RecordTicket links a worktree to the ticket it was created for.
+type RecordTicket struct {
+	WorktreePath string
+	Ticket       tickets.Ticket
+}
+
+func (RecordTicket) isAction() {}
+
+// RecordBase records the ref a new worktree's branch was created from, so
+// unmerged commits are counted against it rather than a guessed default.
+type RecordBase struct {
+	WorktreePath string
+	Base         string
+}
+
+func (RecordBase) isAction() {}
+
+// CreateScratch creates the scratch directory of a worktree (see
+// sprout.ScratchDir), which the executor finds under the sprout root.
+type CreateScratch struct {
+	WorktreePath string
+}
+
+func (CreateScratch) isAction() {}
+
+// RemoveScratch removes the scratch directory of a worktree, if it has one,
+// along with the worktree.
+type RemoveScratch struct {
+	WorktreePath string
+}
+
+func (RemoveScratch) isAction() {}
+
+// SetPinned pins or unpins a worktree (see PlanPinCommand).
+type SetPinned struct {
+	WorktreePath string
+	Pinned       bool
+}
+
+func (SetPinned) isAction() {}
+
+// SetNote sets the note of a worktree; an empty Note removes it.
+type SetNote struct {
+	WorktreePath string
+	Note         string
+}
+
+func (SetNote) isAction() {}
+
+// SetExpiry makes a worktree temporary until ExpiresAt (sprout add --ttl);
+// the zero time makes it permanent again.
+type SetExpiry struct {
+	WorktreePath string
+	ExpiresAt    time.Time
+}
+
+func (SetExpiry) isAction() {}
+
+// ForgetMetadata drops what sprout recorded about a worktree (its base,
+// pin, note, expiry and hook runs) along with the worktree.
+type ForgetMetadata struct {
+	WorktreePath string
+}
+
+func (ForgetMetadata) isAction() {}
+
+// SaveWorkspace records the workspace Name with Repos (main worktree paths)
+// as its members, replacing any earlier one of that name (sprout ws create).
+type SaveWorkspace struct {
+	Name  string
+	Repos []string
+}
+
+func (SaveWorkspace) isAction() {}
+
+// DeleteWorkspace forgets the workspace Name; its worktrees are left alone.
+type DeleteWorkspace struct {
+	Name string
+}
+
+func (DeleteWorkspace) isAction() {}
+
+// SetTelemetry opts in to or out of telemetry. Either way the usage
+// collected so far is discarded.
+type SetTelemetry struct {
+	Enabled bool
+}
+
+func (SetTelemetry) isAction() {}
+
 // SelectInteractive represents an interactive selection.
 // Note: Uses 'any' for flexibility, but this is intentionally "edge-only" - not
 // executed by the standard effects executor. Interactive prompts are handled in
@@ -123,6 +328,20 @@ type SelectInteractive struct {
 
 func (SelectInteractive) isAction() {}
 
+// Parallel runs independent actions concurrently (e.g. the files a new
+// worktree gets from the template directory and the main worktree). Once a
+// member fails the executor starts no more of them, waits for the ones
+// running, and reports their failures in member order.
+//
+// Members must not depend on each other's results, and must not be
+// interactive or control flow: PromptTrust, SelectInteractive and Exit are
+// rejected. Their output may interleave.
+type Parallel struct {
+	Actions []Action
+}
+
+func (Parallel) isAction() {}
+
 // Exit terminates the command with the specified exit code.
 type Exit struct {
 	Code int
@@ -133,4 +352,21 @@ func (Exit) isAction() {}
 // Plan represents a sequence of actions to execute.
 type Plan struct {
 	Actions []Action
+	Err     error // Why the plan only fails (see errorPlan), nil otherwise
+}
+
+// WithoutMessages returns the plan without its PrintMessage actions, for
+// commands whose stdout is read by scripts. Errors are kept.
+func WithoutMessages(plan Plan) Plan {
+	var actions []Action
+	for _, action := range plan.Actions {
+		switch a := action.(type) {
+		case PrintMessage:
+			continue
+		case Parallel:
+			action = Parallel{Actions: WithoutMessages(Plan{Actions: a.Actions}).Actions}
+		}
+		actions = append(actions, action)
+	}
+	return Plan{Actions: actions, Err: plan.Err}
 }
@@ -3,8 +3,12 @@ package core
 import (
 	"fmt"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"time"
 
 	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/tickets"
 )
 
 // Message constants for consistent UX
@@ -12,6 +16,18 @@ const (
 	msgWorktreeExists   = "Worktree already exists at %s"
 	msgCreatingWorktree = "Creating worktree for %s at %s..."
 	msgWorktreeCreated  = "Worktree created!"
+	msgCopyingTemplate  = "📄 Copying %d template file(s) from %s"
+	msgCloningArtifacts = "🧊 Cloning %d build artifact dir(s) from the main worktree"
+	msgGrafting         = "🌿 Moving uncommitted changes from %s"
+	msgApplyingStash    = "📦 Applying %s"
+	msgApplyingPatch    = "🩹 Applying %s"
+	msgLocked           = "🔒 Locked, so git won't prune it while its directory is missing; unlock it with 'git worktree unlock %s'"
+	errChangesExisting  = "%s already exists; %s only applies changes to a new worktree"
+	errTTLExisting      = "%s already exists; --ttl only makes a new worktree temporary"
+	errTagBranchExists  = "branch %s already exists; --tag starts a new branch at the tag"
+	errDetachExisting   = "%s already exists; give the detached worktree another label"
+	errLockExisting     = "%s already exists; lock it with 'git worktree lock %s'"
+	errCheckedOut       = "branch %s is already checked out at %s; open it there with 'sprout open %s', or pass --force-create to check it out here as well"
 )
 
 // AddContext contains all inputs needed to plan the add command.
@@ -24,11 +40,51 @@ type AddContext struct {
 	WorktreeExists     bool
 	LocalBranchExists  bool
 	RemoteBranchExists bool
-	HasOriginMain      bool
+	RemoteBase         string         // origin/<default branch> when it exists, the base of new branches
+	HeadBranch         string         // Branch checked out in RepoRoot, the base of new branches without RemoteBase
 	Config             *config.Config // Must not be nil
+	DefaultHooks       []string       // The user's default_hooks, which run before Config's on_create hooks without trust
 	IsTrusted          bool
 	NoHooks            bool
 	NoOpen             bool
+	Trust              bool           // Trust the repo without prompting (--trust)
+	HooksDenied        bool           // Organization policy forbids hooks for this repo
+	Ticket             tickets.Ticket // Set by --ticket; recorded once the worktree exists
+	ExpiresAt          time.Time      // Set by --ttl: the worktree is temporary, and prune removes it after this
+	Tag                string         // Set by --tag: the new branch starts at this tag, which is its base
+	// Set by --detach: the commit checked out on a detached HEAD. Branch is
+	// then the label that names the worktree directory (see DetachedLabel)
+	Detach string
+	// Another worktree that has Branch checked out. git refuses to check it
+	// out twice unless forced (--force-create)
+	CheckedOutAt string
+	Force        bool
+	// Set by --lock: git locks the new worktree as it adds it, with
+	// LockReason if given, so 'git worktree prune' keeps it while its
+	// directory is missing, such as on an unmounted volume
+	Lock       bool
+	LockReason string
+
+	// Template files to copy into the new worktree (config template_dir)
+	TemplateDir   string
+	TemplateFiles []string // Paths relative to TemplateDir
+
+	// Build artifact directories to clone from the main worktree
+	// (--clone-artifacts), relative to the worktree root
+	Artifacts []string
+
+	// Sprout worktrees of the repository, for the max_worktrees limit; only
+	// gathered when the new worktree would exceed it
+	ExistingWorktrees []WorktreeUsage
+	Evict             bool // Remove clean, merged worktrees to stay within the limit (--evict)
+
+	// Worktree whose uncommitted changes move into the new one (sprout graft)
+	GraftFrom string
+
+	// Changes to apply to the new worktree after checkout: a stash entry
+	// (--from-stash), which is kept, or a patch file (--apply-patch)
+	FromStash string
+	PatchFile string
 }
 
 // PlanAddCommand creates a plan for adding/opening a worktree.
@@ -36,8 +92,9 @@ type AddContext struct {
 // Logic:
 //  1. Validate inputs
 //  2. If worktree exists, optionally open it (respecting NoOpen)
-//  3. If creating new worktree with hooks, check trust
-//  4. Build action sequence: create dir → git worktree add → editor/hooks (order varies)
+//  3. If creating new worktree with hooks, check trust (prompt, or trust directly with --trust);
+//     the user's default hooks alone don't need it
+//  4. Build action sequence: create dir → git worktree add → scratch dir → editor/hooks (order varies)
 func PlanAddCommand(ctx AddContext) Plan {
 	// Validate inputs
 	if ctx.RepoRoot == "" {
@@ -53,7 +110,29 @@ func PlanAddCommand(ctx AddContext) Plan {
 		return errorPlan(ErrNilConfig)
 	}
 
+	if ctx.Tag != "" && (ctx.LocalBranchExists || ctx.RemoteBranchExists) {
+		return errorPlan(fmt.Errorf(errTagBranchExists, ctx.Branch))
+	}
+
 	// If worktree already exists, optionally open it (respecting NoOpen flag)
+	if ctx.WorktreeExists && ctx.FromStash != "" {
+		return errorPlan(fmt.Errorf(errChangesExisting, ctx.WorktreePath, "--from-stash"))
+	}
+	if ctx.WorktreeExists && ctx.PatchFile != "" {
+		return errorPlan(fmt.Errorf(errChangesExisting, ctx.WorktreePath, "--apply-patch"))
+	}
+	if ctx.WorktreeExists && !ctx.ExpiresAt.IsZero() {
+		return errorPlan(fmt.Errorf(errTTLExisting, ctx.WorktreePath))
+	}
+	if ctx.WorktreeExists && ctx.Detach != "" {
+		return errorPlan(fmt.Errorf(errDetachExisting, ctx.WorktreePath))
+	}
+	if ctx.WorktreeExists && ctx.Lock {
+		return errorPlan(fmt.Errorf(errLockExisting, ctx.WorktreePath, ctx.WorktreePath))
+	}
+	if !ctx.WorktreeExists && ctx.CheckedOutAt != "" && !ctx.Force {
+		return errorPlan(fmt.Errorf(errCheckedOut, ctx.Branch, ctx.CheckedOutAt, ctx.CheckedOutAt))
+	}
 	if ctx.WorktreeExists {
 		actions := []Action{
 			PrintMessage{Msg: fmt.Sprintf(msgWorktreeExists, ctx.WorktreePath)},
@@ -65,20 +144,24 @@ func PlanAddCommand(ctx AddContext) Plan {
 	}
 
 	// Check trust requirements before creating worktree
-	shouldRunHooks := ctx.Config.HasCreateHooks() && !ctx.NoHooks
+	hooks := append(slices.Clone(ctx.DefaultHooks), ctx.Config.Hooks.OnCreate...)
+	shouldRunHooks := len(hooks) > 0 && !ctx.NoHooks && !ctx.HooksDenied
+	needsTrust := shouldRunHooks && ctx.Config.HasCreateHooks()
 	if shouldRunHooks {
 		if ctx.MainWorktreePath == "" {
 			return errorPlan(ErrEmptyMainWorktreePath)
 		}
-		if !ctx.IsTrusted {
+		if needsTrust && !ctx.IsTrusted && !ctx.Trust {
 			// Return a plan that prompts for trust interactively
 			// If prompt fails (non-interactive), it will error with helpful guidance
-			return Plan{Actions: []Action{
+			actions := []Action{
 				PromptTrust{
 					MainWorktreePath: ctx.MainWorktreePath,
 					HookType:         HookTypeOnCreate,
 					HookCommands:     ctx.Config.Hooks.OnCreate,
 				},
+			}
+			actions = append(actions,
 				PrintMessage{Msg: fmt.Sprintf(msgCreatingWorktree, ctx.Branch, ctx.WorktreePath)},
 				CreateDirectory{
 					Path: filepath.Dir(ctx.WorktreePath),
@@ -86,23 +169,50 @@ func PlanAddCommand(ctx AddContext) Plan {
 				},
 				RunGitCommand{
 					Dir:  ctx.RepoRoot,
-					Args: WorktreeAddArgs(ctx.WorktreePath, ctx.Branch, ctx.LocalBranchExists, ctx.RemoteBranchExists, ctx.HasOriginMain),
+					Args: worktreeAddArgs(ctx),
 				},
+			)
+			actions = append(actions, planWorktreeLimit(ctx)...)
+			actions = appendGraft(actions, ctx)
+			actions = appendChanges(actions, ctx)
+			actions = appendForgetMetadata(actions, ctx)
+			actions = appendRecordTicket(actions, ctx)
+			actions = appendRecordBase(actions, ctx)
+			actions = appendSetExpiry(actions, ctx)
+			actions = appendLockNotice(actions, ctx)
+			actions = appendWorktreeFiles(actions, ctx)
+			actions = append(actions,
+				CreateScratch{WorktreePath: ctx.WorktreePath},
 				PrintMessage{Msg: msgWorktreeCreated},
 				conditionalEditor(ctx.NoOpen, ctx.WorktreePath),
 				RunHooks{
 					Type:             HookTypeOnCreate,
-					Commands:         ctx.Config.Hooks.OnCreate,
+					Commands:         hooks,
 					Path:             ctx.WorktreePath,
 					RepoRoot:         ctx.RepoRoot,
 					MainWorktreePath: ctx.MainWorktreePath,
+					Branch:           hookBranch(ctx),
 				},
-			}}
+			)
+			return Plan{Actions: actions}
 		}
 	}
 
+	var actions []Action
+	if len(hooks) > 0 && !ctx.NoHooks && ctx.HooksDenied {
+		actions = append(actions, PrintMessage{Msg: fmt.Sprintf(MsgHooksDeniedByPolicy, HookTypeOnCreate)})
+	}
+
+	// --trust: show the hooks and trust the repo, as if the user answered the prompt with yes
+	if needsTrust && !ctx.IsTrusted && ctx.Trust {
+		actions = append(actions,
+			PrintMessage{Msg: MsgTrustingWithHooks(ctx.MainWorktreePath, HookTypeOnCreate, ctx.Config.Hooks.OnCreate)},
+			TrustRepo{RepoRoot: ctx.MainWorktreePath},
+		)
+	}
+
 	// Build action sequence
-	actions := []Action{
+	actions = append(actions,
 		PrintMessage{Msg: fmt.Sprintf(msgCreatingWorktree, ctx.Branch, ctx.WorktreePath)},
 		CreateDirectory{
 			Path: filepath.Dir(ctx.WorktreePath),
@@ -110,10 +220,19 @@ func PlanAddCommand(ctx AddContext) Plan {
 		},
 		RunGitCommand{
 			Dir:  ctx.RepoRoot,
-			Args: WorktreeAddArgs(ctx.WorktreePath, ctx.Branch, ctx.LocalBranchExists, ctx.RemoteBranchExists, ctx.HasOriginMain),
+			Args: worktreeAddArgs(ctx),
 		},
-		PrintMessage{Msg: msgWorktreeCreated},
-	}
+	)
+	actions = append(actions, planWorktreeLimit(ctx)...)
+	actions = appendGraft(actions, ctx)
+	actions = appendChanges(actions, ctx)
+	actions = appendForgetMetadata(actions, ctx)
+	actions = appendRecordTicket(actions, ctx)
+	actions = appendRecordBase(actions, ctx)
+	actions = appendSetExpiry(actions, ctx)
+	actions = appendLockNotice(actions, ctx)
+	actions = appendWorktreeFiles(actions, ctx)
+	actions = append(actions, CreateScratch{WorktreePath: ctx.WorktreePath}, PrintMessage{Msg: msgWorktreeCreated})
 
 	// Add hooks and editor based on configuration
 	// Note: When hooks run, editor opens FIRST so user can browse while hooks execute in terminal
@@ -124,10 +243,11 @@ func PlanAddCommand(ctx AddContext) Plan {
 		}
 		actions = append(actions, RunHooks{
 			Type:             HookTypeOnCreate,
-			Commands:         ctx.Config.Hooks.OnCreate,
+			Commands:         hooks,
 			Path:             ctx.WorktreePath,
 			RepoRoot:         ctx.RepoRoot,
 			MainWorktreePath: ctx.MainWorktreePath,
+			Branch:           hookBranch(ctx),
 		})
 	} else if !ctx.NoOpen {
 		// No hooks: open editor after creation
@@ -137,6 +257,43 @@ func PlanAddCommand(ctx AddContext) Plan {
 	return Plan{Actions: actions}
 }
 
+// worktreeAddArgs returns the 'git worktree add' arguments of the new
+// worktree: on a detached HEAD with --detach, on a new branch at ctx.Tag
+// with --tag, as WorktreeAddArgs decides otherwise. --force-create adds
+// --force, so git checks out a branch another worktree has, and --lock
+// adds --lock with its --reason.
+func worktreeAddArgs(ctx AddContext) []string {
+	var args []string
+	switch {
+	case ctx.Detach != "":
+		args = DetachWorktreeAddArgs(ctx.WorktreePath, ctx.Detach)
+	case ctx.Tag != "":
+		args = TagWorktreeAddArgs(ctx.WorktreePath, ctx.Branch, ctx.Tag)
+	default:
+		args = WorktreeAddArgs(ctx.WorktreePath, ctx.Branch, ctx.LocalBranchExists, ctx.RemoteBranchExists, ctx.RemoteBase)
+	}
+	if ctx.Force {
+		args = slices.Insert(args, 2, "--force")
+	}
+	if ctx.Lock {
+		lock := []string{"--lock"}
+		if ctx.LockReason != "" {
+			lock = append(lock, "--reason", ctx.LockReason)
+		}
+		args = slices.Insert(args, 2, lock...)
+	}
+	return args
+}
+
+// hookBranch is the branch the new worktree of ctx has checked out, or
+// empty on a detached HEAD.
+func hookBranch(ctx AddContext) string {
+	if ctx.Detach != "" {
+		return ""
+	}
+	return ctx.Branch
+}
+
 // conditionalEditor returns OpenEditor action unless noOpen is true.
 func conditionalEditor(noOpen bool, path string) Action {
 	if noOpen {
@@ -145,10 +302,186 @@ func conditionalEditor(noOpen bool, path string) Action {
 	return OpenEditor{Path: path}
 }
 
-// errorPlan creates a plan that prints an error and exits.
+// appendGraft moves the uncommitted changes of ctx.GraftFrom into the new
+// worktree through the stash, which all worktrees share. This happens right
+// after checkout, so template files and hooks see the changes.
+func appendGraft(actions []Action, ctx AddContext) []Action {
+	if ctx.GraftFrom == "" {
+		return actions
+	}
+	return append(actions,
+		PrintMessage{Msg: fmt.Sprintf(msgGrafting, ctx.GraftFrom)},
+		RunGitCommand{
+			Dir:  ctx.GraftFrom,
+			Args: []string{"stash", "push", "--include-untracked", "--message", "sprout graft to " + ctx.Branch},
+		},
+		RunGitCommand{Dir: ctx.WorktreePath, Args: []string{"stash", "pop"}},
+	)
+}
+
+// appendChanges applies the stash entry or patch file given with
+// --from-stash or --apply-patch to the new worktree, like appendGraft right
+// after checkout. The stash entry is applied rather than popped, so it
+// stays around if the changes conflict.
+func appendChanges(actions []Action, ctx AddContext) []Action {
+	if ctx.FromStash != "" {
+		actions = append(actions,
+			PrintMessage{Msg: fmt.Sprintf(msgApplyingStash, ctx.FromStash)},
+			RunGitCommand{Dir: ctx.WorktreePath, Args: []string{"stash", "apply", ctx.FromStash}},
+		)
+	}
+	if ctx.PatchFile != "" {
+		actions = append(actions,
+			PrintMessage{Msg: fmt.Sprintf(msgApplyingPatch, ctx.PatchFile)},
+			RunGitCommand{Dir: ctx.WorktreePath, Args: []string{"apply", ctx.PatchFile}},
+		)
+	}
+	return actions
+}
+
+var stashIndex = regexp.MustCompile(`^[0-9]+$`)
+
+// StashRef expands a bare stash index such as "1" to "stash@{1}"; other
+// refs are returned as given.
+func StashRef(ref string) string {
+	if stashIndex.MatchString(ref) {
+		return "stash@{" + ref + "}"
+	}
+	return ref
+}
+
+// appendRecordTicket links the new worktree to its ticket, if it was
+// created from one (--ticket).
+func appendRecordTicket(actions []Action, ctx AddContext) []Action {
+	if ctx.Ticket.ID == "" {
+		return actions
+	}
+	return append(actions, RecordTicket{WorktreePath: ctx.WorktreePath, Ticket: ctx.Ticket})
+}
+
+// appendRecordBase records the ref a new branch starts from (see
+// worktreeAddArgs). Checking out an existing branch records nothing: where
+// it was created from is unknown, and neither does a detached HEAD.
+func appendRecordBase(actions []Action, ctx AddContext) []Action {
+	if ctx.LocalBranchExists || ctx.RemoteBranchExists || ctx.Detach != "" {
+		return actions
+	}
+	if ctx.Tag != "" {
+		return append(actions, RecordBase{WorktreePath: ctx.WorktreePath, Base: ctx.Tag})
+	}
+	base := ctx.HeadBranch
+	if ctx.RemoteBase != "" {
+		base = ctx.RemoteBase
+	}
+	if base == "" {
+		return actions // Detached HEAD: there is no ref to compare against later
+	}
+	return append(actions, RecordBase{WorktreePath: ctx.WorktreePath, Base: base})
+}
+
+// appendForgetMetadata drops metadata left at the new worktree's path by
+// one removed before sprout forgot it, so the new worktree doesn't come
+// back pinned or expired.
+func appendForgetMetadata(actions []Action, ctx AddContext) []Action {
+	return append(actions, ForgetMetadata{WorktreePath: ctx.WorktreePath})
+}
+
+// appendSetExpiry marks the new worktree temporary when --ttl is given.
+func appendSetExpiry(actions []Action, ctx AddContext) []Action {
+	if ctx.ExpiresAt.IsZero() {
+		return actions
+	}
+	return append(actions, SetExpiry{WorktreePath: ctx.WorktreePath, ExpiresAt: ctx.ExpiresAt})
+}
+
+// appendLockNotice tells how to unlock the new worktree when --lock is given.
+func appendLockNotice(actions []Action, ctx AddContext) []Action {
+	if !ctx.Lock {
+		return actions
+	}
+	return append(actions, PrintMessage{Msg: fmt.Sprintf(msgLocked, ctx.WorktreePath)})
+}
+
+// appendWorktreeFiles fills the new worktree from the template directory
+// and the main worktree. Each copy writes its own path, so they run in
+// parallel, after the messages announcing them.
+func appendWorktreeFiles(actions []Action, ctx AddContext) []Action {
+	var files []Action
+	files = appendTemplateFiles(files, ctx)
+	files = appendSharedDirectories(files, ctx)
+	files = appendArtifactClones(files, ctx)
+
+	var copies []Action
+	for _, action := range files {
+		if msg, ok := action.(PrintMessage); ok {
+			actions = append(actions, msg)
+		} else {
+			copies = append(copies, action)
+		}
+	}
+	if len(copies) < 2 {
+		return append(actions, copies...)
+	}
+	return append(actions, Parallel{Actions: copies})
+}
+
+// appendTemplateFiles copies the template directory's files into the new
+// worktree, before hooks run so they can rely on them.
+func appendTemplateFiles(actions []Action, ctx AddContext) []Action {
+	if len(ctx.TemplateFiles) == 0 {
+		return actions
+	}
+	actions = append(actions, PrintMessage{Msg: fmt.Sprintf(msgCopyingTemplate, len(ctx.TemplateFiles), ctx.TemplateDir)})
+	for _, file := range ctx.TemplateFiles {
+		actions = append(actions, CopyFile{
+			Src:        filepath.Join(ctx.TemplateDir, file),
+			Dst:        filepath.Join(ctx.WorktreePath, file),
+			OnConflict: ctx.Config.TemplateConflict,
+		})
+	}
+	return actions
+}
+
+// appendSharedDirectories reuses the main worktree's heavy directories
+// (config share), before hooks run so installs can find them.
+func appendSharedDirectories(actions []Action, ctx AddContext) []Action {
+	if len(ctx.Config.Share) == 0 || ctx.MainWorktreePath == "" {
+		return actions
+	}
+	for _, dir := range ctx.Config.Share {
+		actions = append(actions, ShareDirectory{
+			Src:  filepath.Join(ctx.MainWorktreePath, dir),
+			Dst:  filepath.Join(ctx.WorktreePath, dir),
+			Mode: ctx.Config.ShareMode,
+		})
+	}
+	return actions
+}
+
+// appendArtifactClones clones build artifacts into the new worktree so its
+// first build is incremental.
+func appendArtifactClones(actions []Action, ctx AddContext) []Action {
+	if len(ctx.Artifacts) == 0 || ctx.MainWorktreePath == "" {
+		return actions
+	}
+	actions = append(actions, PrintMessage{Msg: fmt.Sprintf(msgCloningArtifacts, len(ctx.Artifacts))})
+	for _, dir := range ctx.Artifacts {
+		actions = append(actions, CloneDirectory{
+			Src: filepath.Join(ctx.MainWorktreePath, dir),
+			Dst: filepath.Join(ctx.WorktreePath, dir),
+		})
+	}
+	return actions
+}
+
+// errorPlan creates a plan that prints an error and exits, recording err
+// as the plan's Err.
 func errorPlan(err error) Plan {
-	return Plan{Actions: []Action{
-		PrintError{Msg: err.Error()},
-		Exit{Code: 1},
-	}}
+	return Plan{
+		Actions: []Action{
+			PrintError{Msg: err.Error()},
+			Exit{Code: 1},
+		},
+		Err: err,
+	}
 }
@@ -2,8 +2,10 @@ package core
 
 import (
 	"testing"
+	"time"
 
 	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/tickets"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -24,7 +26,7 @@ func TestPlanAddCommand(t *testing.T) {
 				WorktreeExists:     true,
 				LocalBranchExists:  true,
 				RemoteBranchExists: false,
-				HasOriginMain:      true,
+				RemoteBase:         "origin/main",
 				Config:             &config.Config{},
 				IsTrusted:          true,
 				NoHooks:            false,
@@ -51,7 +53,7 @@ func TestPlanAddCommand(t *testing.T) {
 				WorktreeExists:     true,
 				LocalBranchExists:  true,
 				RemoteBranchExists: false,
-				HasOriginMain:      true,
+				RemoteBase:         "origin/main",
 				Config:             &config.Config{},
 				IsTrusted:          true,
 				NoHooks:            false,
@@ -81,13 +83,13 @@ func TestPlanAddCommand(t *testing.T) {
 				WorktreeExists:     false,
 				LocalBranchExists:  false,
 				RemoteBranchExists: false,
-				HasOriginMain:      true,
+				RemoteBase:         "origin/main",
 				Config:             &config.Config{Hooks: config.HooksConfig{OnCreate: []string{"npm install"}}},
 				IsTrusted:          true,
 				NoHooks:            false,
 				NoOpen:             false,
 			},
-			wantActions: 6,
+			wantActions: 9,
 			checkActions: func(t *testing.T, actions []Action) {
 				assert.IsType(t, PrintMessage{}, actions[0], "should print creating message")
 				assert.Contains(t, actions[0].(PrintMessage).Msg, "Creating worktree")
@@ -102,14 +104,17 @@ func TestPlanAddCommand(t *testing.T) {
 				assert.Contains(t, git.Args, "worktree")
 				assert.Contains(t, git.Args, "add")
 
-				assert.IsType(t, PrintMessage{}, actions[3], "should print success message")
-				assert.Contains(t, actions[3].(PrintMessage).Msg, "created")
+				assert.Equal(t, ForgetMetadata{WorktreePath: "/sprout/feature"}, actions[3])
+				assert.Equal(t, RecordBase{WorktreePath: "/sprout/feature", Base: "origin/main"}, actions[4])
+				assert.Equal(t, CreateScratch{WorktreePath: "/sprout/feature"}, actions[5])
+				assert.IsType(t, PrintMessage{}, actions[6], "should print success message")
+				assert.Contains(t, actions[6].(PrintMessage).Msg, "created")
 
-				assert.IsType(t, OpenEditor{}, actions[4], "should open editor before hooks")
-				assert.Equal(t, "/sprout/feature", actions[4].(OpenEditor).Path)
+				assert.IsType(t, OpenEditor{}, actions[7], "should open editor before hooks")
+				assert.Equal(t, "/sprout/feature", actions[7].(OpenEditor).Path)
 
-				assert.IsType(t, RunHooks{}, actions[5], "should run hooks")
-				hooks := actions[5].(RunHooks)
+				assert.IsType(t, RunHooks{}, actions[8], "should run hooks")
+				hooks := actions[8].(RunHooks)
 				assert.Equal(t, HookTypeOnCreate, hooks.Type)
 				assert.Equal(t, []string{"npm install"}, hooks.Commands)
 				assert.Equal(t, "/sprout/feature", hooks.Path)
@@ -125,13 +130,13 @@ func TestPlanAddCommand(t *testing.T) {
 				WorktreeExists:     false,
 				LocalBranchExists:  false,
 				RemoteBranchExists: false,
-				HasOriginMain:      true,
+				RemoteBase:         "origin/main",
 				Config:             &config.Config{Hooks: config.HooksConfig{OnCreate: []string{"npm install"}}},
 				IsTrusted:          false,
 				NoHooks:            false,
 				NoOpen:             false,
 			},
-			wantActions: 7,
+			wantActions: 10,
 			checkActions: func(t *testing.T, actions []Action) {
 				// First action: prompt for trust
 				assert.IsType(t, PromptTrust{}, actions[0])
@@ -144,16 +149,104 @@ func TestPlanAddCommand(t *testing.T) {
 				assert.IsType(t, PrintMessage{}, actions[1])
 				assert.IsType(t, CreateDirectory{}, actions[2])
 				assert.IsType(t, RunGitCommand{}, actions[3])
-				assert.IsType(t, PrintMessage{}, actions[4])
-				assert.IsType(t, OpenEditor{}, actions[5])
+				assert.Equal(t, ForgetMetadata{WorktreePath: "/sprout/feature"}, actions[4])
+				assert.Equal(t, RecordBase{WorktreePath: "/sprout/feature", Base: "origin/main"}, actions[5])
+				assert.Equal(t, CreateScratch{WorktreePath: "/sprout/feature"}, actions[6])
+				assert.IsType(t, PrintMessage{}, actions[7])
+				assert.IsType(t, OpenEditor{}, actions[8])
 
 				// Finally: run hooks
-				assert.IsType(t, RunHooks{}, actions[6])
-				hooks := actions[6].(RunHooks)
+				assert.IsType(t, RunHooks{}, actions[9])
+				hooks := actions[9].(RunHooks)
 				assert.Equal(t, HookTypeOnCreate, hooks.Type)
 				assert.Equal(t, []string{"npm install"}, hooks.Commands)
 			},
 		},
+		{
+			name: "new branch with hooks - untrusted repo with --trust",
+			ctx: AddContext{
+				Branch:             "feature",
+				RepoRoot:           "/repo",
+				MainWorktreePath:   "/repo",
+				WorktreePath:       "/sprout/feature",
+				WorktreeExists:     false,
+				LocalBranchExists:  false,
+				RemoteBranchExists: false,
+				RemoteBase:         "origin/main",
+				Config:             &config.Config{Hooks: config.HooksConfig{OnCreate: []string{"npm install"}}},
+				IsTrusted:          false,
+				Trust:              true,
+			},
+			wantActions: 11,
+			checkActions: func(t *testing.T, actions []Action) {
+				// Hooks are shown before trusting, like the interactive prompt
+				msg := actions[0].(PrintMessage)
+				assert.Contains(t, msg.Msg, "npm install")
+				assert.Contains(t, msg.Msg, "on_create")
+
+				trust := actions[1].(TrustRepo)
+				assert.Equal(t, "/repo", trust.RepoRoot, "trust key is the main worktree path")
+
+				assert.IsType(t, PrintMessage{}, actions[2])
+				assert.IsType(t, CreateDirectory{}, actions[3])
+				assert.IsType(t, RunGitCommand{}, actions[4])
+				assert.Equal(t, ForgetMetadata{WorktreePath: "/sprout/feature"}, actions[5])
+				assert.Equal(t, RecordBase{WorktreePath: "/sprout/feature", Base: "origin/main"}, actions[6])
+				assert.Equal(t, CreateScratch{WorktreePath: "/sprout/feature"}, actions[7])
+				assert.IsType(t, PrintMessage{}, actions[8])
+				assert.IsType(t, OpenEditor{}, actions[9])
+				assert.IsType(t, RunHooks{}, actions[10])
+
+				for _, action := range actions {
+					_, isPrompt := action.(PromptTrust)
+					assert.False(t, isPrompt, "--trust should never prompt")
+				}
+			},
+		},
+		{
+			name: "new branch with hooks - already trusted with --trust does not re-trust",
+			ctx: AddContext{
+				Branch:           "feature",
+				RepoRoot:         "/repo",
+				MainWorktreePath: "/repo",
+				WorktreePath:     "/sprout/feature",
+				RemoteBase:       "origin/main",
+				Config:           &config.Config{Hooks: config.HooksConfig{OnCreate: []string{"npm install"}}},
+				IsTrusted:        true,
+				Trust:            true,
+			},
+			wantActions: 9,
+			checkActions: func(t *testing.T, actions []Action) {
+				for _, action := range actions {
+					_, isTrust := action.(TrustRepo)
+					assert.False(t, isTrust, "already trusted repo should not be trusted again")
+				}
+			},
+		},
+		{
+			name: "new branch with hooks denied by policy - skips hooks without prompting",
+			ctx: AddContext{
+				Branch:           "feature",
+				RepoRoot:         "/repo",
+				MainWorktreePath: "/repo",
+				WorktreePath:     "/sprout/feature",
+				RemoteBase:       "origin/main",
+				Config:           &config.Config{Hooks: config.HooksConfig{OnCreate: []string{"npm install"}}},
+				HooksDenied:      true,
+			},
+			wantActions: 9,
+			checkActions: func(t *testing.T, actions []Action) {
+				msg := actions[0].(PrintMessage)
+				assert.Contains(t, msg.Msg, "policy")
+				assert.IsType(t, OpenEditor{}, actions[8])
+				for _, action := range actions {
+					_, isPrompt := action.(PromptTrust)
+					assert.False(t, isPrompt, "policy denial should not prompt for trust")
+					_, isHooks := action.(RunHooks)
+					assert.False(t, isHooks, "policy denial should skip hooks")
+				}
+			},
+		},
 		{
 			name: "new branch with hooks but empty main worktree path",
 			ctx: AddContext{
@@ -164,7 +257,7 @@ func TestPlanAddCommand(t *testing.T) {
 				WorktreeExists:     false,
 				LocalBranchExists:  false,
 				RemoteBranchExists: false,
-				HasOriginMain:      true,
+				RemoteBase:         "origin/main",
 				Config:             &config.Config{Hooks: config.HooksConfig{OnCreate: []string{"npm install"}}},
 				IsTrusted:          true,
 				NoHooks:            false,
@@ -191,21 +284,24 @@ func TestPlanAddCommand(t *testing.T) {
 				WorktreeExists:     false,
 				LocalBranchExists:  false,
 				RemoteBranchExists: false,
-				HasOriginMain:      true,
+				RemoteBase:         "origin/main",
 				Config:             &config.Config{Hooks: config.HooksConfig{OnCreate: []string{"npm install"}}},
 				IsTrusted:          true,
 				NoHooks:            false,
 				NoOpen:             true, // User explicitly skipped editor
 			},
-			wantActions: 5,
+			wantActions: 8,
 			checkActions: func(t *testing.T, actions []Action) {
 				assert.IsType(t, PrintMessage{}, actions[0])
 				assert.IsType(t, CreateDirectory{}, actions[1])
 				assert.IsType(t, RunGitCommand{}, actions[2])
-				assert.IsType(t, PrintMessage{}, actions[3])
-				assert.IsType(t, RunHooks{}, actions[4], "should run hooks")
+				assert.Equal(t, ForgetMetadata{WorktreePath: "/sprout/feature"}, actions[3])
+				assert.Equal(t, RecordBase{WorktreePath: "/sprout/feature", Base: "origin/main"}, actions[4])
+				assert.Equal(t, CreateScratch{WorktreePath: "/sprout/feature"}, actions[5])
+				assert.IsType(t, PrintMessage{}, actions[6])
+				assert.IsType(t, RunHooks{}, actions[7], "should run hooks")
 
-				hooks := actions[4].(RunHooks)
+				hooks := actions[7].(RunHooks)
 				assert.Equal(t, HookTypeOnCreate, hooks.Type)
 				assert.Equal(t, []string{"npm install"}, hooks.Commands)
 				assert.Equal(t, "/sprout/feature", hooks.Path)
@@ -226,19 +322,22 @@ func TestPlanAddCommand(t *testing.T) {
 				WorktreeExists:     false,
 				LocalBranchExists:  false,
 				RemoteBranchExists: false,
-				HasOriginMain:      true,
+				RemoteBase:         "origin/main",
 				Config:             &config.Config{},
 				IsTrusted:          false, // Not trusted, but no hooks so it's fine
 				NoHooks:            false,
 				NoOpen:             false,
 			},
-			wantActions: 5,
+			wantActions: 8,
 			checkActions: func(t *testing.T, actions []Action) {
 				assert.IsType(t, PrintMessage{}, actions[0])
 				assert.IsType(t, CreateDirectory{}, actions[1])
 				assert.IsType(t, RunGitCommand{}, actions[2])
-				assert.IsType(t, PrintMessage{}, actions[3])
-				assert.IsType(t, OpenEditor{}, actions[4])
+				assert.Equal(t, ForgetMetadata{WorktreePath: "/sprout/feature"}, actions[3])
+				assert.Equal(t, RecordBase{WorktreePath: "/sprout/feature", Base: "origin/main"}, actions[4])
+				assert.Equal(t, CreateScratch{WorktreePath: "/sprout/feature"}, actions[5])
+				assert.IsType(t, PrintMessage{}, actions[6])
+				assert.IsType(t, OpenEditor{}, actions[7])
 			},
 		},
 		{
@@ -250,19 +349,22 @@ func TestPlanAddCommand(t *testing.T) {
 				WorktreeExists:     false,
 				LocalBranchExists:  false,
 				RemoteBranchExists: false,
-				HasOriginMain:      true,
+				RemoteBase:         "origin/main",
 				Config:             &config.Config{Hooks: config.HooksConfig{OnCreate: []string{"npm install"}}},
 				IsTrusted:          true,
 				NoHooks:            true, // User explicitly skipped hooks
 				NoOpen:             false,
 			},
-			wantActions: 5,
+			wantActions: 8,
 			checkActions: func(t *testing.T, actions []Action) {
 				assert.IsType(t, PrintMessage{}, actions[0])
 				assert.IsType(t, CreateDirectory{}, actions[1])
 				assert.IsType(t, RunGitCommand{}, actions[2])
-				assert.IsType(t, PrintMessage{}, actions[3])
-				assert.IsType(t, OpenEditor{}, actions[4])
+				assert.Equal(t, ForgetMetadata{WorktreePath: "/sprout/feature"}, actions[3])
+				assert.Equal(t, RecordBase{WorktreePath: "/sprout/feature", Base: "origin/main"}, actions[4])
+				assert.Equal(t, CreateScratch{WorktreePath: "/sprout/feature"}, actions[5])
+				assert.IsType(t, PrintMessage{}, actions[6])
+				assert.IsType(t, OpenEditor{}, actions[7])
 
 				// Verify no hooks action
 				for _, action := range actions {
@@ -280,18 +382,21 @@ func TestPlanAddCommand(t *testing.T) {
 				WorktreeExists:     false,
 				LocalBranchExists:  false,
 				RemoteBranchExists: false,
-				HasOriginMain:      true,
+				RemoteBase:         "origin/main",
 				Config:             &config.Config{},
 				IsTrusted:          true,
 				NoHooks:            false,
 				NoOpen:             true, // User explicitly skipped editor
 			},
-			wantActions: 4,
+			wantActions: 7,
 			checkActions: func(t *testing.T, actions []Action) {
 				assert.IsType(t, PrintMessage{}, actions[0])
 				assert.IsType(t, CreateDirectory{}, actions[1])
 				assert.IsType(t, RunGitCommand{}, actions[2])
-				assert.IsType(t, PrintMessage{}, actions[3])
+				assert.Equal(t, ForgetMetadata{WorktreePath: "/sprout/feature"}, actions[3])
+				assert.Equal(t, RecordBase{WorktreePath: "/sprout/feature", Base: "origin/main"}, actions[4])
+				assert.Equal(t, CreateScratch{WorktreePath: "/sprout/feature"}, actions[5])
+				assert.IsType(t, PrintMessage{}, actions[6])
 
 				// Verify no editor action
 				for _, action := range actions {
@@ -309,18 +414,21 @@ func TestPlanAddCommand(t *testing.T) {
 				WorktreeExists:     false,
 				LocalBranchExists:  false,
 				RemoteBranchExists: false,
-				HasOriginMain:      true,
+				RemoteBase:         "origin/main",
 				Config:             &config.Config{Hooks: config.HooksConfig{OnCreate: []string{"npm install"}}},
 				IsTrusted:          true,
 				NoHooks:            true,
 				NoOpen:             true,
 			},
-			wantActions: 4,
+			wantActions: 7,
 			checkActions: func(t *testing.T, actions []Action) {
 				assert.IsType(t, PrintMessage{}, actions[0])
 				assert.IsType(t, CreateDirectory{}, actions[1])
 				assert.IsType(t, RunGitCommand{}, actions[2])
-				assert.IsType(t, PrintMessage{}, actions[3])
+				assert.Equal(t, ForgetMetadata{WorktreePath: "/sprout/feature"}, actions[3])
+				assert.Equal(t, RecordBase{WorktreePath: "/sprout/feature", Base: "origin/main"}, actions[4])
+				assert.Equal(t, CreateScratch{WorktreePath: "/sprout/feature"}, actions[5])
+				assert.IsType(t, PrintMessage{}, actions[6])
 
 				// Verify neither hooks nor editor
 				for _, action := range actions {
@@ -340,13 +448,13 @@ func TestPlanAddCommand(t *testing.T) {
 				WorktreeExists:     false,
 				LocalBranchExists:  true,
 				RemoteBranchExists: false,
-				HasOriginMain:      true,
+				RemoteBase:         "origin/main",
 				Config:             &config.Config{},
 				IsTrusted:          true,
 				NoHooks:            false,
 				NoOpen:             false,
 			},
-			wantActions: 5,
+			wantActions: 7,
 			checkActions: func(t *testing.T, actions []Action) {
 				assert.IsType(t, RunGitCommand{}, actions[2])
 				git := actions[2].(RunGitCommand)
@@ -363,13 +471,13 @@ func TestPlanAddCommand(t *testing.T) {
 				WorktreeExists:     false,
 				LocalBranchExists:  false,
 				RemoteBranchExists: true,
-				HasOriginMain:      true,
+				RemoteBase:         "origin/main",
 				Config:             &config.Config{},
 				IsTrusted:          true,
 				NoHooks:            false,
 				NoOpen:             false,
 			},
-			wantActions: 5,
+			wantActions: 7,
 			checkActions: func(t *testing.T, actions []Action) {
 				assert.IsType(t, RunGitCommand{}, actions[2])
 				git := actions[2].(RunGitCommand)
@@ -387,7 +495,7 @@ func TestPlanAddCommand(t *testing.T) {
 				WorktreeExists:     false,
 				LocalBranchExists:  false,
 				RemoteBranchExists: false,
-				HasOriginMain:      true,
+				RemoteBase:         "origin/main",
 				Config:             &config.Config{},
 				IsTrusted:          true,
 				NoHooks:            false,
@@ -411,7 +519,7 @@ func TestPlanAddCommand(t *testing.T) {
 				WorktreeExists:     false,
 				LocalBranchExists:  false,
 				RemoteBranchExists: false,
-				HasOriginMain:      true,
+				RemoteBase:         "origin/main",
 				Config:             &config.Config{},
 				IsTrusted:          true,
 				NoHooks:            false,
@@ -435,7 +543,7 @@ func TestPlanAddCommand(t *testing.T) {
 				WorktreeExists:     false,
 				LocalBranchExists:  false,
 				RemoteBranchExists: false,
-				HasOriginMain:      true,
+				RemoteBase:         "origin/main",
 				Config:             &config.Config{},
 				IsTrusted:          true,
 				NoHooks:            false,
@@ -459,7 +567,7 @@ func TestPlanAddCommand(t *testing.T) {
 				WorktreeExists:     false,
 				LocalBranchExists:  false,
 				RemoteBranchExists: false,
-				HasOriginMain:      true,
+				RemoteBase:         "origin/main",
 				Config:             nil, // Invalid
 				IsTrusted:          true,
 				NoHooks:            false,
@@ -488,3 +596,424 @@ func TestPlanAddCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestPlanAddCommand_RecordsTicket(t *testing.T) {
+	t.Parallel()
+
+	ticket := tickets.Ticket{ID: "ABC-123", Title: "Fix login"}
+	plan := PlanAddCommand(AddContext{
+		Branch:           "feat/ABC-123-fix-login",
+		RepoRoot:         "/repo",
+		MainWorktreePath: "/repo",
+		WorktreePath:     "/sprout/feat/ABC-123-fix-login",
+		RemoteBase:       "origin/main",
+		Config:           &config.Config{},
+		NoOpen:           true,
+		Ticket:           ticket,
+	})
+
+	require.Len(t, plan.Actions, 8)
+	assert.IsType(t, RunGitCommand{}, plan.Actions[2])
+	assert.Equal(t, ForgetMetadata{WorktreePath: "/sprout/feat/ABC-123-fix-login"}, plan.Actions[3], "left by a worktree removed from the path earlier")
+	assert.Equal(t, RecordTicket{WorktreePath: "/sprout/feat/ABC-123-fix-login", Ticket: ticket}, plan.Actions[4])
+	assert.IsType(t, RecordBase{}, plan.Actions[5])
+	assert.Equal(t, PrintMessage{Msg: msgWorktreeCreated}, plan.Actions[7])
+}
+
+func TestPlanAddCommand_DefaultHooks(t *testing.T) {
+	t.Parallel()
+
+	ctx := AddContext{
+		Branch:           "feature",
+		RepoRoot:         "/repo",
+		MainWorktreePath: "/repo",
+		WorktreePath:     "/sprout/feature",
+		RemoteBase:       "origin/main",
+		Config:           &config.Config{},
+		DefaultHooks:     []string{"direnv allow"},
+		NoOpen:           true,
+	}
+	lastHooks := func(t *testing.T, plan Plan) RunHooks {
+		t.Helper()
+		hooks, ok := plan.Actions[len(plan.Actions)-1].(RunHooks)
+		require.True(t, ok, "hooks run last")
+		return hooks
+	}
+
+	t.Run("run without trust", func(t *testing.T) {
+		plan := PlanAddCommand(ctx)
+
+		assert.Equal(t, []string{"direnv allow"}, lastHooks(t, plan).Commands)
+		for _, action := range plan.Actions {
+			assert.IsNotType(t, PromptTrust{}, action)
+			assert.IsNotType(t, TrustRepo{}, action)
+		}
+	})
+
+	t.Run("before the repository's hooks", func(t *testing.T) {
+		ctx := ctx
+		ctx.Config = &config.Config{Hooks: config.HooksConfig{OnCreate: []string{"npm ci"}}}
+
+		plan := PlanAddCommand(ctx)
+
+		assert.Equal(t, PromptTrust{MainWorktreePath: "/repo", HookType: HookTypeOnCreate, HookCommands: []string{"npm ci"}}, plan.Actions[0])
+		assert.Equal(t, []string{"direnv allow", "npm ci"}, lastHooks(t, plan).Commands)
+	})
+
+	t.Run("skipped with --no-hooks", func(t *testing.T) {
+		ctx := ctx
+		ctx.NoHooks = true
+
+		for _, action := range PlanAddCommand(ctx).Actions {
+			assert.IsNotType(t, RunHooks{}, action)
+		}
+	})
+
+	t.Run("skipped by policy", func(t *testing.T) {
+		ctx := ctx
+		ctx.HooksDenied = true
+
+		plan := PlanAddCommand(ctx)
+		assert.Equal(t, PrintMessage{Msg: "🚫 Skipping on_create hooks: disabled by organization trust policy"}, plan.Actions[0])
+		for _, action := range plan.Actions {
+			assert.IsNotType(t, RunHooks{}, action)
+		}
+	})
+}
+
+func TestPlanAddCommand_TemplateFiles(t *testing.T) {
+	t.Parallel()
+
+	plan := PlanAddCommand(AddContext{
+		Branch:        "feature",
+		RepoRoot:      "/repo",
+		WorktreePath:  "/sprout/feature",
+		RemoteBase:    "origin/main",
+		Config:        &config.Config{TemplateConflict: config.ConflictBackup},
+		NoOpen:        true,
+		TemplateDir:   "/repo/.sprout/template",
+		TemplateFiles: []string{".env.local", ".vscode/settings.json"},
+	})
+
+	require.Len(t, plan.Actions, 9)
+	assert.IsType(t, RunGitCommand{}, plan.Actions[2])
+	assert.IsType(t, RecordBase{}, plan.Actions[4])
+	assert.Equal(t, PrintMessage{Msg: "📄 Copying 2 template file(s) from /repo/.sprout/template"}, plan.Actions[5])
+	assert.Equal(t, Parallel{Actions: []Action{
+		CopyFile{Src: "/repo/.sprout/template/.env.local", Dst: "/sprout/feature/.env.local", OnConflict: config.ConflictBackup},
+		CopyFile{Src: "/repo/.sprout/template/.vscode/settings.json", Dst: "/sprout/feature/.vscode/settings.json", OnConflict: config.ConflictBackup},
+	}}, plan.Actions[6], "the copies run in parallel")
+	assert.Equal(t, CreateScratch{WorktreePath: "/sprout/feature"}, plan.Actions[7], "after the templates, before hooks")
+	assert.Equal(t, PrintMessage{Msg: msgWorktreeCreated}, plan.Actions[8])
+}
+
+func TestPlanAddCommand_SharedDirectories(t *testing.T) {
+	t.Parallel()
+
+	plan := PlanAddCommand(AddContext{
+		Branch:           "feature",
+		RepoRoot:         "/repo",
+		MainWorktreePath: "/repo",
+		WorktreePath:     "/sprout/feature",
+		RemoteBase:       "origin/main",
+		Config:           &config.Config{Share: []string{"node_modules", "web/.venv"}, ShareMode: config.ShareClone},
+		NoOpen:           true,
+	})
+
+	require.Len(t, plan.Actions, 8)
+	assert.IsType(t, RunGitCommand{}, plan.Actions[2])
+	assert.IsType(t, RecordBase{}, plan.Actions[4])
+	assert.Equal(t, Parallel{Actions: []Action{
+		ShareDirectory{Src: "/repo/node_modules", Dst: "/sprout/feature/node_modules", Mode: config.ShareClone},
+		ShareDirectory{Src: "/repo/web/.venv", Dst: "/sprout/feature/web/.venv", Mode: config.ShareClone},
+	}}, plan.Actions[5])
+	assert.Equal(t, PrintMessage{Msg: msgWorktreeCreated}, plan.Actions[7])
+}
+
+func TestPlanAddCommand_WorktreeFilesInParallel(t *testing.T) {
+	t.Parallel()
+
+	plan := PlanAddCommand(AddContext{
+		Branch:           "feature",
+		RepoRoot:         "/repo",
+		MainWorktreePath: "/repo",
+		WorktreePath:     "/sprout/feature",
+		Config:           &config.Config{Share: []string{"node_modules"}},
+		NoOpen:           true,
+		TemplateDir:      "/repo/.sprout/template",
+		TemplateFiles:    []string{".env"},
+		Artifacts:        []string{"target"},
+	})
+
+	actions := afterAdd(t, plan)
+	assert.Equal(t, []Action{
+		PrintMessage{Msg: "📄 Copying 1 template file(s) from /repo/.sprout/template"},
+		PrintMessage{Msg: "🧊 Cloning 1 build artifact dir(s) from the main worktree"},
+		Parallel{Actions: []Action{
+			CopyFile{Src: "/repo/.sprout/template/.env", Dst: "/sprout/feature/.env"},
+			ShareDirectory{Src: "/repo/node_modules", Dst: "/sprout/feature/node_modules"},
+			CloneDirectory{Src: "/repo/target", Dst: "/sprout/feature/target"},
+		}},
+	}, actions[1:4], "announced first, then copied together")
+}
+
+func TestPlanAddCommand_CloneArtifacts(t *testing.T) {
+	t.Parallel()
+
+	plan := PlanAddCommand(AddContext{
+		Branch:           "feature",
+		RepoRoot:         "/repo",
+		MainWorktreePath: "/repo",
+		WorktreePath:     "/sprout/feature",
+		RemoteBase:       "origin/main",
+		Config:           &config.Config{},
+		NoOpen:           true,
+		Artifacts:        []string{"target"},
+	})
+
+	require.Len(t, plan.Actions, 9)
+	assert.IsType(t, RecordBase{}, plan.Actions[4])
+	assert.Equal(t, PrintMessage{Msg: "🧊 Cloning 1 build artifact dir(s) from the main worktree"}, plan.Actions[5])
+	assert.Equal(t, CloneDirectory{Src: "/repo/target", Dst: "/sprout/feature/target"}, plan.Actions[6])
+}
+
+func TestPlanAddCommand_RecordsBase(t *testing.T) {
+	t.Parallel()
+
+	ctx := AddContext{
+		Branch:       "feature",
+		RepoRoot:     "/repo",
+		WorktreePath: "/sprout/feature",
+		HeadBranch:   "develop",
+		Config:       &config.Config{},
+		NoOpen:       true,
+	}
+	recorded := func(ctx AddContext) []RecordBase {
+		var bases []RecordBase
+		for _, action := range PlanAddCommand(ctx).Actions {
+			if a, ok := action.(RecordBase); ok {
+				bases = append(bases, a)
+			}
+		}
+		return bases
+	}
+
+	assert.Equal(t, []RecordBase{{WorktreePath: "/sprout/feature", Base: "develop"}}, recorded(ctx),
+		"without origin/main the branch starts from the current branch")
+
+	fromMain := ctx
+	fromMain.RemoteBase = "origin/main"
+	assert.Equal(t, []RecordBase{{WorktreePath: "/sprout/feature", Base: "origin/main"}}, recorded(fromMain))
+
+	detached := ctx
+	detached.HeadBranch = ""
+	assert.Empty(t, recorded(detached))
+
+	existing := fromMain
+	existing.LocalBranchExists = true
+	assert.Empty(t, recorded(existing), "where an existing branch started is unknown")
+}
+
+func TestPlanAddCommand_ApplyChanges(t *testing.T) {
+	t.Parallel()
+	base := AddContext{
+		Branch:       "feature",
+		RepoRoot:     "/repo",
+		WorktreePath: "/sprout/feature",
+		RemoteBase:   "origin/main",
+		Config:       &config.Config{},
+		NoOpen:       true,
+	}
+
+	t.Run("stash entry is applied after checkout", func(t *testing.T) {
+		t.Parallel()
+		ctx := base
+		ctx.FromStash = "stash@{1}"
+
+		plan := PlanAddCommand(ctx)
+
+		assert.Equal(t, []string{"worktree", "add"}, plan.Actions[2].(RunGitCommand).Args[:2])
+		assert.Equal(t, PrintMessage{Msg: "📦 Applying stash@{1}"}, plan.Actions[3])
+		assert.Equal(t, RunGitCommand{Dir: "/sprout/feature", Args: []string{"stash", "apply", "stash@{1}"}}, plan.Actions[4])
+	})
+
+	t.Run("patch file is applied after checkout", func(t *testing.T) {
+		t.Parallel()
+		ctx := base
+		ctx.PatchFile = "/tmp/fix.diff"
+
+		plan := PlanAddCommand(ctx)
+
+		assert.Equal(t, PrintMessage{Msg: "🩹 Applying /tmp/fix.diff"}, plan.Actions[3])
+		assert.Equal(t, RunGitCommand{Dir: "/sprout/feature", Args: []string{"apply", "/tmp/fix.diff"}}, plan.Actions[4])
+	})
+
+	t.Run("existing worktree", func(t *testing.T) {
+		t.Parallel()
+		ctx := base
+		ctx.WorktreeExists = true
+		ctx.FromStash = "stash@{0}"
+
+		plan := PlanAddCommand(ctx)
+
+		require.Len(t, plan.Actions, 2)
+		assert.Equal(t, PrintError{Msg: "/sprout/feature already exists; --from-stash only applies changes to a new worktree"}, plan.Actions[0])
+		assert.Equal(t, Exit{Code: 1}, plan.Actions[1])
+	})
+}
+
+func TestPlanAddCommand_TTL(t *testing.T) {
+	t.Parallel()
+	expiresAt := time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC)
+	ctx := AddContext{
+		Branch:       "spike",
+		RepoRoot:     "/repo",
+		WorktreePath: "/sprout/spike",
+		RemoteBase:   "origin/main",
+		Config:       &config.Config{},
+		NoOpen:       true,
+		ExpiresAt:    expiresAt,
+	}
+
+	assert.Contains(t, PlanAddCommand(ctx).Actions, SetExpiry{WorktreePath: "/sprout/spike", ExpiresAt: expiresAt})
+
+	permanent := ctx
+	permanent.ExpiresAt = time.Time{}
+	for _, action := range PlanAddCommand(permanent).Actions {
+		_, ok := action.(SetExpiry)
+		assert.False(t, ok, "worktrees without --ttl are permanent")
+	}
+
+	existing := ctx
+	existing.WorktreeExists = true
+	assert.Equal(t, []Action{
+		PrintError{Msg: "/sprout/spike already exists; --ttl only makes a new worktree temporary"},
+		Exit{Code: 1},
+	}, PlanAddCommand(existing).Actions)
+}
+
+func TestPlanAddCommand_Tag(t *testing.T) {
+	t.Parallel()
+	ctx := AddContext{
+		Branch:       "hotfix/1.4.3",
+		RepoRoot:     "/repo",
+		WorktreePath: "/sprout/hotfix/1.4.3",
+		RemoteBase:   "origin/main",
+		Config:       &config.Config{},
+		NoOpen:       true,
+		Tag:          "v1.4.2",
+	}
+
+	plan := PlanAddCommand(ctx)
+	assert.Equal(t, RunGitCommand{
+		Dir:  "/repo",
+		Args: []string{"worktree", "add", "/sprout/hotfix/1.4.3", "-b", "hotfix/1.4.3", "--no-track", "refs/tags/v1.4.2"},
+	}, plan.Actions[2], "the tag, not origin/main, is the start point")
+	assert.Equal(t, RecordBase{WorktreePath: "/sprout/hotfix/1.4.3", Base: "v1.4.2"}, plan.Actions[4])
+
+	for _, exists := range []func(*AddContext){
+		func(c *AddContext) { c.LocalBranchExists = true },
+		func(c *AddContext) { c.RemoteBranchExists = true },
+	} {
+		existing := ctx
+		exists(&existing)
+		assert.Equal(t, []Action{
+			PrintError{Msg: "branch hotfix/1.4.3 already exists; --tag starts a new branch at the tag"},
+			Exit{Code: 1},
+		}, PlanAddCommand(existing).Actions)
+	}
+}
+
+func TestPlanAddCommand_Detach(t *testing.T) {
+	t.Parallel()
+	ctx := AddContext{
+		Branch:       "release",
+		RepoRoot:     "/repo",
+		WorktreePath: "/sprout/release/repo",
+		RemoteBase:   "origin/main",
+		Config:       &config.Config{},
+		NoOpen:       true,
+		Detach:       "3f2a9c1d6b0e4a7c8f9e2d1b0a3c4e5f6a7b8c9d",
+	}
+
+	plan := PlanAddCommand(ctx)
+	assert.Equal(t, RunGitCommand{
+		Dir:  "/repo",
+		Args: []string{"worktree", "add", "--detach", "/sprout/release/repo", "3f2a9c1d6b0e4a7c8f9e2d1b0a3c4e5f6a7b8c9d"},
+	}, plan.Actions[2])
+	for _, action := range plan.Actions {
+		_, isBase := action.(RecordBase)
+		assert.False(t, isBase, "a detached HEAD has no base")
+	}
+
+	existing := ctx
+	existing.WorktreeExists = true
+	assert.Equal(t, []Action{
+		PrintError{Msg: "/sprout/release/repo already exists; give the detached worktree another label"},
+		Exit{Code: 1},
+	}, PlanAddCommand(existing).Actions)
+}
+
+func TestPlanAddCommand_CheckedOutElsewhere(t *testing.T) {
+	t.Parallel()
+	ctx := AddContext{
+		Branch:            "feature",
+		RepoRoot:          "/repo",
+		WorktreePath:      "/sprout/feature/repo",
+		LocalBranchExists: true,
+		Config:            &config.Config{},
+		NoOpen:            true,
+		CheckedOutAt:      "/repo",
+	}
+
+	assert.Equal(t, []Action{
+		PrintError{Msg: "branch feature is already checked out at /repo; open it there with 'sprout open /repo', or pass --force-create to check it out here as well"},
+		Exit{Code: 1},
+	}, PlanAddCommand(ctx).Actions)
+
+	ctx.Force = true
+	assert.Equal(t, RunGitCommand{
+		Dir:  "/repo",
+		Args: []string{"worktree", "add", "--force", "/sprout/feature/repo", "feature"},
+	}, PlanAddCommand(ctx).Actions[2])
+}
+
+func TestPlanAddCommand_Lock(t *testing.T) {
+	t.Parallel()
+	ctx := AddContext{
+		Branch:            "feature",
+		RepoRoot:          "/repo",
+		WorktreePath:      "/mnt/usb/feature/repo",
+		LocalBranchExists: true,
+		Config:            &config.Config{},
+		NoOpen:            true,
+		Lock:              true,
+		LockReason:        "on the USB drive",
+	}
+
+	plan := PlanAddCommand(ctx)
+	assert.Equal(t, RunGitCommand{
+		Dir:  "/repo",
+		Args: []string{"worktree", "add", "--lock", "--reason", "on the USB drive", "/mnt/usb/feature/repo", "feature"},
+	}, plan.Actions[2])
+	assert.Contains(t, plan.Actions, PrintMessage{Msg: "🔒 Locked, so git won't prune it while its directory is missing; unlock it with 'git worktree unlock /mnt/usb/feature/repo'"})
+
+	ctx.LockReason = ""
+	ctx.Force = true
+	assert.Equal(t, []string{"worktree", "add", "--lock", "--force", "/mnt/usb/feature/repo", "feature"}, PlanAddCommand(ctx).Actions[2].(RunGitCommand).Args)
+
+	existing := ctx
+	existing.WorktreeExists = true
+	assert.Equal(t, []Action{
+		PrintError{Msg: "/mnt/usb/feature/repo already exists; lock it with 'git worktree lock /mnt/usb/feature/repo'"},
+		Exit{Code: 1},
+	}, PlanAddCommand(existing).Actions)
+}
+
+func TestStashRef(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "stash@{0}", StashRef("0"))
+	assert.Equal(t, "stash@{12}", StashRef("12"))
+	assert.Equal(t, "stash@{1}", StashRef("stash@{1}"))
+	assert.Equal(t, "abc123", StashRef("abc123"))
+}
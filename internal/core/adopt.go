@@ -0,0 +1,97 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// Adopt errors
+var (
+	ErrNotAWorktree      = errors.New("not a worktree of this repository")
+	ErrAdoptMainWorktree = errors.New("cannot adopt the main worktree")
+	ErrAdoptMoveDetached = errors.New("cannot move a detached HEAD worktree: its location is derived from the branch name")
+	ErrDestinationExists = errors.New("destination already exists")
+)
+
+// AdoptContext contains all inputs needed to plan the adopt command.
+type AdoptContext struct {
+	RepoRoot         string // Directory git commands run in
+	MainWorktreePath string // Repo identity; adopted entries are recorded against it
+	SproutRoot       string // Global sprout root
+	Adopted          []string
+
+	// TargetPath is the worktree to adopt; IsWorktree reports whether git lists it
+	TargetPath string
+	Branch     string
+	IsWorktree bool
+
+	// Move relocates the worktree under the sprout root instead of recording it
+	Move       bool
+	DestPath   string // Where the worktree would live if sprout had created it
+	DestExists bool
+}
+
+// PlanAdoptCommand creates a plan that brings an existing worktree under sprout's management.
+//
+// Without Move the worktree stays where it is and is recorded in the adopted
+// worktrees store. With Move it is relocated with `git worktree move` to the
+// path `sprout add` would have used, which needs no extra bookkeeping.
+func PlanAdoptCommand(ctx AdoptContext) Plan {
+	if ctx.TargetPath == "" {
+		return errorPlan(ErrEmptyTargetPath)
+	}
+	if !ctx.IsWorktree {
+		return errorPlan(fmt.Errorf("%s: %w", ctx.TargetPath, ErrNotAWorktree))
+	}
+	if filepath.Clean(ctx.TargetPath) == filepath.Clean(ctx.MainWorktreePath) {
+		return errorPlan(ErrAdoptMainWorktree)
+	}
+
+	underRoot := IsUnderSproutRoot(ctx.TargetPath, ctx.SproutRoot)
+	adopted := IsAdopted(ctx.TargetPath, ctx.Adopted)
+
+	if !ctx.Move {
+		if underRoot || adopted {
+			return Plan{Actions: []Action{
+				PrintMessage{Msg: fmt.Sprintf("✓ Already managed by sprout: %s", ctx.TargetPath)},
+			}}
+		}
+		return Plan{Actions: []Action{
+			AdoptWorktree{RepoRoot: ctx.MainWorktreePath, Path: ctx.TargetPath},
+			PrintMessage{Msg: fmt.Sprintf("🌱 Adopted worktree: %s", ctx.TargetPath)},
+		}}
+	}
+
+	if underRoot {
+		return Plan{Actions: []Action{
+			PrintMessage{Msg: fmt.Sprintf("✓ Already under the sprout root: %s", ctx.TargetPath)},
+		}}
+	}
+	if ctx.Branch == "" {
+		return errorPlan(ErrAdoptMoveDetached)
+	}
+	if ctx.DestPath == "" {
+		return errorPlan(ErrEmptyWorktreePath)
+	}
+	if ctx.DestExists {
+		return errorPlan(fmt.Errorf("%w: %s", ErrDestinationExists, ctx.DestPath))
+	}
+
+	actions := []Action{
+		CreateDirectory{Path: filepath.Dir(ctx.DestPath), Perm: 0755},
+		RunGitCommand{
+			Dir:  ctx.RepoRoot,
+			Args: []string{"worktree", "move", ctx.TargetPath, ctx.DestPath},
+		},
+	}
+	if adopted {
+		// The worktree is now found by location; drop the stale record
+		actions = append(actions, ForgetWorktree{Path: ctx.TargetPath})
+	}
+	actions = append(actions, PrintMessage{
+		Msg: fmt.Sprintf("🌱 Moved worktree: %s → %s", ctx.TargetPath, ctx.DestPath),
+	})
+
+	return Plan{Actions: actions}
+}
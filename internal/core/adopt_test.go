@@ -0,0 +1,80 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanAdoptCommand(t *testing.T) {
+	base := AdoptContext{
+		RepoRoot:         "/repo",
+		MainWorktreePath: "/repo",
+		SproutRoot:       "/sprout",
+		TargetPath:       "/manual/feature",
+		Branch:           "feature",
+		IsWorktree:       true,
+	}
+
+	t.Run("records worktree in place", func(t *testing.T) {
+		plan := PlanAdoptCommand(base)
+
+		require.Len(t, plan.Actions, 2)
+		assert.Equal(t, AdoptWorktree{RepoRoot: "/repo", Path: "/manual/feature"}, plan.Actions[0])
+	})
+
+	t.Run("already adopted is a no-op", func(t *testing.T) {
+		ctx := base
+		ctx.Adopted = []string{"/manual/feature"}
+		plan := PlanAdoptCommand(ctx)
+
+		require.Len(t, plan.Actions, 1)
+		_, isMsg := plan.Actions[0].(PrintMessage)
+		assert.True(t, isMsg)
+	})
+
+	t.Run("move relocates with git worktree move", func(t *testing.T) {
+		ctx := base
+		ctx.Move = true
+		ctx.Adopted = []string{"/manual/feature"}
+		ctx.DestPath = "/sprout/repo-1234/feature/repo"
+		plan := PlanAdoptCommand(ctx)
+
+		require.Len(t, plan.Actions, 4)
+		assert.Equal(t, CreateDirectory{Path: "/sprout/repo-1234/feature", Perm: 0755}, plan.Actions[0])
+		assert.Equal(t, RunGitCommand{
+			Dir:  "/repo",
+			Args: []string{"worktree", "move", "/manual/feature", "/sprout/repo-1234/feature/repo"},
+		}, plan.Actions[1])
+		assert.Equal(t, ForgetWorktree{Path: "/manual/feature"}, plan.Actions[2], "stale adoption record is dropped after moving")
+	})
+
+	errorCases := []struct {
+		name   string
+		modify func(*AdoptContext)
+	}{
+		{"not a worktree", func(c *AdoptContext) { c.IsWorktree = false }},
+		{"main worktree", func(c *AdoptContext) { c.TargetPath = "/repo" }},
+		{"move detached HEAD", func(c *AdoptContext) { c.Move = true; c.Branch = "" }},
+		{"move onto existing path", func(c *AdoptContext) {
+			c.Move = true
+			c.DestPath = "/sprout/repo-1234/feature/repo"
+			c.DestExists = true
+		}},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := base
+			tc.modify(&ctx)
+			plan := PlanAdoptCommand(ctx)
+
+			require.Len(t, plan.Actions, 2)
+			assert.Equal(t, Exit{Code: 1}, plan.Actions[1])
+			for _, action := range plan.Actions {
+				_, isAdopt := action.(AdoptWorktree)
+				assert.False(t, isAdopt)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ExpandAlias returns the words the command name stands for under aliases
+// (see config.UserConfig.Aliases), following an alias of an alias until a
+// command that is none. ok is false when name isn't an alias, and always
+// for built-in commands, which aliases never shadow. An alias that leads
+// back to itself is an error.
+func ExpandAlias(name string, aliases map[string]string, builtin func(string) bool) (words []string, ok bool, err error) {
+	words = []string{name}
+	var chain []string
+	for {
+		expansion, isAlias := aliases[words[0]]
+		if !isAlias || builtin(words[0]) {
+			return words, len(chain) > 0, nil
+		}
+		if slices.Contains(chain, words[0]) {
+			return nil, false, fmt.Errorf("alias %s expands to itself: %s", name, strings.Join(append(chain, words[0]), " → "))
+		}
+		fields := strings.Fields(expansion)
+		if len(fields) == 0 {
+			return nil, false, fmt.Errorf("alias %s is empty", words[0])
+		}
+		chain = append(chain, words[0])
+		words = append(fields, words[1:]...)
+	}
+}
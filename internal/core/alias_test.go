@@ -0,0 +1,44 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandAlias(t *testing.T) {
+	t.Parallel()
+
+	aliases := map[string]string{
+		"a":     "add --no-open",
+		"rm":    "remove --force",
+		"tmp":   "a --ttl 2h",
+		"list":  "list --details",
+		"loop":  "again",
+		"again": "loop x",
+	}
+	builtin := func(name string) bool { return name == "add" || name == "remove" || name == "list" }
+
+	words, ok, err := ExpandAlias("a", aliases, builtin)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"add", "--no-open"}, words)
+
+	words, ok, err = ExpandAlias("tmp", aliases, builtin)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"add", "--no-open", "--ttl", "2h"}, words, "aliases of aliases")
+
+	words, ok, err = ExpandAlias("list", aliases, builtin)
+	require.NoError(t, err)
+	assert.False(t, ok, "built-in commands are never shadowed")
+	assert.Equal(t, []string{"list"}, words)
+
+	_, ok, err = ExpandAlias("open", aliases, builtin)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = ExpandAlias("loop", aliases, builtin)
+	assert.EqualError(t, err, "alias loop expands to itself: loop → again → loop")
+}
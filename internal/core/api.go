@@ -0,0 +1,137 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/m44rten1/sprout/internal/git"
+)
+
+// APIVersion is the version of the 'sprout api' schema. Adding a field
+// keeps it; removing one or changing its meaning bumps it.
+const APIVersion = 1
+
+// Error codes of APIError, for clients to branch on instead of messages.
+const (
+	APIErrInvalidRequest = "invalid_request" // Malformed request, or a required field is missing
+	APIErrNotFound       = "not_found"       // No worktree matches the request
+	APIErrNonInteractive = "non_interactive" // The operation needs a prompt (e.g. trusting hooks)
+	APIErrGit            = "git"             // A git command failed; see APIError.Git
+	APIErrFailed         = "failed"          // Anything else
+)
+
+// Errors reported with their own APIError codes.
+var (
+	ErrInvalidAPIRequest = errors.New("invalid request")
+	ErrNoWorktreeFound   = errors.New("no worktree found")
+)
+
+// APIRequest is the JSON object read from stdin by the api subcommands that
+// take input. Fields a subcommand doesn't use are ignored.
+type APIRequest struct {
+	Version int    `json:"version"` // Schema the client was written for; 0 means APIVersion
+	Branch  string `json:"branch"`
+	NoHooks bool   `json:"no_hooks"` // create: skip on_create hooks
+	Trust   bool   `json:"trust"`    // create: trust the repository if hooks would run
+	Force   bool   `json:"force"`    // remove: remove even with uncommitted changes
+}
+
+// APIResponse is the JSON object every api subcommand writes to stdout,
+// whether it succeeds or not.
+type APIResponse struct {
+	Version   int           `json:"version"`
+	Worktrees []APIWorktree `json:"worktrees,omitempty"` // list-worktrees
+	Worktree  *APIWorktree  `json:"worktree,omitempty"`  // resolve-branch, create, remove
+	Created   bool          `json:"created,omitempty"`   // create: false if the worktree already existed
+	Error     *APIError     `json:"error,omitempty"`
+}
+
+// APIError describes a failed api request.
+type APIError struct {
+	Code    string        `json:"code"`
+	Message string        `json:"message"`
+	Git     *git.GitError `json:"git,omitempty"` // Set when a git command failed
+}
+
+// APIWorktree describes a worktree of the repository.
+type APIWorktree struct {
+	Path   string     `json:"path"`
+	Branch string     `json:"branch,omitempty"` // Empty for a detached HEAD
+	Main   bool       `json:"main"`
+	Base   string     `json:"base,omitempty"`   // Ref the branch was created from, if recorded
+	Ticket string     `json:"ticket,omitempty"` // Ticket summary, if created with --ticket
+	Pinned bool       `json:"pinned,omitempty"`
+	Note   string     `json:"note,omitempty"`   // From 'sprout note'
+	Status *APIStatus `json:"status,omitempty"` // Only set by list-worktrees
+}
+
+// APIStatus is the git state of a worktree.
+type APIStatus struct {
+	Dirty         bool `json:"dirty"`
+	UntrackedOnly bool `json:"untracked_only"`
+	Ahead         int  `json:"ahead"`
+	Behind        int  `json:"behind"`
+	Unmerged      bool `json:"unmerged"`
+	Stash         bool `json:"stash"`
+}
+
+// ParseAPIRequest decodes a request read from stdin. Empty input is an
+// empty request. Unknown fields are rejected, so a typo doesn't silently
+// fall back to a default, and so are requests for a newer schema.
+func ParseAPIRequest(data []byte) (APIRequest, error) {
+	var req APIRequest
+	if len(bytes.TrimSpace(data)) == 0 {
+		return req, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		return APIRequest{}, fmt.Errorf("%w: %v", ErrInvalidAPIRequest, err)
+	}
+	if req.Version > APIVersion {
+		return APIRequest{}, fmt.Errorf("%w: written for api version %d, but this sprout speaks version %d", ErrInvalidAPIRequest, req.Version, APIVersion)
+	}
+	return req, nil
+}
+
+// NewAPIWorktree converts a listed worktree for an api response.
+func NewAPIWorktree(item WorktreeDisplayItem) APIWorktree {
+	return APIWorktree{
+		Path:   item.Path,
+		Branch: item.Branch,
+		Main:   item.IsMain,
+		Base:   item.Base,
+		Ticket: item.Ticket,
+		Pinned: item.Pinned,
+		Note:   item.Note,
+		Status: &APIStatus{
+			Dirty:         item.Status.Dirty,
+			UntrackedOnly: item.Status.UntrackedOnly,
+			Ahead:         item.Status.Ahead,
+			Behind:        item.Status.Behind,
+			Unmerged:      item.Status.Unmerged,
+			Stash:         item.Status.HasStash,
+		},
+	}
+}
+
+// PlanAPIResponse creates a plan that prints resp as a single line of JSON,
+// stamped with APIVersion.
+func PlanAPIResponse(resp APIResponse) Plan {
+	resp.Version = APIVersion
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return errorPlan(fmt.Errorf("failed to encode response: %w", err))
+	}
+	return Plan{Actions: []Action{PrintMessage{Msg: string(data)}}}
+}
+
+// PlanErr returns the error of a plan that only fails (such as a plan for
+// invalid input), or nil for any other plan. Callers that report failures
+// in their own format check it instead of executing the plan.
+func PlanErr(plan Plan) error {
+	return plan.Err
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAPIRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    APIRequest
+		wantErr bool
+	}{
+		{name: "empty input", input: "", want: APIRequest{}},
+		{name: "whitespace only", input: " \n", want: APIRequest{}},
+		{
+			name:  "create request",
+			input: `{"version": 1, "branch": "feature", "no_hooks": true}`,
+			want:  APIRequest{Version: 1, Branch: "feature", NoHooks: true},
+		},
+		{name: "unknown field", input: `{"brnach": "feature"}`, wantErr: true},
+		{name: "malformed", input: `{"branch":`, wantErr: true},
+		{name: "newer version", input: `{"version": 2, "branch": "feature"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseAPIRequest([]byte(tt.input))
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidAPIRequest)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPlanAPIResponse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stamps the version and omits unset results", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanAPIResponse(APIResponse{
+			Worktree: &APIWorktree{Path: "/wt/feature", Branch: "feature"},
+			Created:  true,
+		})
+		assert.Equal(t, []Action{PrintMessage{
+			Msg: `{"version":1,"worktree":{"path":"/wt/feature","branch":"feature","main":false},"created":true}`,
+		}}, plan.Actions)
+	})
+
+	t.Run("listed worktree with status", func(t *testing.T) {
+		t.Parallel()
+		wt := NewAPIWorktree(WorktreeDisplayItem{
+			Branch: "feature",
+			Path:   "/wt/feature",
+			Status: git.WorktreeStatus{Dirty: true, Ahead: 2},
+			Base:   "origin/main",
+		})
+		plan := PlanAPIResponse(APIResponse{Worktrees: []APIWorktree{wt}})
+		assert.Equal(t, []Action{PrintMessage{
+			Msg: `{"version":1,"worktrees":[{"path":"/wt/feature","branch":"feature","main":false,"base":"origin/main",` +
+				`"status":{"dirty":true,"untracked_only":false,"ahead":2,"behind":0,"unmerged":false,"stash":false}}]}`,
+		}}, plan.Actions)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanAPIResponse(APIResponse{Error: &APIError{Code: APIErrNotFound, Message: "no worktree found"}})
+		assert.Equal(t, []Action{PrintMessage{
+			Msg: `{"version":1,"error":{"code":"not_found","message":"no worktree found"}}`,
+		}}, plan.Actions)
+	})
+}
+
+func TestPlanErr(t *testing.T) {
+	t.Parallel()
+
+	err := PlanErr(errorPlan(errors.New("boom")))
+	assert.EqualError(t, err, "boom")
+
+	sentinel := errors.New("sentinel")
+	assert.ErrorIs(t, PlanErr(errorPlan(fmt.Errorf("wrapped: %w", sentinel))), sentinel)
+
+	assert.NoError(t, PlanErr(Plan{}))
+	assert.NoError(t, PlanErr(Plan{Actions: []Action{PrintMessage{Msg: "hi"}, Exit{Code: 1}}}))
+	assert.NoError(t, PlanErr(Plan{Actions: []Action{PrintError{Msg: "warning"}, Exit{Code: 1}}}), "a failure printed by a plan that does more")
+}
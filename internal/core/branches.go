@@ -1,6 +1,64 @@
 package core
 
-import "github.com/m44rten1/sprout/internal/git"
+import (
+	"strings"
+
+	"github.com/m44rten1/sprout/internal/git"
+)
+
+// branchArgPrefixes are stripped from branch arguments, longest first.
+var branchArgPrefixes = []string{"refs/heads/", "refs/remotes/origin/", "remotes/origin/", "origin/"}
+
+// NormalizeBranchArg strips the prefix a branch argument may carry when it
+// is copied from 'git branch -a' or names the remote-tracking branch:
+// "origin/feature" and "refs/heads/feature" are both feature.
+func NormalizeBranchArg(arg string) string {
+	for _, prefix := range branchArgPrefixes {
+		if rest, ok := strings.CutPrefix(arg, prefix); ok && rest != "" {
+			return rest
+		}
+	}
+	return arg
+}
+
+// MatchBranchName returns the index of the branch name arg refers to:
+// the name arg is exactly, otherwise the only one it equals once
+// normalized (see NormalizeBranchArg) and ignoring case. Returns -1 when
+// there is none, or when several match that way. Empty names never match.
+func MatchBranchName(names []string, arg string) int {
+	if arg == "" {
+		return -1
+	}
+	for i, name := range names {
+		if name != "" && name == arg {
+			return i
+		}
+	}
+
+	normalized := NormalizeBranchArg(arg)
+	match := -1
+	for i, name := range names {
+		if name == "" || !strings.EqualFold(name, normalized) {
+			continue
+		}
+		if match >= 0 {
+			return -1
+		}
+		match = i
+	}
+	return match
+}
+
+// MatchWorktreeBranch returns the index of the worktree, the main one
+// included, that has the branch arg refers to checked out (see
+// MatchBranchName), or -1.
+func MatchWorktreeBranch(worktrees []git.Worktree, arg string) int {
+	names := make([]string, len(worktrees))
+	for i, wt := range worktrees {
+		names[i] = wt.Branch
+	}
+	return MatchBranchName(names, arg)
+}
 
 // GetWorktreeAvailableBranches returns branches that can be used to create new worktrees.
 // Excludes branches currently checked out in any worktree.
@@ -25,3 +83,48 @@ func GetWorktreeAvailableBranches(allBranches []git.Branch, worktrees []git.Work
 	}
 	return available
 }
+
+// BranchChoice is an entry of the branch picker of 'sprout add'.
+// CheckedOutAt is the worktree that has the branch checked out, the main
+// one included; git checks a branch out in one worktree at a time, so the
+// picker shows where it is instead of listing it.
+type BranchChoice struct {
+	Branch       git.Branch
+	CheckedOutAt string
+}
+
+// Available reports whether a new worktree can check out the branch.
+func (c BranchChoice) Available() bool {
+	return c.CheckedOutAt == ""
+}
+
+// BranchChoices returns the branches to pick from for a new worktree, as
+// GetWorktreeAvailableBranches does, followed by the branches checked out
+// in a worktree, each once, so the user can see where they went.
+func BranchChoices(allBranches []git.Branch, worktrees []git.Worktree) []BranchChoice {
+	checkedOut := make(map[string]string, len(worktrees))
+	for _, wt := range worktrees {
+		if wt.Branch != "" {
+			checkedOut[wt.Branch] = wt.Path
+		}
+	}
+
+	var choices, unavailable []BranchChoice
+	listed := make(map[string]bool)
+	for _, branch := range allBranches {
+		if branch.Name == "" {
+			continue
+		}
+		path, ok := checkedOut[branch.Name]
+		if !ok {
+			choices = append(choices, BranchChoice{Branch: branch})
+			continue
+		}
+		// The local branch and its remote-tracking branch are one entry
+		if !listed[branch.Name] {
+			listed[branch.Name] = true
+			unavailable = append(unavailable, BranchChoice{Branch: branch, CheckedOutAt: path})
+		}
+	}
+	return append(choices, unavailable...)
+}
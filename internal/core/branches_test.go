@@ -7,6 +7,30 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func TestNormalizeBranchArg(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "feature", NormalizeBranchArg("feature"))
+	assert.Equal(t, "feat/login", NormalizeBranchArg("origin/feat/login"))
+	assert.Equal(t, "feat/login", NormalizeBranchArg("remotes/origin/feat/login"))
+	assert.Equal(t, "feat/login", NormalizeBranchArg("refs/remotes/origin/feat/login"))
+	assert.Equal(t, "feat/login", NormalizeBranchArg("refs/heads/feat/login"))
+	assert.Equal(t, "origin/", NormalizeBranchArg("origin/"), "nothing is left to name a branch")
+	assert.Equal(t, "upstream/feature", NormalizeBranchArg("upstream/feature"))
+}
+
+func TestMatchBranchName(t *testing.T) {
+	t.Parallel()
+
+	names := []string{"main", "", "Feature", "feature", "fix/Login"}
+	assert.Equal(t, 3, MatchBranchName(names, "feature"), "exact names win")
+	assert.Equal(t, 2, MatchBranchName(names, "Feature"))
+	assert.Equal(t, 4, MatchBranchName(names, "origin/fix/login"), "prefix and case are ignored")
+	assert.Equal(t, -1, MatchBranchName(names, "FEATURE"), "ambiguous without an exact match")
+	assert.Equal(t, -1, MatchBranchName(names, "fix"))
+	assert.Equal(t, -1, MatchBranchName(names, ""))
+}
+
 func TestGetWorktreeAvailableBranches(t *testing.T) {
 	t.Parallel()
 
@@ -118,3 +142,31 @@ func TestGetWorktreeAvailableBranches(t *testing.T) {
 		})
 	}
 }
+
+func TestBranchChoices(t *testing.T) {
+	t.Parallel()
+
+	remote := func(name string) git.Branch {
+		return git.Branch{RefName: "origin/" + name, DisplayName: name, Name: name}
+	}
+	branches := []git.Branch{
+		MakeBranch("main"),
+		remote("main"),
+		MakeBranch("feature"),
+		remote("bugfix"),
+		{RefName: "origin/HEAD"},
+	}
+	worktrees := []git.Worktree{
+		MakeWorktree("/code/app", "main"),
+		{Path: "/sprout/app/detached/app", HEAD: "3f2a9c1"},
+	}
+
+	choices := BranchChoices(branches, worktrees)
+	assert.Equal(t, []BranchChoice{
+		{Branch: MakeBranch("feature")},
+		{Branch: remote("bugfix")},
+		{Branch: MakeBranch("main"), CheckedOutAt: "/code/app"},
+	}, choices)
+	assert.True(t, choices[0].Available())
+	assert.False(t, choices[2].Available())
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Placeholders supported in branch_template.
+const (
+	placeholderUser   = "user"
+	placeholderTicket = "ticket"
+	placeholderSlug   = "slug"
+)
+
+// ErrNoBranchTemplate is returned when --ticket is used without a branch_template.
+var ErrNoBranchTemplate = errors.New("--ticket requires a branch_template in .sprout.yml (e.g. branch_template: \"feat/{user}/{ticket}-{slug}\")")
+
+// BranchTemplateVars holds the values substituted into a branch_template.
+type BranchTemplateVars struct {
+	User   string // Already slugified git user
+	Ticket string // Ticket id as given, e.g. "ABC-123"
+	Title  string // Free-form description, slugified into {slug}
+}
+
+// ExpandBranchTemplate builds a branch name from a template such as
+// "feat/{user}/{ticket}-{slug}". Unknown placeholders, placeholders without
+// a value, and results that git would reject are reported as errors.
+func ExpandBranchTemplate(template string, vars BranchTemplateVars) (string, error) {
+	if template == "" {
+		return "", ErrNoBranchTemplate
+	}
+
+	values := map[string]string{
+		placeholderUser:   vars.User,
+		placeholderTicket: vars.Ticket,
+		placeholderSlug:   Slugify(vars.Title),
+	}
+	missing := map[string]string{
+		placeholderUser:   "git user.email is not set",
+		placeholderTicket: "no --ticket was given",
+		placeholderSlug:   "no description was given",
+	}
+
+	var b strings.Builder
+	rest := template
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			b.WriteString(rest)
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("invalid branch_template %q: unclosed '{'", template)
+		}
+		end += start
+
+		name := rest[start+1 : end]
+		value, ok := values[name]
+		if !ok {
+			return "", fmt.Errorf("invalid branch_template %q: unknown placeholder {%s} (supported: {user}, {ticket}, {slug})", template, name)
+		}
+		if value == "" {
+			return "", fmt.Errorf("branch_template uses {%s} but %s", name, missing[name])
+		}
+
+		b.WriteString(rest[:start])
+		b.WriteString(value)
+		rest = rest[end+1:]
+	}
+
+	branch := b.String()
+	if err := ValidateBranchName(branch); err != nil {
+		return "", fmt.Errorf("branch_template %q expands to an invalid branch name: %w", template, err)
+	}
+	return branch, nil
+}
+
+// Slugify lowercases s and replaces every run of characters other than
+// letters and digits with a single dash ("Fix Login!" -> "fix-login").
+func Slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// ValidateBranchName checks a branch name against git's ref naming rules
+// (see git-check-ref-format), so a bad template fails before git is run.
+func ValidateBranchName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("branch name is empty")
+	case strings.HasPrefix(name, "-"):
+		return fmt.Errorf("%q starts with '-'", name)
+	case strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/"):
+		return fmt.Errorf("%q starts or ends with '/'", name)
+	case strings.HasSuffix(name, "."):
+		return fmt.Errorf("%q ends with '.'", name)
+	case strings.Contains(name, ".."), strings.Contains(name, "//"), strings.Contains(name, "@{"):
+		return fmt.Errorf("%q contains '..', '//' or '@{'", name)
+	}
+
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(" ~^:?*[\\", r) {
+			return fmt.Errorf("%q contains invalid character %q", name, r)
+		}
+	}
+
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || strings.HasSuffix(part, ".lock") {
+			return fmt.Errorf("%q has a component starting with '.' or ending with '.lock'", name)
+		}
+	}
+	return nil
+}
+
+// BranchUserFromEmail derives the {user} value from a git email address
+// (its local part, slugified): "Jane.Doe@example.com" -> "jane-doe".
+func BranchUserFromEmail(email string) string {
+	local, _, _ := strings.Cut(strings.TrimSpace(email), "@")
+	return Slugify(local)
+}
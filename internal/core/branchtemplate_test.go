@@ -0,0 +1,61 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandBranchTemplate(t *testing.T) {
+	vars := core.BranchTemplateVars{User: "jane", Ticket: "ABC-123", Title: "Fix login!"}
+
+	tests := []struct {
+		name     string
+		template string
+		vars     core.BranchTemplateVars
+		want     string
+		wantErr  string
+	}{
+		{name: "all placeholders", template: "feat/{user}/{ticket}-{slug}", vars: vars, want: "feat/jane/ABC-123-fix-login"},
+		{name: "literal text only", template: "spike", vars: vars, want: "spike"},
+		{name: "no template", template: "", vars: vars, wantErr: "--ticket requires a branch_template"},
+		{name: "unknown placeholder", template: "feat/{team}/{ticket}", vars: vars, wantErr: "unknown placeholder {team}"},
+		{name: "unclosed brace", template: "feat/{ticket", vars: vars, wantErr: "unclosed '{'"},
+		{name: "missing description", template: "{ticket}-{slug}", vars: core.BranchTemplateVars{Ticket: "ABC-1"}, wantErr: "uses {slug} but no description was given"},
+		{name: "missing user", template: "{user}/{ticket}", vars: core.BranchTemplateVars{Ticket: "ABC-1"}, wantErr: "uses {user} but git user.email is not set"},
+		{name: "invalid result", template: "feat/{ticket}", vars: core.BranchTemplateVars{Ticket: "ABC 1"}, wantErr: "expands to an invalid branch name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := core.ExpandBranchTemplate(tt.template, tt.vars)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "fix-login", core.Slugify("  Fix   login! "))
+	assert.Equal(t, "cafe-2", core.Slugify("Cafe #2"))
+	assert.Equal(t, "", core.Slugify("!!!"))
+}
+
+func TestValidateBranchName(t *testing.T) {
+	assert.NoError(t, core.ValidateBranchName("feat/jane/ABC-123-fix-login"))
+
+	for _, name := range []string{"", "-x", "/x", "x/", "x.", "a..b", "a//b", "a@{b", "a b", "a:b", "a/.b", "a.lock"} {
+		assert.Error(t, core.ValidateBranchName(name), name)
+	}
+}
+
+func TestBranchUserFromEmail(t *testing.T) {
+	assert.Equal(t, "jane-doe", core.BranchUserFromEmail("Jane.Doe@example.com\n"))
+	assert.Equal(t, "", core.BranchUserFromEmail(""))
+}
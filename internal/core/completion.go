@@ -0,0 +1,89 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/style"
+)
+
+// Completion descriptions shown next to candidates in zsh, fish and bash.
+const (
+	completionLocalBranch  = "local"
+	completionRemoteBranch = "remote only"
+	completionClean        = "clean"
+)
+
+// PlainStatusIcons builds status icons without ANSI color codes, with
+// commit counts on the ahead/behind arrows when counts is set.
+// Used where escapes are not rendered (fuzzy finder, shell completion).
+func PlainStatusIcons(status git.WorktreeStatus, counts bool) string {
+	return strings.Join(plainStatusIcons(status, counts), " ")
+}
+
+func plainStatusIcons(status git.WorktreeStatus, counts bool) []string {
+	glyphs := style.CurrentIcons()
+	var icons []string
+	switch {
+	case status.UntrackedOnly:
+		icons = append(icons, glyphs.Untracked)
+	case status.Dirty:
+		icons = append(icons, glyphs.Dirty)
+	}
+	if status.Ahead > 0 {
+		icons = append(icons, arrow(glyphs.Ahead, status.Ahead, counts))
+	}
+	if status.Behind > 0 {
+		icons = append(icons, arrow(glyphs.Behind, status.Behind, counts))
+	}
+	if status.Unmerged {
+		icons = append(icons, glyphs.Unmerged)
+	}
+	if status.HasStash {
+		icons = append(icons, glyphs.Stash)
+	}
+	return icons
+}
+
+// BranchCompletions returns shell completion candidates for branches that
+// start with toComplete, in cobra's "value\tdescription" form. The description
+// tells local branches apart from ones that only exist on the remote.
+func BranchCompletions(branches []git.Branch, toComplete string) []string {
+	var completions []string
+	for _, branch := range branches {
+		if !strings.HasPrefix(branch.DisplayName, toComplete) {
+			continue
+		}
+		desc := completionRemoteBranch
+		if branch.IsLocal {
+			desc = completionLocalBranch
+		}
+		completions = append(completions, branch.DisplayName+"\t"+desc)
+	}
+	return completions
+}
+
+// WorktreeCompletions returns shell completion candidates for worktree
+// branches that start with toComplete, described by the picker's status
+// icons without counts. statuses is indexed like worktrees; detached
+// worktrees complete by their label (see DetachedLabel).
+func WorktreeCompletions(worktrees []git.Worktree, statuses []git.WorktreeStatus, toComplete string) []string {
+	var completions []string
+	for i, wt := range worktrees {
+		name := wt.Branch
+		if name == "" {
+			name = DetachedLabel(wt.Path)
+		}
+		if !strings.HasPrefix(name, toComplete) {
+			continue
+		}
+		desc := completionClean
+		if i < len(statuses) {
+			if icons := PlainStatusIcons(statuses[i], false); icons != "" {
+				desc = icons
+			}
+		}
+		completions = append(completions, name+"\t"+desc)
+	}
+	return completions
+}
@@ -0,0 +1,51 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlainStatusIcons(t *testing.T) {
+	assert.Equal(t, "", core.PlainStatusIcons(git.WorktreeStatus{}, true))
+	assert.Equal(t, "✗ ↑ ↓ ↕", core.PlainStatusIcons(git.WorktreeStatus{Dirty: true, Ahead: 1, Behind: 2, Unmerged: true}, false))
+	assert.Equal(t, "✗ ↑1 ↓2 ↕", core.PlainStatusIcons(git.WorktreeStatus{Dirty: true, Ahead: 1, Behind: 2, Unmerged: true}, true))
+	assert.Equal(t, "? ≡", core.PlainStatusIcons(git.WorktreeStatus{Dirty: true, UntrackedOnly: true, HasStash: true}, false))
+}
+
+func TestBranchCompletions(t *testing.T) {
+	branches := []git.Branch{
+		{RefName: "feat/local", DisplayName: "feat/local", Name: "feat/local", IsLocal: true},
+		{RefName: "origin/feat/remote", DisplayName: "feat/remote", Name: "feat/remote"},
+		{RefName: "fix", DisplayName: "fix", Name: "fix", IsLocal: true},
+	}
+
+	assert.Equal(t, []string{
+		"feat/local\tlocal",
+		"feat/remote\tremote only",
+	}, core.BranchCompletions(branches, "feat"))
+	assert.Len(t, core.BranchCompletions(branches, ""), 3)
+	assert.Empty(t, core.BranchCompletions(branches, "nope"))
+}
+
+func TestWorktreeCompletions(t *testing.T) {
+	worktrees := []git.Worktree{
+		{Path: "/wt/clean", Branch: "clean"},
+		{Path: "/wt/dirty", Branch: "dirty"},
+		{Path: "/wt/3f2a9c1/repo"},
+	}
+	statuses := []git.WorktreeStatus{
+		{},
+		{Dirty: true, Behind: 1},
+		{Dirty: true},
+	}
+
+	assert.Equal(t, []string{
+		"clean\tclean",
+		"dirty\t✗ ↓",
+		"3f2a9c1\t✗",
+	}, core.WorktreeCompletions(worktrees, statuses, ""), "detached worktrees complete by label")
+	assert.Equal(t, []string{"dirty\t✗ ↓"}, core.WorktreeCompletions(worktrees, statuses, "d"))
+}
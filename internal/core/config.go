@@ -0,0 +1,117 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/m44rten1/sprout/internal/config"
+)
+
+// ConfigFileContext contains all inputs needed to plan the commands that
+// check or rewrite a .sprout.yml.
+type ConfigFileContext struct {
+	Path string // The .sprout.yml
+	Data []byte // Its contents
+	// Dir is what it extends relative paths from, as config.Load does: the
+	// main worktree; Path's directory if empty
+	Dir string
+	// Bases holds the contents of the files it extends, directly or not,
+	// by path; the ones that couldn't be read are missing
+	Bases map[string][]byte
+}
+
+// extendsDir returns the directory relative extends are resolved against.
+func (ctx ConfigFileContext) extendsDir() string {
+	if ctx.Dir != "" {
+		return ctx.Dir
+	}
+	return filepath.Dir(ctx.Path)
+}
+
+// readBase returns the contents of an extended file for config.Resolve.
+func (ctx ConfigFileContext) readBase(path string) ([]byte, error) {
+	if data, ok := ctx.Bases[path]; ok {
+		return data, nil
+	}
+	return nil, fs.ErrNotExist
+}
+
+// PlanConfigValidateCommand creates a plan that reports every problem in a
+// .sprout.yml, each with its line, and exits non-zero if there are any.
+func PlanConfigValidateCommand(ctx ConfigFileContext) Plan {
+	if _, err := config.ResolveIn(ctx.Path, ctx.extendsDir(), ctx.Data, ctx.readBase); err != nil {
+		problems := config.Problems(err)
+		sort.SliceStable(problems, func(i, j int) bool { return problemLine(problems[i]) < problemLine(problems[j]) })
+		actions := make([]Action, 0, len(problems)+2)
+		for _, problem := range problems {
+			actions = append(actions, PrintError{Msg: problem.Error()})
+		}
+		noun := "problems"
+		if len(problems) == 1 {
+			noun = "problem"
+		}
+		return Plan{Actions: append(actions,
+			PrintError{Msg: fmt.Sprintf("❌ %d %s found", len(problems), noun)},
+			Exit{Code: 1},
+		)}
+	}
+
+	msg := fmt.Sprintf("✅ %s is valid", ctx.Path)
+	switch len(ctx.Bases) {
+	case 0:
+	case 1:
+		msg += ", and so is the file it extends"
+	default:
+		msg += fmt.Sprintf(", and so are the %d files it extends", len(ctx.Bases))
+	}
+	if _, from, err := config.Upgrade(ctx.Path, ctx.Data); err == nil && from < config.CurrentVersion {
+		msg += fmt.Sprintf("\nℹ️  It uses config format version %d; 'sprout upgrade-config' rewrites it in version %d.", from, config.CurrentVersion)
+	}
+	return Plan{Actions: []Action{PrintMessage{Msg: msg}}}
+}
+
+// problemLine is the line a config problem is on, or 0 if unknown.
+func problemLine(err error) int {
+	var cfgErr *config.Error
+	if errors.As(err, &cfgErr) {
+		return cfgErr.Line
+	}
+	return 0
+}
+
+// PlanUpgradeConfigCommand creates a plan that rewrites a .sprout.yml in
+// the newest format, keeping its comments. A file that doesn't load is left
+// alone, with the problems reported.
+func PlanUpgradeConfigCommand(ctx ConfigFileContext) Plan {
+	if _, err := config.Parse(ctx.Path, ctx.Data); err != nil {
+		return errorPlan(err)
+	}
+	upgraded, from, err := config.Upgrade(ctx.Path, ctx.Data)
+	if err != nil {
+		return errorPlan(err)
+	}
+	if from == config.CurrentVersion {
+		return Plan{Actions: []Action{
+			PrintMessage{Msg: fmt.Sprintf("✅ %s is already in the newest format (version %d)", ctx.Path, config.CurrentVersion)},
+		}}
+	}
+
+	return Plan{Actions: []Action{
+		WriteFile{Path: ctx.Path, Content: upgraded, Perm: 0644},
+		PrintMessage{Msg: fmt.Sprintf("✅ Upgraded %s from version %d to %d", ctx.Path, from, config.CurrentVersion)},
+	}}
+}
+
+// PlanConfigSchemaCommand creates a plan that prints the JSON Schema of
+// .sprout.yml, for editors to complete and check the file with.
+func PlanConfigSchemaCommand() Plan {
+	data, err := json.MarshalIndent(config.Schema(), "", "  ")
+	if err != nil {
+		return errorPlan(fmt.Errorf("failed to encode config schema: %w", err))
+	}
+	return Plan{Actions: []Action{PrintMessage{Msg: string(data)}}}
+}
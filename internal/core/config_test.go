@@ -0,0 +1,206 @@
+package core_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanUpgradeConfigCommand(t *testing.T) {
+	t.Run("adds the version and keeps comments", func(t *testing.T) {
+		plan := core.PlanUpgradeConfigCommand(core.ConfigFileContext{
+			Path: "/repo/.sprout.yml",
+			Data: []byte("share_mode: clone\nhooks:\n  # install\n  on_create:\n    - npm ci # fast\n"),
+		})
+
+		require.Len(t, plan.Actions, 2)
+		write, ok := plan.Actions[0].(core.WriteFile)
+		require.True(t, ok)
+		assert.Equal(t, "/repo/.sprout.yml", write.Path)
+		assert.Equal(t, "version: 1\nshare_mode: clone\nhooks:\n  # install\n  on_create:\n    - npm ci # fast\n", string(write.Content))
+		assert.Contains(t, plan.Actions[1].(core.PrintMessage).Msg, "from version 0 to 1")
+
+		cfg, err := config.Parse(write.Path, write.Content)
+		require.NoError(t, err)
+		assert.Equal(t, config.CurrentVersion, cfg.Version)
+	})
+
+	t.Run("current file is left alone", func(t *testing.T) {
+		plan := core.PlanUpgradeConfigCommand(core.ConfigFileContext{Path: "/repo/.sprout.yml", Data: []byte("version: 1\n")})
+
+		require.Len(t, plan.Actions, 1)
+		assert.Contains(t, plan.Actions[0].(core.PrintMessage).Msg, "already in the newest format")
+	})
+
+	t.Run("invalid file is not rewritten", func(t *testing.T) {
+		plan := core.PlanUpgradeConfigCommand(core.ConfigFileContext{
+			Path: "/repo/.sprout.yml",
+			Data: []byte("hooks:\n  on_create:\n    - npm ci\n    - \"\"\n"),
+		})
+
+		require.Len(t, plan.Actions, 2)
+		assert.Equal(t, core.PrintError{Msg: "/repo/.sprout.yml:4: hooks.on_create[1] is empty"}, plan.Actions[0])
+		assert.Equal(t, core.Exit{Code: 1}, plan.Actions[1])
+	})
+
+	t.Run("newer version", func(t *testing.T) {
+		plan := core.PlanUpgradeConfigCommand(core.ConfigFileContext{Path: "/repo/.sprout.yml", Data: []byte("# hi\nversion: 99\n")})
+
+		require.Len(t, plan.Actions, 2)
+		assert.Contains(t, plan.Actions[0].(core.PrintError).Msg, "/repo/.sprout.yml:2: config was written for a newer version of sprout")
+	})
+}
+
+func TestConfigParse_ErrorLines(t *testing.T) {
+	_, err := config.Parse("/repo/.sprout.yml", []byte("share_mode: clone\nhooks:\n  on_open: make dev\nmax_worktrees: -1\n"))
+	assert.EqualError(t, err, "/repo/.sprout.yml:3: cannot unmarshal !!str `make dev` into []string")
+
+	_, err = config.Parse("/repo/.sprout.yml", []byte("share_mode: clone\nmax_worktrees: -1\n"))
+	assert.EqualError(t, err, "/repo/.sprout.yml:2: max_worktrees must not be negative, got -1")
+	var fieldErr *config.FieldError
+	assert.ErrorAs(t, err, &fieldErr)
+
+	_, err = config.Parse("/repo/.sprout.yml", []byte("hooks:\n  on_create:\n  - a\n - b\n"))
+	assert.EqualError(t, err, "/repo/.sprout.yml:3: did not find expected key")
+
+	for _, dir := range []string{"/etc", "~/.ssh", "../other"} {
+		_, err = config.Parse("/repo/.sprout.yml", []byte("template_dir: "+dir+"\n"))
+		assert.EqualError(t, err, fmt.Sprintf("/repo/.sprout.yml:1: template_dir must be a path inside the repository, got %q", dir))
+	}
+	_, err = config.Parse("/repo/.sprout.yml", []byte("template_dir: .sprout/template\n"))
+	assert.NoError(t, err)
+}
+
+func TestConfigParse_EmptyDocument(t *testing.T) {
+	for _, data := range []string{"", "---\n", "---\n# nothing yet\n", "---\n...\n"} {
+		cfg, err := config.Parse("/repo/.sprout.yml", []byte(data))
+		require.NoError(t, err, "%q", data)
+		assert.Equal(t, &config.Config{Version: config.CurrentVersion}, cfg, "%q", data)
+	}
+}
+
+func TestPlanConfigValidateCommand(t *testing.T) {
+	t.Run("reports every problem by line", func(t *testing.T) {
+		plan := core.PlanConfigValidateCommand(core.ConfigFileContext{
+			Path: "/repo/.sprout.yml",
+			Data: []byte("hooks:\n  on_creat:\n    - npm ci\n  on_open:\n    - \"\"\nshare_mod: clone\ntemplate_conflict: merge\n"),
+		})
+
+		assert.Equal(t, []core.Action{
+			core.PrintError{Msg: `/repo/.sprout.yml:2: unknown key "on_creat" in hooks (did you mean "on_create"?)`},
+			core.PrintError{Msg: "/repo/.sprout.yml:5: hooks.on_open[0] is empty"},
+			core.PrintError{Msg: `/repo/.sprout.yml:6: unknown key "share_mod" (did you mean "share_mode"?)`},
+			core.PrintError{Msg: `/repo/.sprout.yml:7: template_conflict "merge" is not supported (use skip, overwrite or backup)`},
+			core.PrintError{Msg: "❌ 4 problems found"},
+			core.Exit{Code: 1},
+		}, plan.Actions)
+	})
+
+	t.Run("valid file", func(t *testing.T) {
+		plan := core.PlanConfigValidateCommand(core.ConfigFileContext{Path: "/repo/.sprout.yml", Data: []byte("version: 1\nshare_mode: clone\n")})
+
+		require.Len(t, plan.Actions, 1)
+		assert.Equal(t, core.PrintMessage{Msg: "✅ /repo/.sprout.yml is valid"}, plan.Actions[0])
+	})
+
+	t.Run("valid file in an older format", func(t *testing.T) {
+		plan := core.PlanConfigValidateCommand(core.ConfigFileContext{Path: "/repo/.sprout.yml", Data: []byte("share_mode: clone\n")})
+
+		require.Len(t, plan.Actions, 1)
+		assert.Contains(t, plan.Actions[0].(core.PrintMessage).Msg, "sprout upgrade-config")
+	})
+
+	t.Run("file that extends another", func(t *testing.T) {
+		plan := core.PlanConfigValidateCommand(core.ConfigFileContext{
+			Path:  "/repo/.sprout.yml",
+			Data:  []byte("version: 1\nextends: ../shared/base.yml\n"),
+			Bases: map[string][]byte{"/shared/base.yml": []byte("version: 1\nhooks:\n  on_create:\n    - npm ci\n")},
+		})
+
+		assert.Equal(t, []core.Action{core.PrintMessage{Msg: "✅ /repo/.sprout.yml is valid, and so is the file it extends"}}, plan.Actions)
+	})
+
+	t.Run("problem in the file extended", func(t *testing.T) {
+		plan := core.PlanConfigValidateCommand(core.ConfigFileContext{
+			Path:  "/repo/.sprout.yml",
+			Data:  []byte("version: 1\nextends: ../shared/base.yml\n"),
+			Bases: map[string][]byte{"/shared/base.yml": []byte("version: 1\nshare_mode: copy\n")},
+		})
+
+		require.Len(t, plan.Actions, 3)
+		assert.Contains(t, plan.Actions[0].(core.PrintError).Msg, "/shared/base.yml:2: share_mode")
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		plan := core.PlanConfigValidateCommand(core.ConfigFileContext{
+			Path:  "/repo/.sprout.yml",
+			Data:  []byte("version: 1\nextends: ../shared/base.yml\n"),
+			Bases: map[string][]byte{"/shared/base.yml": []byte("version: 1\nextends:\n  - ../repo/.sprout.yml\n")},
+		})
+
+		require.Len(t, plan.Actions, 3)
+		assert.Equal(t, core.PrintError{Msg: "/shared/base.yml:3: extends[0] makes a cycle: /repo/.sprout.yml → /shared/base.yml → /repo/.sprout.yml"}, plan.Actions[0])
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		plan := core.PlanConfigValidateCommand(core.ConfigFileContext{
+			Path: "/repo/.sprout.yml",
+			Data: []byte("version: 1\nextends: ../shared/base.yml\n"),
+		})
+
+		require.Len(t, plan.Actions, 3)
+		assert.Contains(t, plan.Actions[0].(core.PrintError).Msg, "/repo/.sprout.yml:2: extends[0] can't be read")
+	})
+
+	t.Run("merge without extends", func(t *testing.T) {
+		plan := core.PlanConfigValidateCommand(core.ConfigFileContext{
+			Path: "/repo/.sprout.yml",
+			Data: []byte("version: 1\nhooks:\n  merge: replace\n"),
+		})
+
+		require.Len(t, plan.Actions, 3)
+		assert.Contains(t, plan.Actions[0].(core.PrintError).Msg, "/repo/.sprout.yml:3: hooks.merge only applies to a config that extends another")
+	})
+}
+
+func TestPlanConfigSchemaCommand(t *testing.T) {
+	plan := core.PlanConfigSchemaCommand()
+	require.Len(t, plan.Actions, 1)
+
+	var schema struct {
+		Type                 string                     `json:"type"`
+		AdditionalProperties bool                       `json:"additionalProperties"`
+		Properties           map[string]json.RawMessage `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(plan.Actions[0].(core.PrintMessage).Msg), &schema))
+	assert.Equal(t, "object", schema.Type)
+	assert.False(t, schema.AdditionalProperties)
+
+	// Every setting is described, nested ones included
+	for _, field := range config.SchemaFields() {
+		node := schema.Properties
+		parts := strings.Split(field, ".")
+		for i, part := range parts {
+			require.Contains(t, node, part, field)
+			var prop struct {
+				Description string                     `json:"description"`
+				Enum        []string                   `json:"enum"`
+				Properties  map[string]json.RawMessage `json:"properties"`
+			}
+			require.NoError(t, json.Unmarshal(node[part], &prop))
+			if i == len(parts)-1 {
+				assert.NotEmpty(t, prop.Description, "%s has no description", field)
+				if field == "share_mode" {
+					assert.Equal(t, []string{"symlink", "clone"}, prop.Enum)
+				}
+			}
+			node = prop.Properties
+		}
+	}
+}
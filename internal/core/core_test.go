@@ -1,6 +1,7 @@
 package core
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/m44rten1/sprout/internal/git"
@@ -26,3 +27,25 @@ func TestBasicSetup(t *testing.T) {
 	assert.True(t, true, "basic true check")
 	assert.False(t, false, "basic false check")
 }
+
+func TestWithoutMessages(t *testing.T) {
+	plan := Plan{Actions: []Action{
+		PrintMessage{Msg: "Creating worktree..."},
+		RunGitCommand{Dir: "/repo", Args: []string{"fetch"}},
+		Parallel{Actions: []Action{
+			PrintMessage{Msg: "Copying"},
+			CopyFile{Src: "/a", Dst: "/b"},
+		}},
+		PrintError{Msg: "warning"},
+		PrintMessage{Msg: "Worktree created!"},
+	}}
+
+	assert.Equal(t, Plan{Actions: []Action{
+		RunGitCommand{Dir: "/repo", Args: []string{"fetch"}},
+		Parallel{Actions: []Action{CopyFile{Src: "/a", Dst: "/b"}}},
+		PrintError{Msg: "warning"},
+	}}, WithoutMessages(plan))
+
+	failing := errorPlan(errors.New("boom"))
+	assert.Equal(t, failing, WithoutMessages(failing), "a plan that only fails keeps its Err")
+}
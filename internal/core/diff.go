@@ -0,0 +1,52 @@
+package core
+
+// DiffSide is one worktree being compared.
+type DiffSide struct {
+	Path string // Worktree path
+	Rev  string // Revision to compare: the branch, or a snapshot of the working tree
+}
+
+// DiffContext contains all inputs needed to plan the diff command.
+type DiffContext struct {
+	RepoRoot string
+	From     DiffSide
+	To       DiffSide
+	Tool     bool     // Open 'git difftool --dir-diff' instead of printing the diff
+	Stat     bool     // Only print a diffstat
+	Paths    []string // Limit the diff to these paths
+}
+
+// PlanDiffCommand creates a plan that shows the differences between two
+// worktrees. Both revisions live in the same repository, so git can compare
+// them directly from any worktree.
+func PlanDiffCommand(ctx DiffContext) Plan {
+	if ctx.RepoRoot == "" {
+		return errorPlan(ErrEmptyRepoRoot)
+	}
+	if ctx.From.Rev == "" || ctx.To.Rev == "" {
+		return errorPlan(ErrEmptyBranch)
+	}
+
+	return Plan{Actions: []Action{
+		RunGitInteractive{Dir: ctx.RepoRoot, Args: DiffArgs(ctx)},
+	}}
+}
+
+// DiffArgs builds the git arguments comparing ctx.From to ctx.To.
+func DiffArgs(ctx DiffContext) []string {
+	var args []string
+	if ctx.Tool {
+		args = []string{"difftool", "--dir-diff"}
+	} else {
+		args = []string{"diff"}
+		if ctx.Stat {
+			args = append(args, "--stat")
+		}
+	}
+	args = append(args, ctx.From.Rev, ctx.To.Rev)
+	if len(ctx.Paths) > 0 {
+		args = append(args, "--")
+		args = append(args, ctx.Paths...)
+	}
+	return args
+}
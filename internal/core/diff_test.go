@@ -0,0 +1,70 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanDiffCommand(t *testing.T) {
+	base := core.DiffContext{
+		RepoRoot: "/repo",
+		From:     core.DiffSide{Path: "/sprout/repo-1234/approach-a/repo", Rev: "approach-a"},
+		To:       core.DiffSide{Path: "/sprout/repo-1234/approach-b/repo", Rev: "approach-b"},
+	}
+
+	tests := []struct {
+		name string
+		edit func(*core.DiffContext)
+		want []string
+	}{
+		{
+			name: "heads",
+			want: []string{"diff", "approach-a", "approach-b"},
+		},
+		{
+			name: "stat limited to paths",
+			edit: func(c *core.DiffContext) {
+				c.Stat = true
+				c.Paths = []string{"internal/api", "go.mod"}
+			},
+			want: []string{"diff", "--stat", "approach-a", "approach-b", "--", "internal/api", "go.mod"},
+		},
+		{
+			name: "difftool",
+			edit: func(c *core.DiffContext) { c.Tool = true },
+			want: []string{"difftool", "--dir-diff", "approach-a", "approach-b"},
+		},
+		{
+			name: "working tree snapshot",
+			edit: func(c *core.DiffContext) { c.To.Rev = "3f1c2a9" },
+			want: []string{"diff", "approach-a", "3f1c2a9"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := base
+			if tt.edit != nil {
+				tt.edit(&ctx)
+			}
+
+			plan := core.PlanDiffCommand(ctx)
+			require.Len(t, plan.Actions, 1)
+			assert.Equal(t, core.RunGitInteractive{Dir: "/repo", Args: tt.want}, plan.Actions[0])
+		})
+	}
+}
+
+func TestPlanDiffCommand_MissingRevision(t *testing.T) {
+	plan := core.PlanDiffCommand(core.DiffContext{
+		RepoRoot: "/repo",
+		From:     core.DiffSide{Path: "/repo", Rev: "main"},
+	})
+
+	require.Len(t, plan.Actions, 2)
+	assert.IsType(t, core.PrintError{}, plan.Actions[0])
+	assert.Equal(t, core.Exit{Code: 1}, plan.Actions[1])
+}
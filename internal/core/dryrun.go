@@ -2,7 +2,13 @@ package core
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/style"
 )
 
 // FormatPlan converts a Plan into a human-readable description of what will happen.
@@ -21,11 +27,65 @@ func FormatPlan(plan Plan) string {
 		prefix := fmt.Sprintf("  %d. ", i+1)
 		formatted := formatAction(action)
 		lines = append(lines, prefix+formatted)
+		if hooks, ok := action.(RunHooks); ok {
+			lines = append(lines, formatHookCommands(hooks)...)
+		}
 	}
 
 	return strings.Join(lines, "\n")
 }
 
+// hookVarPattern matches $SPROUT_NAME and ${SPROUT_NAME}, taking in every
+// character the shell would as part of the name.
+var hookVarPattern = regexp.MustCompile(`\$\{(SPROUT_\w+)\}|\$(SPROUT_\w+)`)
+
+// formatHookCommands lists the commands of a, marked as not run, with the
+// SPROUT_* variables that the plan knows replaced by their values. A last
+// line names the ones that are only set when the hooks run (see
+// hooks.RunHooks), such as the port base.
+func formatHookCommands(a RunHooks) []string {
+	known := map[string]string{
+		"SPROUT_REPO_ROOT":          a.RepoRoot,
+		"SPROUT_WORKTREE_PATH":      a.Path,
+		"SPROUT_MAIN_WORKTREE_PATH": a.MainWorktreePath,
+		"SPROUT_HOOK_TYPE":          string(a.Type),
+	}
+	if a.Branch != "" {
+		known["SPROUT_BRANCH"] = a.Branch
+		known["SPROUT_WORKTREE_NAME"] = WorktreeName(a.Branch, a.Path)
+	}
+
+	var lines, unknown []string
+	for _, command := range a.Commands {
+		expanded := hookVarPattern.ReplaceAllStringFunc(command, func(ref string) string {
+			match := hookVarPattern.FindStringSubmatch(ref)
+			name := match[1] + match[2]
+			if value, ok := known[name]; ok {
+				return value
+			}
+			if !slices.Contains(unknown, "$"+name) {
+				unknown = append(unknown, "$"+name)
+			}
+			return ref
+		})
+		lines = append(lines, "       would run: "+expanded)
+	}
+	if len(unknown) > 0 {
+		verb := "is"
+		if len(unknown) > 1 {
+			verb = "are"
+		}
+		lines = append(lines, fmt.Sprintf("       (%s %s only known when the hooks run)", strings.Join(unknown, ", "), verb))
+	}
+	return lines
+}
+
+// DescribeAction returns the one-line description of an action used by
+// --dry-run, e.g. for logging actions as they execute.
+func DescribeAction(action Action) string {
+	return formatAction(action)
+}
+
 // formatAction converts a single action into a human-readable description.
 func formatAction(action Action) string {
 	switch a := action.(type) {
@@ -41,6 +101,34 @@ func formatAction(action Action) string {
 	case CreateDirectory:
 		return fmt.Sprintf("Create directory: %s", a.Path)
 
+	case MoveDirectory:
+		return fmt.Sprintf("Move directory: %s → %s", a.From, a.To)
+
+	case RemoveDirectory:
+		return fmt.Sprintf("Remove directory: %s", a.Path)
+
+	case RemoveFile:
+		return fmt.Sprintf("Remove file: %s", a.Path)
+
+	case CopyFile:
+		policy := a.OnConflict
+		if policy == "" {
+			policy = config.ConflictSkip
+		}
+		return fmt.Sprintf("Copy file: %s → %s (%s if it exists)", a.Src, a.Dst, policy)
+
+	case WriteFile:
+		return fmt.Sprintf("Write file: %s (%d bytes)", a.Path, len(a.Content))
+
+	case ShareDirectory:
+		if a.Mode == config.ShareClone {
+			return fmt.Sprintf("Clone directory (copy-on-write): %s → %s", a.Src, a.Dst)
+		}
+		return fmt.Sprintf("Symlink directory: %s → %s", a.Dst, a.Src)
+
+	case CloneDirectory:
+		return fmt.Sprintf("Clone directory (copy-on-write): %s → %s", a.Src, a.Dst)
+
 	case RunGitCommand:
 		// Handle empty args edge case
 		if len(a.Args) == 0 {
@@ -55,9 +143,18 @@ func formatAction(action Action) string {
 		}
 		return fmt.Sprintf("Run git command: git %s", args)
 
+	case RunGitInteractive:
+		return fmt.Sprintf("Run git command in %s: git %s", a.Dir, strings.Join(a.Args, " "))
+
 	case OpenEditor:
 		return fmt.Sprintf("Open editor: %s", a.Path)
 
+	case OpenFile:
+		return fmt.Sprintf("Open editor: %s", FormatFileLocation(a.File, a.Line, a.Column))
+
+	case OpenURL:
+		return fmt.Sprintf("Open in browser: %s", a.URL)
+
 	case RunHooks:
 		hookCount := len(a.Commands)
 		return fmt.Sprintf("Run %d %s hook(s) in %s", hookCount, a.Type, a.Path)
@@ -71,9 +168,69 @@ func formatAction(action Action) string {
 	case PromptTrust:
 		return fmt.Sprintf("Prompt to trust repository: %s (%d %s hooks)", a.MainWorktreePath, len(a.HookCommands), a.HookType)
 
+	case AdoptWorktree:
+		return fmt.Sprintf("Adopt worktree: %s", a.Path)
+
+	case ForgetWorktree:
+		return fmt.Sprintf("Forget adopted worktree: %s", a.Path)
+
+	case RecordTicket:
+		return fmt.Sprintf("Record ticket %s for %s", a.Ticket.ID, a.WorktreePath)
+
+	case RecordBase:
+		return fmt.Sprintf("Record base %s for %s", a.Base, a.WorktreePath)
+
+	case SetPinned:
+		if a.Pinned {
+			return fmt.Sprintf("Pin worktree: %s", a.WorktreePath)
+		}
+		return fmt.Sprintf("Unpin worktree: %s", a.WorktreePath)
+
+	case SetNote:
+		if a.Note == "" {
+			return fmt.Sprintf("Remove note of %s", a.WorktreePath)
+		}
+		return fmt.Sprintf("Set note of %s: %s", a.WorktreePath, a.Note)
+
+	case CreateScratch:
+		return fmt.Sprintf("Create scratch directory of %s", a.WorktreePath)
+
+	case RemoveScratch:
+		return fmt.Sprintf("Remove scratch directory of %s", a.WorktreePath)
+
+	case SetExpiry:
+		if a.ExpiresAt.IsZero() {
+			return fmt.Sprintf("Clear expiry of %s", a.WorktreePath)
+		}
+		return fmt.Sprintf("Mark %s temporary until %s", a.WorktreePath, a.ExpiresAt.Format(time.DateTime))
+
+	case ForgetMetadata:
+		return fmt.Sprintf("Forget metadata of %s", a.WorktreePath)
+
+	case SaveWorkspace:
+		return fmt.Sprintf("Record workspace %s: %s", a.Name, strings.Join(a.Repos, ", "))
+
+	case DeleteWorkspace:
+		return fmt.Sprintf("Forget workspace %s", a.Name)
+
+	case SetTelemetry:
+		if a.Enabled {
+			return "Turn telemetry on"
+		}
+		return "Turn telemetry off"
+
 	case SelectInteractive:
 		return "Interactive selection (should not appear in execution plans)"
 
+	case Parallel:
+		// Members are listed in plan order, indented under the group
+		lines := []string{fmt.Sprintf("Run in parallel (%d actions):", len(a.Actions))}
+		for _, member := range a.Actions {
+			formatted := strings.ReplaceAll(formatAction(member), "\n", "\n     ")
+			lines = append(lines, "     - "+formatted)
+		}
+		return strings.Join(lines, "\n")
+
 	case Exit:
 		return fmt.Sprintf("Exit with code %d", a.Code)
 
@@ -82,22 +239,13 @@ func formatAction(action Action) string {
 	}
 }
 
-// truncate shortens a string to maxLen characters (byte-based), adding "..." if truncated.
+// truncate shortens a string to maxLen terminal columns, adding "..." if
+// truncated, without splitting characters (see style.Truncate).
 // Multiline strings are truncated to the first line only.
-// Note: operates on bytes, not runes, so may split multibyte UTF-8 characters.
 func truncate(s string, maxLen int) string {
 	// Show only first line
 	if idx := strings.IndexAny(s, "\n\r"); idx != -1 {
 		s = s[:idx]
 	}
-
-	if len(s) <= maxLen {
-		return s
-	}
-
-	if maxLen <= 3 {
-		return "..."
-	}
-
-	return s[:maxLen-3] + "..."
+	return style.Truncate(s, maxLen)
 }
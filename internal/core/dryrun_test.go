@@ -3,6 +3,7 @@ package core_test
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/m44rten1/sprout/internal/core"
 	"github.com/stretchr/testify/assert"
@@ -53,6 +54,8 @@ func TestFormatPlan_AllActionTypes(t *testing.T) {
 			core.PrintMessage{Msg: "message"},
 			core.PrintError{Msg: "error"},
 			core.CreateDirectory{Path: "/dir", Perm: 0755},
+			core.RemoveDirectory{Path: "/old"},
+			core.RemoveFile{Path: "/old.json"},
 			core.RunGitCommand{Dir: "/repo", Args: []string{"status"}},
 			core.OpenEditor{Path: "/path"},
 			core.RunHooks{
@@ -71,6 +74,8 @@ func TestFormatPlan_AllActionTypes(t *testing.T) {
 	assert.Contains(t, output, "Print: \"message\"")
 	assert.Contains(t, output, "Print error: \"error\"")
 	assert.Contains(t, output, "Create directory: /dir")
+	assert.Contains(t, output, "Remove directory: /old")
+	assert.Contains(t, output, "Remove file: /old.json")
 	assert.Contains(t, output, "Run git command in /repo: git status")
 	assert.Contains(t, output, "Open editor: /path")
 	assert.Contains(t, output, "Run 2 on_create hook(s) in /worktree")
@@ -132,6 +137,16 @@ func TestFormatPlan_TruncateEdgeCases(t *testing.T) {
 			msg:  strings.Repeat("a", 100),
 			want: strings.Repeat("a", 57) + "...",
 		},
+		{
+			name: "multibyte characters are never split",
+			msg:  strings.Repeat("ü", 100),
+			want: `Print: "` + strings.Repeat("ü", 57) + `..."`,
+		},
+		{
+			name: "wide characters count two columns",
+			msg:  strings.Repeat("機", 40),
+			want: `Print: "` + strings.Repeat("機", 28) + `..."`,
+		},
 		{
 			name: "multiline shows only first line",
 			msg:  "First\nSecond",
@@ -240,6 +255,37 @@ func TestFormatPlan_HookFormatting(t *testing.T) {
 	}
 }
 
+func TestFormatPlan_HookCommands(t *testing.T) {
+	action := core.RunHooks{
+		Type:             core.HookTypeOnCreate,
+		Commands:         []string{"npm ci", "createdb app_${SPROUT_WORKTREE_NAME}", "PORT=$SPROUT_PORT_BASE make dev -C $SPROUT_WORKTREE_PATH $HOME"},
+		Path:             "/sprout/feat/login/repo",
+		RepoRoot:         "/repo",
+		MainWorktreePath: "/repo",
+		Branch:           "feat/login",
+	}
+
+	assert.Equal(t, `Planned actions:
+  1. Run 3 on_create hook(s) in /sprout/feat/login/repo
+       would run: npm ci
+       would run: createdb app_feat-login
+       would run: PORT=$SPROUT_PORT_BASE make dev -C /sprout/feat/login/repo $HOME
+       ($SPROUT_PORT_BASE is only known when the hooks run)`, core.FormatPlan(core.Plan{Actions: []core.Action{action}}))
+
+	t.Run("branch not known", func(t *testing.T) {
+		action := action
+		action.Branch = ""
+
+		output := core.FormatPlan(core.Plan{Actions: []core.Action{action}})
+		assert.Contains(t, output, "would run: createdb app_${SPROUT_WORKTREE_NAME}")
+		assert.Contains(t, output, "($SPROUT_WORKTREE_NAME, $SPROUT_PORT_BASE are only known when the hooks run)")
+	})
+
+	t.Run("one line when logged", func(t *testing.T) {
+		assert.Equal(t, "Run 3 on_create hook(s) in /sprout/feat/login/repo", core.DescribeAction(action))
+	})
+}
+
 func TestFormatPlan_Deterministic(t *testing.T) {
 	plan := core.Plan{
 		Actions: []core.Action{
@@ -254,3 +300,32 @@ func TestFormatPlan_Deterministic(t *testing.T) {
 	// Same input produces same output
 	assert.Equal(t, output1, output2)
 }
+
+func TestFormatPlan_Parallel(t *testing.T) {
+	plan := core.Plan{Actions: []core.Action{
+		core.Parallel{Actions: []core.Action{
+			core.RunGitCommand{Dir: "/repo", Args: []string{"fetch", "origin"}},
+			core.CreateDirectory{Path: "/wt"},
+		}},
+		core.PrintMessage{Msg: "done"},
+	}}
+
+	expected := `Planned actions:
+  1. Run in parallel (2 actions):
+     - Run git command in /repo: git fetch origin
+     - Create directory: /wt
+  2. Print: "done"`
+	assert.Equal(t, expected, core.FormatPlan(plan))
+}
+
+func TestFormatPlan_SetExpiry(t *testing.T) {
+	plan := core.Plan{Actions: []core.Action{
+		core.SetExpiry{WorktreePath: "/wt/spike", ExpiresAt: time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC)},
+		core.SetExpiry{WorktreePath: "/wt/spike"},
+	}}
+
+	expected := `Planned actions:
+  1. Mark /wt/spike temporary until 2026-03-01 14:00:00
+  2. Clear expiry of /wt/spike`
+	assert.Equal(t, expected, core.FormatPlan(plan))
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// WorktreeName returns an identifier for a worktree that is safe to embed in
+// database names, container names, etc.: the branch with every character
+// outside [A-Za-z0-9_-] replaced by "-" (e.g. "feat/login" → "feat-login").
+// Detached worktrees fall back to the worktree directory name.
+func WorktreeName(branch, worktreePath string) string {
+	name := branch
+	if name == "" {
+		name = filepath.Base(worktreePath)
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, name)
+}
+
+// EnvVar is a variable 'sprout env' prints.
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// EnvContext contains all inputs needed to plan the env command.
+type EnvContext struct {
+	Vars  []EnvVar
+	Names []string // Print only the values of these variables, one per line
+	Fish  bool     // Print fish commands instead of POSIX shell ones
+}
+
+// PlanEnvCommand creates a plan that prints ctx.Vars as shell commands
+// that export them, for eval, or the values of ctx.Names alone. Names
+// without a value print empty lines, so each value stays on its line.
+func PlanEnvCommand(ctx EnvContext) Plan {
+	var lines []string
+	if len(ctx.Names) > 0 {
+		values := make(map[string]string, len(ctx.Vars))
+		for _, v := range ctx.Vars {
+			values[v.Name] = v.Value
+		}
+		for _, name := range ctx.Names {
+			lines = append(lines, values[name])
+		}
+	} else {
+		for _, v := range ctx.Vars {
+			if ctx.Fish {
+				lines = append(lines, fmt.Sprintf("set -gx %s %s;", v.Name, FishQuote(v.Value)))
+			} else {
+				lines = append(lines, fmt.Sprintf("export %s=%s;", v.Name, ShellQuote(v.Value)))
+			}
+		}
+	}
+	return Plan{Actions: []Action{PrintMessage{Msg: strings.Join(lines, "\n")}}}
+}
+
+// ShellQuote quotes s for POSIX shells: in single quotes, in which only a
+// single quote needs escaping.
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// FishQuote quotes s for fish, whose single quotes also escape backslashes.
+func FishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
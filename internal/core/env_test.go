@@ -0,0 +1,42 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanEnvCommand(t *testing.T) {
+	t.Parallel()
+	vars := []EnvVar{
+		{Name: "SPROUT_ROOT", Value: "/home/me/.local/share/sprout"},
+		{Name: "SPROUT_BRANCH", Value: "it's/fix"},
+	}
+
+	t.Run("POSIX shell", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanEnvCommand(EnvContext{Vars: vars})
+		assert.Equal(t, []Action{PrintMessage{Msg: "" +
+			"export SPROUT_ROOT='/home/me/.local/share/sprout';\n" +
+			`export SPROUT_BRANCH='it'\''s/fix';`}}, plan.Actions)
+	})
+
+	t.Run("fish", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanEnvCommand(EnvContext{Vars: vars, Fish: true})
+		assert.Equal(t, []Action{PrintMessage{Msg: "" +
+			"set -gx SPROUT_ROOT '/home/me/.local/share/sprout';\n" +
+			`set -gx SPROUT_BRANCH 'it\'s/fix';`}}, plan.Actions)
+	})
+
+	t.Run("values of names", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanEnvCommand(EnvContext{Vars: vars, Names: []string{"SPROUT_BRANCH", "SPROUT_SCRATCH", "SPROUT_ROOT"}})
+		assert.Equal(t, []Action{PrintMessage{Msg: "it's/fix\n\n/home/me/.local/share/sprout"}}, plan.Actions)
+	})
+}
+
+func TestFishQuote(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, `'a\\b'`, FishQuote(`a\b`))
+}
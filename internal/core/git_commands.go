@@ -1,10 +1,11 @@
 package core
 
 // WorktreeAddArgs constructs git arguments for creating a worktree.
-// It follows this priority: local branch > remote branch > new from origin/main > new from HEAD.
+// It follows this priority: local branch > remote branch > new from base > new from HEAD.
 // When creating from a remote branch, upstream tracking is enabled by default.
-// For truly new branches, --no-track is used to avoid configuring an upstream.
-func WorktreeAddArgs(path, branch string, localExists bool, remoteBranchExists bool, hasOriginMain bool) []string {
+// For truly new branches, --no-track is used to avoid configuring an upstream;
+// they start from base (e.g. origin/main), or from HEAD when base is empty.
+func WorktreeAddArgs(path, branch string, localExists bool, remoteBranchExists bool, base string) []string {
 	args := []string{"worktree", "add", path}
 
 	// Case 1: Local branch exists - simple checkout
@@ -22,9 +23,21 @@ func WorktreeAddArgs(path, branch string, localExists bool, remoteBranchExists b
 	// --no-track must follow -b (it's a branch creation option, not worktree option)
 	args = append(args, "-b", branch, "--no-track")
 
-	if hasOriginMain {
-		return append(args, "origin/main")
+	if base != "" {
+		return append(args, base)
 	}
 
 	return append(args, "HEAD")
 }
+
+// TagWorktreeAddArgs constructs git arguments for creating a worktree on a
+// new branch that starts at a tag, without an upstream.
+func TagWorktreeAddArgs(path, branch, tag string) []string {
+	return []string{"worktree", "add", path, "-b", branch, "--no-track", "refs/tags/" + tag}
+}
+
+// DetachWorktreeAddArgs constructs git arguments for creating a worktree
+// with a detached HEAD at commit.
+func DetachWorktreeAddArgs(path, commit string) []string {
+	return []string{"worktree", "add", "--detach", path, commit}
+}
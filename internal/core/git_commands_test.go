@@ -13,7 +13,7 @@ func TestWorktreeAddArgs(t *testing.T) {
 		branch             string
 		localExists        bool
 		remoteBranchExists bool
-		hasOriginMain      bool
+		base               string
 		want               []string
 	}{
 		{
@@ -22,7 +22,7 @@ func TestWorktreeAddArgs(t *testing.T) {
 			branch:             "feature-123",
 			localExists:        true,
 			remoteBranchExists: false,
-			hasOriginMain:      false,
+			base:               "",
 			want:               []string{"worktree", "add", "/path/to/worktree", "feature-123"},
 		},
 		{
@@ -31,7 +31,7 @@ func TestWorktreeAddArgs(t *testing.T) {
 			branch:             "feature-456",
 			localExists:        false,
 			remoteBranchExists: true,
-			hasOriginMain:      false,
+			base:               "",
 			want:               []string{"worktree", "add", "/path/to/worktree", "-b", "feature-456", "origin/feature-456"},
 		},
 		{
@@ -40,7 +40,7 @@ func TestWorktreeAddArgs(t *testing.T) {
 			branch:             "new-feature",
 			localExists:        false,
 			remoteBranchExists: false,
-			hasOriginMain:      true,
+			base:               "origin/main",
 			want:               []string{"worktree", "add", "/path/to/worktree", "-b", "new-feature", "--no-track", "origin/main"},
 		},
 		{
@@ -49,7 +49,7 @@ func TestWorktreeAddArgs(t *testing.T) {
 			branch:             "experimental",
 			localExists:        false,
 			remoteBranchExists: false,
-			hasOriginMain:      false,
+			base:               "",
 			want:               []string{"worktree", "add", "/path/to/worktree", "-b", "experimental", "--no-track", "HEAD"},
 		},
 		{
@@ -58,7 +58,7 @@ func TestWorktreeAddArgs(t *testing.T) {
 			branch:             "main",
 			localExists:        true,
 			remoteBranchExists: true,
-			hasOriginMain:      true,
+			base:               "origin/main",
 			want:               []string{"worktree", "add", "/path/to/worktree", "main"},
 		},
 		{
@@ -67,7 +67,7 @@ func TestWorktreeAddArgs(t *testing.T) {
 			branch:             "develop",
 			localExists:        false,
 			remoteBranchExists: true,
-			hasOriginMain:      true,
+			base:               "origin/main",
 			want:               []string{"worktree", "add", "/path/to/worktree", "-b", "develop", "origin/develop"},
 		},
 	}
@@ -76,7 +76,7 @@ func TestWorktreeAddArgs(t *testing.T) {
 		tt := tt // capture range variable
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			got := WorktreeAddArgs(tt.path, tt.branch, tt.localExists, tt.remoteBranchExists, tt.hasOriginMain)
+			got := WorktreeAddArgs(tt.path, tt.branch, tt.localExists, tt.remoteBranchExists, tt.base)
 			assert.Equal(t, tt.want, got, "git command mismatch")
 		})
 	}
@@ -94,17 +94,17 @@ func indexOf(xs []string, v string) int {
 
 func TestWorktreeAddArgs_ArgumentOrder(t *testing.T) {
 	t.Run("existing local branch has no --no-track", func(t *testing.T) {
-		result := WorktreeAddArgs("/path", "existing", true, false, false)
+		result := WorktreeAddArgs("/path", "existing", true, false, "")
 		assert.NotContains(t, result, "--no-track", "existing local branches should not have --no-track")
 	})
 
 	t.Run("remote branch has no --no-track (wants tracking)", func(t *testing.T) {
-		result := WorktreeAddArgs("/path", "remote-branch", false, true, false)
+		result := WorktreeAddArgs("/path", "remote-branch", false, true, "")
 		assert.NotContains(t, result, "--no-track", "remote branches should enable upstream tracking")
 	})
 
 	t.Run("new branch places --no-track AFTER -b (Git semantics)", func(t *testing.T) {
-		result := WorktreeAddArgs("/path", "new", false, false, true)
+		result := WorktreeAddArgs("/path", "new", false, false, "origin/main")
 
 		idxB := indexOf(result, "-b")
 		idxNoTrack := indexOf(result, "--no-track")
@@ -115,7 +115,7 @@ func TestWorktreeAddArgs_ArgumentOrder(t *testing.T) {
 	})
 
 	t.Run("new branch from HEAD places --no-track AFTER -b", func(t *testing.T) {
-		result := WorktreeAddArgs("/path", "new", false, false, false)
+		result := WorktreeAddArgs("/path", "new", false, false, "")
 
 		idxB := indexOf(result, "-b")
 		idxNoTrack := indexOf(result, "--no-track")
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertGolden compares got with testdata/<name>.golden. Run the tests
+// with UPDATE_GOLDEN=1 to write got there instead, then review the diff.
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if os.Getenv("UPDATE_GOLDEN") == "1" {
+		require.NoError(t, os.MkdirAll("testdata", 0755))
+		require.NoError(t, os.WriteFile(path, []byte(got), 0644))
+		return
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "run with UPDATE_GOLDEN=1 to create %s", path)
+	assert.Equal(t, string(want), got, "output differs from %s; run with UPDATE_GOLDEN=1 if the change is intended", path)
+}
+
+var goldenRepos = []RepoDisplay{
+	{
+		Name:     "api",
+		MainPath: "/home/me/code/work/api",
+		Group:    "work",
+		Worktrees: []WorktreeDisplayItem{
+			{Branch: "main", Path: "/home/me/code/work/api", IsMain: true},
+			{Branch: "fix/login", Path: "/home/me/.local/share/sprout/api/fix/login/api", Pinned: true, Status: git.WorktreeStatus{Dirty: true, Ahead: 2}},
+			{Branch: "feat/search", Path: "/home/me/.local/share/sprout/api/feat/search/api", Ticket: "ABC-123 Search", Base: "origin/main", Note: "waiting on review", Status: git.WorktreeStatus{Behind: 3}},
+			{Branch: "spike", Path: "/home/me/.local/share/sprout/api/spike/api", Temporary: true, ExpiresIn: 26 * time.Hour, Status: git.WorktreeStatus{Dirty: true, UntrackedOnly: true}},
+			{Path: "/home/me/.local/share/sprout/api/detached/api", Label: "v1.2.0", Commit: "1a2b3c4", Status: git.WorktreeStatus{Unmerged: true}},
+		},
+	},
+	{
+		Name:     "blog",
+		MainPath: "/home/me/code/personal/blog",
+		Group:    "personal",
+		Worktrees: []WorktreeDisplayItem{
+			{Branch: "main", Path: "/home/me/code/personal/blog", IsMain: true},
+			{Branch: "draft", Path: "/home/me/.local/share/sprout/blog/draft/blog", Temporary: true, ExpiresIn: -time.Hour, Status: git.WorktreeStatus{HasStash: true}},
+		},
+	},
+}
+
+func TestFormatListOutput_Golden(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  ListContext
+	}{
+		{name: "list_repo", ctx: ListContext{Repos: goldenRepos[:1], Home: "/home/me"}},
+		{name: "list_repo_counts", ctx: ListContext{Repos: goldenRepos[:1], Home: "/home/me", Counts: true}},
+		{name: "list_all_grouped", ctx: ListContext{Repos: goldenRepos, Home: "/home/me", ShowAll: true}},
+		{name: "list_all_collapsed", ctx: ListContext{Repos: goldenRepos, Home: "/home/me", ShowAll: true, Collapse: true}},
+		{name: "list_porcelain", ctx: ListContext{Repos: goldenRepos, Home: "/home/me", ShowAll: true, Porcelain: PorcelainV1}},
+		{name: "list_workspaces", ctx: ListContext{ByWorkspace: true, Home: "/home/me", Workspaces: []RepoWorkspace{{Name: "feature-x", Members: []WsMember{
+			{Repo: "api", MainPath: "/home/me/code/work/api", WorktreePath: "/home/me/.local/share/sprout/api/feature-x/api", Status: git.WorktreeStatus{Dirty: true}},
+			{Repo: "web", MainPath: "/home/me/code/work/web"},
+		}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertGolden(t, tt.name, FormatListOutput(tt.ctx))
+		})
+	}
+}
+
+func TestFormatPlan_Golden(t *testing.T) {
+	add := AddContext{
+		Branch:           "feat/search",
+		RepoRoot:         "/home/me/code/api",
+		MainWorktreePath: "/home/me/code/api",
+		WorktreePath:     "/home/me/.local/share/sprout/api/feat/search/api",
+		RemoteBase:       "origin/main",
+		Config: &config.Config{Hooks: config.HooksConfig{
+			OnCreate: []string{"npm ci", "cp $SPROUT_MAIN_WORKTREE_PATH/.env $SPROUT_WORKTREE_PATH/", "echo port $SPROUT_PORT_BASE"},
+		}},
+		DefaultHooks: []string{"direnv allow"},
+	}
+	remove := RemoveContext{
+		RepoRoot:   "/home/me/code/api",
+		SproutRoot: "/home/me/.local/share/sprout",
+		TargetPath: "/home/me/.local/share/sprout/api/feat/search/api",
+		Force:      true,
+	}
+	tests := []struct {
+		name string
+		plan Plan
+	}{
+		{name: "plan_add", plan: PlanAddCommand(add)},
+		{name: "plan_remove", plan: PlanRemoveCommand(remove)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertGolden(t, tt.name, FormatPlan(tt.plan))
+		})
+		t.Run(tt.name+"_rendered", func(t *testing.T) {
+			assertGolden(t, tt.name+"_rendered", RenderPlan(tt.plan))
+		})
+	}
+}
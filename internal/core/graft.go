@@ -0,0 +1,17 @@
+package core
+
+import "fmt"
+
+// PlanGraftCommand creates a plan that moves the uncommitted changes of
+// ctx.GraftFrom into a new worktree for ctx.Branch, leaving the source
+// clean. It is PlanAddCommand with the changes carried over; if they don't
+// apply cleanly, git keeps them in the stash.
+func PlanGraftCommand(ctx AddContext) Plan {
+	if ctx.GraftFrom == "" {
+		return errorPlan(ErrEmptyWorktreePath)
+	}
+	if ctx.WorktreeExists {
+		return errorPlan(fmt.Errorf("a worktree for %s already exists at %s", ctx.Branch, ctx.WorktreePath))
+	}
+	return PlanAddCommand(ctx)
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanGraftCommand(t *testing.T) {
+	t.Parallel()
+
+	ctx := AddContext{
+		Branch:           "feature",
+		RepoRoot:         "/repo",
+		MainWorktreePath: "/repo",
+		WorktreePath:     "/sprout/feature",
+		RemoteBase:       "origin/main",
+		Config:           &config.Config{Hooks: config.HooksConfig{OnCreate: []string{"npm ci"}}},
+		IsTrusted:        true,
+		NoOpen:           true,
+		TemplateDir:      "/repo/.sprout/template",
+		TemplateFiles:    []string{".env.local"},
+		GraftFrom:        "/repo",
+	}
+
+	t.Run("changes move right after checkout", func(t *testing.T) {
+		t.Parallel()
+
+		plan := PlanGraftCommand(ctx)
+
+		require.Len(t, plan.Actions, 13)
+		assert.Equal(t, []string{"worktree", "add"}, plan.Actions[2].(RunGitCommand).Args[:2])
+		assert.Equal(t, PrintMessage{Msg: "🌿 Moving uncommitted changes from /repo"}, plan.Actions[3])
+		assert.Equal(t, RunGitCommand{
+			Dir:  "/repo",
+			Args: []string{"stash", "push", "--include-untracked", "--message", "sprout graft to feature"},
+		}, plan.Actions[4])
+		assert.Equal(t, RunGitCommand{Dir: "/sprout/feature", Args: []string{"stash", "pop"}}, plan.Actions[5])
+		assert.IsType(t, ForgetMetadata{}, plan.Actions[6])
+		assert.IsType(t, RecordBase{}, plan.Actions[7])
+		assert.IsType(t, CopyFile{}, plan.Actions[9])
+		assert.IsType(t, RunHooks{}, plan.Actions[12])
+	})
+
+	t.Run("also after a trust prompt", func(t *testing.T) {
+		t.Parallel()
+		untrusted := ctx
+		untrusted.IsTrusted = false
+
+		plan := PlanGraftCommand(untrusted)
+
+		require.IsType(t, PromptTrust{}, plan.Actions[0])
+		assert.Equal(t, RunGitCommand{Dir: "/sprout/feature", Args: []string{"stash", "pop"}}, plan.Actions[6])
+	})
+
+	t.Run("existing worktree", func(t *testing.T) {
+		t.Parallel()
+		existing := ctx
+		existing.WorktreeExists = true
+
+		plan := PlanGraftCommand(existing)
+
+		require.Len(t, plan.Actions, 2)
+		assert.Equal(t, PrintError{Msg: "a worktree for feature already exists at /sprout/feature"}, plan.Actions[0])
+		assert.Equal(t, Exit{Code: 1}, plan.Actions[1])
+	})
+}
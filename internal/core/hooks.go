@@ -0,0 +1,236 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/style"
+)
+
+// HooksContext contains all inputs needed to plan the hooks command.
+type HooksContext struct {
+	RepoRoot         string
+	MainWorktreePath string
+	ConfigPath       string // .sprout.yml in the current worktree, or the main worktree as fallback
+	ConfigExists     bool
+
+	// Only populated when ConfigExists is true
+	Config         *config.Config
+	IsTrusted      bool
+	DeniedByPolicy bool
+	LastRuns       []HookRunSummary // Sorted by worktree path, then hook type
+	Now            time.Time        // For the age of the last runs
+
+	JSON bool // Print a machine-readable report (--json)
+}
+
+// HookRunSummary is the last run of a worktree's hooks of one type.
+type HookRunSummary struct {
+	WorktreePath string              `json:"worktree_path"`
+	Branch       string              `json:"branch,omitempty"`
+	Type         HookType            `json:"type"`
+	At           time.Time           `json:"at"`
+	Commands     []HookCommandResult `json:"commands"`
+}
+
+// HookCommandResult is how a single hook command went.
+type HookCommandResult struct {
+	Command    string `json:"command"`
+	DurationMS int64  `json:"duration_ms"`
+	ExitCode   int    `json:"exit_code"`
+}
+
+// HooksReport is the machine-readable output of 'sprout hooks --json'.
+type HooksReport struct {
+	Repository     string           `json:"repository"`
+	MainWorktree   string           `json:"main_worktree"`
+	ConfigPath     string           `json:"config_path,omitempty"`
+	ConfigExists   bool             `json:"config_exists"`
+	Trusted        bool             `json:"trusted"`
+	DeniedByPolicy bool             `json:"denied_by_policy"`
+	HooksHash      string           `json:"hooks_hash,omitempty"`
+	Hooks          HooksReportHooks `json:"hooks"`
+	LastRuns       []HookRunSummary `json:"last_runs"`
+}
+
+// HooksReportHooks lists the configured commands per hook type.
+type HooksReportHooks struct {
+	OnCreate []string `json:"on_create"`
+	OnOpen   []string `json:"on_open"`
+	PostSync []string `json:"post_sync"`
+}
+
+// PlanHooksCommand creates a plan that prints the hook configuration status
+// of a repository, as human-readable text or (with JSON set) a HooksReport.
+func PlanHooksCommand(ctx HooksContext) Plan {
+	if ctx.RepoRoot == "" {
+		return errorPlan(ErrEmptyRepoRoot)
+	}
+	if ctx.ConfigExists && ctx.Config == nil {
+		return errorPlan(ErrNilConfig)
+	}
+
+	if ctx.JSON {
+		out, err := FormatHooksReport(ctx)
+		if err != nil {
+			return errorPlan(err)
+		}
+		return Plan{Actions: []Action{PrintMessage{Msg: out}}}
+	}
+
+	return Plan{Actions: []Action{PrintMessage{Msg: formatHooksStatus(ctx)}}}
+}
+
+// FormatHooksReport renders the hooks status as indented JSON.
+// Trust fields and the hooks hash are only set when a config file exists.
+func FormatHooksReport(ctx HooksContext) (string, error) {
+	report := HooksReport{
+		Repository:   ctx.RepoRoot,
+		MainWorktree: ctx.MainWorktreePath,
+		ConfigExists: ctx.ConfigExists,
+		Hooks:        HooksReportHooks{OnCreate: []string{}, OnOpen: []string{}, PostSync: []string{}},
+		LastRuns:     []HookRunSummary{},
+	}
+
+	if ctx.ConfigExists && ctx.Config != nil {
+		report.ConfigPath = ctx.ConfigPath
+		report.Trusted = ctx.IsTrusted
+		report.DeniedByPolicy = ctx.DeniedByPolicy
+		report.HooksHash = ctx.Config.HooksHash()
+		if ctx.Config.HasCreateHooks() {
+			report.Hooks.OnCreate = ctx.Config.Hooks.OnCreate
+		}
+		if ctx.Config.HasOpenHooks() {
+			report.Hooks.OnOpen = ctx.Config.Hooks.OnOpen
+		}
+		if ctx.Config.HasSyncHooks() {
+			report.Hooks.PostSync = ctx.Config.Hooks.PostSync
+		}
+		if len(ctx.LastRuns) > 0 {
+			report.LastRuns = ctx.LastRuns
+		}
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode hooks: %w", err)
+	}
+	return string(data), nil
+}
+
+// formatHooksStatus renders the human-readable hooks status.
+func formatHooksStatus(ctx HooksContext) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "\nRepository: %s\n\n", ctx.RepoRoot)
+
+	if !ctx.ConfigExists {
+		b.WriteString("❌ No .sprout.yml found\n\n")
+		b.WriteString("To add hooks, create a .sprout.yml file in your repository root.\n")
+		b.WriteString("Example:\n\n")
+		b.WriteString("  hooks:\n")
+		b.WriteString("    on_create:\n")
+		b.WriteString("      - npm ci\n")
+		b.WriteString("      - npm run build\n")
+		b.WriteString("    on_open:\n")
+		b.WriteString("      - npm run lint:types\n")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "✅ Config file: %s\n\n", ctx.ConfigPath)
+
+	switch {
+	case ctx.DeniedByPolicy:
+		b.WriteString("🚫 Hooks are disabled by organization trust policy\n")
+	case ctx.IsTrusted:
+		b.WriteString("✅ Repository is trusted\n")
+	default:
+		b.WriteString("🔒 Repository is NOT trusted\n\n")
+		b.WriteString("Run 'sprout trust' to enable hooks for this repository.\n")
+	}
+	b.WriteString("\n")
+
+	cfg := ctx.Config
+	if !cfg.HasHooks() {
+		b.WriteString("ℹ️  No hooks defined")
+		return b.String()
+	}
+
+	if cfg.HasCreateHooks() {
+		b.WriteString("on_create hooks:\n")
+		for i, cmd := range cfg.Hooks.OnCreate {
+			fmt.Fprintf(&b, "  %d. %s\n", i+1, cmd)
+		}
+		b.WriteString("\n")
+	}
+
+	if cfg.HasOpenHooks() {
+		b.WriteString("on_open hooks:\n")
+		for i, cmd := range cfg.Hooks.OnOpen {
+			fmt.Fprintf(&b, "  %d. %s\n", i+1, cmd)
+		}
+		b.WriteString("\n")
+	}
+
+	if cfg.HasSyncHooks() {
+		b.WriteString("post_sync hooks:\n")
+		for i, cmd := range cfg.Hooks.PostSync {
+			fmt.Fprintf(&b, "  %d. %s\n", i+1, cmd)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(ctx.LastRuns) > 0 {
+		b.WriteString("Last runs:\n")
+		b.WriteString(formatHookRuns(ctx.LastRuns, ctx.Now))
+		b.WriteString("\n")
+	}
+
+	// Show how hooks are triggered
+	if ctx.IsTrusted {
+		b.WriteString("Hooks run automatically when:\n")
+		if cfg.HasCreateHooks() {
+			b.WriteString("  - sprout add           (runs on_create)\n")
+		}
+		if cfg.HasOpenHooks() {
+			b.WriteString("  - sprout open          (runs on_open)\n")
+		}
+		if cfg.HasSyncHooks() {
+			b.WriteString("  - sprout rebase-all    (runs post_sync in each updated worktree)\n")
+		}
+		b.WriteString("\nUse --no-hooks flag to skip automatic execution.\n")
+	}
+
+	return b.String()
+}
+
+// formatHookRuns lists each run with its age, and every command with its
+// duration, marking failed ones with their exit code.
+func formatHookRuns(runs []HookRunSummary, now time.Time) string {
+	var b strings.Builder
+	for _, run := range runs {
+		name := run.Branch
+		if name == "" {
+			name = filepath.Base(run.WorktreePath)
+		}
+		fmt.Fprintf(&b, "  %s  %s  %s\n", style.Bold(name), run.Type, style.Gray(FormatAge(now.Sub(run.At))))
+
+		width := 0
+		for _, cmd := range run.Commands {
+			width = max(width, style.Width(cmd.Command))
+		}
+		for _, cmd := range run.Commands {
+			duration := (time.Duration(cmd.DurationMS) * time.Millisecond).Round(10 * time.Millisecond)
+			if cmd.ExitCode != 0 {
+				fmt.Fprintf(&b, "    %s %s  %8s  %s\n", style.Red("✗"), style.PadRight(cmd.Command, width), duration, style.Red(fmt.Sprintf("exit %d", cmd.ExitCode)))
+				continue
+			}
+			fmt.Fprintf(&b, "    %s %s  %8s\n", style.Green("✓"), style.PadRight(cmd.Command, width), duration)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,167 @@
+package core_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/style"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func hooksMessage(t *testing.T, plan core.Plan) string {
+	t.Helper()
+	require.Len(t, plan.Actions, 1)
+	msg, ok := plan.Actions[0].(core.PrintMessage)
+	require.True(t, ok, "expected PrintMessage, got %T", plan.Actions[0])
+	return msg.Msg
+}
+
+func TestPlanHooksCommand_NoConfig(t *testing.T) {
+	msg := hooksMessage(t, core.PlanHooksCommand(core.HooksContext{
+		RepoRoot:         "/repo",
+		MainWorktreePath: "/repo",
+	}))
+
+	assert.Contains(t, msg, "Repository: /repo")
+	assert.Contains(t, msg, "❌ No .sprout.yml found")
+	assert.NotContains(t, msg, "trusted")
+}
+
+func TestPlanHooksCommand_Trusted(t *testing.T) {
+	msg := hooksMessage(t, core.PlanHooksCommand(core.HooksContext{
+		RepoRoot:         "/repo",
+		MainWorktreePath: "/repo",
+		ConfigPath:       "/repo/.sprout.yml",
+		ConfigExists:     true,
+		IsTrusted:        true,
+		Config: &config.Config{Hooks: config.HooksConfig{
+			OnCreate: []string{"npm ci", "npm run build"},
+		}},
+	}))
+
+	assert.Contains(t, msg, "✅ Config file: /repo/.sprout.yml")
+	assert.Contains(t, msg, "✅ Repository is trusted")
+	assert.Contains(t, msg, "  1. npm ci\n  2. npm run build\n")
+	assert.Contains(t, msg, "sprout add           (runs on_create)")
+	assert.NotContains(t, msg, "on_open hooks:")
+	assert.NotContains(t, msg, "sprout open")
+}
+
+func TestPlanHooksCommand_Untrusted(t *testing.T) {
+	msg := hooksMessage(t, core.PlanHooksCommand(core.HooksContext{
+		RepoRoot:     "/repo",
+		ConfigPath:   "/repo/.sprout.yml",
+		ConfigExists: true,
+		Config:       &config.Config{Hooks: config.HooksConfig{OnOpen: []string{"make"}}},
+	}))
+
+	assert.Contains(t, msg, "🔒 Repository is NOT trusted")
+	assert.Contains(t, msg, "Run 'sprout trust'")
+	assert.Contains(t, msg, "on_open hooks:\n  1. make\n")
+	assert.NotContains(t, msg, "Hooks run automatically")
+}
+
+func TestPlanHooksCommand_DeniedByPolicy(t *testing.T) {
+	msg := hooksMessage(t, core.PlanHooksCommand(core.HooksContext{
+		RepoRoot:       "/repo",
+		ConfigPath:     "/repo/.sprout.yml",
+		ConfigExists:   true,
+		DeniedByPolicy: true,
+		Config:         &config.Config{},
+	}))
+
+	assert.Contains(t, msg, "🚫 Hooks are disabled by organization trust policy")
+	assert.Contains(t, msg, "No hooks defined")
+}
+
+func TestPlanHooksCommand_JSON(t *testing.T) {
+	cfg := &config.Config{Hooks: config.HooksConfig{OnCreate: []string{"npm ci"}}}
+	msg := hooksMessage(t, core.PlanHooksCommand(core.HooksContext{
+		RepoRoot:         "/repo/wt",
+		MainWorktreePath: "/repo",
+		ConfigPath:       "/repo/.sprout.yml",
+		ConfigExists:     true,
+		IsTrusted:        true,
+		Config:           cfg,
+		JSON:             true,
+	}))
+
+	var report core.HooksReport
+	require.NoError(t, json.Unmarshal([]byte(msg), &report))
+	assert.Equal(t, core.HooksReport{
+		Repository:   "/repo/wt",
+		MainWorktree: "/repo",
+		ConfigPath:   "/repo/.sprout.yml",
+		ConfigExists: true,
+		Trusted:      true,
+		HooksHash:    cfg.HooksHash(),
+		Hooks:        core.HooksReportHooks{OnCreate: []string{"npm ci"}, OnOpen: []string{}, PostSync: []string{}},
+		LastRuns:     []core.HookRunSummary{},
+	}, report)
+	assert.Contains(t, msg, `"on_open": []`)
+}
+
+func TestPlanHooksCommand_LastRuns(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	runs := []core.HookRunSummary{
+		{WorktreePath: "/wt/feature", Branch: "feature", Type: core.HookTypeOnCreate, At: now.Add(-2 * time.Hour), Commands: []core.HookCommandResult{
+			{Command: "npm ci", DurationMS: 41234},
+			{Command: "npm run build", DurationMS: 3080, ExitCode: 2},
+		}},
+		{WorktreePath: "/wt/detached", Type: core.HookTypeOnOpen, At: now, Commands: []core.HookCommandResult{
+			{Command: "make", DurationMS: 120},
+		}},
+	}
+	ctx := core.HooksContext{
+		RepoRoot:     "/repo",
+		ConfigPath:   "/repo/.sprout.yml",
+		ConfigExists: true,
+		IsTrusted:    true,
+		Config:       &config.Config{Hooks: config.HooksConfig{OnCreate: []string{"npm ci", "npm run build"}}},
+		LastRuns:     runs,
+		Now:          now,
+	}
+
+	msg := hooksMessage(t, core.PlanHooksCommand(ctx))
+	assert.Contains(t, style.Strip(msg), "Last runs:\n"+
+		"  feature  on_create  2h ago\n"+
+		"    ✓ npm ci           41.23s\n"+
+		"    ✗ npm run build     3.08s  exit 2\n"+
+		"  detached  on_open  just now\n"+
+		"    ✓ make     120ms\n")
+
+	ctx.JSON = true
+	var report core.HooksReport
+	require.NoError(t, json.Unmarshal([]byte(hooksMessage(t, core.PlanHooksCommand(ctx))), &report))
+	assert.Equal(t, runs, report.LastRuns)
+}
+
+func TestPlanHooksCommand_JSONNoConfig(t *testing.T) {
+	msg := hooksMessage(t, core.PlanHooksCommand(core.HooksContext{RepoRoot: "/repo", JSON: true}))
+
+	assert.NotContains(t, msg, "config_path")
+	assert.NotContains(t, msg, "hooks_hash")
+	assert.Contains(t, msg, `"config_exists": false`)
+}
+
+func TestPlanHooksCommand_HashTracksCommands(t *testing.T) {
+	a := &config.Config{Hooks: config.HooksConfig{OnCreate: []string{"npm ci"}}}
+	b := &config.Config{Hooks: config.HooksConfig{OnOpen: []string{"npm ci"}}}
+
+	assert.Equal(t, a.HooksHash(), (&config.Config{Hooks: config.HooksConfig{OnCreate: []string{"npm ci"}, OnOpen: []string{}}}).HooksHash())
+	assert.NotEqual(t, a.HooksHash(), b.HooksHash())
+}
+
+func TestPlanHooksCommand_Validation(t *testing.T) {
+	plan := core.PlanHooksCommand(core.HooksContext{})
+	require.Len(t, plan.Actions, 2)
+	assert.Equal(t, core.PrintError{Msg: core.ErrEmptyRepoRoot.Error()}, plan.Actions[0])
+
+	plan = core.PlanHooksCommand(core.HooksContext{RepoRoot: "/repo", ConfigExists: true})
+	require.Len(t, plan.Actions, 2)
+	assert.Equal(t, core.PrintError{Msg: core.ErrNilConfig.Error()}, plan.Actions[0])
+}
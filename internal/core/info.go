@@ -0,0 +1,63 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/m44rten1/sprout/internal/tickets"
+)
+
+// InfoContext contains all inputs needed to plan the info command.
+type InfoContext struct {
+	MainWorktreePath string
+	WorktreePath     string
+	Branch           string         // Empty for a detached HEAD
+	Index            int            // Stable per-worktree index (0 for the main worktree)
+	PortBase         int            // First port reserved for this worktree
+	PortCount        int            // Number of ports reserved starting at PortBase
+	Ticket           tickets.Ticket // Ticket the worktree was created for, if any
+	Base             string         // Ref the branch was created from, if recorded
+	Scratch          string         // Scratch directory exported to hooks as $SPROUT_SCRATCH
+	Porcelain        string         // Porcelain format to print (--porcelain), if any
+}
+
+// PlanInfoCommand creates a plan that prints details about a worktree,
+// including the index and port range exported to hooks.
+func PlanInfoCommand(ctx InfoContext) Plan {
+	if ctx.MainWorktreePath == "" {
+		return errorPlan(ErrEmptyMainWorktreePath)
+	}
+	if ctx.WorktreePath == "" {
+		return errorPlan(ErrEmptyWorktreePath)
+	}
+
+	if ctx.Porcelain != "" {
+		return Plan{Actions: []Action{PrintMessage{Msg: FormatInfoPorcelain(ctx)}}}
+	}
+
+	branch := ctx.Branch
+	if branch == "" {
+		branch = "(detached)"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Repository:  %s\n", ctx.MainWorktreePath)
+	fmt.Fprintf(&b, "Worktree:    %s\n", ctx.WorktreePath)
+	fmt.Fprintf(&b, "Branch:      %s\n", branch)
+	if ctx.Base != "" {
+		fmt.Fprintf(&b, "Base:        %s\n", ctx.Base)
+	}
+	fmt.Fprintf(&b, "Index:       %d\n", ctx.Index)
+	fmt.Fprintf(&b, "Ports:       %d-%d", ctx.PortBase, ctx.PortBase+ctx.PortCount-1)
+	if ctx.Scratch != "" {
+		fmt.Fprintf(&b, "\nScratch:     %s", ctx.Scratch)
+	}
+	if ctx.Ticket.ID != "" {
+		fmt.Fprintf(&b, "\nTicket:      %s", ctx.Ticket.Summary())
+		if ctx.Ticket.URL != "" {
+			fmt.Fprintf(&b, "\n             %s", ctx.Ticket.URL)
+		}
+	}
+
+	return Plan{Actions: []Action{PrintMessage{Msg: b.String()}}}
+}
@@ -0,0 +1,79 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/tickets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanInfoCommand(t *testing.T) {
+	plan := core.PlanInfoCommand(core.InfoContext{
+		MainWorktreePath: "/repo",
+		WorktreePath:     "/sprout/repo-1234/feature/repo",
+		Branch:           "feature",
+		Index:            2,
+		PortBase:         3020,
+		PortCount:        10,
+		Scratch:          "/sprout/scratch/1a2b3c4d",
+	})
+
+	require.Len(t, plan.Actions, 1)
+	msg, ok := plan.Actions[0].(core.PrintMessage)
+	require.True(t, ok)
+	assert.Contains(t, msg.Msg, "Branch:      feature")
+	assert.Contains(t, msg.Msg, "Index:       2")
+	assert.Contains(t, msg.Msg, "Ports:       3020-3029")
+	assert.Contains(t, msg.Msg, "Scratch:     /sprout/scratch/1a2b3c4d")
+}
+
+func TestPlanInfoCommand_Ticket(t *testing.T) {
+	plan := core.PlanInfoCommand(core.InfoContext{
+		MainWorktreePath: "/repo",
+		WorktreePath:     "/sprout/repo-1234/feature/repo",
+		Branch:           "feature",
+		PortCount:        10,
+		Ticket:           tickets.Ticket{ID: "ENG-7", Title: "Add dark mode", URL: "https://linear.app/acme/issue/ENG-7"},
+	})
+
+	require.Len(t, plan.Actions, 1)
+	msg := plan.Actions[0].(core.PrintMessage).Msg
+	assert.Contains(t, msg, "Ticket:      ENG-7 Add dark mode")
+	assert.Contains(t, msg, "https://linear.app/acme/issue/ENG-7")
+}
+
+func TestPlanInfoCommand_Base(t *testing.T) {
+	plan := core.PlanInfoCommand(core.InfoContext{
+		MainWorktreePath: "/repo",
+		WorktreePath:     "/sprout/repo-1234/feature/repo",
+		Branch:           "feature",
+		PortCount:        10,
+		Base:             "origin/main",
+	})
+
+	msg := plan.Actions[0].(core.PrintMessage).Msg
+	assert.Contains(t, msg, "Branch:      feature\nBase:        origin/main\n")
+}
+
+func TestPlanInfoCommand_Detached(t *testing.T) {
+	plan := core.PlanInfoCommand(core.InfoContext{
+		MainWorktreePath: "/repo",
+		WorktreePath:     "/sprout/repo-1234/abc/repo",
+		PortCount:        10,
+	})
+
+	require.Len(t, plan.Actions, 1)
+	assert.Contains(t, plan.Actions[0].(core.PrintMessage).Msg, "(detached)")
+}
+
+func TestPlanInfoCommand_Validation(t *testing.T) {
+	plan := core.PlanInfoCommand(core.InfoContext{WorktreePath: "/wt"})
+	require.Len(t, plan.Actions, 2)
+	assert.Equal(t, core.PrintError{Msg: core.ErrEmptyMainWorktreePath.Error()}, plan.Actions[0])
+
+	plan = core.PlanInfoCommand(core.InfoContext{MainWorktreePath: "/repo"})
+	require.Len(t, plan.Actions, 2)
+	assert.Equal(t, core.PrintError{Msg: core.ErrEmptyWorktreePath.Error()}, plan.Actions[0])
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Message constants for the max_worktrees limit
+const (
+	msgOverWorktreeLimit = "⚠️  This repository has %d worktrees (max_worktrees: %d). Least recently used:"
+	msgEvictHint         = "Remove some with 'sprout remove <branch>', or pass --evict to remove clean, merged ones automatically."
+	msgEvictedWorktree   = "🧹 Evicting %s (last used %s)"
+	msgNothingToEvict    = "⚠️  Still over max_worktrees (%d): no other clean, merged worktrees to evict."
+	lruCandidatesShown   = 3
+)
+
+// WorktreeUsage describes an existing sprout worktree considered when the
+// repository is over its max_worktrees limit.
+type WorktreeUsage struct {
+	Path     string
+	Branch   string // Empty for a detached HEAD
+	LastUsed time.Time
+	Dirty    bool
+	Unmerged bool // Has commits not in the default branch
+	Adopted  bool
+	Pinned   bool // Kept until unpinned, whatever its state
+	Locked   bool // Locked with 'git worktree lock', which git refuses to remove
+	Current  bool // The worktree sprout runs in
+}
+
+// Evictable reports whether removing the worktree loses no work, the user
+// hasn't pinned or locked it, and isn't working in it.
+func (u WorktreeUsage) Evictable() bool {
+	return !u.Dirty && !u.Unmerged && !u.Pinned && !u.Locked && !u.Current
+}
+
+func (u WorktreeUsage) label() string {
+	if u.Branch != "" {
+		return u.Branch
+	}
+	return u.Path
+}
+
+// LeastRecentlyUsed returns the worktrees ordered oldest first.
+func LeastRecentlyUsed(usages []WorktreeUsage) []WorktreeUsage {
+	sorted := append([]WorktreeUsage(nil), usages...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].LastUsed.Before(sorted[j].LastUsed)
+	})
+	return sorted
+}
+
+// planWorktreeLimit returns the actions to run once a worktree is created
+// when that makes the repository exceed max_worktrees: a warning listing
+// the least recently used worktrees or, with --evict, removing clean,
+// merged ones (oldest first) until the new worktree fits. Running after
+// 'git worktree add' means an add that fails evicts nothing.
+func planWorktreeLimit(ctx AddContext) []Action {
+	limit := ctx.Config.MaxWorktrees
+	if limit <= 0 || len(ctx.ExistingWorktrees) < limit {
+		return nil
+	}
+	excess := len(ctx.ExistingWorktrees) + 1 - limit
+	lru := LeastRecentlyUsed(ctx.ExistingWorktrees)
+
+	if !ctx.Evict {
+		shown := max(excess, lruCandidatesShown)
+		var b strings.Builder
+		fmt.Fprintf(&b, msgOverWorktreeLimit, len(ctx.ExistingWorktrees), limit)
+		for i, u := range lru {
+			if i == shown {
+				break
+			}
+			fmt.Fprintf(&b, "\n   %s  (last used %s%s)", u.label(), formatLastUsed(u.LastUsed), usageNote(u))
+		}
+		fmt.Fprintf(&b, "\n%s", msgEvictHint)
+		return []Action{PrintError{Msg: b.String()}}
+	}
+
+	var actions []Action
+	for _, u := range lru {
+		if excess == 0 {
+			break
+		}
+		if !u.Evictable() {
+			continue
+		}
+		actions = append(actions,
+			PrintMessage{Msg: fmt.Sprintf(msgEvictedWorktree, u.label(), formatLastUsed(u.LastUsed))},
+			RunGitCommand{Dir: ctx.RepoRoot, Args: buildRemoveWorktreeArgs(u.Path, false)},
+			RemoveScratch{WorktreePath: u.Path},
+			ForgetMetadata{WorktreePath: u.Path},
+		)
+		if u.Adopted {
+			actions = append(actions, ForgetWorktree{Path: u.Path})
+		}
+		excess--
+	}
+	if len(actions) > 0 {
+		actions = append(actions, RunGitCommand{Dir: ctx.RepoRoot, Args: []string{"worktree", "prune"}})
+	}
+	if excess > 0 {
+		actions = append(actions, PrintError{Msg: fmt.Sprintf(msgNothingToEvict, limit)})
+	}
+	return actions
+}
+
+// usageNote explains why a listed worktree can't be evicted automatically.
+func usageNote(u WorktreeUsage) string {
+	switch {
+	case u.Current:
+		return ", current worktree"
+	case u.Pinned:
+		return ", pinned"
+	case u.Locked:
+		return ", locked"
+	case u.Dirty:
+		return ", uncommitted changes"
+	case u.Unmerged:
+		return ", unmerged commits"
+	default:
+		return ""
+	}
+}
+
+func formatLastUsed(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format("2006-01-02")
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func day(d int) time.Time {
+	return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC)
+}
+
+func limitContext(limit int, evict bool, existing ...WorktreeUsage) AddContext {
+	return AddContext{
+		Branch:            "new",
+		RepoRoot:          "/repo",
+		WorktreePath:      "/sprout/new",
+		RemoteBase:        "origin/main",
+		Config:            &config.Config{MaxWorktrees: limit},
+		NoOpen:            true,
+		ExistingWorktrees: existing,
+		Evict:             evict,
+	}
+}
+
+// afterAdd returns the actions planned after 'git worktree add', which is
+// where the worktree limit is enforced.
+func afterAdd(t *testing.T, plan Plan) []Action {
+	t.Helper()
+	for i, action := range plan.Actions {
+		if git, ok := action.(RunGitCommand); ok && git.Args[0] == "worktree" && git.Args[1] == "add" {
+			return plan.Actions[i+1:]
+		}
+	}
+	t.Fatal("plan has no 'git worktree add'")
+	return nil
+}
+
+func TestLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	sorted := LeastRecentlyUsed([]WorktreeUsage{
+		{Branch: "b", LastUsed: day(5)},
+		{Branch: "a", LastUsed: day(1)},
+		{Branch: "c", LastUsed: day(9)},
+	})
+
+	var branches []string
+	for _, u := range sorted {
+		branches = append(branches, u.Branch)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, branches)
+}
+
+func TestPlanAddCommand_WorktreeLimit(t *testing.T) {
+	t.Parallel()
+
+	existing := []WorktreeUsage{
+		{Path: "/sprout/recent", Branch: "recent", LastUsed: day(20)},
+		{Path: "/sprout/dirty", Branch: "dirty", LastUsed: day(2), Dirty: true},
+		{Path: "/sprout/old", Branch: "old", LastUsed: day(5)},
+	}
+
+	t.Run("within the limit", func(t *testing.T) {
+		t.Parallel()
+
+		plan := PlanAddCommand(limitContext(4, false, existing...))
+		assert.Equal(t, PrintMessage{Msg: fmt.Sprintf(msgCreatingWorktree, "new", "/sprout/new")}, plan.Actions[0])
+		for _, action := range afterAdd(t, plan) {
+			assert.NotEqual(t, "worktree", gitArg(action, 0))
+		}
+	})
+
+	t.Run("warns with least recently used candidates", func(t *testing.T) {
+		t.Parallel()
+
+		plan := PlanAddCommand(limitContext(3, false, existing...))
+
+		warning, ok := afterAdd(t, plan)[0].(PrintError)
+		require.True(t, ok)
+		assert.Contains(t, warning.Msg, "has 3 worktrees (max_worktrees: 3)")
+		assert.Contains(t, warning.Msg, "dirty  (last used 2026-03-02, uncommitted changes)\n   old  (last used 2026-03-05)\n   recent")
+		assert.Contains(t, warning.Msg, "--evict")
+	})
+
+	t.Run("evicts the oldest clean, merged worktree", func(t *testing.T) {
+		t.Parallel()
+
+		plan := PlanAddCommand(limitContext(3, true, existing...))
+
+		actions := afterAdd(t, plan)
+		require.GreaterOrEqual(t, len(actions), 5)
+		assert.Equal(t, PrintMessage{Msg: "🧹 Evicting old (last used 2026-03-05)"}, actions[0])
+		assert.Equal(t, RunGitCommand{Dir: "/repo", Args: []string{"worktree", "remove", "/sprout/old"}}, actions[1])
+		assert.Equal(t, RemoveScratch{WorktreePath: "/sprout/old"}, actions[2])
+		assert.Equal(t, ForgetMetadata{WorktreePath: "/sprout/old"}, actions[3])
+		assert.Equal(t, RunGitCommand{Dir: "/repo", Args: []string{"worktree", "prune"}}, actions[4])
+	})
+
+	t.Run("evicts only after the new worktree is added", func(t *testing.T) {
+		t.Parallel()
+
+		plan := PlanAddCommand(limitContext(3, true, existing...))
+
+		for _, action := range plan.Actions[:len(plan.Actions)-len(afterAdd(t, plan))] {
+			assert.NotEqual(t, "remove", gitArg(action, 1))
+		}
+	})
+
+	t.Run("never evicts pinned worktrees", func(t *testing.T) {
+		t.Parallel()
+
+		pinned := WorktreeUsage{Path: "/sprout/pinned", Branch: "pinned", LastUsed: day(1), Pinned: true}
+		plan := PlanAddCommand(limitContext(4, true, append([]WorktreeUsage{pinned}, existing...)...))
+
+		actions := afterAdd(t, plan)
+		assert.Equal(t, PrintMessage{Msg: "🧹 Evicting old (last used 2026-03-05)"}, actions[0])
+		assert.Equal(t, RunGitCommand{Dir: "/repo", Args: []string{"worktree", "remove", "/sprout/old"}}, actions[1])
+	})
+
+	t.Run("never evicts the current or locked worktrees", func(t *testing.T) {
+		t.Parallel()
+
+		current := WorktreeUsage{Path: "/repo", Branch: "main", LastUsed: day(1), Current: true}
+		locked := WorktreeUsage{Path: "/sprout/locked", Branch: "locked", LastUsed: day(1), Locked: true}
+		plan := PlanAddCommand(limitContext(5, true, append([]WorktreeUsage{current, locked}, existing...)...))
+
+		actions := afterAdd(t, plan)
+		assert.Equal(t, PrintMessage{Msg: "🧹 Evicting old (last used 2026-03-05)"}, actions[0])
+		assert.Equal(t, RunGitCommand{Dir: "/repo", Args: []string{"worktree", "remove", "/sprout/old"}}, actions[1])
+	})
+
+	t.Run("forgets evicted adopted worktrees", func(t *testing.T) {
+		t.Parallel()
+
+		plan := PlanAddCommand(limitContext(1, true, WorktreeUsage{Path: "/elsewhere/wt", Branch: "wt", Adopted: true}))
+
+		assert.Equal(t, ForgetWorktree{Path: "/elsewhere/wt"}, afterAdd(t, plan)[4])
+	})
+
+	t.Run("warns when nothing can be evicted", func(t *testing.T) {
+		t.Parallel()
+
+		plan := PlanAddCommand(limitContext(1, true, WorktreeUsage{Path: "/sprout/wip", Branch: "wip", Unmerged: true}))
+
+		assert.Equal(t, PrintError{Msg: "⚠️  Still over max_worktrees (1): no other clean, merged worktrees to evict."}, afterAdd(t, plan)[0])
+		for _, action := range plan.Actions {
+			assert.NotEqual(t, "remove", gitArg(action, 1))
+		}
+	})
+}
+
+// gitArg returns the i-th argument of a git command action, or "" for any
+// other action.
+func gitArg(action Action, i int) string {
+	if git, ok := action.(RunGitCommand); ok && i < len(git.Args) {
+		return git.Args[i]
+	}
+	return ""
+}
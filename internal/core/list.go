@@ -3,68 +3,124 @@ package core
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/style"
 )
 
-// ANSI color codes for terminal output
-const (
-	colorReset   = "\033[0m"
-	colorRed     = "\033[31m"
-	colorYellow  = "\033[33m"
-	colorCyan    = "\033[36m"
-	colorMagenta = "\033[35m"
-	colorGreen   = "\033[32m"
-	colorGray    = "\033[90m"
-)
-
-func colorize(s, color string) string {
-	return color + s + colorReset
-}
-
 // ListContext contains all inputs needed for list formatting.
 // This is the context struct passed from the imperative shell to the pure formatter.
 type ListContext struct {
-	Repos   []RepoDisplay
-	Home    string // User's home directory for path shortening
-	ShowAll bool   // Whether --all flag was used (affects headers and empty message)
+	Repos    []RepoDisplay
+	Home     string // User's home directory for path shortening
+	ShowAll  bool   // Whether --all flag was used (affects headers and empty message)
+	Group    string // Only show repositories in this group (--group)
+	Collapse bool   // Show one summary line per group instead of worktrees (--collapse)
+	Counts   bool   // Show commit counts next to the ahead/behind arrows
+	// Porcelain is the porcelain format to print (--porcelain), or empty
+	// for the human-readable listing
+	Porcelain string
+	// ByWorkspace lists Workspaces instead of Repos (--workspace)
+	ByWorkspace bool
+	Workspaces  []RepoWorkspace
 }
 
 // RepoDisplay holds display data for a repository (pure data, no I/O).
 type RepoDisplay struct {
 	Name      string
 	MainPath  string
+	Group     string // From .sprout.yml, or inferred from the parent directory
 	Worktrees []WorktreeDisplayItem
 }
 
+// RepoGroup returns the group label for a repository: the configured
+// label if set, otherwise the name of the directory that contains it
+// (~/code/work/app → "work").
+func RepoGroup(configured, mainPath string) string {
+	if configured != "" {
+		return configured
+	}
+	parent := filepath.Base(filepath.Dir(mainPath))
+	if parent == "." || parent == string(filepath.Separator) {
+		return ""
+	}
+	return parent
+}
+
+// FilterReposByGroup returns the repositories whose group equals group.
+// An empty group matches everything.
+func FilterReposByGroup(repos []RepoDisplay, group string) []RepoDisplay {
+	if group == "" {
+		return repos
+	}
+	var filtered []RepoDisplay
+	for _, repo := range repos {
+		if repo.Group == group {
+			filtered = append(filtered, repo)
+		}
+	}
+	return filtered
+}
+
 // WorktreeDisplayItem holds display data for a worktree.
 type WorktreeDisplayItem struct {
 	Branch string
 	Path   string
 	Status git.WorktreeStatus
 	IsMain bool
+	Ticket string // Ticket summary ("ABC-123 Fix login"), if created with --ticket
+	Base   string // Ref the branch was created from; only set for list --details
+	Pinned bool   // Listed right after the main worktree (see 'sprout pin')
+	Note   string // From 'sprout note'; only set for list --details
+	Locked bool   // Locked with 'git worktree lock'
+	// Temporary worktrees were created with --ttl; ExpiresIn is the time
+	// left until prune removes them, negative once it has run out
+	Temporary bool
+	ExpiresIn time.Duration
+	// Label and Commit name a detached HEAD worktree in place of a branch;
+	// Label is only set under the sprout root (see DetachedLabel)
+	Label  string
+	Commit string
 }
 
-// BuildStatusEmojis builds a string of status emoji indicators.
+// BuildStatusEmojis builds a string of status emoji indicators. With counts,
+// the ahead/behind arrows carry the number of commits (e.g. ↑3 ↓1).
 // Returns empty string for clean worktrees.
-func BuildStatusEmojis(status git.WorktreeStatus) string {
-	emojis := make([]string, 0, 4)
-	if status.Dirty {
-		emojis = append(emojis, colorize("✗", colorRed)) // Red - urgent
+func BuildStatusEmojis(status git.WorktreeStatus, counts bool) string {
+	icons := style.CurrentIcons()
+	emojis := make([]string, 0, 5)
+	switch {
+	case status.UntrackedOnly:
+		emojis = append(emojis, style.Gray(icons.Untracked)) // Gray - only new files
+	case status.Dirty:
+		emojis = append(emojis, style.Red(icons.Dirty)) // Red - urgent
 	}
 	if status.Ahead > 0 {
-		emojis = append(emojis, colorize("↑", colorYellow)) // Yellow - warning
+		emojis = append(emojis, style.Yellow(arrow(icons.Ahead, status.Ahead, counts))) // Yellow - warning
 	}
 	if status.Behind > 0 {
-		emojis = append(emojis, colorize("↓", colorCyan)) // Cyan - informational
+		emojis = append(emojis, style.Cyan(arrow(icons.Behind, status.Behind, counts))) // Cyan - informational
 	}
 	if status.Unmerged {
-		emojis = append(emojis, colorize("↕", colorMagenta)) // Magenta - special state
+		emojis = append(emojis, style.Magenta(icons.Unmerged)) // Magenta - special state
+	}
+	if status.HasStash {
+		emojis = append(emojis, style.Blue(icons.Stash)) // Blue - stashed work
 	}
 	return strings.Join(emojis, " ")
 }
 
+// arrow returns an ahead/behind arrow, followed by n when counts is set.
+func arrow(symbol string, n int, counts bool) string {
+	if !counts {
+		return symbol
+	}
+	return fmt.Sprintf("%s%d", symbol, n)
+}
+
 // ShortenPathWithHome is the pure version of ShortenPath that takes home as a parameter.
 // This allows testing without depending on the environment.
 func ShortenPathWithHome(path, home string) string {
@@ -93,21 +149,39 @@ type WorktreeDisplay struct {
 	Branch       string
 	Path         string
 	StatusEmojis string
+	Ticket       string
+	Base         string // Shown on the path line when set
+	Note         string // Shown on a third line when set
 	IsMain       bool
+	Pinned       bool
+	Temporary    bool          // Shows ExpiresIn on the branch line
+	ExpiresIn    time.Duration // Negative once expired
+	Label        string        // Shown for a detached HEAD, with Commit
+	Commit       string
 	IsLast       bool
 	UseTreeLines bool
 }
 
 // FormatWorktree formats a single worktree for display.
-// Returns two lines: branch line with optional status, and path line.
+// Returns two lines: branch line with optional status, and path line,
+// followed by a line with the note if there is one.
 func FormatWorktree(display WorktreeDisplay) string {
-	icon := "🌱 "
+	icons := style.CurrentIcons()
+	icon := icons.Sprout + " "
+	if display.Pinned {
+		icon = icons.Pinned + " "
+	}
 	if display.IsMain {
 		icon = ""
 	}
 
 	branch := display.Branch
-	if branch == "" {
+	detachedAt := ""
+	switch {
+	case branch == "" && display.Label != "":
+		branch = display.Label
+		detachedAt = "(detached at " + ShortCommit(display.Commit) + ")"
+	case branch == "":
 		branch = "(detached)"
 	}
 
@@ -124,43 +198,154 @@ func FormatWorktree(display WorktreeDisplay) string {
 		}
 	}
 
-	label := fmt.Sprintf("%s%s%s", branchPrefix, icon, colorize(branch, colorGreen))
+	label := fmt.Sprintf("%s%s%s", branchPrefix, icon, style.Green(branch))
 
 	// Build branch line
 	branchLine := label
+	if detachedAt != "" {
+		branchLine += " " + style.Gray(detachedAt)
+	}
 	if display.StatusEmojis != "" {
 		branchLine += " " + display.StatusEmojis
 	}
+	if display.Ticket != "" {
+		branchLine += " " + style.Gray(display.Ticket)
+	}
+	if display.Temporary {
+		if display.ExpiresIn <= 0 {
+			branchLine += " " + style.Yellow("(expired)")
+		} else {
+			branchLine += " " + style.Gray("(expires in "+FormatTimeLeft(display.ExpiresIn)+")")
+		}
+	}
 
 	// Build path line
 	var pathLine string
 	if pathPrefix != "" {
-		pathLine = pathPrefix + " " + colorize(display.Path, colorGray)
+		pathLine = pathPrefix + " " + style.Gray(display.Path)
 	} else {
-		pathLine = colorize(display.Path, colorGray)
+		pathLine = style.Gray(display.Path)
+	}
+	if display.Base != "" {
+		pathLine += style.Gray(" (from " + display.Base + ")")
+	}
+	if display.Note != "" {
+		note := style.Yellow(icons.Note + " " + display.Note)
+		if pathPrefix != "" {
+			note = pathPrefix + " " + note
+		}
+		pathLine += "\n" + note
 	}
 
 	return branchLine + "\n" + pathLine
 }
 
 // FormatListOutput formats the list command output.
-// Pure function that handles both empty and non-empty cases.
+// Pure function that handles both empty and non-empty cases. Porcelain
+// output is empty without worktrees.
 // This is the single entry point for list formatting from the command layer.
 func FormatListOutput(ctx ListContext) string {
-	if len(ctx.Repos) == 0 {
+	if ctx.ByWorkspace {
+		return FormatWorkspaceList(ctx.Workspaces, ctx.Home, ctx.Counts)
+	}
+	repos := FilterReposByGroup(ctx.Repos, ctx.Group)
+	if ctx.Porcelain != "" {
+		return FormatListPorcelain(repos)
+	}
+	if len(repos) == 0 {
+		if ctx.Group != "" {
+			return fmt.Sprintf("\nNo sprout worktrees found in group '%s'.", ctx.Group)
+		}
 		if ctx.ShowAll {
 			return "\nNo sprout worktrees found."
 		}
 		return "\nNo sprout worktrees found for this repository."
 	}
 
-	return FormatRepoList(ctx.Repos, ctx.Home, ctx.ShowAll)
+	if ctx.ShowAll && (ctx.Collapse || countGroups(repos) > 1) {
+		return FormatGroupedRepoList(repos, ctx.Home, ctx.Collapse, ctx.Counts)
+	}
+
+	return FormatRepoList(repos, ctx.Home, ctx.ShowAll, ctx.Counts)
+}
+
+// FormatGroupedRepoList formats repositories in sections per group, sorted
+// by group name (ungrouped repositories last). With collapse, each section
+// is reduced to its header with repository and worktree counts.
+func FormatGroupedRepoList(repos []RepoDisplay, home string, collapse, counts bool) string {
+	groups := groupRepos(repos)
+
+	var lines []string
+	for i, g := range groups {
+		name := g.name
+		if name == "" {
+			name = "(ungrouped)"
+		}
+
+		if collapse {
+			if i == 0 {
+				lines = append(lines, "")
+			}
+			worktrees := 0
+			for _, repo := range g.repos {
+				worktrees += len(repo.Worktrees) - 1 // main worktree is not a sprout worktree
+			}
+			lines = append(lines, "▸ "+style.Bold(name)+" "+
+				style.Gray(fmt.Sprintf("%d repos, %d worktrees", len(g.repos), worktrees)))
+			continue
+		}
+
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "", "▾ "+style.Heading(name))
+		// FormatRepoList starts with a blank spacer line; drop it inside a section
+		lines = append(lines, strings.TrimPrefix(FormatRepoList(g.repos, home, true, counts), "\n"))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type repoGroup struct {
+	name  string
+	repos []RepoDisplay
+}
+
+// groupRepos buckets repositories by group, preserving their relative order.
+func groupRepos(repos []RepoDisplay) []repoGroup {
+	var groups []repoGroup
+	index := map[string]int{}
+	for _, repo := range repos {
+		i, ok := index[repo.Group]
+		if !ok {
+			i = len(groups)
+			index[repo.Group] = i
+			groups = append(groups, repoGroup{name: repo.Group})
+		}
+		groups[i].repos = append(groups[i].repos, repo)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if (groups[i].name == "") != (groups[j].name == "") {
+			return groups[j].name == ""
+		}
+		return groups[i].name < groups[j].name
+	})
+	return groups
+}
+
+func countGroups(repos []RepoDisplay) int {
+	seen := map[string]bool{}
+	for _, repo := range repos {
+		seen[repo.Group] = true
+	}
+	return len(seen)
 }
 
 // FormatRepoList formats a list of repositories for display.
 // Pure function - takes home dir as parameter instead of calling os.UserHomeDir().
 // Returns empty string if repos is empty.
-func FormatRepoList(repos []RepoDisplay, home string, showHeaders bool) string {
+func FormatRepoList(repos []RepoDisplay, home string, showHeaders, counts bool) string {
 	if len(repos) == 0 {
 		return ""
 	}
@@ -173,16 +358,29 @@ func FormatRepoList(repos []RepoDisplay, home string, showHeaders bool) string {
 			if i > 0 {
 				lines = append(lines, "") // Blank line between repos
 			}
-			lines = append(lines, fmt.Sprintf("\033[1m%s\033[0m", repo.Name))
+			lines = append(lines, style.Bold(repo.Name))
 		}
 
-		for j, wt := range repo.Worktrees {
+		// The main worktree comes first, then pinned worktrees
+		order := PinnedFirst(len(repo.Worktrees), func(i int) bool {
+			return repo.Worktrees[i].IsMain || repo.Worktrees[i].Pinned
+		})
+		for j, i := range order {
+			wt := repo.Worktrees[i]
 			isLast := j == len(repo.Worktrees)-1
 			display := WorktreeDisplay{
 				Branch:       wt.Branch,
 				Path:         ShortenPathWithHome(wt.Path, home),
-				StatusEmojis: BuildStatusEmojis(wt.Status),
+				StatusEmojis: BuildStatusEmojis(wt.Status, counts),
+				Ticket:       wt.Ticket,
+				Base:         wt.Base,
+				Note:         wt.Note,
 				IsMain:       wt.IsMain,
+				Pinned:       wt.Pinned,
+				Temporary:    wt.Temporary,
+				ExpiresIn:    wt.ExpiresIn,
+				Label:        wt.Label,
+				Commit:       wt.Commit,
 				IsLast:       isLast,
 				UseTreeLines: showHeaders,
 			}
@@ -192,3 +390,17 @@ func FormatRepoList(repos []RepoDisplay, home string, showHeaders bool) string {
 
 	return strings.Join(lines, "\n")
 }
+
+// FormatTimeLeft describes the time left until a temporary worktree
+// expires, in the largest whole unit: "45s", "20m", "3h" or "2d".
+func FormatTimeLeft(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+}
@@ -1,13 +1,17 @@
 package core
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/style"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBuildStatusEmojis(t *testing.T) {
@@ -16,6 +20,7 @@ func TestBuildStatusEmojis(t *testing.T) {
 	tests := []struct {
 		name     string
 		status   git.WorktreeStatus
+		counts   bool
 		expected string
 	}{
 		{
@@ -58,13 +63,35 @@ func TestBuildStatusEmojis(t *testing.T) {
 			status:   git.WorktreeStatus{Ahead: 5, Behind: 3},
 			expected: "\033[33m↑\033[0m \033[36m↓\033[0m",
 		},
+		{
+			name:     "untracked files only",
+			status:   git.WorktreeStatus{Dirty: true, UntrackedOnly: true},
+			expected: "\033[90m?\033[0m",
+		},
+		{
+			name:     "stash and behind",
+			status:   git.WorktreeStatus{Behind: 1, HasStash: true},
+			expected: "\033[36m↓\033[0m \033[34m≡\033[0m",
+		},
+		{
+			name:     "ahead and behind with counts",
+			status:   git.WorktreeStatus{Ahead: 5, Behind: 3},
+			counts:   true,
+			expected: "\033[33m↑5\033[0m \033[36m↓3\033[0m",
+		},
+		{
+			name:     "counts leave other indicators bare",
+			status:   git.WorktreeStatus{Dirty: true, Ahead: 1, Unmerged: true},
+			counts:   true,
+			expected: "\033[31m✗\033[0m \033[33m↑1\033[0m \033[35m↕\033[0m",
+		},
 	}
 
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			result := BuildStatusEmojis(tt.status)
+			result := BuildStatusEmojis(tt.status, tt.counts)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -198,14 +225,32 @@ func TestFormatWorktree(t *testing.T) {
 			display: WorktreeDisplay{
 				Branch:       "feature-branch",
 				Path:         "~/sprout/repo/feature",
-				StatusEmojis: colorize("✗", colorRed),
+				StatusEmojis: style.Red("✗"),
 				IsMain:       false,
 				IsLast:       false,
 				UseTreeLines: false,
 			},
-			expectedHas:    []string{"🌱", "feature-branch", colorize("✗", colorRed), "~/sprout/repo/feature"},
+			expectedHas:    []string{"🌱", "feature-branch", style.Red("✗"), "~/sprout/repo/feature"},
 			expectedNotHas: []string{"├──", "└──", "│"},
 		},
+		{
+			name: "worktree with ticket",
+			display: WorktreeDisplay{
+				Branch: "feat/ABC-123-fix-login",
+				Path:   "~/sprout/repo/feat",
+				Ticket: "ABC-123 Fix login",
+			},
+			expectedHas: []string{"feat/ABC-123-fix-login", style.Gray("ABC-123 Fix login")},
+		},
+		{
+			name: "worktree with base (list --details)",
+			display: WorktreeDisplay{
+				Branch: "feature",
+				Path:   "~/sprout/repo/feature",
+				Base:   "origin/develop",
+			},
+			expectedHas: []string{style.Gray("~/sprout/repo/feature") + style.Gray(" (from origin/develop)")},
+		},
 		{
 			name: "main worktree without status",
 			display: WorktreeDisplay{
@@ -264,10 +309,10 @@ func TestFormatWorktree(t *testing.T) {
 				Branch: "busy-branch",
 				Path:   "~/sprout/repo/busy",
 				StatusEmojis: strings.Join([]string{
-					colorize("✗", colorRed),
-					colorize("↑", colorYellow),
-					colorize("↓", colorCyan),
-					colorize("↕", colorMagenta),
+					style.Red("✗"),
+					style.Yellow("↑"),
+					style.Cyan("↓"),
+					style.Magenta("↕"),
 				}, " "),
 				IsMain:       false,
 				IsLast:       false,
@@ -275,10 +320,10 @@ func TestFormatWorktree(t *testing.T) {
 			},
 			expectedHas: []string{
 				"busy-branch",
-				colorize("✗", colorRed),
-				colorize("↑", colorYellow),
-				colorize("↓", colorCyan),
-				colorize("↕", colorMagenta),
+				style.Red("✗"),
+				style.Yellow("↑"),
+				style.Cyan("↓"),
+				style.Magenta("↕"),
 			},
 		},
 	}
@@ -320,3 +365,138 @@ func TestFormatWorktree(t *testing.T) {
 		})
 	}
 }
+
+func TestRepoGroup(t *testing.T) {
+	assert.Equal(t, "work", RepoGroup("", "/home/user/code/work/app"))
+	assert.Equal(t, "clients", RepoGroup("clients", "/home/user/code/work/app"))
+	assert.Equal(t, "", RepoGroup("", "/app"))
+}
+
+func TestFormatListOutput_Groups(t *testing.T) {
+	repo := func(name, group string, worktrees int) RepoDisplay {
+		items := []WorktreeDisplayItem{{Branch: "main", Path: "/code/" + name, IsMain: true}}
+		for i := 0; i < worktrees; i++ {
+			items = append(items, WorktreeDisplayItem{Branch: fmt.Sprintf("feat-%d", i), Path: fmt.Sprintf("/wt/%s/%d", name, i)})
+		}
+		return RepoDisplay{Name: name, MainPath: "/code/" + name, Group: group, Worktrees: items}
+	}
+	repos := []RepoDisplay{
+		repo("api", "work", 2),
+		repo("dotfiles", "", 1),
+		repo("blog", "personal", 1),
+		repo("web", "work", 1),
+	}
+
+	t.Run("sections when repos span groups", func(t *testing.T) {
+		out := FormatListOutput(ListContext{Repos: repos, ShowAll: true})
+
+		personal := strings.Index(out, "personal")
+		work := strings.Index(out, "▾ \033[1;4mwork")
+		ungrouped := strings.Index(out, "(ungrouped)")
+		assert.True(t, personal >= 0 && personal < work && work < ungrouped, out)
+		assert.Contains(t, out, "feat-0")
+	})
+
+	t.Run("single group stays flat", func(t *testing.T) {
+		out := FormatListOutput(ListContext{Repos: repos[:1], ShowAll: true})
+
+		assert.NotContains(t, out, "▾")
+	})
+
+	t.Run("collapse summarizes groups", func(t *testing.T) {
+		out := FormatListOutput(ListContext{Repos: repos, ShowAll: true, Collapse: true})
+
+		assert.Contains(t, out, "work\033[0m \033[90m2 repos, 3 worktrees")
+		assert.NotContains(t, out, "feat-0")
+	})
+
+	t.Run("group filter", func(t *testing.T) {
+		out := FormatListOutput(ListContext{Repos: repos, ShowAll: true, Group: "personal"})
+
+		assert.Contains(t, out, "blog")
+		assert.NotContains(t, out, "api")
+
+		out = FormatListOutput(ListContext{Repos: repos, ShowAll: true, Group: "nope"})
+		assert.Equal(t, "\nNo sprout worktrees found in group 'nope'.", out)
+	})
+}
+
+func TestFormatListOutput_Workspaces(t *testing.T) {
+	workspaces := []RepoWorkspace{{Name: "feature-x", Members: []WsMember{
+		{Repo: "api", MainPath: "/code/api", WorktreePath: "/home/me/wt/api", Status: git.WorktreeStatus{Dirty: true}},
+		{Repo: "infra", MainPath: "/code/infra"},
+	}}}
+
+	out := FormatListOutput(ListContext{ByWorkspace: true, Workspaces: workspaces, Home: "/home/me"})
+	lines := strings.Split(out, "\n")
+	require.Len(t, lines, 4, out)
+	assert.Contains(t, lines[1], "feature-x")
+	assert.Contains(t, lines[2], "├── ")
+	assert.Contains(t, lines[2], "~/wt/api")
+	assert.Contains(t, lines[2], style.Red(style.CurrentIcons().Dirty))
+	assert.Contains(t, lines[3], "└── ")
+	assert.Contains(t, lines[3], "(no worktree)")
+
+	out = FormatListOutput(ListContext{ByWorkspace: true})
+	assert.Equal(t, "\n"+msgNoWorkspaces, out)
+}
+
+func TestFormatWorktree_Note(t *testing.T) {
+	out := FormatWorktree(WorktreeDisplay{
+		Branch:       "feature",
+		Path:         "~/sprout/repo/feature",
+		Note:         "waiting on review",
+		IsLast:       true,
+		UseTreeLines: true,
+	})
+
+	lines := strings.Split(out, "\n")
+	require.Len(t, lines, 3, "the note gets a line of its own")
+	assert.Equal(t, "     "+style.Yellow("📝 waiting on review"), lines[2])
+}
+
+func TestFormatWorktree_Temporary(t *testing.T) {
+	branchLine := func(expiresIn time.Duration) string {
+		out := FormatWorktree(WorktreeDisplay{Branch: "spike", Path: "~/sprout/repo/spike", Temporary: true, ExpiresIn: expiresIn})
+		return style.Strip(strings.Split(out, "\n")[0])
+	}
+
+	assert.Contains(t, branchLine(90*time.Minute), "spike (expires in 1h)")
+	assert.Contains(t, branchLine(-time.Minute), "spike (expired)")
+	assert.NotContains(t, style.Strip(FormatWorktree(WorktreeDisplay{Branch: "feature", Path: "~/sprout/repo/feature"})), "expire")
+}
+
+func TestFormatWorktree_Detached(t *testing.T) {
+	branchLine := func(display WorktreeDisplay) string {
+		return style.Strip(strings.Split(FormatWorktree(display), "\n")[0])
+	}
+
+	assert.Equal(t, "🌱 release (detached at 3f2a9c1)", branchLine(WorktreeDisplay{
+		Path:   "~/sprout/repo/release/repo",
+		Label:  "release",
+		Commit: "3f2a9c1d6b0e4a7c8f9e2d1b0a3c4e5f6a7b8c9d",
+	}))
+	assert.Equal(t, "🌱 (detached)", branchLine(WorktreeDisplay{Path: "~/code/repo-bisect"}), "no label outside the sprout root")
+}
+
+func TestFormatTimeLeft(t *testing.T) {
+	assert.Equal(t, "30s", FormatTimeLeft(30*time.Second))
+	assert.Equal(t, "45m", FormatTimeLeft(45*time.Minute))
+	assert.Equal(t, "3h", FormatTimeLeft(3*time.Hour+20*time.Minute))
+	assert.Equal(t, "2d", FormatTimeLeft(50*time.Hour))
+}
+
+func TestFormatRepoList_PinnedFirst(t *testing.T) {
+	repo := RepoDisplay{Name: "app", MainPath: "/code/app", Worktrees: []WorktreeDisplayItem{
+		{Branch: "main", Path: "/code/app", IsMain: true},
+		{Branch: "feat-a", Path: "/wt/a"},
+		{Branch: "feat-b", Path: "/wt/b", Pinned: true},
+	}}
+
+	out := FormatRepoList([]RepoDisplay{repo}, "", true, false)
+
+	main, pinned, other := strings.Index(out, "main"), strings.Index(out, "feat-b"), strings.Index(out, "feat-a")
+	assert.True(t, main < pinned && pinned < other, out)
+	assert.Contains(t, out, "├── 📌 ")
+	assert.Contains(t, out, "└── 🌱 \033[32mfeat-a", "the last line gets the closing tree line")
+}
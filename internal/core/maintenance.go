@@ -0,0 +1,53 @@
+package core
+
+import "fmt"
+
+// Message constants for the maintenance command
+const (
+	msgMaintainingRepo = "🧰 %s"
+	msgMaintained      = "✨ Maintained %d repo(s)."
+	msgScheduled       = "⏰ Scheduled git maintenance for %d repo(s). Undo with 'git maintenance unregister' in a repository, or 'git maintenance stop'."
+	msgNoRepos         = "No sprout-managed repositories found."
+)
+
+// MaintenanceContext contains all inputs needed to plan the maintenance command.
+type MaintenanceContext struct {
+	Repos    []string // Main worktrees of the sprout-managed repositories
+	GC       bool     // Run git gc instead of git maintenance run (--gc)
+	Schedule bool     // Register the repositories with git's maintenance scheduler (--schedule)
+}
+
+// PlanMaintenanceCommand creates a plan that tidies the object store every
+// worktree of a repository shares. Each repository has its stale worktree
+// entries pruned and git maintenance run on it, or git gc with --gc. With
+// --schedule it is registered for git's background maintenance instead,
+// which 'git maintenance start' sets up with cron, launchd or systemd.
+func PlanMaintenanceCommand(ctx MaintenanceContext) Plan {
+	if len(ctx.Repos) == 0 {
+		return Plan{Actions: []Action{PrintMessage{Msg: msgNoRepos}}}
+	}
+
+	var actions []Action
+	for _, repo := range ctx.Repos {
+		actions = append(actions, PrintMessage{Msg: fmt.Sprintf(msgMaintainingRepo, repo)})
+		switch {
+		case ctx.Schedule:
+			actions = append(actions, RunGitCommand{Dir: repo, Args: []string{"maintenance", "start"}})
+		case ctx.GC:
+			actions = append(actions,
+				RunGitCommand{Dir: repo, Args: []string{"worktree", "prune"}},
+				RunGitCommand{Dir: repo, Args: []string{"gc", "--quiet"}},
+			)
+		default:
+			actions = append(actions,
+				RunGitCommand{Dir: repo, Args: []string{"worktree", "prune"}},
+				RunGitCommand{Dir: repo, Args: []string{"maintenance", "run"}},
+			)
+		}
+	}
+
+	if ctx.Schedule {
+		return Plan{Actions: append(actions, PrintMessage{Msg: fmt.Sprintf(msgScheduled, len(ctx.Repos))})}
+	}
+	return Plan{Actions: append(actions, PrintMessage{Msg: fmt.Sprintf(msgMaintained, len(ctx.Repos))})}
+}
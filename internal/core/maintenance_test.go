@@ -0,0 +1,49 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanMaintenanceCommand(t *testing.T) {
+	t.Parallel()
+
+	t.Run("prunes and runs maintenance in every repo", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanMaintenanceCommand(MaintenanceContext{Repos: []string{"/code/a", "/code/b"}})
+
+		assert.Equal(t, []Action{
+			PrintMessage{Msg: "🧰 /code/a"},
+			RunGitCommand{Dir: "/code/a", Args: []string{"worktree", "prune"}},
+			RunGitCommand{Dir: "/code/a", Args: []string{"maintenance", "run"}},
+			PrintMessage{Msg: "🧰 /code/b"},
+			RunGitCommand{Dir: "/code/b", Args: []string{"worktree", "prune"}},
+			RunGitCommand{Dir: "/code/b", Args: []string{"maintenance", "run"}},
+			PrintMessage{Msg: "✨ Maintained 2 repo(s)."},
+		}, plan.Actions)
+	})
+
+	t.Run("gc", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanMaintenanceCommand(MaintenanceContext{Repos: []string{"/code/a"}, GC: true})
+
+		assert.Equal(t, RunGitCommand{Dir: "/code/a", Args: []string{"gc", "--quiet"}}, plan.Actions[2])
+	})
+
+	t.Run("schedule", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanMaintenanceCommand(MaintenanceContext{Repos: []string{"/code/a"}, Schedule: true})
+
+		assert.Len(t, plan.Actions, 3)
+		assert.Equal(t, RunGitCommand{Dir: "/code/a", Args: []string{"maintenance", "start"}}, plan.Actions[1])
+		assert.Contains(t, plan.Actions[2].(PrintMessage).Msg, "Scheduled git maintenance for 1 repo(s)")
+	})
+
+	t.Run("no repos", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanMaintenanceCommand(MaintenanceContext{})
+
+		assert.Equal(t, []Action{PrintMessage{Msg: "No sprout-managed repositories found."}}, plan.Actions)
+	})
+}
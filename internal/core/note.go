@@ -0,0 +1,77 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// NoteMaxLength is the longest note, in characters. Notes are shown on one
+// line of 'sprout list --details' and in the picker.
+const NoteMaxLength = 100
+
+// Message constants for the note command
+const (
+	msgNoteSet       = "📝 Noted on %s: %s"
+	msgNoteRemoved   = "Removed the note of %s."
+	msgNoNote        = "%s has no note."
+	errNoteNonSprout = "can only add notes to sprout worktrees, not %s"
+	errNoteEmpty     = "the note is empty; pass --clear to remove it"
+	errNoteTooLong   = "the note is %d characters long; keep it to %d"
+)
+
+// NoteContext contains all inputs needed to plan the note command.
+type NoteContext struct {
+	TargetPath string   // Worktree whose note is shown or changed
+	Branch     string   // Its branch, for messages; empty for a detached HEAD
+	SproutRoot string   // Sprout root directory for this repo
+	Adopted    []string // Adopted worktree paths
+	Current    string   // The worktree's note now
+
+	Note  string // New note
+	Set   bool   // Whether a new note was given; otherwise the note is shown
+	Clear bool   // Remove the note (--clear)
+}
+
+// PlanNoteCommand creates a plan that sets, removes or prints the note of
+// the target worktree. Whitespace in a new note is collapsed so it fits on
+// one line.
+func PlanNoteCommand(ctx NoteContext) Plan {
+	label := ctx.Branch
+	if label == "" {
+		label = ctx.TargetPath
+	}
+	if !IsSproutWorktree(ctx.TargetPath, ctx.SproutRoot, ctx.Adopted) {
+		return errorPlan(fmt.Errorf(errNoteNonSprout, ctx.TargetPath))
+	}
+
+	switch {
+	case ctx.Clear:
+		if ctx.Current == "" {
+			return Plan{Actions: []Action{PrintMessage{Msg: fmt.Sprintf(msgNoNote, label)}}}
+		}
+		return Plan{Actions: []Action{
+			SetNote{WorktreePath: ctx.TargetPath},
+			PrintMessage{Msg: fmt.Sprintf(msgNoteRemoved, label)},
+		}}
+
+	case !ctx.Set:
+		if ctx.Current == "" {
+			return Plan{Actions: []Action{PrintError{Msg: fmt.Sprintf(msgNoNote, label)}}}
+		}
+		return Plan{Actions: []Action{PrintMessage{Msg: ctx.Current}}}
+	}
+
+	note := strings.Join(strings.Fields(ctx.Note), " ")
+	if note == "" {
+		return errorPlan(errors.New(errNoteEmpty))
+	}
+	if n := utf8.RuneCountInString(note); n > NoteMaxLength {
+		return errorPlan(fmt.Errorf(errNoteTooLong, n, NoteMaxLength))
+	}
+	return Plan{Actions: []Action{
+		SetNote{WorktreePath: ctx.TargetPath, Note: note},
+		PrintMessage{Msg: fmt.Sprintf(msgNoteSet, label, note)},
+	}}
+}
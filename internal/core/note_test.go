@@ -0,0 +1,71 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanNoteCommand(t *testing.T) {
+	t.Parallel()
+
+	base := NoteContext{TargetPath: "/sprout/repo/feature", Branch: "feature", SproutRoot: "/sprout/repo"}
+
+	t.Run("sets a note on one line", func(t *testing.T) {
+		t.Parallel()
+		ctx := base
+		ctx.Note, ctx.Set = "  waiting on\n review ", true
+
+		plan := PlanNoteCommand(ctx)
+
+		require.Len(t, plan.Actions, 2)
+		assert.Equal(t, SetNote{WorktreePath: "/sprout/repo/feature", Note: "waiting on review"}, plan.Actions[0])
+		assert.Equal(t, PrintMessage{Msg: "📝 Noted on feature: waiting on review"}, plan.Actions[1])
+	})
+
+	t.Run("prints the note", func(t *testing.T) {
+		t.Parallel()
+		ctx := base
+		ctx.Current = "waiting on review"
+
+		assert.Equal(t, Plan{Actions: []Action{PrintMessage{Msg: "waiting on review"}}}, PlanNoteCommand(ctx))
+		assert.Equal(t, Plan{Actions: []Action{PrintError{Msg: "feature has no note."}}}, PlanNoteCommand(base))
+	})
+
+	t.Run("clears the note", func(t *testing.T) {
+		t.Parallel()
+		ctx := base
+		ctx.Current, ctx.Clear = "waiting on review", true
+
+		plan := PlanNoteCommand(ctx)
+
+		require.Len(t, plan.Actions, 2)
+		assert.Equal(t, SetNote{WorktreePath: "/sprout/repo/feature"}, plan.Actions[0])
+	})
+
+	t.Run("rejects empty and long notes", func(t *testing.T) {
+		t.Parallel()
+		for _, note := range []string{" ", strings.Repeat("x", NoteMaxLength+1)} {
+			ctx := base
+			ctx.Note, ctx.Set = note, true
+
+			plan := PlanNoteCommand(ctx)
+
+			require.Len(t, plan.Actions, 2)
+			assert.IsType(t, PrintError{}, plan.Actions[0])
+			assert.Equal(t, Exit{Code: 1}, plan.Actions[1])
+		}
+	})
+
+	t.Run("refuses the main worktree", func(t *testing.T) {
+		t.Parallel()
+		ctx := base
+		ctx.TargetPath, ctx.Note, ctx.Set = "/code/repo", "main", true
+
+		plan := PlanNoteCommand(ctx)
+
+		assert.Contains(t, plan.Actions[0].(PrintError).Msg, "can only add notes to sprout worktrees")
+	})
+}
@@ -1,6 +1,11 @@
 package core
 
 import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
 	"github.com/m44rten1/sprout/internal/config"
 )
 
@@ -18,6 +23,18 @@ type OpenContext struct {
 	Config           *config.Config // Must not be nil
 	IsTrusted        bool
 	NoHooks          bool
+	HooksDenied      bool // Organization policy forbids hooks for this repo
+	NoOpen           bool // Print the path instead of opening the editor (--no-open, open_editor: false)
+
+	// WebURL is set by --web: the branch page on the forge is opened in the
+	// browser instead of the worktree in the editor, and no hooks run.
+	WebURL string
+
+	// File, when set, is opened in the editor (at Line and Column, if set)
+	// instead of just the worktree. It must be an absolute path inside TargetPath.
+	File   string
+	Line   int
+	Column int
 }
 
 // PlanOpenCommand creates a plan for opening a worktree.
@@ -32,7 +49,16 @@ type OpenContext struct {
 //  1. Validate inputs
 //  2. Open editor in target path
 //  3. If hooks configured, trusted, and not disabled: run on_open hooks
+//
+// With WebURL set, the plan only opens that URL in the browser.
 func PlanOpenCommand(ctx OpenContext) Plan {
+	if ctx.WebURL != "" {
+		return Plan{Actions: []Action{
+			PrintMessage{Msg: fmt.Sprintf("🌐 Opening %s", ctx.WebURL)},
+			OpenURL{URL: ctx.WebURL},
+		}}
+	}
+
 	// Validate inputs
 	if ctx.TargetPath == "" {
 		return errorPlan(ErrEmptyTargetPath)
@@ -47,7 +73,7 @@ func PlanOpenCommand(ctx OpenContext) Plan {
 	// Check trust requirements before any actions
 	// This is a security feature: if hooks are configured and enabled,
 	// we fail fast before opening the editor to avoid partial success states
-	shouldRunHooks := ctx.Config.HasOpenHooks() && !ctx.NoHooks
+	shouldRunHooks := ctx.Config.HasOpenHooks() && !ctx.NoHooks && !ctx.HooksDenied
 	if shouldRunHooks {
 		if ctx.MainWorktreePath == "" {
 			return errorPlan(ErrEmptyMainWorktreePath)
@@ -61,7 +87,7 @@ func PlanOpenCommand(ctx OpenContext) Plan {
 					HookType:         HookTypeOnOpen,
 					HookCommands:     ctx.Config.Hooks.OnOpen,
 				},
-				OpenEditor{Path: ctx.TargetPath},
+				openEditorAction(ctx),
 				RunHooks{
 					Type:             HookTypeOnOpen,
 					Commands:         ctx.Config.Hooks.OnOpen,
@@ -75,9 +101,11 @@ func PlanOpenCommand(ctx OpenContext) Plan {
 
 	// Open editor first, then run hooks
 	// This allows user to start browsing code while hooks run
-	actions := []Action{
-		OpenEditor{Path: ctx.TargetPath},
+	var actions []Action
+	if ctx.Config.HasOpenHooks() && !ctx.NoHooks && ctx.HooksDenied {
+		actions = append(actions, PrintMessage{Msg: fmt.Sprintf(MsgHooksDeniedByPolicy, HookTypeOnOpen)})
 	}
+	actions = append(actions, openEditorAction(ctx))
 
 	// Run on_open hooks if configured, trusted, and not disabled
 	if shouldRunHooks {
@@ -92,3 +120,88 @@ func PlanOpenCommand(ctx OpenContext) Plan {
 
 	return Plan{Actions: actions}
 }
+
+// openEditorAction opens the requested file, or else the worktree itself.
+// With NoOpen it prints what would have been opened.
+func openEditorAction(ctx OpenContext) Action {
+	if ctx.NoOpen {
+		if ctx.File == "" {
+			return PrintMessage{Msg: ctx.TargetPath}
+		}
+		return PrintMessage{Msg: FormatFileLocation(ctx.File, ctx.Line, ctx.Column)}
+	}
+	if ctx.File == "" {
+		return OpenEditor{Path: ctx.TargetPath}
+	}
+	return OpenFile{WorktreePath: ctx.TargetPath, File: ctx.File, Line: ctx.Line, Column: ctx.Column}
+}
+
+// ParseFileLocation splits "path/to/file.go:42:7" (as printed in stack
+// traces and compiler errors) into the file, line and column. Line and
+// column are 0 when absent; anything after the file that is not a number
+// is kept as part of the path.
+func ParseFileLocation(arg string) (file string, line, column int) {
+	file = arg
+	var numbers []int
+	for len(numbers) < 2 {
+		i := strings.LastIndexByte(file, ':')
+		if i <= 0 {
+			break
+		}
+		n, err := strconv.Atoi(file[i+1:])
+		if err != nil || n <= 0 {
+			break
+		}
+		numbers = append(numbers, n)
+		file = file[:i]
+	}
+
+	switch len(numbers) {
+	case 1:
+		return file, numbers[0], 0
+	case 2:
+		return file, numbers[1], numbers[0]
+	default:
+		return file, 0, 0
+	}
+}
+
+// FormatFileLocation renders a file position the way ParseFileLocation reads it.
+func FormatFileLocation(file string, line, column int) string {
+	switch {
+	case line <= 0:
+		return file
+	case column <= 0:
+		return fmt.Sprintf("%s:%d", file, line)
+	default:
+		return fmt.Sprintf("%s:%d:%d", file, line, column)
+	}
+}
+
+// RelocateFile returns the path of file inside target. Relative paths are
+// taken relative to target. Absolute paths inside another worktree of the
+// repository (say, from a stack trace printed in the main checkout) are
+// mapped to the same relative path in target; other absolute paths are
+// returned unchanged.
+func RelocateFile(file, target string, worktrees []string) string {
+	if !filepath.IsAbs(file) {
+		return filepath.Join(target, file)
+	}
+
+	// Prefer the deepest match, since worktrees may be nested in one another
+	best := ""
+	for _, wt := range worktrees {
+		rel, err := filepath.Rel(wt, file)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(wt) > len(best) {
+			best = wt
+		}
+	}
+	if best == "" {
+		return file
+	}
+	rel, _ := filepath.Rel(best, file)
+	return filepath.Join(target, rel)
+}
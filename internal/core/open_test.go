@@ -163,6 +163,32 @@ func TestPlanOpenCommand(t *testing.T) {
 				assert.Equal(t, "/test/repo/.sprout/feature", openEditor.Path)
 			},
 		},
+		{
+			name: "open with hooks denied by policy",
+			ctx: OpenContext{
+				TargetPath:       "/test/repo/.sprout/feature",
+				RepoRoot:         "/test/repo",
+				MainWorktreePath: "/test/repo",
+				Config: &config.Config{
+					Hooks: config.HooksConfig{
+						OnOpen: []string{"echo 'opening'"},
+					},
+				},
+				HooksDenied: true,
+			},
+			wantActions: 2,
+			checkActions: func(t *testing.T, actions []Action) {
+				require.Len(t, actions, 2)
+
+				msg, ok := actions[0].(PrintMessage)
+				require.True(t, ok, "first action should explain the skipped hooks")
+				assert.Contains(t, msg.Msg, "on_open")
+				assert.Contains(t, msg.Msg, "policy")
+
+				_, ok = actions[1].(OpenEditor)
+				require.True(t, ok, "editor still opens")
+			},
+		},
 		{
 			name: "open untrusted with --no-hooks flag",
 			ctx: OpenContext{
@@ -258,3 +284,89 @@ func TestPlanOpenCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestPlanOpenCommand_Web(t *testing.T) {
+	plan := PlanOpenCommand(OpenContext{
+		RepoRoot: "/repo",
+		Config: &config.Config{Hooks: config.HooksConfig{
+			OnOpen: []string{"npm run dev"},
+		}},
+		WebURL: "https://github.com/acme/app/tree/feat",
+	})
+
+	require.Len(t, plan.Actions, 2)
+	assert.Equal(t, PrintMessage{Msg: "🌐 Opening https://github.com/acme/app/tree/feat"}, plan.Actions[0])
+	assert.Equal(t, OpenURL{URL: "https://github.com/acme/app/tree/feat"}, plan.Actions[1])
+}
+
+func TestPlanOpenCommand_File(t *testing.T) {
+	plan := PlanOpenCommand(OpenContext{
+		TargetPath:       "/sprout/feat",
+		RepoRoot:         "/repo",
+		MainWorktreePath: "/repo",
+		Config: &config.Config{Hooks: config.HooksConfig{
+			OnOpen: []string{"npm run dev"},
+		}},
+		IsTrusted: true,
+		File:      "/sprout/feat/src/app.ts",
+		Line:      42,
+	})
+
+	require.Len(t, plan.Actions, 2)
+	assert.Equal(t, OpenFile{WorktreePath: "/sprout/feat", File: "/sprout/feat/src/app.ts", Line: 42}, plan.Actions[0])
+	assert.IsType(t, RunHooks{}, plan.Actions[1])
+}
+
+func TestPlanOpenCommand_NoOpen(t *testing.T) {
+	plan := PlanOpenCommand(OpenContext{
+		TargetPath:       "/sprout/feat",
+		RepoRoot:         "/repo",
+		MainWorktreePath: "/repo",
+		Config: &config.Config{Hooks: config.HooksConfig{
+			OnOpen: []string{"npm run dev"},
+		}},
+		IsTrusted: true,
+		NoOpen:    true,
+	})
+
+	require.Len(t, plan.Actions, 2)
+	assert.Equal(t, PrintMessage{Msg: "/sprout/feat"}, plan.Actions[0])
+	assert.IsType(t, RunHooks{}, plan.Actions[1], "hooks still run")
+}
+
+func TestParseFileLocation(t *testing.T) {
+	tests := []struct {
+		arg          string
+		file         string
+		line, column int
+	}{
+		{"main.go", "main.go", 0, 0},
+		{"main.go:42", "main.go", 42, 0},
+		{"internal/api/server.go:42:7", "internal/api/server.go", 42, 7},
+		{"/abs/main.go:12:", "/abs/main.go:12:", 0, 0},
+		{"C:\\src\\main.go:3", "C:\\src\\main.go", 3, 0},
+		{"weird:name.go", "weird:name.go", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			file, line, column := ParseFileLocation(tt.arg)
+			assert.Equal(t, tt.file, file)
+			assert.Equal(t, tt.line, line)
+			assert.Equal(t, tt.column, column)
+			if tt.line > 0 {
+				assert.Equal(t, tt.arg, FormatFileLocation(file, line, column))
+			}
+		})
+	}
+}
+
+func TestRelocateFile(t *testing.T) {
+	worktrees := []string{"/repo", "/repo/.worktrees/feat", "/sprout/fix"}
+
+	assert.Equal(t, "/sprout/fix/src/app.ts", RelocateFile("src/app.ts", "/sprout/fix", worktrees))
+	assert.Equal(t, "/sprout/fix/src/app.ts", RelocateFile("/repo/src/app.ts", "/sprout/fix", worktrees))
+	assert.Equal(t, "/sprout/fix/lib/x.go", RelocateFile("/repo/.worktrees/feat/lib/x.go", "/sprout/fix", worktrees))
+	assert.Equal(t, "/etc/hosts", RelocateFile("/etc/hosts", "/sprout/fix", worktrees))
+	assert.Equal(t, "/repository/a.go", RelocateFile("/repository/a.go", "/sprout/fix", worktrees))
+}
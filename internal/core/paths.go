@@ -0,0 +1,48 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SproutPath is a directory sprout keeps its files in.
+type SproutPath struct {
+	Name  string // Such as "data", which 'sprout paths data' prints the path of
+	Path  string
+	Holds string // What sprout keeps there
+}
+
+// PathsContext contains all inputs needed to plan the paths command.
+type PathsContext struct {
+	Paths []SproutPath
+	Name  string // Print only the path with this name
+}
+
+// PlanPathsCommand creates a plan that prints where sprout keeps its
+// files, one directory per line, or only the path named by ctx.Name.
+func PlanPathsCommand(ctx PathsContext) Plan {
+	names := make([]string, len(ctx.Paths))
+	for i, p := range ctx.Paths {
+		names[i] = p.Name
+	}
+
+	if ctx.Name != "" {
+		for _, p := range ctx.Paths {
+			if p.Name == ctx.Name {
+				return Plan{Actions: []Action{PrintMessage{Msg: p.Path}}}
+			}
+		}
+		return errorPlan(fmt.Errorf("no %s path; sprout has %s", ctx.Name, strings.Join(names, ", ")))
+	}
+
+	nameWidth, pathWidth := 0, 0
+	for _, p := range ctx.Paths {
+		nameWidth = max(nameWidth, len(p.Name))
+		pathWidth = max(pathWidth, len(p.Path))
+	}
+	lines := make([]string, len(ctx.Paths))
+	for i, p := range ctx.Paths {
+		lines[i] = fmt.Sprintf("%-*s  %-*s  %s", nameWidth, p.Name, pathWidth, p.Path, p.Holds)
+	}
+	return Plan{Actions: []Action{PrintMessage{Msg: strings.Join(lines, "\n")}}}
+}
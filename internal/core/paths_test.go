@@ -0,0 +1,35 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanPathsCommand(t *testing.T) {
+	t.Parallel()
+	paths := []SproutPath{
+		{Name: "config", Path: "/home/me/.config/sprout", Holds: "config.yml"},
+		{Name: "data", Path: "/data/sprout", Holds: "worktrees"},
+	}
+
+	t.Run("all paths", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanPathsCommand(PathsContext{Paths: paths})
+		assert.Equal(t, []Action{PrintMessage{Msg: "" +
+			"config  /home/me/.config/sprout  config.yml\n" +
+			"data    /data/sprout             worktrees"}}, plan.Actions)
+	})
+
+	t.Run("one path", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanPathsCommand(PathsContext{Paths: paths, Name: "data"})
+		assert.Equal(t, []Action{PrintMessage{Msg: "/data/sprout"}}, plan.Actions)
+	})
+
+	t.Run("unknown name", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanPathsCommand(PathsContext{Paths: paths, Name: "logs"})
+		assert.Equal(t, []Action{PrintError{Msg: "no logs path; sprout has config, data"}, Exit{Code: 1}}, plan.Actions)
+	})
+}
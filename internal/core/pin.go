@@ -0,0 +1,71 @@
+package core
+
+import "fmt"
+
+// Message constants for the pin and unpin commands
+const (
+	msgPinned         = "📌 Pinned %s. It's listed first and never removed by 'remove --all-merged' or 'add --evict'."
+	msgUnpinned       = "Unpinned %s."
+	msgAlreadyPinned  = "%s is already pinned."
+	msgNotPinned      = "%s isn't pinned."
+	errPinNonSprout   = "can only pin sprout worktrees, not %s"
+	errUnpinNonSprout = "can only unpin sprout worktrees, not %s"
+)
+
+// PinContext contains all inputs needed to plan the pin and unpin commands.
+type PinContext struct {
+	TargetPath string   // Worktree to pin or unpin
+	Branch     string   // Its branch, for messages; empty for a detached HEAD
+	SproutRoot string   // Sprout root directory for this repo
+	Adopted    []string // Adopted worktree paths
+	Pinned     bool     // Whether the worktree is pinned now
+}
+
+// PlanPinCommand creates a plan that pins the target worktree, or unpins it
+// when pin is false. Only sprout worktrees can be pinned: the main worktree
+// is always listed first and never removed anyway.
+func PlanPinCommand(ctx PinContext, pin bool) Plan {
+	label := ctx.Branch
+	if label == "" {
+		label = ctx.TargetPath
+	}
+	if !IsSproutWorktree(ctx.TargetPath, ctx.SproutRoot, ctx.Adopted) {
+		if pin {
+			return errorPlan(fmt.Errorf(errPinNonSprout, ctx.TargetPath))
+		}
+		return errorPlan(fmt.Errorf(errUnpinNonSprout, ctx.TargetPath))
+	}
+
+	switch {
+	case pin && ctx.Pinned:
+		return Plan{Actions: []Action{PrintMessage{Msg: fmt.Sprintf(msgAlreadyPinned, label)}}}
+	case !pin && !ctx.Pinned:
+		return Plan{Actions: []Action{PrintMessage{Msg: fmt.Sprintf(msgNotPinned, label)}}}
+	}
+
+	msg := fmt.Sprintf(msgUnpinned, label)
+	if pin {
+		msg = fmt.Sprintf(msgPinned, label)
+	}
+	return Plan{Actions: []Action{
+		SetPinned{WorktreePath: ctx.TargetPath, Pinned: pin},
+		PrintMessage{Msg: msg},
+	}}
+}
+
+// PinnedFirst returns the indices of n items with the pinned ones first,
+// each part in its original order.
+func PinnedFirst(n int, pinned func(i int) bool) []int {
+	order := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		if pinned(i) {
+			order = append(order, i)
+		}
+	}
+	for i := 0; i < n; i++ {
+		if !pinned(i) {
+			order = append(order, i)
+		}
+	}
+	return order
+}
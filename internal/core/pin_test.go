@@ -0,0 +1,65 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanPinCommand(t *testing.T) {
+	t.Parallel()
+
+	ctx := PinContext{TargetPath: "/sprout/repo/feature", Branch: "feature", SproutRoot: "/sprout/repo"}
+
+	t.Run("pins", func(t *testing.T) {
+		t.Parallel()
+
+		plan := PlanPinCommand(ctx, true)
+
+		require.Len(t, plan.Actions, 2)
+		assert.Equal(t, SetPinned{WorktreePath: "/sprout/repo/feature", Pinned: true}, plan.Actions[0])
+		assert.Contains(t, plan.Actions[1].(PrintMessage).Msg, "Pinned feature")
+	})
+
+	t.Run("unpins", func(t *testing.T) {
+		t.Parallel()
+		pinned := ctx
+		pinned.Pinned = true
+
+		plan := PlanPinCommand(pinned, false)
+
+		require.Len(t, plan.Actions, 2)
+		assert.Equal(t, SetPinned{WorktreePath: "/sprout/repo/feature", Pinned: false}, plan.Actions[0])
+		assert.Equal(t, PrintMessage{Msg: "Unpinned feature."}, plan.Actions[1])
+	})
+
+	t.Run("nothing to do", func(t *testing.T) {
+		t.Parallel()
+		pinned := ctx
+		pinned.Pinned = true
+
+		assert.Equal(t, Plan{Actions: []Action{PrintMessage{Msg: "feature is already pinned."}}}, PlanPinCommand(pinned, true))
+		assert.Equal(t, Plan{Actions: []Action{PrintMessage{Msg: "feature isn't pinned."}}}, PlanPinCommand(ctx, false))
+	})
+
+	t.Run("refuses the main worktree", func(t *testing.T) {
+		t.Parallel()
+		main := ctx
+		main.TargetPath = "/code/repo"
+
+		plan := PlanPinCommand(main, true)
+
+		require.Len(t, plan.Actions, 2)
+		assert.Contains(t, plan.Actions[0].(PrintError).Msg, "can only pin sprout worktrees")
+		assert.Equal(t, Exit{Code: 1}, plan.Actions[1])
+	})
+}
+
+func TestPinnedFirst(t *testing.T) {
+	t.Parallel()
+
+	pinned := []bool{false, true, false, true}
+	assert.Equal(t, []int{1, 3, 0, 2}, PinnedFirst(len(pinned), func(i int) bool { return pinned[i] }))
+	assert.Empty(t, PinnedFirst(0, nil))
+}
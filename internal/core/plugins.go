@@ -0,0 +1,37 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/m44rten1/sprout/internal/style"
+)
+
+// Plugin is an executable named sprout-<name> on PATH, run for 'sprout <name>'.
+type Plugin struct {
+	Name     string
+	Path     string
+	Shadowed bool // A built-in command has the same name, so it never runs
+}
+
+// PluginsContext contains all inputs needed to plan the plugins command.
+type PluginsContext struct {
+	Plugins []Plugin // In PATH order
+}
+
+// PlanPluginsCommand creates a plan that lists the plugins sprout found.
+func PlanPluginsCommand(ctx PluginsContext) Plan {
+	if len(ctx.Plugins) == 0 {
+		return Plan{Actions: []Action{PrintMessage{Msg: "No plugins found. Put an executable named sprout-<name> on your PATH to add 'sprout <name>'."}}}
+	}
+
+	var b strings.Builder
+	for _, p := range ctx.Plugins {
+		fmt.Fprintf(&b, "%s %s", style.PadRight(p.Name, 20), p.Path)
+		if p.Shadowed {
+			b.WriteString("  (shadowed by the built-in command)")
+		}
+		b.WriteString("\n")
+	}
+	return Plan{Actions: []Action{PrintMessage{Msg: strings.TrimSuffix(b.String(), "\n")}}}
+}
@@ -0,0 +1,30 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanPluginsCommand(t *testing.T) {
+	t.Run("lists plugins and marks shadowed ones", func(t *testing.T) {
+		plan := core.PlanPluginsCommand(core.PluginsContext{Plugins: []core.Plugin{
+			{Name: "deploy", Path: "/usr/local/bin/sprout-deploy"},
+			{Name: "list", Path: "/usr/local/bin/sprout-list", Shadowed: true},
+		}})
+
+		require.Len(t, plan.Actions, 1)
+		msg := plan.Actions[0].(core.PrintMessage).Msg
+		assert.Contains(t, msg, "deploy               /usr/local/bin/sprout-deploy\n")
+		assert.Contains(t, msg, "/usr/local/bin/sprout-list  (shadowed by the built-in command)")
+	})
+
+	t.Run("explains how to add one when there are none", func(t *testing.T) {
+		plan := core.PlanPluginsCommand(core.PluginsContext{})
+
+		require.Len(t, plan.Actions, 1)
+		assert.Contains(t, plan.Actions[0].(core.PrintMessage).Msg, "sprout-<name>")
+	})
+}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PorcelainV1 is the first porcelain format. Like git's porcelain output it
+// is meant for scripts: v1 output never changes, so a script written
+// against it keeps working. New fields will come in a new version.
+const PorcelainV1 = "v1"
+
+// ParsePorcelain validates the value of a --porcelain flag.
+func ParsePorcelain(value string) (string, error) {
+	if value != PorcelainV1 {
+		return "", fmt.Errorf("unsupported porcelain format %q (supported: %s)", value, PorcelainV1)
+	}
+	return value, nil
+}
+
+// FormatListPorcelain formats worktrees as porcelain v1: one line per
+// worktree in the order git lists them, with these tab-separated fields:
+//
+//	repository  main worktree of the repository
+//	path        the worktree
+//	branch      empty for a detached HEAD
+//	kind        "main" or "sprout"
+//	dirty       1 with uncommitted changes, else 0 (as are the flags below)
+//	untracked   1 when the only changes are untracked files
+//	ahead       commits not pushed
+//	behind      commits not pulled
+//	unmerged    1 with commits not in the base branch
+//	stash       1 when the stash has entries for the branch
+//	pinned      1 when pinned with 'sprout pin'
+//
+// No field contains a tab or newline; see escapePorcelain. There are no
+// headers, and no output without worktrees.
+func FormatListPorcelain(repos []RepoDisplay) string {
+	var lines []string
+	for _, repo := range repos {
+		for _, wt := range repo.Worktrees {
+			kind := "sprout"
+			if wt.IsMain {
+				kind = "main"
+			}
+			lines = append(lines, porcelainLine(
+				repo.MainPath,
+				wt.Path,
+				wt.Branch,
+				kind,
+				porcelainFlag(wt.Status.Dirty),
+				porcelainFlag(wt.Status.UntrackedOnly),
+				strconv.Itoa(wt.Status.Ahead),
+				strconv.Itoa(wt.Status.Behind),
+				porcelainFlag(wt.Status.Unmerged),
+				porcelainFlag(wt.Status.HasStash),
+				porcelainFlag(wt.Pinned),
+			))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatInfoPorcelain formats worktree details as porcelain v1: one
+// "key<TAB>value" line for each of repository, worktree, branch, base,
+// index, port_base, port_count and ticket, in that order. Every key is
+// always present; values that aren't known are empty.
+func FormatInfoPorcelain(ctx InfoContext) string {
+	return strings.Join([]string{
+		porcelainLine("repository", ctx.MainWorktreePath),
+		porcelainLine("worktree", ctx.WorktreePath),
+		porcelainLine("branch", ctx.Branch),
+		porcelainLine("base", ctx.Base),
+		porcelainLine("index", strconv.Itoa(ctx.Index)),
+		porcelainLine("port_base", strconv.Itoa(ctx.PortBase)),
+		porcelainLine("port_count", strconv.Itoa(ctx.PortCount)),
+		porcelainLine("ticket", ctx.Ticket.ID),
+	}, "\n")
+}
+
+// FormatAddPorcelain formats the worktree of a successful add as porcelain
+// v1: a single line with these tab-separated fields:
+//
+//	repository  main worktree of the repository
+//	path        the worktree
+//	branch      empty for a detached HEAD
+//	created     1 when this add created it, 0 when it existed already
+func FormatAddPorcelain(ctx AddContext) string {
+	branch := ctx.Branch
+	if ctx.Detach != "" {
+		branch = ""
+	}
+	return porcelainLine(ctx.MainWorktreePath, ctx.WorktreePath, branch, porcelainFlag(!ctx.WorktreeExists))
+}
+
+// RemoveOutcome is what a remove did with one worktree.
+type RemoveOutcome struct {
+	Path    string
+	Branch  string
+	Removed bool
+	Reason  string // Why it was kept, if it was (e.g. "uncommitted changes")
+}
+
+// FormatRemovePorcelain formats the outcome of a successful remove as
+// porcelain v1: one line per worktree it considered, with these
+// tab-separated fields:
+//
+//	path     the worktree
+//	branch   empty for a detached HEAD
+//	removed  1 when removed, 0 when kept
+//	reason   why it was kept, else empty
+func FormatRemovePorcelain(outcomes []RemoveOutcome) string {
+	lines := make([]string, len(outcomes))
+	for i, o := range outcomes {
+		lines[i] = porcelainLine(o.Path, o.Branch, porcelainFlag(o.Removed), o.Reason)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func porcelainLine(fields ...string) string {
+	for i, f := range fields {
+		fields[i] = escapePorcelain(f)
+	}
+	return strings.Join(fields, "\t")
+}
+
+// escapePorcelain writes a backslash, tab, newline or carriage return in a
+// field as \\, \t, \n or \r, so fields and lines can be split reliably.
+func escapePorcelain(s string) string {
+	if !strings.ContainsAny(s, "\\\t\n\r") {
+		return s
+	}
+	return strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`).Replace(s)
+}
+
+func porcelainFlag(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/tickets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePorcelain(t *testing.T) {
+	format, err := ParsePorcelain("v1")
+	require.NoError(t, err)
+	assert.Equal(t, PorcelainV1, format)
+
+	_, err = ParsePorcelain("v2")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported porcelain format "v2"`)
+}
+
+func TestFormatListPorcelain(t *testing.T) {
+	repos := []RepoDisplay{{
+		Name:     "app",
+		MainPath: "/code/app",
+		Worktrees: []WorktreeDisplayItem{
+			{Branch: "main", Path: "/code/app", IsMain: true},
+			{
+				Branch: "feature",
+				Path:   "/sprout/app/feature/app",
+				Status: git.WorktreeStatus{Dirty: true, Ahead: 3, Behind: 1, Unmerged: true},
+				Pinned: true,
+				Note:   "not part of v1",
+			},
+			{Path: "/sprout/app/detached/app", Status: git.WorktreeStatus{Dirty: true, UntrackedOnly: true, HasStash: true}},
+		},
+	}}
+
+	assert.Equal(t,
+		"/code/app\t/code/app\tmain\tmain\t0\t0\t0\t0\t0\t0\t0\n"+
+			"/code/app\t/sprout/app/feature/app\tfeature\tsprout\t1\t0\t3\t1\t1\t0\t1\n"+
+			"/code/app\t/sprout/app/detached/app\t\tsprout\t1\t1\t0\t0\t0\t1\t0",
+		FormatListPorcelain(repos))
+}
+
+func TestFormatListPorcelain_Empty(t *testing.T) {
+	assert.Equal(t, "", FormatListPorcelain(nil))
+	assert.Equal(t, "", FormatListOutput(ListContext{Porcelain: PorcelainV1}))
+}
+
+func TestFormatListOutput_PorcelainFiltersGroup(t *testing.T) {
+	ctx := ListContext{
+		Porcelain: PorcelainV1,
+		Group:     "work",
+		Repos: []RepoDisplay{
+			{Name: "a", MainPath: "/work/a", Group: "work", Worktrees: []WorktreeDisplayItem{{Branch: "main", Path: "/work/a", IsMain: true}}},
+			{Name: "b", MainPath: "/oss/b", Group: "oss", Worktrees: []WorktreeDisplayItem{{Branch: "main", Path: "/oss/b", IsMain: true}}},
+		},
+	}
+
+	assert.Equal(t, "/work/a\t/work/a\tmain\tmain\t0\t0\t0\t0\t0\t0\t0", FormatListOutput(ctx))
+}
+
+func TestFormatInfoPorcelain(t *testing.T) {
+	out := FormatInfoPorcelain(InfoContext{
+		MainWorktreePath: "/repo",
+		WorktreePath:     "/sprout/repo-1234/feature/repo",
+		Branch:           "feature",
+		Base:             "origin/main",
+		Index:            2,
+		PortBase:         3020,
+		PortCount:        10,
+		Ticket:           tickets.Ticket{ID: "ABC-123", Title: "Fix login"},
+	})
+
+	assert.Equal(t, "repository\t/repo\n"+
+		"worktree\t/sprout/repo-1234/feature/repo\n"+
+		"branch\tfeature\n"+
+		"base\torigin/main\n"+
+		"index\t2\n"+
+		"port_base\t3020\n"+
+		"port_count\t10\n"+
+		"ticket\tABC-123", out)
+}
+
+func TestPlanInfoCommand_Porcelain(t *testing.T) {
+	plan := PlanInfoCommand(InfoContext{MainWorktreePath: "/repo", WorktreePath: "/repo", Porcelain: PorcelainV1})
+
+	require.Len(t, plan.Actions, 1)
+	msg, ok := plan.Actions[0].(PrintMessage)
+	require.True(t, ok)
+	assert.Contains(t, msg.Msg, "branch\t\n")
+	assert.Contains(t, msg.Msg, "ticket\t")
+	assert.NotContains(t, msg.Msg, "(detached)")
+}
+
+func TestFormatAddPorcelain(t *testing.T) {
+	ctx := AddContext{MainWorktreePath: "/repo", WorktreePath: "/sprout/repo/feature/repo", Branch: "feature"}
+	assert.Equal(t, "/repo\t/sprout/repo/feature/repo\tfeature\t1", FormatAddPorcelain(ctx))
+
+	ctx.WorktreeExists = true
+	assert.Equal(t, "/repo\t/sprout/repo/feature/repo\tfeature\t0", FormatAddPorcelain(ctx))
+
+	detached := AddContext{MainWorktreePath: "/repo", WorktreePath: "/sprout/repo/v1/repo", Branch: "v1", Detach: "3f2a9c1"}
+	assert.Equal(t, "/repo\t/sprout/repo/v1/repo\t\t1", FormatAddPorcelain(detached))
+}
+
+func TestFormatRemovePorcelain(t *testing.T) {
+	ctx := RemoveContext{
+		TargetPath: "/sprout/repo/feature/repo",
+		Worktrees: []git.Worktree{
+			{Path: "/repo", Branch: "main"},
+			{Path: "/sprout/repo/feature/repo", Branch: "feature"},
+		},
+	}
+	assert.Equal(t, "/sprout/repo/feature/repo\tfeature\t1\t", FormatRemovePorcelain([]RemoveOutcome{ctx.Outcome()}))
+
+	merged := RemoveMergedContext{
+		RepoRoot:    "/repo",
+		CurrentPath: "/sprout/repo/here/repo",
+		Worktrees: []WorktreeUsage{
+			{Path: "/sprout/repo/done/repo", Branch: "done"},
+			{Path: "/sprout/repo/wip/repo", Branch: "wip", Dirty: true},
+			{Path: "/sprout/repo/here/repo", Branch: "here"},
+		},
+	}
+	assert.Equal(t, "/sprout/repo/done/repo\tdone\t1\t\n"+
+		"/sprout/repo/wip/repo\twip\t0\tuncommitted changes\n"+
+		"/sprout/repo/here/repo\there\t0\tcurrent worktree", FormatRemovePorcelain(merged.Outcomes()))
+}
+
+func TestEscapePorcelain(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"/plain/path", "/plain/path"},
+		{"a\tb", `a\tb`},
+		{"a\nb\r", `a\nb\r`},
+		{`C:\dir`, `C:\\dir`},
+		{"\\t", `\\t`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, escapePorcelain(tt.in), tt.in)
+	}
+}
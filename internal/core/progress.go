@@ -0,0 +1,70 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/m44rten1/sprout/internal/config"
+)
+
+// MinProgressSteps is the number of slow actions a plan needs before the
+// executor reports step progress. Quick plans are not worth the noise.
+const MinProgressSteps = 2
+
+// ProgressLabel returns a short description of a slow action for step
+// progress ("Running git worktree add"). The second result is false for
+// actions that complete instantly and are not counted as steps.
+func ProgressLabel(action Action) (string, bool) {
+	switch a := action.(type) {
+	case RunGitCommand:
+		return "Running git " + gitSubcommand(a.Args), true
+	case RunHooks:
+		return fmt.Sprintf("Running %s hooks", a.Type), true
+	case ShareDirectory:
+		// Symlinks are instant; clones walk the whole tree
+		if a.Mode == config.ShareClone {
+			return "Cloning " + filepath.Base(a.Src), true
+		}
+		return "", false
+	case CloneDirectory:
+		return "Cloning " + filepath.Base(a.Src), true
+	case Parallel:
+		// A parallel group is a single step; describe its slow members together
+		var labels []string
+		for _, member := range a.Actions {
+			if label, ok := ProgressLabel(member); ok {
+				labels = append(labels, label)
+			}
+		}
+		return strings.Join(labels, " + "), len(labels) > 0
+	default:
+		return "", false
+	}
+}
+
+// CountProgressSteps returns how many actions in the plan are slow steps.
+func CountProgressSteps(plan Plan) int {
+	steps := 0
+	for _, action := range plan.Actions {
+		if _, ok := ProgressLabel(action); ok {
+			steps++
+		}
+	}
+	return steps
+}
+
+// gitSubcommand returns the git command being run, including the
+// subcommand for commands that have one ("worktree add", not "worktree").
+func gitSubcommand(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	switch args[0] {
+	case "worktree", "remote", "stash":
+		if len(args) > 1 && !strings.HasPrefix(args[1], "-") {
+			return args[0] + " " + args[1]
+		}
+	}
+	return args[0]
+}
@@ -0,0 +1,57 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressLabel(t *testing.T) {
+	tests := []struct {
+		name   string
+		action core.Action
+		label  string
+		slow   bool
+	}{
+		{"git with subcommand", core.RunGitCommand{Args: []string{"worktree", "add", "/wt", "feat"}}, "Running git worktree add", true},
+		{"git without subcommand", core.RunGitCommand{Args: []string{"fetch", "origin", "main"}}, "Running git fetch", true},
+		{"git flag after command", core.RunGitCommand{Args: []string{"worktree", "--help"}}, "Running git worktree", true},
+		{"hooks", core.RunHooks{Type: core.HookTypeOnOpen}, "Running on_open hooks", true},
+		{"print is instant", core.PrintMessage{Msg: "hi"}, "", false},
+		{"mkdir is instant", core.CreateDirectory{Path: "/x"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			label, slow := core.ProgressLabel(tt.action)
+			assert.Equal(t, tt.label, label)
+			assert.Equal(t, tt.slow, slow)
+		})
+	}
+}
+
+func TestCountProgressSteps(t *testing.T) {
+	plan := core.Plan{Actions: []core.Action{
+		core.CreateDirectory{Path: "/x"},
+		core.RunGitCommand{Args: []string{"worktree", "add"}},
+		core.PrintMessage{Msg: "ok"},
+		core.RunHooks{Type: core.HookTypeOnCreate},
+	}}
+
+	assert.Equal(t, 2, core.CountProgressSteps(plan))
+	assert.Equal(t, 0, core.CountProgressSteps(core.Plan{}))
+}
+
+func TestProgressLabel_Parallel(t *testing.T) {
+	label, slow := core.ProgressLabel(core.Parallel{Actions: []core.Action{
+		core.RunGitCommand{Args: []string{"fetch"}},
+		core.PrintMessage{Msg: "hi"},
+		core.RunHooks{Type: core.HookTypeOnCreate},
+	}})
+	assert.True(t, slow)
+	assert.Equal(t, "Running git fetch + Running on_create hooks", label)
+
+	_, slow = core.ProgressLabel(core.Parallel{Actions: []core.Action{core.CreateDirectory{Path: "/x"}}})
+	assert.False(t, slow)
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/style"
+)
+
+// PromptContext contains all inputs needed to plan the prompt command.
+type PromptContext struct {
+	WorktreePath string // Empty outside a worktree: nothing is printed
+	Head         string // Branch name, or short commit for a detached HEAD
+	Status       git.WorktreeStatus
+	StatusKnown  bool      // Status came from the cache (or --refresh)
+	UpdatedAt    time.Time // When Status was computed
+
+	Raw  bool // Print space-separated fields for custom prompts (--raw)
+	JSON bool // Print a PromptReport (--json)
+}
+
+// PromptReport is the machine-readable output of 'sprout prompt --json'.
+type PromptReport struct {
+	Worktree      string     `json:"worktree"`
+	Head          string     `json:"head"`
+	Dirty         bool       `json:"dirty"`
+	UntrackedOnly bool       `json:"untracked_only"`
+	Ahead         int        `json:"ahead"`
+	Behind        int        `json:"behind"`
+	Unmerged      bool       `json:"unmerged"`
+	Stash         bool       `json:"stash"`
+	StatusKnown   bool       `json:"status_known"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+}
+
+// PlanPromptCommand creates a plan that prints a short description of the
+// current worktree for a shell prompt. Outside a worktree it prints nothing,
+// so prompts stay clean.
+func PlanPromptCommand(ctx PromptContext) Plan {
+	if ctx.WorktreePath == "" {
+		return Plan{}
+	}
+
+	var out string
+	switch {
+	case ctx.JSON:
+		report := PromptReport{
+			Worktree:      ctx.WorktreePath,
+			Head:          ctx.Head,
+			Dirty:         ctx.Status.Dirty,
+			UntrackedOnly: ctx.Status.UntrackedOnly,
+			Ahead:         ctx.Status.Ahead,
+			Behind:        ctx.Status.Behind,
+			Unmerged:      ctx.Status.Unmerged,
+			Stash:         ctx.Status.HasStash,
+			StatusKnown:   ctx.StatusKnown,
+		}
+		if ctx.StatusKnown {
+			report.UpdatedAt = &ctx.UpdatedAt
+		}
+		data, err := json.Marshal(report)
+		if err != nil {
+			return errorPlan(fmt.Errorf("failed to encode prompt: %w", err))
+		}
+		out = string(data)
+	case ctx.Raw:
+		out = FormatPromptRaw(ctx.Head, ctx.Status)
+	default:
+		out = FormatPromptSegment(ctx.Head, ctx.Status)
+	}
+
+	return Plan{Actions: []Action{PrintMessage{Msg: out}}}
+}
+
+// FormatPromptSegment renders the default prompt segment, e.g. "🌱 feature ✗↑".
+// It uses the same icons as 'sprout list', without color or spacing.
+func FormatPromptSegment(head string, status git.WorktreeStatus) string {
+	segment := style.CurrentIcons().Sprout + " " + head
+	if icons := strings.Join(plainStatusIcons(status, false), ""); icons != "" {
+		segment += " " + icons
+	}
+	return segment
+}
+
+// FormatPromptRaw renders "head dirty ahead behind unmerged" with 0/1 for
+// the flags, for prompt themes that draw their own indicators.
+func FormatPromptRaw(head string, status git.WorktreeStatus) string {
+	return fmt.Sprintf("%s %d %d %d %d", head, boolToInt(status.Dirty), status.Ahead, status.Behind, boolToInt(status.Unmerged))
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanPromptCommand(t *testing.T) {
+	t.Parallel()
+
+	status := git.WorktreeStatus{Dirty: true, Ahead: 2}
+	updated := time.Date(2026, 5, 1, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		ctx  PromptContext
+		want []Action
+	}{
+		{
+			name: "outside a worktree prints nothing",
+			ctx:  PromptContext{},
+			want: nil,
+		},
+		{
+			name: "segment with status icons",
+			ctx:  PromptContext{WorktreePath: "/wt", Head: "feature", Status: status, StatusKnown: true},
+			want: []Action{PrintMessage{Msg: "🌱 feature ✗↑"}},
+		},
+		{
+			name: "clean segment",
+			ctx:  PromptContext{WorktreePath: "/wt", Head: "feature"},
+			want: []Action{PrintMessage{Msg: "🌱 feature"}},
+		},
+		{
+			name: "raw fields",
+			ctx:  PromptContext{WorktreePath: "/wt", Head: "feature", Status: status, Raw: true},
+			want: []Action{PrintMessage{Msg: "feature 1 2 0 0"}},
+		},
+		{
+			name: "json with cache time",
+			ctx:  PromptContext{WorktreePath: "/wt", Head: "feature", Status: status, StatusKnown: true, UpdatedAt: updated, JSON: true},
+			want: []Action{PrintMessage{Msg: `{"worktree":"/wt","head":"feature","dirty":true,"untracked_only":false,"ahead":2,"behind":0,"unmerged":false,"stash":false,"status_known":true,"updated_at":"2026-05-01T09:30:00Z"}`}},
+		},
+		{
+			name: "json without cached status",
+			ctx:  PromptContext{WorktreePath: "/wt", Head: "feature", JSON: true},
+			want: []Action{PrintMessage{Msg: `{"worktree":"/wt","head":"feature","dirty":false,"untracked_only":false,"ahead":0,"behind":0,"unmerged":false,"stash":false,"status_known":false}`}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, PlanPromptCommand(tt.ctx).Actions)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/m44rten1/sprout/internal/style"
+)
+
+// Message constants for the prune command
+const (
+	msgNoOrphans    = "No orphaned directories found."
+	msgOrphansFound = "🗑️  Found %d orphaned director(ies) using %s:"
+	msgBrokenLink   = "has a .git file; 'sprout repair' in its repository may relink it"
+	msgPruned       = "🧹 Removed %d orphaned director(ies), freeing %s."
+	msgExpiredFound = "⏳ Found %d expired temporary worktree(s):"
+	msgExpiredGone  = "🧹 Removed %d expired worktree(s); their branches are kept."
+	msgExpiredLeft  = "⚠️  Could not remove %d expired worktree(s):"
+)
+
+// OrphanedDir is a directory under the sprout root that holds no worktree
+// git knows about, left behind by 'git worktree remove --force' outside
+// sprout, a deleted repository or an interrupted command.
+type OrphanedDir struct {
+	Path   string
+	Size   int64 // Bytes in the files under Path
+	HasGit bool  // Contains a .git file: a worktree whose repository moved or is gone
+}
+
+// ExpiredWorktree is a temporary worktree (sprout add --ttl) whose time has
+// run out. It is removed through git, like 'sprout remove' does, unless
+// Kept says why not.
+type ExpiredWorktree struct {
+	MainPath string // Main worktree of its repository
+	Path     string
+	Branch   string
+	Kept     string // "uncommitted changes", "untracked files", "pinned" or "locked"; empty when it is removed
+}
+
+// PruneResult is how removing one expired worktree went.
+type PruneResult struct {
+	Worktree ExpiredWorktree
+	Failed   string // Why it could not be removed; empty when it was
+}
+
+// PruneContext contains all inputs needed to plan the prune command.
+type PruneContext struct {
+	Orphans []OrphanedDir
+	Expired []ExpiredWorktree
+	Home    string // For shortening paths
+}
+
+// Removable returns the expired worktrees prune removes.
+func (ctx PruneContext) Removable() []ExpiredWorktree {
+	var removable []ExpiredWorktree
+	for _, wt := range ctx.Expired {
+		if wt.Kept == "" {
+			removable = append(removable, wt)
+		}
+	}
+	return removable
+}
+
+// TotalSize returns the bytes all orphaned directories take up.
+func (ctx PruneContext) TotalSize() int64 {
+	var total int64
+	for _, orphan := range ctx.Orphans {
+		total += orphan.Size
+	}
+	return total
+}
+
+// PlanPruneReport creates a plan that lists the orphaned directories with
+// their sizes and the expired temporary worktrees, before anything is
+// removed.
+func PlanPruneReport(ctx PruneContext) Plan {
+	if len(ctx.Orphans) == 0 && len(ctx.Expired) == 0 {
+		return Plan{Actions: []Action{PrintMessage{Msg: msgNoOrphans}}}
+	}
+
+	var actions []Action
+	if len(ctx.Orphans) > 0 {
+		actions = append(actions, PrintMessage{Msg: formatOrphans(ctx)})
+	}
+	if len(ctx.Expired) > 0 {
+		actions = append(actions, PrintMessage{Msg: formatExpired(ctx)})
+	}
+	return Plan{Actions: actions}
+}
+
+// formatOrphans lists the orphaned directories with their sizes.
+func formatOrphans(ctx PruneContext) string {
+	sizes := make([]string, len(ctx.Orphans))
+	width := 0
+	for i, orphan := range ctx.Orphans {
+		sizes[i] = FormatSize(orphan.Size)
+		width = max(width, len(sizes[i]))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, msgOrphansFound, len(ctx.Orphans), FormatSize(ctx.TotalSize()))
+	for i, orphan := range ctx.Orphans {
+		fmt.Fprintf(&b, "\n   %*s  %s", width, sizes[i], ShortenPathWithHome(orphan.Path, ctx.Home))
+		if orphan.HasGit {
+			b.WriteString("  " + style.Yellow("("+msgBrokenLink+")"))
+		}
+	}
+	return b.String()
+}
+
+// formatExpired lists the expired temporary worktrees, with the reason
+// for each one that is kept.
+func formatExpired(ctx PruneContext) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, msgExpiredFound, len(ctx.Expired))
+	for _, wt := range ctx.Expired {
+		fmt.Fprintf(&b, "\n   %s  %s", style.Green(wt.Branch), style.Gray(ShortenPathWithHome(wt.Path, ctx.Home)))
+		if wt.Kept != "" {
+			b.WriteString("  " + style.Yellow("(kept: "+wt.Kept+")"))
+		}
+	}
+	return b.String()
+}
+
+// PlanPruneDelete creates a plan that removes every orphaned directory.
+// Expired worktrees are removed one at a time (see PlanPruneExpired), so
+// one git refuses doesn't stop the others.
+func PlanPruneDelete(ctx PruneContext) Plan {
+	if len(ctx.Orphans) == 0 {
+		return Plan{Actions: nil}
+	}
+
+	var actions []Action
+	for _, orphan := range ctx.Orphans {
+		actions = append(actions, RemoveDirectory{Path: orphan.Path})
+	}
+	actions = append(actions, PrintMessage{Msg: fmt.Sprintf(msgPruned, len(ctx.Orphans), FormatSize(ctx.TotalSize()))})
+	return Plan{Actions: actions}
+}
+
+// PlanPruneExpired creates a plan that removes one expired worktree. It is
+// removed without --force, so git refuses it if it picked up changes since
+// it was listed. Its metadata goes along with it, so a worktree created at
+// the same path later isn't temporary.
+func PlanPruneExpired(wt ExpiredWorktree) Plan {
+	return Plan{Actions: []Action{
+		RunGitCommand{Dir: wt.MainPath, Args: []string{"worktree", "remove", wt.Path}},
+		RemoveScratch{WorktreePath: wt.Path},
+		ForgetMetadata{WorktreePath: wt.Path},
+	}}
+}
+
+// PlanPruneExpiredSummary reports how removing the expired worktrees went,
+// listing the ones that could not be removed and why. Any failure makes
+// the command exit with 1.
+func PlanPruneExpiredSummary(ctx PruneContext, results []PruneResult) Plan {
+	var failed []PruneResult
+	for _, r := range results {
+		if r.Failed != "" {
+			failed = append(failed, r)
+		}
+	}
+
+	var actions []Action
+	if removed := len(results) - len(failed); removed > 0 {
+		actions = append(actions, PrintMessage{Msg: fmt.Sprintf(msgExpiredGone, removed)})
+	}
+	if len(failed) == 0 {
+		return Plan{Actions: actions}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, msgExpiredLeft, len(failed))
+	for _, r := range failed {
+		fmt.Fprintf(&b, "\n   %s  %s  %s", style.Green(r.Worktree.Branch), style.Gray(ShortenPathWithHome(r.Worktree.Path, ctx.Home)), r.Failed)
+	}
+	return Plan{Actions: append(actions, PrintError{Msg: b.String()}, Exit{Code: 1})}
+}
+
+// FormatSize describes a number of bytes in the largest binary unit that
+// keeps it at least 1: "512 B", "1.5 KB", "230.0 MB".
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	size := float64(bytes) / unit
+	suffixes := []string{"KB", "MB", "GB", "TB"}
+	i := 0
+	for size >= unit && i < len(suffixes)-1 {
+		size /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", size, suffixes[i])
+}
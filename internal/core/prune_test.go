@@ -0,0 +1,118 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/style"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanPruneReport(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nothing to prune", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanPruneReport(PruneContext{})
+		assert.Equal(t, []Action{PrintMessage{Msg: msgNoOrphans}}, plan.Actions)
+	})
+
+	t.Run("lists directories with aligned sizes", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanPruneReport(PruneContext{
+			Home: "/home/me",
+			Orphans: []OrphanedDir{
+				{Path: "/home/me/.local/share/sprout/app-1234/stale", Size: 3 << 20},
+				{Path: "/home/me/.local/share/sprout/gone-5678", Size: 12, HasGit: true},
+			},
+		})
+		assert.Equal(t, []Action{PrintMessage{Msg: "🗑️  Found 2 orphaned director(ies) using 3.0 MB:\n" +
+			"   3.0 MB  ~/.local/share/sprout/app-1234/stale\n" +
+			"     12 B  ~/.local/share/sprout/gone-5678  " + style.Yellow("("+msgBrokenLink+")")}}, plan.Actions)
+	})
+}
+
+func TestPlanPruneReport_Expired(t *testing.T) {
+	t.Parallel()
+
+	plan := PlanPruneReport(PruneContext{
+		Home: "/home/me",
+		Expired: []ExpiredWorktree{
+			{MainPath: "/code/app", Path: "/home/me/sprout/app/spike", Branch: "spike"},
+			{MainPath: "/code/app", Path: "/home/me/sprout/app/try", Branch: "try", Kept: "uncommitted changes"},
+		},
+	})
+	require.Len(t, plan.Actions, 1, "without orphans only the expired worktrees are listed")
+	assert.Equal(t, "⏳ Found 2 expired temporary worktree(s):\n"+
+		"   spike  ~/sprout/app/spike\n"+
+		"   try  ~/sprout/app/try  (kept: uncommitted changes)", style.Strip(plan.Actions[0].(PrintMessage).Msg))
+}
+
+func TestPlanPruneDelete(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, PlanPruneDelete(PruneContext{}).Actions)
+
+	plan := PlanPruneDelete(PruneContext{Orphans: []OrphanedDir{
+		{Path: "/sprout/app-1234/stale", Size: 1024},
+		{Path: "/sprout/gone-5678", Size: 512},
+	}})
+	assert.Equal(t, []Action{
+		RemoveDirectory{Path: "/sprout/app-1234/stale"},
+		RemoveDirectory{Path: "/sprout/gone-5678"},
+		PrintMessage{Msg: "🧹 Removed 2 orphaned director(ies), freeing 1.5 KB."},
+	}, plan.Actions)
+
+	assert.Empty(t, PlanPruneDelete(PruneContext{Expired: []ExpiredWorktree{{Path: "/sprout/app/spike"}}}).Actions,
+		"expired worktrees are removed one at a time")
+}
+
+func TestPlanPruneExpired(t *testing.T) {
+	t.Parallel()
+
+	plan := PlanPruneExpired(ExpiredWorktree{MainPath: "/code/app", Path: "/sprout/app/spike", Branch: "spike"})
+	assert.Equal(t, []Action{
+		RunGitCommand{Dir: "/code/app", Args: []string{"worktree", "remove", "/sprout/app/spike"}},
+		RemoveScratch{WorktreePath: "/sprout/app/spike"},
+		ForgetMetadata{WorktreePath: "/sprout/app/spike"},
+	}, plan.Actions)
+}
+
+func TestPlanPruneExpiredSummary(t *testing.T) {
+	t.Parallel()
+	spike := ExpiredWorktree{MainPath: "/code/app", Path: "/home/me/sprout/app/spike", Branch: "spike"}
+	try := ExpiredWorktree{MainPath: "/code/app", Path: "/home/me/sprout/app/try", Branch: "try"}
+	ctx := PruneContext{Home: "/home/me"}
+
+	plan := PlanPruneExpiredSummary(ctx, []PruneResult{{Worktree: spike}, {Worktree: try}})
+	assert.Equal(t, []Action{PrintMessage{Msg: "🧹 Removed 2 expired worktree(s); their branches are kept."}}, plan.Actions)
+
+	plan = PlanPruneExpiredSummary(ctx, []PruneResult{{Worktree: spike, Failed: "contains modified or untracked files"}, {Worktree: try}})
+	require.Len(t, plan.Actions, 3)
+	assert.Equal(t, PrintMessage{Msg: "🧹 Removed 1 expired worktree(s); their branches are kept."}, plan.Actions[0])
+	assert.Equal(t, "⚠️  Could not remove 1 expired worktree(s):\n   spike  ~/sprout/app/spike  contains modified or untracked files",
+		style.Strip(plan.Actions[1].(PrintError).Msg))
+	assert.Equal(t, Exit{Code: 1}, plan.Actions[2])
+
+	plan = PlanPruneExpiredSummary(ctx, []PruneResult{{Worktree: try, Failed: "is locked"}})
+	assert.IsType(t, PrintError{}, plan.Actions[0], "nothing removed, nothing to report as removed")
+}
+
+func TestFormatSize(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KB"},
+		{1536, "1.5 KB"},
+		{230 << 20, "230.0 MB"},
+		{5 << 30, "5.0 GB"},
+		{2048 << 40, "2048.0 TB"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, FormatSize(tt.bytes))
+	}
+}
@@ -0,0 +1,235 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/m44rten1/sprout/internal/style"
+)
+
+// Message constants for the rebase-all command
+const (
+	msgFetchingTarget  = "Fetching %s..."
+	msgUpdatingOnto    = "🔄 Updating %d worktree(s) onto %s"
+	msgRebasing        = "Rebasing %s onto %s..."
+	msgMerging         = "Merging %s into %s..."
+	msgUpdatedAll      = "🔄 Updated %d worktree(s) onto %s, %d failed, skipped %d:"
+	msgFailedLeft      = "Failed worktrees were left as they were; update them by hand with %s."
+	msgSyncHooksFailed = "post_sync hooks failed in %d updated worktree(s); their output is above."
+)
+
+// Reasons a worktree is left out of rebase-all
+const (
+	reasonDetached      = "detached HEAD"
+	reasonDefaultBranch = "is the default branch"
+	reasonDirty         = "uncommitted changes (pass --autostash)"
+	reasonUpToDate      = "up to date"
+)
+
+// RebaseAllContext contains all inputs needed to plan the rebase-all command.
+type RebaseAllContext struct {
+	RepoRoot      string
+	DefaultBranch string // Branch every worktree is updated onto
+	HasRemote     bool   // Fetch and update onto origin/DefaultBranch, else the local branch
+	Merge         bool   // Merge instead of rebasing (--merge)
+	Autostash     bool   // Stash uncommitted changes around the update (--autostash)
+	Worktrees     []RebaseWorktree
+
+	// post_sync hooks, run in each worktree that was updated
+	MainWorktreePath string
+	SyncHooks        []string // Empty when none are configured or with --no-hooks
+	IsTrusted        bool
+	HooksDenied      bool // An organization policy skips hooks
+}
+
+// RebaseWorktree is a sprout worktree that rebase-all may update.
+type RebaseWorktree struct {
+	Path   string
+	Branch string // Empty for a detached HEAD
+	Dirty  bool
+	Behind int // Commits of the target the branch doesn't have; known after the fetch
+}
+
+// RebaseResult is what rebase-all did to one worktree.
+type RebaseResult struct {
+	Branch      string
+	Updated     bool
+	Skipped     string   // Why the worktree was left alone, if it was
+	Conflicts   []string // Conflicting files when the update was aborted
+	Failed      string   // Why git failed, when it wasn't (only) conflicts
+	HooksFailed string   // Why the post_sync hooks of an updated worktree failed
+}
+
+// Target returns the ref worktrees are updated onto.
+func (ctx RebaseAllContext) Target() string {
+	if ctx.HasRemote {
+		return "origin/" + ctx.DefaultBranch
+	}
+	return ctx.DefaultBranch
+}
+
+// runsSyncHooks reports whether updated worktrees run post_sync hooks.
+func (ctx RebaseAllContext) runsSyncHooks() bool {
+	return len(ctx.SyncHooks) > 0 && !ctx.HooksDenied
+}
+
+// verb names the kind of update, as in git: rebase or merge.
+func (ctx RebaseAllContext) verb() string {
+	if ctx.Merge {
+		return "merge"
+	}
+	return "rebase"
+}
+
+// PlanRebaseAllFetch creates the plan that runs before any worktree is
+// updated: fetching the default branch, unless the repository has no remote.
+// Trust for post_sync hooks is asked for first, so declining changes nothing.
+func PlanRebaseAllFetch(ctx RebaseAllContext) Plan {
+	if ctx.RepoRoot == "" {
+		return errorPlan(ErrEmptyRepoRoot)
+	}
+	if len(ctx.Worktrees) == 0 {
+		return errorPlan(ErrNoSproutWorktrees)
+	}
+
+	var actions []Action
+	switch {
+	case len(ctx.SyncHooks) > 0 && ctx.HooksDenied:
+		actions = append(actions, PrintMessage{Msg: fmt.Sprintf(MsgHooksDeniedByPolicy, HookTypePostSync)})
+	case ctx.runsSyncHooks() && !ctx.IsTrusted:
+		if ctx.MainWorktreePath == "" {
+			return errorPlan(ErrEmptyMainWorktreePath)
+		}
+		actions = append(actions, PromptTrust{
+			MainWorktreePath: ctx.MainWorktreePath,
+			HookType:         HookTypePostSync,
+			HookCommands:     ctx.SyncHooks,
+		})
+	}
+	if ctx.HasRemote {
+		actions = append(actions,
+			PrintMessage{Msg: fmt.Sprintf(msgFetchingTarget, ctx.Target())},
+			RunGitCommand{Dir: ctx.RepoRoot, Args: []string{"fetch", "origin", ctx.DefaultBranch}},
+		)
+	}
+	actions = append(actions, PrintMessage{Msg: fmt.Sprintf(msgUpdatingOnto, len(ctx.Worktrees), ctx.Target())})
+	return Plan{Actions: actions}
+}
+
+// RebaseSkipReason returns why wt is left out, or "" when it is updated.
+// Dirty worktrees are only updated with --autostash, so a failed update
+// never mixes conflicts with uncommitted work.
+func RebaseSkipReason(ctx RebaseAllContext, wt RebaseWorktree) string {
+	switch {
+	case wt.Branch == "":
+		return reasonDetached
+	case wt.Branch == ctx.DefaultBranch:
+		return reasonDefaultBranch
+	case wt.Dirty && !ctx.Autostash:
+		return reasonDirty
+	case wt.Behind == 0:
+		return reasonUpToDate
+	}
+	return ""
+}
+
+// PlanRebaseWorktree creates a plan that rebases wt onto the target, or
+// merges the target into it with --merge.
+func PlanRebaseWorktree(ctx RebaseAllContext, wt RebaseWorktree) Plan {
+	args := []string{ctx.verb()}
+	if ctx.Autostash {
+		args = append(args, "--autostash")
+	}
+	if ctx.Merge {
+		args = append(args, "--no-edit")
+	}
+	msg := fmt.Sprintf(msgRebasing, wt.Branch, ctx.Target())
+	if ctx.Merge {
+		msg = fmt.Sprintf(msgMerging, ctx.Target(), wt.Branch)
+	}
+	return Plan{Actions: []Action{
+		PrintMessage{Msg: msg},
+		RunGitCommand{Dir: wt.Path, Args: append(args, ctx.Target())},
+	}}
+}
+
+// PlanSyncHooks creates a plan that runs the post_sync hooks in wt once it
+// was updated; it is empty when there are none to run.
+func PlanSyncHooks(ctx RebaseAllContext, wt RebaseWorktree) Plan {
+	if !ctx.runsSyncHooks() {
+		return Plan{Actions: nil}
+	}
+	return Plan{Actions: []Action{RunHooks{
+		Type:             HookTypePostSync,
+		Commands:         ctx.SyncHooks,
+		Path:             wt.Path,
+		RepoRoot:         ctx.RepoRoot,
+		MainWorktreePath: ctx.MainWorktreePath,
+		Branch:           wt.Branch,
+	}}}
+}
+
+// PlanRebaseAbort creates a plan that abandons a conflicting update of wt,
+// leaving it as it was before (including autostashed changes).
+func PlanRebaseAbort(ctx RebaseAllContext, wt RebaseWorktree) Plan {
+	return Plan{Actions: []Action{
+		RunGitCommand{Dir: wt.Path, Args: []string{ctx.verb(), "--abort"}},
+	}}
+}
+
+// PlanRebaseAllSummary prints one line per worktree: updated, conflicts
+// (with the files), failed or skipped, and why. The command exits with 1
+// when any update or post_sync hook failed, so scheduled runs notice.
+func PlanRebaseAllSummary(ctx RebaseAllContext, results []RebaseResult) Plan {
+	updated, failed, skipped, hooksFailed := 0, 0, 0, 0
+	rows := make([][3]string, 0, len(results)) // outcome, branch, details
+	for _, r := range results {
+		label := r.Branch
+		if label == "" {
+			label = "(detached)"
+		}
+		switch {
+		case r.Updated && r.HooksFailed != "":
+			updated++
+			hooksFailed++
+			rows = append(rows, [3]string{"updated", label, r.HooksFailed})
+		case r.Updated:
+			updated++
+			rows = append(rows, [3]string{"updated", label, ""})
+		case len(r.Conflicts) > 0:
+			failed++
+			rows = append(rows, [3]string{"conflicts", label, strings.Join(r.Conflicts, ", ")})
+		case r.Failed != "":
+			failed++
+			rows = append(rows, [3]string{"failed", label, r.Failed})
+		default:
+			skipped++
+			rows = append(rows, [3]string{"skipped", label, r.Skipped})
+		}
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, style.Width(row[1]))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, msgUpdatedAll, updated, ctx.Target(), failed, skipped)
+	for _, row := range rows {
+		if row[2] == "" {
+			fmt.Fprintf(&b, "\n   %-9s  %s", row[0], row[1])
+		} else {
+			fmt.Fprintf(&b, "\n   %-9s  %s  %s", row[0], style.PadRight(row[1], width), row[2])
+		}
+	}
+
+	if failed == 0 && hooksFailed == 0 {
+		return Plan{Actions: []Action{PrintMessage{Msg: b.String()}}}
+	}
+	if failed > 0 {
+		fmt.Fprintf(&b, "\n\n"+msgFailedLeft, "'git "+ctx.verb()+" "+ctx.Target()+"'")
+	}
+	if hooksFailed > 0 {
+		fmt.Fprintf(&b, "\n\n"+msgSyncHooksFailed, hooksFailed)
+	}
+	return Plan{Actions: []Action{PrintMessage{Msg: b.String()}, Exit{Code: 1}}}
+}
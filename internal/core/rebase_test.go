@@ -0,0 +1,201 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanRebaseAllFetch(t *testing.T) {
+	t.Parallel()
+	ctx := RebaseAllContext{
+		RepoRoot:      "/repo",
+		DefaultBranch: "main",
+		HasRemote:     true,
+		Worktrees:     []RebaseWorktree{{Path: "/sprout/a", Branch: "a"}},
+	}
+
+	t.Run("fetches the default branch", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanRebaseAllFetch(ctx)
+
+		require.Len(t, plan.Actions, 3)
+		assert.Equal(t, RunGitCommand{Dir: "/repo", Args: []string{"fetch", "origin", "main"}}, plan.Actions[1])
+		assert.Equal(t, PrintMessage{Msg: "🔄 Updating 1 worktree(s) onto origin/main"}, plan.Actions[2])
+	})
+
+	t.Run("without a remote", func(t *testing.T) {
+		t.Parallel()
+		local := ctx
+		local.HasRemote = false
+
+		plan := PlanRebaseAllFetch(local)
+
+		assert.Equal(t, []Action{PrintMessage{Msg: "🔄 Updating 1 worktree(s) onto main"}}, plan.Actions)
+	})
+
+	t.Run("no worktrees", func(t *testing.T) {
+		t.Parallel()
+		empty := ctx
+		empty.Worktrees = nil
+
+		plan := PlanRebaseAllFetch(empty)
+
+		assert.Equal(t, PrintError{Msg: ErrNoSproutWorktrees.Error()}, plan.Actions[0])
+	})
+
+	t.Run("asks to trust post_sync hooks first", func(t *testing.T) {
+		t.Parallel()
+		hooked := ctx
+		hooked.MainWorktreePath = "/repo"
+		hooked.SyncHooks = []string{"npm ci"}
+
+		plan := PlanRebaseAllFetch(hooked)
+
+		require.Len(t, plan.Actions, 4)
+		assert.Equal(t, PromptTrust{MainWorktreePath: "/repo", HookType: HookTypePostSync, HookCommands: []string{"npm ci"}}, plan.Actions[0])
+
+		hooked.IsTrusted = true
+		assert.Len(t, PlanRebaseAllFetch(hooked).Actions, 3)
+	})
+
+	t.Run("says when policy denies the hooks", func(t *testing.T) {
+		t.Parallel()
+		denied := ctx
+		denied.SyncHooks = []string{"npm ci"}
+		denied.HooksDenied = true
+
+		plan := PlanRebaseAllFetch(denied)
+
+		assert.Equal(t, PrintMessage{Msg: "🚫 Skipping post_sync hooks: disabled by organization trust policy"}, plan.Actions[0])
+	})
+}
+
+func TestPlanSyncHooks(t *testing.T) {
+	t.Parallel()
+	ctx := RebaseAllContext{RepoRoot: "/repo", MainWorktreePath: "/repo", SyncHooks: []string{"npm ci"}, IsTrusted: true}
+	wt := RebaseWorktree{Path: "/sprout/a", Branch: "a"}
+
+	assert.Equal(t, []Action{RunHooks{
+		Type:             HookTypePostSync,
+		Commands:         []string{"npm ci"},
+		Path:             "/sprout/a",
+		RepoRoot:         "/repo",
+		MainWorktreePath: "/repo",
+		Branch:           "a",
+	}}, PlanSyncHooks(ctx, wt).Actions)
+
+	ctx.HooksDenied = true
+	assert.Empty(t, PlanSyncHooks(ctx, wt).Actions)
+	assert.Empty(t, PlanSyncHooks(RebaseAllContext{}, wt).Actions)
+}
+
+func TestRebaseSkipReason(t *testing.T) {
+	t.Parallel()
+	ctx := RebaseAllContext{DefaultBranch: "main"}
+
+	tests := []struct {
+		name      string
+		wt        RebaseWorktree
+		autostash bool
+		want      string
+	}{
+		{"behind", RebaseWorktree{Branch: "a", Behind: 2}, false, ""},
+		{"up to date", RebaseWorktree{Branch: "a"}, false, "up to date"},
+		{"detached", RebaseWorktree{Behind: 2}, false, "detached HEAD"},
+		{"default branch", RebaseWorktree{Branch: "main", Behind: 2}, false, "is the default branch"},
+		{"dirty", RebaseWorktree{Branch: "a", Dirty: true, Behind: 2}, false, "uncommitted changes (pass --autostash)"},
+		{"dirty with autostash", RebaseWorktree{Branch: "a", Dirty: true, Behind: 2}, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := ctx
+			c.Autostash = tt.autostash
+			assert.Equal(t, tt.want, RebaseSkipReason(c, tt.wt))
+		})
+	}
+}
+
+func TestPlanRebaseWorktree(t *testing.T) {
+	t.Parallel()
+	wt := RebaseWorktree{Path: "/sprout/a", Branch: "a", Behind: 1}
+
+	plan := PlanRebaseWorktree(RebaseAllContext{DefaultBranch: "main", HasRemote: true}, wt)
+	assert.Equal(t, []Action{
+		PrintMessage{Msg: "Rebasing a onto origin/main..."},
+		RunGitCommand{Dir: "/sprout/a", Args: []string{"rebase", "origin/main"}},
+	}, plan.Actions)
+
+	ctx := RebaseAllContext{DefaultBranch: "main", Merge: true, Autostash: true}
+	plan = PlanRebaseWorktree(ctx, wt)
+	assert.Equal(t, []Action{
+		PrintMessage{Msg: "Merging main into a..."},
+		RunGitCommand{Dir: "/sprout/a", Args: []string{"merge", "--autostash", "--no-edit", "main"}},
+	}, plan.Actions)
+	assert.Equal(t, []Action{RunGitCommand{Dir: "/sprout/a", Args: []string{"merge", "--abort"}}}, PlanRebaseAbort(ctx, wt).Actions)
+}
+
+func TestPlanRebaseAllSummary(t *testing.T) {
+	t.Parallel()
+	ctx := RebaseAllContext{DefaultBranch: "main", HasRemote: true}
+
+	t.Run("all updated", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanRebaseAllSummary(ctx, []RebaseResult{
+			{Branch: "a", Updated: true},
+			{Branch: "bb", Skipped: "up to date"},
+		})
+
+		assert.Equal(t, []Action{PrintMessage{Msg: "🔄 Updated 1 worktree(s) onto origin/main, 0 failed, skipped 1:\n" +
+			"   updated    a\n" +
+			"   skipped    bb  up to date"}}, plan.Actions)
+	})
+
+	t.Run("aligns wide branch names by their width", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanRebaseAllSummary(ctx, []RebaseResult{
+			{Branch: "機能", Skipped: "up to date"},
+			{Branch: "fix-ü", Skipped: "up to date"},
+		})
+
+		assert.Equal(t, []Action{PrintMessage{Msg: "🔄 Updated 0 worktree(s) onto origin/main, 0 failed, skipped 2:\n" +
+			"   skipped    機能   up to date\n" +
+			"   skipped    fix-ü  up to date"}}, plan.Actions)
+	})
+
+	t.Run("conflicts exit with 1", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanRebaseAllSummary(ctx, []RebaseResult{
+			{Branch: "feature", Conflicts: []string{"go.mod", "main.go"}},
+			{Branch: "b", Failed: "error: cannot rebase"},
+			{Skipped: "detached HEAD"},
+		})
+
+		require.Len(t, plan.Actions, 2)
+		assert.Equal(t, "🔄 Updated 0 worktree(s) onto origin/main, 2 failed, skipped 1:\n"+
+			"   conflicts  feature     go.mod, main.go\n"+
+			"   failed     b           error: cannot rebase\n"+
+			"   skipped    (detached)  detached HEAD\n\n"+
+			"Failed worktrees were left as they were; update them by hand with 'git rebase origin/main'.",
+			plan.Actions[0].(PrintMessage).Msg)
+		assert.Equal(t, Exit{Code: 1}, plan.Actions[1])
+	})
+
+	t.Run("failed post_sync hooks exit with 1", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanRebaseAllSummary(ctx, []RebaseResult{
+			{Branch: "a", Updated: true, HooksFailed: "run post_sync hooks: exit 1"},
+			{Branch: "b", Updated: true},
+		})
+
+		require.Len(t, plan.Actions, 2)
+		assert.Equal(t, "🔄 Updated 2 worktree(s) onto origin/main, 0 failed, skipped 0:\n"+
+			"   updated    a  run post_sync hooks: exit 1\n"+
+			"   updated    b\n\n"+
+			"post_sync hooks failed in 1 updated worktree(s); their output is above.",
+			plan.Actions[0].(PrintMessage).Msg)
+		assert.Equal(t, Exit{Code: 1}, plan.Actions[1])
+	})
+}
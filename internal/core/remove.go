@@ -2,14 +2,19 @@ package core
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/style"
 )
 
 // Message constants for remove command
 const (
 	msgRemovedWorktree = "Removed worktree at %s"
 	errRefuseNonSprout = "Refusing to remove non-sprout worktree: %s"
+	msgRemovedMerged   = "🧹 Removed %d merged worktree(s), skipped %d:"
+	msgNothingMerged   = "Nothing to remove: no sprout worktree is both clean and merged."
 )
 
 // RemoveContext contains all inputs needed to plan a remove command.
@@ -27,6 +32,7 @@ type RemoveContext struct {
 	RepoRoot   string         // Repository root path
 	SproutRoot string         // Sprout root directory for this repo
 	Worktrees  []git.Worktree // All worktrees in the repo (used by shell, not planner)
+	Adopted    []string       // Adopted worktree paths (managed despite living outside SproutRoot)
 
 	// Resolved target (after branch lookup or interactive selection)
 	TargetPath string // Final worktree path to remove
@@ -39,9 +45,10 @@ type RemoveContext struct {
 //
 // The command flow is:
 // 1. Validate target path is under a sprout root (safety check)
-// 2. Remove the worktree using git
+// 2. Remove the worktree using git, with its scratch directory and metadata
 // 3. Print success message
 // 4. Prune stale worktree references
+// 5. Forget the worktree if it was adopted
 //
 // Note: Currently prune failures will fail the entire plan. To make this truly
 // "best-effort" (warn but continue), we would need a RunGitCommandBestEffort
@@ -59,8 +66,8 @@ func PlanRemoveCommand(ctx RemoveContext) Plan {
 		return errorPlan(ErrEmptyTargetPath)
 	}
 
-	// Safety check: verify target is under a sprout root
-	if !IsUnderSproutRoot(ctx.TargetPath, ctx.SproutRoot) {
+	// Safety check: verify target is under a sprout root (or was adopted)
+	if !IsSproutWorktree(ctx.TargetPath, ctx.SproutRoot, ctx.Adopted) {
 		return errorPlan(fmt.Errorf(errRefuseNonSprout, ctx.TargetPath))
 	}
 
@@ -71,6 +78,8 @@ func PlanRemoveCommand(ctx RemoveContext) Plan {
 			Dir:  ctx.RepoRoot,
 			Args: buildRemoveWorktreeArgs(ctx.TargetPath, ctx.Force),
 		},
+		RemoveScratch{WorktreePath: ctx.TargetPath},
+		ForgetMetadata{WorktreePath: ctx.TargetPath},
 		// Print success message
 		PrintMessage{
 			Msg: fmt.Sprintf(msgRemovedWorktree, ctx.TargetPath),
@@ -82,9 +91,24 @@ func PlanRemoveCommand(ctx RemoveContext) Plan {
 		},
 	}
 
+	if IsAdopted(ctx.TargetPath, ctx.Adopted) {
+		actions = append(actions, ForgetWorktree{Path: ctx.TargetPath})
+	}
+
 	return Plan{Actions: actions}
 }
 
+// Outcome is what the plan of PlanRemoveCommand does once it succeeds.
+func (ctx RemoveContext) Outcome() RemoveOutcome {
+	outcome := RemoveOutcome{Path: ctx.TargetPath, Removed: true}
+	for _, wt := range ctx.Worktrees {
+		if filepath.Clean(wt.Path) == filepath.Clean(ctx.TargetPath) {
+			outcome.Branch = wt.Branch
+		}
+	}
+	return outcome
+}
+
 // buildRemoveWorktreeArgs constructs arguments for 'git worktree remove'.
 // If force is true, adds --force flag to remove even with uncommitted changes.
 func buildRemoveWorktreeArgs(path string, force bool) []string {
@@ -95,3 +119,87 @@ func buildRemoveWorktreeArgs(path string, force bool) []string {
 	args = append(args, path)
 	return args
 }
+
+// RemoveMergedContext contains all inputs needed to plan remove --all-merged.
+type RemoveMergedContext struct {
+	RepoRoot    string
+	CurrentPath string          // Worktree the command runs in; never removed
+	Worktrees   []WorktreeUsage // The repository's sprout worktrees
+}
+
+// PlanRemoveMergedCommand creates a plan that removes every sprout worktree
+// that is clean and fully merged, then prints a table of what was removed
+// and what was skipped, and why.
+func PlanRemoveMergedCommand(ctx RemoveMergedContext) Plan {
+	if ctx.RepoRoot == "" {
+		return errorPlan(ErrEmptyRepoRoot)
+	}
+
+	var actions []Action
+	var rows [][2]string // label, reason ("" when removed)
+	removed := 0
+	for i, outcome := range ctx.Outcomes() {
+		u := ctx.Worktrees[i]
+		rows = append(rows, [2]string{u.label(), outcome.Reason})
+		if !outcome.Removed {
+			continue
+		}
+
+		actions = append(actions,
+			RunGitCommand{Dir: ctx.RepoRoot, Args: buildRemoveWorktreeArgs(u.Path, false)},
+			RemoveScratch{WorktreePath: u.Path},
+			ForgetMetadata{WorktreePath: u.Path},
+		)
+		if u.Adopted {
+			actions = append(actions, ForgetWorktree{Path: u.Path})
+		}
+		removed++
+	}
+	if removed > 0 {
+		actions = append(actions, RunGitCommand{Dir: ctx.RepoRoot, Args: []string{"worktree", "prune"}})
+	}
+
+	return Plan{Actions: append(actions, PrintMessage{Msg: formatRemoveMergedSummary(rows, removed)})}
+}
+
+// Outcomes returns what the plan of PlanRemoveMergedCommand does with each
+// worktree once it succeeds.
+func (ctx RemoveMergedContext) Outcomes() []RemoveOutcome {
+	outcomes := make([]RemoveOutcome, len(ctx.Worktrees))
+	for i, u := range ctx.Worktrees {
+		reason := usageNote(u)
+		if reason == "" && u.Path == ctx.CurrentPath {
+			reason = usageNote(WorktreeUsage{Current: true})
+		}
+		outcomes[i] = RemoveOutcome{
+			Path:    u.Path,
+			Branch:  u.Branch,
+			Removed: reason == "",
+			Reason:  strings.TrimPrefix(reason, ", "),
+		}
+	}
+	return outcomes
+}
+
+// formatRemoveMergedSummary renders one aligned line per worktree.
+func formatRemoveMergedSummary(rows [][2]string, removed int) string {
+	var b strings.Builder
+	if removed == 0 {
+		b.WriteString(msgNothingMerged)
+	} else {
+		fmt.Fprintf(&b, msgRemovedMerged, removed, len(rows)-removed)
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, style.Width(row[0]))
+	}
+	for _, row := range rows {
+		if row[1] == "" {
+			fmt.Fprintf(&b, "\n   removed  %s", row[0])
+		} else {
+			fmt.Fprintf(&b, "\n   skipped  %s  %s", style.PadRight(row[0], width), row[1])
+		}
+	}
+	return b.String()
+}
@@ -25,7 +25,7 @@ func TestPlanRemoveCommand(t *testing.T) {
 				TargetPath: "/test/repo/.sprout/feature",
 				Force:      false,
 			},
-			wantActions: 3, // git remove + success message + prune
+			wantActions: 5, // git remove + scratch + metadata + success message + prune
 			wantExit:    false,
 			assertions: func(t *testing.T, plan Plan) {
 				// Action 1: git worktree remove
@@ -34,15 +34,21 @@ func TestPlanRemoveCommand(t *testing.T) {
 				assert.Equal(t, "/test/repo", gitCmd.Dir)
 				assert.Equal(t, []string{"worktree", "remove", "/test/repo/.sprout/feature"}, gitCmd.Args)
 
-				// Action 2: success message
-				msg, ok := plan.Actions[1].(PrintMessage)
-				require.True(t, ok, "expected PrintMessage at index 1")
+				// Action 2: its scratch directory
+				assert.Equal(t, RemoveScratch{WorktreePath: "/test/repo/.sprout/feature"}, plan.Actions[1])
+
+				// Action 3: its metadata, so a later worktree there starts afresh
+				assert.Equal(t, ForgetMetadata{WorktreePath: "/test/repo/.sprout/feature"}, plan.Actions[2])
+
+				// Action 4: success message
+				msg, ok := plan.Actions[3].(PrintMessage)
+				require.True(t, ok, "expected PrintMessage at index 3")
 				assert.Contains(t, msg.Msg, "Removed worktree")
 				assert.Contains(t, msg.Msg, "/test/repo/.sprout/feature")
 
-				// Action 3: prune
-				prune, ok := plan.Actions[2].(RunGitCommand)
-				require.True(t, ok, "expected RunGitCommand at index 2")
+				// Action 5: prune
+				prune, ok := plan.Actions[4].(RunGitCommand)
+				require.True(t, ok, "expected RunGitCommand at index 4")
 				assert.Equal(t, []string{"worktree", "prune"}, prune.Args)
 			},
 		},
@@ -54,7 +60,7 @@ func TestPlanRemoveCommand(t *testing.T) {
 				TargetPath: "/test/repo/.sprout/feature",
 				Force:      true,
 			},
-			wantActions: 3,
+			wantActions: 5,
 			wantExit:    false,
 			assertions: func(t *testing.T, plan Plan) {
 				// Check --force flag is present in first action
@@ -63,6 +69,20 @@ func TestPlanRemoveCommand(t *testing.T) {
 				assert.Equal(t, []string{"worktree", "remove", "--force", "/test/repo/.sprout/feature"}, gitCmd.Args)
 			},
 		},
+		{
+			name: "remove adopted worktree outside sprout root",
+			ctx: RemoveContext{
+				RepoRoot:   "/test/repo",
+				SproutRoot: "/test/repo/.sprout",
+				Adopted:    []string{"/elsewhere/feature"},
+				TargetPath: "/elsewhere/feature",
+			},
+			wantActions: 6, // git remove + scratch + metadata + success message + prune + forget
+			wantExit:    false,
+			assertions: func(t *testing.T, plan Plan) {
+				assert.Equal(t, ForgetWorktree{Path: "/elsewhere/feature"}, plan.Actions[5])
+			},
+		},
 		{
 			name: "empty repo root returns error",
 			ctx: RemoveContext{
@@ -203,3 +223,83 @@ func TestRemoveContext_Documentation(t *testing.T) {
 	assert.Equal(t, "/test/repo/.sprout/feature", ctx.TargetPath)
 	assert.True(t, ctx.Force)
 }
+
+func TestPlanRemoveMergedCommand(t *testing.T) {
+	ctx := RemoveMergedContext{
+		RepoRoot:    "/test/repo",
+		CurrentPath: "/test/repo/.sprout/current",
+		Worktrees: []WorktreeUsage{
+			{Path: "/test/repo/.sprout/done", Branch: "done"},
+			{Path: "/test/repo/.sprout/wip", Branch: "wip", Dirty: true},
+			{Path: "/test/repo/.sprout/ahead", Branch: "ahead", Unmerged: true},
+			{Path: "/test/repo/.sprout/current", Branch: "current"},
+			{Path: "/elsewhere/adopted", Branch: "adopted", Adopted: true},
+		},
+	}
+
+	plan := PlanRemoveMergedCommand(ctx)
+
+	require.Len(t, plan.Actions, 9)
+	assert.Equal(t, RunGitCommand{Dir: "/test/repo", Args: []string{"worktree", "remove", "/test/repo/.sprout/done"}}, plan.Actions[0])
+	assert.Equal(t, RemoveScratch{WorktreePath: "/test/repo/.sprout/done"}, plan.Actions[1])
+	assert.Equal(t, ForgetMetadata{WorktreePath: "/test/repo/.sprout/done"}, plan.Actions[2])
+	assert.Equal(t, RunGitCommand{Dir: "/test/repo", Args: []string{"worktree", "remove", "/elsewhere/adopted"}}, plan.Actions[3])
+	assert.Equal(t, RemoveScratch{WorktreePath: "/elsewhere/adopted"}, plan.Actions[4])
+	assert.Equal(t, ForgetMetadata{WorktreePath: "/elsewhere/adopted"}, plan.Actions[5])
+	assert.Equal(t, ForgetWorktree{Path: "/elsewhere/adopted"}, plan.Actions[6])
+	assert.Equal(t, RunGitCommand{Dir: "/test/repo", Args: []string{"worktree", "prune"}}, plan.Actions[7])
+
+	msg, ok := plan.Actions[8].(PrintMessage)
+	require.True(t, ok)
+	assert.Equal(t, "🧹 Removed 2 merged worktree(s), skipped 3:\n"+
+		"   removed  done\n"+
+		"   skipped  wip      uncommitted changes\n"+
+		"   skipped  ahead    unmerged commits\n"+
+		"   skipped  current  current worktree\n"+
+		"   removed  adopted", msg.Msg)
+}
+
+func TestPlanRemoveMergedCommand_SkipsPinned(t *testing.T) {
+	plan := PlanRemoveMergedCommand(RemoveMergedContext{
+		RepoRoot:  "/test/repo",
+		Worktrees: []WorktreeUsage{{Path: "/test/repo/.sprout/keep", Branch: "keep", Pinned: true}},
+	})
+
+	require.Len(t, plan.Actions, 1)
+	msg, ok := plan.Actions[0].(PrintMessage)
+	require.True(t, ok)
+	assert.Contains(t, msg.Msg, "skipped  keep  pinned")
+}
+
+func TestPlanRemoveMergedCommand_SkipsLocked(t *testing.T) {
+	plan := PlanRemoveMergedCommand(RemoveMergedContext{
+		RepoRoot:  "/test/repo",
+		Worktrees: []WorktreeUsage{{Path: "/test/repo/.sprout/usb", Branch: "usb", Locked: true}},
+	})
+
+	require.Len(t, plan.Actions, 1)
+	msg, ok := plan.Actions[0].(PrintMessage)
+	require.True(t, ok)
+	assert.Contains(t, msg.Msg, "skipped  usb  locked")
+}
+
+func TestPlanRemoveMergedCommand_NothingToRemove(t *testing.T) {
+	plan := PlanRemoveMergedCommand(RemoveMergedContext{
+		RepoRoot:  "/test/repo",
+		Worktrees: []WorktreeUsage{{Path: "/test/repo/.sprout/wip", Branch: "wip", Dirty: true}},
+	})
+
+	require.Len(t, plan.Actions, 1)
+	msg, ok := plan.Actions[0].(PrintMessage)
+	require.True(t, ok)
+	assert.Contains(t, msg.Msg, "Nothing to remove")
+	assert.Contains(t, msg.Msg, "skipped  wip  uncommitted changes")
+}
+
+func TestPlanRemoveMergedCommand_EmptyRepoRoot(t *testing.T) {
+	plan := PlanRemoveMergedCommand(RemoveMergedContext{})
+
+	require.Len(t, plan.Actions, 2)
+	assert.IsType(t, PrintError{}, plan.Actions[0])
+	assert.Equal(t, Exit{Code: 1}, plan.Actions[1])
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RenderPlan writes plan as a Go composite literal, one action per line,
+// for the hidden --render-plan flag. Unlike FormatPlan it shows every field
+// an action sets, so the output can be pasted into a test as the expected
+// or input plan. Fields with their zero value are left out, which keeps
+// the output stable when actions gain fields. Deterministic: map keys are
+// sorted and times are rendered in UTC.
+func RenderPlan(plan Plan) string {
+	if len(plan.Actions) == 0 {
+		return "core.Plan{}"
+	}
+	return "core.Plan{Actions: " + renderValue(reflect.ValueOf(plan.Actions), "") + "}"
+}
+
+var (
+	fileModeType = reflect.TypeOf(os.FileMode(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// renderValue renders v as Go source. Lists of actions or structs take a
+// line per element, indented one tab more than indent.
+func renderValue(v reflect.Value, indent string) string {
+	switch v.Type() {
+	case fileModeType:
+		return fmt.Sprintf("%#o", v.Uint())
+	case timeType:
+		t := v.Interface().(time.Time).UTC()
+		return fmt.Sprintf("time.Date(%d, time.%s, %d, %d, %d, %d, %d, time.UTC)",
+			t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond())
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("%q", v.String())
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v.Interface())
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return "nil"
+		}
+		if v.Kind() == reflect.Pointer {
+			return "&" + renderValue(v.Elem(), indent)
+		}
+		return renderValue(v.Elem(), indent)
+	case reflect.Func:
+		if v.IsNil() {
+			return "nil"
+		}
+		return "/* " + v.Type().String() + " */ nil"
+	case reflect.Slice:
+		if v.IsNil() {
+			return "nil"
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return fmt.Sprintf("[]byte(%q)", v.Bytes())
+		}
+		if v.Len() == 0 {
+			return v.Type().String() + "{}"
+		}
+		elems := make([]string, v.Len())
+		for i := range elems {
+			elems[i] = renderValue(v.Index(i), indent+"\t")
+		}
+		switch v.Type().Elem().Kind() {
+		case reflect.Interface, reflect.Struct:
+			inner := indent + "\t"
+			return v.Type().String() + "{\n" + inner + strings.Join(elems, ",\n"+inner) + ",\n" + indent + "}"
+		}
+		return v.Type().String() + "{" + strings.Join(elems, ", ") + "}"
+	case reflect.Map:
+		if v.IsNil() {
+			return "nil"
+		}
+		keys := v.MapKeys()
+		entries := make([]string, len(keys))
+		for i, key := range keys {
+			entries[i] = renderValue(key, indent) + ": " + renderValue(v.MapIndex(key), indent)
+		}
+		slices.Sort(entries)
+		return v.Type().String() + "{" + strings.Join(entries, ", ") + "}"
+	case reflect.Struct:
+		var fields []string
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() || v.Field(i).IsZero() {
+				continue
+			}
+			fields = append(fields, field.Name+": "+renderValue(v.Field(i), indent))
+		}
+		return v.Type().String() + "{" + strings.Join(fields, ", ") + "}"
+	}
+	return fmt.Sprintf("%#v", v.Interface())
+}
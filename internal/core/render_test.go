@@ -0,0 +1,35 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/tickets"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderPlan(t *testing.T) {
+	assert.Equal(t, "core.Plan{}", RenderPlan(Plan{}))
+
+	plan := Plan{Actions: []Action{
+		NoOp{},
+		Parallel{Actions: []Action{
+			CopyFile{Src: "/repo/.env", Dst: "/wt/.env"},
+			WriteFile{Path: "/wt/.tool-versions", Content: []byte("go 1.24\n")},
+		}},
+		RecordTicket{WorktreePath: "/wt", Ticket: tickets.Ticket{ID: "ABC-1"}},
+		SetExpiry{WorktreePath: "/wt", ExpiresAt: time.Date(2026, time.March, 1, 9, 30, 0, 0, time.FixedZone("CET", 3600))},
+		Exit{Code: 1},
+	}}
+
+	assert.Equal(t, `core.Plan{Actions: []core.Action{
+	core.NoOp{},
+	core.Parallel{Actions: []core.Action{
+		core.CopyFile{Src: "/repo/.env", Dst: "/wt/.env"},
+		core.WriteFile{Path: "/wt/.tool-versions", Content: []byte("go 1.24\n")},
+	}},
+	core.RecordTicket{WorktreePath: "/wt", Ticket: tickets.Ticket{ID: "ABC-1"}},
+	core.SetExpiry{WorktreePath: "/wt", ExpiresAt: time.Date(2026, time.March, 1, 8, 30, 0, 0, time.UTC)},
+	core.Exit{Code: 1},
+}}`, RenderPlan(plan))
+}
@@ -1,5 +1,12 @@
 package core
 
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/m44rten1/sprout/internal/git"
+)
+
 // RepairContext contains repositories that may need worktree repair.
 type RepairContext struct {
 	// Repos are absolute paths to git repository roots that may need repair
@@ -24,3 +31,70 @@ func PlanRepair(ctx RepairContext) Plan {
 
 	return Plan{Actions: actions}
 }
+
+// RepoRepairContext describes a single repository for `sprout repair`.
+type RepoRepairContext struct {
+	MainWorktreePath string
+	// WorktreeRoot is where worktrees belong under the configured repo identity
+	WorktreeRoot       string
+	WorktreeRootExists bool
+	// PathWorktreeRoot is the path-keyed root used before switching to remote identity
+	PathWorktreeRoot       string
+	PathWorktreeRootExists bool
+	// Worktrees are the repository's worktrees as recorded by git
+	Worktrees []git.Worktree
+}
+
+// PlanRepoRepair creates a Plan that relinks a repository's worktrees.
+//
+// If worktrees still live under the path-keyed root while the configured
+// identity points elsewhere, the directory is moved to the new root and
+// `git worktree repair <paths>` updates git's links to the new locations.
+// Otherwise `git worktree repair` is run from the main worktree, which also
+// fixes worktrees left dangling after the main checkout itself was moved.
+func PlanRepoRepair(ctx RepoRepairContext) Plan {
+	if ctx.MainWorktreePath == "" {
+		return errorPlan(ErrEmptyMainWorktreePath)
+	}
+
+	repair := RunGitCommand{Dir: ctx.MainWorktreePath, Args: []string{"worktree", "repair"}}
+	done := PrintMessage{Msg: fmt.Sprintf("✅ Repaired worktrees for %s", ctx.MainWorktreePath)}
+
+	needsMigration := ctx.PathWorktreeRoot != "" &&
+		ctx.PathWorktreeRoot != ctx.WorktreeRoot &&
+		ctx.PathWorktreeRootExists
+	if !needsMigration {
+		return Plan{Actions: []Action{repair, done}}
+	}
+
+	if ctx.WorktreeRootExists {
+		// Never merge two directory trees automatically
+		return Plan{Actions: []Action{
+			PrintError{Msg: fmt.Sprintf("⚠️  Both %s and %s exist; move remaining worktrees manually, then run 'sprout repair' again", ctx.PathWorktreeRoot, ctx.WorktreeRoot)},
+			repair,
+			done,
+		}}
+	}
+
+	// Compute where each worktree will live after the move
+	var movedPaths []string
+	for _, wt := range ctx.Worktrees {
+		if !IsUnderSproutRoot(wt.Path, ctx.PathWorktreeRoot) {
+			continue
+		}
+		rel, err := filepath.Rel(ctx.PathWorktreeRoot, wt.Path)
+		if err != nil {
+			continue
+		}
+		movedPaths = append(movedPaths, filepath.Join(ctx.WorktreeRoot, rel))
+	}
+	repair.Args = append(repair.Args, movedPaths...)
+
+	return Plan{Actions: []Action{
+		PrintMessage{Msg: fmt.Sprintf("📦 Moving worktrees: %s → %s", ctx.PathWorktreeRoot, ctx.WorktreeRoot)},
+		CreateDirectory{Path: filepath.Dir(ctx.WorktreeRoot), Perm: 0755},
+		MoveDirectory{From: ctx.PathWorktreeRoot, To: ctx.WorktreeRoot},
+		repair,
+		done,
+	}}
+}
@@ -4,7 +4,9 @@ import (
 	"testing"
 
 	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/git"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPlanRepair_NoRepos(t *testing.T) {
@@ -53,3 +55,69 @@ func TestPlanRepair_Deterministic(t *testing.T) {
 	// Pure function: same input produces same output
 	assert.Equal(t, plan1, plan2)
 }
+
+func TestPlanRepoRepair_SameRoot(t *testing.T) {
+	ctx := core.RepoRepairContext{
+		MainWorktreePath:       "/repo",
+		WorktreeRoot:           "/sprout/repo-aaaa1111",
+		WorktreeRootExists:     true,
+		PathWorktreeRoot:       "/sprout/repo-aaaa1111",
+		PathWorktreeRootExists: true,
+	}
+	plan := core.PlanRepoRepair(ctx)
+
+	require.Len(t, plan.Actions, 2)
+	assert.Equal(t, core.RunGitCommand{Dir: "/repo", Args: []string{"worktree", "repair"}}, plan.Actions[0])
+	for _, action := range plan.Actions {
+		_, isMove := action.(core.MoveDirectory)
+		assert.False(t, isMove, "nothing to migrate")
+	}
+}
+
+func TestPlanRepoRepair_MigratesPathRoot(t *testing.T) {
+	ctx := core.RepoRepairContext{
+		MainWorktreePath:       "/repo",
+		WorktreeRoot:           "/sprout/app-bbbb2222",
+		PathWorktreeRoot:       "/sprout/repo-aaaa1111",
+		PathWorktreeRootExists: true,
+		Worktrees: []git.Worktree{
+			{Path: "/repo", Branch: "main"},
+			{Path: "/sprout/repo-aaaa1111/feature/repo", Branch: "feature"},
+			{Path: "/elsewhere/manual", Branch: "manual"},
+		},
+	}
+	plan := core.PlanRepoRepair(ctx)
+
+	require.Len(t, plan.Actions, 5)
+	assert.Equal(t, core.CreateDirectory{Path: "/sprout", Perm: 0755}, plan.Actions[1])
+	assert.Equal(t, core.MoveDirectory{From: "/sprout/repo-aaaa1111", To: "/sprout/app-bbbb2222"}, plan.Actions[2])
+	assert.Equal(t, core.RunGitCommand{
+		Dir:  "/repo",
+		Args: []string{"worktree", "repair", "/sprout/app-bbbb2222/feature/repo"},
+	}, plan.Actions[3])
+}
+
+func TestPlanRepoRepair_BothRootsExist(t *testing.T) {
+	ctx := core.RepoRepairContext{
+		MainWorktreePath:       "/repo",
+		WorktreeRoot:           "/sprout/app-bbbb2222",
+		WorktreeRootExists:     true,
+		PathWorktreeRoot:       "/sprout/repo-aaaa1111",
+		PathWorktreeRootExists: true,
+	}
+	plan := core.PlanRepoRepair(ctx)
+
+	_, isWarning := plan.Actions[0].(core.PrintError)
+	assert.True(t, isWarning, "should warn instead of merging directories")
+	for _, action := range plan.Actions {
+		_, isMove := action.(core.MoveDirectory)
+		assert.False(t, isMove, "should not move into an existing directory")
+	}
+}
+
+func TestPlanRepoRepair_EmptyMainWorktree(t *testing.T) {
+	plan := core.PlanRepoRepair(core.RepoRepairContext{})
+
+	require.Len(t, plan.Actions, 2)
+	assert.Equal(t, core.Exit{Code: 1}, plan.Actions[1])
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrRepoNotFound is returned when a repository name matches no known repository.
+var ErrRepoNotFound = errors.New("no sprout-managed repository with that name")
+
+// ResolveRepoName returns the main worktree path of the repository called
+// name among the repositories sprout knows about (those with worktrees under
+// the sprout root or adopted ones). A name is the repository's directory,
+// optionally preceded by the directories above it as 'sprout repos' prints
+// it ("app", or "work/app" to tell it from another app). Names that match
+// several repositories are rejected so a longer name can be passed instead.
+func ResolveRepoName(repos []RepoDisplay, name string) (string, error) {
+	var matches []RepoDisplay
+	for _, repo := range repos {
+		if name != "" && strings.HasSuffix(filepath.ToSlash(repo.MainPath), "/"+strings.Trim(name, "/")) {
+			matches = append(matches, repo)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%s: %w", name, ErrRepoNotFound)
+	case 1:
+		return matches[0].MainPath, nil
+	default:
+		paths := make([]string, len(matches))
+		for i, repo := range matches {
+			paths[i] = repo.MainPath
+		}
+		return "", fmt.Errorf("repository name '%s' is ambiguous (%s); pass %s or a path instead",
+			name, strings.Join(paths, ", "), strings.Join(RepoNames(matches), " or "))
+	}
+}
+
+// RepoNames returns the shortest name of each repository that no other one
+// has: its directory, preceded by as many directories above it as it takes
+// to tell it from repositories in directories of the same name.
+func RepoNames(repos []RepoDisplay) []string {
+	names := make([]string, len(repos))
+	depth := make([]int, len(repos))
+	for i := range depth {
+		depth[i] = 1
+	}
+	for {
+		byName := make(map[string][]int)
+		for i, repo := range repos {
+			names[i] = pathSuffix(repo.MainPath, depth[i])
+			byName[names[i]] = append(byName[names[i]], i)
+		}
+		longer := false
+		for _, same := range byName {
+			if len(same) < 2 {
+				continue
+			}
+			for _, i := range same {
+				if pathSuffix(repos[i].MainPath, depth[i]+1) != names[i] {
+					depth[i]++
+					longer = true
+				}
+			}
+		}
+		if !longer {
+			return names
+		}
+	}
+}
+
+// pathSuffix returns the last n elements of path, joined by slashes.
+func pathSuffix(path string, n int) string {
+	parts := strings.Split(strings.Trim(filepath.ToSlash(path), "/"), "/")
+	return strings.Join(parts[max(0, len(parts)-n):], "/")
+}
@@ -0,0 +1,57 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveRepoName(t *testing.T) {
+	repos := []core.RepoDisplay{
+		{Name: "api", MainPath: "/src/api"},
+		{Name: "web", MainPath: "/src/web"},
+		{Name: "web", MainPath: "/forks/web"},
+	}
+
+	path, err := core.ResolveRepoName(repos, "api")
+	require.NoError(t, err)
+	assert.Equal(t, "/src/api", path)
+
+	_, err = core.ResolveRepoName(repos, "missing")
+	assert.ErrorIs(t, err, core.ErrRepoNotFound)
+
+	_, err = core.ResolveRepoName(repos, "web")
+	assert.ErrorContains(t, err, "ambiguous (/src/web, /forks/web)")
+}
+
+func TestResolveRepoName_Qualified(t *testing.T) {
+	repos := []core.RepoDisplay{
+		{Name: "web", MainPath: "/src/web"},
+		{Name: "web", MainPath: "/forks/web"},
+		{Name: "myweb", MainPath: "/src/myweb"},
+	}
+
+	path, err := core.ResolveRepoName(repos, "forks/web")
+	require.NoError(t, err)
+	assert.Equal(t, "/forks/web", path)
+
+	_, err = core.ResolveRepoName(repos, "web")
+	assert.ErrorContains(t, err, "pass src/web or forks/web or a path instead")
+
+	_, err = core.ResolveRepoName(repos, "eb")
+	assert.ErrorIs(t, err, core.ErrRepoNotFound, "names are whole directories")
+}
+
+func TestRepoNames(t *testing.T) {
+	repos := []core.RepoDisplay{
+		{MainPath: "/code/api"},
+		{MainPath: "/code/work/app"},
+		{MainPath: "/code/personal/app"},
+		{MainPath: "/a/x/tool"},
+		{MainPath: "/b/x/tool"},
+	}
+
+	assert.Equal(t, []string{"api", "work/app", "personal/app", "a/x/tool", "b/x/tool"}, core.RepoNames(repos))
+}
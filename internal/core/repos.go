@@ -0,0 +1,110 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/style"
+)
+
+// ReposContext contains all inputs needed to plan the repos command.
+type ReposContext struct {
+	Repos []RepoSummary
+	Home  string    // For shortening paths
+	Now   time.Time // For the age of the last activity
+	JSON  bool      // Print a JSON array of RepoSummary (--json)
+}
+
+// RepoSummary is a repository as 'sprout repos' lists it.
+type RepoSummary struct {
+	Name         string    `json:"name"` // Unique name, accepted by --repo (see RepoNames)
+	MainPath     string    `json:"path"`
+	Worktrees    int       `json:"worktrees"` // Sprout worktrees, not counting the main one
+	LastActivity time.Time `json:"last_activity,omitzero"`
+}
+
+// SummarizeRepos turns the repositories 'sprout list --all' shows into
+// summaries, named as --repo accepts them. lastUsed is when each worktree
+// was last worked in; the latest of a repository's is its last activity.
+func SummarizeRepos(repos []RepoDisplay, lastUsed map[string]time.Time) []RepoSummary {
+	names := RepoNames(repos)
+	summaries := make([]RepoSummary, len(repos))
+	for i, repo := range repos {
+		summary := RepoSummary{Name: names[i], MainPath: repo.MainPath}
+		for _, wt := range repo.Worktrees {
+			if !wt.IsMain {
+				summary.Worktrees++
+			}
+			if used := lastUsed[wt.Path]; used.After(summary.LastActivity) {
+				summary.LastActivity = used
+			}
+		}
+		summaries[i] = summary
+	}
+	return summaries
+}
+
+// PlanReposCommand creates a plan that prints every repository sprout
+// manages: one aligned row each with its name, worktree count, last
+// activity and path, or a JSON array with --json.
+func PlanReposCommand(ctx ReposContext) Plan {
+	if ctx.JSON {
+		repos := ctx.Repos
+		if repos == nil {
+			repos = []RepoSummary{} // [] rather than null
+		}
+		data, err := json.MarshalIndent(repos, "", "  ")
+		if err != nil {
+			return errorPlan(fmt.Errorf("failed to encode repositories: %w", err))
+		}
+		return Plan{Actions: []Action{PrintMessage{Msg: string(data)}}}
+	}
+
+	if len(ctx.Repos) == 0 {
+		return Plan{Actions: []Action{PrintMessage{Msg: msgNoRepos}}}
+	}
+
+	rows := make([][4]string, len(ctx.Repos)) // name, worktrees, last activity, path
+	var widths [3]int
+	for i, repo := range ctx.Repos {
+		noun := "worktrees"
+		if repo.Worktrees == 1 {
+			noun = "worktree"
+		}
+		age := "never"
+		if !repo.LastActivity.IsZero() {
+			age = FormatAge(ctx.Now.Sub(repo.LastActivity))
+		}
+		rows[i] = [4]string{repo.Name, strconv.Itoa(repo.Worktrees) + " " + noun, age, ShortenPathWithHome(repo.MainPath, ctx.Home)}
+		for c := range widths {
+			widths[c] = max(widths[c], style.Width(rows[i][c]))
+		}
+	}
+
+	lines := make([]string, len(rows))
+	for i, row := range rows {
+		lines[i] = fmt.Sprintf("%s  %s  %s  %s",
+			style.Bold(style.PadRight(row[0], widths[0])),
+			style.PadRight(row[1], widths[1]),
+			style.PadRight(row[2], widths[2]),
+			style.Gray(row[3]))
+	}
+	return Plan{Actions: []Action{PrintMessage{Msg: strings.Join(lines, "\n")}}}
+}
+
+// FormatAge describes how long ago something happened, in the largest
+// whole unit: "just now", "5m ago", "3h ago" or "12d ago".
+func FormatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%dd ago", int(d/(24*time.Hour)))
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeRepos(t *testing.T) {
+	t.Parallel()
+	older := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	newer := older.Add(48 * time.Hour)
+
+	summaries := SummarizeRepos([]RepoDisplay{
+		{Name: "app", MainPath: "/code/app", Worktrees: []WorktreeDisplayItem{
+			{Path: "/code/app", IsMain: true},
+			{Path: "/sprout/app/a"},
+			{Path: "/sprout/app/b"},
+		}},
+		{Name: "idle", MainPath: "/code/idle", Worktrees: []WorktreeDisplayItem{
+			{Path: "/code/idle", IsMain: true},
+		}},
+	}, map[string]time.Time{"/code/app": older, "/sprout/app/b": newer})
+
+	assert.Equal(t, []RepoSummary{
+		{Name: "app", MainPath: "/code/app", Worktrees: 2, LastActivity: newer},
+		{Name: "idle", MainPath: "/code/idle"},
+	}, summaries)
+}
+
+func TestPlanReposCommand(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	repos := []RepoSummary{
+		{Name: "work/app", MainPath: "/home/me/work/app", Worktrees: 3, LastActivity: now.Add(-2 * time.Hour)},
+		{Name: "api", MainPath: "/srv/api", Worktrees: 1},
+	}
+
+	t.Run("text", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanReposCommand(ReposContext{Repos: repos, Home: "/home/me", Now: now})
+
+		require.Len(t, plan.Actions, 1)
+		assert.Equal(t, "\033[1mwork/app\033[0m  3 worktrees  2h ago  \033[90m~/work/app\033[0m\n"+
+			"\033[1mapi     \033[0m  1 worktree   never   \033[90m/srv/api\033[0m",
+			plan.Actions[0].(PrintMessage).Msg)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanReposCommand(ReposContext{Repos: repos, Now: now, JSON: true})
+
+		require.Len(t, plan.Actions, 1)
+		assert.JSONEq(t, `[
+			{"name": "work/app", "path": "/home/me/work/app", "worktrees": 3, "last_activity": "2026-03-10T10:00:00Z"},
+			{"name": "api", "path": "/srv/api", "worktrees": 1}
+		]`, plan.Actions[0].(PrintMessage).Msg)
+	})
+
+	t.Run("no repositories", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, []Action{PrintMessage{Msg: msgNoRepos}}, PlanReposCommand(ReposContext{}).Actions)
+		assert.Equal(t, []Action{PrintMessage{Msg: "[]"}}, PlanReposCommand(ReposContext{JSON: true}).Actions)
+	})
+}
+
+func TestFormatAge(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "just now", FormatAge(30*time.Second))
+	assert.Equal(t, "5m ago", FormatAge(5*time.Minute+10*time.Second))
+	assert.Equal(t, "23h ago", FormatAge(23*time.Hour+59*time.Minute))
+	assert.Equal(t, "12d ago", FormatAge(12*24*time.Hour+time.Hour))
+}
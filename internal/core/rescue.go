@@ -0,0 +1,158 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// AddFailure is why 'git worktree add' refused to create a worktree, as far
+// as sprout can tell from what git printed.
+type AddFailure int
+
+const (
+	AddFailureUnknown AddFailure = iota
+	// Something that is not a worktree is in the way at the path
+	AddFailurePathExists
+	// A worktree whose directory is gone is still registered at the path
+	AddFailureStaleWorktree
+	// As AddFailureStaleWorktree, but the registration is locked
+	AddFailureLockedWorktree
+	// The branch is checked out in another worktree
+	AddFailureCheckedOut
+)
+
+// git 2.42 renamed "is already checked out at" to "is already used by worktree at"
+var checkedOutPattern = regexp.MustCompile(`'.+' is already (?:checked out|used by worktree) at '(.+)'`)
+
+// ClassifyAddFailure tells from the stderr of a failed 'git worktree add'
+// why it failed. For AddFailureCheckedOut it also returns the worktree
+// that has the branch.
+func ClassifyAddFailure(stderr string) (AddFailure, string) {
+	switch {
+	case strings.Contains(stderr, "is a missing but locked worktree"):
+		return AddFailureLockedWorktree, ""
+	case strings.Contains(stderr, "is a missing but already registered worktree"):
+		return AddFailureStaleWorktree, ""
+	case strings.Contains(stderr, "' already exists") && !strings.Contains(stderr, "a branch named"):
+		return AddFailurePathExists, ""
+	}
+	if m := checkedOutPattern.FindStringSubmatch(stderr); m != nil {
+		return AddFailureCheckedOut, m[1]
+	}
+	return AddFailureUnknown, ""
+}
+
+// AddRescue is one way to recover from a failed 'git worktree add': run
+// Before, then create the worktree of Ctx.
+type AddRescue struct {
+	Label  string
+	Before []Action
+	Ctx    AddContext
+}
+
+// AddRescues explains a failed 'git worktree add' and offers ways out.
+type AddRescues struct {
+	Problem string
+	Hint    string // What to do by hand, for when sprout can't ask
+	Rescues []AddRescue
+}
+
+// PlanAddRescues returns the ways to recover from failure when adding the
+// worktree of ctx; altPath is a free path to create it at instead. ok is
+// false if sprout doesn't know what went wrong.
+func PlanAddRescues(ctx AddContext, failure AddFailure, checkedOutAt, altPath string) (rescues AddRescues, ok bool) {
+	elsewhere := ctx
+	elsewhere.WorktreePath = altPath
+	moveRescue := AddRescue{Label: "Create the worktree at " + altPath + " instead", Ctx: elsewhere}
+
+	switch failure {
+	case AddFailurePathExists:
+		return AddRescues{
+			Problem: fmt.Sprintf("%s already exists, and is not a worktree", ctx.WorktreePath),
+			Hint:    "move it away and run sprout add again",
+			Rescues: []AddRescue{moveRescue},
+		}, true
+
+	case AddFailureStaleWorktree:
+		forced := ctx
+		forced.Force = true
+		return AddRescues{
+			Problem: fmt.Sprintf("%s is still registered as a worktree, but its directory is gone", ctx.WorktreePath),
+			Hint:    "run 'git worktree prune' and sprout add again",
+			Rescues: []AddRescue{
+				{
+					Label:  "Prune stale worktrees and try again",
+					Before: []Action{RunGitCommand{Dir: ctx.RepoRoot, Args: []string{"worktree", "prune"}}},
+					Ctx:    ctx,
+				},
+				{Label: "Take over the registration (git worktree add --force)", Ctx: forced},
+				moveRescue,
+			},
+		}, true
+
+	case AddFailureLockedWorktree:
+		return AddRescues{
+			Problem: fmt.Sprintf("%s is registered as a locked worktree, but its directory is gone", ctx.WorktreePath),
+			Hint:    fmt.Sprintf("run 'git worktree unlock %s' and 'git worktree prune', and sprout add again", ctx.WorktreePath),
+			Rescues: []AddRescue{
+				{
+					Label: "Unlock and prune it, then try again",
+					Before: []Action{
+						RunGitCommand{Dir: ctx.RepoRoot, Args: []string{"worktree", "unlock", ctx.WorktreePath}},
+						RunGitCommand{Dir: ctx.RepoRoot, Args: []string{"worktree", "prune"}},
+					},
+					Ctx: ctx,
+				},
+				moveRescue,
+			},
+		}, true
+
+	case AddFailureCheckedOut:
+		forced := ctx
+		forced.CheckedOutAt = checkedOutAt
+		forced.Force = true
+		return AddRescues{
+			Problem: fmt.Sprintf("branch %s is already checked out at %s", ctx.Branch, checkedOutAt),
+			Hint:    fmt.Sprintf("open it there with 'sprout open %s', or pass --force-create", checkedOutAt),
+			Rescues: []AddRescue{{Label: "Check it out here as well (--force-create)", Ctx: forced}},
+		}, true
+	}
+	return AddRescues{}, false
+}
+
+// PlanAddRetry plans the rescue: its Before actions, then the rest of the
+// add from creating the worktree on. What comes before that (trust,
+// evicting worktrees over the limit) already happened in the failed attempt.
+func PlanAddRetry(rescue AddRescue) Plan {
+	plan := PlanAddCommand(rescue.Ctx)
+	actions := append([]Action{}, rescue.Before...)
+	for i, action := range plan.Actions {
+		if _, ok := action.(CreateDirectory); ok && i+1 < len(plan.Actions) && isWorktreeAdd(plan.Actions[i+1]) {
+			actions = append(actions, PrintMessage{Msg: fmt.Sprintf(msgCreatingWorktree, rescue.Ctx.Branch, rescue.Ctx.WorktreePath)})
+			return Plan{Actions: append(actions, plan.Actions[i:]...)}
+		}
+	}
+	// The plan doesn't create a worktree, such as an error plan
+	return plan
+}
+
+// AlternativeWorktreePath returns a path next to a worktree path that is
+// free: the branch directory gets a "-2" suffix, or "-3", and so on.
+func AlternativeWorktreePath(path string, exists func(string) bool) string {
+	dir, base := filepath.Split(filepath.Clean(path))
+	dir = filepath.Clean(dir)
+	for n := 2; ; n++ {
+		alt := filepath.Join(fmt.Sprintf("%s-%d", dir, n), base)
+		if !exists(alt) {
+			return alt
+		}
+	}
+}
+
+// isWorktreeAdd reports whether action runs 'git worktree add'.
+func isWorktreeAdd(action Action) bool {
+	a, ok := action.(RunGitCommand)
+	return ok && len(a.Args) > 1 && a.Args[0] == "worktree" && a.Args[1] == "add"
+}
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyAddFailure(t *testing.T) {
+	tests := []struct {
+		name         string
+		stderr       string
+		want         AddFailure
+		checkedOutAt string
+	}{
+		{
+			name:   "path exists",
+			stderr: "Preparing worktree (new branch 'b1')\nfatal: '../p1' already exists",
+			want:   AddFailurePathExists,
+		},
+		{
+			name:   "missing but registered",
+			stderr: "fatal: '../p2' is a missing but already registered worktree;\nuse 'add -f' to override, or 'prune' or 'remove' to clear",
+			want:   AddFailureStaleWorktree,
+		},
+		{
+			name:   "missing and locked",
+			stderr: "fatal: '../p3' is a missing but locked worktree;\nuse 'add -f -f' to override, or 'unlock' and 'prune' or 'remove' to clear",
+			want:   AddFailureLockedWorktree,
+		},
+		{
+			name:         "checked out",
+			stderr:       "fatal: 'main' is already checked out at '/repo'",
+			want:         AddFailureCheckedOut,
+			checkedOutAt: "/repo",
+		},
+		{
+			name:         "used by worktree",
+			stderr:       "fatal: 'main' is already used by worktree at '/repo'",
+			want:         AddFailureCheckedOut,
+			checkedOutAt: "/repo",
+		},
+		{
+			name:   "branch exists",
+			stderr: "fatal: a branch named 'b2' already exists",
+			want:   AddFailureUnknown,
+		},
+		{
+			name:   "invalid reference",
+			stderr: "fatal: invalid reference: nosuch",
+			want:   AddFailureUnknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, checkedOutAt := ClassifyAddFailure(tt.stderr)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.checkedOutAt, checkedOutAt)
+		})
+	}
+}
+
+func TestPlanAddRescues(t *testing.T) {
+	ctx := AddContext{
+		Branch:            "feature",
+		RepoRoot:          "/repo",
+		WorktreePath:      "/sprout/feature/repo",
+		LocalBranchExists: true,
+		Config:            &config.Config{},
+	}
+
+	t.Run("stale worktree", func(t *testing.T) {
+		rescues, ok := PlanAddRescues(ctx, AddFailureStaleWorktree, "", "/sprout/feature-2/repo")
+		require.True(t, ok)
+		require.Len(t, rescues.Rescues, 3)
+
+		prune := rescues.Rescues[0]
+		assert.Equal(t, []Action{RunGitCommand{Dir: "/repo", Args: []string{"worktree", "prune"}}}, prune.Before)
+		assert.Equal(t, ctx, prune.Ctx)
+		assert.True(t, rescues.Rescues[1].Ctx.Force)
+		assert.Equal(t, "/sprout/feature-2/repo", rescues.Rescues[2].Ctx.WorktreePath)
+	})
+
+	t.Run("locked worktree", func(t *testing.T) {
+		rescues, ok := PlanAddRescues(ctx, AddFailureLockedWorktree, "", "/sprout/feature-2/repo")
+		require.True(t, ok)
+		assert.Equal(t, []Action{
+			RunGitCommand{Dir: "/repo", Args: []string{"worktree", "unlock", "/sprout/feature/repo"}},
+			RunGitCommand{Dir: "/repo", Args: []string{"worktree", "prune"}},
+		}, rescues.Rescues[0].Before)
+		assert.Contains(t, rescues.Hint, "git worktree unlock /sprout/feature/repo")
+	})
+
+	t.Run("checked out", func(t *testing.T) {
+		rescues, ok := PlanAddRescues(ctx, AddFailureCheckedOut, "/elsewhere", "/sprout/feature-2/repo")
+		require.True(t, ok)
+		assert.Equal(t, "branch feature is already checked out at /elsewhere", rescues.Problem)
+		require.Len(t, rescues.Rescues, 1)
+		assert.True(t, rescues.Rescues[0].Ctx.Force)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, ok := PlanAddRescues(ctx, AddFailureUnknown, "", "/sprout/feature-2/repo")
+		assert.False(t, ok)
+	})
+}
+
+func TestPlanAddRetry(t *testing.T) {
+	ctx := AddContext{
+		Branch:            "feature",
+		RepoRoot:          "/repo",
+		WorktreePath:      "/sprout/feature/repo",
+		LocalBranchExists: true,
+		Config:            &config.Config{},
+		IsTrusted:         true,
+		NoOpen:            true,
+		Force:             true,
+	}
+	prune := RunGitCommand{Dir: "/repo", Args: []string{"worktree", "prune"}}
+
+	plan := PlanAddRetry(AddRescue{Before: []Action{prune}, Ctx: ctx})
+	require.GreaterOrEqual(t, len(plan.Actions), 4)
+	assert.Equal(t, prune, plan.Actions[0])
+	assert.IsType(t, PrintMessage{}, plan.Actions[1])
+	assert.Equal(t, CreateDirectory{Path: "/sprout/feature", Perm: 0755}, plan.Actions[2])
+	assert.Equal(t, RunGitCommand{Dir: "/repo", Args: []string{"worktree", "add", "--force", "/sprout/feature/repo", "feature"}}, plan.Actions[3])
+}
+
+func TestAlternativeWorktreePath(t *testing.T) {
+	taken := map[string]bool{"/sprout/feature-2/repo": true}
+	exists := func(path string) bool { return taken[path] }
+
+	assert.Equal(t, "/sprout/feature-3/repo", AlternativeWorktreePath("/sprout/feature/repo", exists))
+	assert.Equal(t, "/sprout/fix-2/repo", AlternativeWorktreePath("/sprout/fix/repo", exists))
+}
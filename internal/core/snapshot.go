@@ -0,0 +1,165 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestVersion is the current snapshot manifest format version.
+const ManifestVersion = 1
+
+// Manifest is a portable description of sprout worktrees, written by
+// `sprout snapshot` and consumed by `sprout restore`.
+type Manifest struct {
+	Version int            `yaml:"version"`
+	Repos   []ManifestRepo `yaml:"repos"`
+}
+
+// ManifestRepo lists the worktrees of one repository.
+// Path may start with "~" so manifests can be shared between machines.
+type ManifestRepo struct {
+	Path      string             `yaml:"path"`
+	Remote    string             `yaml:"remote,omitempty"`
+	Worktrees []ManifestWorktree `yaml:"worktrees"`
+}
+
+// ManifestWorktree is a single worktree. Base is the ref to create the branch
+// from when it exists neither locally nor on origin: the one 'sprout add'
+// created it from.
+type ManifestWorktree struct {
+	Branch string `yaml:"branch"`
+	Base   string `yaml:"base,omitempty"`
+}
+
+// FormatManifest renders a manifest as YAML.
+func FormatManifest(m Manifest) (string, error) {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	return string(data), nil
+}
+
+// ParseManifest decodes and validates a YAML manifest.
+func ParseManifest(data []byte) (Manifest, error) {
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return Manifest{}, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if m.Version != ManifestVersion {
+		return Manifest{}, fmt.Errorf("unsupported manifest version %d (expected %d)", m.Version, ManifestVersion)
+	}
+	for i, repo := range m.Repos {
+		if repo.Path == "" {
+			return Manifest{}, fmt.Errorf("repos[%d]: path is required", i)
+		}
+		for j, wt := range repo.Worktrees {
+			if wt.Branch == "" {
+				return Manifest{}, fmt.Errorf("repos[%d].worktrees[%d]: branch is required", i, j)
+			}
+		}
+	}
+	return m, nil
+}
+
+// ExpandHomeWithHome is the inverse of ShortenPathWithHome: it replaces a
+// leading "~" with home.
+func ExpandHomeWithHome(path, home string) string {
+	if home == "" {
+		return path
+	}
+	if path == "~" {
+		return home
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		return filepath.Join(home, rest)
+	}
+	return path
+}
+
+// RestoreContext contains all inputs needed to plan the restore command.
+type RestoreContext struct {
+	Repos []RestoreRepo
+}
+
+// RestoreRepo is a manifest repository resolved against the local machine.
+type RestoreRepo struct {
+	Path      string // Absolute path of the main worktree (or where to clone it)
+	Remote    string // Clone URL, used when the repository is missing
+	Exists    bool   // Repository is already present at Path
+	Worktrees []RestoreWorktree
+}
+
+// RestoreWorktree is a manifest worktree resolved against the local machine.
+// Branch existence is only known for repositories that already exist.
+type RestoreWorktree struct {
+	Branch             string
+	Base               string
+	Path               string // Where sprout places the worktree
+	Exists             bool
+	LocalBranchExists  bool
+	RemoteBranchExists bool
+	HasOriginMain      bool
+}
+
+// PlanRestoreCommand creates a plan that recreates the worktrees in a manifest.
+//
+// Missing repositories are cloned from their remote (or skipped with a warning
+// if the manifest has none). Existing worktrees are left alone. Hooks are not
+// run and no editor is opened; use `sprout open` afterwards.
+func PlanRestoreCommand(ctx RestoreContext) Plan {
+	var actions []Action
+	created := 0
+
+	for _, repo := range ctx.Repos {
+		if !repo.Exists {
+			if repo.Remote == "" {
+				actions = append(actions, PrintError{
+					Msg: fmt.Sprintf("⚠️  Skipping %s: repository not found and manifest has no remote", repo.Path),
+				})
+				continue
+			}
+			actions = append(actions,
+				PrintMessage{Msg: fmt.Sprintf("📥 Cloning %s into %s...", repo.Remote, repo.Path)},
+				CreateDirectory{Path: filepath.Dir(repo.Path), Perm: 0755},
+				RunGitCommand{Dir: filepath.Dir(repo.Path), Args: []string{"clone", repo.Remote, repo.Path}},
+			)
+		}
+
+		for _, wt := range repo.Worktrees {
+			if wt.Exists {
+				actions = append(actions, PrintMessage{Msg: fmt.Sprintf(msgWorktreeExists, wt.Path)})
+				continue
+			}
+			actions = append(actions,
+				PrintMessage{Msg: fmt.Sprintf(msgCreatingWorktree, wt.Branch, wt.Path)},
+				CreateDirectory{Path: filepath.Dir(wt.Path), Perm: 0755},
+				RunGitCommand{Dir: repo.Path, Args: restoreWorktreeArgs(repo, wt)},
+			)
+			created++
+		}
+	}
+
+	actions = append(actions, PrintMessage{Msg: fmt.Sprintf("🌱 Restored %d worktree(s)", created)})
+	return Plan{Actions: actions}
+}
+
+// restoreWorktreeArgs picks the git arguments for recreating a worktree.
+func restoreWorktreeArgs(repo RestoreRepo, wt RestoreWorktree) []string {
+	if !repo.Exists {
+		// Freshly cloned: branch existence is unknown at planning time, so rely on
+		// git's DWIM to check out the branch or create it tracking origin/<branch>
+		return []string{"worktree", "add", wt.Path, wt.Branch}
+	}
+	if !wt.LocalBranchExists && !wt.RemoteBranchExists && wt.Base != "" {
+		return []string{"worktree", "add", wt.Path, "-b", wt.Branch, "--no-track", wt.Base}
+	}
+	var base string
+	if wt.HasOriginMain {
+		base = "origin/main"
+	}
+	return WorktreeAddArgs(wt.Path, wt.Branch, wt.LocalBranchExists, wt.RemoteBranchExists, base)
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	m := Manifest{Version: ManifestVersion, Repos: []ManifestRepo{{
+		Path:   "~/code/app",
+		Remote: "git@github.com:mycompany/app.git",
+		Worktrees: []ManifestWorktree{
+			{Branch: "feat/login", Base: "origin/feat/login"},
+			{Branch: "spike"},
+		},
+	}}}
+
+	out, err := FormatManifest(m)
+	require.NoError(t, err)
+	assert.Contains(t, out, "branch: feat/login")
+	assert.NotContains(t, out, "base: \"\"", "empty base should be omitted")
+
+	parsed, err := ParseManifest([]byte(out))
+	require.NoError(t, err)
+	assert.Equal(t, m, parsed)
+}
+
+func TestParseManifest_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"wrong version":  "version: 2\nrepos: []\n",
+		"missing path":   "version: 1\nrepos:\n  - worktrees: []\n",
+		"missing branch": "version: 1\nrepos:\n  - path: /repo\n    worktrees:\n      - base: main\n",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseManifest([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestExpandHomeWithHome(t *testing.T) {
+	assert.Equal(t, "/home/user/code/app", ExpandHomeWithHome("~/code/app", "/home/user"))
+	assert.Equal(t, "/home/user", ExpandHomeWithHome("~", "/home/user"))
+	assert.Equal(t, "/srv/app", ExpandHomeWithHome("/srv/app", "/home/user"))
+	assert.Equal(t, "~/code", ExpandHomeWithHome("~/code", ""))
+}
+
+func TestPlanRestoreCommand(t *testing.T) {
+	t.Run("existing repo creates missing worktrees only", func(t *testing.T) {
+		plan := PlanRestoreCommand(RestoreContext{Repos: []RestoreRepo{{
+			Path:   "/code/app",
+			Exists: true,
+			Worktrees: []RestoreWorktree{
+				{Branch: "done", Path: "/sprout/app/done/app", Exists: true},
+				{Branch: "feat", Path: "/sprout/app/feat/app", RemoteBranchExists: true},
+				{Branch: "local-only", Base: "develop", Path: "/sprout/app/local-only/app"},
+			},
+		}}})
+
+		var gitCmds []RunGitCommand
+		for _, action := range plan.Actions {
+			if cmd, ok := action.(RunGitCommand); ok {
+				gitCmds = append(gitCmds, cmd)
+			}
+		}
+		require.Len(t, gitCmds, 2)
+		assert.Equal(t, []string{"worktree", "add", "/sprout/app/feat/app", "-b", "feat", "origin/feat"}, gitCmds[0].Args)
+		assert.Equal(t, []string{"worktree", "add", "/sprout/app/local-only/app", "-b", "local-only", "--no-track", "develop"}, gitCmds[1].Args)
+		assert.Equal(t, PrintMessage{Msg: "🌱 Restored 2 worktree(s)"}, plan.Actions[len(plan.Actions)-1])
+	})
+
+	t.Run("missing repo is cloned first", func(t *testing.T) {
+		plan := PlanRestoreCommand(RestoreContext{Repos: []RestoreRepo{{
+			Path:      "/code/app",
+			Remote:    "git@github.com:mycompany/app.git",
+			Worktrees: []RestoreWorktree{{Branch: "feat", Path: "/sprout/app/feat/app"}},
+		}}})
+
+		assert.Contains(t, plan.Actions, RunGitCommand{Dir: "/code", Args: []string{"clone", "git@github.com:mycompany/app.git", "/code/app"}})
+		assert.Contains(t, plan.Actions, RunGitCommand{Dir: "/code/app", Args: []string{"worktree", "add", "/sprout/app/feat/app", "feat"}})
+	})
+
+	t.Run("missing repo without remote is skipped", func(t *testing.T) {
+		plan := PlanRestoreCommand(RestoreContext{Repos: []RestoreRepo{{
+			Path:      "/code/app",
+			Worktrees: []RestoreWorktree{{Branch: "feat", Path: "/sprout/app/feat/app"}},
+		}}})
+
+		require.Len(t, plan.Actions, 2)
+		_, isWarning := plan.Actions[0].(PrintError)
+		assert.True(t, isWarning)
+		for _, action := range plan.Actions {
+			_, isExit := action.(Exit)
+			assert.False(t, isExit, "one unrestorable repo should not abort the rest")
+		}
+	})
+}
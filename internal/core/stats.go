@@ -0,0 +1,164 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/style"
+	"github.com/m44rten1/sprout/internal/timing"
+)
+
+// StatsContext contains all inputs needed to plan the stats command.
+type StatsContext struct {
+	Samples []timing.Sample // Recorded samples of every repository, oldest first
+	Repo    string          // Main worktree path to report on; empty reports every repository (--all)
+	Home    string          // For shortening paths; may be empty
+}
+
+// statsSections orders the kinds of samples in the report.
+var statsSections = []struct {
+	kind  string
+	title string
+}{
+	{timing.Command, "Commands"},
+	{timing.Hook, "Hooks"},
+	{timing.Status, "Worktree status"},
+	{timing.Action, "Actions"},
+}
+
+// TimingSummary aggregates the samples of one name.
+type TimingSummary struct {
+	Name   string
+	Runs   int
+	Median time.Duration
+	P90    time.Duration
+	Max    time.Duration
+}
+
+// PlanStatsCommand creates a plan that prints how long commands, hooks,
+// status checks and actions typically take, slowest first, for one
+// repository or for each repository.
+func PlanStatsCommand(ctx StatsContext) Plan {
+	byRepo := make(map[string][]timing.Sample)
+	for _, s := range ctx.Samples {
+		if ctx.Repo != "" && s.Repo != ctx.Repo {
+			continue
+		}
+		byRepo[s.Repo] = append(byRepo[s.Repo], s)
+	}
+
+	if len(byRepo) == 0 {
+		msg := "No timings recorded yet. sprout records how long commands take as you use it."
+		if ctx.Repo != "" {
+			msg = fmt.Sprintf("No timings recorded yet for %s. sprout records how long commands take as you use it.", ShortenPathWithHome(ctx.Repo, ctx.Home))
+		}
+		return Plan{Actions: []Action{PrintMessage{Msg: msg}}}
+	}
+
+	repos := make([]string, 0, len(byRepo))
+	for repo := range byRepo {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+
+	var reports []string
+	for _, repo := range repos {
+		reports = append(reports, FormatTimingReport(repo, byRepo[repo], ctx.Home))
+	}
+	return Plan{Actions: []Action{PrintMessage{Msg: strings.Join(reports, "\n\n")}}}
+}
+
+// FormatTimingReport renders the samples of one repository as a table per
+// kind of sample. Samples recorded outside a repository have an empty repo.
+func FormatTimingReport(repo string, samples []timing.Sample, home string) string {
+	title := "(outside a repository)"
+	if repo != "" {
+		title = ShortenPathWithHome(repo, home)
+	}
+	since := samples[0].At
+	for _, s := range samples {
+		if s.At.Before(since) {
+			since = s.At
+		}
+	}
+
+	sections := make(map[string][]TimingSummary)
+	width := 0
+	for _, section := range statsSections {
+		summaries := SummarizeTimings(samples, section.kind)
+		sections[section.kind] = summaries
+		for _, summary := range summaries {
+			width = max(width, style.Width(summary.Name), style.Width(section.title))
+		}
+	}
+
+	var b strings.Builder
+	noun := "samples"
+	if len(samples) == 1 {
+		noun = "sample"
+	}
+	fmt.Fprintf(&b, "📊 %s (%d %s since %s)", title, len(samples), noun, since.Format("2006-01-02"))
+	for _, section := range statsSections {
+		summaries := sections[section.kind]
+		if len(summaries) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n\n  %s %6s %8s %8s %8s", style.PadRight(section.title, width), "runs", "median", "p90", "max")
+		for _, s := range summaries {
+			fmt.Fprintf(&b, "\n  %s %6d %8s %8s %8s", style.PadRight(s.Name, width), s.Runs,
+				FormatTiming(s.Median), FormatTiming(s.P90), FormatTiming(s.Max))
+		}
+	}
+	return b.String()
+}
+
+// SummarizeTimings aggregates the samples of one kind by name, slowest
+// median first.
+func SummarizeTimings(samples []timing.Sample, kind string) []TimingSummary {
+	durations := make(map[string][]time.Duration)
+	for _, s := range samples {
+		if s.Kind == kind {
+			durations[s.Name] = append(durations[s.Name], s.Duration)
+		}
+	}
+
+	summaries := make([]TimingSummary, 0, len(durations))
+	for name, ds := range durations {
+		sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+		summaries = append(summaries, TimingSummary{
+			Name:   name,
+			Runs:   len(ds),
+			Median: percentile(ds, 0.5),
+			P90:    percentile(ds, 0.9),
+			Max:    ds[len(ds)-1],
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Median != summaries[j].Median {
+			return summaries[i].Median > summaries[j].Median
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries
+}
+
+// percentile returns the nearest-rank percentile p of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
+
+// FormatTiming renders a duration compactly: "45ms", "1.2s", "2m5s".
+func FormatTiming(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	default:
+		return d.Round(time.Second).String()
+	}
+}
@@ -0,0 +1,76 @@
+package core_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/timing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeTimings(t *testing.T) {
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	var samples []timing.Sample
+	for i := 1; i <= 10; i++ {
+		samples = append(samples, timing.Sample{Kind: timing.Hook, Name: "on_create: npm ci", Duration: time.Duration(i) * time.Second, At: at})
+	}
+	samples = append(samples,
+		timing.Sample{Kind: timing.Hook, Name: "on_open: make dev", Duration: 20 * time.Second, At: at},
+		timing.Sample{Kind: timing.Command, Name: "list", Duration: time.Second, At: at},
+	)
+
+	summaries := core.SummarizeTimings(samples, timing.Hook)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, core.TimingSummary{Name: "on_open: make dev", Runs: 1, Median: 20 * time.Second, P90: 20 * time.Second, Max: 20 * time.Second}, summaries[0], "slowest first")
+	assert.Equal(t, core.TimingSummary{Name: "on_create: npm ci", Runs: 10, Median: 5 * time.Second, P90: 9 * time.Second, Max: 10 * time.Second}, summaries[1])
+}
+
+func TestPlanStatsCommand(t *testing.T) {
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	samples := []timing.Sample{
+		{Repo: "/home/me/repo", Kind: timing.Command, Name: "list", Duration: 180 * time.Millisecond, At: at},
+		{Repo: "/home/me/repo", Kind: timing.Status, Name: "worktree status", Duration: 45 * time.Millisecond, At: at},
+		{Repo: "/home/me/other", Kind: timing.Command, Name: "add", Duration: 90 * time.Second, At: at.Add(time.Hour)},
+	}
+
+	t.Run("one repository", func(t *testing.T) {
+		plan := core.PlanStatsCommand(core.StatsContext{Samples: samples, Repo: "/home/me/repo", Home: "/home/me"})
+
+		require.Len(t, plan.Actions, 1)
+		msg := plan.Actions[0].(core.PrintMessage).Msg
+		assert.Equal(t, `📊 ~/repo (2 samples since 2026-10-01)
+
+  Commands          runs   median      p90      max
+  list                 1    180ms    180ms    180ms
+
+  Worktree status   runs   median      p90      max
+  worktree status      1     45ms     45ms     45ms`, msg)
+	})
+
+	t.Run("every repository", func(t *testing.T) {
+		plan := core.PlanStatsCommand(core.StatsContext{Samples: samples, Home: "/home/me"})
+
+		msg := plan.Actions[0].(core.PrintMessage).Msg
+		assert.Contains(t, msg, "📊 ~/other (1 sample since")
+		assert.Contains(t, msg, "📊 ~/repo (2 samples")
+		assert.Less(t, strings.Index(msg, "~/other"), strings.Index(msg, "~/repo"))
+		assert.Contains(t, msg, "1m30s")
+	})
+
+	t.Run("nothing recorded", func(t *testing.T) {
+		plan := core.PlanStatsCommand(core.StatsContext{Samples: samples, Repo: "/home/me/new", Home: "/home/me"})
+
+		msg := plan.Actions[0].(core.PrintMessage).Msg
+		assert.Contains(t, msg, "No timings recorded yet for ~/new")
+	})
+}
+
+func TestFormatTiming(t *testing.T) {
+	assert.Equal(t, "0ms", core.FormatTiming(300*time.Microsecond))
+	assert.Equal(t, "450ms", core.FormatTiming(450*time.Millisecond))
+	assert.Equal(t, "1.2s", core.FormatTiming(1240*time.Millisecond))
+	assert.Equal(t, "2m5s", core.FormatTiming(125*time.Second))
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/telemetry"
+)
+
+// TelemetryReportSchema is the version of telemetry.Report.
+const TelemetryReportSchema = 1
+
+// TelemetryContext contains all inputs needed to plan the telemetry commands.
+type TelemetryContext struct {
+	State    telemetry.State
+	Endpoint string // Where reports go; empty for builds that never send
+	Version  string // sprout version
+	OS       string
+	Arch     string
+	Now      time.Time
+}
+
+// PlanTelemetryOn creates a plan that opts in to telemetry.
+func PlanTelemetryOn(ctx TelemetryContext) Plan {
+	if ctx.State.Enabled {
+		return Plan{Actions: []Action{PrintMessage{Msg: "✅ Telemetry is already on. 'sprout telemetry show' prints what will be sent."}}}
+	}
+	msg := `✅ Telemetry is on. Thank you!
+
+sprout now counts how often each command runs, how long it takes and which
+kinds of errors it fails with, and sends a summary once a week. No paths,
+branch names, arguments or error messages are collected.
+
+  sprout telemetry show   print exactly what will be sent
+  sprout telemetry off    stop and discard what was collected`
+	if ctx.Endpoint == "" {
+		msg += "\n\nThis build of sprout has no telemetry endpoint, so nothing will actually be sent."
+	}
+	return Plan{Actions: []Action{
+		SetTelemetry{Enabled: true},
+		PrintMessage{Msg: msg},
+	}}
+}
+
+// PlanTelemetryOff creates a plan that opts out of telemetry, discarding
+// the usage collected for the next report.
+func PlanTelemetryOff(ctx TelemetryContext) Plan {
+	if !ctx.State.Enabled {
+		return Plan{Actions: []Action{PrintMessage{Msg: "Telemetry is already off."}}}
+	}
+	return Plan{Actions: []Action{
+		SetTelemetry{Enabled: false},
+		PrintMessage{Msg: "✅ Telemetry is off. Nothing more will be collected or sent."},
+	}}
+}
+
+// PlanTelemetryShow creates a plan that prints the report that would be
+// sent next, as JSON on stdout, with its status on stderr so the JSON can
+// be piped.
+func PlanTelemetryShow(ctx TelemetryContext) Plan {
+	if !ctx.State.Enabled {
+		return Plan{Actions: []Action{PrintMessage{Msg: "Telemetry is off: nothing is collected or sent. Run 'sprout telemetry on' to opt in."}}}
+	}
+
+	data, err := json.MarshalIndent(BuildTelemetryReport(ctx.State, ctx.Version, ctx.OS, ctx.Arch, ctx.Now), "", "  ")
+	if err != nil {
+		return errorPlan(fmt.Errorf("failed to encode telemetry report: %w", err))
+	}
+
+	status := fmt.Sprintf("Telemetry is on. This report will be sent to %s after %s:",
+		ctx.Endpoint, ctx.State.Since.Add(telemetry.SendInterval).Format("2006-01-02"))
+	if ctx.Endpoint == "" {
+		status = "Telemetry is on, but this build has no telemetry endpoint. This report would be sent:"
+	}
+	return Plan{Actions: []Action{
+		PrintError{Msg: status},
+		PrintMessage{Msg: string(data)},
+	}}
+}
+
+// BuildTelemetryReport summarizes the usage aggregated in state as the
+// report sent at now. Commands are sorted by name.
+func BuildTelemetryReport(state telemetry.State, version, goos, goarch string, now time.Time) telemetry.Report {
+	report := telemetry.Report{
+		Schema:   TelemetryReportSchema,
+		Version:  version,
+		OS:       goos,
+		Arch:     goarch,
+		From:     state.Since.UTC().Format("2006-01-02"),
+		To:       now.UTC().Format("2006-01-02"),
+		Commands: []telemetry.CommandReport{},
+		Errors:   map[string]int{},
+	}
+	for errType, count := range state.Errors {
+		report.Errors[errType] = count
+	}
+	for name, usage := range state.Commands {
+		command := telemetry.CommandReport{Name: name, Runs: usage.Runs, Failures: usage.Failures}
+		if len(usage.DurationsMS) > 0 {
+			ds := make([]time.Duration, len(usage.DurationsMS))
+			for i, ms := range usage.DurationsMS {
+				ds[i] = time.Duration(ms) * time.Millisecond
+			}
+			sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+			command.MedianMS = percentile(ds, 0.5).Milliseconds()
+			command.P90MS = percentile(ds, 0.9).Milliseconds()
+		}
+		report.Commands = append(report.Commands, command)
+	}
+	sort.Slice(report.Commands, func(i, j int) bool { return report.Commands[i].Name < report.Commands[j].Name })
+	return report
+}
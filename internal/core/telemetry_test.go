@@ -0,0 +1,100 @@
+package core_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTelemetryReport(t *testing.T) {
+	since := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	var state telemetry.State
+	state.Reset(since)
+	for _, ms := range []time.Duration{30, 10, 20, 40} {
+		state.Record("list", ms*time.Millisecond, "")
+	}
+	state.Record("add", 2*time.Second, "hook")
+
+	report := core.BuildTelemetryReport(state, "1.2.0", "darwin", "arm64", since.Add(7*24*time.Hour))
+
+	assert.Equal(t, telemetry.Report{
+		Schema:  core.TelemetryReportSchema,
+		Version: "1.2.0",
+		OS:      "darwin",
+		Arch:    "arm64",
+		From:    "2026-10-01",
+		To:      "2026-10-08",
+		Commands: []telemetry.CommandReport{
+			{Name: "add", Runs: 1, Failures: 1, MedianMS: 2000, P90MS: 2000},
+			{Name: "list", Runs: 4, MedianMS: 20, P90MS: 40},
+		},
+		Errors: map[string]int{"hook": 1},
+	}, report)
+}
+
+func TestPlanTelemetryOn(t *testing.T) {
+	t.Run("turns telemetry on", func(t *testing.T) {
+		plan := core.PlanTelemetryOn(core.TelemetryContext{Endpoint: "https://example.com"})
+
+		require.Len(t, plan.Actions, 2)
+		assert.Equal(t, core.SetTelemetry{Enabled: true}, plan.Actions[0])
+		msg := plan.Actions[1].(core.PrintMessage).Msg
+		assert.Contains(t, msg, "No paths")
+		assert.NotContains(t, msg, "no telemetry endpoint")
+	})
+
+	t.Run("says when nothing can be sent", func(t *testing.T) {
+		plan := core.PlanTelemetryOn(core.TelemetryContext{})
+
+		msg := plan.Actions[1].(core.PrintMessage).Msg
+		assert.Contains(t, msg, "no telemetry endpoint")
+	})
+
+	t.Run("already on", func(t *testing.T) {
+		plan := core.PlanTelemetryOn(core.TelemetryContext{State: telemetry.State{Enabled: true}})
+
+		require.Len(t, plan.Actions, 1)
+		assert.IsType(t, core.PrintMessage{}, plan.Actions[0])
+	})
+}
+
+func TestPlanTelemetryOff(t *testing.T) {
+	plan := core.PlanTelemetryOff(core.TelemetryContext{State: telemetry.State{Enabled: true}})
+	require.Len(t, plan.Actions, 2)
+	assert.Equal(t, core.SetTelemetry{Enabled: false}, plan.Actions[0])
+
+	plan = core.PlanTelemetryOff(core.TelemetryContext{})
+	require.Len(t, plan.Actions, 1)
+	assert.Contains(t, plan.Actions[0].(core.PrintMessage).Msg, "already off")
+}
+
+func TestPlanTelemetryShow(t *testing.T) {
+	t.Run("prints the report as JSON", func(t *testing.T) {
+		since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+		state := telemetry.State{Enabled: true}
+		state.Reset(since)
+		state.Record("list", 20*time.Millisecond, "")
+
+		plan := core.PlanTelemetryShow(core.TelemetryContext{State: state, Endpoint: "https://example.com", Version: "1.2.0", Now: since})
+
+		require.Len(t, plan.Actions, 2)
+		assert.Equal(t, core.PrintError{Msg: "Telemetry is on. This report will be sent to https://example.com after 2026-10-08:"}, plan.Actions[0])
+		var report telemetry.Report
+		require.NoError(t, json.Unmarshal([]byte(plan.Actions[1].(core.PrintMessage).Msg), &report))
+		assert.Equal(t, "1.2.0", report.Version)
+		require.Len(t, report.Commands, 1)
+		assert.Equal(t, "list", report.Commands[0].Name)
+	})
+
+	t.Run("off", func(t *testing.T) {
+		plan := core.PlanTelemetryShow(core.TelemetryContext{})
+
+		require.Len(t, plan.Actions, 1)
+		assert.Contains(t, plan.Actions[0].(core.PrintMessage).Msg, "Telemetry is off")
+	})
+}
@@ -2,12 +2,22 @@ package core
 
 import (
 	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/style"
 )
 
+// MsgHooksDeniedByPolicy is shown when an organization policy skips hooks.
+const MsgHooksDeniedByPolicy = "🚫 Skipping %s hooks: disabled by organization trust policy"
+
 // TrustContext contains all inputs needed to plan the trust command.
 type TrustContext struct {
 	RepoRoot       string
+	WorktreePath   string // The worktree whose hooks are trusted, if not the main one
 	AlreadyTrusted bool
+	Renew          bool // Refresh the trust timestamp even if already trusted (--renew)
 }
 
 // PlanTrustCommand generates a plan for trusting a repository.
@@ -15,9 +25,13 @@ type TrustContext struct {
 // or TrustRepo + PrintMessage if trust needs to be added.
 func PlanTrustCommand(ctx TrustContext) Plan {
 	if ctx.RepoRoot == "" {
+		return errorPlan(ErrNoRepoRoot)
+	}
+
+	if ctx.AlreadyTrusted && ctx.Renew {
 		return Plan{Actions: []Action{
-			PrintError{Msg: ErrNoRepoRoot.Error()},
-			Exit{Code: 1},
+			TrustRepo{RepoRoot: ctx.RepoRoot, WorktreePath: ctx.WorktreePath},
+			PrintMessage{Msg: fmt.Sprintf("🔄 Trust renewed: %s", ctx.RepoRoot)},
 		}}
 	}
 
@@ -38,7 +52,7 @@ Use --no-hooks flag to skip automatic execution.
 `, ctx.RepoRoot)
 
 	return Plan{Actions: []Action{
-		TrustRepo{RepoRoot: ctx.RepoRoot},
+		TrustRepo{RepoRoot: ctx.RepoRoot, WorktreePath: ctx.WorktreePath},
 		PrintMessage{Msg: successMsg},
 	}}
 }
@@ -48,10 +62,7 @@ Use --no-hooks flag to skip automatic execution.
 // or UntrustRepo + PrintMessage if trust needs to be removed.
 func PlanUntrustCommand(ctx TrustContext) Plan {
 	if ctx.RepoRoot == "" {
-		return Plan{Actions: []Action{
-			PrintError{Msg: ErrNoRepoRoot.Error()},
-			Exit{Code: 1},
-		}}
+		return errorPlan(ErrNoRepoRoot)
 	}
 
 	if !ctx.AlreadyTrusted {
@@ -73,3 +84,132 @@ Hooks will no longer run automatically. You can still:
 		PrintMessage{Msg: successMsg},
 	}}
 }
+
+// MsgTrustingWithHooks describes the hooks that will run when a repository is
+// trusted non-interactively (e.g. via --trust), mirroring what the prompt shows.
+func MsgTrustingWithHooks(repoRoot string, hookType HookType, commands []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🔓 Trusting repository (--trust): %s\n\n", repoRoot)
+	fmt.Fprintf(&b, "Hooks that will run on '%s':\n", hookType)
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "  • %s\n", cmd)
+	}
+	return b.String()
+}
+
+// FormatHookChanges shows how the hook commands of a repository changed
+// since it was trusted, as a diff of each hook type that changed: removed
+// commands in red, added ones in green, unchanged ones as context. Hooks
+// are keyed by type, as in config.Config.HookCommands.
+func FormatHookChanges(previous, current map[string][]string) string {
+	var b strings.Builder
+	for _, hookType := range []HookType{HookTypeOnCreate, HookTypeOnOpen, HookTypePostSync} {
+		before, after := previous[string(hookType)], current[string(hookType)]
+		if slices.Equal(before, after) {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s:\n", hookType)
+		for _, line := range diffLines(before, after) {
+			switch line.op {
+			case '-':
+				fmt.Fprintf(&b, "    %s\n", style.Red("- "+line.text))
+			case '+':
+				fmt.Fprintf(&b, "    %s\n", style.Green("+ "+line.text))
+			default:
+				fmt.Fprintf(&b, "      %s\n", line.text)
+			}
+		}
+	}
+	return b.String()
+}
+
+// diffLine is a line of a diff: op is '-' for removed, '+' for added and
+// ' ' for kept.
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// diffLines diffs two lists of lines through their longest common
+// subsequence, listing removals before additions where lines changed.
+func diffLines(a, b []string) []diffLine {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	return lines
+}
+
+// TrustListContext contains all inputs needed to format `sprout trust list`.
+type TrustListContext struct {
+	Entries  []TrustEntryDisplay
+	TTLLabel string    // Configured trust_ttl (empty if trust never expires)
+	Now      time.Time // Reference time for expiry checks
+	Home     string    // User's home directory for path shortening
+}
+
+// TrustEntryDisplay holds display data for one trusted repository.
+type TrustEntryDisplay struct {
+	RepoRoot  string
+	TrustedAt time.Time
+	ExpiresAt time.Time // Zero if trust never expires
+}
+
+// FormatTrustList formats the trusted repositories with their expiry dates.
+func FormatTrustList(ctx TrustListContext) string {
+	if len(ctx.Entries) == 0 {
+		return "No trusted repositories."
+	}
+
+	const dateFormat = "2006-01-02"
+
+	var lines []string
+	lines = append(lines, "Trusted repositories:", "")
+	for _, e := range ctx.Entries {
+		status := "never expires"
+		if !e.ExpiresAt.IsZero() {
+			if ctx.Now.Before(e.ExpiresAt) {
+				status = "expires " + e.ExpiresAt.Format(dateFormat)
+			} else {
+				status = style.Red("expired " + e.ExpiresAt.Format(dateFormat))
+			}
+		}
+		lines = append(lines,
+			"  "+ShortenPathWithHome(e.RepoRoot, ctx.Home),
+			style.Gray(fmt.Sprintf("    trusted %s · %s", e.TrustedAt.Format(dateFormat), status)),
+		)
+	}
+
+	if ctx.TTLLabel != "" {
+		lines = append(lines, "", fmt.Sprintf("Trust expires after %s. Run 'sprout trust --renew' to refresh.", ctx.TTLLabel))
+	}
+
+	return strings.Join(lines, "\n")
+}
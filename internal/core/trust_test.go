@@ -2,7 +2,9 @@ package core
 
 import (
 	"testing"
+	"time"
 
+	"github.com/m44rten1/sprout/internal/style"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -86,6 +88,13 @@ func TestPlanTrustCommand(t *testing.T) {
 		assert.Contains(t, printMsg.Msg, "on_open hooks")
 		assert.Contains(t, printMsg.Msg, "--no-hooks")
 	})
+
+	t.Run("from a worktree trusts its hooks", func(t *testing.T) {
+		plan := PlanTrustCommand(TrustContext{RepoRoot: "/test/repo", WorktreePath: "/sprout/feature/repo"})
+
+		require.Len(t, plan.Actions, 2)
+		assert.Equal(t, TrustRepo{RepoRoot: "/test/repo", WorktreePath: "/sprout/feature/repo"}, plan.Actions[0])
+	})
 }
 
 func TestPlanUntrustCommand(t *testing.T) {
@@ -148,3 +157,82 @@ func TestPlanUntrustCommand(t *testing.T) {
 		assert.Contains(t, printMsg.Msg, "sprout trust")
 	})
 }
+
+func TestPlanTrustCommand_Renew(t *testing.T) {
+	t.Run("already trusted with renew refreshes trust", func(t *testing.T) {
+		plan := PlanTrustCommand(TrustContext{RepoRoot: "/repo", AlreadyTrusted: true, Renew: true})
+
+		require.Len(t, plan.Actions, 2)
+		trustAction, ok := plan.Actions[0].(TrustRepo)
+		require.True(t, ok, "first action should be TrustRepo")
+		assert.Equal(t, "/repo", trustAction.RepoRoot)
+
+		msg := plan.Actions[1].(PrintMessage)
+		assert.Contains(t, msg.Msg, "renewed")
+	})
+
+	t.Run("not trusted with renew trusts normally", func(t *testing.T) {
+		plan := PlanTrustCommand(TrustContext{RepoRoot: "/repo", Renew: true})
+
+		require.Len(t, plan.Actions, 2)
+		assert.IsType(t, TrustRepo{}, plan.Actions[0])
+		assert.Contains(t, plan.Actions[1].(PrintMessage).Msg, "Repository trusted")
+	})
+}
+
+func TestFormatHookChanges(t *testing.T) {
+	previous := map[string][]string{
+		"on_create": {"npm install", "npm run build"},
+		"on_open":   {"make dev"},
+	}
+	current := map[string][]string{
+		"on_create": {"npm ci", "npm run build", "make db"},
+		"on_open":   {"make dev"},
+		"post_sync": {"npm ci"},
+	}
+
+	assert.Equal(t, "  on_create:\n"+
+		"    "+style.Red("- npm install")+"\n"+
+		"    "+style.Green("+ npm ci")+"\n"+
+		"      npm run build\n"+
+		"    "+style.Green("+ make db")+"\n"+
+		"  post_sync:\n"+
+		"    "+style.Green("+ npm ci")+"\n", FormatHookChanges(previous, current))
+
+	assert.Equal(t, "  on_open:\n    "+style.Red("- make dev")+"\n", FormatHookChanges(map[string][]string{"on_open": {"make dev"}}, nil))
+	assert.Empty(t, FormatHookChanges(previous, previous))
+}
+
+func TestFormatTrustList(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Equal(t, "No trusted repositories.", FormatTrustList(TrustListContext{Now: now}))
+	})
+
+	t.Run("shows expiry status", func(t *testing.T) {
+		out := FormatTrustList(TrustListContext{
+			Entries: []TrustEntryDisplay{
+				{RepoRoot: "/home/user/app", TrustedAt: now.AddDate(0, -1, 0), ExpiresAt: now.AddDate(0, 2, 0)},
+				{RepoRoot: "/home/user/old", TrustedAt: now.AddDate(0, -4, 0), ExpiresAt: now.AddDate(0, -1, 0)},
+			},
+			TTLLabel: "90d",
+			Now:      now,
+			Home:     "/home/user",
+		})
+
+		assert.Contains(t, out, "~/app")
+		assert.Contains(t, out, "expires 2025-08-01")
+		assert.Contains(t, out, "expired 2025-05-01")
+		assert.Contains(t, out, "90d")
+	})
+
+	t.Run("no ttl", func(t *testing.T) {
+		out := FormatTrustList(TrustListContext{
+			Entries: []TrustEntryDisplay{{RepoRoot: "/repo", TrustedAt: now}},
+			Now:     now,
+		})
+		assert.Contains(t, out, "never expires")
+		assert.NotContains(t, out, "--renew")
+	})
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Message constants for the uninstall command
+const (
+	msgUninstallIntro   = "⚠️  This removes everything sprout set up:"
+	msgUninstallKept    = "Branches stay in their repositories, and your config in %s is kept."
+	msgRemovingRepo     = "🧹 Removing %d worktree(s) of %s"
+	msgRemovedCompleted = "✨ sprout is uninstalled. Remove the sprout binary itself with your package manager, or by deleting it."
+)
+
+// UninstallContext contains all inputs needed to plan the uninstall command.
+type UninstallContext struct {
+	Repos      []UninstallRepo
+	DataDir    string // The sprout root, with all sprout worktrees and stores
+	StateDir   string // Debug logs, timings and locks; empty when missing
+	CacheDir   string // Empty when missing
+	ConfigDir  string // Kept, apart from the trust store
+	TrustStore string // Removed with its lock file; empty when missing
+	// ShellConfig is the shell config file install-completion added to,
+	// empty if it didn't; ShellConfigRest is the file without those lines.
+	ShellConfig     string
+	ShellConfigData []byte
+	ShellConfigRest []byte
+	Home            string // For shortening paths
+}
+
+// UninstallRepo is a repository with the sprout worktrees uninstall removes.
+type UninstallRepo struct {
+	MainPath  string
+	Worktrees []WorktreeDisplayItem
+}
+
+// PlanUninstallReport creates a plan that lists what uninstall removes,
+// warning about worktrees with uncommitted changes, before anything is.
+func PlanUninstallReport(ctx UninstallContext) Plan {
+	short := func(path string) string { return ShortenPathWithHome(path, ctx.Home) }
+
+	lines := []string{msgUninstallIntro}
+	for _, repo := range ctx.Repos {
+		lines = append(lines, fmt.Sprintf("   %d worktree(s) of %s", len(repo.Worktrees), short(repo.MainPath)))
+		for _, wt := range repo.Worktrees {
+			line := fmt.Sprintf("      %s  %s", wt.Branch, short(wt.Path))
+			if wt.Status.Dirty {
+				line += "  (uncommitted changes are lost)"
+			}
+			lines = append(lines, line)
+		}
+	}
+	lines = append(lines, fmt.Sprintf("   %s (sprout's data: worktrees and metadata)", short(ctx.DataDir)))
+	if ctx.StateDir != "" {
+		lines = append(lines, fmt.Sprintf("   %s (debug logs and timings)", short(ctx.StateDir)))
+	}
+	if ctx.CacheDir != "" {
+		lines = append(lines, fmt.Sprintf("   %s (caches)", short(ctx.CacheDir)))
+	}
+	if ctx.TrustStore != "" {
+		lines = append(lines, fmt.Sprintf("   %s (trusted repositories)", short(ctx.TrustStore)))
+	}
+	if ctx.ShellConfig != "" {
+		lines = append(lines, fmt.Sprintf("   the completion setup in %s", short(ctx.ShellConfig)))
+	}
+	lines = append(lines, "", fmt.Sprintf(msgUninstallKept, short(ctx.ConfigDir)))
+
+	return Plan{Actions: []Action{PrintMessage{Msg: strings.Join(lines, "\n")}}}
+}
+
+// PlanUninstall creates a plan that removes every sprout worktree through
+// git, so repositories forget them, then sprout's data, state, caches and
+// trust store, and the completion setup, after backing up the shell config the
+// way install-completion does.
+func PlanUninstall(ctx UninstallContext) Plan {
+	if ctx.DataDir == "" {
+		return errorPlan(ErrEmptySproutRoot)
+	}
+
+	var actions []Action
+	for _, repo := range ctx.Repos {
+		actions = append(actions, PrintMessage{Msg: fmt.Sprintf(msgRemovingRepo, len(repo.Worktrees), repo.MainPath)})
+		for _, wt := range repo.Worktrees {
+			actions = append(actions, RunGitCommand{Dir: repo.MainPath, Args: []string{"worktree", "remove", "--force", wt.Path}})
+		}
+		actions = append(actions, RunGitCommand{Dir: repo.MainPath, Args: []string{"worktree", "prune"}})
+	}
+
+	actions = append(actions, RemoveDirectory{Path: ctx.DataDir})
+	if ctx.StateDir != "" {
+		actions = append(actions, RemoveDirectory{Path: ctx.StateDir})
+	}
+	if ctx.CacheDir != "" {
+		actions = append(actions, RemoveDirectory{Path: ctx.CacheDir})
+	}
+	if ctx.TrustStore != "" {
+		actions = append(actions, RemoveFile{Path: ctx.TrustStore}, RemoveFile{Path: ctx.TrustStore + ".lock"})
+	}
+	if ctx.ShellConfig != "" {
+		actions = append(actions,
+			WriteFile{Path: ctx.ShellConfig + ".backup-sprout", Content: ctx.ShellConfigData, Perm: 0644},
+			WriteFile{Path: ctx.ShellConfig, Content: ctx.ShellConfigRest, Perm: 0644},
+		)
+	}
+
+	return Plan{Actions: append(actions, PrintMessage{Msg: msgRemovedCompleted})}
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func uninstallTestContext() UninstallContext {
+	return UninstallContext{
+		Repos: []UninstallRepo{{MainPath: "/home/me/code/app", Worktrees: []WorktreeDisplayItem{
+			{Branch: "feature", Path: "/home/me/.local/share/sprout/app-1234/feature/app"},
+			{Branch: "wip", Path: "/home/me/.local/share/sprout/app-1234/wip/app", Status: git.WorktreeStatus{Dirty: true}},
+		}}},
+		DataDir:         "/home/me/.local/share/sprout",
+		StateDir:        "/home/me/.local/state/sprout",
+		CacheDir:        "/home/me/.cache/sprout",
+		ConfigDir:       "/home/me/.config/sprout",
+		TrustStore:      "/home/me/.config/sprout/trusted-projects.json",
+		ShellConfig:     "/home/me/.zshrc",
+		ShellConfigData: []byte("export A=1\n\n# setup\n"),
+		ShellConfigRest: []byte("export A=1\n"),
+		Home:            "/home/me",
+	}
+}
+
+func TestPlanUninstallReport(t *testing.T) {
+	t.Parallel()
+
+	plan := PlanUninstallReport(uninstallTestContext())
+	require.Len(t, plan.Actions, 1)
+	assert.Equal(t, PrintMessage{Msg: msgUninstallIntro + `
+   2 worktree(s) of ~/code/app
+      feature  ~/.local/share/sprout/app-1234/feature/app
+      wip  ~/.local/share/sprout/app-1234/wip/app  (uncommitted changes are lost)
+   ~/.local/share/sprout (sprout's data: worktrees and metadata)
+   ~/.local/state/sprout (debug logs and timings)
+   ~/.cache/sprout (caches)
+   ~/.config/sprout/trusted-projects.json (trusted repositories)
+   the completion setup in ~/.zshrc
+
+Branches stay in their repositories, and your config in ~/.config/sprout is kept.`}, plan.Actions[0])
+}
+
+func TestPlanUninstall(t *testing.T) {
+	t.Parallel()
+
+	t.Run("removes worktrees, then sprout's files", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanUninstall(uninstallTestContext())
+		assert.Equal(t, []Action{
+			PrintMessage{Msg: "🧹 Removing 2 worktree(s) of /home/me/code/app"},
+			RunGitCommand{Dir: "/home/me/code/app", Args: []string{"worktree", "remove", "--force", "/home/me/.local/share/sprout/app-1234/feature/app"}},
+			RunGitCommand{Dir: "/home/me/code/app", Args: []string{"worktree", "remove", "--force", "/home/me/.local/share/sprout/app-1234/wip/app"}},
+			RunGitCommand{Dir: "/home/me/code/app", Args: []string{"worktree", "prune"}},
+			RemoveDirectory{Path: "/home/me/.local/share/sprout"},
+			RemoveDirectory{Path: "/home/me/.local/state/sprout"},
+			RemoveDirectory{Path: "/home/me/.cache/sprout"},
+			RemoveFile{Path: "/home/me/.config/sprout/trusted-projects.json"},
+			RemoveFile{Path: "/home/me/.config/sprout/trusted-projects.json.lock"},
+			WriteFile{Path: "/home/me/.zshrc.backup-sprout", Content: []byte("export A=1\n\n# setup\n"), Perm: 0644},
+			WriteFile{Path: "/home/me/.zshrc", Content: []byte("export A=1\n"), Perm: 0644},
+			PrintMessage{Msg: msgRemovedCompleted},
+		}, plan.Actions)
+	})
+
+	t.Run("only the sprout root when nothing else exists", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanUninstall(UninstallContext{DataDir: "/sprout"})
+		assert.Equal(t, []Action{
+			RemoveDirectory{Path: "/sprout"},
+			PrintMessage{Msg: msgRemovedCompleted},
+		}, plan.Actions)
+	})
+
+	t.Run("requires the sprout root", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanUninstall(UninstallContext{})
+		assert.Equal(t, errorPlan(ErrEmptySproutRoot), plan)
+	})
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// WorkspaceFolder is one root of a multi-root .code-workspace file.
+type WorkspaceFolder struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// WorkspaceContext contains all inputs needed to plan the workspace command.
+type WorkspaceContext struct {
+	WorkspacePath string            // .code-workspace file to write or open
+	Folders       []WorkspaceFolder // Worktrees to include; none to open the file as is
+	Existing      []byte            // Current file content, whose other settings are kept
+	Open          bool              // Open the workspace in the editor afterwards
+}
+
+// PlanWorkspaceCommand creates a plan that writes a multi-root workspace
+// with the given worktrees as folders and optionally opens it.
+func PlanWorkspaceCommand(ctx WorkspaceContext) Plan {
+	if ctx.WorkspacePath == "" {
+		return errorPlan(ErrEmptyTargetPath)
+	}
+
+	var actions []Action
+	if len(ctx.Folders) > 0 {
+		content, err := BuildWorkspaceFile(ctx.Existing, ctx.Folders)
+		if err != nil {
+			return errorPlan(err)
+		}
+		actions = append(actions,
+			WriteFile{Path: ctx.WorkspacePath, Content: content, Perm: 0644},
+			PrintMessage{Msg: fmt.Sprintf("🗂  Wrote workspace with %d folder(s) to %s", len(ctx.Folders), ctx.WorkspacePath)},
+		)
+	}
+	if ctx.Open {
+		actions = append(actions, OpenEditor{Path: ctx.WorkspacePath})
+	}
+	return Plan{Actions: actions}
+}
+
+// BuildWorkspaceFile renders a .code-workspace file listing folders. Other
+// keys of an existing file (settings, extensions, launch) are preserved, so
+// regenerating the workspace only replaces its folders.
+func BuildWorkspaceFile(existing []byte, folders []WorkspaceFolder) ([]byte, error) {
+	workspace := map[string]any{}
+	if len(existing) > 0 {
+		if err := json.Unmarshal(existing, &workspace); err != nil {
+			return nil, fmt.Errorf("existing workspace file is not valid JSON (remove comments or delete it): %w", err)
+		}
+	}
+	workspace["folders"] = folders
+	if _, ok := workspace["settings"]; !ok {
+		workspace["settings"] = map[string]any{}
+	}
+
+	data, err := json.MarshalIndent(workspace, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode workspace: %w", err)
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildWorkspaceFile(t *testing.T) {
+	t.Parallel()
+
+	folders := []WorkspaceFolder{
+		{Name: "main", Path: "/repo"},
+		{Name: "feature", Path: "/sprout/feature"},
+	}
+
+	t.Run("new file", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := BuildWorkspaceFile(nil, folders)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"folders": [{"name": "main", "path": "/repo"}, {"name": "feature", "path": "/sprout/feature"}],
+			"settings": {}
+		}`, string(data))
+	})
+
+	t.Run("keeps existing settings", func(t *testing.T) {
+		t.Parallel()
+
+		existing := []byte(`{"folders": [{"path": "/old"}], "settings": {"editor.tabSize": 2}, "extensions": {"recommendations": ["golang.go"]}}`)
+		data, err := BuildWorkspaceFile(existing, folders[:1])
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"folders": [{"name": "main", "path": "/repo"}],
+			"settings": {"editor.tabSize": 2},
+			"extensions": {"recommendations": ["golang.go"]}
+		}`, string(data))
+	})
+
+	t.Run("invalid existing file", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := BuildWorkspaceFile([]byte("// comment\n{}"), folders)
+		assert.ErrorContains(t, err, "not valid JSON")
+	})
+}
+
+func TestPlanWorkspaceCommand(t *testing.T) {
+	t.Parallel()
+
+	t.Run("writes and opens", func(t *testing.T) {
+		t.Parallel()
+
+		plan := PlanWorkspaceCommand(WorkspaceContext{
+			WorkspacePath: "/sprout/repo.code-workspace",
+			Folders:       []WorkspaceFolder{{Name: "main", Path: "/repo"}},
+			Open:          true,
+		})
+
+		require.Len(t, plan.Actions, 3)
+		write, ok := plan.Actions[0].(WriteFile)
+		require.True(t, ok)
+		assert.Equal(t, "/sprout/repo.code-workspace", write.Path)
+		assert.Contains(t, string(write.Content), `"path": "/repo"`)
+		assert.Equal(t, PrintMessage{Msg: "🗂  Wrote workspace with 1 folder(s) to /sprout/repo.code-workspace"}, plan.Actions[1])
+		assert.Equal(t, OpenEditor{Path: "/sprout/repo.code-workspace"}, plan.Actions[2])
+	})
+
+	t.Run("opens an existing file as is", func(t *testing.T) {
+		t.Parallel()
+
+		plan := PlanWorkspaceCommand(WorkspaceContext{WorkspacePath: "/sprout/repo.code-workspace", Open: true})
+		assert.Equal(t, []Action{OpenEditor{Path: "/sprout/repo.code-workspace"}}, plan.Actions)
+	})
+
+	t.Run("invalid existing file", func(t *testing.T) {
+		t.Parallel()
+
+		plan := PlanWorkspaceCommand(WorkspaceContext{
+			WorkspacePath: "/sprout/repo.code-workspace",
+			Folders:       []WorkspaceFolder{{Name: "main", Path: "/repo"}},
+			Existing:      []byte("{"),
+		})
+		assert.IsType(t, PrintError{}, plan.Actions[0])
+	})
+}
@@ -3,38 +3,139 @@
 package core
 
 import (
+	"fmt"
 	"path/filepath"
 	"strings"
 
 	"github.com/m44rten1/sprout/internal/git"
 )
 
-// FilterSproutWorktrees returns worktrees located under the given sprout root.
+// FilterSproutWorktrees returns worktrees located under the given sprout root,
+// plus any adopted worktrees (created outside sprout, see `sprout adopt`).
 // Worktrees at the root level itself are excluded (must be descendants).
-func FilterSproutWorktrees(worktrees []git.Worktree, sproutRoot string) []git.Worktree {
+func FilterSproutWorktrees(worktrees []git.Worktree, sproutRoot string, adopted ...string) []git.Worktree {
 	filtered := make([]git.Worktree, 0, len(worktrees))
 	for _, wt := range worktrees {
-		if IsUnderSproutRoot(wt.Path, sproutRoot) {
+		if IsSproutWorktree(wt.Path, sproutRoot, adopted) {
 			filtered = append(filtered, wt)
 		}
 	}
 	return filtered
 }
 
-// FindWorktreeByBranch finds the first worktree under the sprout root matching the given branch.
+// FindWorktreeByBranch finds the first sprout-managed worktree matching the given branch.
 // Returns the worktree path and true if found, empty string and false otherwise.
-// Empty branch name never matches (excludes detached HEAD worktrees).
-func FindWorktreeByBranch(worktrees []git.Worktree, sproutRoot string, branch string) (string, bool) {
-	if branch == "" {
-		return "", false
+// The branch may carry a remote prefix or differ in case (see
+// MatchBranchName). Detached HEAD worktrees under sproutRoot match by their
+// label instead (see DetachedLabel). Empty branch name never matches.
+func FindWorktreeByBranch(worktrees []git.Worktree, sproutRoot string, branch string, adopted ...string) (string, bool) {
+	if i := MatchBranchName(worktreeNames(worktrees, sproutRoot, adopted), branch); i >= 0 {
+		return worktrees[i].Path, true
 	}
+	return "", false
+}
 
-	for _, wt := range worktrees {
-		if wt.Branch == branch && IsUnderSproutRoot(wt.Path, sproutRoot) {
-			return wt.Path, true
+// MatchWorktreesByBranch returns the sprout-managed worktrees an abbreviated
+// branch names, so "ABC-1234" finds feature/ABC-1234-long-description: the
+// match if there is one (see FindWorktreeByBranch), otherwise every worktree
+// whose branch starts with query, otherwise every one whose branch contains
+// it, ignoring case. More than one match is ambiguous (see
+// AmbiguousBranchError).
+func MatchWorktreesByBranch(worktrees []git.Worktree, sproutRoot, query string, adopted ...string) []git.Worktree {
+	if query == "" {
+		return nil
+	}
+	names := worktreeNames(worktrees, sproutRoot, adopted)
+	if i := MatchBranchName(names, query); i >= 0 {
+		return []git.Worktree{worktrees[i]}
+	}
+
+	query = strings.ToLower(NormalizeBranchArg(query))
+	var prefixed, containing []git.Worktree
+	for i, wt := range worktrees {
+		name := strings.ToLower(names[i])
+		switch {
+		case name == "":
+		case strings.HasPrefix(name, query):
+			prefixed = append(prefixed, wt)
+		case strings.Contains(name, query):
+			containing = append(containing, wt)
 		}
 	}
-	return "", false
+	if len(prefixed) > 0 {
+		return prefixed
+	}
+	return containing
+}
+
+// AmbiguousBranchError describes an abbreviated branch that matches more
+// than one worktree, listing their branches.
+func AmbiguousBranchError(query string, matches []git.Worktree) error {
+	names := make([]string, len(matches))
+	for i, wt := range matches {
+		names[i] = wt.Branch
+		if names[i] == "" {
+			names[i] = DetachedLabel(wt.Path)
+		}
+	}
+	return fmt.Errorf("'%s' matches %d worktrees: %s; give more of the branch name", query, len(matches), strings.Join(names, ", "))
+}
+
+// worktreeNames returns the name of each worktree (see worktreeName).
+func worktreeNames(worktrees []git.Worktree, sproutRoot string, adopted []string) []string {
+	names := make([]string, len(worktrees))
+	for i, wt := range worktrees {
+		names[i] = worktreeName(wt, sproutRoot, adopted)
+	}
+	return names
+}
+
+// worktreeName returns the name a sprout-managed worktree is found by: its
+// branch, or the label of a detached HEAD under sproutRoot. It is empty for
+// other worktrees.
+func worktreeName(wt git.Worktree, sproutRoot string, adopted []string) string {
+	switch {
+	case wt.Branch != "" && IsSproutWorktree(wt.Path, sproutRoot, adopted):
+		return wt.Branch
+	case wt.Branch == "" && IsUnderSproutRoot(wt.Path, sproutRoot):
+		return DetachedLabel(wt.Path)
+	}
+	return ""
+}
+
+// DetachedLabel returns the label of a detached HEAD worktree under the
+// sprout root: the name of the directory that holds it, where a branch
+// worktree has its branch (see 'sprout add --detach').
+func DetachedLabel(path string) string {
+	return filepath.Base(filepath.Dir(filepath.Clean(path)))
+}
+
+// ShortCommit abbreviates a commit hash for display.
+func ShortCommit(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// IsSproutWorktree reports whether path is managed by sprout: either located
+// under sproutRoot or listed in adopted.
+func IsSproutWorktree(path, sproutRoot string, adopted []string) bool {
+	return IsUnderSproutRoot(path, sproutRoot) || IsAdopted(path, adopted)
+}
+
+// IsAdopted reports whether path is one of the adopted worktree paths.
+func IsAdopted(path string, adopted []string) bool {
+	if path == "" {
+		return false
+	}
+	cleaned := filepath.Clean(path)
+	for _, a := range adopted {
+		if filepath.Clean(a) == cleaned {
+			return true
+		}
+	}
+	return false
 }
 
 // IsUnderSproutRoot reports whether path is a descendant of sproutRoot.
@@ -161,6 +161,26 @@ func TestFindWorktreeByBranch(t *testing.T) {
 			wantPath:   "",
 			wantFound:  false,
 		},
+		{
+			name: "finds detached HEAD worktrees by label",
+			worktrees: []git.Worktree{
+				MakeWorktree("/home/user/.sprout/myrepo-1a2b/release/myrepo", ""),
+			},
+			sproutRoot: "/home/user/.sprout",
+			branch:     "release",
+			wantPath:   "/home/user/.sprout/myrepo-1a2b/release/myrepo",
+			wantFound:  true,
+		},
+		{
+			name: "labels only count under the sprout root",
+			worktrees: []git.Worktree{
+				MakeWorktree("/home/user/code/release/myrepo", ""),
+			},
+			sproutRoot: "/home/user/.sprout",
+			branch:     "release",
+			wantPath:   "",
+			wantFound:  false,
+		},
 		{
 			name:       "returns false for empty sprout roots",
 			worktrees:  []git.Worktree{MakeWorktree("/home/user/.sprout/myrepo/feature", "feature")},
@@ -211,6 +231,46 @@ func TestFindWorktreeByBranch(t *testing.T) {
 	}
 }
 
+func TestMatchWorktreesByBranch(t *testing.T) {
+	t.Parallel()
+
+	worktrees := []git.Worktree{
+		MakeWorktree("/repos/app", "main"),
+		MakeWorktree("/sprout/app/feature/ABC-1234-login/app", "feature/ABC-1234-login"),
+		MakeWorktree("/sprout/app/feature/ABC-1250-logout/app", "feature/ABC-1250-logout"),
+		MakeWorktree("/sprout/app/fix/app", "fix"),
+		MakeWorktree("/sprout/app/fix-typo/app", "fix-typo"),
+		MakeWorktree("/sprout/app/release/app", ""),
+	}
+	branches := func(query string) []string {
+		var names []string
+		for _, wt := range MatchWorktreesByBranch(worktrees, "/sprout", query) {
+			names = append(names, wt.Branch)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"fix"}, branches("fix"), "an exact match wins over prefixes")
+	assert.Equal(t, []string{"feature/ABC-1234-login", "feature/ABC-1250-logout"}, branches("feature/ABC"), "prefixes")
+	assert.Equal(t, []string{"feature/ABC-1234-login"}, branches("1234"), "substrings without a prefix match")
+	assert.Equal(t, []string{"fix-typo"}, branches("typo"))
+	assert.Equal(t, []string{"fix"}, branches("origin/FIX"), "remote prefix and case")
+	assert.Equal(t, []string{"feature/ABC-1234-login"}, branches("abc-1234"))
+	assert.Equal(t, []string{""}, branches("rel"), "detached worktrees by label")
+	assert.Empty(t, branches("main"), "only sprout-managed worktrees")
+	assert.Empty(t, branches(""))
+}
+
+func TestAmbiguousBranchError(t *testing.T) {
+	t.Parallel()
+
+	err := AmbiguousBranchError("fe", []git.Worktree{
+		MakeWorktree("/sprout/app/feat/app", "feat"),
+		MakeWorktree("/sprout/app/fe-debug/app", ""),
+	})
+	assert.EqualError(t, err, "'fe' matches 2 worktrees: feat, fe-debug; give more of the branch name")
+}
+
 func TestIsUnderSproutRoot(t *testing.T) {
 	t.Parallel()
 
@@ -297,3 +357,27 @@ func TestIsUnderSproutRoot(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterSproutWorktrees_Adopted(t *testing.T) {
+	t.Parallel()
+
+	worktrees := []git.Worktree{
+		MakeWorktree("/home/user/repos/myrepo", "main"),
+		MakeWorktree("/home/user/.sprout/myrepo/feature-1", "feature-1"),
+		MakeWorktree("/tmp/manual", "manual"),
+		MakeWorktree("/tmp/other", "other"),
+	}
+
+	got := FilterSproutWorktrees(worktrees, "/home/user/.sprout", "/tmp/manual/")
+	assert.Equal(t, []git.Worktree{
+		MakeWorktree("/home/user/.sprout/myrepo/feature-1", "feature-1"),
+		MakeWorktree("/tmp/manual", "manual"),
+	}, got)
+
+	path, found := FindWorktreeByBranch(worktrees, "/home/user/.sprout", "manual", "/tmp/manual")
+	assert.True(t, found)
+	assert.Equal(t, "/tmp/manual", path)
+
+	_, found = FindWorktreeByBranch(worktrees, "/home/user/.sprout", "other", "/tmp/manual")
+	assert.False(t, found, "worktrees that are neither under the root nor adopted are not sprout-managed")
+}
@@ -0,0 +1,225 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/style"
+)
+
+// Message constants for the ws commands
+const (
+	msgNoWorkspaces       = "No workspaces. Create one with 'sprout ws create <name> <repo>...'."
+	msgWsHasWorktree      = "%s already has a worktree of %s: %s"
+	msgWsCreated          = "🌱 Workspace %s: %s"
+	msgWsNoWorktree       = "%s has no worktree of %s."
+	msgWsRemoved          = "Removed workspace %s."
+	errWsNoRepos          = "a workspace needs at least one repository"
+	errWsNoneHaveWorktree = "no repository of workspace %s has a worktree of it; run 'sprout ws create %s' to add them"
+	errWsDirty            = "uncommitted changes in the worktree of %s; commit or stash them, or pass --force"
+)
+
+// WsMember is a repository of a workspace (see 'sprout ws').
+type WsMember struct {
+	Repo         string             // Its name, as 'sprout repos' prints it
+	MainPath     string             // Its main worktree
+	WorktreePath string             // Its worktree of the workspace's branch; empty if it has none
+	Status       git.WorktreeStatus // Of the worktree; only set for list --workspace
+}
+
+// RepoWorkspace is a workspace with what each of its repositories has of it.
+// The workspace's name is the branch of its worktrees.
+type RepoWorkspace struct {
+	Name    string
+	Members []WsMember
+}
+
+// mainPaths returns the main worktree paths of the workspace's members.
+func (ws RepoWorkspace) mainPaths() []string {
+	paths := make([]string, len(ws.Members))
+	for i, m := range ws.Members {
+		paths[i] = m.MainPath
+	}
+	return paths
+}
+
+// repoNames returns the names of the workspace's members.
+func (ws RepoWorkspace) repoNames() []string {
+	names := make([]string, len(ws.Members))
+	for i, m := range ws.Members {
+		names[i] = m.Repo
+	}
+	return names
+}
+
+// WsListContext contains all inputs needed to plan 'sprout ws list'.
+type WsListContext struct {
+	Workspaces []RepoWorkspace
+	Home       string // For shortening paths
+}
+
+// PlanWsListCommand creates a plan that prints each workspace with the
+// worktree each of its repositories has of it.
+func PlanWsListCommand(ctx WsListContext) Plan {
+	if len(ctx.Workspaces) == 0 {
+		return Plan{Actions: []Action{PrintMessage{Msg: msgNoWorkspaces}}}
+	}
+	var lines []string
+	for i, ws := range ctx.Workspaces {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, ws.Name)
+		width := 0
+		for _, m := range ws.Members {
+			width = max(width, len(m.Repo))
+		}
+		for _, m := range ws.Members {
+			path := "(no worktree)"
+			if m.WorktreePath != "" {
+				path = ShortenPathWithHome(m.WorktreePath, ctx.Home)
+			}
+			lines = append(lines, fmt.Sprintf("  %-*s  %s", width, m.Repo, path))
+		}
+	}
+	return Plan{Actions: []Action{PrintMessage{Msg: strings.Join(lines, "\n")}}}
+}
+
+// FormatWorkspaceList formats the workspaces for list --workspace: a section
+// per workspace with a line per repository, giving the path and status of
+// its worktree.
+func FormatWorkspaceList(workspaces []RepoWorkspace, home string, counts bool) string {
+	if len(workspaces) == 0 {
+		return "\n" + msgNoWorkspaces
+	}
+	var lines []string
+	for i, ws := range workspaces {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "", "▾ "+style.Heading(ws.Name))
+		width := 0
+		for _, m := range ws.Members {
+			width = max(width, len(m.Repo))
+		}
+		for j, m := range ws.Members {
+			prefix := "├── "
+			if j == len(ws.Members)-1 {
+				prefix = "└── "
+			}
+			line := prefix + style.Bold(fmt.Sprintf("%-*s", width, m.Repo)) + "  "
+			if m.WorktreePath == "" {
+				lines = append(lines, line+style.Gray("(no worktree)"))
+				continue
+			}
+			line += style.Gray(ShortenPathWithHome(m.WorktreePath, home))
+			if emojis := BuildStatusEmojis(m.Status, counts); emojis != "" {
+				line += " " + emojis
+			}
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WsCreateContext contains all inputs needed to plan 'sprout ws create'.
+type WsCreateContext struct {
+	Workspace RepoWorkspace
+	// Adds are the worktrees to create, for the members without one
+	Adds []AddContext
+}
+
+// PlanWsCreateCommand creates a plan that records the workspace, then adds
+// the missing worktrees one repository at a time. Members that already
+// have a worktree of the branch keep it, so creating a workspace again
+// finishes one that failed halfway, or adds repositories to it.
+func PlanWsCreateCommand(ctx WsCreateContext) Plan {
+	ws := ctx.Workspace
+	if len(ws.Members) == 0 {
+		return errorPlan(errors.New(errWsNoRepos))
+	}
+	if err := ValidateBranchName(ws.Name); err != nil {
+		return errorPlan(fmt.Errorf("invalid workspace name: %w", err))
+	}
+
+	// Fail before creating anything when a repository can't take the worktree
+	plans := make([]Plan, len(ctx.Adds))
+	for i, add := range ctx.Adds {
+		plans[i] = PlanAddCommand(add)
+		if err := PlanErr(plans[i]); err != nil {
+			return errorPlan(fmt.Errorf("%s: %w", add.RepoRoot, err))
+		}
+	}
+
+	actions := []Action{SaveWorkspace{Name: ws.Name, Repos: ws.mainPaths()}}
+	for _, m := range ws.Members {
+		if m.WorktreePath != "" {
+			actions = append(actions, PrintMessage{Msg: fmt.Sprintf(msgWsHasWorktree, m.Repo, ws.Name, m.WorktreePath)})
+		}
+	}
+	for _, plan := range plans {
+		actions = append(actions, plan.Actions...)
+	}
+	actions = append(actions, PrintMessage{Msg: fmt.Sprintf(msgWsCreated, ws.Name, strings.Join(ws.repoNames(), ", "))})
+	return Plan{Actions: actions}
+}
+
+// WsOpenContext contains all inputs needed to plan 'sprout ws open'.
+type WsOpenContext struct {
+	Workspace RepoWorkspace
+	Opens     []OpenContext // One for each member with a worktree
+}
+
+// PlanWsOpenCommand creates a plan that opens the worktree of every
+// repository in the workspace, saying which ones have none.
+func PlanWsOpenCommand(ctx WsOpenContext) Plan {
+	ws := ctx.Workspace
+	if len(ctx.Opens) == 0 {
+		return errorPlan(fmt.Errorf(errWsNoneHaveWorktree, ws.Name, ws.Name))
+	}
+	var actions []Action
+	for _, m := range ws.Members {
+		if m.WorktreePath == "" {
+			actions = append(actions, PrintMessage{Msg: fmt.Sprintf(msgWsNoWorktree, m.Repo, ws.Name)})
+		}
+	}
+	for _, open := range ctx.Opens {
+		plan := PlanOpenCommand(open)
+		if err := PlanErr(plan); err != nil {
+			return plan
+		}
+		actions = append(actions, plan.Actions...)
+	}
+	return Plan{Actions: actions}
+}
+
+// WsRemoveContext contains all inputs needed to plan 'sprout ws remove'.
+type WsRemoveContext struct {
+	Workspace RepoWorkspace
+	Removes   []RemoveContext // One for each member with a worktree
+	Dirty     []string        // Members whose worktree has uncommitted changes
+	Force     bool
+}
+
+// PlanWsRemoveCommand creates a plan that removes the workspace's worktrees
+// and then forgets the workspace. Without Force, uncommitted changes in any
+// of them keep all of them, so the workspace stays whole.
+func PlanWsRemoveCommand(ctx WsRemoveContext) Plan {
+	if len(ctx.Dirty) > 0 && !ctx.Force {
+		return errorPlan(fmt.Errorf(errWsDirty, strings.Join(ctx.Dirty, ", ")))
+	}
+	var actions []Action
+	for _, remove := range ctx.Removes {
+		plan := PlanRemoveCommand(remove)
+		if err := PlanErr(plan); err != nil {
+			return errorPlan(fmt.Errorf("%s: %w", remove.RepoRoot, err))
+		}
+		actions = append(actions, plan.Actions...)
+	}
+	return Plan{Actions: append(actions,
+		DeleteWorkspace{Name: ctx.Workspace.Name},
+		PrintMessage{Msg: fmt.Sprintf(msgWsRemoved, ctx.Workspace.Name)},
+	)}
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanWsListCommand(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no workspaces", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanWsListCommand(WsListContext{})
+		assert.Equal(t, []Action{PrintMessage{Msg: msgNoWorkspaces}}, plan.Actions)
+	})
+
+	t.Run("members with and without a worktree", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanWsListCommand(WsListContext{
+			Home: "/home/me",
+			Workspaces: []RepoWorkspace{{Name: "feature-x", Members: []WsMember{
+				{Repo: "api", MainPath: "/code/api", WorktreePath: "/home/me/.local/share/sprout/api-1/feature-x/api"},
+				{Repo: "infra", MainPath: "/code/infra"},
+			}}},
+		})
+		assert.Equal(t, []Action{PrintMessage{Msg: "" +
+			"feature-x\n" +
+			"  api    ~/.local/share/sprout/api-1/feature-x/api\n" +
+			"  infra  (no worktree)"}}, plan.Actions)
+	})
+}
+
+func TestPlanWsCreateCommand(t *testing.T) {
+	t.Parallel()
+	ws := RepoWorkspace{Name: "feature-x", Members: []WsMember{
+		{Repo: "api", MainPath: "/code/api", WorktreePath: "/sprout/api/feature-x/api"},
+		{Repo: "web", MainPath: "/code/web"},
+	}}
+	add := AddContext{
+		Branch:       "feature-x",
+		RepoRoot:     "/code/web",
+		WorktreePath: "/sprout/web/feature-x/web",
+		Config:       &config.Config{},
+		NoOpen:       true,
+	}
+
+	t.Run("records the workspace and adds the missing worktrees", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanWsCreateCommand(WsCreateContext{Workspace: ws, Adds: []AddContext{add}})
+		require.NotEmpty(t, plan.Actions)
+		assert.Equal(t, SaveWorkspace{Name: "feature-x", Repos: []string{"/code/api", "/code/web"}}, plan.Actions[0])
+		assert.Equal(t, PrintMessage{Msg: "api already has a worktree of feature-x: /sprout/api/feature-x/api"}, plan.Actions[1])
+		assert.Contains(t, plan.Actions, RunGitCommand{Dir: "/code/web", Args: []string{"worktree", "add", "/sprout/web/feature-x/web", "-b", "feature-x", "--no-track", "HEAD"}})
+		assert.Equal(t, PrintMessage{Msg: "🌱 Workspace feature-x: api, web"}, plan.Actions[len(plan.Actions)-1])
+	})
+
+	t.Run("fails before creating anything", func(t *testing.T) {
+		t.Parallel()
+		broken := add
+		broken.Branch = ""
+		plan := PlanWsCreateCommand(WsCreateContext{Workspace: ws, Adds: []AddContext{broken}})
+		require.Error(t, PlanErr(plan))
+		assert.Contains(t, PlanErr(plan).Error(), "/code/web: ")
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanWsCreateCommand(WsCreateContext{Workspace: RepoWorkspace{Name: "-x", Members: ws.Members}})
+		assert.ErrorContains(t, PlanErr(plan), "invalid workspace name")
+	})
+
+	t.Run("no repositories", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanWsCreateCommand(WsCreateContext{Workspace: RepoWorkspace{Name: "feature-x"}})
+		assert.EqualError(t, PlanErr(plan), errWsNoRepos)
+	})
+}
+
+func TestPlanWsOpenCommand(t *testing.T) {
+	t.Parallel()
+	ws := RepoWorkspace{Name: "feature-x", Members: []WsMember{
+		{Repo: "api", MainPath: "/code/api", WorktreePath: "/sprout/api/feature-x/api"},
+		{Repo: "web", MainPath: "/code/web"},
+	}}
+
+	t.Run("opens each worktree", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanWsOpenCommand(WsOpenContext{Workspace: ws, Opens: []OpenContext{{
+			TargetPath:       "/sprout/api/feature-x/api",
+			RepoRoot:         "/sprout/api/feature-x/api",
+			MainWorktreePath: "/code/api",
+			Config:           &config.Config{},
+		}}})
+		assert.Equal(t, PrintMessage{Msg: "web has no worktree of feature-x."}, plan.Actions[0])
+		assert.Contains(t, plan.Actions, OpenEditor{Path: "/sprout/api/feature-x/api"})
+	})
+
+	t.Run("no worktrees", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanWsOpenCommand(WsOpenContext{Workspace: ws})
+		assert.ErrorContains(t, PlanErr(plan), "no repository of workspace feature-x has a worktree of it")
+	})
+}
+
+func TestPlanWsRemoveCommand(t *testing.T) {
+	t.Parallel()
+	ws := RepoWorkspace{Name: "feature-x", Members: []WsMember{
+		{Repo: "api", MainPath: "/code/api", WorktreePath: "/sprout/api/feature-x/api"},
+	}}
+	remove := RemoveContext{
+		RepoRoot:   "/code/api",
+		SproutRoot: "/sprout/api",
+		TargetPath: "/sprout/api/feature-x/api",
+	}
+
+	t.Run("removes the worktrees, then the workspace", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanWsRemoveCommand(WsRemoveContext{Workspace: ws, Removes: []RemoveContext{remove}})
+		assert.Equal(t, RunGitCommand{Dir: "/code/api", Args: []string{"worktree", "remove", "/sprout/api/feature-x/api"}}, plan.Actions[0])
+		assert.Equal(t, []Action{
+			DeleteWorkspace{Name: "feature-x"},
+			PrintMessage{Msg: "Removed workspace feature-x."},
+		}, plan.Actions[len(plan.Actions)-2:])
+	})
+
+	t.Run("uncommitted changes", func(t *testing.T) {
+		t.Parallel()
+		plan := PlanWsRemoveCommand(WsRemoveContext{Workspace: ws, Removes: []RemoveContext{remove}, Dirty: []string{"api"}})
+		assert.EqualError(t, PlanErr(plan), "uncommitted changes in the worktree of api; commit or stash them, or pass --force")
+	})
+
+	t.Run("forced", func(t *testing.T) {
+		t.Parallel()
+		forced := remove
+		forced.Force = true
+		plan := PlanWsRemoveCommand(WsRemoveContext{Workspace: ws, Removes: []RemoveContext{forced}, Dirty: []string{"api"}, Force: true})
+		assert.NoError(t, PlanErr(plan))
+		assert.Equal(t, RunGitCommand{Dir: "/code/api", Args: []string{"worktree", "remove", "--force", "/sprout/api/feature-x/api"}}, plan.Actions[0])
+	})
+}
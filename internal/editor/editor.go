@@ -1,105 +1,311 @@
 package editor
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
-// Open opens the given path in an editor.
-// Priority order:
-//  1. $SPROUT_EDITOR - sprout-specific override
-//  2. $EDITOR - standard Unix convention
-//  3. Platform defaults (Cursor, Code, system defaults)
-func Open(path string) error {
-	// Check SPROUT_EDITOR first (highest priority)
-	if editor := os.Getenv("SPROUT_EDITOR"); editor != "" {
-		return openWithCommand(editor, path)
+// ErrNotFound is the error of an attempt whose command is not installed.
+var ErrNotFound = errors.New("command not found")
+
+// launchTimeout is how long an editor command may run before sprout takes
+// it as launched and stops waiting, leaving it running: one that waits for
+// its window to close (code --wait), or a launcher that stays up. An editor
+// that fails within it is reported, and the next one tried.
+var launchTimeout = 10 * time.Second
+
+// Attempt is one editor that was tried and why it failed.
+type Attempt struct {
+	Editor string // Command line as configured, e.g. "code --wait"
+	Err    error
+}
+
+// OpenError is returned when no editor could open a path. It lists every
+// editor that was tried, in order.
+type OpenError struct {
+	Path     string
+	Attempts []Attempt
+}
+
+func (e *OpenError) Error() string {
+	var b strings.Builder
+	b.WriteString("no working editor found")
+	if len(e.Attempts) == 0 {
+		fmt.Fprintf(&b, " (no defaults on %s)", runtime.GOOS)
+	}
+	for _, a := range e.Attempts {
+		fmt.Fprintf(&b, "\n  %s: %v", a.Editor, a.Err)
 	}
+	b.WriteString("\nSet $SPROUT_EDITOR, or editor_fallbacks in ~/.config/sprout/config.yml")
+	return b.String()
+}
 
-	// Check EDITOR second (standard Unix convention)
-	if editor := os.Getenv("EDITOR"); editor != "" {
-		return openWithCommand(editor, path)
+// Unwrap returns the errors of the attempts.
+func (e *OpenError) Unwrap() []error {
+	errs := make([]error, len(e.Attempts))
+	for i, a := range e.Attempts {
+		errs[i] = a.Err
 	}
+	return errs
+}
 
-	// Fall back to platform-aware defaults
-	return openWithPlatformDefaults(path)
+// Open opens the given path in the first editor that works.
+// Order:
+//  1. $SPROUT_EDITOR - sprout-specific override, else $EDITOR
+//  2. fallbacks (editor_fallbacks in the user config), in order
+//  3. Platform defaults (Cursor, Code, system defaults), unless 1 or 2 is set
+//
+// If none works, the error is an *OpenError listing what was tried.
+func Open(path string, fallbacks ...string) error {
+	return openFirst(path, candidates(platformEditors(), fallbacks), func(string) []string {
+		return []string{path}
+	})
+}
+
+// candidates returns the editor commands to try, in order. The platform
+// defaults are only used when the user configured nothing, so an explicit
+// choice never silently ends up in another editor.
+func candidates(defaults, fallbacks []string) []string {
+	var list []string
+	for _, env := range []string{"SPROUT_EDITOR", "EDITOR"} {
+		if editor := os.Getenv(env); editor != "" {
+			list = append(list, editor)
+			break
+		}
+	}
+	if len(fallbacks) > 0 {
+		return append(list, fallbacks...)
+	}
+	if len(list) > 0 {
+		return list
+	}
+	return defaults
+}
+
+// openFirst runs each editor with its arguments until one succeeds.
+func openFirst(path string, editors []string, args func(editor string) []string) error {
+	openErr := &OpenError{Path: path}
+	for _, editor := range editors {
+		err := openWithArgs(editor, args(editor)...)
+		if err == nil {
+			return nil
+		}
+		openErr.Attempts = append(openErr.Attempts, Attempt{Editor: editor, Err: err})
+	}
+	return openErr
 }
 
 // openWithCommand executes the given editor command with the path.
 // Supports space-separated arguments (e.g., "code --wait").
 func openWithCommand(editor, path string) error {
-	// Split command and args (simple split on spaces)
-	parts := strings.Fields(editor)
+	return openWithArgs(editor, path)
+}
+
+// openWithArgs executes the given editor command with extra arguments
+// appended after the ones in the command itself, waiting for it at most
+// launchTimeout.
+func openWithArgs(editor string, extra ...string) error {
+	parts := splitCommand(editor)
 	if len(parts) == 0 {
 		return fmt.Errorf("empty editor command")
 	}
 
 	cmd := parts[0]
-	args := append(parts[1:], path)
+	args := append(parts[1:], extra...)
 
 	// Check if command exists
 	if _, err := exec.LookPath(cmd); err != nil {
-		return fmt.Errorf("editor command not found: %s", cmd)
+		return fmt.Errorf("editor %w: %s", ErrNotFound, cmd)
 	}
 
-	return exec.Command(cmd, args...).Run()
+	c := exec.Command(cmd, args...)
+	if err := c.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() { done <- c.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(launchTimeout):
+		return nil
+	}
 }
 
-// openWithPlatformDefaults tries platform-specific editor defaults.
-func openWithPlatformDefaults(path string) error {
-	switch runtime.GOOS {
-	case "darwin":
-		// macOS: Try Cursor app, then cursor/code commands, then system open
-		if err := exec.Command("open", "-a", "Cursor", path).Run(); err == nil {
-			return nil
-		}
-		if _, err := exec.LookPath("cursor"); err == nil {
-			if err := exec.Command("cursor", path).Run(); err == nil {
-				return nil
-			}
-		}
-		if _, err := exec.LookPath("code"); err == nil {
-			if err := exec.Command("code", path).Run(); err == nil {
-				return nil
-			}
+// splitCommand splits an editor command on spaces. A word of two double
+// quotes is an empty argument, as cmd's start takes for the window title.
+func splitCommand(editor string) []string {
+	parts := strings.Fields(editor)
+	for i, part := range parts {
+		if part == `""` {
+			parts[i] = ""
 		}
-		return exec.Command("open", path).Run()
+	}
+	return parts
+}
 
+// platformEditors returns the editors tried when none is configured, ending
+// with the system's default handler.
+func platformEditors() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"open -a Cursor", "cursor", "code", "open"}
 	case "linux":
-		// Linux: Try cursor/code commands, then xdg-open
-		if _, err := exec.LookPath("cursor"); err == nil {
-			if err := exec.Command("cursor", path).Run(); err == nil {
-				return nil
-			}
+		return []string{"cursor", "code", "xdg-open"}
+	case "windows":
+		// cmd's start takes its first quoted argument as the window title
+		return []string{"cursor", "code", `cmd /C start ""`}
+	default:
+		return nil
+	}
+}
+
+// Location is a file to open inside a worktree, optionally at a line and
+// column (1-based; 0 means unset).
+type Location struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// lineStyle is how an editor is told which line to open a file at.
+type lineStyle int
+
+const (
+	lineStyleNone      lineStyle = iota // Unknown editor: open the file, ignore the line
+	lineStyleGoto                       // code -g file:line:column
+	lineStyleSuffix                     // subl file:line:column
+	lineStyleJetBrains                  // idea --line N --column C file
+	lineStylePlus                       // vim +N file
+	lineStyleEmacs                      // emacs +N:C file
+	lineStyleNano                       // nano +N,C file
+)
+
+// editorCapabilities describes what an editor's command line accepts.
+type editorCapabilities struct {
+	line    lineStyle
+	project bool // Takes the worktree too, so the file opens in the right window
+}
+
+// knownEditors maps editor executables to their capabilities.
+var knownEditors = map[string]editorCapabilities{
+	"code":          {line: lineStyleGoto, project: true},
+	"code-insiders": {line: lineStyleGoto, project: true},
+	"codium":        {line: lineStyleGoto, project: true},
+	"cursor":        {line: lineStyleGoto, project: true},
+	"windsurf":      {line: lineStyleGoto, project: true},
+	"subl":          {line: lineStyleSuffix, project: true},
+	"zed":           {line: lineStyleSuffix, project: true},
+	"hx":            {line: lineStyleSuffix},
+	"idea":          {line: lineStyleJetBrains},
+	"goland":        {line: lineStyleJetBrains},
+	"pycharm":       {line: lineStyleJetBrains},
+	"webstorm":      {line: lineStyleJetBrains},
+	"phpstorm":      {line: lineStyleJetBrains},
+	"rubymine":      {line: lineStyleJetBrains},
+	"clion":         {line: lineStyleJetBrains},
+	"rider":         {line: lineStyleJetBrains},
+	"studio":        {line: lineStyleJetBrains},
+	"vi":            {line: lineStylePlus},
+	"vim":           {line: lineStylePlus},
+	"nvim":          {line: lineStylePlus},
+	"emacs":         {line: lineStyleEmacs},
+	"emacsclient":   {line: lineStyleEmacs},
+	"nano":          {line: lineStyleNano},
+}
+
+// FileArgs returns the arguments that make the editor command open a file
+// of the worktree at loc's line. Editors sprout does not know just get the
+// file, without the line.
+func FileArgs(command, worktree string, loc Location) []string {
+	var name string
+	if parts := strings.Fields(command); len(parts) > 0 {
+		name = strings.TrimSuffix(filepath.Base(parts[0]), ".exe")
+	}
+	caps := knownEditors[name]
+
+	var args []string
+	if caps.project {
+		args = append(args, worktree)
+	}
+	if loc.Line <= 0 {
+		return append(args, loc.File)
+	}
+
+	position := strconv.Itoa(loc.Line)
+	switch caps.line {
+	case lineStyleGoto, lineStyleSuffix:
+		target := loc.File + ":" + position
+		if loc.Column > 0 {
+			target += ":" + strconv.Itoa(loc.Column)
 		}
-		if _, err := exec.LookPath("code"); err == nil {
-			if err := exec.Command("code", path).Run(); err == nil {
-				return nil
-			}
+		if caps.line == lineStyleGoto {
+			args = append(args, "-g")
 		}
-		if _, err := exec.LookPath("xdg-open"); err == nil {
-			return exec.Command("xdg-open", path).Run()
+		return append(args, target)
+	case lineStyleJetBrains:
+		args = append(args, "--line", position)
+		if loc.Column > 0 {
+			args = append(args, "--column", strconv.Itoa(loc.Column))
 		}
-		return fmt.Errorf("no suitable editor found (tried: cursor, code, xdg-open)")
-
-	case "windows":
-		// Windows: Try cursor/code commands, then cmd start
-		if _, err := exec.LookPath("cursor"); err == nil {
-			if err := exec.Command("cursor", path).Run(); err == nil {
-				return nil
+		return append(args, loc.File)
+	case lineStylePlus:
+		return append(args, "+"+position, loc.File)
+	case lineStyleEmacs, lineStyleNano:
+		if loc.Column > 0 {
+			sep := ":"
+			if caps.line == lineStyleNano {
+				sep = ","
 			}
+			position += sep + strconv.Itoa(loc.Column)
 		}
-		if _, err := exec.LookPath("code"); err == nil {
-			if err := exec.Command("code", path).Run(); err == nil {
-				return nil
-			}
+		return append(args, "+"+position, loc.File)
+	default:
+		return append(args, loc.File)
+	}
+}
+
+// OpenFile opens a file of the worktree in an editor, at loc's line when
+// the editor supports it. The editor is chosen like Open, except that
+// without any configured, Cursor or VS Code are preferred so the line can
+// be honored before the platform default handler gets the file.
+func OpenFile(worktree string, loc Location, fallbacks ...string) error {
+	defaults := []string{"cursor", "code"}
+	for _, editor := range platformEditors() {
+		if !slices.Contains(defaults, editor) {
+			defaults = append(defaults, editor)
 		}
-		// Use cmd.exe with /C start to open the path
-		return exec.Command("cmd", "/C", "start", "", path).Run()
+	}
+	return openFirst(loc.File, candidates(defaults, fallbacks), func(editor string) []string {
+		return FileArgs(editor, worktree, loc)
+	})
+}
 
+// OpenURL opens a URL in the user's web browser.
+// $BROWSER takes precedence over the platform default handler.
+func OpenURL(url string) error {
+	if browser := os.Getenv("BROWSER"); browser != "" {
+		return openWithCommand(browser, url)
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Run()
+	case "linux":
+		if _, err := exec.LookPath("xdg-open"); err != nil {
+			return fmt.Errorf("no browser found (set $BROWSER or install xdg-open)")
+		}
+		return exec.Command("xdg-open", url).Run()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Run()
 	default:
 		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
 	}
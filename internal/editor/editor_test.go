@@ -3,8 +3,10 @@ package editor
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestOpenWithCommand(t *testing.T) {
@@ -33,6 +35,24 @@ func TestOpenWithCommand(t *testing.T) {
 	})
 }
 
+func TestOpenWithCommand_LaunchTimeout(t *testing.T) {
+	orig := launchTimeout
+	launchTimeout = 50 * time.Millisecond
+	defer func() { launchTimeout = orig }()
+
+	start := time.Now()
+	assert.NoError(t, openWithCommand("sleep", "5"), "a command still running is taken as launched")
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	assert.Error(t, openWithCommand("false", "/test/path"), "a command failing in time is reported")
+}
+
+func TestSplitCommand(t *testing.T) {
+	assert.Equal(t, []string{"code", "--wait"}, splitCommand("  code   --wait "))
+	assert.Equal(t, []string{"cmd", "/C", "start", ""}, splitCommand(`cmd /C start ""`))
+	assert.Empty(t, splitCommand(" "))
+}
+
 func TestOpen_EnvironmentVariables(t *testing.T) {
 	// Save original environment
 	origSproutEditor := os.Getenv("SPROUT_EDITOR")
@@ -86,3 +106,65 @@ func TestOpen_CommandParsing(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestOpen_Fallbacks(t *testing.T) {
+	t.Run("tries fallbacks after a failing editor", func(t *testing.T) {
+		t.Setenv("SPROUT_EDITOR", "false")
+
+		err := Open("/test/path", "nonexistent-editor-12345", "true")
+		assert.NoError(t, err)
+	})
+
+	t.Run("lists every attempt when all fail", func(t *testing.T) {
+		t.Setenv("SPROUT_EDITOR", "")
+		t.Setenv("EDITOR", "false")
+
+		err := Open("/test/path", "nonexistent-editor-12345")
+
+		var openErr *OpenError
+		require.ErrorAs(t, err, &openErr)
+		require.Len(t, openErr.Attempts, 2, "fallbacks replace the platform defaults")
+		assert.Equal(t, "false", openErr.Attempts[0].Editor)
+		assert.Equal(t, "nonexistent-editor-12345", openErr.Attempts[1].Editor)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Contains(t, err.Error(), "nonexistent-editor-12345: editor command not found")
+	})
+
+	t.Run("an explicit editor is not replaced by platform defaults", func(t *testing.T) {
+		t.Setenv("SPROUT_EDITOR", "nonexistent-editor-12345")
+
+		err := Open("/test/path")
+
+		var openErr *OpenError
+		require.ErrorAs(t, err, &openErr)
+		assert.Len(t, openErr.Attempts, 1)
+	})
+}
+
+func TestFileArgs(t *testing.T) {
+	loc := Location{File: "/wt/main.go", Line: 42, Column: 7}
+
+	tests := []struct {
+		command string
+		loc     Location
+		want    []string
+	}{
+		{"code --wait", loc, []string{"/wt", "-g", "/wt/main.go:42:7"}},
+		{"/usr/local/bin/cursor", Location{File: "/wt/main.go", Line: 42}, []string{"/wt", "-g", "/wt/main.go:42"}},
+		{"code", Location{File: "/wt/main.go"}, []string{"/wt", "/wt/main.go"}},
+		{"subl", loc, []string{"/wt", "/wt/main.go:42:7"}},
+		{"hx", loc, []string{"/wt/main.go:42:7"}},
+		{"idea", loc, []string{"--line", "42", "--column", "7", "/wt/main.go"}},
+		{"goland.exe", Location{File: "/wt/main.go", Line: 42}, []string{"--line", "42", "/wt/main.go"}},
+		{"nvim", loc, []string{"+42", "/wt/main.go"}},
+		{"emacsclient -n", loc, []string{"+42:7", "/wt/main.go"}},
+		{"nano", loc, []string{"+42,7", "/wt/main.go"}},
+		{"ed", loc, []string{"/wt/main.go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			assert.Equal(t, tt.want, FileArgs(tt.command, "/wt", tt.loc))
+		})
+	}
+}
@@ -1,10 +1,40 @@
 package effects
 
 import (
+	"errors"
 	"os"
+	"time"
 
 	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/events"
 	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/sprout"
+	"github.com/m44rten1/sprout/internal/telemetry"
+	"github.com/m44rten1/sprout/internal/tickets"
+	"github.com/m44rten1/sprout/internal/timing"
+)
+
+// ErrNonInteractive is wrapped by the errors of prompts and selections that
+// were refused because sprout runs non-interactively (--non-interactive,
+// SPROUT_NON_INTERACTIVE=1, or stdin is not a terminal).
+var ErrNonInteractive = errors.New("running non-interactively")
+
+// ErrTrustDeclined is returned by PromptTrustRepo when the user answers no.
+var ErrTrustDeclined = errors.New("repository not trusted: user declined")
+
+// Exit codes other than 0 and the 1 of a failed command, so scripts and CI
+// jobs can tell what went wrong. They never change meaning.
+const (
+	// ExitUsage is the exit code of a command given unknown flags or the
+	// wrong number of arguments.
+	ExitUsage = 2
+	// ExitNonInteractive is the exit code of a command that needed input
+	// it was not allowed to ask for.
+	ExitNonInteractive = 3
+	// ExitLocked is the exit code of a command that gave up waiting for
+	// another sprout operation on the repository.
+	ExitLocked = 4
 )
 
 // Effects defines all side effects that commands can perform.
@@ -16,25 +46,52 @@ type Effects interface {
 	ListWorktrees(repoRoot string) ([]git.Worktree, error)
 	ListBranches(repoRoot string) ([]git.Branch, error)
 	RunGitCommand(dir string, args ...string) (string, error)
+	RunGitInteractive(dir string, args ...string) error
 
 	// File system
-	FileExists(path string) bool
+	// Stat reports whether there is a file or directory at path, and which.
+	// The error is set when it can't tell, such as when a parent directory
+	// may not be read.
+	Stat(path string) (exists, isDir bool, err error)
 	MkdirAll(path string, perm os.FileMode) error
+	Rename(oldPath, newPath string) error
+	// CopyFile copies src to dst (replacing it), creating dst's parent directories.
+	CopyFile(src, dst string) error
+	ReadFile(path string) ([]byte, error)
+	WriteFile(path string, data []byte, perm os.FileMode) error
+	Symlink(target, link string) error
+	// CloneTree makes a copy-on-write clone of a directory tree. The error
+	// wraps reflink.ErrUnsupported when the filesystem can't clone.
+	CloneTree(src, dst string) error
+	// ReflinkSupported reports whether files can be cloned from srcDir into dstDir.
+	ReflinkSupported(srcDir, dstDir string) bool
+	// RemoveAll deletes path and everything under it.
+	RemoveAll(path string) error
+	// DirSize returns the total size in bytes of the files under path.
+	DirSize(path string) (int64, error)
 
 	// Config
 	LoadConfig(currentPath, mainPath string) (*config.Config, error)
+	LoadUserConfig() (*config.UserConfig, error)
 
 	// Trust
-	IsTrusted(repoRoot string) (bool, error)
-	TrustRepo(repoRoot string) error
+	// IsTrusted and TrustRepo take the worktree whose .sprout.yml hooks are
+	// meant, which is repoRoot for the main worktree's.
+	IsTrusted(repoRoot, worktreePath string) (bool, error)
+	TrustRepo(repoRoot, worktreePath string) error
 	UntrustRepo(repoRoot string) error
 	// PromptTrustRepo prompts the user to trust a repository interactively.
 	// Shows hooks that will run and asks for consent.
-	// Returns error if stdin is not a terminal or user declined.
+	// Returns an error wrapping ErrNonInteractive if it may not ask, or
+	// ErrTrustDeclined if the user declined.
 	PromptTrustRepo(mainWorktreePath, hookType string, hookCommands []string) error
 
 	// Editor
 	OpenEditor(path string) error
+	// OpenFile opens a file of a worktree, at line and column when non-zero.
+	OpenFile(worktreePath, file string, line, column int) error
+	// OpenURL opens a web page in the user's browser.
+	OpenURL(url string) error
 
 	// Output
 	// Print and PrintErr are best-effort operations that write to stdout/stderr.
@@ -42,13 +99,23 @@ type Effects interface {
 	// If precise output handling is required, use a buffered writer with error checking.
 	Print(msg string)
 	PrintErr(msg string)
+	// ReportProgress announces step of total slow plan steps (best-effort, stderr).
+	ReportProgress(step, total int, label string)
 
 	// Interactive (kept at edge)
 	// SelectOne displays items with custom formatting and returns selected index.
 	// Items must be provided as []T where display converts T to string.
 	// This maintains type safety while avoiding interface{} casting in callers.
-	SelectBranch(branches []git.Branch) (int, error)
+	// Both fail with an error wrapping ErrNonInteractive if they may not ask.
+	// SelectBranch shows the branches that aren't available too, but only
+	// returns the index of one that is.
+	SelectBranch(branches []core.BranchChoice) (int, error)
 	SelectWorktree(worktrees []git.Worktree) (int, error)
+	// Confirm asks a yes/no question that defaults to no.
+	Confirm(question string) (bool, error)
+	// Choose asks which of choices to go with, returning its index. The
+	// user may decline them all, which is an error.
+	Choose(question string, choices []string) (int, error)
 
 	// Hooks
 	// RunHooks executes hook commands in the given worktree.
@@ -56,6 +123,8 @@ type Effects interface {
 	RunHooks(repoRoot, worktreePath, mainWorktreePath string, commands []string, hookType string) error
 
 	// Branch existence checks
+	// HasRemote reports whether the repository has any remote at all
+	HasRemote(repoRoot string) (bool, error)
 	LocalBranchExists(repoRoot, branch string) (bool, error)
 	// RemoteBranchExists checks if a branch exists on the remote (automatically prepends "origin/")
 	RemoteBranchExists(repoRoot, branch string) (bool, error)
@@ -66,6 +135,57 @@ type Effects interface {
 	// Sprout paths
 	GetSproutRoot() (string, error)
 	GetWorktreeRoot(repoRoot string) (string, error)
+	// GetPathWorktreeRoot returns the worktree root keyed by repository path,
+	// ignoring the configured repo identity (used to migrate old layouts).
+	GetPathWorktreeRoot(repoRoot string) (string, error)
+
+	// LockRepo keeps other sprout processes from changing the repository's
+	// worktrees until unlock is called, waiting up to timeout for one that
+	// holds the lock. The error wraps sprout.ErrRepoLocked if it timed out.
+	LockRepo(mainWorktreePath string, timeout time.Duration) (unlock func(), err error)
+
+	// Adopted worktrees (created outside sprout but managed by it)
+	ListAdoptedWorktrees() ([]string, error)
+	AdoptWorktree(repoRoot, path string) error
+	ForgetWorktree(path string) error
+
+	// Repository index (see sprout.RepoIndexStore)
+	// LoadRepoIndex returns the main worktree paths of repositories, keyed
+	// by their directory under the sprout root.
+	LoadRepoIndex() (map[string]string, error)
+	SaveRepoIndex(repos map[string]string) error
+	IndexRepo(repoDir, mainPath string) error
+
+	// WorktreeIndex returns the stable index assigned to a worktree,
+	// allocating one on first use (0 for the main worktree).
+	WorktreeIndex(repoRoot, path string) (int, error)
+
+	// Tickets
+	// FetchTicket looks up a ticket with the named provider (see tickets.NewProvider).
+	FetchTicket(provider, id string) (tickets.Ticket, error)
+	// LoadTickets returns the tickets recorded for worktrees, keyed by path.
+	LoadTickets() (map[string]tickets.Ticket, error)
+	RecordTicket(path string, ticket tickets.Ticket) error
+
+	// Worktree metadata
+	// LoadWorktreeMetadata returns what sprout recorded about worktrees, keyed by path.
+	LoadWorktreeMetadata() (map[string]sprout.WorktreeMeta, error)
+	// RecordBase records the ref a worktree's branch was created from.
+	RecordBase(path, base string) error
+	SetPinned(path string, pinned bool) error
+	// SetNote sets the note of a worktree; an empty note removes it.
+	SetNote(path, note string) error
+	// SetExpiry sets when a temporary worktree expires.
+	SetExpiry(path string, expiresAt time.Time) error
+	// ForgetMetadata drops the metadata of a removed worktree.
+	ForgetMetadata(path string) error
+
+	// Workspaces (see sprout.WorkspaceStore)
+	// LoadWorkspaces returns the recorded workspaces, sorted by name.
+	LoadWorkspaces() ([]sprout.Workspace, error)
+	// SaveWorkspace records a workspace, replacing one of the same name.
+	SaveWorkspace(name string, repos []string) error
+	DeleteWorkspace(name string) error
 
 	// Filesystem (additional)
 	ReadDir(path string) ([]os.DirEntry, error)
@@ -73,4 +193,29 @@ type Effects interface {
 
 	// Git status
 	GetWorktreeStatus(path string) git.WorktreeStatus
+	// GetDefaultBranch returns the repository's default branch, such as main.
+	GetDefaultBranch(path string) (string, error)
+	// WorktreeLastUsed estimates when a worktree was last worked in.
+	WorktreeLastUsed(path string) (time.Time, error)
+	// ReadHead finds the worktree containing dir and its HEAD without running git.
+	ReadHead(dir string) (root, head string, err error)
+	// CacheStatuses records worktree statuses (by path) for 'sprout prompt'.
+	CacheStatuses(statuses map[string]git.WorktreeStatus) error
+	LoadCachedStatus(path string) (sprout.CachedStatus, bool, error)
+	// LoadTimings returns the recorded timing samples, oldest first.
+	LoadTimings() ([]timing.Sample, error)
+	LoadTelemetry() (*telemetry.State, error)
+	// SetTelemetry turns telemetry on or off, discarding collected usage.
+	SetTelemetry(enabled bool) error
+	// EmitEvent delivers event to the command and socket in the user
+	// config, if any.
+	EmitEvent(event events.Event) error
+}
+
+// Exists reports whether fx.Stat finds path. A path it can't check, such as
+// one under a directory the user may not read, doesn't count; use Stat
+// where that should be an error instead.
+func Exists(fx Effects, path string) bool {
+	exists, _, err := fx.Stat(path)
+	return exists && err == nil
 }
@@ -3,8 +3,19 @@ package effects
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/m44rten1/sprout/internal/config"
 	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/events"
+	"github.com/m44rten1/sprout/internal/logging"
+	"github.com/m44rten1/sprout/internal/reflink"
+	"github.com/m44rten1/sprout/internal/sprout"
+	"github.com/m44rten1/sprout/internal/timing"
 )
 
 // ExitError is returned when a plan includes an Exit action.
@@ -31,18 +42,199 @@ func IsExit(err error) (code int, ok bool) {
 	return 0, false
 }
 
+// ExecuteOptions controls optional executor behavior.
+type ExecuteOptions struct {
+	// Progress reports "[step/total]" before each slow action (git commands,
+	// hooks) when the plan has at least core.MinProgressSteps of them.
+	Progress bool
+}
+
 // ExecutePlan executes all actions in a plan using the provided Effects.
 // It stops and returns an error on the first failure (fail-fast semantics).
 // If an Exit action is encountered, it returns an ExitError with the code.
 func ExecutePlan(plan core.Plan, fx Effects) error {
+	return ExecutePlanWithOptions(plan, fx, ExecuteOptions{})
+}
+
+// ExecutePlanWithOptions is ExecutePlan with optional progress reporting.
+func ExecutePlanWithOptions(plan core.Plan, fx Effects, opts ExecuteOptions) error {
+	total := 0
+	if opts.Progress {
+		if steps := core.CountProgressSteps(plan); steps >= core.MinProgressSteps {
+			total = steps
+		}
+	}
+
+	step := 0
 	for _, action := range plan.Actions {
-		if err := executeAction(action, fx); err != nil {
+		if label, ok := core.ProgressLabel(action); ok && total > 0 {
+			step++
+			fx.ReportProgress(step, total, label)
+		}
+		if err := runAction(action, fx); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// runAction executes an action, recording its duration for 'sprout stats'
+// and, when a debug log is open, logging it.
+func runAction(action core.Action, fx Effects) error {
+	started := time.Now()
+	err := executeAction(action, fx)
+	if name, ok := timingName(action); ok {
+		timing.Record(timing.Action, name, started)
+	}
+	if event, ok := actionEvent(action, err, time.Since(started)); ok {
+		event.Time = time.Now()
+		// Listeners are optional, so failing to reach one doesn't fail the action
+		if emitErr := fx.EmitEvent(event); emitErr != nil {
+			logging.Printf("%v", emitErr)
+		}
+	}
+	if dir, ok := changedRepo(action, err); ok {
+		// The index only speeds up finding repositories, so a failed update doesn't fail the action
+		if indexErr := indexRepo(fx, dir); indexErr != nil {
+			logging.Printf("failed to update the repository index: %v", indexErr)
+		}
+	}
+	if logging.Enabled() {
+		outcome := "ok"
+		if err != nil {
+			outcome = err.Error()
+		}
+		logging.Printf("action %s: %s, %s", core.DescribeAction(action), outcome, time.Since(started).Round(time.Millisecond))
+	}
+	return err
+}
+
+// timingName names an action in timing samples, so that samples of the
+// same kind of work add up across runs. Actions that print, exit, group
+// other actions or wait for the user are not timed.
+func timingName(action core.Action) (string, bool) {
+	switch a := action.(type) {
+	case core.NoOp, core.PrintMessage, core.PrintError, core.Exit, core.Parallel,
+		core.SelectInteractive, core.PromptTrust, core.RunGitInteractive:
+		return "", false
+	case core.RunGitCommand:
+		return "git " + gitSubcommand(a.Args), true
+	case core.RunHooks:
+		return "RunHooks " + string(a.Type), true
+	default:
+		return strings.TrimPrefix(fmt.Sprintf("%T", action), "core."), true
+	}
+}
+
+// actionEvent returns the event an action that ran for d, failing with
+// err, reports: creating or removing a worktree, or running hooks (whether
+// or not they succeeded). Other actions report nothing.
+func actionEvent(action core.Action, err error, d time.Duration) (events.Event, bool) {
+	switch a := action.(type) {
+	case core.RunGitCommand:
+		if err != nil || len(a.Args) < 3 || a.Args[0] != "worktree" {
+			return events.Event{}, false
+		}
+		switch a.Args[1] {
+		case "add":
+			path, branch := worktreeAddTarget(a.Args[2:])
+			return events.Event{Version: events.Version, Type: events.WorktreeCreated, Repo: a.Dir, Path: path, Branch: branch}, path != ""
+		case "remove":
+			path := a.Args[len(a.Args)-1]
+			return events.Event{Version: events.Version, Type: events.WorktreeRemoved, Repo: a.Dir, Path: path}, !strings.HasPrefix(path, "-")
+		}
+	case core.RunHooks:
+		if len(a.Commands) == 0 {
+			return events.Event{}, false
+		}
+		event := events.Event{
+			Version:    events.Version,
+			Type:       events.HooksCompleted,
+			Repo:       a.MainWorktreePath,
+			Path:       a.Path,
+			Hook:       string(a.Type),
+			DurationMS: d.Milliseconds(),
+		}
+		if err != nil {
+			event.Error = err.Error()
+		}
+		return event, true
+	}
+	return events.Event{}, false
+}
+
+// changedRepo returns the directory of a git command that added or moved a
+// worktree, so the repository it belongs to can be indexed.
+func changedRepo(action core.Action, err error) (string, bool) {
+	a, ok := action.(core.RunGitCommand)
+	if !ok || err != nil || len(a.Args) < 2 || a.Args[0] != "worktree" {
+		return "", false
+	}
+	return a.Dir, a.Args[1] == "add" || a.Args[1] == "move"
+}
+
+// indexRepo records the repository dir belongs to in the repository index,
+// once its directory under the sprout root exists.
+func indexRepo(fx Effects, dir string) error {
+	worktrees, err := fx.ListWorktrees(dir)
+	if err != nil || len(worktrees) == 0 {
+		return err
+	}
+	mainPath := worktrees[0].Path
+	repoDir, err := fx.GetWorktreeRoot(mainPath)
+	if err != nil || !Exists(fx, repoDir) {
+		return err
+	}
+	return fx.IndexRepo(repoDir, mainPath)
+}
+
+// scratchDir returns the scratch directory of a worktree under the sprout
+// root.
+func scratchDir(fx Effects, worktreePath string) (string, error) {
+	root, err := fx.GetSproutRoot()
+	if err != nil {
+		return "", fmt.Errorf("get sprout root: %w", err)
+	}
+	return sprout.ScratchDir(root, worktreePath), nil
+}
+
+// worktreeAddTarget returns the path and branch of 'git worktree add'
+// arguments (after "add"): the first argument that isn't a flag is the
+// path, and the branch is the one -b creates or the commit-ish after the
+// path.
+func worktreeAddTarget(args []string) (path, branch string) {
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "-b" || arg == "-B":
+			if i+1 < len(args) {
+				branch = args[i+1]
+				i++
+			}
+		case strings.HasPrefix(arg, "-"):
+		case path == "":
+			path = arg
+		case branch == "":
+			branch = arg
+		}
+	}
+	return path, branch
+}
+
+// gitSubcommand returns the git subcommand of args, such as "fetch" or
+// "worktree add", leaving out branches, paths and flags.
+func gitSubcommand(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	switch args[0] {
+	case "worktree", "stash", "remote":
+		if len(args) > 1 && !strings.HasPrefix(args[1], "-") {
+			return args[0] + " " + args[1]
+		}
+	}
+	return args[0]
+}
+
 // executeAction executes a single action using type switches.
 // Returns an error if the action fails or encounters an Exit action.
 func executeAction(action core.Action, fx Effects) error {
@@ -67,6 +259,63 @@ func executeAction(action core.Action, fx Effects) error {
 		}
 		return nil
 
+	case core.MoveDirectory:
+		if err := fx.Rename(a.From, a.To); err != nil {
+			return fmt.Errorf("move %s to %s: %w", a.From, a.To, err)
+		}
+		return nil
+
+	case core.RemoveDirectory:
+		if err := fx.RemoveAll(a.Path); err != nil {
+			return fmt.Errorf("remove %s: %w", a.Path, err)
+		}
+		return nil
+
+	case core.CreateScratch:
+		dir, err := scratchDir(fx, a.WorktreePath)
+		if err != nil {
+			return err
+		}
+		if err := fx.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("create scratch directory %s: %w", dir, err)
+		}
+		return nil
+
+	case core.RemoveScratch:
+		dir, err := scratchDir(fx, a.WorktreePath)
+		if err != nil {
+			return err
+		}
+		if err := fx.RemoveAll(dir); err != nil {
+			return fmt.Errorf("remove scratch directory %s: %w", dir, err)
+		}
+		return nil
+
+	case core.RemoveFile:
+		// RemoveAll is os.Remove for a file, but without failing when it's gone
+		if err := fx.RemoveAll(a.Path); err != nil {
+			return fmt.Errorf("remove %s: %w", a.Path, err)
+		}
+		return nil
+
+	case core.CopyFile:
+		return executeCopyFile(a, fx)
+
+	case core.WriteFile:
+		if err := fx.MkdirAll(filepath.Dir(a.Path), 0755); err != nil {
+			return fmt.Errorf("create directory for %s: %w", a.Path, err)
+		}
+		if err := fx.WriteFile(a.Path, a.Content, a.Perm); err != nil {
+			return fmt.Errorf("write %s: %w", a.Path, err)
+		}
+		return nil
+
+	case core.ShareDirectory:
+		return executeShareDirectory(a, fx)
+
+	case core.CloneDirectory:
+		return executeCloneDirectory(a, fx)
+
 	case core.RunGitCommand:
 		// Note: Output is intentionally discarded here.
 		// This executor handles "command for side-effect" git operations.
@@ -78,12 +327,30 @@ func executeAction(action core.Action, fx Effects) error {
 		}
 		return nil
 
+	case core.RunGitInteractive:
+		if err := fx.RunGitInteractive(a.Dir, a.Args...); err != nil {
+			return fmt.Errorf("git command in %s failed: %w", a.Dir, err)
+		}
+		return nil
+
 	case core.OpenEditor:
 		if err := fx.OpenEditor(a.Path); err != nil {
 			return fmt.Errorf("open editor for %s: %w", a.Path, err)
 		}
 		return nil
 
+	case core.OpenFile:
+		if err := fx.OpenFile(a.WorktreePath, a.File, a.Line, a.Column); err != nil {
+			return fmt.Errorf("open %s in editor: %w", a.File, err)
+		}
+		return nil
+
+	case core.OpenURL:
+		if err := fx.OpenURL(a.URL); err != nil {
+			return fmt.Errorf("open %s: %w", a.URL, err)
+		}
+		return nil
+
 	case core.RunHooks:
 		if err := fx.RunHooks(a.RepoRoot, a.Path, a.MainWorktreePath, a.Commands, string(a.Type)); err != nil {
 			return fmt.Errorf("run %s hooks: %w", a.Type, err)
@@ -97,7 +364,11 @@ func executeAction(action core.Action, fx Effects) error {
 		return nil
 
 	case core.TrustRepo:
-		if err := fx.TrustRepo(a.RepoRoot); err != nil {
+		worktreePath := a.WorktreePath
+		if worktreePath == "" {
+			worktreePath = a.RepoRoot
+		}
+		if err := fx.TrustRepo(a.RepoRoot, worktreePath); err != nil {
 			return fmt.Errorf("trust repo %s: %w", a.RepoRoot, err)
 		}
 		return nil
@@ -108,12 +379,81 @@ func executeAction(action core.Action, fx Effects) error {
 		}
 		return nil
 
+	case core.AdoptWorktree:
+		if err := fx.AdoptWorktree(a.RepoRoot, a.Path); err != nil {
+			return fmt.Errorf("adopt worktree %s: %w", a.Path, err)
+		}
+		return nil
+
+	case core.ForgetWorktree:
+		if err := fx.ForgetWorktree(a.Path); err != nil {
+			return fmt.Errorf("forget worktree %s: %w", a.Path, err)
+		}
+		return nil
+
+	case core.RecordTicket:
+		if err := fx.RecordTicket(a.WorktreePath, a.Ticket); err != nil {
+			return fmt.Errorf("record ticket for %s: %w", a.WorktreePath, err)
+		}
+		return nil
+
+	case core.RecordBase:
+		if err := fx.RecordBase(a.WorktreePath, a.Base); err != nil {
+			return fmt.Errorf("record base for %s: %w", a.WorktreePath, err)
+		}
+		return nil
+
+	case core.SetPinned:
+		if err := fx.SetPinned(a.WorktreePath, a.Pinned); err != nil {
+			return fmt.Errorf("set pinned for %s: %w", a.WorktreePath, err)
+		}
+		return nil
+
+	case core.SetNote:
+		if err := fx.SetNote(a.WorktreePath, a.Note); err != nil {
+			return fmt.Errorf("set note for %s: %w", a.WorktreePath, err)
+		}
+		return nil
+
+	case core.SetExpiry:
+		if err := fx.SetExpiry(a.WorktreePath, a.ExpiresAt); err != nil {
+			return fmt.Errorf("set expiry of %s: %w", a.WorktreePath, err)
+		}
+		return nil
+
+	case core.ForgetMetadata:
+		if err := fx.ForgetMetadata(a.WorktreePath); err != nil {
+			return fmt.Errorf("forget metadata of %s: %w", a.WorktreePath, err)
+		}
+		return nil
+
+	case core.SaveWorkspace:
+		if err := fx.SaveWorkspace(a.Name, a.Repos); err != nil {
+			return fmt.Errorf("save workspace %s: %w", a.Name, err)
+		}
+		return nil
+
+	case core.DeleteWorkspace:
+		if err := fx.DeleteWorkspace(a.Name); err != nil {
+			return fmt.Errorf("delete workspace %s: %w", a.Name, err)
+		}
+		return nil
+
+	case core.SetTelemetry:
+		if err := fx.SetTelemetry(a.Enabled); err != nil {
+			return fmt.Errorf("set telemetry: %w", err)
+		}
+		return nil
+
 	case core.SelectInteractive:
 		// SelectInteractive is a planning-time artifact, not an executable action.
 		// Interactive selection should happen in the shell BEFORE plan generation.
 		// If this appears in an execution plan, it indicates a bug in the planner.
 		return fmt.Errorf("SelectInteractive should not appear in execution plans (selection belongs in shell)")
 
+	case core.Parallel:
+		return executeParallel(a, fx)
+
 	case core.Exit:
 		return ExitError{Code: a.Code}
 
@@ -121,3 +461,129 @@ func executeAction(action core.Action, fx Effects) error {
 		return fmt.Errorf("unknown action type: %T", action)
 	}
 }
+
+// parallelLimit is how many members of a Parallel group run at once.
+const parallelLimit = 4
+
+// executeParallel runs the members of a Parallel group concurrently, up to
+// parallelLimit at a time. Once one fails no further members start; the
+// ones already running are waited for, and their failures are joined in
+// member order.
+func executeParallel(group core.Parallel, fx Effects) error {
+	for _, member := range group.Actions {
+		switch member.(type) {
+		case core.PromptTrust, core.SelectInteractive, core.Exit:
+			return fmt.Errorf("%T cannot run in a parallel group", member)
+		}
+	}
+
+	errs := make([]error, len(group.Actions))
+	slots := make(chan struct{}, parallelLimit)
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for i, member := range group.Actions {
+		slots <- struct{}{}
+		if failed.Load() {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if errs[i] = runAction(member, fx); errs[i] != nil {
+				failed.Store(true)
+			}
+			<-slots // Only after recording a failure, so the next member sees it
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// executeCopyFile copies a file, resolving a conflict with an existing
+// destination according to the action's policy.
+func executeCopyFile(a core.CopyFile, fx Effects) error {
+	if Exists(fx, a.Dst) {
+		switch a.OnConflict {
+		case config.ConflictOverwrite:
+		case config.ConflictBackup:
+			backup := backupPath(fx, a.Dst)
+			if err := fx.Rename(a.Dst, backup); err != nil {
+				return fmt.Errorf("back up %s: %w", a.Dst, err)
+			}
+			fx.Print(fmt.Sprintf("   Moved existing %s to %s", a.Dst, backup))
+		default:
+			fx.Print(fmt.Sprintf("   Kept existing %s", a.Dst))
+			return nil
+		}
+	}
+
+	if err := fx.CopyFile(a.Src, a.Dst); err != nil {
+		return fmt.Errorf("copy %s to %s: %w", a.Src, a.Dst, err)
+	}
+	return nil
+}
+
+// backupPath returns where to move path aside to: path.orig, or the first
+// of path.orig.1, path.orig.2, ... that doesn't exist, so an earlier backup
+// is never overwritten.
+func backupPath(fx Effects, path string) string {
+	backup := path + ".orig"
+	for i := 1; Exists(fx, backup); i++ {
+		backup = fmt.Sprintf("%s.orig.%d", path, i)
+	}
+	return backup
+}
+
+// executeShareDirectory links or clones a main worktree directory into a
+// new worktree. Clones fall back to a symlink where the filesystem can't
+// clone, so the setup time is saved either way.
+func executeShareDirectory(a core.ShareDirectory, fx Effects) error {
+	if !Exists(fx, a.Src) {
+		fx.Print(fmt.Sprintf("   Not sharing %s (missing in the main worktree)", a.Src))
+		return nil
+	}
+	if Exists(fx, a.Dst) {
+		fx.Print(fmt.Sprintf("   Not sharing %s (already exists)", a.Dst))
+		return nil
+	}
+	if err := fx.MkdirAll(filepath.Dir(a.Dst), 0755); err != nil {
+		return fmt.Errorf("create directory for %s: %w", a.Dst, err)
+	}
+
+	if a.Mode == config.ShareClone {
+		err := fx.CloneTree(a.Src, a.Dst)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, reflink.ErrUnsupported) {
+			return fmt.Errorf("clone %s: %w", a.Src, err)
+		}
+		fx.Print(fmt.Sprintf("   Copy-on-write clones are not supported here; symlinking %s instead", a.Dst))
+	}
+
+	if err := fx.Symlink(a.Src, a.Dst); err != nil {
+		return fmt.Errorf("symlink %s: %w", a.Dst, err)
+	}
+	return nil
+}
+
+// executeCloneDirectory clones build artifacts into a new worktree. They are
+// only a cache, so a failed clone is reported and the add carries on.
+func executeCloneDirectory(a core.CloneDirectory, fx Effects) error {
+	if !Exists(fx, a.Src) {
+		fx.Print(fmt.Sprintf("   Not cloning %s (missing in the main worktree)", a.Src))
+		return nil
+	}
+	if Exists(fx, a.Dst) {
+		fx.Print(fmt.Sprintf("   Not cloning %s (already exists)", a.Dst))
+		return nil
+	}
+	if err := fx.MkdirAll(filepath.Dir(a.Dst), 0755); err != nil {
+		return fmt.Errorf("create directory for %s: %w", a.Dst, err)
+	}
+	if err := fx.CloneTree(a.Src, a.Dst); err != nil {
+		fx.PrintErr(fmt.Sprintf("   Could not clone %s: %v", a.Src, err))
+	}
+	return nil
+}
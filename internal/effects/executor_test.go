@@ -1,10 +1,16 @@
 package effects
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/m44rten1/sprout/internal/config"
 	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/events"
+	"github.com/m44rten1/sprout/internal/reflink"
+	"github.com/m44rten1/sprout/internal/sprout"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -70,6 +76,35 @@ func TestExecutePlan(t *testing.T) {
 		assert.True(t, fx.Files["/test/dir"], "Directory should be marked as existing")
 	})
 
+	t.Run("scratch directories live under the sprout root", func(t *testing.T) {
+		fx := NewTestEffects()
+		scratch := sprout.ScratchDir(fx.SproutRoot, "/sprout/repo/feature")
+		require.True(t, strings.HasPrefix(scratch, fx.SproutRoot+"/scratch/"))
+
+		require.NoError(t, ExecutePlan(core.Plan{Actions: []core.Action{core.CreateScratch{WorktreePath: "/sprout/repo/feature"}}}, fx))
+		assert.Equal(t, []string{scratch}, fx.CreatedDirs)
+
+		require.NoError(t, ExecutePlan(core.Plan{Actions: []core.Action{core.RemoveScratch{WorktreePath: "/sprout/repo/feature"}}}, fx))
+		assert.Equal(t, []string{scratch}, fx.RemovedDirs)
+		assert.False(t, fx.Files[scratch])
+	})
+
+	t.Run("MoveDirectory calls Rename", func(t *testing.T) {
+		fx := NewTestEffects()
+		fx.Files["/old/root"] = true
+		plan := core.Plan{Actions: []core.Action{
+			core.MoveDirectory{From: "/old/root", To: "/new/root"},
+		}}
+
+		err := ExecutePlan(plan, fx)
+
+		require.NoError(t, err)
+		require.Len(t, fx.Renames, 1)
+		assert.Equal(t, RenameCall{From: "/old/root", To: "/new/root"}, fx.Renames[0])
+		assert.False(t, fx.Files["/old/root"])
+		assert.True(t, fx.Files["/new/root"])
+	})
+
 	t.Run("RunGitCommand executes git", func(t *testing.T) {
 		fx := NewTestEffects()
 		fx.GitCommandOutput["/repo\nworktree add"] = "success"
@@ -85,6 +120,19 @@ func TestExecutePlan(t *testing.T) {
 		assert.Equal(t, []string{"worktree", "add"}, fx.GitCommands[0].Args)
 	})
 
+	t.Run("RunGitInteractive runs git attached to the terminal", func(t *testing.T) {
+		fx := NewTestEffects()
+		plan := core.Plan{Actions: []core.Action{
+			core.RunGitInteractive{Dir: "/repo", Args: []string{"diff", "a", "b"}},
+		}}
+
+		err := ExecutePlan(plan, fx)
+
+		require.NoError(t, err)
+		assert.Equal(t, []GitCmd{{Dir: "/repo", Args: []string{"diff", "a", "b"}}}, fx.InteractiveGitCommands)
+		assert.Empty(t, fx.GitCommands)
+	})
+
 	t.Run("OpenEditor calls OpenEditor", func(t *testing.T) {
 		fx := NewTestEffects()
 		plan := core.Plan{Actions: []core.Action{
@@ -286,3 +334,415 @@ func TestIsExit(t *testing.T) {
 		assert.Equal(t, 7, code)
 	})
 }
+
+func TestExecutePlanWithOptions_Progress(t *testing.T) {
+	plan := core.Plan{Actions: []core.Action{
+		core.PrintMessage{Msg: "starting"},
+		core.RunGitCommand{Dir: "/repo", Args: []string{"fetch", "origin"}},
+		core.RunGitCommand{Dir: "/repo", Args: []string{"worktree", "add", "-b", "feat", "/wt"}},
+		core.RunHooks{Type: core.HookTypeOnCreate, Path: "/wt", Commands: []string{"make"}},
+	}}
+
+	t.Run("reports each slow step", func(t *testing.T) {
+		fx := NewTestEffects()
+
+		require.NoError(t, ExecutePlanWithOptions(plan, fx, ExecuteOptions{Progress: true}))
+
+		assert.Equal(t, []string{
+			"[1/3] Running git fetch",
+			"[2/3] Running git worktree add",
+			"[3/3] Running on_create hooks",
+		}, fx.ProgressReports)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		fx := NewTestEffects()
+
+		require.NoError(t, ExecutePlan(plan, fx))
+
+		assert.Empty(t, fx.ProgressReports)
+	})
+
+	t.Run("single slow step is not reported", func(t *testing.T) {
+		fx := NewTestEffects()
+		single := core.Plan{Actions: []core.Action{
+			core.RunGitCommand{Dir: "/repo", Args: []string{"worktree", "prune"}},
+			core.PrintMessage{Msg: "done"},
+		}}
+
+		require.NoError(t, ExecutePlanWithOptions(single, fx, ExecuteOptions{Progress: true}))
+
+		assert.Empty(t, fx.ProgressReports)
+	})
+
+	t.Run("stops reporting after failure", func(t *testing.T) {
+		fx := NewTestEffects()
+		fx.GitCommandErrors["/repo\nfetch origin"] = fmt.Errorf("network down")
+
+		err := ExecutePlanWithOptions(plan, fx, ExecuteOptions{Progress: true})
+
+		require.Error(t, err)
+		assert.Equal(t, []string{"[1/3] Running git fetch"}, fx.ProgressReports)
+	})
+}
+
+func TestExecutePlan_Parallel(t *testing.T) {
+	t.Run("runs every member", func(t *testing.T) {
+		fx := NewTestEffects()
+		plan := core.Plan{Actions: []core.Action{
+			core.Parallel{Actions: []core.Action{
+				core.RunGitCommand{Dir: "/repo", Args: []string{"fetch"}},
+				core.CreateDirectory{Path: "/a", Perm: 0755},
+				core.CreateDirectory{Path: "/b", Perm: 0755},
+			}},
+			core.PrintMessage{Msg: "after"},
+		}}
+
+		require.NoError(t, ExecutePlan(plan, fx))
+
+		assert.Equal(t, 1, fx.RunGitCommandCalls)
+		assert.ElementsMatch(t, []string{"/a", "/b"}, fx.CreatedDirs)
+		assert.Equal(t, []string{"after"}, fx.PrintedMsgs)
+	})
+
+	t.Run("waits for running members and joins failures in order", func(t *testing.T) {
+		fx := NewTestEffects()
+		fx.GitCommandErrors["/one\nfetch"] = fmt.Errorf("first")
+		fx.GitCommandErrors["/two\nfetch"] = fmt.Errorf("second")
+		plan := core.Plan{Actions: []core.Action{
+			core.Parallel{Actions: []core.Action{
+				core.RunGitCommand{Dir: "/one", Args: []string{"fetch"}},
+				core.CreateDirectory{Path: "/ok", Perm: 0755},
+				core.RunGitCommand{Dir: "/two", Args: []string{"fetch"}},
+			}},
+			core.PrintMessage{Msg: "not reached"},
+		}}
+
+		err := ExecutePlan(plan, fx)
+
+		require.Error(t, err)
+		assert.Equal(t, "git command in /one failed: first\ngit command in /two failed: second", err.Error())
+		assert.Equal(t, []string{"/ok"}, fx.CreatedDirs)
+		assert.Empty(t, fx.PrintedMsgs)
+	})
+
+	t.Run("starts no more members after a failure", func(t *testing.T) {
+		fx := NewTestEffects()
+		var members []core.Action
+		for i := range parallelLimit {
+			dir := fmt.Sprintf("/repo%d", i)
+			fx.GitCommandErrors[dir+"\nfetch"] = fmt.Errorf("offline")
+			members = append(members, core.RunGitCommand{Dir: dir, Args: []string{"fetch"}})
+		}
+		members = append(members, core.CreateDirectory{Path: "/late", Perm: 0755})
+
+		err := ExecutePlan(core.Plan{Actions: []core.Action{core.Parallel{Actions: members}}}, fx)
+
+		require.Error(t, err)
+		assert.Equal(t, parallelLimit, fx.RunGitCommandCalls, "every member already running finishes")
+		assert.Empty(t, fx.CreatedDirs, "the member waiting for a slot never starts")
+	})
+
+	t.Run("rejects control flow members", func(t *testing.T) {
+		fx := NewTestEffects()
+		plan := core.Plan{Actions: []core.Action{
+			core.Parallel{Actions: []core.Action{
+				core.CreateDirectory{Path: "/a"},
+				core.Exit{Code: 1},
+			}},
+		}}
+
+		err := ExecutePlan(plan, fx)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot run in a parallel group")
+		assert.Empty(t, fx.CreatedDirs)
+	})
+}
+
+func TestExecutePlan_OpenURL(t *testing.T) {
+	fx := NewTestEffects()
+	fx.OpenURLErr = fmt.Errorf("no browser")
+
+	err := ExecutePlan(core.Plan{Actions: []core.Action{core.OpenURL{URL: "https://example.com"}}}, fx)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "open https://example.com")
+	assert.Equal(t, []string{"https://example.com"}, fx.OpenedURLs)
+}
+
+func TestExecutePlan_CopyFile(t *testing.T) {
+	copyTo := func(policy config.ConflictPolicy) core.Plan {
+		return core.Plan{Actions: []core.Action{
+			core.CopyFile{Src: "/tpl/.env", Dst: "/wt/.env", OnConflict: policy},
+		}}
+	}
+
+	t.Run("copies new file", func(t *testing.T) {
+		fx := NewTestEffects()
+
+		require.NoError(t, ExecutePlan(copyTo(""), fx))
+		assert.Equal(t, []RenameCall{{From: "/tpl/.env", To: "/wt/.env"}}, fx.CopiedFiles)
+	})
+
+	t.Run("skip keeps existing file", func(t *testing.T) {
+		fx := NewTestEffects()
+		fx.Files["/wt/.env"] = true
+
+		require.NoError(t, ExecutePlan(copyTo(config.ConflictSkip), fx))
+		assert.Empty(t, fx.CopiedFiles)
+		assert.Contains(t, fx.PrintedMsgs[0], "Kept existing /wt/.env")
+	})
+
+	t.Run("overwrite replaces existing file", func(t *testing.T) {
+		fx := NewTestEffects()
+		fx.Files["/wt/.env"] = true
+
+		require.NoError(t, ExecutePlan(copyTo(config.ConflictOverwrite), fx))
+		assert.Len(t, fx.CopiedFiles, 1)
+		assert.Empty(t, fx.Renames)
+	})
+
+	t.Run("backup moves existing file aside", func(t *testing.T) {
+		fx := NewTestEffects()
+		fx.Files["/wt/.env"] = true
+
+		require.NoError(t, ExecutePlan(copyTo(config.ConflictBackup), fx))
+		assert.Equal(t, []RenameCall{{From: "/wt/.env", To: "/wt/.env.orig"}}, fx.Renames)
+		assert.Len(t, fx.CopiedFiles, 1)
+	})
+
+	t.Run("backup keeps earlier backups", func(t *testing.T) {
+		fx := NewTestEffects()
+		fx.Files["/wt/.env"] = true
+		fx.Files["/wt/.env.orig"] = true
+		fx.Files["/wt/.env.orig.1"] = true
+
+		require.NoError(t, ExecutePlan(copyTo(config.ConflictBackup), fx))
+		assert.Equal(t, []RenameCall{{From: "/wt/.env", To: "/wt/.env.orig.2"}}, fx.Renames)
+	})
+
+	t.Run("copy failure", func(t *testing.T) {
+		fx := NewTestEffects()
+		fx.CopyFileErr = fmt.Errorf("permission denied")
+
+		err := ExecutePlan(copyTo(""), fx)
+		assert.ErrorContains(t, err, "copy /tpl/.env to /wt/.env: permission denied")
+	})
+}
+
+func TestExecutePlan_ShareDirectory(t *testing.T) {
+	share := func(mode config.ShareMode) core.Plan {
+		return core.Plan{Actions: []core.Action{
+			core.ShareDirectory{Src: "/repo/node_modules", Dst: "/wt/node_modules", Mode: mode},
+		}}
+	}
+
+	t.Run("symlinks by default", func(t *testing.T) {
+		fx := NewTestEffects()
+		fx.Files["/repo/node_modules"] = true
+
+		require.NoError(t, ExecutePlan(share(""), fx))
+		assert.Equal(t, []RenameCall{{From: "/wt/node_modules", To: "/repo/node_modules"}}, fx.Symlinks)
+		assert.Zero(t, fx.CloneTreeCalls)
+	})
+
+	t.Run("clone mode clones", func(t *testing.T) {
+		fx := NewTestEffects()
+		fx.Files["/repo/node_modules"] = true
+
+		require.NoError(t, ExecutePlan(share(config.ShareClone), fx))
+		assert.Equal(t, []RenameCall{{From: "/repo/node_modules", To: "/wt/node_modules"}}, fx.ClonedTrees)
+		assert.Empty(t, fx.Symlinks)
+	})
+
+	t.Run("clone falls back to symlink when unsupported", func(t *testing.T) {
+		fx := NewTestEffects()
+		fx.Files["/repo/node_modules"] = true
+		fx.CloneTreeErr = fmt.Errorf("clone /repo/node_modules: %w", reflink.ErrUnsupported)
+
+		require.NoError(t, ExecutePlan(share(config.ShareClone), fx))
+		assert.Len(t, fx.Symlinks, 1)
+		assert.Contains(t, fx.PrintedMsgs[0], "symlinking /wt/node_modules instead")
+	})
+
+	t.Run("clone failure", func(t *testing.T) {
+		fx := NewTestEffects()
+		fx.Files["/repo/node_modules"] = true
+		fx.CloneTreeErr = fmt.Errorf("disk full")
+
+		err := ExecutePlan(share(config.ShareClone), fx)
+		assert.ErrorContains(t, err, "clone /repo/node_modules: disk full")
+		assert.Empty(t, fx.Symlinks)
+	})
+
+	t.Run("skips missing source", func(t *testing.T) {
+		fx := NewTestEffects()
+
+		require.NoError(t, ExecutePlan(share(""), fx))
+		assert.Zero(t, fx.SymlinkCalls)
+		assert.Contains(t, fx.PrintedMsgs[0], "missing in the main worktree")
+	})
+
+	t.Run("keeps existing destination", func(t *testing.T) {
+		fx := NewTestEffects()
+		fx.Files["/repo/node_modules"] = true
+		fx.Files["/wt/node_modules"] = true
+
+		require.NoError(t, ExecutePlan(share(""), fx))
+		assert.Zero(t, fx.SymlinkCalls)
+		assert.Contains(t, fx.PrintedMsgs[0], "already exists")
+	})
+}
+
+func TestExecutePlan_CloneDirectory(t *testing.T) {
+	plan := core.Plan{Actions: []core.Action{
+		core.CloneDirectory{Src: "/repo/target", Dst: "/wt/target"},
+	}}
+
+	t.Run("clones artifacts", func(t *testing.T) {
+		fx := NewTestEffects()
+		fx.Files["/repo/target"] = true
+
+		require.NoError(t, ExecutePlan(plan, fx))
+		assert.Equal(t, []RenameCall{{From: "/repo/target", To: "/wt/target"}}, fx.ClonedTrees)
+	})
+
+	t.Run("failed clone is a warning, never a symlink", func(t *testing.T) {
+		fx := NewTestEffects()
+		fx.Files["/repo/target"] = true
+		fx.CloneTreeErr = reflink.ErrUnsupported
+
+		require.NoError(t, ExecutePlan(plan, fx))
+		assert.Empty(t, fx.Symlinks)
+		assert.Contains(t, fx.PrintedErrs[0], "Could not clone /repo/target")
+	})
+
+	t.Run("skips missing artifacts", func(t *testing.T) {
+		fx := NewTestEffects()
+
+		require.NoError(t, ExecutePlan(plan, fx))
+		assert.Zero(t, fx.CloneTreeCalls)
+	})
+}
+
+func TestExecutePlan_WriteFile(t *testing.T) {
+	fx := NewTestEffects()
+	plan := core.Plan{Actions: []core.Action{
+		core.WriteFile{Path: "/sprout/repo/repo.code-workspace", Content: []byte("{}"), Perm: 0644},
+	}}
+
+	require.NoError(t, ExecutePlan(plan, fx))
+	assert.Equal(t, []byte("{}"), fx.FileContents["/sprout/repo/repo.code-workspace"])
+	assert.Contains(t, fx.CreatedDirs, "/sprout/repo")
+}
+
+func TestTimingName(t *testing.T) {
+	tests := []struct {
+		action core.Action
+		want   string
+	}{
+		{core.RunGitCommand{Dir: "/repo", Args: []string{"fetch", "origin"}}, "git fetch"},
+		{core.RunGitCommand{Dir: "/repo", Args: []string{"worktree", "add", "/wt", "feature"}}, "git worktree add"},
+		{core.RunGitCommand{Dir: "/repo", Args: []string{"stash", "--include-untracked"}}, "git stash"},
+		{core.RunHooks{Type: core.HookTypeOnCreate}, "RunHooks on_create"},
+		{core.CopyFile{Src: "/a", Dst: "/b"}, "CopyFile"},
+	}
+	for _, tt := range tests {
+		name, ok := timingName(tt.action)
+		assert.True(t, ok)
+		assert.Equal(t, tt.want, name)
+	}
+
+	for _, action := range []core.Action{core.PrintMessage{Msg: "hi"}, core.Exit{Code: 1}, core.PromptTrust{}, core.Parallel{}} {
+		_, ok := timingName(action)
+		assert.False(t, ok, "%T is not timed", action)
+	}
+}
+
+func TestExecutePlan_PromptTrust(t *testing.T) {
+	plan := core.Plan{Actions: []core.Action{
+		core.PromptTrust{MainWorktreePath: "/repo", HookType: core.HookTypeOnCreate, HookCommands: []string{"npm ci"}},
+		core.CreateDirectory{Path: "/wt", Perm: 0755},
+	}}
+
+	t.Run("trusted", func(t *testing.T) {
+		fx := NewTestEffects()
+
+		require.NoError(t, ExecutePlan(plan, fx))
+		assert.Equal(t, []PromptTrustCall{{MainWorktreePath: "/repo", HookType: "on_create", HookCommands: []string{"npm ci"}}}, fx.PromptTrustRepoInvocations)
+		assert.True(t, fx.TrustedRepos["/repo"])
+		assert.Equal(t, 1, fx.MkdirAllCalls)
+	})
+
+	t.Run("declined", func(t *testing.T) {
+		fx := NewTestEffects()
+		fx.TrustDeclined = true
+
+		err := ExecutePlan(plan, fx)
+		assert.ErrorIs(t, err, ErrTrustDeclined)
+		assert.False(t, fx.TrustedRepos["/repo"])
+		assert.Zero(t, fx.MkdirAllCalls, "nothing runs after a declined prompt")
+	})
+
+	t.Run("non-interactive", func(t *testing.T) {
+		fx := NewTestEffects()
+		fx.PromptTrustRepoErr = fmt.Errorf("%w; run sprout trust", ErrNonInteractive)
+
+		err := ExecutePlan(plan, fx)
+		assert.ErrorIs(t, err, ErrNonInteractive)
+		assert.Zero(t, fx.MkdirAllCalls)
+	})
+}
+
+func TestExecutePlan_EmitsEvents(t *testing.T) {
+	fx := NewTestEffects()
+	fx.RunHooksErr = errors.New("npm ci failed")
+	plan := core.Plan{Actions: []core.Action{
+		core.RunGitCommand{Dir: "/repo", Args: core.WorktreeAddArgs("/wt/feature", "feature", false, false, "origin/main")},
+		core.RunGitCommand{Dir: "/repo", Args: []string{"worktree", "remove", "--force", "/wt/old"}},
+		core.RunGitCommand{Dir: "/repo", Args: []string{"worktree", "prune"}},
+		core.RunHooks{Type: core.HookTypeOnCreate, Commands: []string{"npm ci"}, Path: "/wt/feature", MainWorktreePath: "/repo"},
+	}}
+
+	require.Error(t, ExecutePlan(plan, fx))
+	require.Len(t, fx.Events, 3)
+	assert.Equal(t, events.WorktreeCreated, fx.Events[0].Type)
+	assert.Equal(t, "/wt/feature", fx.Events[0].Path)
+	assert.Equal(t, "feature", fx.Events[0].Branch)
+	assert.Equal(t, "/repo", fx.Events[0].Repo)
+	assert.False(t, fx.Events[0].Time.IsZero())
+	assert.Equal(t, events.WorktreeRemoved, fx.Events[1].Type)
+	assert.Equal(t, "/wt/old", fx.Events[1].Path)
+	assert.Equal(t, events.HooksCompleted, fx.Events[2].Type)
+	assert.Equal(t, "on_create", fx.Events[2].Hook)
+	assert.Contains(t, fx.Events[2].Error, "npm ci failed", "failed hooks are reported too")
+}
+
+func TestExecutePlan_EventErrorsDontFail(t *testing.T) {
+	fx := NewTestEffects()
+	fx.EmitEventErr = errors.New("connection refused")
+	plan := core.Plan{Actions: []core.Action{
+		core.RunGitCommand{Dir: "/repo", Args: []string{"worktree", "add", "/wt/feature", "feature"}},
+	}}
+
+	require.NoError(t, ExecutePlan(plan, fx))
+	assert.Len(t, fx.Events, 1)
+}
+
+func TestWorktreeAddTarget(t *testing.T) {
+	tests := []struct {
+		args         []string
+		path, branch string
+	}{
+		{[]string{"/wt", "feature"}, "/wt", "feature"},
+		{[]string{"/wt", "-b", "feature", "origin/feature"}, "/wt", "feature"},
+		{[]string{"/wt", "-b", "feature", "--no-track", "HEAD"}, "/wt", "feature"},
+		{[]string{"--detach", "/wt"}, "/wt", ""},
+	}
+	for _, tt := range tests {
+		path, branch := worktreeAddTarget(tt.args)
+		assert.Equal(t, tt.path, path, "%q", tt.args)
+		assert.Equal(t, tt.branch, branch, "%q", tt.args)
+	}
+}
@@ -2,16 +2,27 @@ package effects
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/core"
 	"github.com/m44rten1/sprout/internal/editor"
+	"github.com/m44rten1/sprout/internal/events"
 	"github.com/m44rten1/sprout/internal/git"
 	"github.com/m44rten1/sprout/internal/hooks"
+	"github.com/m44rten1/sprout/internal/reflink"
 	"github.com/m44rten1/sprout/internal/sprout"
+	"github.com/m44rten1/sprout/internal/style"
+	"github.com/m44rten1/sprout/internal/telemetry"
+	"github.com/m44rten1/sprout/internal/tickets"
+	"github.com/m44rten1/sprout/internal/timing"
 	"github.com/m44rten1/sprout/internal/trust"
 	"github.com/m44rten1/sprout/internal/tui"
 	"golang.org/x/term"
@@ -19,19 +30,27 @@ import (
 
 // RealEffects implements Effects by delegating to existing packages.
 // This is the production implementation used by CLI commands.
-type RealEffects struct{}
+type RealEffects struct {
+	dir string // Directory the current repository is found from; empty is the working directory
+}
 
 // NewRealEffects creates a new RealEffects instance.
 func NewRealEffects() *RealEffects {
 	return &RealEffects{}
 }
 
+// NewRealEffectsIn creates a RealEffects instance for the repository
+// containing dir, for callers that can't change the working directory.
+func NewRealEffectsIn(dir string) *RealEffects {
+	return &RealEffects{dir: dir}
+}
+
 func (r *RealEffects) GetRepoRoot() (string, error) {
-	return git.GetRepoRoot()
+	return git.GetRepoRootIn(r.dir)
 }
 
 func (r *RealEffects) GetMainWorktreePath() (string, error) {
-	return git.GetMainWorktreePath()
+	return git.GetMainWorktreePathIn(r.dir)
 }
 
 func (r *RealEffects) ListWorktrees(repoRoot string) ([]git.Worktree, error) {
@@ -42,31 +61,151 @@ func (r *RealEffects) ListBranches(repoRoot string) ([]git.Branch, error) {
 	return git.ListAllBranches(repoRoot)
 }
 
+// RunGitCommand runs git. Commands that talk to a remote are retried with
+// backoff on network errors, so a flaky connection doesn't fail a command
+// halfway through.
 func (r *RealEffects) RunGitCommand(dir string, args ...string) (string, error) {
-	return git.RunGitCommand(dir, args...)
+	if !git.IsNetworkCommand(args) {
+		return git.RunGitCommand(dir, args...)
+	}
+
+	attempts := config.DefaultNetworkAttempts
+	if userCfg, err := config.LoadUser(); err == nil {
+		attempts = userCfg.Attempts()
+	}
+	policy := git.RetryPolicy{
+		Attempts: attempts,
+		Delay:    time.Second,
+		OnRetry: func(attempt int, delay time.Duration, err error) {
+			reason := err.Error()
+			var gitErr *git.GitError
+			if errors.As(err, &gitErr) {
+				reason = gitErr.Summary()
+			}
+			fmt.Fprintf(os.Stderr, "⚠️  git %s failed (%s); retrying in %s (%d/%d)\n",
+				args[0], reason, delay, attempt+1, attempts)
+		},
+	}
+
+	var out string
+	err := git.Retry(policy, func() error {
+		var err error
+		out, err = git.RunGitCommand(dir, args...)
+		return err
+	})
+	return out, err
 }
 
-// FileExists returns true only if the path exists.
-// Returns false for permission errors, broken symlinks, and other stat failures.
-func (r *RealEffects) FileExists(path string) bool {
-	_, err := os.Stat(path)
-	return !os.IsNotExist(err)
+func (r *RealEffects) RunGitInteractive(dir string, args ...string) error {
+	return git.RunGitInteractive(dir, args...)
+}
+
+// Stat follows symlinks, so a broken one doesn't exist.
+func (r *RealEffects) Stat(path string) (exists, isDir bool, err error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	return true, info.IsDir(), nil
 }
 
 func (r *RealEffects) MkdirAll(path string, perm os.FileMode) error {
 	return os.MkdirAll(path, perm)
 }
 
+func (r *RealEffects) Rename(oldPath, newPath string) error {
+	return os.Rename(oldPath, newPath)
+}
+
+func (r *RealEffects) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+func (r *RealEffects) WriteFile(path string, data []byte, perm os.FileMode) error {
+	return os.WriteFile(path, data, perm)
+}
+
+func (r *RealEffects) Symlink(target, link string) error {
+	return os.Symlink(target, link)
+}
+
+func (r *RealEffects) CloneTree(src, dst string) error {
+	return reflink.CloneTree(src, dst)
+}
+
+func (r *RealEffects) ReflinkSupported(srcDir, dstDir string) bool {
+	return reflink.Supported(srcDir, dstDir)
+}
+
+func (r *RealEffects) RemoveAll(path string) error {
+	return os.RemoveAll(path)
+}
+
+// DirSize adds up the sizes of the files under path, without following
+// symlinks.
+func (r *RealEffects) DirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+func (r *RealEffects) CopyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, info.Mode().Perm())
+}
+
 func (r *RealEffects) LoadConfig(currentPath, mainPath string) (*config.Config, error) {
 	return config.Load(currentPath, mainPath)
 }
 
-func (r *RealEffects) IsTrusted(repoRoot string) (bool, error) {
-	return trust.IsRepoTrusted(repoRoot)
+// hookTrigger says when hooks of hookType run, for the trust prompt that
+// sprout add, open and rebase-all share.
+func hookTrigger(hookType string) string {
+	switch core.HookType(hookType) {
+	case core.HookTypeOnOpen:
+		return "when a worktree is opened"
+	case core.HookTypePostSync:
+		return "in each worktree 'sprout rebase-all' updates"
+	default:
+		return "when a worktree is created"
+	}
+}
+
+func (r *RealEffects) LoadUserConfig() (*config.UserConfig, error) {
+	return config.LoadUser()
 }
 
-func (r *RealEffects) TrustRepo(repoRoot string) error {
-	return trust.TrustRepo(repoRoot)
+func (r *RealEffects) IsTrusted(repoRoot, worktreePath string) (bool, error) {
+	return trust.IsRepoTrusted(repoRoot, worktreePath)
+}
+
+func (r *RealEffects) TrustRepo(repoRoot, worktreePath string) error {
+	return trust.TrustRepo(repoRoot, worktreePath)
 }
 
 func (r *RealEffects) UntrustRepo(repoRoot string) error {
@@ -74,7 +213,23 @@ func (r *RealEffects) UntrustRepo(repoRoot string) error {
 }
 
 func (r *RealEffects) OpenEditor(path string) error {
-	return editor.Open(path)
+	userCfg, err := config.LoadUser()
+	if err != nil {
+		return err
+	}
+	return editor.Open(path, userCfg.EditorFallbacks...)
+}
+
+func (r *RealEffects) OpenFile(worktreePath, file string, line, column int) error {
+	userCfg, err := config.LoadUser()
+	if err != nil {
+		return err
+	}
+	return editor.OpenFile(worktreePath, editor.Location{File: file, Line: line, Column: column}, userCfg.EditorFallbacks...)
+}
+
+func (r *RealEffects) OpenURL(url string) error {
+	return editor.OpenURL(url)
 }
 
 func (r *RealEffects) Print(msg string) {
@@ -85,35 +240,157 @@ func (r *RealEffects) PrintErr(msg string) {
 	fmt.Fprintln(os.Stderr, msg)
 }
 
-func (r *RealEffects) SelectBranch(branches []git.Branch) (int, error) {
-	return tui.SelectOne(branches, branchLabel, nil)
+// ReportProgress prints "[step/total] label…" to stderr. When stderr is not a
+// terminal (CI logs, pipes) the line is plain ASCII without color.
+func (r *RealEffects) ReportProgress(step, total int, label string) {
+	if term.IsTerminal(int(os.Stderr.Fd())) {
+		fmt.Fprintf(os.Stderr, "%s %s…\n", style.Gray(fmt.Sprintf("[%d/%d]", step, total)), label)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[%d/%d] %s...\n", step, total, label)
+}
+
+// Interactive reports whether sprout may prompt: stdin is a terminal and
+// SPROUT_NON_INTERACTIVE (set by --non-interactive) is not.
+func Interactive() bool {
+	if v := os.Getenv("SPROUT_NON_INTERACTIVE"); v != "" && v != "0" {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+func (r *RealEffects) SelectBranch(branches []core.BranchChoice) (int, error) {
+	if !Interactive() {
+		return 0, fmt.Errorf("%w; pass a branch", ErrNonInteractive)
+	}
+	// Only the available branches can be picked, so only they are listed;
+	// the others are dimmed in the preview window, which takes colors and
+	// stays put while the query changes
+	var available []int
+	for i, c := range branches {
+		if c.Available() {
+			available = append(available, i)
+		}
+	}
+	label := func(i int) string { return branchLabel(branches[i].Branch) }
+	idx, err := tui.SelectWithNote(available, label, nil, checkedOutNote(branches))
+	if err != nil {
+		return -1, err
+	}
+	return available[idx], nil
+}
+
+// checkedOutNote lists the branches checked out in a worktree, with where,
+// dimmed line by line, for the preview window of the branch picker. It is
+// "" when every branch is available.
+func checkedOutNote(branches []core.BranchChoice) string {
+	home, _ := os.UserHomeDir()
+	var rows [][2]string
+	width := 0
+	for _, c := range branches {
+		if !c.Available() {
+			rows = append(rows, [2]string{branchLabel(c.Branch), core.ShortenPathWithHome(c.CheckedOutAt, home)})
+			width = max(width, style.Width(rows[len(rows)-1][0]))
+		}
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+	lines := []string{"Checked out in a worktree already, so not listed:", ""}
+	for _, row := range rows {
+		lines = append(lines, "  "+style.PadRight(row[0], width)+"  "+row[1])
+	}
+	lines = append(lines, "", "git checks out a branch in one worktree at a time;", "open one with 'sprout open <path>'.")
+	for i, line := range lines {
+		lines[i] = style.Gray(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *RealEffects) Confirm(question string) (bool, error) {
+	if !Interactive() {
+		return false, ErrNonInteractive
+	}
+	fmt.Fprint(os.Stderr, style.Bold(question+" [y/N]:")+" ")
+	response, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("failed to read user input: %w", err)
+	}
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes", nil
+}
+
+func (r *RealEffects) Choose(question string, choices []string) (int, error) {
+	if !Interactive() {
+		return -1, ErrNonInteractive
+	}
+	fmt.Fprintln(os.Stderr, style.Bold(question))
+	for i, choice := range choices {
+		fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, choice)
+	}
+	numbers := "1"
+	if len(choices) > 1 {
+		numbers = fmt.Sprintf("1-%d", len(choices))
+	}
+	fmt.Fprint(os.Stderr, style.Bold("Choose "+numbers+", or press Enter to cancel:")+" ")
+	response, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return -1, fmt.Errorf("failed to read user input: %w", err)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(response))
+	if err != nil || n < 1 || n > len(choices) {
+		return -1, errors.New("cancelled")
+	}
+	return n - 1, nil
 }
 
 func (r *RealEffects) SelectWorktree(worktrees []git.Worktree) (int, error) {
+	if !Interactive() {
+		return 0, fmt.Errorf("%w; pass a branch or path", ErrNonInteractive)
+	}
+
 	// Pre-compute statuses for all worktrees in parallel
-	statuses := make([]git.WorktreeStatus, len(worktrees))
-	var wg sync.WaitGroup
-	for i, wt := range worktrees {
-		wg.Add(1)
-		go func(idx int, path string) {
-			defer wg.Done()
-			statuses[idx] = git.GetWorktreeStatus(path)
-		}(i, wt.Path)
+	var defaultBranch string
+	if len(worktrees) > 0 {
+		defaultBranch = configuredDefaultBranch(worktrees[0].Path)
+	}
+	statuses := git.GetWorktreeStatuses(worktrees, recordedBases(), defaultBranch)
+	userCfg, _ := config.LoadUser()
+	counts := userCfg.ShowsStatusCounts()
+
+	// Pinned worktrees are offered first; the caller gets its own index back
+	metadata, _ := sprout.LoadMetadata()
+	order := core.PinnedFirst(len(worktrees), func(i int) bool { return metadata[worktrees[i].Path].Pinned })
+	sproutRoot, _ := sprout.GetSproutRoot()
+	ordered := make([]git.Worktree, len(order))
+	for j, i := range order {
+		ordered[j] = worktrees[i]
 	}
-	wg.Wait()
 
 	// Create label function with pre-computed statuses
 	labelFunc := func(w git.Worktree) string {
+		label := worktreeLabel(w, sproutRoot)
 		// Find index of this worktree to get its status
 		for i, wt := range worktrees {
 			if wt.Path == w.Path {
-				return worktreeLabelWithStatus(w, statuses[i])
+				label = worktreeLabelWithStatus(w, sproutRoot, statuses[i], counts)
+				break
 			}
 		}
-		return worktreeLabel(w)
+		if metadata[w.Path].Pinned {
+			label = style.CurrentIcons().Pinned + " " + label
+		}
+		if note := metadata[w.Path].Note; note != "" {
+			label += "  " + style.CurrentIcons().Note + " " + note
+		}
+		return label
 	}
 
-	return tui.SelectOne(worktrees, labelFunc, nil)
+	idx, err := tui.SelectOne(ordered, labelFunc, nil)
+	if err != nil {
+		return idx, err
+	}
+	return order[idx], nil
 }
 
 // branchLabel returns the display name for a branch.
@@ -122,47 +399,36 @@ func branchLabel(b git.Branch) string {
 }
 
 // worktreeLabel returns a display label for a worktree.
-// Shows branch name if available, otherwise falls back to path (detached HEAD).
-func worktreeLabel(w git.Worktree) string {
+// Shows branch name if available; a detached HEAD under the sprout root
+// shows its label and commit, and falls back to path elsewhere.
+func worktreeLabel(w git.Worktree, sproutRoot string) string {
 	if w.Branch != "" {
 		return w.Branch
 	}
+	if sproutRoot != "" && core.IsUnderSproutRoot(w.Path, sproutRoot) {
+		return core.DetachedLabel(w.Path) + " (detached at " + core.ShortCommit(w.HEAD) + ")"
+	}
 	return w.Path
 }
 
 // worktreeLabelWithStatus returns a display label for a worktree with status icons.
-func worktreeLabelWithStatus(w git.Worktree, status git.WorktreeStatus) string {
-	label := worktreeLabel(w)
-	statusIcons := buildPlainStatusIcons(status)
+func worktreeLabelWithStatus(w git.Worktree, sproutRoot string, status git.WorktreeStatus, counts bool) string {
+	label := worktreeLabel(w, sproutRoot)
+	statusIcons := core.PlainStatusIcons(status, counts)
 	if statusIcons != "" {
 		return label + " " + statusIcons
 	}
 	return label
 }
 
-// buildPlainStatusIcons builds status icons without ANSI color codes.
-// Used for fuzzy finder which doesn't support ANSI escapes.
-func buildPlainStatusIcons(status git.WorktreeStatus) string {
-	var icons []string
-	if status.Dirty {
-		icons = append(icons, "✗")
-	}
-	if status.Ahead > 0 {
-		icons = append(icons, "↑")
-	}
-	if status.Behind > 0 {
-		icons = append(icons, "↓")
-	}
-	if status.Unmerged {
-		icons = append(icons, "↕")
-	}
-	return strings.Join(icons, " ")
-}
-
 func (r *RealEffects) RunHooks(repoRoot, worktreePath, mainWorktreePath string, commands []string, hookType string) error {
 	return hooks.RunHooks(repoRoot, worktreePath, mainWorktreePath, hooks.HookType(hookType))
 }
 
+func (r *RealEffects) HasRemote(repoRoot string) (bool, error) {
+	return git.HasRemote(repoRoot)
+}
+
 func (r *RealEffects) LocalBranchExists(repoRoot, branch string) (bool, error) {
 	return git.LocalBranchExists(repoRoot, branch)
 }
@@ -183,6 +449,111 @@ func (r *RealEffects) GetWorktreeRoot(repoRoot string) (string, error) {
 	return sprout.GetWorktreeRoot(repoRoot)
 }
 
+func (r *RealEffects) GetPathWorktreeRoot(repoRoot string) (string, error) {
+	return sprout.GetPathWorktreeRoot(repoRoot)
+}
+
+func (r *RealEffects) LockRepo(mainWorktreePath string, timeout time.Duration) (func(), error) {
+	return sprout.LockRepo(mainWorktreePath, timeout)
+}
+
+func (r *RealEffects) ListAdoptedWorktrees() ([]string, error) {
+	return sprout.LoadAdopted()
+}
+
+func (r *RealEffects) AdoptWorktree(repoRoot, path string) error {
+	return sprout.AdoptWorktree(repoRoot, path)
+}
+
+func (r *RealEffects) ForgetWorktree(path string) error {
+	return sprout.ForgetWorktree(path)
+}
+
+func (r *RealEffects) LoadRepoIndex() (map[string]string, error) {
+	return sprout.LoadRepoIndex()
+}
+
+func (r *RealEffects) SaveRepoIndex(repos map[string]string) error {
+	return sprout.SaveRepoIndex(repos)
+}
+
+func (r *RealEffects) IndexRepo(repoDir, mainPath string) error {
+	return sprout.IndexRepo(repoDir, mainPath)
+}
+
+func (r *RealEffects) WorktreeIndex(repoRoot, path string) (int, error) {
+	return sprout.WorktreeIndex(repoRoot, path)
+}
+
+func (r *RealEffects) FetchTicket(provider, id string) (tickets.Ticket, error) {
+	p, err := tickets.NewProvider(provider)
+	if err != nil {
+		return tickets.Ticket{}, err
+	}
+	return p.Fetch(id)
+}
+
+func (r *RealEffects) LoadTickets() (map[string]tickets.Ticket, error) {
+	return sprout.LoadTickets()
+}
+
+func (r *RealEffects) RecordTicket(path string, ticket tickets.Ticket) error {
+	return sprout.RecordTicket(path, ticket)
+}
+
+func (r *RealEffects) LoadWorktreeMetadata() (map[string]sprout.WorktreeMeta, error) {
+	return sprout.LoadMetadata()
+}
+
+func (r *RealEffects) RecordBase(path, base string) error {
+	return sprout.RecordBase(path, base)
+}
+
+func (r *RealEffects) SetPinned(path string, pinned bool) error {
+	return sprout.SetPinned(path, pinned)
+}
+
+func (r *RealEffects) SetNote(path, note string) error {
+	return sprout.SetNote(path, note)
+}
+
+func (r *RealEffects) SetExpiry(path string, expiresAt time.Time) error {
+	return sprout.SetExpiry(path, expiresAt)
+}
+
+func (r *RealEffects) ForgetMetadata(path string) error {
+	return sprout.ForgetMetadata(path)
+}
+
+func (r *RealEffects) LoadWorkspaces() ([]sprout.Workspace, error) {
+	return sprout.LoadWorkspaces()
+}
+
+func (r *RealEffects) SaveWorkspace(name string, repos []string) error {
+	return sprout.SaveWorkspace(name, repos)
+}
+
+func (r *RealEffects) DeleteWorkspace(name string) error {
+	return sprout.DeleteWorkspace(name)
+}
+
+// recordedBases returns the recorded base of each worktree that has one.
+// It is best-effort: without the metadata, unmerged commits are counted
+// against the default branch.
+func recordedBases() map[string]string {
+	metadata, err := sprout.LoadMetadata()
+	if err != nil {
+		return nil
+	}
+	bases := make(map[string]string, len(metadata))
+	for path, meta := range metadata {
+		if meta.Base != "" {
+			bases[path] = meta.Base
+		}
+	}
+	return bases
+}
+
 func (r *RealEffects) ReadDir(path string) ([]os.DirEntry, error) {
 	return os.ReadDir(path)
 }
@@ -192,42 +563,148 @@ func (r *RealEffects) UserHomeDir() (string, error) {
 }
 
 func (r *RealEffects) GetWorktreeStatus(path string) git.WorktreeStatus {
-	return git.GetWorktreeStatus(path)
+	defer timing.Record(timing.Status, "worktree status", time.Now())
+	return git.GetWorktreeStatusAgainst(path, recordedBases()[path], configuredDefaultBranch(path))
+}
+
+func (r *RealEffects) GetDefaultBranch(path string) (string, error) {
+	return git.GetDefaultBranch(path)
+}
+
+// configuredDefaultBranch returns default_branch from the .sprout.yml of
+// the worktree at path, if any. .sprout.yml is tracked, so every worktree
+// of a repository has one; an unreadable config means git decides.
+func configuredDefaultBranch(path string) string {
+	cfg, err := config.Load(path, "")
+	if err != nil {
+		return ""
+	}
+	return cfg.DefaultBranch
+}
+
+func (r *RealEffects) WorktreeLastUsed(path string) (time.Time, error) {
+	return git.LastUsed(path)
+}
+
+func (r *RealEffects) ReadHead(dir string) (string, string, error) {
+	return git.ReadHead(dir)
+}
+
+func (r *RealEffects) LoadTimings() ([]timing.Sample, error) {
+	return sprout.LoadTimings()
+}
+
+func (r *RealEffects) LoadTelemetry() (*telemetry.State, error) {
+	return sprout.LoadTelemetry()
+}
+
+// EmitEvent names the repository of event by its main worktree, whichever
+// worktree the action ran in.
+func (r *RealEffects) EmitEvent(event events.Event) error {
+	userCfg, err := config.LoadUser()
+	if err != nil {
+		return err
+	}
+	if userCfg.EventsCommand == "" && userCfg.EventsSocket == "" {
+		return nil
+	}
+	if main, err := git.GetMainWorktreePathIn(event.Repo); err == nil {
+		event.Repo = main
+	}
+	return events.Emit(userCfg.EventsCommand, userCfg.EventsSocket, event)
+}
+
+func (r *RealEffects) SetTelemetry(enabled bool) error {
+	state, err := sprout.LoadTelemetry()
+	if err != nil {
+		return err
+	}
+	state.Enabled = enabled
+	state.Reset(time.Now())
+	return sprout.SaveTelemetry(state)
+}
+
+func (r *RealEffects) CacheStatuses(statuses map[string]git.WorktreeStatus) error {
+	return sprout.CacheStatuses(statuses, time.Now())
+}
+
+func (r *RealEffects) LoadCachedStatus(path string) (sprout.CachedStatus, bool, error) {
+	return sprout.LoadCachedStatus(path)
 }
 
 func (r *RealEffects) PromptTrustRepo(mainWorktreePath, hookType string, hookCommands []string) error {
-	// Check if stdin is a terminal (interactive mode)
-	if !term.IsTerminal(int(os.Stdin.Fd())) {
+	// Trust that lapsed because the hooks changed shows what changed
+	project, found, err := trust.FindTrustedProject(mainWorktreePath)
+	found = found && err == nil
+	var changes string
+	if found {
+		if cfg, err := config.Load(mainWorktreePath, mainWorktreePath); err == nil && project.HooksChanged(cfg) {
+			changes = core.FormatHookChanges(project.Hooks, cfg.HookCommands())
+		}
+	}
+
+	// Trust given to another clone of the same origin needs confirming here
+	var elsewhere string
+	if found && project.RepoRoot != mainWorktreePath {
+		elsewhere = fmt.Sprintf("You trusted this repository's origin at %s, not here.", project.RepoRoot)
+	}
+
+	if !Interactive() {
 		// Not a terminal - return error with helpful guidance for non-interactive environments
 		var guidance strings.Builder
-		guidance.WriteString("\nRepository has hooks but is not trusted.\n\n")
-		guidance.WriteString(fmt.Sprintf("Hooks that would run on '%s':\n", hookType))
-		for _, cmd := range hookCommands {
-			guidance.WriteString(fmt.Sprintf("  • %s\n", cmd))
+		if elsewhere != "" {
+			guidance.WriteString("\n" + elsewhere + "\n")
+		}
+		if changes != "" {
+			guidance.WriteString("\nRepository hooks changed since it was trusted:\n\n")
+			guidance.WriteString(changes)
+		} else {
+			guidance.WriteString("\nRepository has hooks but is not trusted.\n\n")
+			guidance.WriteString(fmt.Sprintf("Hooks that would run on '%s':\n", hookType))
+			for _, cmd := range hookCommands {
+				guidance.WriteString(fmt.Sprintf("  • %s\n", cmd))
+			}
 		}
 		guidance.WriteString("\nTo allow these hooks, run:\n")
 		guidance.WriteString("  sprout trust\n\n")
 		guidance.WriteString("To skip hooks this time:\n")
 		guidance.WriteString("  Use the --no-hooks flag")
-		return fmt.Errorf("%s", guidance.String())
+		return fmt.Errorf("%w%s", ErrNonInteractive, guidance.String())
 	}
 
-	// Display warning and hooks
-	fmt.Fprintln(os.Stderr, "\n⚠️  This repository defines Sprout hooks in .sprout.yml:")
-	fmt.Fprintln(os.Stderr, "")
-	fmt.Fprintf(os.Stderr, "  %s:\n", hookType)
-	for _, cmd := range hookCommands {
-		fmt.Fprintf(os.Stderr, "    - %s\n", cmd)
+	// Explain why a previously trusted repository is prompting again
+	if elsewhere != "" {
+		fmt.Fprintln(os.Stderr, "\n"+elsewhere)
+	} else if found {
+		if ttl, err := trust.TrustTTL(); err == nil {
+			if expiresAt, ok := project.ExpiresAt(ttl); ok && project.IsExpired(ttl, time.Now()) {
+				fmt.Fprintf(os.Stderr, "\n⏰ Trust for this repository expired on %s and needs re-confirmation.\n", expiresAt.Format("2006-01-02"))
+			}
+		}
+	}
+
+	// Display warning and hooks, or how they changed
+	if changes != "" {
+		fmt.Fprintln(os.Stderr, "\n⚠️  "+style.Yellow("The hooks in .sprout.yml changed since you trusted this repository:"))
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprint(os.Stderr, changes)
+	} else {
+		fmt.Fprintln(os.Stderr, "\n⚠️  "+style.Yellow("This repository defines Sprout hooks in .sprout.yml:"))
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintf(os.Stderr, "  %s:\n", hookType)
+		for _, cmd := range hookCommands {
+			fmt.Fprintf(os.Stderr, "    - %s\n", cmd)
+		}
 	}
 	fmt.Fprintln(os.Stderr, "")
-	fmt.Fprintln(os.Stderr, "These commands will be executed automatically when a worktree is created.")
+	fmt.Fprintf(os.Stderr, "These commands will be executed automatically %s.\n", hookTrigger(hookType))
 	fmt.Fprintln(os.Stderr, "")
 	fmt.Fprintln(os.Stderr, "Do you want to allow hooks from this repository?")
 	fmt.Fprintln(os.Stderr, "Press 'y' to run them, or run again with --no-hooks to skip.")
 	fmt.Fprintln(os.Stderr, "")
 
 	// Prompt for consent
-	fmt.Fprint(os.Stderr, "Allow hooks? [y/N]: ")
+	fmt.Fprint(os.Stderr, style.Bold("Allow hooks? [y/N]:")+" ")
 
 	reader := bufio.NewReader(os.Stdin)
 	response, err := reader.ReadString('\n')
@@ -239,7 +716,7 @@ func (r *RealEffects) PromptTrustRepo(mainWorktreePath, hookType string, hookCom
 
 	if response == "y" || response == "yes" {
 		// Trust the repository using the effects layer for consistency
-		if err := r.TrustRepo(mainWorktreePath); err != nil {
+		if err := r.TrustRepo(mainWorktreePath, mainWorktreePath); err != nil {
 			return fmt.Errorf("failed to trust repository: %w", err)
 		}
 		fmt.Fprintln(os.Stderr, "✓ Repository trusted")
@@ -247,5 +724,5 @@ func (r *RealEffects) PromptTrustRepo(mainWorktreePath, hookType string, hookCom
 	}
 
 	// User declined
-	return fmt.Errorf("repository not trusted: user declined")
+	return ErrTrustDeclined
 }
@@ -0,0 +1,35 @@
+package effects
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/style"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHookTrigger(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "when a worktree is created", hookTrigger(string(core.HookTypeOnCreate)))
+	assert.Equal(t, "when a worktree is opened", hookTrigger(string(core.HookTypeOnOpen)))
+	assert.Equal(t, "in each worktree 'sprout rebase-all' updates", hookTrigger(string(core.HookTypePostSync)))
+}
+
+func TestCheckedOutNote(t *testing.T) {
+	t.Parallel()
+
+	branches := []core.BranchChoice{
+		{Branch: git.Branch{Name: "feature", DisplayName: "feature"}},
+		{Branch: git.Branch{Name: "main", DisplayName: "main"}, CheckedOutAt: "/code/app"},
+		{Branch: git.Branch{Name: "release/1.x", DisplayName: "release/1.x"}, CheckedOutAt: "/code/app-release"},
+	}
+	assert.Equal(t, "Checked out in a worktree already, so not listed:\n\n"+
+		"  main         /code/app\n"+
+		"  release/1.x  /code/app-release\n\n"+
+		"git checks out a branch in one worktree at a time;\n"+
+		"open one with 'sprout open <path>'.", style.Strip(checkedOutNote(branches)))
+
+	assert.Empty(t, checkedOutNote(branches[:1]), "nothing to note when every branch is available")
+}
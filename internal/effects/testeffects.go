@@ -2,46 +2,91 @@ package effects
 
 import (
 	"fmt"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/m44rten1/sprout/internal/config"
 	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/events"
 	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/sprout"
+	"github.com/m44rten1/sprout/internal/telemetry"
+	"github.com/m44rten1/sprout/internal/tickets"
+	"github.com/m44rten1/sprout/internal/timing"
 )
 
 // TestEffects is a mock implementation of Effects for testing.
 // It records all method calls and returns predefined values.
 type TestEffects struct {
+	// mu guards the methods the executor calls, so Parallel groups can run
+	// against TestEffects without data races.
+	mu sync.Mutex
+
 	// Predefined return values
 	RepoRoot         string
 	MainWorktreePath string
 	Worktrees        []git.Worktree
 	Branches         []git.Branch
 	Config           *config.Config
+	UserConfig       *config.UserConfig
 	TrustedRepos     map[string]bool
 	Files            map[string]bool   // Paths that "exist"
+	RegularFiles     map[string]bool   // Paths in Files that are files; the others are directories
+	StatErrors       map[string]error  // path -> error returned by Stat
 	GitCommandOutput map[string]string // Key: "dir\nargs..." -> output
 	GitCommandErrors map[string]error  // Key: "dir\nargs..." -> error
 
 	// Branch existence mocking
 	LocalBranches  map[string]bool // branch name -> exists locally
 	RemoteBranches map[string]bool // branch name -> exists on remote
+	NoRemote       bool            // The repository has no remote (HasRemote)
 
 	// Worktree path calculation
 	WorktreePaths      map[string]string // branch -> path mapping
 	GetWorktreePathErr error
 
 	// Sprout paths
-	SproutRoot         string
-	WorktreeRoot       string
+	SproutRoot       string
+	WorktreeRoot     string
+	PathWorktreeRoot string // Defaults to WorktreeRoot when empty
+
+	// Adopted worktrees
+	Adopted            []string          // Paths of adopted worktrees
+	WorktreeIndices    map[string]int    // path -> assigned index
+	RepoIndex          map[string]string // repository directory -> main worktree path
 	GetSproutRootErr   error
 	GetWorktreeRootErr error
+	LockRepoErr        error
+
+	// Tickets
+	Tickets         map[string]tickets.Ticket // ticket id -> ticket returned by FetchTicket
+	WorktreeTickets map[string]tickets.Ticket // worktree path -> recorded ticket
+
+	// Worktree metadata
+	WorktreeMetadata map[string]sprout.WorktreeMeta // worktree path -> metadata, updated by RecordBase, SetPinned, SetNote and SetExpiry and cleared by ForgetMetadata
+
+	// Workspaces
+	Workspaces map[string][]string // workspace name -> member repositories
 
 	// Filesystem (additional)
-	DirEntries        map[string][]os.DirEntry // path -> entries
-	UserHome          string
-	WorktreeStatuses  map[string]git.WorktreeStatus // path -> status
+	DirEntries       map[string][]os.DirEntry // path -> entries
+	DirSizes         map[string]int64         // path -> DirSize result
+	FileContents     map[string][]byte        // path -> content for ReadFile, written by WriteFile
+	UserHome         string
+	CanReflink       bool                           // Result of ReflinkSupported
+	WorktreeStatuses map[string]git.WorktreeStatus  // path -> status
+	DefaultBranch    string                         // Returned by GetDefaultBranch; "main" when empty
+	LastUsed         map[string]time.Time           // path -> WorktreeLastUsed result
+	Heads            map[string]string              // worktree root -> HEAD returned by ReadHead
+	CachedStatuses   map[string]sprout.CachedStatus // path -> status cache entry
+	Timings          []timing.Sample                // Returned by LoadTimings
+	Telemetry        telemetry.State                // Returned by LoadTelemetry, updated by SetTelemetry
+	Events           []events.Event                 // Recorded by EmitEvent
 
 	// Error injection - set these to simulate failures
 	GetRepoRootErr         error
@@ -49,12 +94,44 @@ type TestEffects struct {
 	ListWorktreesErr       error
 	ListBranchesErr        error
 	MkdirAllErr            error
+	RenameErr              error
+	CopyFileErr            error
+	SymlinkErr             error
+	CloneTreeErr           error
+	RemoveAllErr           error
+	DirSizeErr             error
+	CacheStatusesErr       error
+	LoadTimingsErr         error
+	SetTelemetryErr        error
+	EmitEventErr           error
+	WriteFileErr           error
+	RunGitInteractiveErr   error
+	ListAdoptedErr         error
+	AdoptWorktreeErr       error
+	ForgetWorktreeErr      error
+	WorktreeIndexErr       error
+	LoadRepoIndexErr       error
+	FetchTicketErr         error
+	LoadTicketsErr         error
+	RecordTicketErr        error
+	LoadMetadataErr        error
+	RecordBaseErr          error
+	SetPinnedErr           error
+	SetNoteErr             error
+	SetExpiryErr           error
+	ForgetMetadataErr      error
+	LoadWorkspacesErr      error
+	SaveWorkspaceErr       error
 	LoadConfigErr          error
+	LoadUserConfigErr      error
 	IsTrustedErr           error
 	TrustRepoErr           error
 	UntrustRepoErr         error
 	OpenEditorErr          error
+	OpenURLErr             error
+	OpenFileErr            error
 	RunHooksErr            error
+	HasRemoteErr           error
 	LocalBranchExistsErr   error
 	RemoteBranchExistsErr  error
 	PromptTrustRepoErr     error
@@ -65,6 +142,11 @@ type TestEffects struct {
 	SelectedBranchIndex   int
 	SelectedWorktreeIndex int
 	SelectionError        error
+	Confirmed             bool // Answer returned by Confirm
+	ConfirmErr            error
+	Choice                int // Index returned by Choose
+	ChooseErr             error
+	TrustDeclined         bool // PromptTrustRepo answers no (ErrTrustDeclined) instead of yes
 
 	// Call counters (structured tracking)
 	GetRepoRootCalls         int
@@ -72,49 +154,87 @@ type TestEffects struct {
 	ListWorktreesCalls       int
 	ListBranchesCalls        int
 	RunGitCommandCalls       int
-	FileExistsCalls          int
+	StatCalls                int
 	MkdirAllCalls            int
+	RenameCalls              int
+	CopyFileCalls            int
+	SymlinkCalls             int
+	CloneTreeCalls           int
+	CacheStatusesCalls       int
+	WriteFileCalls           int
 	LoadConfigCalls          int
 	IsTrustedCalls           int
 	TrustRepoCalls           int
 	UntrustRepoCalls         int
 	OpenEditorCalls          int
+	OpenURLCalls             int
+	OpenFileCalls            int
 	PrintCalls               int
 	PrintErrCalls            int
 	SelectBranchCalls        int
 	SelectWorktreeCalls      int
+	ConfirmCalls             int
+	ChooseCalls              int
 	RunHooksCalls            int
 	LocalBranchExistsCalls   int
 	RemoteBranchExistsCalls  int
 	GetWorktreePathCalls     int
 	GetSproutRootCalls       int
 	GetWorktreeRootCalls     int
+	GetPathWorktreeRootCalls int
+	ListAdoptedCalls         int
+	AdoptWorktreeCalls       int
+	ForgetWorktreeCalls      int
+	WorktreeIndexCalls       int
+	SaveRepoIndexCalls       int
+	FetchTicketCalls         int
+	LoadTicketsCalls         int
+	RecordTicketCalls        int
+	RecordBaseCalls          int
+	SetPinnedCalls           int
+	SetNoteCalls             int
+	SetExpiryCalls           int
+	ForgetMetadataCalls      int
+	SaveWorkspaceCalls       int
+	DeleteWorkspaceCalls     int
+	SetTelemetryCalls        int
 	PromptTrustRepoCalls     int
 	ReadDirCalls             int
 	UserHomeDirCalls         int
 	GetWorktreeStatusCalls   int
 
 	// Call tracking (captured side effects and arguments)
-	ListWorktreesArgs         []string   // repoRoot args passed to ListWorktrees
-	ListBranchesArgs          []string   // repoRoot args passed to ListBranches
-	LoadConfigCurrentArgs     []string   // currentPath args passed to LoadConfig
-	LoadConfigMainArgs        []string   // mainPath args passed to LoadConfig
-	IsTrustedArgs             []string   // repoRoot args passed to IsTrusted
-	TrustRepoRepos            []string   // Repos that had TrustRepo called
-	UntrustRepoRepos          []string   // Repos that had UntrustRepo called
-	PrintedMsgs               []string   // Messages printed via Print
-	PrintedErrs               []string   // Messages printed via PrintErr
-	GitCommands               []GitCmd   // Git commands executed
-	OpenedPaths               []string   // Paths opened in editor
-	CreatedDirs               []string   // Directories created via MkdirAll
-	RunHooksInvocations       []HookCall // Hooks that were run
-	LocalBranchExistsQueries  []BranchQuery
-	RemoteBranchExistsQueries []BranchQuery
-	GetWorktreePathQueries    []WorktreePathQuery
+	ListWorktreesArgs          []string     // repoRoot args passed to ListWorktrees
+	ListBranchesArgs           []string     // repoRoot args passed to ListBranches
+	LoadConfigCurrentArgs      []string     // currentPath args passed to LoadConfig
+	LoadConfigMainArgs         []string     // mainPath args passed to LoadConfig
+	IsTrustedArgs              []string     // repoRoot args passed to IsTrusted
+	TrustRepoRepos             []string     // Repos that had TrustRepo called
+	UntrustRepoRepos           []string     // Repos that had UntrustRepo called
+	PrintedMsgs                []string     // Messages printed via Print
+	PrintedErrs                []string     // Messages printed via PrintErr
+	ProgressReports            []string     // "[step/total] label" per ReportProgress call
+	GitCommands                []GitCmd     // Git commands executed
+	InteractiveGitCommands     []GitCmd     // Git commands run via RunGitInteractive
+	OpenedPaths                []string     // Paths opened in editor
+	OpenedURLs                 []string     // URLs opened in the browser
+	OpenedFiles                []FileOpen   // Files opened in the editor at a position
+	CreatedDirs                []string     // Directories created via MkdirAll
+	Renames                    []RenameCall // Paths moved via Rename
+	CopiedFiles                []RenameCall // Files copied via CopyFile (From: src, To: dst)
+	Symlinks                   []RenameCall // Links created via Symlink (From: link, To: target)
+	ClonedTrees                []RenameCall // Trees cloned via CloneTree (From: src, To: dst)
+	RemovedDirs                []string     // Paths deleted via RemoveAll
+	LockedRepos                []string     // Repos locked via LockRepo and not unlocked yet
+	RunHooksInvocations        []HookCall   // Hooks that were run
+	LocalBranchExistsQueries   []BranchQuery
+	RemoteBranchExistsQueries  []BranchQuery
+	GetWorktreePathQueries     []WorktreePathQuery
 	GetWorktreeRootArgs        []string // repoRoot args passed to GetWorktreeRoot
 	PromptTrustRepoInvocations []PromptTrustCall
-	ReadDirArgs                []string // path args passed to ReadDir
-	GetWorktreeStatusArgs      []string // path args passed to GetWorktreeStatus
+	ReadDirArgs                []string              // path args passed to ReadDir
+	SelectBranchArgs           [][]core.BranchChoice // branches args passed to SelectBranch
+	GetWorktreeStatusArgs      []string              // path args passed to GetWorktreeStatus
 }
 
 // GitCmd represents a recorded git command execution.
@@ -123,6 +243,20 @@ type GitCmd struct {
 	Args []string
 }
 
+// RenameCall represents a recorded file or directory move.
+type RenameCall struct {
+	From string
+	To   string
+}
+
+// FileOpen represents a recorded OpenFile call.
+type FileOpen struct {
+	WorktreePath string
+	File         string
+	Line         int
+	Column       int
+}
+
 // HookCall represents a recorded hook execution.
 type HookCall struct {
 	RepoRoot         string
@@ -154,40 +288,62 @@ type PromptTrustCall struct {
 // NewTestEffects creates a new TestEffects with sensible defaults.
 func NewTestEffects() *TestEffects {
 	return &TestEffects{
-		RepoRoot:                  "/test/repo",
-		MainWorktreePath:          "/test/repo",
-		Worktrees:                 []git.Worktree{},
-		Branches:                  []git.Branch{},
-		Config:                    &config.Config{},
-		TrustedRepos:              make(map[string]bool),
-		Files:                     make(map[string]bool),
-		GitCommandOutput:          make(map[string]string),
-		GitCommandErrors:          make(map[string]error),
-		LocalBranches:             make(map[string]bool),
-		RemoteBranches:            make(map[string]bool),
-		WorktreePaths:             make(map[string]string),
-		ListWorktreesArgs:         []string{},
-		ListBranchesArgs:          []string{},
-		LoadConfigCurrentArgs:     []string{},
-		LoadConfigMainArgs:        []string{},
-		IsTrustedArgs:             []string{},
-		TrustRepoRepos:            []string{},
-		PrintedMsgs:               []string{},
-		PrintedErrs:               []string{},
-		GitCommands:               []GitCmd{},
-		OpenedPaths:               []string{},
-		CreatedDirs:               []string{},
-		RunHooksInvocations:       []HookCall{},
-		LocalBranchExistsQueries:  []BranchQuery{},
-		RemoteBranchExistsQueries: []BranchQuery{},
-		GetWorktreePathQueries:    []WorktreePathQuery{},
+		RepoRoot:                   "/test/repo",
+		MainWorktreePath:           "/test/repo",
+		Worktrees:                  []git.Worktree{},
+		Branches:                   []git.Branch{},
+		Config:                     &config.Config{},
+		TrustedRepos:               make(map[string]bool),
+		Files:                      make(map[string]bool),
+		RegularFiles:               make(map[string]bool),
+		StatErrors:                 make(map[string]error),
+		GitCommandOutput:           make(map[string]string),
+		GitCommandErrors:           make(map[string]error),
+		LocalBranches:              make(map[string]bool),
+		RemoteBranches:             make(map[string]bool),
+		WorktreePaths:              make(map[string]string),
+		ListWorktreesArgs:          []string{},
+		ListBranchesArgs:           []string{},
+		LoadConfigCurrentArgs:      []string{},
+		LoadConfigMainArgs:         []string{},
+		IsTrustedArgs:              []string{},
+		TrustRepoRepos:             []string{},
+		PrintedMsgs:                []string{},
+		PrintedErrs:                []string{},
+		ProgressReports:            []string{},
+		GitCommands:                []GitCmd{},
+		OpenedPaths:                []string{},
+		OpenedURLs:                 []string{},
+		OpenedFiles:                []FileOpen{},
+		CreatedDirs:                []string{},
+		Renames:                    []RenameCall{},
+		CopiedFiles:                []RenameCall{},
+		Symlinks:                   []RenameCall{},
+		ClonedTrees:                []RenameCall{},
+		RemovedDirs:                []string{},
+		LockedRepos:                []string{},
+		RunHooksInvocations:        []HookCall{},
+		LocalBranchExistsQueries:   []BranchQuery{},
+		RemoteBranchExistsQueries:  []BranchQuery{},
+		GetWorktreePathQueries:     []WorktreePathQuery{},
 		GetWorktreeRootArgs:        []string{},
 		PromptTrustRepoInvocations: []PromptTrustCall{},
 		SproutRoot:                 "/home/user/.local/share/sprout",
 		WorktreeRoot:               "/home/user/.local/share/sprout/test-12345678",
 		DirEntries:                 make(map[string][]os.DirEntry),
+		DirSizes:                   make(map[string]int64),
+		FileContents:               make(map[string][]byte),
 		UserHome:                   "/home/user",
 		WorktreeStatuses:           make(map[string]git.WorktreeStatus),
+		LastUsed:                   make(map[string]time.Time),
+		Heads:                      make(map[string]string),
+		CachedStatuses:             make(map[string]sprout.CachedStatus),
+		WorktreeIndices:            make(map[string]int),
+		RepoIndex:                  make(map[string]string),
+		Tickets:                    make(map[string]tickets.Ticket),
+		WorktreeTickets:            make(map[string]tickets.Ticket),
+		WorktreeMetadata:           make(map[string]sprout.WorktreeMeta),
+		Workspaces:                 make(map[string][]string),
 		ReadDirArgs:                []string{},
 		GetWorktreeStatusArgs:      []string{},
 	}
@@ -234,6 +390,8 @@ func (t *TestEffects) ListBranches(repoRoot string) ([]git.Branch, error) {
 }
 
 func (t *TestEffects) RunGitCommand(dir string, args ...string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.RunGitCommandCalls++
 	// Copy args to avoid slice aliasing bugs
 	argsCopy := append([]string(nil), args...)
@@ -252,12 +410,29 @@ func (t *TestEffects) RunGitCommand(dir string, args ...string) (string, error)
 	return "", nil
 }
 
-func (t *TestEffects) FileExists(path string) bool {
-	t.FileExistsCalls++
-	return t.Files[path]
+func (t *TestEffects) RunGitInteractive(dir string, args ...string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.InteractiveGitCommands = append(t.InteractiveGitCommands, GitCmd{Dir: dir, Args: append([]string(nil), args...)})
+	return t.RunGitInteractiveErr
+}
+
+func (t *TestEffects) Stat(path string) (exists, isDir bool, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.StatCalls++
+	if err := t.StatErrors[path]; err != nil {
+		return false, false, err
+	}
+	if !t.Files[path] {
+		return false, false, nil
+	}
+	return true, !t.RegularFiles[path], nil
 }
 
 func (t *TestEffects) MkdirAll(path string, perm os.FileMode) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.MkdirAllCalls++
 	t.CreatedDirs = append(t.CreatedDirs, path)
 	if t.MkdirAllErr != nil {
@@ -268,6 +443,113 @@ func (t *TestEffects) MkdirAll(path string, perm os.FileMode) error {
 	return nil
 }
 
+func (t *TestEffects) Rename(oldPath, newPath string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.RenameCalls++
+	t.Renames = append(t.Renames, RenameCall{From: oldPath, To: newPath})
+	if t.RenameErr != nil {
+		return t.RenameErr
+	}
+	delete(t.Files, oldPath)
+	t.Files[newPath] = true
+	if t.RegularFiles[oldPath] {
+		delete(t.RegularFiles, oldPath)
+		t.RegularFiles[newPath] = true
+	}
+	return nil
+}
+
+// ReadFile returns FileContents[path], or an os.ErrNotExist error.
+func (t *TestEffects) ReadFile(path string) ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	data, ok := t.FileContents[path]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+	}
+	return data, nil
+}
+
+func (t *TestEffects) WriteFile(path string, data []byte, perm os.FileMode) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.WriteFileCalls++
+	if t.WriteFileErr != nil {
+		return t.WriteFileErr
+	}
+	t.FileContents[path] = data
+	t.Files[path] = true
+	t.RegularFiles[path] = true
+	return nil
+}
+
+func (t *TestEffects) Symlink(target, link string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.SymlinkCalls++
+	t.Symlinks = append(t.Symlinks, RenameCall{From: link, To: target})
+	if t.SymlinkErr != nil {
+		return t.SymlinkErr
+	}
+	t.Files[link] = true
+	return nil
+}
+
+func (t *TestEffects) CloneTree(src, dst string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.CloneTreeCalls++
+	t.ClonedTrees = append(t.ClonedTrees, RenameCall{From: src, To: dst})
+	if t.CloneTreeErr != nil {
+		return t.CloneTreeErr
+	}
+	t.Files[dst] = true
+	return nil
+}
+
+// RemoveAll records path and forgets it and everything under it in Files.
+func (t *TestEffects) RemoveAll(path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.RemovedDirs = append(t.RemovedDirs, path)
+	if t.RemoveAllErr != nil {
+		return t.RemoveAllErr
+	}
+	for file := range t.Files {
+		if file == path || strings.HasPrefix(file, path+string(filepath.Separator)) {
+			delete(t.Files, file)
+		}
+	}
+	return nil
+}
+
+func (t *TestEffects) DirSize(path string) (int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.DirSizeErr != nil {
+		return 0, t.DirSizeErr
+	}
+	return t.DirSizes[path], nil
+}
+
+func (t *TestEffects) ReflinkSupported(srcDir, dstDir string) bool {
+	return t.CanReflink
+}
+
+func (t *TestEffects) CopyFile(src, dst string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.CopyFileCalls++
+	t.CopiedFiles = append(t.CopiedFiles, RenameCall{From: src, To: dst})
+	if t.CopyFileErr != nil {
+		return t.CopyFileErr
+	}
+	t.Files[dst] = true
+	t.RegularFiles[dst] = true
+	return nil
+}
+
 func (t *TestEffects) LoadConfig(currentPath, mainPath string) (*config.Config, error) {
 	t.LoadConfigCalls++
 	t.LoadConfigCurrentArgs = append(t.LoadConfigCurrentArgs, currentPath)
@@ -281,7 +563,17 @@ func (t *TestEffects) LoadConfig(currentPath, mainPath string) (*config.Config,
 	return t.Config, nil
 }
 
-func (t *TestEffects) IsTrusted(repoRoot string) (bool, error) {
+func (t *TestEffects) LoadUserConfig() (*config.UserConfig, error) {
+	if t.LoadUserConfigErr != nil {
+		return nil, t.LoadUserConfigErr
+	}
+	if t.UserConfig == nil {
+		return &config.UserConfig{}, nil
+	}
+	return t.UserConfig, nil
+}
+
+func (t *TestEffects) IsTrusted(repoRoot, worktreePath string) (bool, error) {
 	t.IsTrustedCalls++
 	t.IsTrustedArgs = append(t.IsTrustedArgs, repoRoot)
 	if t.IsTrustedErr != nil {
@@ -290,7 +582,9 @@ func (t *TestEffects) IsTrusted(repoRoot string) (bool, error) {
 	return t.TrustedRepos[repoRoot], nil
 }
 
-func (t *TestEffects) TrustRepo(repoRoot string) error {
+func (t *TestEffects) TrustRepo(repoRoot, worktreePath string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.TrustRepoCalls++
 	t.TrustRepoRepos = append(t.TrustRepoRepos, repoRoot)
 	if t.TrustRepoErr != nil {
@@ -301,6 +595,8 @@ func (t *TestEffects) TrustRepo(repoRoot string) error {
 }
 
 func (t *TestEffects) UntrustRepo(repoRoot string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.UntrustRepoCalls++
 	t.UntrustRepoRepos = append(t.UntrustRepoRepos, repoRoot)
 	if t.UntrustRepoErr != nil {
@@ -310,7 +606,25 @@ func (t *TestEffects) UntrustRepo(repoRoot string) error {
 	return nil
 }
 
+func (t *TestEffects) OpenURL(url string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.OpenURLCalls++
+	t.OpenedURLs = append(t.OpenedURLs, url)
+	return t.OpenURLErr
+}
+
+func (t *TestEffects) OpenFile(worktreePath, file string, line, column int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.OpenFileCalls++
+	t.OpenedFiles = append(t.OpenedFiles, FileOpen{WorktreePath: worktreePath, File: file, Line: line, Column: column})
+	return t.OpenFileErr
+}
+
 func (t *TestEffects) OpenEditor(path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.OpenEditorCalls++
 	t.OpenedPaths = append(t.OpenedPaths, path)
 	if t.OpenEditorErr != nil {
@@ -320,26 +634,59 @@ func (t *TestEffects) OpenEditor(path string) error {
 }
 
 func (t *TestEffects) Print(msg string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.PrintCalls++
 	t.PrintedMsgs = append(t.PrintedMsgs, msg)
 }
 
 func (t *TestEffects) PrintErr(msg string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.PrintErrCalls++
 	t.PrintedErrs = append(t.PrintedErrs, msg)
 }
 
-func (t *TestEffects) SelectBranch(branches []git.Branch) (int, error) {
+func (t *TestEffects) ReportProgress(step, total int, label string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ProgressReports = append(t.ProgressReports, fmt.Sprintf("[%d/%d] %s", step, total, label))
+}
+
+func (t *TestEffects) SelectBranch(branches []core.BranchChoice) (int, error) {
 	t.SelectBranchCalls++
+	t.SelectBranchArgs = append(t.SelectBranchArgs, branches)
 	if t.SelectionError != nil {
 		return -1, t.SelectionError
 	}
 	if t.SelectedBranchIndex < 0 || t.SelectedBranchIndex >= len(branches) {
 		return -1, fmt.Errorf("invalid selection index")
 	}
+	if !branches[t.SelectedBranchIndex].Available() {
+		return -1, fmt.Errorf("invalid selection: %s is checked out at %s", branches[t.SelectedBranchIndex].Branch.Name, branches[t.SelectedBranchIndex].CheckedOutAt)
+	}
 	return t.SelectedBranchIndex, nil
 }
 
+func (t *TestEffects) Confirm(question string) (bool, error) {
+	t.ConfirmCalls++
+	if t.ConfirmErr != nil {
+		return false, t.ConfirmErr
+	}
+	return t.Confirmed, nil
+}
+
+func (t *TestEffects) Choose(question string, choices []string) (int, error) {
+	t.ChooseCalls++
+	if t.ChooseErr != nil {
+		return -1, t.ChooseErr
+	}
+	if t.Choice < 0 || t.Choice >= len(choices) {
+		return -1, fmt.Errorf("invalid choice index")
+	}
+	return t.Choice, nil
+}
+
 func (t *TestEffects) SelectWorktree(worktrees []git.Worktree) (int, error) {
 	t.SelectWorktreeCalls++
 	if t.SelectionError != nil {
@@ -352,6 +699,8 @@ func (t *TestEffects) SelectWorktree(worktrees []git.Worktree) (int, error) {
 }
 
 func (t *TestEffects) RunHooks(repoRoot, worktreePath, mainWorktreePath string, commands []string, hookType string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.RunHooksCalls++
 	t.RunHooksInvocations = append(t.RunHooksInvocations, HookCall{
 		RepoRoot:         repoRoot,
@@ -363,6 +712,13 @@ func (t *TestEffects) RunHooks(repoRoot, worktreePath, mainWorktreePath string,
 	return t.RunHooksErr
 }
 
+func (t *TestEffects) HasRemote(repoRoot string) (bool, error) {
+	if t.HasRemoteErr != nil {
+		return false, t.HasRemoteErr
+	}
+	return !t.NoRemote, nil
+}
+
 func (t *TestEffects) LocalBranchExists(repoRoot, branch string) (bool, error) {
 	t.LocalBranchExistsCalls++
 	t.LocalBranchExistsQueries = append(t.LocalBranchExistsQueries, BranchQuery{
@@ -426,6 +782,259 @@ func (t *TestEffects) GetWorktreeRoot(repoRoot string) (string, error) {
 	return t.WorktreeRoot, nil
 }
 
+func (t *TestEffects) GetPathWorktreeRoot(repoRoot string) (string, error) {
+	t.GetPathWorktreeRootCalls++
+	if t.GetWorktreeRootErr != nil {
+		return "", t.GetWorktreeRootErr
+	}
+	if t.PathWorktreeRoot != "" {
+		return t.PathWorktreeRoot, nil
+	}
+	if t.WorktreeRoot == "" {
+		return "", fmt.Errorf("failed to get worktree root")
+	}
+	return t.WorktreeRoot, nil
+}
+
+func (t *TestEffects) LockRepo(mainWorktreePath string, timeout time.Duration) (func(), error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.LockRepoErr != nil {
+		return nil, t.LockRepoErr
+	}
+	t.LockedRepos = append(t.LockedRepos, mainWorktreePath)
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.LockedRepos = slices.DeleteFunc(t.LockedRepos, func(repo string) bool { return repo == mainWorktreePath })
+	}, nil
+}
+
+func (t *TestEffects) ListAdoptedWorktrees() ([]string, error) {
+	t.ListAdoptedCalls++
+	if t.ListAdoptedErr != nil {
+		return nil, t.ListAdoptedErr
+	}
+	return t.Adopted, nil
+}
+
+func (t *TestEffects) LoadRepoIndex() (map[string]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.LoadRepoIndexErr != nil {
+		return nil, t.LoadRepoIndexErr
+	}
+	return maps.Clone(t.RepoIndex), nil
+}
+
+func (t *TestEffects) SaveRepoIndex(repos map[string]string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.SaveRepoIndexCalls++
+	t.RepoIndex = maps.Clone(repos)
+	return nil
+}
+
+func (t *TestEffects) IndexRepo(repoDir, mainPath string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.RepoIndex == nil {
+		t.RepoIndex = make(map[string]string)
+	}
+	t.RepoIndex[repoDir] = mainPath
+	return nil
+}
+
+func (t *TestEffects) AdoptWorktree(repoRoot, path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.AdoptWorktreeCalls++
+	if t.AdoptWorktreeErr != nil {
+		return t.AdoptWorktreeErr
+	}
+	t.Adopted = append(t.Adopted, path)
+	return nil
+}
+
+func (t *TestEffects) ForgetWorktree(path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ForgetWorktreeCalls++
+	if t.ForgetWorktreeErr != nil {
+		return t.ForgetWorktreeErr
+	}
+	filtered := []string{}
+	for _, p := range t.Adopted {
+		if p != path {
+			filtered = append(filtered, p)
+		}
+	}
+	t.Adopted = filtered
+	return nil
+}
+
+// FetchTicket returns the predefined ticket for id, or tickets.ErrTicketNotFound.
+func (t *TestEffects) FetchTicket(provider, id string) (tickets.Ticket, error) {
+	t.FetchTicketCalls++
+	if t.FetchTicketErr != nil {
+		return tickets.Ticket{}, t.FetchTicketErr
+	}
+	ticket, ok := t.Tickets[id]
+	if !ok {
+		return tickets.Ticket{}, fmt.Errorf("%s: %w", id, tickets.ErrTicketNotFound)
+	}
+	return ticket, nil
+}
+
+func (t *TestEffects) LoadTickets() (map[string]tickets.Ticket, error) {
+	t.LoadTicketsCalls++
+	if t.LoadTicketsErr != nil {
+		return nil, t.LoadTicketsErr
+	}
+	return t.WorktreeTickets, nil
+}
+
+func (t *TestEffects) RecordTicket(path string, ticket tickets.Ticket) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.RecordTicketCalls++
+	if t.RecordTicketErr != nil {
+		return t.RecordTicketErr
+	}
+	t.WorktreeTickets[path] = ticket
+	return nil
+}
+
+func (t *TestEffects) LoadWorktreeMetadata() (map[string]sprout.WorktreeMeta, error) {
+	if t.LoadMetadataErr != nil {
+		return nil, t.LoadMetadataErr
+	}
+	return t.WorktreeMetadata, nil
+}
+
+func (t *TestEffects) RecordBase(path, base string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.RecordBaseCalls++
+	if t.RecordBaseErr != nil {
+		return t.RecordBaseErr
+	}
+	meta := t.WorktreeMetadata[path]
+	meta.Path = path
+	meta.Base = base
+	t.WorktreeMetadata[path] = meta
+	return nil
+}
+
+func (t *TestEffects) SetPinned(path string, pinned bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.SetPinnedCalls++
+	if t.SetPinnedErr != nil {
+		return t.SetPinnedErr
+	}
+	meta := t.WorktreeMetadata[path]
+	meta.Path = path
+	meta.Pinned = pinned
+	t.WorktreeMetadata[path] = meta
+	return nil
+}
+
+func (t *TestEffects) SetNote(path, note string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.SetNoteCalls++
+	if t.SetNoteErr != nil {
+		return t.SetNoteErr
+	}
+	meta := t.WorktreeMetadata[path]
+	meta.Path = path
+	meta.Note = note
+	t.WorktreeMetadata[path] = meta
+	return nil
+}
+
+func (t *TestEffects) SetExpiry(path string, expiresAt time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.SetExpiryCalls++
+	if t.SetExpiryErr != nil {
+		return t.SetExpiryErr
+	}
+	meta := t.WorktreeMetadata[path]
+	meta.Path = path
+	meta.ExpiresAt = expiresAt
+	t.WorktreeMetadata[path] = meta
+	return nil
+}
+
+func (t *TestEffects) ForgetMetadata(path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ForgetMetadataCalls++
+	if t.ForgetMetadataErr != nil {
+		return t.ForgetMetadataErr
+	}
+	delete(t.WorktreeMetadata, path)
+	return nil
+}
+
+func (t *TestEffects) LoadWorkspaces() ([]sprout.Workspace, error) {
+	if t.LoadWorkspacesErr != nil {
+		return nil, t.LoadWorkspacesErr
+	}
+	workspaces := []sprout.Workspace{}
+	for name, repos := range t.Workspaces {
+		workspaces = append(workspaces, sprout.Workspace{Name: name, Repos: repos})
+	}
+	slices.SortFunc(workspaces, func(a, b sprout.Workspace) int { return strings.Compare(a.Name, b.Name) })
+	return workspaces, nil
+}
+
+func (t *TestEffects) SaveWorkspace(name string, repos []string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.SaveWorkspaceCalls++
+	if t.SaveWorkspaceErr != nil {
+		return t.SaveWorkspaceErr
+	}
+	t.Workspaces[name] = repos
+	return nil
+}
+
+func (t *TestEffects) DeleteWorkspace(name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.DeleteWorkspaceCalls++
+	delete(t.Workspaces, name)
+	return nil
+}
+
+// WorktreeIndex returns the predefined index for path, or allocates the
+// lowest unused one like the real store does.
+func (t *TestEffects) WorktreeIndex(repoRoot, path string) (int, error) {
+	t.WorktreeIndexCalls++
+	if t.WorktreeIndexErr != nil {
+		return 0, t.WorktreeIndexErr
+	}
+	if path == repoRoot {
+		return 0, nil
+	}
+	if index, ok := t.WorktreeIndices[path]; ok {
+		return index, nil
+	}
+	used := map[int]bool{}
+	for _, index := range t.WorktreeIndices {
+		used[index] = true
+	}
+	index := 1
+	for used[index] {
+		index++
+	}
+	t.WorktreeIndices[path] = index
+	return index, nil
+}
+
 func (t *TestEffects) PromptTrustRepo(mainWorktreePath, hookType string, hookCommands []string) error {
 	t.PromptTrustRepoCalls++
 	t.PromptTrustRepoInvocations = append(t.PromptTrustRepoInvocations, PromptTrustCall{
@@ -436,6 +1045,9 @@ func (t *TestEffects) PromptTrustRepo(mainWorktreePath, hookType string, hookCom
 	if t.PromptTrustRepoErr != nil {
 		return t.PromptTrustRepoErr
 	}
+	if t.TrustDeclined {
+		return ErrTrustDeclined
+	}
 	// Auto-trust on success (simulates user saying yes)
 	t.TrustedRepos[mainWorktreePath] = true
 	return nil
@@ -474,3 +1086,81 @@ func (t *TestEffects) GetWorktreeStatus(path string) git.WorktreeStatus {
 	// Default: clean worktree
 	return git.WorktreeStatus{}
 }
+
+func (t *TestEffects) GetDefaultBranch(path string) (string, error) {
+	if t.DefaultBranch == "" {
+		return "main", nil
+	}
+	return t.DefaultBranch, nil
+}
+
+func (t *TestEffects) WorktreeLastUsed(path string) (time.Time, error) {
+	return t.LastUsed[path], nil
+}
+
+// ReadHead returns the closest Heads entry at or above dir.
+func (t *TestEffects) ReadHead(dir string) (string, string, error) {
+	for root := dir; ; root = filepath.Dir(root) {
+		if head, ok := t.Heads[root]; ok {
+			return root, head, nil
+		}
+		if filepath.Dir(root) == root {
+			return "", "", git.ErrNotInWorktree
+		}
+	}
+}
+
+func (t *TestEffects) CacheStatuses(statuses map[string]git.WorktreeStatus) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.CacheStatusesCalls++
+	if t.CacheStatusesErr != nil {
+		return t.CacheStatusesErr
+	}
+	for path, status := range statuses {
+		t.CachedStatuses[path] = sprout.CachedStatus{
+			Path:          path,
+			Dirty:         status.Dirty,
+			UntrackedOnly: status.UntrackedOnly,
+			Ahead:         status.Ahead,
+			Behind:        status.Behind,
+			Unmerged:      status.Unmerged,
+			Stash:         status.HasStash,
+		}
+	}
+	return nil
+}
+
+func (t *TestEffects) LoadCachedStatus(path string) (sprout.CachedStatus, bool, error) {
+	cached, ok := t.CachedStatuses[path]
+	return cached, ok, nil
+}
+
+func (t *TestEffects) LoadTelemetry() (*telemetry.State, error) {
+	state := t.Telemetry
+	return &state, nil
+}
+
+func (t *TestEffects) SetTelemetry(enabled bool) error {
+	t.SetTelemetryCalls++
+	if t.SetTelemetryErr != nil {
+		return t.SetTelemetryErr
+	}
+	t.Telemetry.Enabled = enabled
+	t.Telemetry.Reset(time.Time{})
+	return nil
+}
+
+func (t *TestEffects) EmitEvent(event events.Event) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Events = append(t.Events, event)
+	return t.EmitEventErr
+}
+
+func (t *TestEffects) LoadTimings() ([]timing.Sample, error) {
+	if t.LoadTimingsErr != nil {
+		return nil, t.LoadTimingsErr
+	}
+	return t.Timings, nil
+}
@@ -0,0 +1,116 @@
+package effects
+
+import (
+	"path/filepath"
+	"slices"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/git"
+)
+
+// BranchLocation says where a branch added with TestRepo.WithBranch exists.
+type BranchLocation int
+
+const (
+	Local  BranchLocation = 1 << iota // a local branch
+	Remote                            // a branch on origin
+	// LocalAndRemote is a local branch that tracks a branch on origin.
+	LocalAndRemote = Local | Remote
+)
+
+// TestRepo builds TestEffects for a repository, keeping the maps that
+// describe the same branch, worktree or file consistent with each other:
+//
+//	fx := effects.NewTestRepo("/test/repo").
+//		WithBranch("feature", effects.Remote).
+//		WithWorktree("fix", "/sprout/fix/repo").
+//		Effects()
+//
+// Anything the builder doesn't cover can still be set on the result.
+type TestRepo struct {
+	fx *TestEffects
+}
+
+// NewTestRepo starts a repository whose main worktree at root has the local
+// branch main checked out.
+func NewTestRepo(root string) *TestRepo {
+	fx := NewTestEffects()
+	fx.RepoRoot = root
+	fx.MainWorktreePath = root
+	r := &TestRepo{fx: fx}
+	return r.WithWorktree("main", root)
+}
+
+// WithBranch adds branch where it says, so that ListBranches lists it and
+// LocalBranchExists and RemoteBranchExists find it. Adding a branch again
+// adds it where it wasn't yet.
+func (r *TestRepo) WithBranch(name string, where BranchLocation) *TestRepo {
+	if where&Local != 0 && !r.fx.LocalBranches[name] {
+		r.fx.LocalBranches[name] = true
+		r.fx.Branches = append(r.fx.Branches, git.Branch{RefName: name, DisplayName: name, Name: name, IsLocal: true})
+	}
+	if where&Remote != 0 && !r.fx.RemoteBranches[name] {
+		r.fx.RemoteBranches[name] = true
+		r.fx.Branches = append(r.fx.Branches, git.Branch{RefName: "origin/" + name, DisplayName: name, Name: name})
+	}
+	return r
+}
+
+// WithWorktree adds a worktree at path with the local branch checked out,
+// adding the branch if needed. The path exists and GetWorktreePath returns
+// it for the branch.
+func (r *TestRepo) WithWorktree(branch, path string) *TestRepo {
+	r.WithBranch(branch, Local)
+	r.fx.Worktrees = append(r.fx.Worktrees, git.Worktree{Path: path, Branch: branch})
+	r.fx.WorktreePaths[branch] = path
+	r.fx.Files[path] = true
+	return r
+}
+
+// WithDetachedWorktree adds a worktree at path with head checked out
+// detached.
+func (r *TestRepo) WithDetachedWorktree(path, head string) *TestRepo {
+	r.fx.Worktrees = append(r.fx.Worktrees, git.Worktree{Path: path, HEAD: head})
+	r.fx.Files[path] = true
+	return r
+}
+
+// WithStatus sets what GetWorktreeStatus returns for the worktree at path.
+// The worktree has to be added first.
+func (r *TestRepo) WithStatus(path string, status git.WorktreeStatus) *TestRepo {
+	if !slices.ContainsFunc(r.fx.Worktrees, func(wt git.Worktree) bool { return wt.Path == path }) {
+		panic("TestRepo.WithStatus: no worktree at " + path)
+	}
+	r.fx.WorktreeStatuses[path] = status
+	return r
+}
+
+// WithFile adds a regular file at path that ReadFile reads as content. The
+// directories it is in exist too.
+func (r *TestRepo) WithFile(path, content string) *TestRepo {
+	for dir := filepath.Dir(path); !r.fx.Files[dir] && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		r.fx.Files[dir] = true
+	}
+	r.fx.Files[path] = true
+	r.fx.RegularFiles[path] = true
+	r.fx.FileContents[path] = []byte(content)
+	return r
+}
+
+// WithConfig sets the .sprout.yml of the repository.
+func (r *TestRepo) WithConfig(cfg *config.Config) *TestRepo {
+	r.fx.Config = cfg
+	return r
+}
+
+// Trusted trusts the repository.
+func (r *TestRepo) Trusted() *TestRepo {
+	r.fx.TrustedRepos[r.fx.RepoRoot] = true
+	return r
+}
+
+// Effects returns the TestEffects built so far. Later builder calls keep
+// changing them.
+func (r *TestRepo) Effects() *TestEffects {
+	return r.fx
+}
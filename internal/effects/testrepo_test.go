@@ -0,0 +1,74 @@
+package effects
+
+import (
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestRepo(t *testing.T) {
+	fx := NewTestRepo("/repo").
+		WithBranch("remote-only", Remote).
+		WithBranch("tracked", LocalAndRemote).
+		WithWorktree("feature", "/sprout/feature/repo").
+		WithStatus("/sprout/feature/repo", git.WorktreeStatus{Dirty: true}).
+		WithFile("/repo/.env", "KEY=1").
+		Trusted().
+		Effects()
+
+	assert.Equal(t, "/repo", fx.RepoRoot)
+	assert.Equal(t, "/repo", fx.MainWorktreePath)
+	assert.Equal(t, []git.Worktree{
+		{Path: "/repo", Branch: "main"},
+		{Path: "/sprout/feature/repo", Branch: "feature"},
+	}, fx.Worktrees)
+	assert.Equal(t, []git.Branch{
+		{RefName: "main", DisplayName: "main", Name: "main", IsLocal: true},
+		{RefName: "origin/remote-only", DisplayName: "remote-only", Name: "remote-only"},
+		{RefName: "tracked", DisplayName: "tracked", Name: "tracked", IsLocal: true},
+		{RefName: "origin/tracked", DisplayName: "tracked", Name: "tracked"},
+		{RefName: "feature", DisplayName: "feature", Name: "feature", IsLocal: true},
+	}, fx.Branches)
+
+	local, err := fx.LocalBranchExists("/repo", "remote-only")
+	require.NoError(t, err)
+	assert.False(t, local)
+	remote, err := fx.RemoteBranchExists("/repo", "remote-only")
+	require.NoError(t, err)
+	assert.True(t, remote)
+
+	path, err := fx.GetWorktreePath("/repo", "feature")
+	require.NoError(t, err)
+	assert.Equal(t, "/sprout/feature/repo", path)
+	assert.True(t, fx.GetWorktreeStatus("/sprout/feature/repo").Dirty)
+
+	exists, isDir, err := fx.Stat("/repo/.env")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.False(t, isDir)
+	data, err := fx.ReadFile("/repo/.env")
+	require.NoError(t, err)
+	assert.Equal(t, "KEY=1", string(data))
+
+	trusted, err := fx.IsTrusted("/repo", "/repo")
+	require.NoError(t, err)
+	assert.True(t, trusted)
+}
+
+func TestTestRepo_WithFileAddsDirectories(t *testing.T) {
+	fx := NewTestRepo("/repo").WithFile("/repo/config/app/settings.json", "{}").Effects()
+
+	for _, dir := range []string{"/repo/config", "/repo/config/app"} {
+		exists, isDir, err := fx.Stat(dir)
+		require.NoError(t, err)
+		assert.True(t, exists && isDir, dir)
+	}
+}
+
+func TestTestRepo_WithStatusNeedsWorktree(t *testing.T) {
+	assert.Panics(t, func() {
+		NewTestRepo("/repo").WithStatus("/nowhere", git.WorktreeStatus{})
+	})
+}
@@ -0,0 +1,102 @@
+// Package events tells external tools, such as window managers, dashboards
+// and tmux scripts, what sprout did: worktrees created and removed and
+// hooks that finished. Events go to a command or unix socket the user sets
+// in their own config; repositories can't configure them.
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Version is the schema version of Event.
+const Version = 1
+
+// Event types.
+const (
+	WorktreeCreated = "worktree_created"
+	WorktreeRemoved = "worktree_removed"
+	HooksCompleted  = "hooks_completed"
+)
+
+// Timeout bounds how long sprout waits for an event to be delivered, so a
+// stuck listener only slows it down a little.
+const Timeout = 5 * time.Second
+
+// Event is one thing sprout did, delivered as a single line of JSON.
+type Event struct {
+	Version    int       `json:"version"`
+	Type       string    `json:"type"`
+	Time       time.Time `json:"time"`
+	Repo       string    `json:"repo,omitempty"` // Main worktree of the repository
+	Path       string    `json:"path"`           // The worktree
+	Branch     string    `json:"branch,omitempty"`
+	Hook       string    `json:"hook,omitempty"`        // hooks_completed: on_create or on_open
+	DurationMS int64     `json:"duration_ms,omitempty"` // hooks_completed: how long the hooks ran
+	Error      string    `json:"error,omitempty"`       // hooks_completed: why the hooks failed; empty on success
+}
+
+// Emit delivers event to the unix socket at socket and to the shell
+// command command, whichever are set. The command gets the event on stdin
+// and its type in $SPROUT_EVENT.
+func Emit(command, socket string, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", event.Type, err)
+	}
+	data = append(data, '\n')
+
+	var errs []string
+	if socket != "" {
+		if err := sendSocket(socket, data); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if command != "" {
+		if err := runCommand(command, event.Type, data); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to emit %s event: %s", event.Type, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func sendSocket(socket string, data []byte) error {
+	conn, err := net.DialTimeout("unix", socket, Timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetWriteDeadline(time.Now().Add(Timeout)); err != nil {
+		return err
+	}
+	_, err = conn.Write(data)
+	return err
+}
+
+func runCommand(command, eventType string, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), "SPROUT_EVENT="+eventType)
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
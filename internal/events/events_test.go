@@ -0,0 +1,53 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmit(t *testing.T) {
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "events.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		received <- line
+	}()
+
+	out := filepath.Join(dir, "out")
+	event := Event{Version: Version, Type: WorktreeCreated, Time: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC), Path: "/wt/feature", Branch: "feature"}
+	require.NoError(t, Emit(`{ echo "$SPROUT_EVENT"; cat; } > `+out, socket, event))
+
+	var got Event
+	require.NoError(t, json.Unmarshal([]byte(<-received), &got))
+	assert.Equal(t, event, got)
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "worktree_created\n{\"version\":1,\"type\":\"worktree_created\",\"time\":\"2026-10-01T12:00:00Z\",\"path\":\"/wt/feature\",\"branch\":\"feature\"}\n", string(data))
+}
+
+func TestEmit_ReportsFailures(t *testing.T) {
+	err := Emit("echo broken >&2; exit 1", filepath.Join(t.TempDir(), "missing.sock"), Event{Type: HooksCompleted})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to emit hooks_completed event")
+	assert.Contains(t, err.Error(), "broken")
+	assert.Contains(t, err.Error(), "missing.sock")
+}
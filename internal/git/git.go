@@ -1,26 +1,46 @@
 package git
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"net/url"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/logging"
 )
 
 // GetRepoRoot returns the absolute path to the root of the current git repository.
 func GetRepoRoot() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	out, err := cmd.Output()
+	return GetRepoRootIn("")
+}
+
+// GetRepoRootIn is GetRepoRoot for the repository containing dir. An empty
+// dir is the working directory.
+func GetRepoRootIn(dir string) (string, error) {
+	out, err := RunGitCommand(dir, "rev-parse", "--show-toplevel")
 	if err != nil {
 		return "", fmt.Errorf("failed to get repo root (not a git repo?): %w", err)
 	}
-	return strings.TrimSpace(string(out)), nil
+	return out, nil
 }
 
 // GetMainWorktreePath returns the absolute path to the main worktree.
 // This is useful for finding config files that might be gitignored but exist in the main worktree.
 func GetMainWorktreePath() (string, error) {
+	return GetMainWorktreePathIn("")
+}
+
+// GetMainWorktreePathIn is GetMainWorktreePath for the repository containing
+// dir. An empty dir is the working directory.
+func GetMainWorktreePathIn(dir string) (string, error) {
 	// The first worktree in the list is always the main worktree
-	worktrees, err := ListWorktrees("")
+	worktrees, err := ListWorktrees(dir)
 	if err != nil {
 		return "", fmt.Errorf("failed to list worktrees: %w", err)
 	}
@@ -32,17 +52,311 @@ func GetMainWorktreePath() (string, error) {
 	return worktrees[0].Path, nil
 }
 
-// RunGitCommand runs a git command in the given directory.
+// EnvOverrides are the environment variables that make git use another
+// repository or work tree than the one containing its working directory.
+var EnvOverrides = []string{"GIT_DIR", "GIT_WORK_TREE"}
+
+// ResolveEnvOverrides returns the worktree GIT_DIR and GIT_WORK_TREE select
+// and unsets them, or "" when neither is set. sprout runs git in the
+// directory of each worktree it touches, which these variables would
+// override, so they are only honored when they select a worktree git also
+// finds without them. Anything else, such as a bare repository or a
+// dotfiles-style work tree, is an error saying what to do instead.
+func ResolveEnvOverrides() (string, error) {
+	var set []string
+	for _, name := range EnvOverrides {
+		if value, ok := os.LookupEnv(name); ok {
+			set = append(set, name+"="+value)
+		}
+	}
+	if len(set) == 0 {
+		return "", nil
+	}
+	vars := strings.Join(set, " ")
+
+	root, err := RunGitCommand("", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("%s doesn't select a work tree (is it a bare repository?): %w\nsprout manages worktrees; unset %s and run sprout from inside one",
+			vars, err, strings.Join(EnvOverrides, " and "))
+	}
+	gitDir, err := RunGitCommand("", "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return "", err
+	}
+
+	for _, name := range EnvOverrides {
+		os.Unsetenv(name)
+	}
+	if found, err := RunGitCommand(root, "rev-parse", "--absolute-git-dir"); err != nil || !samePath(found, gitDir) {
+		return "", fmt.Errorf("%s selects the work tree %s, which git doesn't know as a worktree of %s\nsprout runs git in each worktree's own directory; unset %s, or add the work tree with 'git worktree add'",
+			vars, root, gitDir, strings.Join(EnvOverrides, " and "))
+	}
+	return root, nil
+}
+
+// samePath reports whether a and b are the same path once symlinks are
+// resolved.
+func samePath(a, b string) bool {
+	if ra, err := filepath.EvalSymlinks(a); err == nil {
+		a = ra
+	}
+	if rb, err := filepath.EvalSymlinks(b); err == nil {
+		b = rb
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// GitError is returned when a git command fails. Its message is a single
+// line; the complete stderr is in Stderr.
+type GitError struct {
+	Args     []string `json:"args"`
+	Dir      string   `json:"dir,omitempty"`
+	ExitCode int      `json:"exit_code"` // -1 if git did not run to completion
+	Stderr   string   `json:"stderr,omitempty"`
+	Err      error    `json:"-"`
+}
+
+func (e *GitError) Error() string {
+	msg := "git " + strings.Join(e.Args, " ") + ": "
+	if summary := e.Summary(); summary != "" {
+		msg += summary
+	} else {
+		msg += e.Err.Error()
+	}
+	if e.ExitCode >= 0 {
+		msg += fmt.Sprintf(" (exit %d)", e.ExitCode)
+	}
+	return msg
+}
+
+func (e *GitError) Unwrap() error {
+	return e.Err
+}
+
+// Summary returns the line of Stderr that explains the failure: the first
+// "fatal:" or "error:" line, or else the last line git printed.
+func (e *GitError) Summary() string {
+	var last string
+	for _, line := range strings.Split(e.Stderr, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "fatal:") || strings.HasPrefix(line, "error:") {
+			return line
+		}
+		if line != "" {
+			last = line
+		}
+	}
+	return last
+}
+
+// newGitError describes a failed git command.
+func newGitError(dir string, args []string, stderr string, err error) *GitError {
+	exitCode := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+	return &GitError{
+		Args:     append([]string(nil), args...),
+		Dir:      dir,
+		ExitCode: exitCode,
+		Stderr:   strings.TrimSpace(stderr),
+		Err:      err,
+	}
+}
+
+// RunGitCommand runs a git command in the given directory and returns its
+// trimmed stdout. A failure is returned as a *GitError.
 func RunGitCommand(dir string, args ...string) (string, error) {
 	cmd := exec.Command("git", args...)
 	if dir != "" {
 		cmd.Dir = dir
 	}
-	out, err := cmd.CombinedOutput()
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	traceGit(dir, args)
+	started := time.Now()
+	if err := cmd.Run(); err != nil {
+		gitErr := newGitError(dir, args, stderr.String(), err)
+		logGit(dir, args, started, gitErr)
+		return stdout.String(), gitErr
+	}
+	logGit(dir, args, started, nil)
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// logGit records a git invocation and its duration in the debug log, and
+// echoes how it went when tracing.
+func logGit(dir string, args []string, started time.Time, err error) {
+	elapsed := time.Since(started).Round(time.Millisecond)
+	if w := tracer(); w != nil {
+		outcome := "ok"
+		var gitErr *GitError
+		if errors.As(err, &gitErr) && gitErr.ExitCode >= 0 {
+			outcome = fmt.Sprintf("exit %d", gitErr.ExitCode)
+		} else if err != nil {
+			outcome = err.Error()
+		}
+		writeTrace(w, fmt.Sprintf("  git %s: %s, %s\n", strings.Join(args, " "), outcome, elapsed))
+	}
+
+	if !logging.Enabled() {
+		return
+	}
+	if dir == "" {
+		dir = "."
+	}
+	outcome := "ok"
 	if err != nil {
-		return string(out), fmt.Errorf("git command failed: %w\nOutput: %s", err, string(out))
+		outcome = err.Error()
 	}
-	return strings.TrimSpace(string(out)), nil
+	logging.Printf("git %s (in %s): %s, %s", strings.Join(args, " "), dir, outcome, elapsed)
+}
+
+var (
+	traceMu sync.Mutex
+	trace   io.Writer // nil while tracing is off
+)
+
+// SetTrace echoes every git command to w (--verbose-git): the command and
+// the directory it runs in before it starts, and how it went and how long
+// it took once it is done. A nil w turns tracing off.
+func SetTrace(w io.Writer) {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	trace = w
+}
+
+func tracer() io.Writer {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	return trace
+}
+
+// traceGit echoes a git command that is about to run when tracing.
+func traceGit(dir string, args []string) {
+	w := tracer()
+	if w == nil {
+		return
+	}
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	writeTrace(w, fmt.Sprintf("+ git %s (in %s)\n", strings.Join(args, " "), dir))
+}
+
+// writeTrace writes a whole trace line at once, as git commands may run
+// concurrently.
+func writeTrace(w io.Writer, line string) {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	_, _ = io.WriteString(w, line)
+}
+
+// networkSubcommands talk to a remote and may fail because of the network.
+var networkSubcommands = map[string]bool{
+	"fetch": true, "pull": true, "push": true, "ls-remote": true, "clone": true,
+}
+
+// IsNetworkCommand reports whether git args run a command that talks to a
+// remote (fetch, pull, push, ls-remote, clone).
+func IsNetworkCommand(args []string) bool {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			continue // Global options such as --no-pager
+		}
+		return networkSubcommands[arg]
+	}
+	return false
+}
+
+// transientMessages are git and curl errors for network failures that may
+// pass on a retry, unlike rejected pushes or missing refs.
+var transientMessages = []string{
+	"could not resolve host",
+	"temporary failure in name resolution",
+	"connection timed out",
+	"operation timed out",
+	"connection reset",
+	"connection refused",
+	"network is unreachable",
+	"failed to connect",
+	"the remote end hung up unexpectedly",
+	"early eof",
+	"rpc failed",
+	"tls connection was non-properly terminated",
+	"ssl_connect",
+	"http 502",
+	"http 503",
+	"http 504",
+}
+
+// IsTransient reports whether err is a git failure that looks like a
+// network hiccup worth retrying.
+func IsTransient(err error) bool {
+	var gitErr *GitError
+	if !errors.As(err, &gitErr) {
+		return false
+	}
+	stderr := strings.ToLower(gitErr.Stderr)
+	for _, msg := range transientMessages {
+		if strings.Contains(stderr, msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryPolicy controls how network commands are retried.
+type RetryPolicy struct {
+	Attempts int           // Total tries; less than 1 means 1
+	Delay    time.Duration // Wait before the first retry, doubling after each
+	// OnRetry, if set, is called before waiting to retry (attempt is the
+	// one that failed, starting at 1).
+	OnRetry func(attempt int, delay time.Duration, err error)
+	Sleep   func(time.Duration) // Defaults to time.Sleep
+}
+
+// Retry calls run until it succeeds, fails permanently (see IsTransient) or
+// the policy's attempts are used up, and returns the last error.
+func Retry(policy RetryPolicy, run func() error) error {
+	sleep := policy.Sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	delay := policy.Delay
+	for attempt := 1; ; attempt++ {
+		err := run()
+		if err == nil || attempt >= policy.Attempts || !IsTransient(err) {
+			return err
+		}
+		if policy.OnRetry != nil {
+			policy.OnRetry(attempt, delay, err)
+		}
+		sleep(delay)
+		delay *= 2
+	}
+}
+
+// RunGitInteractive runs a git command with the terminal attached (pager,
+// difftool, editor), without capturing its output. A failure is returned as
+// a *GitError without Stderr, which the user has already seen.
+func RunGitInteractive(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	traceGit(dir, args)
+	started := time.Now()
+	if err := cmd.Run(); err != nil {
+		gitErr := newGitError(dir, args, "", err)
+		logGit(dir, args, started, gitErr)
+		return gitErr
+	}
+	logGit(dir, args, started, nil)
+	return nil
 }
 
 // Worktree represents a git worktree.
@@ -50,6 +364,7 @@ type Worktree struct {
 	Path   string
 	HEAD   string
 	Branch string
+	Locked bool // git refuses to remove or prune it (see 'git worktree lock')
 }
 
 // ListWorktrees returns a list of worktrees for the repo.
@@ -58,7 +373,11 @@ func ListWorktrees(repoRoot string) ([]Worktree, error) {
 	if err != nil {
 		return nil, err
 	}
+	return parseWorktrees(out), nil
+}
 
+// parseWorktrees parses the output of 'git worktree list --porcelain'.
+func parseWorktrees(out string) []Worktree {
 	var worktrees []Worktree
 	var current Worktree
 
@@ -74,13 +393,15 @@ func ListWorktrees(repoRoot string) ([]Worktree, error) {
 		} else if strings.HasPrefix(line, "branch ") {
 			ref := strings.TrimPrefix(line, "branch ")
 			current.Branch = strings.TrimPrefix(ref, "refs/heads/")
+		} else if line == "locked" || strings.HasPrefix(line, "locked ") {
+			current.Locked = true
 		}
 	}
 	if current.Path != "" {
 		worktrees = append(worktrees, current)
 	}
 
-	return worktrees, nil
+	return worktrees
 }
 
 // PruneWorktrees prunes stale worktrees.
@@ -99,6 +420,105 @@ func RemoveWorktree(repoRoot, path string, force bool) error {
 	return err
 }
 
+// ErrNotInWorktree is returned by ReadHead outside any git worktree.
+var ErrNotInWorktree = errors.New("not inside a git worktree")
+
+// ReadHead finds the worktree containing dir and what its HEAD points at,
+// by reading .git files directly rather than running git (for shell
+// prompts). head is the branch name, or the short commit for a detached HEAD.
+func ReadHead(dir string) (root, head string, err error) {
+	for root = dir; ; root = filepath.Dir(root) {
+		if _, err := os.Lstat(filepath.Join(root, ".git")); err == nil {
+			break
+		}
+		if filepath.Dir(root) == root {
+			return "", "", ErrNotInWorktree
+		}
+	}
+
+	gitDir, err := worktreeGitDir(root)
+	if err != nil {
+		return "", "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", "", err
+	}
+	head = strings.TrimSpace(string(data))
+	if ref, ok := strings.CutPrefix(head, "ref: "); ok {
+		return root, strings.TrimPrefix(ref, "refs/heads/"), nil
+	}
+	if len(head) > 7 {
+		head = head[:7]
+	}
+	return root, head, nil
+}
+
+// worktreeGitDir returns the git directory of the worktree at root: .git
+// itself, or for linked worktrees the directory their .git file points to.
+func worktreeGitDir(root string) (string, error) {
+	gitDir := filepath.Join(root, ".git")
+	info, err := os.Stat(gitDir)
+	if err != nil || info.IsDir() {
+		return gitDir, err
+	}
+
+	// Linked worktrees have a .git file: "gitdir: /repo/.git/worktrees/name"
+	data, err := os.ReadFile(gitDir)
+	if err != nil {
+		return "", err
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return "", fmt.Errorf("unrecognized .git file in %s", root)
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(root, target)
+	}
+	return target, nil
+}
+
+// commonGitDir returns the git directory shared by all worktrees of the
+// repository at root, falling back to root itself when it can't be read.
+func commonGitDir(root string) string {
+	gitDir, err := worktreeGitDir(root)
+	if err != nil {
+		return root
+	}
+	common := gitDir // The main worktree's .git is the common directory
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		common = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+	}
+	// Worktrees may reach the same directory through different symlinks
+	if resolved, err := filepath.EvalSymlinks(common); err == nil {
+		return resolved
+	}
+	return filepath.Clean(common)
+}
+
+// LastUsed estimates when a worktree was last used from its index file,
+// which git rewrites on checkout, add, commit and status. It falls back to
+// the worktree directory's modification time.
+func LastUsed(path string) (time.Time, error) {
+	if index, err := RunGitCommand(path, "rev-parse", "--git-path", "index"); err == nil {
+		if !filepath.IsAbs(index) {
+			index = filepath.Join(path, index)
+		}
+		if info, err := os.Stat(index); err == nil {
+			return info.ModTime(), nil
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
 // BranchExists checks if a branch exists (local or remote).
 func BranchExists(repoRoot, branch string) (bool, error) {
 	// Check local
@@ -112,6 +532,27 @@ func BranchExists(repoRoot, branch string) (bool, error) {
 	return false, nil
 }
 
+// remotes caches HasRemote per repository (common git directory), since
+// every worktree's status needs it.
+var remotes sync.Map
+
+// HasRemote reports whether the repository containing path has any remote.
+// Local-only repositories have no remote branches to check, fetch or
+// compare against. The result is cached for the repository.
+func HasRemote(path string) (bool, error) {
+	key := commonGitDir(path)
+	if has, ok := remotes.Load(key); ok {
+		return has.(bool), nil
+	}
+	out, err := RunGitCommand(path, "remote")
+	if err != nil {
+		return false, err
+	}
+	has := out != ""
+	remotes.Store(key, has)
+	return has, nil
+}
+
 // LocalBranchExists checks if a branch exists locally.
 func LocalBranchExists(repoRoot, branch string) (bool, error) {
 	if _, err := RunGitCommand(repoRoot, "rev-parse", "--verify", branch); err == nil {
@@ -210,19 +651,58 @@ func ListAllBranches(repoRoot string) ([]Branch, error) {
 
 // WorktreeStatus represents the git status of a worktree.
 type WorktreeStatus struct {
-	Dirty    bool
-	Ahead    int
-	Behind   int
-	Unmerged bool
+	Dirty         bool // Any uncommitted changes, untracked files included
+	UntrackedOnly bool // Dirty, but only because of untracked files
+	Ahead         int
+	Behind        int
+	Unmerged      bool
+	HasStash      bool // Stash entries were made on this worktree's branch
 }
 
 // IsDirty checks if a worktree has uncommitted changes.
 func IsDirty(path string) (bool, error) {
+	dirty, _, err := worktreeChanges(path)
+	return dirty, err
+}
+
+// worktreeChanges reports whether a worktree has uncommitted changes and
+// whether all of them are untracked files, which nothing tracks yet and are
+// less likely to be lost work than modified or staged files.
+func worktreeChanges(path string) (dirty, untrackedOnly bool, err error) {
 	out, err := RunGitCommand(path, "status", "--porcelain")
+	if err != nil {
+		return false, false, err
+	}
+	if out == "" {
+		return false, false, nil
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if line != "" && !strings.HasPrefix(line, "??") {
+			return true, false, nil
+		}
+	}
+	return true, true, nil
+}
+
+// HasStash reports whether the stash has entries made on the branch checked
+// out in path. The stash is shared by all worktrees of a repository, so
+// entries are matched by the branch in their message ("WIP on feature: ...").
+// A detached HEAD has none.
+func HasStash(path string) (bool, error) {
+	branch, err := RunGitCommand(path, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil || branch == "" {
+		return false, nil
+	}
+	out, err := RunGitCommand(path, "stash", "list", "--format=%gs")
 	if err != nil {
 		return false, err
 	}
-	return strings.TrimSpace(out) != "", nil
+	for _, subject := range strings.Split(out, "\n") {
+		if strings.HasPrefix(subject, "WIP on "+branch+": ") || strings.HasPrefix(subject, "On "+branch+": ") {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 // GetAheadBehind returns how many commits the worktree is ahead/behind its upstream.
@@ -251,30 +731,66 @@ func GetAheadBehind(path string) (ahead, behind int, err error) {
 	return ahead, behind, nil
 }
 
+// defaultBranches caches GetDefaultBranch per repository (common git
+// directory), since every worktree's status needs it. It lasts as long as
+// the process, one sprout command: the next one asks git again, so it sees
+// a changed origin/HEAD without a cache to invalidate.
+var defaultBranches sync.Map
+
 // GetDefaultBranch returns the default branch name for the repository (e.g., main, master).
+// The result is cached for the repository within the command, so worktrees
+// after the first cost no git commands.
 func GetDefaultBranch(path string) (string, error) {
-	// Try to get the default branch from origin/HEAD
-	out, err := RunGitCommand(path, "symbolic-ref", "refs/remotes/origin/HEAD")
-	if err == nil {
-		// Output is like "refs/remotes/origin/main"
-		branch := strings.TrimPrefix(out, "refs/remotes/origin/")
-		if branch != "" {
-			return branch, nil
+	key := commonGitDir(path)
+	if branch, ok := defaultBranches.Load(key); ok {
+		return branch.(string), nil
+	}
+	branch := detectDefaultBranch(path)
+	defaultBranches.Store(key, branch)
+	return branch, nil
+}
+
+// detectDefaultBranch asks git for the default branch: origin/HEAD, the
+// remote.origin.head setting, origin/main or origin/master, and finally,
+// for repositories without a remote, the first local branch of
+// init.defaultBranch, main and master.
+func detectDefaultBranch(path string) string {
+	// Output is like "refs/remotes/origin/main"
+	if out, err := RunGitCommand(path, "symbolic-ref", "refs/remotes/origin/HEAD"); err == nil {
+		if branch := strings.TrimPrefix(out, "refs/remotes/origin/"); branch != "" {
+			return branch
 		}
 	}
+	if out, err := RunGitCommand(path, "config", "--get", "remote.origin.head"); err == nil && out != "" {
+		return strings.TrimPrefix(strings.TrimPrefix(out, "refs/remotes/origin/"), "refs/heads/")
+	}
 
 	// Fallback: check if main exists, then master
 	if _, err := RunGitCommand(path, "rev-parse", "--verify", "origin/main"); err == nil {
-		return "main", nil
+		return "main"
 	}
 	if _, err := RunGitCommand(path, "rev-parse", "--verify", "origin/master"); err == nil {
-		return "master", nil
+		return "master"
 	}
 
-	return "main", nil // Final fallback
+	initDefault, _ := RunGitCommand(path, "config", "--get", "init.defaultBranch")
+	for _, branch := range []string{initDefault, "main", "master"} {
+		if branch == "" {
+			continue
+		}
+		if _, err := RunGitCommand(path, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+			return branch
+		}
+	}
+	if initDefault != "" {
+		return initDefault
+	}
+	return "main" // Final fallback
 }
 
-// IsUnmerged checks if the worktree has commits not in the base branch.
+// IsUnmerged checks if the worktree has commits not in the base branch on
+// origin, or in the local base branch when origin doesn't have it (as in
+// repositories without a remote).
 func IsUnmerged(path, baseBranch string) (bool, error) {
 	// Get current branch
 	currentBranch, err := RunGitCommand(path, "rev-parse", "--abbrev-ref", "HEAD")
@@ -287,44 +803,201 @@ func IsUnmerged(path, baseBranch string) (bool, error) {
 		return false, nil
 	}
 
-	// Check if base branch exists
-	baseRef := "origin/" + baseBranch
+	if unmerged, known := hasCommitsNotIn(path, "origin/"+baseBranch); known {
+		return unmerged, nil
+	}
+	unmerged, _ := hasCommitsNotIn(path, baseBranch)
+	return unmerged, nil
+}
+
+// hasCommitsNotIn reports whether HEAD has commits that baseRef doesn't.
+// Known is false when baseRef doesn't exist, since that can't be determined.
+func hasCommitsNotIn(path, baseRef string) (unmerged, known bool) {
 	if _, err := RunGitCommand(path, "rev-parse", "--verify", baseRef); err != nil {
-		// Base branch doesn't exist, can't determine
-		return false, nil
+		return false, false
 	}
 
 	// Count commits in current branch not in base
 	out, err := RunGitCommand(path, "rev-list", "--count", baseRef+"..HEAD")
 	if err != nil {
-		return false, nil // Silently skip on error
+		return false, false // Silently skip on error
 	}
 
 	count := 0
 	fmt.Sscanf(out, "%d", &count)
-	return count > 0, nil
+	return count > 0, true
 }
 
 // GetWorktreeStatus returns the complete status of a worktree.
 func GetWorktreeStatus(path string) WorktreeStatus {
+	return GetWorktreeStatusAgainst(path, "", "")
+}
+
+// GetWorktreeStatusAgainst is GetWorktreeStatus with unmerged commits
+// counted against base, the ref the branch was created from. An empty or
+// no longer existing base falls back to defaultBranch on origin, or to
+// GetDefaultBranch's answer when no default branch is configured.
+func GetWorktreeStatusAgainst(path, base, defaultBranch string) WorktreeStatus {
 	status := WorktreeStatus{}
 
 	// Get dirty status
-	if dirty, err := IsDirty(path); err == nil {
+	if dirty, untrackedOnly, err := worktreeChanges(path); err == nil {
 		status.Dirty = dirty
+		status.UntrackedOnly = untrackedOnly
 	}
 
-	// Get ahead/behind status
-	if ahead, behind, err := GetAheadBehind(path); err == nil {
-		status.Ahead = ahead
-		status.Behind = behind
+	if stashed, err := HasStash(path); err == nil {
+		status.HasStash = stashed
 	}
 
-	// Get unmerged status
-	baseBranch, _ := GetDefaultBranch(path)
-	if unmerged, err := IsUnmerged(path, baseBranch); err == nil {
-		status.Unmerged = unmerged
+	// Get ahead/behind status; branches of local-only repositories have
+	// nothing to push to or pull from
+	if hasRemote, err := HasRemote(path); err == nil && hasRemote {
+		if ahead, behind, err := GetAheadBehind(path); err == nil {
+			status.Ahead = ahead
+			status.Behind = behind
+		}
+	}
+
+	// Get unmerged status, preferring the recorded base over a guess
+	known := false
+	if base != "" {
+		status.Unmerged, known = hasCommitsNotIn(path, base)
+	}
+	if !known {
+		baseBranch := defaultBranch
+		if baseBranch == "" {
+			baseBranch, _ = GetDefaultBranch(path)
+		}
+		if unmerged, err := IsUnmerged(path, baseBranch); err == nil {
+			status.Unmerged = unmerged
+		}
 	}
 
 	return status
 }
+
+// GetWorktreeStatuses returns the status of each worktree, computed in parallel.
+// The result is indexed like worktrees. Bases maps worktree paths to the ref
+// their branch was created from, where known (see GetWorktreeStatusAgainst).
+func GetWorktreeStatuses(worktrees []Worktree, bases map[string]string, defaultBranch string) []WorktreeStatus {
+	statuses := make([]WorktreeStatus, len(worktrees))
+	var wg sync.WaitGroup
+	for i, wt := range worktrees {
+		wg.Add(1)
+		go func(idx int, path string) {
+			defer wg.Done()
+			statuses[idx] = GetWorktreeStatusAgainst(path, bases[path], defaultBranch)
+		}(i, wt.Path)
+	}
+	wg.Wait()
+	return statuses
+}
+
+// GetRemoteURL returns the configured URL of the named remote (e.g. "origin").
+func GetRemoteURL(repoRoot, remote string) (string, error) {
+	out, err := RunGitCommand(repoRoot, "remote", "get-url", remote)
+	if err != nil {
+		return "", fmt.Errorf("failed to get URL for remote %s: %w", remote, err)
+	}
+	return out, nil
+}
+
+// NormalizeRemoteURL reduces a git remote URL to "host/owner/repo" so that
+// SSH, HTTPS, and scp-style URLs for the same repository compare equal.
+//
+//	git@github.com:mycompany/app.git       → github.com/mycompany/app
+//	https://user@github.com/mycompany/app  → github.com/mycompany/app
+//	ssh://git@github.com:22/mycompany/app  → github.com/mycompany/app
+func NormalizeRemoteURL(raw string) string {
+	host, repoPath := splitRemoteURL(raw)
+	if host == "" {
+		return strings.ToLower(repoPath)
+	}
+	return strings.ToLower(host + "/" + repoPath)
+}
+
+// splitRemoteURL splits a git remote URL into its host and repository path
+// (e.g. "github.com" and "mycompany/app"), preserving case. The host is empty
+// if the URL has no recognizable host, in which case the whole input is
+// returned as the path.
+func splitRemoteURL(raw string) (host, repoPath string) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return "", ""
+	}
+
+	if strings.Contains(s, "://") {
+		if u, err := url.Parse(s); err == nil && u.Host != "" {
+			host, s = u.Hostname(), strings.TrimPrefix(u.Path, "/")
+		}
+	} else if at := strings.Index(s, "@"); at != -1 && strings.Contains(s[at:], ":") {
+		// scp-style: user@host:owner/repo
+		host, s, _ = strings.Cut(s[at+1:], ":")
+	}
+
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "/"), ".git")
+	return host, s
+}
+
+// Forge identifies a code hosting service that sprout can link to.
+type Forge string
+
+const (
+	ForgeGitHub Forge = "github"
+	ForgeGitLab Forge = "gitlab"
+)
+
+// ErrUnsupportedForge is returned when no web URL can be derived for a remote.
+var ErrUnsupportedForge = errors.New("unsupported remote host (only GitHub and GitLab are supported)")
+
+// RemoteRepo is a remote URL parsed into the parts needed for web links.
+type RemoteRepo struct {
+	Host  string // e.g. "github.com" or "gitlab.example.com"
+	Path  string // e.g. "mycompany/app" (GitLab paths may include subgroups)
+	Forge Forge  // Empty if the host is not a known forge
+}
+
+// ParseRemoteURL parses an SSH, HTTPS, or scp-style remote URL. The forge is
+// guessed from the host name, so self-hosted instances such as
+// gitlab.example.com or github.mycompany.com are recognized.
+func ParseRemoteURL(raw string) (RemoteRepo, error) {
+	host, repoPath := splitRemoteURL(raw)
+	if host == "" || repoPath == "" {
+		return RemoteRepo{}, fmt.Errorf("cannot parse remote URL %q", raw)
+	}
+
+	repo := RemoteRepo{Host: host, Path: repoPath}
+	switch lower := strings.ToLower(host); {
+	case strings.Contains(lower, "github"):
+		repo.Forge = ForgeGitHub
+	case strings.Contains(lower, "gitlab"):
+		repo.Forge = ForgeGitLab
+	}
+	return repo, nil
+}
+
+// WebURL returns the repository's home page.
+func (r RemoteRepo) WebURL() string {
+	return "https://" + r.Host + "/" + r.Path
+}
+
+// BranchURL returns the web page for a branch. GitHub offers to open a pull
+// request from there when the branch has recent pushes.
+func (r RemoteRepo) BranchURL(branch string) (string, error) {
+	// Escape each path segment but keep the slashes of "feat/login"
+	segments := strings.Split(branch, "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	escaped := strings.Join(segments, "/")
+
+	switch r.Forge {
+	case ForgeGitHub:
+		return r.WebURL() + "/tree/" + escaped, nil
+	case ForgeGitLab:
+		return r.WebURL() + "/-/tree/" + escaped, nil
+	default:
+		return "", fmt.Errorf("%s: %w", r.Host, ErrUnsupportedForge)
+	}
+}
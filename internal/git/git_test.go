@@ -0,0 +1,404 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeRemoteURL(t *testing.T) {
+	tests := map[string]string{
+		"git@github.com:MyCompany/app.git":      "github.com/mycompany/app",
+		"https://github.com/mycompany/app.git":  "github.com/mycompany/app",
+		"https://user@github.com/mycompany/app": "github.com/mycompany/app",
+		"ssh://git@github.com:22/mycompany/app": "github.com/mycompany/app",
+		"github.com/mycompany/*":                "github.com/mycompany/*",
+		"":                                      "",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, NormalizeRemoteURL(in), in)
+	}
+}
+
+func TestParseRemoteURL(t *testing.T) {
+	repo, err := ParseRemoteURL("git@github.com:MyCompany/app.git")
+	require.NoError(t, err)
+	assert.Equal(t, RemoteRepo{Host: "github.com", Path: "MyCompany/app", Forge: ForgeGitHub}, repo)
+
+	repo, err = ParseRemoteURL("https://gitlab.example.com/group/sub/app.git")
+	require.NoError(t, err)
+	assert.Equal(t, RemoteRepo{Host: "gitlab.example.com", Path: "group/sub/app", Forge: ForgeGitLab}, repo)
+
+	repo, err = ParseRemoteURL("ssh://git@git.example.com:2222/team/app")
+	require.NoError(t, err)
+	assert.Equal(t, Forge(""), repo.Forge)
+
+	_, err = ParseRemoteURL("/srv/git/app.git")
+	assert.Error(t, err)
+}
+
+func TestRemoteRepo_BranchURL(t *testing.T) {
+	github := RemoteRepo{Host: "github.com", Path: "acme/app", Forge: ForgeGitHub}
+	url, err := github.BranchURL("feat/login#2")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/acme/app/tree/feat/login%232", url)
+
+	gitlab := RemoteRepo{Host: "gitlab.com", Path: "acme/app", Forge: ForgeGitLab}
+	url, err = gitlab.BranchURL("main")
+	require.NoError(t, err)
+	assert.Equal(t, "https://gitlab.com/acme/app/-/tree/main", url)
+
+	_, err = RemoteRepo{Host: "git.example.com", Path: "acme/app"}.BranchURL("main")
+	assert.ErrorIs(t, err, ErrUnsupportedForge)
+}
+
+func TestReadHead(t *testing.T) {
+	t.Parallel()
+
+	write := func(path, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	// Main worktree with a .git directory, and a linked worktree with a .git file
+	repo := t.TempDir()
+	write(filepath.Join(repo, ".git", "HEAD"), "ref: refs/heads/main\n")
+	write(filepath.Join(repo, ".git", "worktrees", "feature", "HEAD"), "3f2a1c9d8e7b6a5f4e3d2c1b0a9f8e7d6c5b4a39\n")
+	linked := t.TempDir()
+	write(filepath.Join(linked, ".git"), "gitdir: "+filepath.Join(repo, ".git", "worktrees", "feature")+"\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "src", "pkg"), 0755))
+
+	root, head, err := ReadHead(filepath.Join(repo, "src", "pkg"))
+	require.NoError(t, err)
+	assert.Equal(t, repo, root)
+	assert.Equal(t, "main", head)
+
+	root, head, err = ReadHead(linked)
+	require.NoError(t, err)
+	assert.Equal(t, linked, root)
+	assert.Equal(t, "3f2a1c9", head, "detached HEAD shows the short commit")
+}
+
+func TestParseWorktrees(t *testing.T) {
+	out := "worktree /repo\nHEAD 1a2b\nbranch refs/heads/main\n\n" +
+		"worktree /sprout/usb\nHEAD 3c4d\nbranch refs/heads/usb\nlocked on the USB drive\n\n" +
+		"worktree /sprout/v1\nHEAD 5e6f\ndetached\nlocked\n"
+
+	assert.Equal(t, []Worktree{
+		{Path: "/repo", HEAD: "1a2b", Branch: "main"},
+		{Path: "/sprout/usb", HEAD: "3c4d", Branch: "usb", Locked: true},
+		{Path: "/sprout/v1", HEAD: "5e6f", Locked: true},
+	}, parseWorktrees(out))
+}
+
+func TestRunGitCommand_GitError(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	_, err := RunGitCommand(dir, "rev-parse", "--git-dir")
+
+	var gitErr *GitError
+	require.ErrorAs(t, err, &gitErr)
+	assert.Equal(t, []string{"rev-parse", "--git-dir"}, gitErr.Args)
+	assert.Equal(t, dir, gitErr.Dir)
+	assert.Equal(t, 128, gitErr.ExitCode)
+	assert.Contains(t, gitErr.Stderr, "not a git repository")
+	assert.NotContains(t, err.Error(), "\n")
+	assert.Contains(t, err.Error(), "git rev-parse --git-dir: fatal: not a git repository")
+}
+
+// Not parallel: tracing is global
+func TestSetTrace(t *testing.T) {
+	dir := t.TempDir()
+	var out strings.Builder
+	SetTrace(&out)
+	defer SetTrace(nil)
+
+	_, _ = RunGitCommand(dir, "rev-parse", "--git-dir")
+	SetTrace(nil)
+	_, _ = RunGitCommand(dir, "--version")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, "+ git rev-parse --git-dir (in "+dir+")", lines[0])
+	assert.Regexp(t, `^  git rev-parse --git-dir: exit 128, [\d.]+m?s$`, lines[1])
+}
+
+func TestGitError_Summary(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		stderr string
+		want   string
+	}{
+		{"Preparing worktree (new branch 'x')\nfatal: a branch named 'x' already exists", "fatal: a branch named 'x' already exists"},
+		{"error: pathspec 'nope' did not match\nhint: try again", "error: pathspec 'nope' did not match"},
+		{"Auto packing the repository\nsomething went wrong\n", "something went wrong"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, (&GitError{Stderr: tt.stderr}).Summary())
+	}
+}
+
+func TestIsNetworkCommand(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsNetworkCommand([]string{"fetch", "origin"}))
+	assert.True(t, IsNetworkCommand([]string{"--no-pager", "push", "-u", "origin", "x"}))
+	assert.False(t, IsNetworkCommand([]string{"stash", "push"}))
+	assert.False(t, IsNetworkCommand([]string{"worktree", "add", "fetch"}))
+	assert.False(t, IsNetworkCommand(nil))
+}
+
+func TestIsTransient(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsTransient(&GitError{Stderr: "fatal: unable to access 'https://x/': Could not resolve host: x"}))
+	assert.True(t, IsTransient(&GitError{Stderr: "fatal: the remote end hung up unexpectedly"}))
+	assert.False(t, IsTransient(&GitError{Stderr: "! [rejected] main -> main (non-fast-forward)"}))
+	assert.False(t, IsTransient(&GitError{Stderr: "fatal: couldn't find remote ref nope"}))
+	assert.False(t, IsTransient(errors.New("connection reset")))
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+	transient := &GitError{Stderr: "fatal: unable to access: Connection timed out"}
+
+	t.Run("retries transient errors with backoff", func(t *testing.T) {
+		t.Parallel()
+		var slept []time.Duration
+		var retried []int
+		calls := 0
+		err := Retry(RetryPolicy{
+			Attempts: 3,
+			Delay:    time.Second,
+			OnRetry:  func(attempt int, _ time.Duration, _ error) { retried = append(retried, attempt) },
+			Sleep:    func(d time.Duration) { slept = append(slept, d) },
+		}, func() error {
+			calls++
+			if calls < 3 {
+				return transient
+			}
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, []int{1, 2}, retried)
+		assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, slept)
+	})
+
+	t.Run("gives up after the last attempt", func(t *testing.T) {
+		t.Parallel()
+		calls := 0
+		err := Retry(RetryPolicy{Attempts: 2, Sleep: func(time.Duration) {}}, func() error {
+			calls++
+			return transient
+		})
+
+		assert.Equal(t, transient, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("does not retry permanent errors", func(t *testing.T) {
+		t.Parallel()
+		calls := 0
+		rejected := &GitError{Stderr: "! [rejected] main -> main (fetch first)"}
+		err := Retry(RetryPolicy{Attempts: 5, Sleep: func(time.Duration) {}}, func() error {
+			calls++
+			return rejected
+		})
+
+		assert.Equal(t, rejected, err)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestGetWorktreeStatusAgainst(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	git := func(args ...string) {
+		_, err := RunGitCommand(dir, append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		require.NoError(t, err)
+	}
+	git("init", "-q", "-b", "develop")
+	git("commit", "-q", "--allow-empty", "-m", "base")
+	git("checkout", "-q", "-b", "feature")
+	git("commit", "-q", "--allow-empty", "-m", "work")
+
+	assert.True(t, GetWorktreeStatusAgainst(dir, "develop", "").Unmerged)
+	assert.False(t, GetWorktreeStatusAgainst(dir, "feature", "").Unmerged)
+	assert.False(t, GetWorktreeStatusAgainst(dir, "gone", "").Unmerged, "a missing base falls back to origin, which doesn't exist")
+}
+
+func TestGetWorktreeStatusAgainst_ChangesAndStash(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	git := func(args ...string) {
+		_, err := RunGitCommand(dir, append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		require.NoError(t, err)
+	}
+	git("init", "-q", "-b", "main")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tracked.txt"), []byte("v1\n"), 0644))
+	git("add", "tracked.txt")
+	git("commit", "-q", "-m", "base")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new\n"), 0644))
+	status := GetWorktreeStatusAgainst(dir, "main", "")
+	assert.True(t, status.Dirty)
+	assert.True(t, status.UntrackedOnly)
+	assert.False(t, status.HasStash)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tracked.txt"), []byte("v2\n"), 0644))
+	status = GetWorktreeStatusAgainst(dir, "main", "")
+	assert.True(t, status.Dirty)
+	assert.False(t, status.UntrackedOnly, "modified files make the worktree really dirty")
+
+	git("stash", "push", "-q", "--include-untracked")
+	status = GetWorktreeStatusAgainst(dir, "main", "")
+	assert.False(t, status.Dirty)
+	assert.True(t, status.HasStash)
+
+	git("checkout", "-q", "-b", "other")
+	assert.False(t, GetWorktreeStatusAgainst(dir, "main", "").HasStash, "stash entries of other branches don't count")
+}
+
+func TestGetDefaultBranch(t *testing.T) {
+	t.Parallel()
+	newRepo := func() (string, func(...string)) {
+		dir := t.TempDir()
+		git := func(args ...string) {
+			_, err := RunGitCommand(dir, append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+			require.NoError(t, err)
+		}
+		git("init", "-q", "-b", "trunk")
+		git("commit", "-q", "--allow-empty", "-m", "base")
+		return dir, git
+	}
+
+	t.Run("init.defaultBranch without a remote", func(t *testing.T) {
+		t.Parallel()
+		dir, git := newRepo()
+		git("config", "init.defaultBranch", "trunk")
+
+		branch, err := GetDefaultBranch(dir)
+		require.NoError(t, err)
+		assert.Equal(t, "trunk", branch)
+	})
+
+	t.Run("remote.origin.head", func(t *testing.T) {
+		t.Parallel()
+		dir, git := newRepo()
+		git("config", "remote.origin.head", "refs/remotes/origin/develop")
+
+		branch, err := GetDefaultBranch(dir)
+		require.NoError(t, err)
+		assert.Equal(t, "develop", branch)
+	})
+
+	t.Run("cached for all worktrees of the repository", func(t *testing.T) {
+		t.Parallel()
+		dir, git := newRepo()
+		git("config", "init.defaultBranch", "trunk")
+		linked := filepath.Join(t.TempDir(), "feature")
+		git("worktree", "add", "-q", "-b", "feature", linked)
+
+		branch, err := GetDefaultBranch(dir)
+		require.NoError(t, err)
+		assert.Equal(t, "trunk", branch)
+
+		git("config", "init.defaultBranch", "changed")
+		branch, err = GetDefaultBranch(linked)
+		require.NoError(t, err)
+		assert.Equal(t, "trunk", branch, "the linked worktree shares the main worktree's cache entry")
+	})
+}
+
+// Not parallel: ResolveEnvOverrides reads and unsets process environment.
+func TestResolveEnvOverrides(t *testing.T) {
+	dir := t.TempDir()
+	_, err := RunGitCommand(dir, "init", "-q")
+	require.NoError(t, err)
+	bare := t.TempDir()
+	_, err = RunGitCommand(bare, "init", "-q", "--bare")
+	require.NoError(t, err)
+	root, err := GetRepoRootIn(dir)
+	require.NoError(t, err)
+
+	t.Run("unset", func(t *testing.T) {
+		got, err := ResolveEnvOverrides()
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("selects a worktree", func(t *testing.T) {
+		t.Setenv("GIT_DIR", filepath.Join(dir, ".git"))
+		t.Setenv("GIT_WORK_TREE", dir)
+
+		got, err := ResolveEnvOverrides()
+		require.NoError(t, err)
+		assert.Equal(t, root, got)
+		_, set := os.LookupEnv("GIT_DIR")
+		assert.False(t, set, "unset so git runs in each worktree's own directory")
+	})
+
+	t.Run("bare repository", func(t *testing.T) {
+		t.Setenv("GIT_DIR", bare)
+
+		_, err := ResolveEnvOverrides()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "doesn't select a work tree")
+	})
+
+	t.Run("work tree git doesn't know", func(t *testing.T) {
+		t.Setenv("GIT_DIR", filepath.Join(dir, ".git"))
+		t.Setenv("GIT_WORK_TREE", t.TempDir())
+
+		_, err := ResolveEnvOverrides()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "which git doesn't know as a worktree")
+	})
+}
+
+func TestGetWorktreeStatusAgainst_NoRemote(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	git := func(args ...string) {
+		_, err := RunGitCommand(dir, append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		require.NoError(t, err)
+	}
+	git("init", "-q", "-b", "main")
+	git("commit", "-q", "--allow-empty", "-m", "base")
+	git("checkout", "-q", "-b", "feature")
+	git("commit", "-q", "--allow-empty", "-m", "work")
+	git("branch", "--set-upstream-to=main")
+
+	hasRemote, err := HasRemote(dir)
+	require.NoError(t, err)
+	assert.False(t, hasRemote)
+
+	status := GetWorktreeStatusAgainst(dir, "", "main")
+	assert.Zero(t, status.Ahead, "nothing to push without a remote")
+	assert.True(t, status.Unmerged, "counted against the local main")
+}
+
+func TestHasRemote(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	_, err := RunGitCommand(dir, "init", "-q")
+	require.NoError(t, err)
+	_, err = RunGitCommand(dir, "remote", "add", "origin", "https://example.com/repo.git")
+	require.NoError(t, err)
+
+	hasRemote, err := HasRemote(dir)
+	require.NoError(t, err)
+	assert.True(t, hasRemote)
+}
@@ -1,13 +1,25 @@
 package hooks
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/git"
+	"github.com/m44rten1/sprout/internal/logging"
+	"github.com/m44rten1/sprout/internal/sprout"
+	"github.com/m44rten1/sprout/internal/style"
+	"github.com/m44rten1/sprout/internal/timing"
 	"github.com/m44rten1/sprout/internal/trust"
+	"golang.org/x/term"
 )
 
 // HookType represents the type of hook to run
@@ -16,21 +28,12 @@ type HookType string
 const (
 	OnCreate HookType = "on_create"
 	OnOpen   HookType = "on_open"
+	PostSync HookType = "post_sync"
 )
 
-// RunHooks executes hooks for the given hook type
+// RunHooks executes hooks for the given hook type. on_create runs the
+// user's default_hooks first (see config.DefaultHooks).
 func RunHooks(repoRoot, worktreePath, mainWorktreePath string, hookType HookType) error {
-	// Check if main worktree is trusted (not the current worktree)
-	// Trust is per-repository, not per-worktree
-	trusted, err := trust.IsRepoTrusted(mainWorktreePath)
-	if err != nil {
-		return fmt.Errorf("failed to check trust status: %w", err)
-	}
-
-	if !trusted {
-		return &UntrustedError{RepoRoot: mainWorktreePath}
-	}
-
 	// Load config with fallback from worktree to main worktree
 	cfg, err := config.Load(worktreePath, mainWorktreePath)
 	if err != nil {
@@ -38,12 +41,19 @@ func RunHooks(repoRoot, worktreePath, mainWorktreePath string, hookType HookType
 	}
 
 	// Get commands for this hook type
-	var commands []string
+	var commands, repoCommands []string
 	switch hookType {
 	case OnCreate:
-		commands = cfg.Hooks.OnCreate
+		userCfg, err := config.LoadUser()
+		if err != nil {
+			return fmt.Errorf("failed to load user config: %w", err)
+		}
+		commands = append(slices.Clone(config.DefaultHooks(cfg, userCfg)), cfg.Hooks.OnCreate...)
+		repoCommands = cfg.Hooks.OnCreate
 	case OnOpen:
-		commands = cfg.Hooks.OnOpen
+		commands, repoCommands = cfg.Hooks.OnOpen, cfg.Hooks.OnOpen
+	case PostSync:
+		commands, repoCommands = cfg.Hooks.PostSync, cfg.Hooks.PostSync
 	default:
 		return fmt.Errorf("unknown hook type: %s", hookType)
 	}
@@ -53,13 +63,56 @@ func RunHooks(repoRoot, worktreePath, mainWorktreePath string, hookType HookType
 		return nil
 	}
 
-	fmt.Printf("\n🪝 Running %s hooks...\n\n", hookType)
+	// Trust is keyed by the main worktree, but covers the hooks of cfg, which
+	// a branch may have changed. The user's own commands don't need it, but
+	// a policy that denies hooks covers them too
+	trusted, err := trust.IsRepoTrusted(mainWorktreePath, worktreePath)
+	if err != nil {
+		return fmt.Errorf("failed to check trust status: %w", err)
+	}
 
-	// Execute commands sequentially
-	for i, cmd := range commands {
-		fmt.Printf("[%d/%d] %s\n", i+1, len(commands), cmd)
+	if !trusted && len(repoCommands) > 0 {
+		untrusted := &UntrustedError{RepoRoot: mainWorktreePath}
+		if worktreePath != mainWorktreePath {
+			if mainTrusted, err := trust.IsRepoTrusted(mainWorktreePath, mainWorktreePath); err == nil && mainTrusted {
+				untrusted.WorktreePath = worktreePath
+			}
+		}
+		return untrusted
+	}
 
-		if err := executeCommand(cmd, worktreePath, repoRoot, hookType); err != nil {
+	env := newHookEnv(repoRoot, worktreePath, mainWorktreePath, hookType, cfg.DefaultBranch)
+	output := OutputMode(cfg.Hooks.Output)
+
+	if output != config.HookOutputQuiet {
+		fmt.Printf("\n🪝 %s\n\n", style.Bold(fmt.Sprintf("Running %s hooks...", hookType)))
+	}
+
+	// Execute commands sequentially, recording how each went for 'sprout hooks'
+	run := sprout.HookRun{At: time.Now()}
+	defer func() {
+		if err := sprout.RecordHookRun(worktreePath, string(hookType), run); err != nil {
+			logging.Printf("record %s hooks: %v", hookType, err)
+		}
+	}()
+	for i, cmd := range commands {
+		counter := style.Gray(fmt.Sprintf("[%d/%d]", i+1, len(commands)))
+
+		started := time.Now()
+		var err error
+		if output == config.HookOutputFull {
+			fmt.Printf("%s %s\n", counter, cmd)
+			err = executeCommand(cmd, worktreePath, env, os.Stdin, os.Stdout, os.Stderr)
+		} else {
+			err = runCaptured(cmd, worktreePath, env, counter, output == config.HookOutputSummary, os.Stdout, os.Stderr)
+		}
+		timing.Record(timing.Hook, string(hookType)+": "+cmd, started)
+		result := sprout.HookCommandRun{Command: cmd, Duration: time.Since(started)}
+		if err != nil {
+			result.ExitCode = getExitCode(err)
+		}
+		run.Commands = append(run.Commands, result)
+		if err != nil {
 			return &HookExecutionError{
 				Command:  cmd,
 				ExitCode: getExitCode(err),
@@ -68,28 +121,147 @@ func RunHooks(repoRoot, worktreePath, mainWorktreePath string, hookType HookType
 		}
 	}
 
-	fmt.Printf("\n✅ All %s hooks completed successfully\n\n", hookType)
+	if output != config.HookOutputQuiet {
+		fmt.Printf("\n✅ %s\n\n", style.Green(fmt.Sprintf("All %s hooks completed successfully", hookType)))
+	}
 	return nil
 }
 
+// OutputMode returns how much hook output to show: SPROUT_HOOK_OUTPUT (set
+// to quiet by --quiet-hooks) when it names a mode, else the configured one,
+// else full.
+func OutputMode(configured config.HookOutput) config.HookOutput {
+	switch mode := config.HookOutput(os.Getenv("SPROUT_HOOK_OUTPUT")); mode {
+	case config.HookOutputFull, config.HookOutputSummary, config.HookOutputQuiet:
+		return mode
+	}
+	if configured == "" {
+		return config.HookOutputFull
+	}
+	return configured
+}
+
+// runCaptured runs a command with its output held back, and prints that
+// output only if the command fails. With summary set, a spinner stands in
+// for the command while it runs (on a terminal that takes colors) and a
+// line with its duration replaces it. Commands get no stdin, as nobody would see them ask.
+// The outcome goes to stdout, a failure and its output to stderr.
+func runCaptured(command, worktreePath string, env []string, counter string, summary bool, stdout, stderr io.Writer) error {
+	var output bytes.Buffer
+	started := time.Now()
+
+	stop := func() {}
+	if summary && style.Enabled() && term.IsTerminal(int(os.Stdout.Fd())) {
+		stop = spin(counter + " " + command)
+	}
+	err := executeCommand(command, worktreePath, env, nil, &output, &output)
+	stop()
+
+	elapsed := style.Gray(time.Since(started).Round(10 * time.Millisecond).String())
+	switch {
+	case err != nil:
+		fmt.Fprintf(stderr, "%s %s %s %s\n", style.Red("✗"), counter, command, elapsed)
+		stderr.Write(output.Bytes())
+	case summary:
+		fmt.Fprintf(stdout, "%s %s %s %s\n", style.Green("✓"), counter, command, elapsed)
+	}
+	return err
+}
+
+// spin animates a spinner before label until the returned function is
+// called, which clears the line again.
+func spin(label string) func() {
+	frames := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for i := 0; ; i++ {
+			fmt.Printf("\r\033[K%s %s", style.Cyan(frames[i%len(frames)]), label)
+			select {
+			case <-done:
+				fmt.Print("\r\033[K")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// newHookEnv computes the SPROUT_* variables exported to every hook command.
+// Branch lookups are best-effort: a detached HEAD or missing origin leaves them empty.
+// The worktree index and port base are omitted if the index store is unreadable.
+// The base branch is the one the worktree was created from, if sprout
+// recorded it, and otherwise the default branch: a configured one takes
+// precedence over asking git.
+// The scratch directory is created if the worktree predates it, and omitted
+// if it can't be.
+func newHookEnv(repoRoot, worktreePath, mainWorktreePath string, hookType HookType, defaultBranch string) []string {
+	branch, err := git.RunGitCommand(worktreePath, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil || branch == "HEAD" {
+		branch = ""
+	}
+	baseBranch := recordedBase(worktreePath)
+	if baseBranch == "" {
+		baseBranch = defaultBranch
+	}
+	if baseBranch == "" {
+		baseBranch, _ = git.GetDefaultBranch(mainWorktreePath)
+	}
+
+	env := []string{
+		fmt.Sprintf("SPROUT_REPO_ROOT=%s", repoRoot),
+		fmt.Sprintf("SPROUT_WORKTREE_PATH=%s", worktreePath),
+		fmt.Sprintf("SPROUT_MAIN_WORKTREE_PATH=%s", mainWorktreePath),
+		fmt.Sprintf("SPROUT_HOOK_TYPE=%s", hookType),
+		fmt.Sprintf("SPROUT_BRANCH=%s", branch),
+		fmt.Sprintf("SPROUT_BASE_BRANCH=%s", baseBranch),
+		fmt.Sprintf("SPROUT_WORKTREE_NAME=%s", core.WorktreeName(branch, worktreePath)),
+	}
+
+	if index, err := sprout.WorktreeIndex(mainWorktreePath, worktreePath); err == nil {
+		env = append(env,
+			fmt.Sprintf("SPROUT_WORKTREE_INDEX=%d", index),
+			fmt.Sprintf("SPROUT_PORT_BASE=%d", sprout.PortBase(index)),
+		)
+	}
+	if root, err := sprout.GetSproutRoot(); err == nil {
+		scratch := sprout.ScratchDir(root, worktreePath)
+		if err := os.MkdirAll(scratch, 0755); err == nil {
+			env = append(env, fmt.Sprintf("SPROUT_SCRATCH=%s", scratch))
+		}
+	}
+	return env
+}
+
+// recordedBase returns the ref the worktree's branch was created from, as a
+// branch name ("origin/main" → "main"), or "" if sprout didn't record one.
+func recordedBase(worktreePath string) string {
+	metadata, err := sprout.LoadMetadata()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(metadata[worktreePath].Base, "origin/")
+}
+
 // executeCommand runs a single command in the worktree directory
-func executeCommand(command, worktreePath, repoRoot string, hookType HookType) error {
+func executeCommand(command, worktreePath string, env []string, stdin io.Reader, stdout, stderr io.Writer) error {
 	// Use sh -lc to execute the command (loads user's profile for proper PATH, etc.)
 	cmd := exec.Command("sh", "-lc", command)
 	cmd.Dir = worktreePath
 
 	// Set environment variables
-	cmd.Env = os.Environ()
-	cmd.Env = append(cmd.Env,
-		fmt.Sprintf("SPROUT_REPO_ROOT=%s", repoRoot),
-		fmt.Sprintf("SPROUT_WORKTREE_PATH=%s", worktreePath),
-		fmt.Sprintf("SPROUT_HOOK_TYPE=%s", hookType),
-	)
+	cmd.Env = append(os.Environ(), env...)
 
-	// Pass through stdout and stderr
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
 	return cmd.Run()
 }
@@ -102,13 +274,20 @@ func getExitCode(err error) int {
 	return 1
 }
 
-// UntrustedError is returned when trying to run hooks for an untrusted repo
+// UntrustedError is returned when trying to run hooks for an untrusted repo.
+// WorktreePath is set when the repository is trusted, but the worktree's
+// .sprout.yml has hooks other than the trusted ones.
 type UntrustedError struct {
-	RepoRoot string
+	RepoRoot     string
+	WorktreePath string
 }
 
 func (e *UntrustedError) Error() string {
-	return fmt.Sprintf("hooks are not trusted for this repository")
+	if e.WorktreePath != "" {
+		return fmt.Sprintf("the hooks in %s differ from the ones trusted for this repository; review them and run 'sprout trust %s' to allow them",
+			filepath.Join(e.WorktreePath, ".sprout.yml"), e.WorktreePath)
+	}
+	return "hooks are not trusted for this repository"
 }
 
 // PrintUntrustedMessage prints a helpful message about trusting a repo
@@ -121,7 +300,7 @@ func PrintUntrustedMessageWithConfig(repoRoot string, cfg *config.Config) {
 	configPath := filepath.Join(repoRoot, ".sprout.yml")
 
 	fmt.Println()
-	fmt.Println("🔒 Found .sprout.yml but this repository is not trusted yet.")
+	fmt.Println("🔒 " + style.Bold("Found .sprout.yml but this repository is not trusted yet."))
 	fmt.Println()
 	fmt.Printf("   Config: %s\n", configPath)
 
@@ -174,7 +353,7 @@ func CheckAndPrintUntrusted(repoRoot, mainWorktreePath string) (bool, error) {
 		return false, nil
 	}
 
-	trusted, err := trust.IsRepoTrusted(mainWorktreePath)
+	trusted, err := trust.IsRepoTrusted(mainWorktreePath, repoRoot)
 	if err != nil {
 		return false, err
 	}
@@ -0,0 +1,78 @@
+package hooks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/sprout"
+	"github.com/m44rten1/sprout/internal/style"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordedBase(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	fromOrigin, fromLocal, unrecorded := t.TempDir(), t.TempDir(), t.TempDir()
+	require.NoError(t, sprout.RecordBase(fromOrigin, "origin/develop"))
+	require.NoError(t, sprout.RecordBase(fromLocal, "release/1.x"))
+
+	assert.Equal(t, "develop", recordedBase(fromOrigin))
+	assert.Equal(t, "release/1.x", recordedBase(fromLocal))
+	assert.Empty(t, recordedBase(unrecorded), "a worktree without a recorded base falls back to the default branch")
+}
+
+func TestOutputMode(t *testing.T) {
+	t.Run("defaults to full", func(t *testing.T) {
+		t.Setenv("SPROUT_HOOK_OUTPUT", "")
+		assert.Equal(t, config.HookOutputFull, OutputMode(""))
+	})
+
+	t.Run("configured mode", func(t *testing.T) {
+		t.Setenv("SPROUT_HOOK_OUTPUT", "")
+		assert.Equal(t, config.HookOutputSummary, OutputMode(config.HookOutputSummary))
+	})
+
+	t.Run("environment beats the configured mode", func(t *testing.T) {
+		t.Setenv("SPROUT_HOOK_OUTPUT", "quiet")
+		assert.Equal(t, config.HookOutputQuiet, OutputMode(config.HookOutputFull))
+	})
+
+	t.Run("unknown environment value is ignored", func(t *testing.T) {
+		t.Setenv("SPROUT_HOOK_OUTPUT", "loud")
+		assert.Equal(t, config.HookOutputSummary, OutputMode(config.HookOutputSummary))
+	})
+}
+
+func TestRunCaptured(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	t.Run("success shows no output", func(t *testing.T) {
+		t.Parallel()
+		var stdout, stderr bytes.Buffer
+		require.NoError(t, runCaptured("echo building", dir, nil, "[1/1]", false, &stdout, &stderr))
+		assert.Empty(t, stdout.String())
+		assert.Empty(t, stderr.String())
+	})
+
+	t.Run("summary shows the command but not its output", func(t *testing.T) {
+		t.Parallel()
+		var stdout, stderr bytes.Buffer
+		require.NoError(t, runCaptured("echo building", dir, nil, "[1/1]", true, &stdout, &stderr))
+		assert.Contains(t, style.Strip(stdout.String()), "✓ [1/1] echo building")
+		assert.NotContains(t, stdout.String(), "building\n")
+		assert.Empty(t, stderr.String())
+	})
+
+	t.Run("failure shows the output", func(t *testing.T) {
+		t.Parallel()
+		var stdout, stderr bytes.Buffer
+		err := runCaptured("echo out; echo err >&2; exit 3", dir, nil, "[1/1]", true, &stdout, &stderr)
+		require.Error(t, err)
+		assert.Equal(t, 3, getExitCode(err))
+		assert.Empty(t, stdout.String())
+		assert.Contains(t, style.Strip(stderr.String()), "✗ [1/1] echo out; echo err >&2; exit 3")
+		assert.Contains(t, stderr.String(), "out\nerr\n")
+	})
+}
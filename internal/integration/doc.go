@@ -0,0 +1,11 @@
+// Package integration runs sprout's commands against real git repositories,
+// through RealEffects, to catch what tests with TestEffects can't: git
+// refusing the arguments sprout passes it, or worktrees that don't end up
+// the way the plan said.
+//
+// Each test gets a repository cloned from a bare remote over a file:// URL,
+// with sprout's state in a temporary home directory. The tests need git and
+// are opt-in, behind the integration build tag:
+//
+//	go test -tags integration ./internal/integration
+package integration
@@ -0,0 +1,197 @@
+//go:build integration
+
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m44rten1/sprout/cmd"
+	"github.com/m44rten1/sprout/internal/build"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/style"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdd_NewBranch(t *testing.T) {
+	e := newEnv(t)
+
+	ctx := e.add([]string{"feature"}, build.AddOptions{})
+
+	assert.DirExists(t, ctx.WorktreePath)
+	assert.True(t, strings.HasPrefix(ctx.WorktreePath, e.Home), "worktree under the sprout root: %s", ctx.WorktreePath)
+	assert.Equal(t, "feature", e.git(ctx.WorktreePath, "branch", "--show-current"))
+	assert.Equal(t, e.git(e.Repo, "rev-parse", "origin/main"), e.git(ctx.WorktreePath, "rev-parse", "HEAD"))
+	assert.Contains(t, e.worktrees(), ctx.WorktreePath)
+
+	// A new branch must not track origin/main, or 'git push' would push there
+	err := exec.Command("git", "-C", ctx.WorktreePath, "rev-parse", "--abbrev-ref", "feature@{upstream}").Run()
+	assert.Error(t, err, "feature has no upstream")
+}
+
+func TestAdd_RemoteBranch(t *testing.T) {
+	e := newEnv(t)
+	head := e.pushBranch("bugfix")
+
+	ctx := e.add([]string{"origin/bugfix"}, build.AddOptions{})
+
+	assert.Equal(t, "bugfix", ctx.Branch)
+	assert.Equal(t, head, e.git(ctx.WorktreePath, "rev-parse", "HEAD"))
+	assert.Equal(t, "origin/bugfix", e.git(ctx.WorktreePath, "rev-parse", "--abbrev-ref", "bugfix@{upstream}"))
+	assert.FileExists(t, filepath.Join(ctx.WorktreePath, "bugfix.txt"))
+}
+
+func TestAdd_ExistingLocalBranch(t *testing.T) {
+	e := newEnv(t)
+	e.git(e.Repo, "branch", "topic")
+	head := e.commit(e.Repo, "main.txt", "moved on\n")
+
+	ctx := e.add([]string{"topic"}, build.AddOptions{})
+
+	assert.Equal(t, "topic", e.git(ctx.WorktreePath, "branch", "--show-current"))
+	assert.NotEqual(t, head, e.git(ctx.WorktreePath, "rev-parse", "HEAD"), "the branch, not main")
+}
+
+func TestAdd_ExistingWorktree(t *testing.T) {
+	e := newEnv(t)
+	first := e.add([]string{"feature"}, build.AddOptions{})
+
+	again := e.add([]string{"feature"}, build.AddOptions{})
+
+	assert.Equal(t, first.WorktreePath, again.WorktreePath)
+	assert.Len(t, e.worktrees(), 2)
+}
+
+func TestAdd_Lock(t *testing.T) {
+	e := newEnv(t)
+
+	ctx := e.add([]string{"feature"}, build.AddOptions{Lock: true, LockReason: "on the USB drive"})
+
+	assert.Contains(t, e.git(e.Repo, "worktree", "list", "--porcelain"), "locked on the USB drive")
+	require.NoError(t, os.RemoveAll(ctx.WorktreePath))
+	e.git(e.Repo, "worktree", "prune")
+	assert.Contains(t, e.worktrees(), ctx.WorktreePath, "prune keeps a locked worktree")
+}
+
+func TestAdd_Hooks(t *testing.T) {
+	e := newEnv(t)
+	e.writeConfig("hooks:\n  on_create:\n    - echo \"$SPROUT_BRANCH $SPROUT_MAIN_WORKTREE_PATH\" > created.txt\n")
+
+	fx := e.fx(e.Repo)
+	ctx, err := build.AddContext(fx, []string{"feature"}, build.AddOptions{NoOpen: true})
+	require.NoError(t, err)
+	assert.Error(t, e.tryRun(core.PlanAddCommand(ctx), fx), "hooks of an untrusted repository need a prompt")
+
+	ctx = e.add([]string{"hooked"}, build.AddOptions{Trust: true})
+
+	data, err := os.ReadFile(filepath.Join(ctx.WorktreePath, "created.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hooked "+e.Repo+"\n", string(data))
+}
+
+func TestOpen(t *testing.T) {
+	e := newEnv(t)
+	ctx := e.add([]string{"feature/login"}, build.AddOptions{})
+
+	assert.Equal(t, []string{ctx.WorktreePath}, e.open("login"))
+	assert.Equal(t, []string{ctx.WorktreePath}, e.open(ctx.WorktreePath))
+}
+
+func TestRemove(t *testing.T) {
+	e := newEnv(t)
+	ctx := e.add([]string{"feature"}, build.AddOptions{})
+
+	require.NoError(t, e.remove(false, "feature"))
+
+	assert.NoDirExists(t, ctx.WorktreePath)
+	assert.NotContains(t, e.worktrees(), ctx.WorktreePath)
+	assert.NotEmpty(t, e.git(e.Repo, "branch", "--list", "feature"), "the branch is kept")
+}
+
+func TestRemove_Dirty(t *testing.T) {
+	e := newEnv(t)
+	ctx := e.add([]string{"feature"}, build.AddOptions{})
+	e.writeFile(filepath.Join(ctx.WorktreePath, "README.md"), "changed\n")
+
+	assert.Error(t, e.remove(false, "feature"))
+	assert.DirExists(t, ctx.WorktreePath)
+
+	require.NoError(t, e.remove(true, "feature"))
+	assert.NoDirExists(t, ctx.WorktreePath)
+}
+
+// A worktree added where a temporary one was removed is permanent, and
+// prune leaves it alone.
+func TestRemove_ForgetsExpiry(t *testing.T) {
+	e := newEnv(t)
+	tmp := e.add([]string{"tmp1"}, build.AddOptions{TTL: "1h"})
+	fx := e.fx(e.Repo)
+	require.NoError(t, fx.SetExpiry(tmp.WorktreePath, time.Now().Add(-time.Minute)), "expire it without waiting")
+
+	require.NoError(t, e.remove(true, "tmp1"))
+	ctx := e.add([]string{"tmp1"}, build.AddOptions{NoHooks: true})
+	require.Equal(t, tmp.WorktreePath, ctx.WorktreePath)
+
+	assert.NotContains(t, e.list(), "expired")
+	pruneCtx, err := cmd.BuildPruneContext(fx)
+	require.NoError(t, err)
+	assert.Empty(t, pruneCtx.Expired)
+	assert.DirExists(t, ctx.WorktreePath)
+}
+
+// A pin belongs to the worktree: one added again for the branch after
+// removing the pinned one is not pinned.
+func TestRemove_ForgetsPin(t *testing.T) {
+	e := newEnv(t)
+	pinned := e.add([]string{"feature"}, build.AddOptions{})
+	fx := e.fx(e.Repo)
+	pinCtx, err := cmd.BuildPinContext(fx, []string{"feature"})
+	require.NoError(t, err)
+	e.run(core.PlanPinCommand(pinCtx, true), fx)
+
+	require.NoError(t, e.remove(false, "feature"))
+	ctx := e.add([]string{"feature"}, build.AddOptions{})
+	require.Equal(t, pinned.WorktreePath, ctx.WorktreePath)
+
+	metadata, err := fx.LoadWorktreeMetadata()
+	require.NoError(t, err)
+	assert.False(t, metadata[ctx.WorktreePath].Pinned)
+	assert.NotContains(t, e.list(), "📌")
+}
+
+func TestRemove_MainWorktree(t *testing.T) {
+	e := newEnv(t)
+
+	assert.Error(t, e.remove(true, e.Repo))
+	assert.DirExists(t, e.Repo)
+}
+
+func TestList(t *testing.T) {
+	e := newEnv(t)
+	e.add([]string{"clean"}, build.AddOptions{})
+	dirty := e.add([]string{"dirty"}, build.AddOptions{})
+	e.writeFile(filepath.Join(dirty.WorktreePath, "README.md"), "changed\n")
+
+	// The branch lines, told apart from the path lines below them
+	lines := map[string]string{}
+	for _, line := range strings.Split(e.list(), "\n") {
+		for _, branch := range []string{"clean", "dirty"} {
+			if slices.Contains(strings.Fields(line), branch) {
+				lines[branch] = line
+			}
+		}
+	}
+	require.Contains(t, lines, "clean")
+	require.Contains(t, lines, "dirty")
+	assert.NotContains(t, lines["clean"], style.CurrentIcons().Dirty)
+	assert.Contains(t, lines["dirty"], style.CurrentIcons().Dirty)
+
+	require.NoError(t, e.remove(false, "clean"))
+	assert.NotContains(t, e.list(), "clean")
+}
@@ -0,0 +1,219 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/m44rten1/sprout/cmd"
+	"github.com/m44rten1/sprout/internal/build"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/style"
+	"github.com/stretchr/testify/require"
+)
+
+// testEnv is a repository cloned from a bare remote, and a second clone of
+// the remote that stands in for someone else pushing to it.
+type testEnv struct {
+	t      *testing.T
+	Home   string
+	Remote string // The bare repository, origin of Repo
+	Repo   string // The main worktree sprout works in
+	other  string
+}
+
+// newEnv creates a remote with one commit on main and clones it. HOME and
+// the XDG directories point into the test's temporary directory, so neither
+// the user's git config nor sprout's own state leak in. The environment is
+// shared by the process, so the tests can't run in parallel.
+func newEnv(t *testing.T) *testEnv {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	// Resolve symlinks (macOS temp dirs) so paths compare equal to git's
+	tmp, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	home := filepath.Join(tmp, "home")
+	require.NoError(t, os.MkdirAll(home, 0755))
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, ".local", "share"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, ".local", "state"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "sprout")
+	t.Setenv("GIT_AUTHOR_EMAIL", "sprout@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "sprout")
+	t.Setenv("GIT_COMMITTER_EMAIL", "sprout@example.com")
+	t.Setenv("SPROUT_NON_INTERACTIVE", "1")
+	style.SetEnabled(false)
+	t.Cleanup(func() { style.SetEnabled(true) })
+
+	e := &testEnv{
+		t:      t,
+		Home:   home,
+		Remote: filepath.Join(tmp, "remote.git"),
+		Repo:   filepath.Join(tmp, "code", "app"),
+		other:  filepath.Join(tmp, "other"),
+	}
+	e.git(tmp, "init", "-q", "--bare", "-b", "main", e.Remote)
+	e.git(tmp, "clone", "-q", "file://"+e.Remote, e.other)
+	e.git(e.other, "checkout", "-q", "-b", "main")
+	e.commit(e.other, "README.md", "hello\n")
+	e.git(e.other, "push", "-q", "origin", "main")
+	e.git(tmp, "clone", "-q", "file://"+e.Remote, e.Repo)
+	return e
+}
+
+// git runs git in dir and returns its output without the trailing newline,
+// failing the test if git does.
+func (e *testEnv) git(dir string, args ...string) string {
+	e.t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	require.NoError(e.t, err, "git %s\n%s", strings.Join(args, " "), out)
+	return strings.TrimRight(string(out), "\n")
+}
+
+// commit writes content to name in the worktree dir and commits it,
+// returning the new commit.
+func (e *testEnv) commit(dir, name, content string) string {
+	e.t.Helper()
+	e.writeFile(filepath.Join(dir, name), content)
+	e.git(dir, "add", name)
+	e.git(dir, "commit", "-q", "-m", "update "+name)
+	return e.git(dir, "rev-parse", "HEAD")
+}
+
+func (e *testEnv) writeFile(path, content string) {
+	e.t.Helper()
+	require.NoError(e.t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(e.t, os.WriteFile(path, []byte(content), 0644))
+}
+
+// pushBranch has someone else push branch to the remote, one commit ahead
+// of main, and fetches it into Repo. It returns the commit.
+func (e *testEnv) pushBranch(branch string) string {
+	e.t.Helper()
+	e.git(e.other, "checkout", "-q", "-b", branch, "main")
+	head := e.commit(e.other, branch+".txt", branch+"\n")
+	e.git(e.other, "push", "-q", "origin", branch)
+	e.git(e.other, "checkout", "-q", "main")
+	e.git(e.Repo, "fetch", "-q", "origin")
+	return head
+}
+
+// writeConfig writes the .sprout.yml of Repo.
+func (e *testEnv) writeConfig(yaml string) {
+	e.t.Helper()
+	e.writeFile(filepath.Join(e.Repo, ".sprout.yml"), yaml)
+}
+
+// fx returns RealEffects for the worktree dir, recording what sprout
+// prints instead of writing it to the test's output.
+func (e *testEnv) fx(dir string) *recordingEffects {
+	return &recordingEffects{Effects: effects.NewRealEffectsIn(dir)}
+}
+
+// run executes plan as the sprout command would, failing the test if the
+// plan reports an error or doesn't complete.
+func (e *testEnv) run(plan core.Plan, fx effects.Effects) {
+	e.t.Helper()
+	require.NoError(e.t, e.tryRun(plan, fx))
+}
+
+// tryRun executes plan, returning why it failed.
+func (e *testEnv) tryRun(plan core.Plan, fx effects.Effects) error {
+	if err := core.PlanErr(plan); err != nil {
+		return err
+	}
+	err := effects.ExecutePlan(plan, fx)
+	if code, ok := effects.IsExit(err); ok {
+		return fmt.Errorf("plan exited with code %d", code)
+	}
+	return err
+}
+
+// add runs 'sprout add' in Repo without opening an editor and returns the
+// worktree's context.
+func (e *testEnv) add(args []string, opts build.AddOptions) core.AddContext {
+	e.t.Helper()
+	opts.NoOpen = true
+	fx := e.fx(e.Repo)
+	ctx, err := build.AddContext(fx, args, opts)
+	require.NoError(e.t, err)
+	e.run(core.PlanAddCommand(ctx), fx)
+	return ctx
+}
+
+// open runs 'sprout open --no-open' in Repo and returns what it printed,
+// the worktree's path.
+func (e *testEnv) open(args ...string) []string {
+	e.t.Helper()
+	fx := e.fx(e.Repo)
+	ctx, err := cmd.BuildOpenContext(fx, args, cmd.OpenOptions{NoOpen: true})
+	require.NoError(e.t, err)
+	e.run(core.PlanOpenCommand(ctx), fx)
+	return fx.printed
+}
+
+// remove runs 'sprout remove' in Repo, returning why it failed.
+func (e *testEnv) remove(force bool, args ...string) error {
+	e.t.Helper()
+	fx := e.fx(e.Repo)
+	ctx, err := build.RemoveContext(fx, args, force)
+	if err != nil {
+		return err
+	}
+	return e.tryRun(core.PlanRemoveCommand(ctx), fx)
+}
+
+// list returns the output of 'sprout list' in Repo.
+func (e *testEnv) list() string {
+	e.t.Helper()
+	ctx, err := cmd.BuildListContext(e.fx(e.Repo), false)
+	require.NoError(e.t, err)
+	return core.FormatListOutput(ctx)
+}
+
+// worktrees returns the paths 'git worktree list' gives for Repo.
+func (e *testEnv) worktrees() []string {
+	e.t.Helper()
+	var paths []string
+	for _, line := range strings.Split(e.git(e.Repo, "worktree", "list", "--porcelain"), "\n") {
+		if path, ok := strings.CutPrefix(line, "worktree "); ok {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// recordingEffects keeps what sprout prints, so tests can check it and the
+// test output stays readable. Parallel actions print too, hence mu.
+type recordingEffects struct {
+	effects.Effects
+	mu      sync.Mutex
+	printed []string
+	errors  []string
+}
+
+func (fx *recordingEffects) Print(msg string) {
+	fx.mu.Lock()
+	defer fx.mu.Unlock()
+	fx.printed = append(fx.printed, msg)
+}
+
+func (fx *recordingEffects) PrintErr(msg string) {
+	fx.mu.Lock()
+	defer fx.mu.Unlock()
+	fx.errors = append(fx.errors, msg)
+}
+
+func (*recordingEffects) ReportProgress(step, total int, label string) {}
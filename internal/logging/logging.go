@@ -0,0 +1,91 @@
+// Package logging writes sprout's debug log: a timestamped line for every
+// action executed, git command run and how long each took, meant to be
+// attached to bug reports. Nothing is written unless a log was opened.
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxSize is the size past which a log is rotated when it is opened.
+	MaxSize = 1 << 20
+	// Keep is how many rotated logs are kept (sprout.log.1 is the newest).
+	Keep = 3
+)
+
+var (
+	mu   sync.Mutex
+	file *os.File // nil while logging is off
+	pid  = os.Getpid()
+)
+
+// Open starts appending to the log at path, rotating it first if it has
+// grown past MaxSize. A log that is already open is closed.
+func Open(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() >= MaxSize {
+		rotate(path)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if file != nil {
+		file.Close()
+	}
+	file = f
+	return nil
+}
+
+// rotate shifts path to path.1, path.1 to path.2 and so on, dropping the
+// oldest. Failures are ignored: at worst the log keeps growing.
+func rotate(path string) {
+	_ = os.Remove(path + "." + strconv.Itoa(Keep))
+	for i := Keep - 1; i >= 1; i-- {
+		_ = os.Rename(path+"."+strconv.Itoa(i), path+"."+strconv.Itoa(i+1))
+	}
+	_ = os.Rename(path, path+".1")
+}
+
+// Close stops logging.
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if file == nil {
+		return nil
+	}
+	err := file.Close()
+	file = nil
+	return err
+}
+
+// Enabled reports whether a log is open, so callers can skip building
+// expensive messages.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return file != nil
+}
+
+// Printf appends a line to the log, if one is open. Lines carry the time
+// and process ID, since several sprout processes may share a log.
+func Printf(format string, args ...any) {
+	mu.Lock()
+	defer mu.Unlock()
+	if file == nil {
+		return
+	}
+	// Written unbuffered, so nothing is lost when sprout exits with os.Exit
+	fmt.Fprintf(file, "%s [%d] %s\n", time.Now().Format("2006-01-02T15:04:05.000Z07:00"), pid, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintf(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "sprout.log")
+
+	Printf("dropped while closed")
+	assert.False(t, Enabled())
+
+	require.NoError(t, Open(path))
+	assert.True(t, Enabled())
+	Printf("git %s: ok", "status")
+	require.NoError(t, Close())
+	Printf("dropped after close")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 1)
+	assert.True(t, strings.HasSuffix(lines[0], "] git status: ok"), lines[0])
+}
+
+func TestOpen_Rotates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sprout.log")
+	full := strings.Repeat("x", MaxSize)
+
+	// Fill and reopen the log more often than rotated logs are kept
+	for i := 0; i <= Keep; i++ {
+		require.NoError(t, os.WriteFile(path, []byte(full), 0644))
+		require.NoError(t, Open(path))
+		require.NoError(t, Close())
+	}
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Zero(t, info.Size(), "a full log starts over")
+	for i := 1; i <= Keep; i++ {
+		assert.FileExists(t, filepath.Join(dir, "sprout.log."+strconv.Itoa(i)))
+	}
+	assert.NoFileExists(t, filepath.Join(dir, "sprout.log."+strconv.Itoa(Keep+1)))
+}
@@ -0,0 +1,16 @@
+// Package promptinit keeps 'sprout prompt' fast. The prompt runs on every
+// shell prompt, and most of sprout's start-up time is tcell (pulled in by
+// the fuzzy finder) building a rune width table it never needs there.
+//
+// It must be imported by main only. Go initializes packages in import path
+// order once their own imports are done, so this package, importing only
+// os, is initialized before tcell's dependencies and therefore before tcell.
+package promptinit
+
+import "os"
+
+func init() {
+	if len(os.Args) > 1 && os.Args[1] == "prompt" {
+		os.Setenv("TCELL_MINIMIZE", "1")
+	}
+}
@@ -0,0 +1,55 @@
+// Package reflink makes copy-on-write clones of directory trees on
+// filesystems that support them (APFS, btrfs, XFS). A clone takes no extra
+// space until either copy is modified, so it is nearly as cheap as a
+// symlink while keeping the two trees independent.
+package reflink
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrUnsupported is returned when the platform or filesystem cannot clone.
+var ErrUnsupported = errors.New("copy-on-write clones are not supported here")
+
+// CloneTree clones the directory tree at src to dst, which must not exist.
+// On failure, a partially created dst is removed.
+func CloneTree(src, dst string) error {
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("%s already exists", dst)
+	}
+	if err := cloneTree(src, dst); err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+	return nil
+}
+
+// Supported reports whether files under srcDir can be cloned into dstDir,
+// by cloning a probe file. Both must be on the same clone-capable
+// filesystem. A missing dstDir is probed at its closest existing parent.
+func Supported(srcDir, dstDir string) bool {
+	for {
+		if _, err := os.Stat(dstDir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dstDir)
+		if parent == dstDir {
+			return false
+		}
+		dstDir = parent
+	}
+
+	probe, err := os.CreateTemp(srcDir, ".sprout-reflink-*")
+	if err != nil {
+		return false
+	}
+	probe.Close()
+	defer os.Remove(probe.Name())
+
+	clone := filepath.Join(dstDir, filepath.Base(probe.Name())+".clone")
+	defer os.Remove(clone)
+	return cloneTree(probe.Name(), clone) == nil
+}
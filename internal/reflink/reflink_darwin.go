@@ -0,0 +1,19 @@
+//go:build darwin
+
+package reflink
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// cloneTree uses clonefile(2), which clones whole directories on APFS.
+func cloneTree(src, dst string) error {
+	err := unix.Clonefile(src, dst, unix.CLONE_NOFOLLOW)
+	if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EXDEV) {
+		return fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	return err
+}
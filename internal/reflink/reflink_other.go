@@ -0,0 +1,7 @@
+//go:build !linux && !darwin
+
+package reflink
+
+func cloneTree(src, dst string) error {
+	return ErrUnsupported
+}
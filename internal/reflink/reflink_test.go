@@ -0,0 +1,50 @@
+package reflink
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneTree(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "node_modules")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "pkg"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "pkg", "index.js"), []byte("module.exports = 1"), 0644))
+
+	dst := filepath.Join(filepath.Dir(src), "clone")
+	err := CloneTree(src, dst)
+	if errors.Is(err, ErrUnsupported) {
+		// The temp filesystem can't clone; nothing may be left behind
+		_, statErr := os.Lstat(dst)
+		assert.True(t, os.IsNotExist(statErr))
+		t.Skip("filesystem does not support reflinks")
+	}
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dst, "pkg", "index.js"))
+	require.NoError(t, err)
+	assert.Equal(t, "module.exports = 1", string(data))
+}
+
+func TestCloneTree_ExistingDestination(t *testing.T) {
+	dir := t.TempDir()
+
+	err := CloneTree(dir, dir)
+	assert.ErrorContains(t, err, "already exists")
+}
+
+func TestSupported_LeavesNoProbeFiles(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+
+	Supported(src, filepath.Join(dst, "not", "created", "yet"))
+
+	for _, dir := range []string{src, dst} {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	}
+}
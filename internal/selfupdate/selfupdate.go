@@ -0,0 +1,378 @@
+// Package selfupdate downloads sprout release binaries and replaces the running executable.
+package selfupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReleasesURL is the GitHub API endpoint listing sprout releases (newest first).
+const ReleasesURL = "https://api.github.com/repos/m44rten1/sprout/releases"
+
+// ChecksumsAsset is the checksum file published alongside every release (see .goreleaser.yml).
+const ChecksumsAsset = "checksums.txt"
+
+// Channel selects which releases are eligible for an update.
+type Channel string
+
+const (
+	ChannelStable     Channel = "stable"
+	ChannelPrerelease Channel = "prerelease"
+)
+
+// ParseChannel validates a channel name provided on the command line.
+func ParseChannel(s string) (Channel, error) {
+	switch Channel(s) {
+	case ChannelStable, ChannelPrerelease:
+		return Channel(s), nil
+	default:
+		return "", fmt.Errorf("unknown channel %q (expected %q or %q)", s, ChannelStable, ChannelPrerelease)
+	}
+}
+
+// Release is the subset of the GitHub release payload sprout needs.
+type Release struct {
+	TagName    string  `json:"tag_name"`
+	Draft      bool    `json:"draft"`
+	Prerelease bool    `json:"prerelease"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Asset is a downloadable file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Version returns the release tag without its "v" prefix, matching the
+// version string goreleaser embeds into the binary.
+func (r Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// FindAsset returns the asset with the given name.
+func (r Release) FindAsset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// SelectRelease picks the newest release eligible for the channel.
+// Releases must be ordered newest first (as returned by the GitHub API).
+// Drafts are never selected; prereleases only on the prerelease channel.
+func SelectRelease(releases []Release, channel Channel) (Release, bool) {
+	for _, r := range releases {
+		if r.Draft {
+			continue
+		}
+		if r.Prerelease && channel != ChannelPrerelease {
+			continue
+		}
+		return r, true
+	}
+	return Release{}, false
+}
+
+// CompareVersions compares two release versions ("1.4.0", "1.5.0-rc.1",
+// with or without a "v" prefix) by semver precedence, returning -1, 0 or 1.
+// ok is false when either one is not a release version, such as "dev".
+func CompareVersions(a, b string) (int, bool) {
+	av, aok := parseVersion(a)
+	bv, bok := parseVersion(b)
+	if !aok || !bok {
+		return 0, false
+	}
+	for i := range av.core {
+		if av.core[i] != bv.core[i] {
+			return cmp.Compare(av.core[i], bv.core[i]), true
+		}
+	}
+	return comparePrerelease(av.pre, bv.pre), true
+}
+
+type version struct {
+	core [3]int
+	pre  []string
+}
+
+func parseVersion(s string) (version, bool) {
+	s = strings.TrimPrefix(s, "v")
+	s, _, _ = strings.Cut(s, "+") // Build metadata has no precedence
+	s, pre, hasPre := strings.Cut(s, "-")
+
+	var v version
+	parts := strings.Split(s, ".")
+	if len(parts) != len(v.core) {
+		return version{}, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return version{}, false
+		}
+		v.core[i] = n
+	}
+	if hasPre {
+		v.pre = strings.Split(pre, ".")
+	}
+	return v, true
+}
+
+// comparePrerelease orders pre-release identifiers as semver does: a
+// release outranks its pre-releases, numeric identifiers compare as
+// numbers and rank below alphanumeric ones.
+func comparePrerelease(a, b []string) int {
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+	for i := 0; i < len(a) && i < len(b); i++ {
+		an, aErr := strconv.Atoi(a[i])
+		bn, bErr := strconv.Atoi(b[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return cmp.Compare(an, bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// AssetName returns the archive name goreleaser produces for a platform.
+// Mirrors the name_template in .goreleaser.yml (e.g. "sprout_Darwin_arm64.tar.gz").
+func AssetName(goos, goarch string) string {
+	osName := goos
+	if osName != "" {
+		osName = strings.ToUpper(osName[:1]) + osName[1:]
+	}
+
+	arch := goarch
+	switch goarch {
+	case "amd64":
+		arch = "x86_64"
+	case "386":
+		arch = "i386"
+	}
+
+	ext := "tar.gz"
+	if goos == "windows" {
+		ext = "zip"
+	}
+
+	return fmt.Sprintf("sprout_%s_%s.%s", osName, arch, ext)
+}
+
+// BinaryName returns the executable name inside a release archive.
+func BinaryName(goos string) string {
+	if goos == "windows" {
+		return "sprout.exe"
+	}
+	return "sprout"
+}
+
+// ParseChecksums parses a "<sha256>  <filename>" checksum file into a filename -> hash map.
+func ParseChecksums(data []byte) map[string]string {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return sums
+}
+
+// VerifyChecksum checks that data hashes to the expected hex-encoded SHA256.
+func VerifyChecksum(data []byte, expected string) error {
+	sum := sha256.Sum256(data)
+	actual := hex.EncodeToString(sum[:])
+	if !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, actual)
+	}
+	return nil
+}
+
+// Client fetches release metadata and assets over HTTP.
+type Client struct {
+	HTTP *http.Client
+}
+
+// NewClient creates a Client with a conservative timeout.
+func NewClient() *Client {
+	return &Client{HTTP: &http.Client{Timeout: 60 * time.Second}}
+}
+
+// FetchReleases lists published releases, newest first.
+func (c *Client) FetchReleases() ([]Release, error) {
+	data, err := c.get(ReleasesURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch releases: %w", err)
+	}
+
+	var releases []Release
+	if err := json.Unmarshal(data, &releases); err != nil {
+		return nil, fmt.Errorf("failed to parse releases: %w", err)
+	}
+	return releases, nil
+}
+
+// Download fetches an asset's contents.
+func (c *Client) Download(asset Asset) ([]byte, error) {
+	data, err := c.get(asset.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	return data, nil
+}
+
+func (c *Client) get(url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "sprout-self-update")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// ExtractBinary pulls the named executable out of a .tar.gz or .zip archive.
+func ExtractBinary(archiveName string, archive []byte, binaryName string) ([]byte, error) {
+	if strings.HasSuffix(archiveName, ".zip") {
+		return extractFromZip(archive, binaryName)
+	}
+	return extractFromTarGz(archive, binaryName)
+}
+
+func extractFromTarGz(archive []byte, binaryName string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeReg && filepath.Base(hdr.Name) == binaryName {
+			return io.ReadAll(tr)
+		}
+	}
+	return nil, fmt.Errorf("%s not found in archive", binaryName)
+}
+
+func extractFromZip(archive []byte, binaryName string) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	for _, f := range zr.File {
+		if filepath.Base(f.Name) != binaryName {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("%s not found in archive", binaryName)
+}
+
+// ReplaceExecutable atomically replaces the file at path with data.
+// The new binary is written to a temp file in the same directory and renamed
+// over the original, so a failure never leaves a half-written executable
+// and path always holds one binary or the other.
+func ReplaceExecutable(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".sprout-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file in %s: %w", dir, err)
+	}
+	tmpPath := tmp.Name()
+	// Best-effort cleanup; after a successful rename the temp path no longer exists
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write new binary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write new binary: %w", err)
+	}
+	if err := os.Chmod(tmpPath, 0755); err != nil {
+		return fmt.Errorf("failed to make new binary executable: %w", err)
+	}
+
+	if runtime.GOOS != "windows" {
+		if err := os.Rename(tmpPath, path); err != nil {
+			return fmt.Errorf("failed to install new binary: %w", err)
+		}
+		return nil
+	}
+
+	// Windows cannot overwrite a running executable, but it can rename it
+	oldPath := path + ".old"
+	_ = os.Remove(oldPath)
+	if err := os.Rename(path, oldPath); err != nil {
+		return fmt.Errorf("failed to move current binary aside: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		// Restore the original so the user is never left without a binary
+		_ = os.Rename(oldPath, path)
+		return fmt.Errorf("failed to install new binary: %w", err)
+	}
+	_ = os.Remove(oldPath)
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssetName(t *testing.T) {
+	assert.Equal(t, "sprout_Darwin_x86_64.tar.gz", AssetName("darwin", "amd64"))
+	assert.Equal(t, "sprout_Darwin_arm64.tar.gz", AssetName("darwin", "arm64"))
+	assert.Equal(t, "sprout_Linux_i386.tar.gz", AssetName("linux", "386"))
+	assert.Equal(t, "sprout_Windows_x86_64.zip", AssetName("windows", "amd64"))
+}
+
+func TestSelectRelease(t *testing.T) {
+	releases := []Release{
+		{TagName: "v0.4.0", Draft: true},
+		{TagName: "v0.4.0-rc1", Prerelease: true},
+		{TagName: "v0.3.2"},
+	}
+
+	t.Run("stable skips drafts and prereleases", func(t *testing.T) {
+		r, ok := SelectRelease(releases, ChannelStable)
+		require.True(t, ok)
+		assert.Equal(t, "0.3.2", r.Version())
+	})
+
+	t.Run("prerelease includes prereleases", func(t *testing.T) {
+		r, ok := SelectRelease(releases, ChannelPrerelease)
+		require.True(t, ok)
+		assert.Equal(t, "v0.4.0-rc1", r.TagName)
+	})
+
+	t.Run("no eligible release", func(t *testing.T) {
+		_, ok := SelectRelease([]Release{{TagName: "v1.0.0-rc1", Prerelease: true}}, ChannelStable)
+		assert.False(t, ok)
+	})
+}
+
+func TestParseChannel(t *testing.T) {
+	ch, err := ParseChannel("prerelease")
+	require.NoError(t, err)
+	assert.Equal(t, ChannelPrerelease, ch)
+
+	_, err = ParseChannel("nightly")
+	assert.Error(t, err)
+}
+
+func TestChecksums(t *testing.T) {
+	data := []byte("hello")
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	sums := ParseChecksums([]byte(hash + "  sprout_Darwin_arm64.tar.gz\nmalformed line here\n"))
+	require.Equal(t, hash, sums["sprout_Darwin_arm64.tar.gz"])
+	assert.Len(t, sums, 1)
+
+	assert.NoError(t, VerifyChecksum(data, hash))
+	assert.Error(t, VerifyChecksum([]byte("tampered"), hash))
+}
+
+func TestExtractBinary_TarGz(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, body := range map[string]string{"README.md": "docs", "sprout": "binary"} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(body)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(body))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	data, err := ExtractBinary("sprout_Linux_x86_64.tar.gz", buf.Bytes(), "sprout")
+	require.NoError(t, err)
+	assert.Equal(t, "binary", string(data))
+
+	_, err = ExtractBinary("sprout_Linux_x86_64.tar.gz", buf.Bytes(), "missing")
+	assert.Error(t, err)
+}
+
+func TestReplaceExecutable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sprout")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0755))
+
+	require.NoError(t, ReplaceExecutable(path, []byte("new")))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	_, err = os.Stat(path + ".old")
+	assert.True(t, os.IsNotExist(err), "backup of old binary should be cleaned up")
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.10.0", -1},
+		{"2.0.0", "1.9.9", 1},
+		{"1.5.0-rc.1", "1.5.0", -1},
+		{"1.5.0-rc.2", "1.5.0-rc.10", -1},
+		{"1.5.0-beta", "1.5.0-alpha", 1},
+		{"1.5.0-rc.1", "1.5.0-rc", 1},
+		{"1.5.0-1", "1.5.0-rc", -1},
+		{"1.5.0+build.1", "1.5.0", 0},
+	}
+	for _, tt := range tests {
+		got, ok := CompareVersions(tt.a, tt.b)
+		assert.True(t, ok, "%s vs %s", tt.a, tt.b)
+		assert.Equal(t, tt.want, got, "%s vs %s", tt.a, tt.b)
+	}
+
+	for _, v := range []string{"dev", "1.2", "1.2.x", ""} {
+		_, ok := CompareVersions(v, "1.2.3")
+		assert.False(t, ok, v)
+	}
+}
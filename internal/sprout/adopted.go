@@ -0,0 +1,170 @@
+package sprout
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AdoptedStore records worktrees created outside sprout that sprout should
+// manage anyway. They are treated like worktrees under the sprout root.
+type AdoptedStore struct {
+	Version   int               `json:"version"`
+	Worktrees []AdoptedWorktree `json:"worktrees"`
+}
+
+// AdoptedWorktree is a single adopted worktree.
+type AdoptedWorktree struct {
+	Path      string    `json:"path"`
+	RepoRoot  string    `json:"repo_root"` // main worktree path of the owning repository
+	AdoptedAt time.Time `json:"adopted_at"`
+}
+
+// GetAdoptedStorePath returns the path to the adopted worktrees store.
+func GetAdoptedStorePath() (string, error) {
+	sproutRoot, err := GetSproutRoot()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(sproutRoot, "adopted.json"), nil
+}
+
+// LoadAdopted returns the paths of all adopted worktrees.
+func LoadAdopted() ([]string, error) {
+	store, err := loadAdoptedStore()
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(store.Worktrees))
+	for _, wt := range store.Worktrees {
+		paths = append(paths, wt.Path)
+	}
+	return paths, nil
+}
+
+// AdoptWorktree records a worktree as sprout-managed. Adopting twice is a no-op.
+func AdoptWorktree(repoRoot, path string) error {
+	unlock, err := lockAdoptedStore()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	store, err := loadAdoptedStore()
+	if err != nil {
+		return err
+	}
+	for _, wt := range store.Worktrees {
+		if wt.Path == path {
+			return nil
+		}
+	}
+	store.Worktrees = append(store.Worktrees, AdoptedWorktree{
+		Path:      path,
+		RepoRoot:  repoRoot,
+		AdoptedAt: time.Now(),
+	})
+	return saveAdoptedStore(store)
+}
+
+// ForgetWorktree removes a worktree from the adopted store, if present.
+func ForgetWorktree(path string) error {
+	unlock, err := lockAdoptedStore()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	store, err := loadAdoptedStore()
+	if err != nil {
+		return err
+	}
+	filtered := []AdoptedWorktree{}
+	for _, wt := range store.Worktrees {
+		if wt.Path != path {
+			filtered = append(filtered, wt)
+		}
+	}
+	if len(filtered) == len(store.Worktrees) {
+		return nil
+	}
+	store.Worktrees = filtered
+	return saveAdoptedStore(store)
+}
+
+func lockAdoptedStore() (unlock func(), err error) {
+	storePath, err := GetAdoptedStorePath()
+	if err != nil {
+		return nil, err
+	}
+	return lockStore(storePath)
+}
+
+func loadAdoptedStore() (*AdoptedStore, error) {
+	storePath, err := GetAdoptedStorePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(storePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &AdoptedStore{Version: 1, Worktrees: []AdoptedWorktree{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read adopted worktrees: %w", err)
+	}
+
+	var store AdoptedStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", storePath, err)
+	}
+	return &store, nil
+}
+
+func saveAdoptedStore(store *AdoptedStore) error {
+	storePath, err := GetAdoptedStorePath()
+	if err != nil {
+		return err
+	}
+	return writeStore(storePath, store, "adopted worktrees")
+}
+
+// writeStore writes a JSON metadata store via a temp file and rename so a
+// crash never truncates it. What names the store in error messages. The
+// temp file has a name of its own, so two processes never write to the
+// same one; updates still need lockStore to not lose each other's changes.
+func writeStore(storePath string, store any, what string) error {
+	if err := os.MkdirAll(filepath.Dir(storePath), 0755); err != nil {
+		return fmt.Errorf("failed to create sprout directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", what, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(storePath), "."+filepath.Base(storePath)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", what, err)
+	}
+	tmpPath := tmp.Name()
+	// Best-effort cleanup; after a successful rename the temp path no longer exists
+	defer os.Remove(tmpPath)
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, storePath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", what, err)
+	}
+	return nil
+}
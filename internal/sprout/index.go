@@ -0,0 +1,133 @@
+package sprout
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Port allocation: each worktree gets a block of PortStride ports starting at
+// PortBaseStart + index*PortStride. The main worktree has index 0, so dev
+// servers in the original checkout keep their usual ports.
+const (
+	PortBaseStart = 3000
+	PortStride    = 10
+)
+
+// IndexStore records the stable integer assigned to each worktree.
+type IndexStore struct {
+	Version   int               `json:"version"`
+	Worktrees []IndexedWorktree `json:"worktrees"`
+}
+
+// IndexedWorktree is a single worktree index assignment.
+type IndexedWorktree struct {
+	Path     string `json:"path"`
+	RepoRoot string `json:"repo_root"` // main worktree path of the owning repository
+	Index    int    `json:"index"`
+}
+
+// GetIndexStorePath returns the path to the worktree index store.
+func GetIndexStorePath() (string, error) {
+	return storePath(GetStateDir, "indices.json")
+}
+
+// PortBase returns the first port reserved for the worktree with the given index.
+func PortBase(index int) int {
+	return PortBaseStart + index*PortStride
+}
+
+// WorktreeIndex returns the index assigned to a worktree, allocating the
+// lowest free index (starting at 1) for its repository on first use. The
+// main worktree is always 0 and is never persisted.
+//
+// Assignments for worktrees that no longer exist on disk are dropped, so
+// their indices are reused by the next worktree. The store stays locked
+// while an index is picked, so two worktrees never get the same one.
+func WorktreeIndex(repoRoot, path string) (int, error) {
+	if path == repoRoot {
+		return 0, nil
+	}
+
+	storePath, err := GetIndexStorePath()
+	if err != nil {
+		return 0, err
+	}
+	unlock, err := lockStore(storePath)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	store, err := loadIndexStore()
+	if err != nil {
+		return 0, err
+	}
+
+	live := []IndexedWorktree{}
+	for _, wt := range store.Worktrees {
+		if _, err := os.Stat(wt.Path); err == nil {
+			live = append(live, wt)
+		}
+	}
+	pruned := len(live) != len(store.Worktrees)
+	store.Worktrees = live
+
+	used := map[int]bool{}
+	for _, wt := range store.Worktrees {
+		if wt.Path == path {
+			if pruned {
+				if err := saveIndexStore(store); err != nil {
+					return 0, err
+				}
+			}
+			return wt.Index, nil
+		}
+		if wt.RepoRoot == repoRoot {
+			used[wt.Index] = true
+		}
+	}
+
+	index := 1
+	for used[index] {
+		index++
+	}
+	store.Worktrees = append(store.Worktrees, IndexedWorktree{
+		Path:     path,
+		RepoRoot: repoRoot,
+		Index:    index,
+	})
+	if err := saveIndexStore(store); err != nil {
+		return 0, err
+	}
+	return index, nil
+}
+
+func loadIndexStore() (*IndexStore, error) {
+	storePath, err := GetIndexStorePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(storePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &IndexStore{Version: 1, Worktrees: []IndexedWorktree{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read worktree indices: %w", err)
+	}
+
+	var store IndexStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", storePath, err)
+	}
+	return &store, nil
+}
+
+func saveIndexStore(store *IndexStore) error {
+	storePath, err := GetIndexStorePath()
+	if err != nil {
+		return err
+	}
+	return writeStore(storePath, store, "worktree indices")
+}
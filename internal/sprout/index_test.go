@@ -0,0 +1,48 @@
+package sprout
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorktreeIndex_Concurrent(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+
+	paths := make([]string, 10)
+	for i := range paths {
+		paths[i] = filepath.Join(dir, string(rune('a'+i)))
+		require.NoError(t, os.Mkdir(paths[i], 0755))
+	}
+
+	indices := make([]int, len(paths))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			indices[i], err = WorktreeIndex("/repo", path)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.ElementsMatch(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, indices, "every worktree gets an index of its own")
+
+	storePath, err := GetIndexStorePath()
+	require.NoError(t, err)
+	entries, err := os.ReadDir(filepath.Dir(storePath))
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.ElementsMatch(t, []string{"indices.json", "indices.json.lock"}, names, "no temp files are left behind")
+}
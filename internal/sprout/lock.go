@@ -0,0 +1,105 @@
+package sprout
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrRepoLocked is wrapped by the error of LockRepo when another sprout
+// process kept the lock until the timeout.
+var ErrRepoLocked = errors.New("another sprout operation is running")
+
+// lockRetryInterval is how often LockRepo tries again while the lock is held.
+const lockRetryInterval = 100 * time.Millisecond
+
+// GetRepoLockPath returns the lock file that guards a repository's
+// worktrees, in the state directory. It is named after the worktree root,
+// so clones sharing a root under remote identity share the lock too.
+func GetRepoLockPath(repoPath string) (string, error) {
+	root, err := GetWorktreeRoot(repoPath)
+	if err != nil {
+		return "", err
+	}
+	stateDir, err := GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "locks", filepath.Base(root)+".lock"), nil
+}
+
+// LockRepo takes the lock of a repository's worktrees, waiting up to
+// timeout for another sprout process to release it. unlock releases it.
+//
+// The lock is an advisory file lock, which the OS drops when its process
+// exits, so a sprout that crashed or was killed never leaves a stale lock
+// behind. The file only records who holds it, for the error of the next
+// process that has to wait.
+func LockRepo(repoPath string, timeout time.Duration) (unlock func(), err error) {
+	path, err := GetRepoLockPath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create sprout directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			holder, _ := os.ReadFile(path)
+			f.Close()
+			if holder := strings.TrimSpace(string(holder)); holder != "" {
+				return nil, fmt.Errorf("%w on this repository (%s); try again when it is done", ErrRepoLocked, holder)
+			}
+			return nil, fmt.Errorf("%w on this repository; try again when it is done", ErrRepoLocked)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+
+	// Best-effort: the holder is only shown to processes that wait
+	if err := f.Truncate(0); err == nil {
+		_, _ = fmt.Fprintf(f, "pid %d: %s\n", os.Getpid(), strings.Join(append([]string{"sprout"}, os.Args[1:]...), " "))
+	}
+	return func() {
+		_ = f.Truncate(0)
+		_ = unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// lockStore takes the lock guarding a read-modify-write of the JSON store
+// at storePath, the file storePath+".lock" next to it, waiting for another
+// sprout process to finish its update. unlock releases it.
+func lockStore(storePath string) (unlock func(), err error) {
+	if err := os.MkdirAll(filepath.Dir(storePath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create sprout directory: %w", err)
+	}
+	f, err := os.OpenFile(storePath+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", storePath, err)
+	}
+	return func() {
+		_ = unlockFile(f)
+		f.Close()
+	}, nil
+}
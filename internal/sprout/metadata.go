@@ -0,0 +1,191 @@
+package sprout
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MetadataStore records facts about worktrees that git doesn't keep, such
+// as the ref a worktree's branch was created from.
+type MetadataStore struct {
+	Version   int            `json:"version"`
+	Worktrees []WorktreeMeta `json:"worktrees"`
+}
+
+// WorktreeMeta is the metadata of a single worktree.
+type WorktreeMeta struct {
+	Path      string    `json:"path"`
+	Base      string    `json:"base,omitempty"` // Ref the branch was created from, e.g. origin/main
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	Pinned    bool      `json:"pinned,omitempty"` // Sorted first and never removed by --all-merged or --evict
+	Note      string    `json:"note,omitempty"`   // Why the worktree exists, from 'sprout note'
+	// ExpiresAt makes the worktree temporary ('sprout add --ttl'): once it
+	// has passed, 'sprout prune' removes the worktree if it is clean
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+	// Hooks is the last run of each hook type, keyed by type ("on_create")
+	Hooks map[string]HookRun `json:"hooks,omitempty"`
+}
+
+// HookRun is the outcome of running a worktree's hooks of one type. A
+// failing command ends the run, so the ones after it are missing.
+type HookRun struct {
+	At       time.Time        `json:"at"`
+	Commands []HookCommandRun `json:"commands"`
+}
+
+// HookCommandRun is how a single hook command went.
+type HookCommandRun struct {
+	Command  string        `json:"command"`
+	Duration time.Duration `json:"duration_ns"`
+	ExitCode int           `json:"exit_code"`
+}
+
+// GetMetadataStorePath returns the path to the worktree metadata store.
+func GetMetadataStorePath() (string, error) {
+	sproutRoot, err := GetSproutRoot()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(sproutRoot, "metadata.json"), nil
+}
+
+// LoadMetadata returns the recorded metadata keyed by worktree path.
+// Worktrees that no longer exist on disk are left out.
+func LoadMetadata() (map[string]WorktreeMeta, error) {
+	store, err := loadMetadataStore()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]WorktreeMeta, len(store.Worktrees))
+	for _, wt := range store.Worktrees {
+		if _, err := os.Stat(wt.Path); err == nil {
+			result[wt.Path] = wt
+		}
+	}
+	return result, nil
+}
+
+// UpdateMetadata applies update to the metadata of the worktree at path,
+// creating its entry if needed. Entries of worktrees that no longer exist
+// are dropped on the way. The store stays locked from load to save, so
+// concurrent sprout processes never lose each other's updates.
+func UpdateMetadata(path string, update func(*WorktreeMeta)) error {
+	return rewriteMetadata(path, func(meta WorktreeMeta) []WorktreeMeta {
+		update(&meta)
+		return []WorktreeMeta{meta}
+	})
+}
+
+// ForgetMetadata drops the metadata of the worktree at path when it is
+// removed, so a worktree created there later doesn't inherit its pin,
+// note or expiry.
+func ForgetMetadata(path string) error {
+	return rewriteMetadata(path, func(WorktreeMeta) []WorktreeMeta { return nil })
+}
+
+// rewriteMetadata replaces the entry of the worktree at path (a new one if
+// it has none) with what replace returns, dropping the entries of
+// worktrees that no longer exist, with the store locked from load to save.
+func rewriteMetadata(path string, replace func(WorktreeMeta) []WorktreeMeta) error {
+	storePath, err := GetMetadataStorePath()
+	if err != nil {
+		return err
+	}
+	unlock, err := lockStore(storePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	store, err := loadMetadataStore()
+	if err != nil {
+		return err
+	}
+	meta := WorktreeMeta{Path: path}
+	kept := []WorktreeMeta{}
+	for _, wt := range store.Worktrees {
+		if wt.Path == path {
+			meta = wt
+			continue
+		}
+		if _, err := os.Stat(wt.Path); err == nil {
+			kept = append(kept, wt)
+		}
+	}
+	store.Worktrees = append(kept, replace(meta)...)
+	return saveMetadataStore(store)
+}
+
+// RecordBase records the ref a worktree's branch was created from.
+func RecordBase(path, base string) error {
+	return UpdateMetadata(path, func(meta *WorktreeMeta) {
+		meta.Base = base
+		if meta.CreatedAt.IsZero() {
+			meta.CreatedAt = time.Now()
+		}
+	})
+}
+
+// SetPinned pins or unpins a worktree.
+func SetPinned(path string, pinned bool) error {
+	return UpdateMetadata(path, func(meta *WorktreeMeta) {
+		meta.Pinned = pinned
+	})
+}
+
+// SetNote sets the note of a worktree; an empty note removes it.
+func SetNote(path, note string) error {
+	return UpdateMetadata(path, func(meta *WorktreeMeta) {
+		meta.Note = note
+	})
+}
+
+// SetExpiry sets when a temporary worktree expires; the zero time makes it
+// permanent again.
+func SetExpiry(path string, expiresAt time.Time) error {
+	return UpdateMetadata(path, func(meta *WorktreeMeta) {
+		meta.ExpiresAt = expiresAt
+	})
+}
+
+// RecordHookRun records the last run of a worktree's hooks of hookType.
+func RecordHookRun(path, hookType string, run HookRun) error {
+	return UpdateMetadata(path, func(meta *WorktreeMeta) {
+		if meta.Hooks == nil {
+			meta.Hooks = make(map[string]HookRun)
+		}
+		meta.Hooks[hookType] = run
+	})
+}
+
+func loadMetadataStore() (*MetadataStore, error) {
+	storePath, err := GetMetadataStorePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(storePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &MetadataStore{Version: 1, Worktrees: []WorktreeMeta{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read worktree metadata: %w", err)
+	}
+
+	var store MetadataStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", storePath, err)
+	}
+	return &store, nil
+}
+
+func saveMetadataStore(store *MetadataStore) error {
+	storePath, err := GetMetadataStorePath()
+	if err != nil {
+		return err
+	}
+	return writeStore(storePath, store, "worktree metadata")
+}
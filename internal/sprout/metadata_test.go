@@ -0,0 +1,53 @@
+package sprout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateMetadata_WaitsForStoreLock(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	path := t.TempDir()
+	storePath, err := GetMetadataStorePath()
+	require.NoError(t, err)
+
+	unlock, err := lockStore(storePath)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- SetNote(path, "wip") }()
+	select {
+	case <-done:
+		t.Fatal("SetNote updated the store while another update held its lock")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	unlock()
+	require.NoError(t, <-done)
+	metadata, err := LoadMetadata()
+	require.NoError(t, err)
+	assert.Equal(t, "wip", metadata[path].Note)
+}
+
+func TestForgetMetadata(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	removed, kept := t.TempDir(), t.TempDir()
+	require.NoError(t, SetPinned(removed, true))
+	require.NoError(t, SetExpiry(removed, time.Now().Add(time.Hour)))
+	require.NoError(t, SetNote(kept, "wip"))
+
+	require.NoError(t, ForgetMetadata(removed))
+
+	metadata, err := LoadMetadata()
+	require.NoError(t, err)
+	assert.NotContains(t, metadata, removed, "a worktree created there later starts afresh")
+	assert.Equal(t, "wip", metadata[kept].Note)
+
+	require.NoError(t, SetNote(removed, "new"))
+	metadata, err = LoadMetadata()
+	require.NoError(t, err)
+	assert.Equal(t, WorktreeMeta{Path: removed, Note: "new"}, metadata[removed])
+}
@@ -2,17 +2,59 @@ package sprout
 
 import (
 	"crypto/sha1"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/git"
 )
 
-// GetSproutRoot returns the root directory for sprout worktrees.
+// sprout keeps its files where the XDG base directory spec says: the config
+// directory (see config.GetUserConfigDir) for what users write, the data
+// directory for worktrees and what sprout records about them, the state
+// directory for what it needs from one run to the next but nobody would
+// back up, and the cache directory for what it can rebuild.
+
+// GetSproutRoot returns the root directory for sprout worktrees, which is
+// sprout's data directory.
 // Uses $XDG_DATA_HOME/sprout if XDG_DATA_HOME is set, otherwise ~/.local/share/sprout
 func GetSproutRoot() (string, error) {
-	if xdgData := os.Getenv("XDG_DATA_HOME"); xdgData != "" {
-		return filepath.Join(xdgData, "sprout"), nil
+	return xdgDir("XDG_DATA_HOME", ".local", "share")
+}
+
+// GetStateDir returns the directory of debug logs, timing samples,
+// telemetry, worktree indices and repository locks.
+// Uses $XDG_STATE_HOME/sprout if XDG_STATE_HOME is set, otherwise ~/.local/state/sprout
+func GetStateDir() (string, error) {
+	return xdgDir("XDG_STATE_HOME", ".local", "state")
+}
+
+// GetCacheDir returns the directory of the repository index and the
+// worktree status cache.
+// Uses $XDG_CACHE_HOME/sprout if XDG_CACHE_HOME is set, otherwise ~/.cache/sprout
+func GetCacheDir() (string, error) {
+	return xdgDir("XDG_CACHE_HOME", ".cache")
+}
+
+// GetLogPath returns the debug log written with SPROUT_DEBUG=1.
+func GetLogPath() (string, error) {
+	stateDir, err := GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "sprout.log"), nil
+}
+
+// xdgDir returns the sprout directory under $env, or under the home
+// directory's fallback path when env is unset.
+func xdgDir(env string, fallback ...string) (string, error) {
+	if dir := os.Getenv(env); dir != "" {
+		return filepath.Join(dir, "sprout"), nil
 	}
 
 	home, err := os.UserHomeDir()
@@ -20,26 +62,75 @@ func GetSproutRoot() (string, error) {
 		return "", fmt.Errorf("failed to get user home directory: %w", err)
 	}
 
-	return filepath.Join(home, ".local", "share", "sprout"), nil
+	return filepath.Join(append(append([]string{home}, fallback...), "sprout")...), nil
+}
+
+// storePath returns the path of the store file name in dir. Older versions
+// kept every store in the sprout root; one found there is moved to dir.
+func storePath(dir func() (string, error), name string) (string, error) {
+	storeDir, err := dir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(storeDir, name)
+
+	sproutRoot, err := GetSproutRoot()
+	if err != nil || sproutRoot == storeDir {
+		return path, nil
+	}
+	old := filepath.Join(sproutRoot, name)
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		if _, err := os.Stat(old); err == nil && os.MkdirAll(storeDir, 0755) == nil {
+			_ = os.Rename(old, path)
+		}
+	}
+	return path, nil
 }
 
-// GetRepoID computes a stable identifier for a repository based on its absolute path.
-// It returns the first 8 characters of the SHA1 hash of the path.
-func GetRepoID(repoPath string) string {
-	hash := sha1.Sum([]byte(repoPath))
+// GetRepoID computes a stable identifier for a repository from its identity key
+// (its absolute path, or its normalized remote URL under remote identity).
+// It returns the first 8 characters of the SHA1 hash of the key.
+func GetRepoID(key string) string {
+	hash := sha1.Sum([]byte(key))
 	return fmt.Sprintf("%x", hash)[:8]
 }
 
 // GetWorktreeRoot returns the root directory for worktrees of a specific repository.
 // Format: ~/.sprout/<repo-slug>-<repo-id>
+//
+// With repo_identity: remote in the user config, the slug and ID are derived
+// from the normalized origin URL so the directory survives moving the checkout.
+// Repositories without an origin fall back to path-based identity.
 func GetWorktreeRoot(repoPath string) (string, error) {
+	cfg, err := config.LoadUser()
+	if err != nil {
+		return "", err
+	}
+
+	if cfg.UsesRemoteIdentity() {
+		if remoteURL, err := git.GetRemoteURL(repoPath, "origin"); err == nil {
+			if remote := git.NormalizeRemoteURL(remoteURL); remote != "" {
+				return getWorktreeRoot(path.Base(remote), GetRepoID(remote))
+			}
+		}
+	}
+
+	return GetPathWorktreeRoot(repoPath)
+}
+
+// GetPathWorktreeRoot returns the worktree root keyed by the repository's
+// absolute path, regardless of the configured identity mode. This is where
+// worktrees created before switching to remote identity live.
+func GetPathWorktreeRoot(repoPath string) (string, error) {
+	return getWorktreeRoot(filepath.Base(repoPath), GetRepoID(repoPath))
+}
+
+func getWorktreeRoot(repoSlug, repoID string) (string, error) {
 	sproutRoot, err := GetSproutRoot()
 	if err != nil {
 		return "", err
 	}
 
-	repoSlug := filepath.Base(repoPath)
-	repoID := GetRepoID(repoPath)
 	return filepath.Join(sproutRoot, fmt.Sprintf("%s-%s", repoSlug, repoID)), nil
 }
 
@@ -60,6 +151,19 @@ func GetWorktreePath(repoPath, branch string) (string, error) {
 	return filepath.Join(root, branch, repoSlug), nil
 }
 
+// ScratchDirName is the directory under the sprout root that holds the
+// scratch directories of all worktrees.
+const ScratchDirName = "scratch"
+
+// ScratchDir returns the scratch directory of a worktree, for build caches
+// and logs that don't belong in the working tree. It is outside the
+// worktree, so git never sees it, and is exported to hooks as
+// $SPROUT_SCRATCH.
+// Format: <sprout root>/scratch/<id of the worktree path>
+func ScratchDir(sproutRoot, worktreePath string) string {
+	return filepath.Join(sproutRoot, ScratchDirName, GetRepoID(filepath.Clean(worktreePath)))
+}
+
 // validateBranchName checks if a branch name contains dangerous path components
 // that could allow escaping the sprout root directory.
 func validateBranchName(branch string) error {
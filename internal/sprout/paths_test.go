@@ -0,0 +1,15 @@
+package sprout
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLogPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/state")
+	path, err := GetLogPath()
+	require.NoError(t, err)
+	assert.Equal(t, "/state/sprout/sprout.log", path)
+}
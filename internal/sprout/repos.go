@@ -0,0 +1,84 @@
+package sprout
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// RepoIndexStore maps the repository directories under the sprout root to
+// the main worktrees of their repositories, so finding every repository
+// doesn't take scanning each directory for a worktree and asking git.
+type RepoIndexStore struct {
+	Version int               `json:"version"`
+	Repos   map[string]string `json:"repos"` // repository directory -> main worktree path
+}
+
+// GetRepoIndexStorePath returns the path to the repository index store.
+func GetRepoIndexStorePath() (string, error) {
+	return storePath(GetCacheDir, "repos.json")
+}
+
+// LoadRepoIndex returns the indexed main worktree paths, keyed by
+// repository directory. Without an index it is empty.
+func LoadRepoIndex() (map[string]string, error) {
+	store, err := loadRepoIndexStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.Repos, nil
+}
+
+// SaveRepoIndex replaces the repository index.
+func SaveRepoIndex(repos map[string]string) error {
+	if repos == nil {
+		repos = map[string]string{}
+	}
+	return saveRepoIndexStore(&RepoIndexStore{Version: 1, Repos: repos})
+}
+
+// IndexRepo records the main worktree of the repository whose worktrees
+// live in repoDir. Indexing it again is a no-op.
+func IndexRepo(repoDir, mainPath string) error {
+	store, err := loadRepoIndexStore()
+	if err != nil {
+		return err
+	}
+	if store.Repos[repoDir] == mainPath {
+		return nil
+	}
+	store.Repos[repoDir] = mainPath
+	return saveRepoIndexStore(store)
+}
+
+func loadRepoIndexStore() (*RepoIndexStore, error) {
+	storePath, err := GetRepoIndexStorePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(storePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &RepoIndexStore{Version: 1, Repos: map[string]string{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read repository index: %w", err)
+	}
+
+	var store RepoIndexStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", storePath, err)
+	}
+	if store.Repos == nil {
+		store.Repos = map[string]string{}
+	}
+	return &store, nil
+}
+
+func saveRepoIndexStore(store *RepoIndexStore) error {
+	storePath, err := GetRepoIndexStorePath()
+	if err != nil {
+		return err
+	}
+	return writeStore(storePath, store, "repository index")
+}
@@ -0,0 +1,122 @@
+package sprout
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/m44rten1/sprout/internal/git"
+)
+
+// StatusStore caches the last known status of each worktree, so that
+// 'sprout prompt' can describe a worktree without running git.
+type StatusStore struct {
+	Version   int            `json:"version"`
+	Worktrees []CachedStatus `json:"worktrees"`
+}
+
+// CachedStatus is a worktree's status as of UpdatedAt.
+type CachedStatus struct {
+	Path          string    `json:"path"`
+	Dirty         bool      `json:"dirty"`
+	UntrackedOnly bool      `json:"untracked_only"`
+	Ahead         int       `json:"ahead"`
+	Behind        int       `json:"behind"`
+	Unmerged      bool      `json:"unmerged"`
+	Stash         bool      `json:"stash"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Status returns the cached status as a git.WorktreeStatus.
+func (c CachedStatus) Status() git.WorktreeStatus {
+	return git.WorktreeStatus{
+		Dirty:         c.Dirty,
+		UntrackedOnly: c.UntrackedOnly,
+		Ahead:         c.Ahead,
+		Behind:        c.Behind,
+		Unmerged:      c.Unmerged,
+		HasStash:      c.Stash,
+	}
+}
+
+// GetStatusStorePath returns the path to the worktree status cache.
+func GetStatusStorePath() (string, error) {
+	return storePath(GetCacheDir, "statuses.json")
+}
+
+// LoadCachedStatus returns the cached status of one worktree. It only reads
+// the cache file, so it is cheap enough to run on every shell prompt.
+func LoadCachedStatus(path string) (CachedStatus, bool, error) {
+	store, err := loadStatusStore()
+	if err != nil {
+		return CachedStatus{}, false, err
+	}
+	for _, wt := range store.Worktrees {
+		if wt.Path == path {
+			return wt, true, nil
+		}
+	}
+	return CachedStatus{}, false, nil
+}
+
+// CacheStatuses records freshly computed statuses, keyed by worktree path,
+// replacing older entries. Entries of worktrees that no longer exist are dropped.
+func CacheStatuses(statuses map[string]git.WorktreeStatus, updatedAt time.Time) error {
+	store, err := loadStatusStore()
+	if err != nil {
+		return err
+	}
+	kept := []CachedStatus{}
+	for _, wt := range store.Worktrees {
+		if _, replaced := statuses[wt.Path]; replaced {
+			continue
+		}
+		if _, err := os.Stat(wt.Path); err == nil {
+			kept = append(kept, wt)
+		}
+	}
+	for path, status := range statuses {
+		kept = append(kept, CachedStatus{
+			Path:          path,
+			Dirty:         status.Dirty,
+			UntrackedOnly: status.UntrackedOnly,
+			Ahead:         status.Ahead,
+			Behind:        status.Behind,
+			Unmerged:      status.Unmerged,
+			Stash:         status.HasStash,
+			UpdatedAt:     updatedAt,
+		})
+	}
+	store.Worktrees = kept
+	return saveStatusStore(store)
+}
+
+func loadStatusStore() (*StatusStore, error) {
+	storePath, err := GetStatusStorePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(storePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &StatusStore{Version: 1, Worktrees: []CachedStatus{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read status cache: %w", err)
+	}
+
+	var store StatusStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", storePath, err)
+	}
+	return &store, nil
+}
+
+func saveStatusStore(store *StatusStore) error {
+	storePath, err := GetStatusStorePath()
+	if err != nil {
+		return err
+	}
+	return writeStore(storePath, store, "status cache")
+}
@@ -0,0 +1,46 @@
+package sprout
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/m44rten1/sprout/internal/telemetry"
+)
+
+// GetTelemetryStorePath returns the path to the telemetry setting and the
+// usage aggregated for the next report.
+func GetTelemetryStorePath() (string, error) {
+	return storePath(GetStateDir, "telemetry.json")
+}
+
+// LoadTelemetry returns the telemetry state. Without a store telemetry is off.
+func LoadTelemetry() (*telemetry.State, error) {
+	storePath, err := GetTelemetryStorePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(storePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &telemetry.State{Version: 1}, nil
+		}
+		return nil, fmt.Errorf("failed to read telemetry state: %w", err)
+	}
+
+	var state telemetry.State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", storePath, err)
+	}
+	return &state, nil
+}
+
+// SaveTelemetry writes the telemetry state.
+func SaveTelemetry(state *telemetry.State) error {
+	storePath, err := GetTelemetryStorePath()
+	if err != nil {
+		return err
+	}
+	return writeStore(storePath, state, "telemetry state")
+}
@@ -0,0 +1,97 @@
+package sprout
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/m44rten1/sprout/internal/tickets"
+)
+
+// TicketStore records which ticket each worktree was created for
+// ('sprout add --ticket'), so list and info can show it without a lookup.
+type TicketStore struct {
+	Version   int              `json:"version"`
+	Worktrees []TicketWorktree `json:"worktrees"`
+}
+
+// TicketWorktree links a worktree to a ticket.
+type TicketWorktree struct {
+	Path   string         `json:"path"`
+	Ticket tickets.Ticket `json:"ticket"`
+}
+
+// GetTicketStorePath returns the path to the worktree ticket store.
+func GetTicketStorePath() (string, error) {
+	sproutRoot, err := GetSproutRoot()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(sproutRoot, "tickets.json"), nil
+}
+
+// LoadTickets returns the recorded tickets keyed by worktree path.
+// Worktrees that no longer exist on disk are left out.
+func LoadTickets() (map[string]tickets.Ticket, error) {
+	store, err := loadTicketStore()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]tickets.Ticket, len(store.Worktrees))
+	for _, wt := range store.Worktrees {
+		if _, err := os.Stat(wt.Path); err == nil {
+			result[wt.Path] = wt.Ticket
+		}
+	}
+	return result, nil
+}
+
+// RecordTicket links a worktree to a ticket, replacing any earlier link.
+// Links of worktrees that no longer exist are dropped on the way.
+func RecordTicket(path string, ticket tickets.Ticket) error {
+	store, err := loadTicketStore()
+	if err != nil {
+		return err
+	}
+	kept := []TicketWorktree{}
+	for _, wt := range store.Worktrees {
+		if wt.Path == path {
+			continue
+		}
+		if _, err := os.Stat(wt.Path); err == nil {
+			kept = append(kept, wt)
+		}
+	}
+	store.Worktrees = append(kept, TicketWorktree{Path: path, Ticket: ticket})
+	return saveTicketStore(store)
+}
+
+func loadTicketStore() (*TicketStore, error) {
+	storePath, err := GetTicketStorePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(storePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &TicketStore{Version: 1, Worktrees: []TicketWorktree{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read worktree tickets: %w", err)
+	}
+
+	var store TicketStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", storePath, err)
+	}
+	return &store, nil
+}
+
+func saveTicketStore(store *TicketStore) error {
+	storePath, err := GetTicketStorePath()
+	if err != nil {
+		return err
+	}
+	return writeStore(storePath, store, "worktree tickets")
+}
@@ -0,0 +1,74 @@
+package sprout
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/m44rten1/sprout/internal/timing"
+)
+
+// MaxTimings is how many timing samples are kept; older ones are dropped.
+const MaxTimings = 1000
+
+// TimingStore keeps recent durations of commands, actions, hooks and status
+// checks for 'sprout stats'. It never leaves the machine.
+type TimingStore struct {
+	Version int             `json:"version"`
+	Samples []timing.Sample `json:"samples"`
+}
+
+// GetTimingStorePath returns the path to the timing samples file.
+func GetTimingStorePath() (string, error) {
+	return storePath(GetStateDir, "timings.json")
+}
+
+// LoadTimings returns the stored samples, oldest first.
+func LoadTimings() ([]timing.Sample, error) {
+	store, err := loadTimingStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.Samples, nil
+}
+
+// AppendTimings stores samples, keeping only the newest MaxTimings.
+func AppendTimings(samples []timing.Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	store, err := loadTimingStore()
+	if err != nil {
+		return err
+	}
+	store.Samples = append(store.Samples, samples...)
+	if extra := len(store.Samples) - MaxTimings; extra > 0 {
+		store.Samples = store.Samples[extra:]
+	}
+	storePath, err := GetTimingStorePath()
+	if err != nil {
+		return err
+	}
+	return writeStore(storePath, store, "timings")
+}
+
+func loadTimingStore() (*TimingStore, error) {
+	storePath, err := GetTimingStorePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(storePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &TimingStore{Version: 1, Samples: []timing.Sample{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read timings: %w", err)
+	}
+
+	var store TimingStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", storePath, err)
+	}
+	return &store, nil
+}
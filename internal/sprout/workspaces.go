@@ -0,0 +1,110 @@
+package sprout
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// WorkspaceStore records the workspaces made with 'sprout ws create': sets
+// of repositories that work on a feature together, each in a worktree of
+// the same branch.
+type WorkspaceStore struct {
+	Version    int         `json:"version"`
+	Workspaces []Workspace `json:"workspaces"`
+}
+
+// Workspace is a named set of repositories. Its name is also the branch of
+// the worktrees its members have for it.
+type Workspace struct {
+	Name  string   `json:"name"`
+	Repos []string `json:"repos"` // Main worktree paths of the member repositories
+}
+
+// GetWorkspaceStorePath returns the path to the workspace store.
+func GetWorkspaceStorePath() (string, error) {
+	sproutRoot, err := GetSproutRoot()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(sproutRoot, "workspaces.json"), nil
+}
+
+// LoadWorkspaces returns the recorded workspaces, sorted by name.
+func LoadWorkspaces() ([]Workspace, error) {
+	store, err := loadWorkspaceStore()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(store.Workspaces, func(a, b Workspace) int { return strings.Compare(a.Name, b.Name) })
+	return store.Workspaces, nil
+}
+
+// SaveWorkspace records the workspace called name with repos as its
+// members, replacing any earlier workspace of that name.
+func SaveWorkspace(name string, repos []string) error {
+	store, err := loadWorkspaceStore()
+	if err != nil {
+		return err
+	}
+	kept := []Workspace{}
+	for _, ws := range store.Workspaces {
+		if ws.Name != name {
+			kept = append(kept, ws)
+		}
+	}
+	store.Workspaces = append(kept, Workspace{Name: name, Repos: repos})
+	return saveWorkspaceStore(store)
+}
+
+// DeleteWorkspace forgets the workspace called name. Forgetting one that
+// isn't recorded is a no-op.
+func DeleteWorkspace(name string) error {
+	store, err := loadWorkspaceStore()
+	if err != nil {
+		return err
+	}
+	kept := []Workspace{}
+	for _, ws := range store.Workspaces {
+		if ws.Name != name {
+			kept = append(kept, ws)
+		}
+	}
+	if len(kept) == len(store.Workspaces) {
+		return nil
+	}
+	store.Workspaces = kept
+	return saveWorkspaceStore(store)
+}
+
+func loadWorkspaceStore() (*WorkspaceStore, error) {
+	storePath, err := GetWorkspaceStorePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(storePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &WorkspaceStore{Version: 1, Workspaces: []Workspace{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read workspaces: %w", err)
+	}
+
+	var store WorkspaceStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", storePath, err)
+	}
+	return &store, nil
+}
+
+func saveWorkspaceStore(store *WorkspaceStore) error {
+	storePath, err := GetWorkspaceStorePath()
+	if err != nil {
+		return err
+	}
+	return writeStore(storePath, store, "workspaces")
+}
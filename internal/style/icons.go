@@ -0,0 +1,99 @@
+package style
+
+import "sync/atomic"
+
+// Icons are the glyphs that mark worktrees and their status in list, the
+// picker, completions and the prompt. Each may be replaced in the user
+// config (icons: {dirty: "*", ...}).
+type Icons struct {
+	Sprout    string `yaml:"sprout"`    // Before sprout worktrees
+	Pinned    string `yaml:"pinned"`    // Before pinned worktrees, instead of Sprout
+	Note      string `yaml:"note"`      // Before notes
+	Dirty     string `yaml:"dirty"`     // Modified or staged changes
+	Untracked string `yaml:"untracked"` // Only new, untracked files
+	Ahead     string `yaml:"ahead"`     // Unpushed commits
+	Behind    string `yaml:"behind"`    // Commits to pull
+	Unmerged  string `yaml:"unmerged"`  // Commits not in the base branch
+	Stash     string `yaml:"stash"`     // Stash entries made on the branch
+}
+
+// DefaultIcons are used unless the user config says otherwise.
+var DefaultIcons = Icons{
+	Sprout:    "🌱",
+	Pinned:    "📌",
+	Note:      "📝",
+	Dirty:     "✗",
+	Untracked: "?",
+	Ahead:     "↑",
+	Behind:    "↓",
+	Unmerged:  "↕",
+	Stash:     "≡",
+}
+
+// ASCIIIcons are for terminals and fonts without emoji or arrows
+// (ascii: true). They follow git's prompt where it has a glyph.
+var ASCIIIcons = Icons{
+	Sprout:    "-",
+	Pinned:    "^",
+	Note:      "#",
+	Dirty:     "*",
+	Untracked: "%",
+	Ahead:     ">",
+	Behind:    "<",
+	Unmerged:  "!",
+	Stash:     "$",
+}
+
+// Or returns i with every empty glyph taken from fallback.
+func (i Icons) Or(fallback Icons) Icons {
+	defaults := fallback.fields()
+	for n, f := range i.fields() {
+		if *f.icon == "" {
+			*f.icon = *defaults[n].icon
+		}
+	}
+	return i
+}
+
+// Each calls fn with the config key and glyph of every icon, in order.
+func (i Icons) Each(fn func(name, icon string)) {
+	for _, f := range i.fields() {
+		fn(f.name, *f.icon)
+	}
+}
+
+type iconField struct {
+	name string
+	icon *string
+}
+
+func (i *Icons) fields() []iconField {
+	return []iconField{
+		{"sprout", &i.Sprout},
+		{"pinned", &i.Pinned},
+		{"note", &i.Note},
+		{"dirty", &i.Dirty},
+		{"untracked", &i.Untracked},
+		{"ahead", &i.Ahead},
+		{"behind", &i.Behind},
+		{"unmerged", &i.Unmerged},
+		{"stash", &i.Stash},
+	}
+}
+
+var icons atomic.Pointer[Icons]
+
+// SetIcons replaces the icons for the rest of the process. Empty glyphs
+// keep their default.
+func SetIcons(i Icons) {
+	i = i.Or(DefaultIcons)
+	icons.Store(&i)
+}
+
+// CurrentIcons returns the icons in use.
+func CurrentIcons() Icons {
+	if i := icons.Load(); i != nil {
+		return *i
+	}
+	return DefaultIcons
+}
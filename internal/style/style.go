@@ -0,0 +1,89 @@
+// Package style colors terminal output. All of sprout's ANSI styling goes
+// through it, so colors can be turned off in one place: with --no-color,
+// with NO_COLOR set (https://no-color.org), or when output isn't a terminal.
+package style
+
+import (
+	"regexp"
+	"sync/atomic"
+)
+
+// ANSI escape codes
+const (
+	reset         = "\033[0m"
+	bold          = "\033[1m"
+	boldUnderline = "\033[1;4m"
+	red           = "\033[31m"
+	green         = "\033[32m"
+	yellow        = "\033[33m"
+	blue          = "\033[34m"
+	magenta       = "\033[35m"
+	cyan          = "\033[36m"
+	gray          = "\033[90m"
+)
+
+// disabled is inverted so colors are on until the command decides
+// otherwise, as in tests.
+var disabled atomic.Bool
+
+// SetEnabled turns colors on or off for the rest of the process.
+func SetEnabled(on bool) {
+	disabled.Store(!on)
+}
+
+// Enabled reports whether output is colored.
+func Enabled() bool {
+	return !disabled.Load()
+}
+
+// ShouldColor decides whether to color output: not with --no-color
+// (noColor), a non-empty NO_COLOR, TERM=dumb, or when output isn't a
+// terminal.
+func ShouldColor(noColor bool, getenv func(string) string, terminal bool) bool {
+	if noColor || getenv("NO_COLOR") != "" || getenv("TERM") == "dumb" {
+		return false
+	}
+	return terminal
+}
+
+func paint(s, code string) string {
+	if !Enabled() {
+		return s
+	}
+	return code + s + reset
+}
+
+// Red is for urgent states and errors, such as uncommitted changes.
+func Red(s string) string { return paint(s, red) }
+
+// Green is for branches and success.
+func Green(s string) string { return paint(s, green) }
+
+// Yellow is for warnings, such as unpushed commits.
+func Yellow(s string) string { return paint(s, yellow) }
+
+// Blue is for stashed work.
+func Blue(s string) string { return paint(s, blue) }
+
+// Magenta is for special states, such as unmerged commits.
+func Magenta(s string) string { return paint(s, magenta) }
+
+// Cyan is informational, such as commits to pull.
+func Cyan(s string) string { return paint(s, cyan) }
+
+// Gray is for secondary details, such as paths and step counters.
+func Gray(s string) string { return paint(s, gray) }
+
+// Bold is for names and headings.
+func Bold(s string) string { return paint(s, bold) }
+
+// Heading is for section headings, bold and underlined.
+func Heading(s string) string { return paint(s, boldUnderline) }
+
+var escape = regexp.MustCompile("\033\\[[0-9;]*m")
+
+// Strip removes ANSI styling from s, for text that was styled before
+// colors were turned off (such as help texts built at startup).
+func Strip(s string) string {
+	return escape.ReplaceAllString(s, "")
+}
@@ -0,0 +1,73 @@
+package style
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldColor(t *testing.T) {
+	t.Parallel()
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+
+	assert.True(t, ShouldColor(false, env(nil), true))
+	assert.False(t, ShouldColor(false, env(nil), false), "not a terminal")
+	assert.False(t, ShouldColor(true, env(nil), true), "--no-color")
+	assert.False(t, ShouldColor(false, env(map[string]string{"NO_COLOR": "1"}), true))
+	assert.True(t, ShouldColor(false, env(map[string]string{"NO_COLOR": ""}), true), "an empty NO_COLOR doesn't count")
+	assert.False(t, ShouldColor(false, env(map[string]string{"TERM": "dumb"}), true))
+}
+
+func TestPaint(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "\033[31m✗\033[0m", Red("✗"))
+	assert.Equal(t, "\033[1;4mwork\033[0m", Heading("work"))
+	assert.Equal(t, "✗ main", Strip(Red("✗")+" "+Green("main")))
+}
+
+func TestIconsOr(t *testing.T) {
+	t.Parallel()
+	icons := Icons{Dirty: "D", Sprout: "s"}.Or(ASCIIIcons)
+	assert.Equal(t, "D", icons.Dirty)
+	assert.Equal(t, "s", icons.Sprout)
+	assert.Equal(t, ASCIIIcons.Ahead, icons.Ahead)
+	assert.Equal(t, ASCIIIcons.Stash, icons.Stash)
+
+	var names []string
+	DefaultIcons.Each(func(name, icon string) {
+		assert.NotEmpty(t, icon, name)
+		names = append(names, name)
+	})
+	assert.Equal(t, []string{"sprout", "pinned", "note", "dirty", "untracked", "ahead", "behind", "unmerged", "stash"}, names)
+}
+
+func TestWidth(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, 4, Width("main"))
+	assert.Equal(t, 5, Width("héllo"), "é is one column")
+	assert.Equal(t, 5, Width("he\u0301llo"), "as is e with a combining accent")
+	assert.Equal(t, 4, Width("機能"), "wide characters take two columns")
+	assert.Equal(t, 2, Width("👩‍👩‍👧"), "an emoji sequence is one character")
+	assert.Equal(t, 4, Width(Green("main")), "styling takes no room")
+}
+
+func TestTruncate(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "héllo", Truncate("héllo", 5))
+	assert.Equal(t, "hé...", Truncate("héllo wörld", 5))
+	assert.Equal(t, "he\u0301...", Truncate("he\u0301llo wörld", 5), "the accent stays with its letter")
+	assert.Equal(t, "機...", Truncate("機能ブランチ", 6))
+	assert.Equal(t, "機...", Truncate("機能ブランチ", 5), "a wide character that doesn't fit is left out whole")
+	assert.Equal(t, "👩‍👩‍👧...", Truncate("👩‍👩‍👧👩‍👩‍👧👩‍👩‍👧", 5))
+	assert.Equal(t, "...", Truncate("héllo wörld", 2))
+	assert.Equal(t, "\033[32mma\033[0m...", Truncate(Green("main-branch"), 5), "styling is reset after the cut")
+}
+
+func TestPadRight(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "main  |", PadRight("main", 6)+"|")
+	assert.Equal(t, "機能  |", PadRight("機能", 6)+"|")
+	assert.Equal(t, "feature|", PadRight("feature", 3)+"|")
+}
@@ -0,0 +1,59 @@
+package style
+
+import (
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// Width returns how many terminal columns s takes up: wide characters
+// (CJK, most emoji) take two, combining marks none, and ANSI styling is
+// ignored.
+func Width(s string) int {
+	return uniseg.StringWidth(Strip(s))
+}
+
+// Truncate shortens s to at most width columns, ending it in "..." when
+// anything was cut. It never splits a character made of several runes
+// (such as an emoji with a skin tone or a letter with an accent), and
+// keeps ANSI styling whole, resetting it after the cut.
+func Truncate(s string, width int) string {
+	if Width(s) <= width {
+		return s
+	}
+	if width <= 3 {
+		return "..."
+	}
+
+	var b strings.Builder
+	used, styled := 0, false
+	for rest := s; rest != ""; {
+		if loc := escape.FindStringIndex(rest); loc != nil && loc[0] == 0 {
+			b.WriteString(rest[:loc[1]])
+			rest = rest[loc[1]:]
+			styled = true
+			continue
+		}
+		cluster, next, w, _ := uniseg.FirstGraphemeClusterInString(rest, -1)
+		if used+w > width-3 {
+			break
+		}
+		b.WriteString(cluster)
+		used += w
+		rest = next
+	}
+	if styled {
+		b.WriteString(reset)
+	}
+	b.WriteString("...")
+	return b.String()
+}
+
+// PadRight pads s with spaces to width columns, like %-*s does for
+// strings where every rune takes one column.
+func PadRight(s string, width int) string {
+	if pad := width - Width(s); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+	return s
+}
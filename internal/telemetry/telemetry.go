@@ -0,0 +1,119 @@
+// Package telemetry aggregates anonymous usage statistics: how often each
+// command runs, how long it takes and which kinds of errors it fails with.
+// It is off unless the user runs 'sprout telemetry on'. Nothing identifying
+// is collected: no paths, branch names, arguments or error messages.
+package telemetry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Endpoint receives reports. It is set at build time for release builds;
+// builds without one collect nothing to send and never connect anywhere.
+var Endpoint = ""
+
+// SendInterval is how long usage is aggregated before a report is sent.
+const SendInterval = 7 * 24 * time.Hour
+
+// MaxDurations is how many durations are kept per command for the report's
+// percentiles.
+const MaxDurations = 100
+
+// State is the telemetry setting and the usage aggregated since the last
+// report.
+type State struct {
+	Version     int               `json:"version"`
+	Enabled     bool              `json:"enabled"`
+	Since       time.Time         `json:"since"`        // Start of the current period
+	LastAttempt time.Time         `json:"last_attempt"` // Last time a report was sent, successfully or not
+	Commands    map[string]*Usage `json:"commands"`
+	Errors      map[string]int    `json:"errors"` // Error type -> count
+}
+
+// Usage is how one command was used during the period.
+type Usage struct {
+	Runs        int     `json:"runs"`
+	Failures    int     `json:"failures"`
+	DurationsMS []int64 `json:"durations_ms"` // The most recent MaxDurations
+}
+
+// Record adds one run of command, failing with errType unless it is empty.
+func (s *State) Record(command string, d time.Duration, errType string) {
+	if s.Commands == nil {
+		s.Commands = make(map[string]*Usage)
+	}
+	if s.Errors == nil {
+		s.Errors = make(map[string]int)
+	}
+	usage := s.Commands[command]
+	if usage == nil {
+		usage = &Usage{}
+		s.Commands[command] = usage
+	}
+	usage.Runs++
+	usage.DurationsMS = append(usage.DurationsMS, d.Milliseconds())
+	if extra := len(usage.DurationsMS) - MaxDurations; extra > 0 {
+		usage.DurationsMS = usage.DurationsMS[extra:]
+	}
+	if errType != "" {
+		usage.Failures++
+		s.Errors[errType]++
+	}
+}
+
+// Reset starts a new period at now, forgetting the aggregated usage.
+func (s *State) Reset(now time.Time) {
+	s.Since = now
+	s.Commands = map[string]*Usage{}
+	s.Errors = map[string]int{}
+}
+
+// Due reports whether a report should be sent at now: telemetry is on,
+// there is something to report, and no report was tried for SendInterval.
+func (s *State) Due(now time.Time) bool {
+	return s.Enabled && Endpoint != "" && len(s.Commands) > 0 &&
+		now.Sub(s.LastAttempt) >= SendInterval && now.Sub(s.Since) >= SendInterval
+}
+
+// Report is exactly what is sent to Endpoint.
+type Report struct {
+	Schema   int             `json:"schema"`
+	Version  string          `json:"sprout_version"`
+	OS       string          `json:"os"`
+	Arch     string          `json:"arch"`
+	From     string          `json:"from"` // Dates only, e.g. "2026-10-07"
+	To       string          `json:"to"`
+	Commands []CommandReport `json:"commands"`
+	Errors   map[string]int  `json:"errors"`
+}
+
+// CommandReport summarizes one command's usage.
+type CommandReport struct {
+	Name     string `json:"name"`
+	Runs     int    `json:"runs"`
+	Failures int    `json:"failures"`
+	MedianMS int64  `json:"median_ms"`
+	P90MS    int64  `json:"p90_ms"`
+}
+
+// Send posts report to Endpoint.
+func Send(report Report) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode telemetry report: %w", err)
+	}
+	client := &http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Post(Endpoint, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to send telemetry report: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to send telemetry report: %s", resp.Status)
+	}
+	return nil
+}
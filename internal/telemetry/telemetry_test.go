@@ -0,0 +1,51 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestState_Record(t *testing.T) {
+	var state State
+	for i := 0; i < MaxDurations+5; i++ {
+		state.Record("list", time.Duration(i)*time.Millisecond, "")
+	}
+	state.Record("add", time.Second, "git")
+
+	require.Contains(t, state.Commands, "list")
+	assert.Equal(t, MaxDurations+5, state.Commands["list"].Runs)
+	assert.Len(t, state.Commands["list"].DurationsMS, MaxDurations)
+	assert.Equal(t, int64(5), state.Commands["list"].DurationsMS[0], "oldest durations are dropped")
+	assert.Equal(t, 1, state.Commands["add"].Failures)
+	assert.Equal(t, map[string]int{"git": 1}, state.Errors)
+
+	state.Reset(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))
+	assert.Empty(t, state.Commands)
+	assert.Empty(t, state.Errors)
+}
+
+func TestState_Due(t *testing.T) {
+	Endpoint = "https://example.com/report"
+	t.Cleanup(func() { Endpoint = "" })
+
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	state := State{Enabled: true, Since: since}
+	state.Record("list", time.Second, "")
+
+	assert.False(t, state.Due(since.Add(time.Hour)), "period not over")
+	assert.True(t, state.Due(since.Add(SendInterval)))
+
+	state.LastAttempt = since.Add(SendInterval)
+	assert.False(t, state.Due(since.Add(SendInterval+time.Hour)), "tried recently")
+
+	state.LastAttempt = time.Time{}
+	state.Enabled = false
+	assert.False(t, state.Due(since.Add(SendInterval)), "off")
+
+	state.Enabled = true
+	Endpoint = ""
+	assert.False(t, state.Due(since.Add(SendInterval)), "no endpoint")
+}
@@ -0,0 +1,204 @@
+// Package tickets looks up issue titles in issue trackers (Jira, Linear) so
+// branch names and worktree listings can show what a worktree is for.
+package tickets
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Supported provider names for ticket_provider in .sprout.yml.
+const (
+	ProviderJira   = "jira"
+	ProviderLinear = "linear"
+)
+
+// DefaultLinearURL is the Linear GraphQL endpoint.
+const DefaultLinearURL = "https://api.linear.app/graphql"
+
+var (
+	ErrUnknownProvider = errors.New("unknown ticket provider (supported: jira, linear)")
+	ErrTicketNotFound  = errors.New("ticket not found")
+)
+
+// Ticket is the metadata sprout keeps about an issue.
+type Ticket struct {
+	ID    string `json:"id"`
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+// Summary returns the ticket id followed by its title, if known.
+func (t Ticket) Summary() string {
+	if t.Title == "" {
+		return t.ID
+	}
+	return t.ID + " " + t.Title
+}
+
+// Provider fetches tickets from an issue tracker.
+type Provider interface {
+	Fetch(id string) (Ticket, error)
+}
+
+// NewProvider creates the named provider, configured from the environment:
+//
+//	jira:   JIRA_URL (e.g. https://acme.atlassian.net), JIRA_EMAIL, JIRA_API_TOKEN
+//	linear: LINEAR_API_KEY
+func NewProvider(name string) (Provider, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	switch name {
+	case ProviderJira:
+		p := &Jira{
+			BaseURL: strings.TrimSuffix(os.Getenv("JIRA_URL"), "/"),
+			Email:   os.Getenv("JIRA_EMAIL"),
+			Token:   os.Getenv("JIRA_API_TOKEN"),
+			HTTP:    client,
+		}
+		if p.BaseURL == "" || p.Token == "" {
+			return nil, errors.New("jira ticket provider needs JIRA_URL and JIRA_API_TOKEN (and JIRA_EMAIL for Jira Cloud)")
+		}
+		return p, nil
+	case ProviderLinear:
+		p := &Linear{
+			URL:    DefaultLinearURL,
+			APIKey: os.Getenv("LINEAR_API_KEY"),
+			HTTP:   client,
+		}
+		if p.APIKey == "" {
+			return nil, errors.New("linear ticket provider needs LINEAR_API_KEY")
+		}
+		return p, nil
+	default:
+		return nil, fmt.Errorf("%q: %w", name, ErrUnknownProvider)
+	}
+}
+
+// Jira fetches issues through the Jira REST API.
+type Jira struct {
+	BaseURL string
+	Email   string // Basic auth user; empty means Token is a bearer token (Jira Data Center)
+	Token   string
+	HTTP    *http.Client
+}
+
+// Fetch returns the issue's key, summary and browse URL.
+func (j *Jira) Fetch(id string) (Ticket, error) {
+	req, err := http.NewRequest(http.MethodGet, j.BaseURL+"/rest/api/2/issue/"+url.PathEscape(id)+"?fields=summary", nil)
+	if err != nil {
+		return Ticket{}, err
+	}
+	if j.Email != "" {
+		req.SetBasicAuth(j.Email, j.Token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+j.Token)
+	}
+
+	var issue struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary string `json:"summary"`
+		} `json:"fields"`
+	}
+	if err := doJSON(j.HTTP, req, &issue); err != nil {
+		return Ticket{}, fmt.Errorf("failed to fetch %s from Jira: %w", id, err)
+	}
+
+	return Ticket{
+		ID:    issue.Key,
+		Title: issue.Fields.Summary,
+		URL:   j.BaseURL + "/browse/" + issue.Key,
+	}, nil
+}
+
+// Linear fetches issues through the Linear GraphQL API.
+type Linear struct {
+	URL    string
+	APIKey string
+	HTTP   *http.Client
+}
+
+// Fetch returns the issue's identifier, title and URL.
+func (l *Linear) Fetch(id string) (Ticket, error) {
+	body, err := json.Marshal(map[string]any{
+		"query":     "query($id: String!) { issue(id: $id) { identifier title url } }",
+		"variables": map[string]string{"id": id},
+	})
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, l.URL, bytes.NewReader(body))
+	if err != nil {
+		return Ticket{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", l.APIKey)
+
+	var resp struct {
+		Data struct {
+			Issue *struct {
+				Identifier string `json:"identifier"`
+				Title      string `json:"title"`
+				URL        string `json:"url"`
+			} `json:"issue"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := doJSON(l.HTTP, req, &resp); err != nil {
+		return Ticket{}, fmt.Errorf("failed to fetch %s from Linear: %w", id, err)
+	}
+	if len(resp.Errors) > 0 {
+		return Ticket{}, fmt.Errorf("failed to fetch %s from Linear: %s", id, resp.Errors[0].Message)
+	}
+	if resp.Data.Issue == nil {
+		return Ticket{}, fmt.Errorf("%s: %w", id, ErrTicketNotFound)
+	}
+
+	return Ticket{
+		ID:    resp.Data.Issue.Identifier,
+		Title: resp.Data.Issue.Title,
+		URL:   resp.Data.Issue.URL,
+	}, nil
+}
+
+// doJSON sends req and decodes a successful JSON response into v.
+func doJSON(client *http.Client, req *http.Request, v any) error {
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "sprout")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrTicketNotFound
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("not authorized (%s); check your API token", resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package tickets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJiraFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "jane@example.com", user)
+		assert.Equal(t, "secret", pass)
+
+		if r.URL.Path != "/rest/api/2/issue/ABC-123" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"key": "ABC-123", "fields": {"summary": "Fix login"}}`))
+	}))
+	defer srv.Close()
+
+	jira := &Jira{BaseURL: srv.URL, Email: "jane@example.com", Token: "secret", HTTP: srv.Client()}
+
+	ticket, err := jira.Fetch("ABC-123")
+	require.NoError(t, err)
+	assert.Equal(t, Ticket{ID: "ABC-123", Title: "Fix login", URL: srv.URL + "/browse/ABC-123"}, ticket)
+
+	_, err = jira.Fetch("ABC-404")
+	assert.ErrorIs(t, err, ErrTicketNotFound)
+}
+
+func TestLinearFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "lin_key", r.Header.Get("Authorization"))
+
+		var req struct {
+			Variables map[string]string `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		if req.Variables["id"] != "ENG-7" {
+			w.Write([]byte(`{"data": {"issue": null}}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"issue": {"identifier": "ENG-7", "title": "Add dark mode", "url": "https://linear.app/acme/issue/ENG-7"}}}`))
+	}))
+	defer srv.Close()
+
+	linear := &Linear{URL: srv.URL, APIKey: "lin_key", HTTP: srv.Client()}
+
+	ticket, err := linear.Fetch("ENG-7")
+	require.NoError(t, err)
+	assert.Equal(t, "ENG-7 Add dark mode", ticket.Summary())
+	assert.Equal(t, "https://linear.app/acme/issue/ENG-7", ticket.URL)
+
+	_, err = linear.Fetch("ENG-8")
+	assert.ErrorIs(t, err, ErrTicketNotFound)
+}
+
+func TestFetchUnauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	_, err := (&Linear{URL: srv.URL, APIKey: "bad", HTTP: srv.Client()}).Fetch("ENG-7")
+	assert.ErrorContains(t, err, "not authorized")
+}
+
+func TestNewProvider(t *testing.T) {
+	t.Setenv("LINEAR_API_KEY", "")
+	_, err := NewProvider(ProviderLinear)
+	assert.ErrorContains(t, err, "LINEAR_API_KEY")
+
+	t.Setenv("LINEAR_API_KEY", "lin_key")
+	p, err := NewProvider(ProviderLinear)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultLinearURL, p.(*Linear).URL)
+
+	_, err = NewProvider("github")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
@@ -0,0 +1,61 @@
+// Package timing collects how long commands, actions, hook commands and
+// worktree status checks take, for 'sprout stats'. Samples are kept in
+// memory until the command saves them with the rest of sprout's local state.
+// Nothing is recorded until Start is called, so programs using sprout as a
+// library don't accumulate samples.
+package timing
+
+import (
+	"sync"
+	"time"
+)
+
+// Kinds of samples.
+const (
+	Command = "command" // A whole sprout command, named like "list" or "api create"
+	Action  = "action"  // A planned action, such as "git worktree add" or "CopyFile"
+	Hook    = "hook"    // One hook command, named like "on_create: npm ci"
+	Status  = "status"  // Computing the git status of one worktree
+)
+
+// Sample is one measured duration.
+type Sample struct {
+	Repo     string        `json:"repo,omitempty"` // Main worktree path, set when saved
+	Kind     string        `json:"kind"`
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration_ns"`
+	At       time.Time     `json:"at"`
+}
+
+var (
+	mu      sync.Mutex
+	on      bool
+	samples []Sample
+)
+
+// Start begins recording samples.
+func Start() {
+	mu.Lock()
+	defer mu.Unlock()
+	on = true
+}
+
+// Record adds a sample that started at started and ended now, if
+// recording has started.
+func Record(kind, name string, started time.Time) {
+	mu.Lock()
+	defer mu.Unlock()
+	if !on {
+		return
+	}
+	samples = append(samples, Sample{Kind: kind, Name: name, Duration: time.Since(started), At: started})
+}
+
+// Drain returns the samples recorded so far and forgets them.
+func Drain() []Sample {
+	mu.Lock()
+	defer mu.Unlock()
+	drained := samples
+	samples = nil
+	return drained
+}
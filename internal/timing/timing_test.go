@@ -0,0 +1,29 @@
+package timing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndDrain(t *testing.T) {
+	Record(Command, "dropped before start", time.Now())
+	assert.Empty(t, Drain())
+
+	Start()
+	started := time.Now().Add(-time.Second)
+	Record(Hook, "on_create: npm ci", started)
+	Record(Status, "worktree status", time.Now())
+
+	drained := Drain()
+	require.Len(t, drained, 2)
+	assert.Equal(t, Hook, drained[0].Kind)
+	assert.Equal(t, "on_create: npm ci", drained[0].Name)
+	assert.Equal(t, started, drained[0].At)
+	assert.GreaterOrEqual(t, drained[0].Duration, time.Second)
+	assert.Equal(t, Status, drained[1].Kind)
+
+	assert.Empty(t, Drain(), "drained samples are forgotten")
+}
@@ -0,0 +1,136 @@
+package trust
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/git"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrDeniedByPolicy is returned when an organization policy forbids hooks for a repository.
+var ErrDeniedByPolicy = errors.New("hooks are disabled by policy for this repository")
+
+// Policy is an admin-provided trust policy, evaluated before the per-repo trust store.
+//
+// Example ~/.config/sprout/policy.yml:
+//
+//	disable_hooks: false
+//	trusted_remotes:
+//	  - github.com/mycompany/*
+//	denied_remotes:
+//	  - github.com/mycompany/sandbox-*
+//	trust_ttl: 90d
+type Policy struct {
+	DisableHooks   bool     `yaml:"disable_hooks"`
+	TrustedRemotes []string `yaml:"trusted_remotes"`
+	DeniedRemotes  []string `yaml:"denied_remotes"`
+	TrustTTL       string   `yaml:"trust_ttl"` // Overrides the user's trust_ttl
+}
+
+// PolicyDecision is the outcome of evaluating a policy for a repository.
+type PolicyDecision int
+
+const (
+	// PolicyNone means the policy has no opinion; the trust store decides.
+	PolicyNone PolicyDecision = iota
+	// PolicyAllow pre-trusts the repository.
+	PolicyAllow
+	// PolicyDeny forbids hooks regardless of the trust store.
+	PolicyDeny
+)
+
+// GetPolicyPath returns the policy file location.
+// SPROUT_POLICY_FILE overrides the default of <config dir>/policy.yml.
+func GetPolicyPath() (string, error) {
+	if p := os.Getenv("SPROUT_POLICY_FILE"); p != "" {
+		return p, nil
+	}
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "policy.yml"), nil
+}
+
+// LoadPolicy loads the policy file. Returns nil, nil if no policy exists.
+func LoadPolicy() (*Policy, error) {
+	policyPath, err := GetPolicyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(policyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read trust policy: %w", err)
+	}
+
+	var policy Policy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse trust policy %s: %w", policyPath, err)
+	}
+
+	for _, pattern := range append(append([]string{}, policy.TrustedRemotes...), policy.DeniedRemotes...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q in trust policy %s: %w", pattern, policyPath, err)
+		}
+	}
+
+	if policy.TrustTTL != "" {
+		if _, err := config.ParseDuration(policy.TrustTTL); err != nil {
+			return nil, fmt.Errorf("invalid trust_ttl in trust policy %s: %w", policyPath, err)
+		}
+	}
+
+	return &policy, nil
+}
+
+// Evaluate decides trust for a normalized remote URL (see NormalizeRemoteURL).
+// Deny rules win over allow rules; an empty remote only matches disable_hooks.
+func (p *Policy) Evaluate(remote string) PolicyDecision {
+	if p == nil {
+		return PolicyNone
+	}
+	if p.DisableHooks {
+		return PolicyDeny
+	}
+	if remote == "" {
+		return PolicyNone
+	}
+	if matchesAny(p.DeniedRemotes, remote) {
+		return PolicyDeny
+	}
+	if matchesAny(p.TrustedRemotes, remote) {
+		return PolicyAllow
+	}
+	return PolicyNone
+}
+
+func matchesAny(patterns []string, remote string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(git.NormalizeRemoteURL(pattern), remote); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// EvaluatePolicy loads the policy and evaluates it for the repository at repoRoot,
+// using the URL of its origin remote.
+func EvaluatePolicy(repoRoot string) (PolicyDecision, error) {
+	policy, err := LoadPolicy()
+	if err != nil || policy == nil {
+		return PolicyNone, err
+	}
+
+	// A repo without an origin remote can only be affected by disable_hooks
+	remote, _ := git.GetRemoteURL(repoRoot, "origin")
+	return policy.Evaluate(git.NormalizeRemoteURL(remote)), nil
+}
@@ -6,6 +6,9 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/m44rten1/sprout/internal/config"
+	"github.com/m44rten1/sprout/internal/git"
 )
 
 // Store represents the trusted projects store
@@ -18,26 +21,135 @@ type Store struct {
 type TrustedProject struct {
 	RepoRoot  string    `json:"repo_root"`
 	TrustedAt time.Time `json:"trusted_at"`
+	// Remote is the normalized origin URL at the time trust was granted. With
+	// repo_identity: remote, FindTrustedProject finds the entry from a
+	// repository that was moved, but trust itself stays with RepoRoot.
+	Remote string `json:"remote,omitempty"`
+	// HooksHash is the config.HooksHash of the repository's hooks when trust
+	// was granted, and Hooks their commands by type. Trust lapses when the
+	// hooks change (see HooksChanged). Entries recorded before sprout kept
+	// them get the main worktree's hooks the first time they're checked.
+	HooksHash string              `json:"hooks_hash,omitempty"`
+	Hooks     map[string][]string `json:"hooks,omitempty"`
 }
 
-// GetConfigDir returns the sprout config directory, respecting XDG_CONFIG_HOME
-func GetConfigDir() (string, error) {
-	var configDir string
-	if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
-		configDir = filepath.Join(xdgConfig, "sprout")
-	} else {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return "", fmt.Errorf("failed to get user home directory: %w", err)
-		}
-		configDir = filepath.Join(home, ".config", "sprout")
+// repoMatcher decides whether a store entry refers to a given repository.
+type repoMatcher struct {
+	repoRoot string
+	remote   string // empty unless remote identity is enabled and origin is set
+}
+
+func newRepoMatcher(repoRoot string) (repoMatcher, error) {
+	m := repoMatcher{repoRoot: repoRoot}
+	userCfg, err := config.LoadUser()
+	if err != nil {
+		return m, err
+	}
+	if userCfg.UsesRemoteIdentity() {
+		m.remote = repoRemote(repoRoot)
 	}
+	return m, nil
+}
+
+func (m repoMatcher) matches(project TrustedProject) bool {
+	if project.RepoRoot == m.repoRoot {
+		return true
+	}
+	return m.remote != "" && project.Remote == m.remote
+}
 
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create config directory: %w", err)
+// repoRemote returns the normalized origin URL, or "" if there is none.
+func repoRemote(repoRoot string) string {
+	remoteURL, err := git.GetRemoteURL(repoRoot, "origin")
+	if err != nil {
+		return ""
 	}
+	return git.NormalizeRemoteURL(remoteURL)
+}
 
-	return configDir, nil
+// ExpiresAt returns when trust lapses for the given TTL.
+// Returns false if the TTL is zero (trust never expires).
+func (p TrustedProject) ExpiresAt(ttl time.Duration) (time.Time, bool) {
+	if ttl <= 0 {
+		return time.Time{}, false
+	}
+	return p.TrustedAt.Add(ttl), true
+}
+
+// IsExpired reports whether trust has lapsed at the given time.
+func (p TrustedProject) IsExpired(ttl time.Duration, now time.Time) bool {
+	expiresAt, ok := p.ExpiresAt(ttl)
+	return ok && !now.Before(expiresAt)
+}
+
+// HooksChanged reports whether cfg has other hooks than those trusted.
+func (p TrustedProject) HooksChanged(cfg *config.Config) bool {
+	return p.HooksHash != "" && p.HooksHash != cfg.HooksHash()
+}
+
+// repoConfig loads the .sprout.yml hooks.RunHooks runs in worktreePath:
+// the worktree's own, falling back to the main worktree's at repoRoot.
+// Without one, or with one that doesn't load and so runs no hooks, there
+// are none; once fixed, its hooks differ from none and ask for trust again.
+func repoConfig(repoRoot, worktreePath string) *config.Config {
+	cfg, err := config.Load(worktreePath, repoRoot)
+	if err != nil {
+		return &config.Config{}
+	}
+	return cfg
+}
+
+// TrustTTL returns the effective trust lifetime. A trust_ttl in the
+// organization policy takes precedence over the user config; zero means no expiry.
+func TrustTTL() (time.Duration, error) {
+	policy, err := LoadPolicy()
+	if err != nil {
+		return 0, err
+	}
+	if policy != nil && policy.TrustTTL != "" {
+		return config.ParseDuration(policy.TrustTTL)
+	}
+
+	userCfg, err := config.LoadUser()
+	if err != nil {
+		return 0, err
+	}
+	if userCfg.TrustTTL == "" {
+		return 0, nil
+	}
+	return config.ParseDuration(userCfg.TrustTTL)
+}
+
+// FindTrustedProject returns the trust store entry for a repository, ignoring expiry.
+// Under remote identity an entry recorded for the same origin matches when
+// none was recorded for the path; its RepoRoot tells them apart.
+func FindTrustedProject(repoRoot string) (TrustedProject, bool, error) {
+	matcher, err := newRepoMatcher(repoRoot)
+	if err != nil {
+		return TrustedProject{}, false, err
+	}
+	store, err := LoadStore()
+	if err != nil {
+		return TrustedProject{}, false, err
+	}
+	var byRemote *TrustedProject
+	for i, project := range store.Trusted {
+		if project.RepoRoot == repoRoot {
+			return project, true, nil
+		}
+		if byRemote == nil && matcher.matches(project) {
+			byRemote = &store.Trusted[i]
+		}
+	}
+	if byRemote != nil {
+		return *byRemote, true, nil
+	}
+	return TrustedProject{}, false, nil
+}
+
+// GetConfigDir returns the sprout config directory, respecting XDG_CONFIG_HOME
+func GetConfigDir() (string, error) {
+	return config.GetUserConfigDir()
 }
 
 // GetStorePath returns the path to the trusted projects store
@@ -49,8 +161,18 @@ func GetStorePath() (string, error) {
 	return filepath.Join(configDir, "trusted-projects.json"), nil
 }
 
-// LoadStore loads the trusted projects store
+// LoadStore loads the trusted projects store.
+// A store that cannot be parsed is moved aside to a timestamped backup and
+// replaced with an empty store, so a corrupt file never locks users out.
 func LoadStore() (*Store, error) {
+	return loadStore(false)
+}
+
+// loadStore loads the store; locked says the caller holds its lock. A
+// store that does not parse is only recovered under the lock, after
+// reading it again: without it, a store another process is writing could
+// look corrupt and be reset.
+func loadStore(locked bool) (*Store, error) {
 	storePath, err := GetStorePath()
 	if err != nil {
 		return nil, err
@@ -60,23 +182,30 @@ func LoadStore() (*Store, error) {
 	if err != nil {
 		if os.IsNotExist(err) {
 			// No store file yet, return empty store
-			return &Store{
-				Version: 1,
-				Trusted: []TrustedProject{},
-			}, nil
+			return newStore(), nil
 		}
 		return nil, fmt.Errorf("failed to read trust store: %w", err)
 	}
 
 	var store Store
 	if err := json.Unmarshal(data, &store); err != nil {
-		return nil, fmt.Errorf("failed to parse trust store: %w", err)
+		if locked {
+			return recoverCorruptStore(storePath, err)
+		}
+		unlock, err := lockPath(storePath + ".lock")
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock trust store: %w", err)
+		}
+		defer unlock()
+		return loadStore(true)
 	}
 
 	return &store, nil
 }
 
-// SaveStore saves the trusted projects store
+// SaveStore saves the trusted projects store.
+// The file is written to a temp file and renamed into place, so readers
+// never observe a partially written store.
 func SaveStore(store *Store) error {
 	storePath, err := GetStorePath()
 	if err != nil {
@@ -88,67 +217,229 @@ func SaveStore(store *Store) error {
 		return fmt.Errorf("failed to marshal trust store: %w", err)
 	}
 
-	if err := os.WriteFile(storePath, data, 0644); err != nil {
+	if err := writeFileAtomic(storePath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write trust store: %w", err)
 	}
 
 	return nil
 }
 
-// IsRepoTrusted checks if a repository is trusted
-func IsRepoTrusted(repoRoot string) (bool, error) {
-	store, err := LoadStore()
+// UpdateStore loads the store, applies fn, and saves the result while holding
+// an exclusive lock, so concurrent sprout invocations cannot lose updates.
+// If fn returns false the store is left untouched.
+func UpdateStore(fn func(store *Store) bool) error {
+	storePath, err := GetStorePath()
 	if err != nil {
-		return false, err
+		return err
 	}
 
-	for _, project := range store.Trusted {
-		if project.RepoRoot == repoRoot {
-			return true, nil
-		}
+	unlock, err := lockPath(storePath + ".lock")
+	if err != nil {
+		return fmt.Errorf("failed to lock trust store: %w", err)
+	}
+	defer unlock()
+
+	store, err := loadStore(true)
+	if err != nil {
+		return err
 	}
 
-	return false, nil
+	if !fn(store) {
+		return nil
+	}
+
+	return SaveStore(store)
 }
 
-// TrustRepo adds a repository to the trusted list
-func TrustRepo(repoRoot string) error {
-	store, err := LoadStore()
+func newStore() *Store {
+	return &Store{
+		Version: 1,
+		Trusted: []TrustedProject{},
+	}
+}
+
+// recoverCorruptStore moves an unparseable store aside and returns an empty
+// one. The caller holds the store lock.
+func recoverCorruptStore(storePath string, parseErr error) (*Store, error) {
+	backupPath := fmt.Sprintf("%s.corrupt-%s", storePath, time.Now().Format("20060102-150405"))
+	if err := os.Rename(storePath, backupPath); err != nil {
+		return nil, fmt.Errorf("failed to parse trust store: %w (backup failed: %v)", parseErr, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "⚠️  Trust store was corrupt and has been reset (%v)\n", parseErr)
+	fmt.Fprintf(os.Stderr, "   Backup saved to: %s\n", backupPath)
+	fmt.Fprintf(os.Stderr, "   Run 'sprout trust' again in repositories you trust.\n")
+
+	return newStore(), nil
+}
+
+// writeFileAtomic writes data to a temp file in the target directory and
+// renames it over path.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
 	if err != nil {
 		return err
 	}
+	tmpPath := tmp.Name()
+	// Best-effort cleanup; after a successful rename the temp path no longer exists
+	defer os.Remove(tmpPath)
 
-	// Check if already trusted
-	for _, project := range store.Trusted {
-		if project.RepoRoot == repoRoot {
-			return nil // Already trusted
-		}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
 	}
 
-	// Add to trusted list
-	store.Trusted = append(store.Trusted, TrustedProject{
-		RepoRoot:  repoRoot,
-		TrustedAt: time.Now(),
-	})
+	return os.Rename(tmpPath, path)
+}
 
-	return SaveStore(store)
+// lockPath acquires an exclusive advisory lock on the given lock file,
+// blocking until it is available. The returned function releases it.
+func lockPath(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		_ = unlockFile(f)
+		f.Close()
+	}, nil
 }
 
-// UntrustRepo removes a repository from the trusted list
-func UntrustRepo(repoRoot string) error {
-	store, err := LoadStore()
+// IsRepoTrusted checks if a repository is trusted to run the hooks of its
+// worktree at worktreePath, which is repoRoot for the main worktree. A
+// branch that edits .sprout.yml brings other hooks along, and they need
+// trust of their own.
+// The organization policy (see LoadPolicy) is consulted first: it can pre-trust
+// a repository, or deny hooks with ErrDeniedByPolicy.
+func IsRepoTrusted(repoRoot, worktreePath string) (bool, error) {
+	decision, err := EvaluatePolicy(repoRoot)
 	if err != nil {
-		return err
+		return false, err
+	}
+	switch decision {
+	case PolicyAllow:
+		return true, nil
+	case PolicyDeny:
+		return false, ErrDeniedByPolicy
+	}
+
+	project, found, err := FindTrustedProject(repoRoot)
+	if err != nil || !found {
+		return false, err
+	}
+	// Any clone can claim a trusted origin, so one found by it only shows
+	// where trust was given when asking again
+	if project.RepoRoot != repoRoot {
+		return false, nil
+	}
+
+	// Expired trust requires re-confirmation
+	ttl, err := TrustTTL()
+	if err != nil {
+		return false, err
+	}
+	if project.IsExpired(ttl, time.Now()) {
+		return false, nil
 	}
 
-	// Filter out the repo
-	filtered := []TrustedProject{}
-	for _, project := range store.Trusted {
-		if project.RepoRoot != repoRoot {
-			filtered = append(filtered, project)
+	// An entry from before hooks were recorded trusts the main worktree's
+	// hooks as they are now, but no others
+	if project.HooksHash == "" {
+		if project, err = recordHooks(repoRoot); err != nil || project.HooksHash == "" {
+			// Without an entry left to record them in, it was untrusted meanwhile
+			return false, err
 		}
 	}
 
-	store.Trusted = filtered
-	return SaveStore(store)
+	// So do hooks other than the ones trusted
+	return !project.HooksChanged(repoConfig(repoRoot, worktreePath)), nil
+}
+
+// recordHooks stores the main worktree's hooks in the trust entry of a
+// repository that has none yet, and returns the entry, or a zero one if
+// there is no entry.
+func recordHooks(repoRoot string) (TrustedProject, error) {
+	cfg := repoConfig(repoRoot, repoRoot)
+
+	var project TrustedProject
+	err := UpdateStore(func(store *Store) bool {
+		for i := range store.Trusted {
+			if store.Trusted[i].RepoRoot == repoRoot {
+				if store.Trusted[i].HooksHash == "" {
+					store.Trusted[i].HooksHash = cfg.HooksHash()
+					store.Trusted[i].Hooks = cfg.HookCommands()
+				}
+				project = store.Trusted[i]
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return TrustedProject{}, fmt.Errorf("failed to record trusted hooks: %w", err)
+	}
+	return project, nil
+}
+
+// TrustRepo adds a repository to the trusted list, with the hooks its
+// worktree at worktreePath has now (see IsRepoTrusted). If the repository is
+// already listed, its trust timestamp and hooks are refreshed, which renews
+// trust that has expired (or is about to) or lapsed because the hooks changed.
+// Entries of other clones of the same origin are left alone.
+func TrustRepo(repoRoot, worktreePath string) error {
+	remote := repoRemote(repoRoot)
+	cfg := repoConfig(repoRoot, worktreePath)
+	hooksHash, hooks := cfg.HooksHash(), cfg.HookCommands()
+
+	return UpdateStore(func(store *Store) bool {
+		for i, project := range store.Trusted {
+			if project.RepoRoot == repoRoot {
+				store.Trusted[i].Remote = remote
+				store.Trusted[i].TrustedAt = time.Now()
+				store.Trusted[i].HooksHash = hooksHash
+				store.Trusted[i].Hooks = hooks
+				return true
+			}
+		}
+
+		// Add to trusted list
+		store.Trusted = append(store.Trusted, TrustedProject{
+			RepoRoot:  repoRoot,
+			TrustedAt: time.Now(),
+			Remote:    remote,
+			HooksHash: hooksHash,
+			Hooks:     hooks,
+		})
+		return true
+	})
+}
+
+// UntrustRepo removes a repository from the trusted list
+func UntrustRepo(repoRoot string) error {
+	return UpdateStore(func(store *Store) bool {
+		// Filter out the repo
+		filtered := []TrustedProject{}
+		for _, project := range store.Trusted {
+			if project.RepoRoot != repoRoot {
+				filtered = append(filtered, project)
+			}
+		}
+
+		store.Trusted = filtered
+		return true
+	})
 }
